@@ -384,6 +384,7 @@ func (b *Builder) processTask(
 		gen := NewSimpleBloomGenerator(
 			tenant,
 			blockOpts,
+			b.limits.BloomTermIndexedKeys(tenant),
 			seriesItrWithCounter,
 			b.chunkLoader,
 			blocksIter,
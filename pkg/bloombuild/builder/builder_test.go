@@ -242,6 +242,10 @@ func (f fakeLimits) BloomMaxBloomSize(_ string) int {
 	panic("implement me")
 }
 
+func (f fakeLimits) BloomTermIndexedKeys(_ string) []string {
+	panic("implement me")
+}
+
 type fakeBloomStore struct {
 	bloomshipper.Store
 }
@@ -40,4 +40,5 @@ type Limits interface {
 	BloomBlockEncoding(tenantID string) string
 	BloomMaxBlockSize(tenantID string) int
 	BloomMaxBloomSize(tenantID string) int
+	BloomTermIndexedKeys(tenantID string) []string
 }
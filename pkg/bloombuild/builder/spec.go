@@ -44,6 +44,7 @@ type SimpleBloomGenerator struct {
 func NewSimpleBloomGenerator(
 	userID string,
 	opts v1.BlockOptions,
+	termKeys []string,
 	store iter.Iterator[*v1.Series],
 	chunkLoader ChunkLoader,
 	blocksIter iter.ResetIterator[*v1.SeriesWithBlooms],
@@ -69,6 +70,7 @@ func NewSimpleBloomGenerator(
 
 		tokenizer: v1.NewBloomTokenizer(
 			int(opts.UnencodedBlockOptions.MaxBloomSizeBytes),
+			termKeys,
 			metrics,
 			log.With(
 				logger,
@@ -99,6 +99,7 @@ func dummyBloomGen(t *testing.T, opts v1.BlockOptions, store v2.Iterator[*v1.Ser
 	return NewSimpleBloomGenerator(
 		"fake",
 		opts,
+		nil,
 		store,
 		dummyChunkLoader{},
 		blocksIter,
@@ -0,0 +1,154 @@
+package chunkenc
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/storage/remote/otlptranslator/prometheus"
+)
+
+const (
+	// summaryNGramLength is the length of the n-grams of the log lines added to
+	// the bloom filters of the block summaries.
+	summaryNGramLength = 4
+	// The bloom filters are built with the maximum size, then folded in half
+	// while the ratio of their set bits stays below summaryBloomMaxFill.
+	summaryBloomMaxSize = 8 << 10
+	summaryBloomMinSize = 64
+	summaryBloomMaxFill = 0.5
+)
+
+// blockSummary summarizes the content of a block of ChunkFormatV5 chunks, so
+// that the iterators skip the blocks without the log lines they look for.
+type blockSummary struct {
+	// structuredMetadata are the symbols of the structured metadata names of
+	// the entries of the block.
+	structuredMetadata []uint32
+	// bloom is a bloom filter of the n-grams of the log lines of the block.
+	bloom []byte
+}
+
+// summaryBloomIndexes returns the indexes of the bits of the n-gram in a bloom
+// filter of size bits, which must be a power of two so that the bloom filters
+// can be folded.
+func summaryBloomIndexes(ngram []byte, size uint32) (uint32, uint32) {
+	h := uint64(binary.LittleEndian.Uint32(ngram)) * 0x9E3779B97F4A7C15
+	h1, h2 := uint32(h>>32), uint32(h)|1
+	return h1 & (size - 1), (h1 + h2) & (size - 1)
+}
+
+// addToSummaryBloom adds the n-grams of the log line to the bloom filter.
+func addToSummaryBloom(bloom []byte, line string) {
+	size := uint32(len(bloom) * 8)
+	b := unsafeGetBytes(line)
+	for i := 0; i+summaryNGramLength <= len(b); i++ {
+		i1, i2 := summaryBloomIndexes(b[i:i+summaryNGramLength], size)
+		bloom[i1/8] |= 1 << (i1 % 8)
+		bloom[i2/8] |= 1 << (i2 % 8)
+	}
+}
+
+// foldSummaryBloom folds the bloom filter in half while the ratio of its set
+// bits stays below summaryBloomMaxFill.
+func foldSummaryBloom(bloom []byte) []byte {
+	for len(bloom) > summaryBloomMinSize {
+		half := len(bloom) / 2
+		folded := make([]byte, half)
+		set := 0
+		for i := range folded {
+			folded[i] = bloom[i] | bloom[half+i]
+			set += bits.OnesCount8(folded[i])
+		}
+		if float64(set) > summaryBloomMaxFill*float64(half*8) {
+			break
+		}
+		bloom = folded
+	}
+	return bloom
+}
+
+// mayContain returns whether a log line of the block may contain b.
+func (s *blockSummary) mayContain(b []byte) bool {
+	size := uint32(len(s.bloom) * 8)
+	for i := 0; i+summaryNGramLength <= len(b); i++ {
+		i1, i2 := summaryBloomIndexes(b[i:i+summaryNGramLength], size)
+		if s.bloom[i1/8]&(1<<(i1%8)) == 0 || s.bloom[i2/8]&(1<<(i2%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns whether the block may have log lines containing all the
+// line contents and having all the structured metadata names. It returns true
+// for blocks without summary.
+func (s *blockSummary) matches(symbolizer *symbolizer, lineContents [][]byte, structuredMetadata []string) bool {
+	if s == nil {
+		return true
+	}
+
+	for _, c := range lineContents {
+		if !s.mayContain(c) {
+			return false
+		}
+	}
+
+names:
+	for _, name := range structuredMetadata {
+		for _, symbol := range s.structuredMetadata {
+			// The names are normalized by the pipelines.
+			if prometheus.NormalizeLabel(symbolizer.lookup(symbol)) == name {
+				continue names
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (s *blockSummary) encode(eb *encbuf) {
+	if s == nil {
+		// An empty bloom filter means that the block has no summary.
+		eb.putUvarint(0)
+		eb.putUvarint(0)
+		return
+	}
+
+	eb.putUvarint(len(s.structuredMetadata))
+	for _, symbol := range s.structuredMetadata {
+		eb.putUvarint64(uint64(symbol))
+	}
+	eb.putUvarint(len(s.bloom))
+	eb.b = append(eb.b, s.bloom...)
+}
+
+func (s *blockSummary) encodedSize() int {
+	if s == nil {
+		return 2
+	}
+	return binary.MaxVarintLen32*(len(s.structuredMetadata)+2) + len(s.bloom)
+}
+
+// decodeBlockSummaries decodes the section of the block summaries of a chunk.
+func decodeBlockSummaries(b []byte) ([]*blockSummary, error) {
+	db := decbuf{b: b}
+	num := db.uvarint()
+	summaries := make([]*blockSummary, 0, min(num, len(b)/2))
+	for i := 0; i < num && db.err() == nil; i++ {
+		s := &blockSummary{}
+		nSymbols := db.uvarint()
+		for j := 0; j < nSymbols && db.err() == nil; j++ {
+			s.structuredMetadata = append(s.structuredMetadata, uint32(db.uvarint64()))
+		}
+		s.bloom = db.bytes(db.uvarint())
+		if len(s.bloom) == 0 {
+			s = nil
+		}
+		summaries = append(summaries, s)
+	}
+	if db.err() != nil {
+		return nil, errors.Wrap(db.err(), "decoding block summaries")
+	}
+	return summaries, nil
+}
@@ -0,0 +1,134 @@
+package chunkenc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+func TestBlockSummary(t *testing.T) {
+	summary := &blockSummary{bloom: make([]byte, summaryBloomMaxSize)}
+	for i := 0; i < 100; i++ {
+		addToSummaryBloom(summary.bloom, fmt.Sprintf("level=info msg=\"request %d\"", i))
+	}
+	summary.bloom = foldSummaryBloom(summary.bloom)
+	require.Less(t, len(summary.bloom), summaryBloomMaxSize)
+
+	require.True(t, summary.mayContain([]byte("request 42")))
+	require.True(t, summary.mayContain([]byte("req")), "shorter than an n-gram")
+	require.False(t, summary.mayContain([]byte("OOMKilled")))
+
+	var nilSummary *blockSummary
+	require.True(t, nilSummary.matches(nil, [][]byte{[]byte("OOMKilled")}, []string{"trace_id"}))
+
+	eb := encbuf{}
+	eb.putUvarint(2)
+	summary.encode(&eb)
+	nilSummary.encode(&eb)
+	summaries, err := decodeBlockSummaries(eb.get())
+	require.NoError(t, err)
+	require.Equal(t, []*blockSummary{summary, nil}, summaries)
+}
+
+func TestBlockSummaries_SkipBlocks(t *testing.T) {
+	streamLabels := labels.FromStrings("app", "foo")
+	newChunk := func(format byte) *MemChunk {
+		chk := NewMemChunk(format, compression.Snappy, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, 0)
+		for i := 0; i < 1000; i++ {
+			entry := &logproto.Entry{
+				Timestamp: time.Unix(0, int64(i)),
+				Line:      fmt.Sprintf("level=info msg=%q", fmt.Sprintf("request %d", i)),
+			}
+			if i == 42 {
+				entry.Line = `level=error msg="container OOMKilled"`
+				entry.StructuredMetadata = push.LabelsAdapter{{Name: "trace_id", Value: "42"}}
+			}
+			_, err := chk.Append(entry)
+			require.NoError(t, err)
+			if i%100 == 99 {
+				require.NoError(t, chk.cut())
+			}
+		}
+		require.NoError(t, chk.Close())
+		require.Len(t, chk.blocks, 10)
+		return chk
+	}
+	reload := func(chk *MemChunk) *MemChunk {
+		b, err := chk.Bytes()
+		require.NoError(t, err)
+		chk, err = NewByteChunk(b, testBlockSize, 0)
+		require.NoError(t, err)
+		return chk
+	}
+	v4, v5 := reload(newChunk(ChunkFormatV4)), newChunk(ChunkFormatV5)
+
+	for _, tc := range []struct {
+		query          string
+		expectedBlocks int
+	}{
+		{query: `{app="foo"}`, expectedBlocks: 10},
+		{query: `{app="foo"} |= "OOMKilled"`, expectedBlocks: 1},
+		{query: `{app="foo"} |= "oomkilled"`, expectedBlocks: 0},
+		{query: `{app="foo"} |~ "(?i)oomkilled"`, expectedBlocks: 10},
+		{query: `{app="foo"} != "OOMKilled"`, expectedBlocks: 10},
+		{query: `{app="foo"} | trace_id="42"`, expectedBlocks: 1},
+		{query: `{app="foo"} | app="foo"`, expectedBlocks: 10},
+		{query: `{app="foo"} | logfmt | msg="container OOMKilled"`, expectedBlocks: 10},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := syntax.ParseSampleExpr(fmt.Sprintf(`count_over_time(%s [1m])`, tc.query))
+			require.NoError(t, err)
+			extractor, err := expr.Extractor()
+			require.NoError(t, err)
+
+			samples := func(chk *MemChunk) ([]logproto.Sample, int) {
+				sts, ctx := stats.NewContext(context.Background())
+				it := chk.SampleIterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), extractor.ForStream(streamLabels))
+				var res []logproto.Sample
+				for it.Next() {
+					res = append(res, it.At())
+				}
+				require.NoError(t, it.Close())
+				return res, int(sts.Result(0, 0, 0).TotalDecompressedLines())
+			}
+			expected, _ := samples(v4)
+			// the summaries are kept when the chunk is reloaded.
+			for _, chk := range []*MemChunk{v5, reload(v5)} {
+				actual, decompressedLines := samples(chk)
+				require.Equal(t, expected, actual)
+				require.Equal(t, tc.expectedBlocks*100, decompressedLines)
+			}
+
+			logExpr, err := syntax.ParseLogSelector(tc.query, true)
+			require.NoError(t, err)
+			pipeline, err := logExpr.Pipeline()
+			require.NoError(t, err)
+
+			entries := func(chk *MemChunk) []logproto.Entry {
+				it, err := chk.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.BACKWARD, pipeline.ForStream(streamLabels))
+				require.NoError(t, err)
+				defer it.Close()
+
+				var res []logproto.Entry
+				for it.Next() {
+					res = append(res, it.At())
+				}
+				require.NoError(t, it.Err())
+				return res
+			}
+			require.Equal(t, entries(v4), entries(reload(v5)))
+		})
+	}
+}
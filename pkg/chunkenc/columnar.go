@@ -0,0 +1,509 @@
+package chunkenc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+// The blocks of ChunkFormatV5 chunks store the timestamps, the hashes of the
+// log lines, the log lines and each structured metadata name of their entries
+// in separately compressed columns, so that the iterators only decompress the
+// columns they need:
+//
+//	#entries (uvarint) | #metadata columns (uvarint)
+//	timestamps, hashes, lines compressed length (uvarint)
+//	name symbol, compressed length (uvarint) of each metadata column
+//	timestamps column | hashes column | lines column | metadata columns
+//
+// The timestamps column holds the delta of the timestamp of each entry with
+// the previous one (varint), the hashes column the xxhash of each log line
+// (8 bytes), so that samples are deduplicated with the ones of the other
+// formats without reading the log lines, and the lines column the length
+// (uvarint) and the bytes of each log line. The metadata columns hold the
+// value symbol + 1 of their name for each entry (uvarint), 0 meaning that the
+// entry doesn't have it.
+
+// metadataColumnBuilder builds the column of a structured metadata name.
+type metadataColumnBuilder struct {
+	name      uint32
+	lastEntry int // the last entry whose value was written.
+	buf       encbuf
+}
+
+// serialiseColumnar serialises the entries of the head block into a block of
+// ChunkFormatV5 chunks, and returns its summary.
+func (hb *unorderedHeadBlock) serialiseColumnar(pool compression.WriterPool) ([]byte, *blockSummary, error) {
+	var (
+		timestamps, hashes, lines encbuf
+		bloom                     = make([]byte, summaryBloomMaxSize)
+		metadata                  []*metadataColumnBuilder
+		// the columns of each name, more than one if the name is repeated in an entry.
+		metadataByName = map[uint32][]*metadataColumnBuilder{}
+		entries        int
+		prevTs         int64
+	)
+
+	_ = hb.forEntries(
+		context.Background(),
+		logproto.FORWARD,
+		0,
+		math.MaxInt64,
+		func(_ *stats.Context, ts int64, line string, structuredMetadataSymbols symbols) error {
+			timestamps.putVarint64(ts - prevTs)
+			prevTs = ts
+
+			hashes.putBE64(xxhash.Sum64String(line))
+
+			lines.putUvarint(len(line))
+			lines.b = append(lines.b, line...)
+			addToSummaryBloom(bloom, line)
+
+		symbols:
+			for _, s := range structuredMetadataSymbols {
+				for _, col := range metadataByName[s.Name] {
+					if col.lastEntry < entries {
+						col.buf.putUvarint64(uint64(s.Value) + 1)
+						col.lastEntry = entries
+						continue symbols
+					}
+				}
+				col := &metadataColumnBuilder{name: s.Name, lastEntry: entries}
+				// the previous entries don't have it.
+				for i := 0; i < entries; i++ {
+					col.buf.putUvarint(0)
+				}
+				col.buf.putUvarint64(uint64(s.Value) + 1)
+				metadata = append(metadata, col)
+				metadataByName[s.Name] = append(metadataByName[s.Name], col)
+			}
+			for _, col := range metadata {
+				if col.lastEntry < entries {
+					col.buf.putUvarint(0)
+					col.lastEntry = entries
+				}
+			}
+			entries++
+			return nil
+		},
+	)
+
+	outBuf := &bytes.Buffer{}
+	compressColumn := func(b []byte) (int, error) {
+		start := outBuf.Len()
+		compressedWriter := pool.GetWriter(outBuf)
+		defer pool.PutWriter(compressedWriter)
+
+		if _, err := compressedWriter.Write(b); err != nil {
+			return 0, errors.Wrap(err, "appending column")
+		}
+		if err := compressedWriter.Close(); err != nil {
+			return 0, errors.Wrap(err, "flushing pending compress buffer")
+		}
+		return outBuf.Len() - start, nil
+	}
+
+	var header encbuf
+	header.putUvarint(entries)
+	header.putUvarint(len(metadata))
+	for _, col := range [][]byte{timestamps.get(), hashes.get(), lines.get()} {
+		n, err := compressColumn(col)
+		if err != nil {
+			return nil, nil, err
+		}
+		header.putUvarint(n)
+	}
+	summary := &blockSummary{bloom: foldSummaryBloom(bloom)}
+	for _, col := range metadata {
+		n, err := compressColumn(col.buf.get())
+		if err != nil {
+			return nil, nil, err
+		}
+		header.putUvarint64(uint64(col.name))
+		header.putUvarint(n)
+
+		if metadataByName[col.name][0] == col {
+			summary.structuredMetadata = append(summary.structuredMetadata, col.name)
+		}
+	}
+
+	return append(header.get(), outBuf.Bytes()...), summary, nil
+}
+
+// column is a compressed column of a block, decompressed on its first read.
+type column struct {
+	compressed   []byte
+	buf          *bytes.Buffer
+	decompressed bool
+	d            decbuf
+}
+
+type metadataColumn struct {
+	name uint32
+	column
+}
+
+// columnarIterator iterates over the entries of a block of ChunkFormatV5
+// chunks. The log lines are only decompressed if they are read.
+type columnarIterator struct {
+	origBytes []byte
+	stats     *stats.Context
+
+	pool       compression.ReaderPool
+	symbolizer *symbolizer
+	readsLine  bool // whether to read the log line of each entry.
+
+	err error
+
+	initialized bool
+	entries     int
+	cur         int // the index of the current entry, starting at 1.
+
+	timestamps, hashes, lines column
+	metadata                  []metadataColumn
+	linesRead                 int // the number of entries whose log line was read.
+
+	currTs   int64
+	currLine []byte
+
+	symbolsBuf             []symbol      // The buffer for a single entry's symbols.
+	currStructuredMetadata labels.Labels // The current labels.
+
+	closed bool
+}
+
+func newColumnarIterator(ctx context.Context, pool compression.ReaderPool, b []byte, symbolizer *symbolizer, readsLine bool) *columnarIterator {
+	return &columnarIterator{
+		stats:      stats.FromContext(ctx),
+		origBytes:  b,
+		pool:       pool,
+		symbolizer: symbolizer,
+		readsLine:  readsLine,
+	}
+}
+
+func (si *columnarIterator) init() error {
+	db := decbuf{b: si.origBytes}
+	si.entries = db.uvarint()
+	nMetadata := db.uvarint()
+	tsLen, hashesLen, linesLen := db.uvarint(), db.uvarint(), db.uvarint()
+	si.metadata = make([]metadataColumn, nMetadata)
+	metadataLens := make([]int, nMetadata)
+	for i := range si.metadata {
+		si.metadata[i].name = uint32(db.uvarint64())
+		metadataLens[i] = db.uvarint()
+	}
+	if db.err() != nil {
+		return errors.Wrap(db.err(), "decoding block header")
+	}
+	si.stats.AddCompressedBytes(int64(len(si.origBytes) - len(db.b)))
+
+	si.timestamps.compressed = db.bytes(tsLen)
+	si.hashes.compressed = db.bytes(hashesLen)
+	si.lines.compressed = db.bytes(linesLen)
+	for i := range si.metadata {
+		si.metadata[i].compressed = db.bytes(metadataLens[i])
+	}
+	if db.err() != nil {
+		return errors.Wrap(db.err(), "decoding block columns")
+	}
+
+	if err := si.decompress(&si.timestamps); err != nil {
+		return err
+	}
+	for i := range si.metadata {
+		if err := si.decompress(&si.metadata[i].column); err != nil {
+			return err
+		}
+		si.stats.AddDecompressedStructuredMetadataBytes(int64(si.metadata[i].buf.Len()))
+	}
+	return nil
+}
+
+func (si *columnarIterator) decompress(c *column) error {
+	reader, err := si.pool.GetReader(bytes.NewReader(c.compressed))
+	if err != nil {
+		return err
+	}
+	defer si.pool.PutReader(reader)
+
+	c.buf = serializeBytesBufferPool.Get().(*bytes.Buffer)
+	c.buf.Reset()
+	if _, err := c.buf.ReadFrom(reader); err != nil {
+		return errors.Wrap(err, "decompressing column")
+	}
+	c.decompressed = true
+	c.d = decbuf{b: c.buf.Bytes()}
+
+	si.stats.AddCompressedBytes(int64(len(c.compressed)))
+	si.stats.AddDecompressedBytes(int64(c.buf.Len()))
+	return nil
+}
+
+func (si *columnarIterator) Next() bool {
+	if si.closed {
+		return false
+	}
+
+	if !si.initialized {
+		si.initialized = true
+		if err := si.init(); err != nil {
+			si.err = err
+			si.Close()
+			return false
+		}
+	}
+
+	if si.cur == si.entries {
+		si.Close()
+		return false
+	}
+	si.cur++
+
+	si.currTs += si.timestamps.d.varint64()
+	if err := si.timestamps.d.err(); err != nil {
+		si.err = errors.Wrap(err, "decoding timestamp")
+		si.Close()
+		return false
+	}
+
+	si.symbolsBuf = si.symbolsBuf[:0]
+	for i := range si.metadata {
+		col := &si.metadata[i]
+		v := col.d.uvarint64()
+		if err := col.d.err(); err != nil {
+			si.err = errors.Wrap(err, "decoding structured metadata")
+			si.Close()
+			return false
+		}
+		if v > 0 {
+			si.symbolsBuf = append(si.symbolsBuf, symbol{Name: col.name, Value: uint32(v - 1)})
+		}
+	}
+	si.currStructuredMetadata = si.symbolizer.Lookup(si.symbolsBuf, si.currStructuredMetadata)
+
+	si.currLine = nil
+	if si.readsLine {
+		line, ok := si.line()
+		if !ok {
+			si.Close()
+			return false
+		}
+		si.currLine = line
+	}
+
+	si.stats.AddDecompressedLines(1)
+	return true
+}
+
+// line returns the log line of the current entry, decompressing the log lines
+// on the first call.
+func (si *columnarIterator) line() ([]byte, bool) {
+	if !si.lines.decompressed {
+		if err := si.decompress(&si.lines); err != nil {
+			si.err = err
+			return nil, false
+		}
+	}
+
+	var line []byte
+	for si.linesRead < si.cur {
+		lineSize := si.lines.d.uvarint()
+		if lineSize >= maxLineLength {
+			si.err = fmt.Errorf("line too long %d, maximum %d", lineSize, maxLineLength)
+			return nil, false
+		}
+		line = si.lines.d.bytes(lineSize)
+		si.linesRead++
+	}
+	if err := si.lines.d.err(); err != nil {
+		si.err = errors.Wrap(err, "decoding log line")
+		return nil, false
+	}
+	return line, true
+}
+
+// hash returns the hash of the log line of the current entry.
+func (si *columnarIterator) hash() (uint64, bool) {
+	if !si.hashes.decompressed {
+		if err := si.decompress(&si.hashes); err != nil {
+			si.err = err
+			return 0, false
+		}
+	}
+
+	b := si.hashes.buf.Bytes()
+	if len(b) < si.cur*8 {
+		si.err = errors.Wrap(ErrInvalidSize, "decoding log line hash")
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(b[(si.cur-1)*8:]), true
+}
+
+func (si *columnarIterator) Err() error { return si.err }
+
+func (si *columnarIterator) Close() error {
+	if !si.closed {
+		si.closed = true
+		si.close()
+	}
+	return si.err
+}
+
+func (si *columnarIterator) close() {
+	for _, c := range []*column{&si.timestamps, &si.hashes, &si.lines} {
+		c.release()
+	}
+	for i := range si.metadata {
+		si.metadata[i].release()
+	}
+
+	si.symbolsBuf = nil
+
+	if si.currStructuredMetadata != nil {
+		structuredMetadataPool.Put(si.currStructuredMetadata) // nolint:staticcheck
+		si.currStructuredMetadata = nil
+	}
+
+	si.origBytes = nil
+}
+
+func (c *column) release() {
+	if c.buf != nil {
+		c.buf.Reset()
+		serializeBytesBufferPool.Put(c.buf)
+		c.buf = nil
+	}
+	c.d = decbuf{}
+}
+
+func newColumnarEntryIterator(ctx context.Context, pool compression.ReaderPool, b []byte, pipeline log.StreamPipeline, symbolizer *symbolizer) iter.EntryIterator {
+	return &columnarEntryIterator{
+		columnarIterator: newColumnarIterator(ctx, pool, b, symbolizer, log.ReadsLine(pipeline)),
+		pipeline:         pipeline,
+		stats:            stats.FromContext(ctx),
+	}
+}
+
+type columnarEntryIterator struct {
+	*columnarIterator
+	pipeline log.StreamPipeline
+	stats    *stats.Context
+
+	cur        logproto.Entry
+	currLabels log.LabelsResult
+}
+
+func (e *columnarEntryIterator) At() logproto.Entry {
+	return e.cur
+}
+
+func (e *columnarEntryIterator) Labels() string { return e.currLabels.String() }
+
+func (e *columnarEntryIterator) StreamHash() uint64 { return e.pipeline.BaseLabels().Hash() }
+
+func (e *columnarEntryIterator) Next() bool {
+	for e.columnarIterator.Next() {
+		newLine, lbs, matches := e.pipeline.Process(e.currTs, e.currLine, e.currStructuredMetadata...)
+		if !matches {
+			continue
+		}
+		if !e.readsLine {
+			// The pipeline returns the log line unchanged, so it is only read
+			// for the matching entries.
+			line, ok := e.line()
+			if !ok {
+				e.Close()
+				return false
+			}
+			newLine = line
+		}
+
+		e.stats.AddPostFilterLines(1)
+		e.currLabels = lbs
+		e.cur.Timestamp = time.Unix(0, e.currTs)
+		e.cur.Line = string(newLine)
+		e.cur.StructuredMetadata = logproto.FromLabelsToLabelAdapters(lbs.StructuredMetadata())
+		e.cur.Parsed = logproto.FromLabelsToLabelAdapters(lbs.Parsed())
+
+		return true
+	}
+	return false
+}
+
+func (e *columnarEntryIterator) Close() error {
+	if e.pipeline.ReferencedStructuredMetadata() {
+		e.stats.SetQueryReferencedStructuredMetadata()
+	}
+
+	return e.columnarIterator.Close()
+}
+
+func newColumnarSampleIterator(ctx context.Context, pool compression.ReaderPool, b []byte, extractor log.StreamSampleExtractor, symbolizer *symbolizer) iter.SampleIterator {
+	return &columnarSampleIterator{
+		columnarIterator: newColumnarIterator(ctx, pool, b, symbolizer, log.ReadsLine(extractor)),
+		extractor:        extractor,
+		stats:            stats.FromContext(ctx),
+	}
+}
+
+type columnarSampleIterator struct {
+	*columnarIterator
+
+	extractor log.StreamSampleExtractor
+	stats     *stats.Context
+
+	cur        logproto.Sample
+	currLabels log.LabelsResult
+}
+
+func (e *columnarSampleIterator) Next() bool {
+	for e.columnarIterator.Next() {
+		val, labels, ok := e.extractor.Process(e.currTs, e.currLine, e.currStructuredMetadata...)
+		if !ok {
+			continue
+		}
+		if e.readsLine {
+			e.cur.Hash = xxhash.Sum64(e.currLine)
+		} else if e.cur.Hash, ok = e.hash(); !ok {
+			e.Close()
+			return false
+		}
+
+		e.stats.AddPostFilterLines(1)
+		e.currLabels = labels
+		e.cur.Value = val
+		e.cur.Timestamp = e.currTs
+		return true
+	}
+	return false
+}
+
+func (e *columnarSampleIterator) Close() error {
+	if e.extractor.ReferencedStructuredMetadata() {
+		e.stats.SetQueryReferencedStructuredMetadata()
+	}
+
+	return e.columnarIterator.Close()
+}
+
+func (e *columnarSampleIterator) Labels() string { return e.currLabels.String() }
+
+func (e *columnarSampleIterator) StreamHash() uint64 { return e.extractor.BaseLabels().Hash() }
+
+func (e *columnarSampleIterator) At() logproto.Sample {
+	return e.cur
+}
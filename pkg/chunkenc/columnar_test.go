@@ -0,0 +1,135 @@
+package chunkenc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+func TestColumnarBlocks(t *testing.T) {
+	streamLabels := labels.FromStrings("app", "foo")
+	newChunk := func(format byte) *MemChunk {
+		chk := NewMemChunk(format, compression.Snappy, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, testTargetSize)
+		for i := 0; i < 100; i++ {
+			entry := &logproto.Entry{
+				Timestamp: time.Unix(0, int64(i)),
+				Line:      fmt.Sprintf("level=info msg=%q", fmt.Sprintf("request %d", i)),
+			}
+			if i%2 == 0 {
+				entry.StructuredMetadata = push.LabelsAdapter{{Name: "trace_id", Value: fmt.Sprint(i % 10)}}
+			}
+			if i%5 == 0 {
+				// repeated names are kept.
+				entry.StructuredMetadata = append(entry.StructuredMetadata, push.LabelAdapter{Name: "user", Value: "a"}, push.LabelAdapter{Name: "user", Value: "b"})
+			}
+			_, err := chk.Append(entry)
+			require.NoError(t, err)
+		}
+		require.NoError(t, chk.Close())
+
+		b, err := chk.Bytes()
+		require.NoError(t, err)
+		chk, err = NewByteChunk(b, testBlockSize, testTargetSize)
+		require.NoError(t, err)
+		return chk
+	}
+	v4, v5 := newChunk(ChunkFormatV4), newChunk(ChunkFormatV5)
+
+	for _, query := range []string{
+		`{app="foo"}`,
+		`{app="foo"} | trace_id="2"`,
+		`{app="foo"} | user="b"`,
+		`{app="foo"} |= "request 1" | trace_id!=""`,
+		`{app="foo"} | logfmt | msg="request 20"`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			expr, err := syntax.ParseLogSelector(query, true)
+			require.NoError(t, err)
+			pipeline, err := expr.Pipeline()
+			require.NoError(t, err)
+
+			entries := func(chk *MemChunk, direction logproto.Direction) []logproto.Entry {
+				it, err := chk.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), direction, pipeline.ForStream(streamLabels))
+				require.NoError(t, err)
+				defer it.Close()
+
+				var res []logproto.Entry
+				for it.Next() {
+					res = append(res, it.At())
+				}
+				require.NoError(t, it.Err())
+				return res
+			}
+			expected := entries(v4, logproto.FORWARD)
+			require.NotEmpty(t, expected)
+			require.Equal(t, expected, entries(v5, logproto.FORWARD))
+			require.Equal(t, entries(v4, logproto.BACKWARD), entries(v5, logproto.BACKWARD))
+
+			sampleExpr, err := syntax.ParseSampleExpr(fmt.Sprintf(`count_over_time(%s [1m])`, query))
+			require.NoError(t, err)
+			extractor, err := sampleExpr.Extractor()
+			require.NoError(t, err)
+
+			samples := func(chk *MemChunk) []logproto.Sample {
+				it := chk.SampleIterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), extractor.ForStream(streamLabels))
+				defer it.Close()
+
+				var res []logproto.Sample
+				for it.Next() {
+					res = append(res, it.At())
+				}
+				require.NoError(t, it.Err())
+				return res
+			}
+			// the hashes of the samples match, so they are deduplicated with the ones of the other formats.
+			require.Equal(t, samples(v4), samples(v5))
+		})
+	}
+}
+
+func TestColumnarBlocks_SkipLines(t *testing.T) {
+	streamLabels := labels.FromStrings("app", "foo")
+	chk := NewMemChunk(ChunkFormatV5, compression.Snappy, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, testTargetSize)
+	for i := 0; i < 1000; i++ {
+		_, err := chk.Append(&logproto.Entry{
+			Timestamp:          time.Unix(0, int64(i)),
+			Line:               fmt.Sprintf("a long enough log line to be worth skipping %d", i),
+			StructuredMetadata: push.LabelsAdapter{{Name: "trace_id", Value: fmt.Sprint(i)}},
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, chk.Close())
+
+	decompressedBytes := func(query string) int64 {
+		expr, err := syntax.ParseSampleExpr(query)
+		require.NoError(t, err)
+		extractor, err := expr.Extractor()
+		require.NoError(t, err)
+
+		sts, ctx := stats.NewContext(context.Background())
+		it := chk.SampleIterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), extractor.ForStream(streamLabels))
+		count := 0
+		for it.Next() {
+			count++
+		}
+		require.NoError(t, it.Close())
+		require.Equal(t, 1, count)
+		return sts.Result(0, 0, 0).TotalDecompressedBytes()
+	}
+
+	withoutLines := decompressedBytes(`count_over_time({app="foo"} | trace_id="42" [1m])`)
+	withLines := decompressedBytes(`count_over_time({app="foo"} |= "skipping 42" | trace_id="42" [1m])`)
+	require.Less(t, withoutLines, withLines/2)
+}
@@ -32,6 +32,8 @@ const (
 	ChunkFormatV2
 	ChunkFormatV3
 	ChunkFormatV4
+	// ChunkFormatV5 is ChunkFormatV4 with columnar blocks, see columnar.go.
+	ChunkFormatV5
 
 	blocksPerChunk = 10
 	maxLineLength  = 1024 * 1024 * 1024
@@ -43,6 +45,7 @@ const (
 
 	chunkMetasSectionIdx              = 1
 	chunkStructuredMetadataSectionIdx = 2
+	chunkBlockSummariesSectionIdx     = 3
 )
 
 var HeadBlockFmts = []HeadBlockFmt{OrderedHeadBlockFmt, UnorderedHeadBlockFmt, UnorderedWithStructuredMetadataHeadBlockFmt}
@@ -134,6 +137,8 @@ type MemChunk struct {
 	format   byte
 	encoding compression.Codec
 	headFmt  HeadBlockFmt
+	// The zstd dictionary of the ZstdDict encoding, 0 meaning no dictionary.
+	zstdDictionary uint32
 
 	// compressed size of chunk. Set when chunk is cut or while decoding chunk from storage.
 	compressedSize int
@@ -148,6 +153,8 @@ type block struct {
 
 	offset           int // The offset of the block in the chunk.
 	uncompressedSize int // Total uncompressed size in bytes when the chunk is cut.
+
+	summary *blockSummary // The summary of the block, for chunk format v5+.
 }
 
 // This block holds the un-compressed entries. Once it has enough data, this is
@@ -359,13 +366,21 @@ func NewMemChunk(chunkFormat byte, enc compression.Codec, head HeadBlockFmt, blo
 	return newMemChunkWithFormat(chunkFormat, enc, head, blockSize, targetSize)
 }
 
+// NewZstdDictMemChunk returns a new in-mem chunk compressed with the ZstdDict
+// codec and the zstd dictionary, 0 meaning no dictionary.
+func NewZstdDictMemChunk(chunkFormat byte, dictionary uint32, head HeadBlockFmt, blockSize, targetSize int) *MemChunk {
+	c := newMemChunkWithFormat(chunkFormat, compression.ZstdDict, head, blockSize, targetSize)
+	c.zstdDictionary = dictionary
+	return c
+}
+
 func panicIfInvalidFormat(chunkFmt byte, head HeadBlockFmt) {
 	if chunkFmt == ChunkFormatV2 && head != OrderedHeadBlockFmt {
 		panic("only OrderedHeadBlockFmt is supported for V2 chunks")
 	}
-	if chunkFmt == ChunkFormatV4 && head != UnorderedWithStructuredMetadataHeadBlockFmt {
+	if chunkFmt >= ChunkFormatV4 && head != UnorderedWithStructuredMetadataHeadBlockFmt {
 		fmt.Println("received head fmt", head.String())
-		panic("only UnorderedWithStructuredMetadataHeadBlockFmt is supported for V4 chunks")
+		panic("only UnorderedWithStructuredMetadataHeadBlockFmt is supported for V4 and V5 chunks")
 	}
 }
 
@@ -415,13 +430,23 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 	switch version {
 	case ChunkFormatV1:
 		bc.encoding = compression.GZIP
-	case ChunkFormatV2, ChunkFormatV3, ChunkFormatV4:
+	case ChunkFormatV2, ChunkFormatV3, ChunkFormatV4, ChunkFormatV5:
 		// format v2+ has a byte for block encoding.
 		enc := compression.Codec(db.byte())
 		if db.err() != nil {
 			return nil, errors.Wrap(db.err(), "verifying encoding")
 		}
 		bc.encoding = enc
+		if enc == compression.ZstdDict {
+			// the ZstdDict encoding is followed by the ID of the zstd dictionary.
+			bc.zstdDictionary = db.be32()
+			if db.err() != nil {
+				return nil, errors.Wrap(db.err(), "verifying zstd dictionary")
+			}
+			if err := compression.LoadZstdDictionary(bc.zstdDictionary); err != nil {
+				return nil, err
+			}
+		}
 	default:
 		return nil, errors.Errorf("invalid version %d", version)
 	}
@@ -457,6 +482,19 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 		// version 1 writes blocks after version number while version 2 and 3 write blocks after chunk encoding
 		expectedBlockOffset = len(b) - len(db.b)
 	}
+	var summaries []*blockSummary
+	if version >= ChunkFormatV5 {
+		summariesLength, summariesOffset := readSectionLenAndOffset(chunkBlockSummariesSectionIdx)
+		sb := b[summariesOffset : summariesOffset+summariesLength]
+		if binary.BigEndian.Uint32(b[summariesOffset+summariesLength:]) != crc32.Checksum(sb, castagnoliTable) {
+			return nil, ErrInvalidChecksum
+		}
+		var err error
+		if summaries, err = decodeBlockSummaries(sb); err != nil {
+			return nil, err
+		}
+	}
+
 	mb := b[metasOffset : metasOffset+metasLen]
 	db = decbuf{b: mb}
 
@@ -513,6 +551,9 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 		// next block starts at current block start + current block length + checksum
 		expectedBlockOffset = blk.offset + l + 4
 		blk.b = b[blk.offset : blk.offset+l]
+		if i < len(summaries) {
+			blk.summary = summaries[i]
+		}
 		bc.blocks = append(bc.blocks, blk)
 
 		// Update the counter used to track the size of cut blocks.
@@ -536,7 +577,7 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 		if fromCheckpoint {
 			bc.symbolizer = symbolizerFromCheckpoint(lb)
 		} else {
-			symbolizer, err := symbolizerFromEnc(lb, compression.GetReaderPool(bc.encoding))
+			symbolizer, err := symbolizerFromEnc(lb, bc.readerPool())
 			if err != nil {
 				return nil, err
 			}
@@ -571,6 +612,9 @@ func (c *MemChunk) BytesSize() int {
 	if c.format > ChunkFormatV1 {
 		size++ // chunk format v2+ has a byte for encoding.
 	}
+	if c.encoding == compression.ZstdDict {
+		size += 4 // zstd dictionary
+	}
 
 	// blocks
 	for _, b := range c.blocks {
@@ -586,6 +630,16 @@ func (c *MemChunk) BytesSize() int {
 		size += binary.MaxVarintLen32 // len(b)
 	}
 
+	if c.format >= ChunkFormatV5 {
+		size += binary.MaxVarintLen32 // len blocks
+		for _, b := range c.blocks {
+			size += b.summary.encodedSize()
+		}
+		size += crc32.Size // block summaries crc
+
+		size += 8 + 8 // block summaries offset and length
+	}
+
 	// blockmeta
 	size += binary.MaxVarintLen32 // len  blocks
 
@@ -632,6 +686,9 @@ func (c *MemChunk) writeTo(w io.Writer, forCheckpoint bool) (int64, error) {
 		// chunk format v2+ has a byte for encoding.
 		eb.putByte(byte(c.encoding))
 	}
+	if c.encoding == compression.ZstdDict {
+		eb.putBE32(c.zstdDictionary)
+	}
 
 	n, err := w.Write(eb.get())
 	if err != nil {
@@ -654,7 +711,11 @@ func (c *MemChunk) writeTo(w io.Writer, forCheckpoint bool) (int64, error) {
 			}
 		} else {
 			var err error
-			n, crcHash, err = c.symbolizer.SerializeTo(w, compression.GetWriterPool(c.encoding))
+			pool, err := c.writerPool()
+			if err != nil {
+				return offset, err
+			}
+			n, crcHash, err = c.symbolizer.SerializeTo(w, pool)
 			if err != nil {
 				return offset, errors.Wrap(err, "write structured metadata")
 			}
@@ -686,6 +747,24 @@ func (c *MemChunk) writeTo(w io.Writer, forCheckpoint bool) (int64, error) {
 		offset += int64(n)
 	}
 
+	summariesOffset := offset
+	summariesLength := 0
+	if c.format >= ChunkFormatV5 {
+		eb.reset()
+		eb.putUvarint(len(c.blocks))
+		for _, b := range c.blocks {
+			b.summary.encode(eb)
+		}
+		summariesLength = len(eb.get())
+		eb.putHash(crc32Hash)
+
+		n, err := w.Write(eb.get())
+		if err != nil {
+			return offset, errors.Wrap(err, "write block summaries")
+		}
+		offset += int64(n)
+	}
+
 	metasOffset := offset
 	// Write the number of blocks.
 	eb.reset()
@@ -711,6 +790,18 @@ func (c *MemChunk) writeTo(w io.Writer, forCheckpoint bool) (int64, error) {
 	}
 	offset += int64(n)
 
+	if c.format >= ChunkFormatV5 {
+		// Write block summaries offset and length
+		eb.reset()
+		eb.putBE64int(summariesLength)
+		eb.putBE64int(int(summariesOffset))
+		n, err = w.Write(eb.get())
+		if err != nil {
+			return offset, errors.Wrap(err, "write block summaries offset and length")
+		}
+		offset += int64(n)
+	}
+
 	if c.format >= ChunkFormatV4 {
 		// Write structured metadata offset and length
 		eb.reset()
@@ -942,7 +1033,7 @@ func (c *MemChunk) cut() error {
 		return nil
 	}
 
-	b, err := c.head.Serialise(compression.GetWriterPool(c.encoding))
+	b, summary, err := c.serialiseHead()
 	if err != nil {
 		return err
 	}
@@ -954,6 +1045,7 @@ func (c *MemChunk) cut() error {
 		mint:             mint,
 		maxt:             maxt,
 		uncompressedSize: c.head.UncompressedSize(),
+		summary:          summary,
 	})
 
 	c.cutBlockSize += len(b)
@@ -962,6 +1054,41 @@ func (c *MemChunk) cut() error {
 	return nil
 }
 
+// serialiseHead serialises the head into a block, and returns its summary for
+// chunk format v5+.
+func (c *MemChunk) serialiseHead() ([]byte, *blockSummary, error) {
+	pool, err := c.writerPool()
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.format < ChunkFormatV5 {
+		b, err := c.head.Serialise(pool)
+		return b, nil, err
+	}
+
+	head, err := c.head.Convert(UnorderedWithStructuredMetadataHeadBlockFmt, c.symbolizer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return head.(*unorderedHeadBlock).serialiseColumnar(pool)
+}
+
+// writerPool returns the writer pool of the encoding of the chunk.
+func (c *MemChunk) writerPool() (compression.WriterPool, error) {
+	if c.encoding == compression.ZstdDict {
+		return compression.GetZstdDictWriterPool(c.zstdDictionary)
+	}
+	return compression.GetWriterPool(c.encoding), nil
+}
+
+// readerPool returns the reader pool of the encoding of the chunk.
+func (c *MemChunk) readerPool() compression.ReaderPool {
+	if c.encoding == compression.ZstdDict {
+		return compression.GetZstdDictReaderPool(c.zstdDictionary)
+	}
+	return compression.GetReaderPool(c.encoding)
+}
+
 // Bounds implements Chunk.
 func (c *MemChunk) Bounds() (fromT, toT time.Time) {
 	from, to := c.head.Bounds()
@@ -993,6 +1120,8 @@ func (c *MemChunk) Iterator(ctx context.Context, mintT, maxtT time.Time, directi
 	}
 	var headIterator iter.EntryIterator
 
+	lineContents, structuredMetadata := log.RequiredLineContents(pipeline), log.RequiredStructuredMetadata(pipeline)
+	pool := c.readerPool()
 	var lastMax int64 // placeholder to check order across blocks
 	ordered := true
 	for _, b := range c.blocks {
@@ -1001,13 +1130,17 @@ func (c *MemChunk) Iterator(ctx context.Context, mintT, maxtT time.Time, directi
 		if maxt < b.mint || b.maxt < mint {
 			continue
 		}
+		// skip the blocks without the log lines kept by the pipeline
+		if !b.summary.matches(c.symbolizer, lineContents, structuredMetadata) {
+			continue
+		}
 
 		if b.mint < lastMax {
 			ordered = false
 		}
 		lastMax = b.maxt
 
-		blockItrs = append(blockItrs, encBlock{c.encoding, c.format, c.symbolizer, b}.Iterator(ctx, pipeline))
+		blockItrs = append(blockItrs, encBlock{pool, c.format, c.symbolizer, b}.Iterator(ctx, pipeline))
 	}
 
 	if !c.head.IsEmpty() {
@@ -1077,6 +1210,8 @@ func (c *MemChunk) SampleIterator(ctx context.Context, from, through time.Time,
 		stats.AddDecompressedStructuredMetadataBytes(decompressedSize)
 	}
 
+	lineContents, structuredMetadata := log.RequiredLineContents(extractor), log.RequiredStructuredMetadata(extractor)
+	pool := c.readerPool()
 	var lastMax int64 // placeholder to check order across blocks
 	ordered := true
 	for _, b := range c.blocks {
@@ -1084,12 +1219,16 @@ func (c *MemChunk) SampleIterator(ctx context.Context, from, through time.Time,
 		if maxt < b.mint || b.maxt < mint {
 			continue
 		}
+		// skip the blocks without the log lines kept by the extractor
+		if !b.summary.matches(c.symbolizer, lineContents, structuredMetadata) {
+			continue
+		}
 
 		if b.mint < lastMax {
 			ordered = false
 		}
 		lastMax = b.maxt
-		its = append(its, encBlock{c.encoding, c.format, c.symbolizer, b}.SampleIterator(ctx, extractor))
+		its = append(its, encBlock{pool, c.format, c.symbolizer, b}.SampleIterator(ctx, extractor))
 	}
 
 	if !c.head.IsEmpty() {
@@ -1118,10 +1257,11 @@ func (c *MemChunk) SampleIterator(ctx context.Context, from, through time.Time,
 func (c *MemChunk) Blocks(mintT, maxtT time.Time) []Block {
 	mint, maxt := mintT.UnixNano(), maxtT.UnixNano()
 	blocks := make([]Block, 0, len(c.blocks))
+	pool := c.readerPool()
 
 	for _, b := range c.blocks {
 		if maxt >= b.mint && b.maxt >= mint {
-			blocks = append(blocks, encBlock{c.encoding, c.format, c.symbolizer, b})
+			blocks = append(blocks, encBlock{pool, c.format, c.symbolizer, b})
 		}
 	}
 	return blocks
@@ -1146,6 +1286,7 @@ func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, err
 		// For target chunk size I am using compressed size of original chunk since the newChunk should anyways be lower in size than that.
 		newChunk = NewMemChunk(c.format, c.Encoding(), c.headFmt, defaultBlockSize, c.CompressedSize())
 	}
+	newChunk.zstdDictionary = c.zstdDictionary
 
 	for itr.Next() {
 		entry := itr.At()
@@ -1173,7 +1314,7 @@ func (c *MemChunk) Rebound(start, end time.Time, filter filter.Func) (Chunk, err
 // then allows us to bind a decoding context to a block when requested, but otherwise helps reduce the
 // chances of chunk<>block encoding drift in the codebase as the latter is parameterized by the former.
 type encBlock struct {
-	enc        compression.Codec
+	pool       compression.ReaderPool
 	format     byte
 	symbolizer *symbolizer
 	block
@@ -1183,14 +1324,20 @@ func (b encBlock) Iterator(ctx context.Context, pipeline log.StreamPipeline) ite
 	if len(b.b) == 0 {
 		return iter.NoopEntryIterator
 	}
-	return newEntryIterator(ctx, compression.GetReaderPool(b.enc), b.b, pipeline, b.format, b.symbolizer)
+	if b.format >= ChunkFormatV5 {
+		return newColumnarEntryIterator(ctx, b.pool, b.b, pipeline, b.symbolizer)
+	}
+	return newEntryIterator(ctx, b.pool, b.b, pipeline, b.format, b.symbolizer)
 }
 
 func (b encBlock) SampleIterator(ctx context.Context, extractor log.StreamSampleExtractor) iter.SampleIterator {
 	if len(b.b) == 0 {
 		return iter.NoopSampleIterator
 	}
-	return newSampleIterator(ctx, compression.GetReaderPool(b.enc), b.b, b.format, extractor, b.symbolizer)
+	if b.format >= ChunkFormatV5 {
+		return newColumnarSampleIterator(ctx, b.pool, b.b, extractor, b.symbolizer)
+	}
+	return newSampleIterator(ctx, b.pool, b.b, b.format, extractor, b.symbolizer)
 }
 
 func (b block) Offset() int {
@@ -42,6 +42,7 @@ var testEncodings = []compression.Codec{
 	compression.Snappy,
 	compression.Flate,
 	compression.Zstd,
+	compression.ZstdDict,
 }
 
 var (
@@ -75,6 +76,10 @@ var (
 			headBlockFmt: UnorderedWithStructuredMetadataHeadBlockFmt,
 			chunkFormat:  ChunkFormatV4,
 		},
+		{
+			headBlockFmt: UnorderedWithStructuredMetadataHeadBlockFmt,
+			chunkFormat:  ChunkFormatV5,
+		},
 	}
 )
 
@@ -2097,6 +2102,16 @@ func TestDecodeChunkIncorrectBlockOffset(t *testing.T) {
 					_, err = w.Write(eb.get())
 					require.NoError(t, err)
 
+					if chk.format >= ChunkFormatV5 {
+						// Write block summaries offset and length
+						eb.reset()
+
+						eb.putBE64int(int(binary.BigEndian.Uint64(b[len(b)-48:])))
+						eb.putBE64int(int(binary.BigEndian.Uint64(b[len(b)-40:])))
+						_, err = w.Write(eb.get())
+						require.NoError(t, err)
+					}
+
 					if chk.format >= ChunkFormatV4 {
 						// Write structured metadata offset and length
 						eb.reset()
@@ -2149,3 +2164,62 @@ func TestDecodeChunkIncorrectBlockOffset(t *testing.T) {
 		})
 	}
 }
+
+func TestZstdDictMemChunk(t *testing.T) {
+	line := func(i int) string {
+		return fmt.Sprintf(`level=info caller=http.go:194 component=frontend msg="request completed" status=200 duration=%dms`, i)
+	}
+	var samples [][]byte
+	for i := 0; i < 100; i++ {
+		samples = append(samples, []byte(line(i)))
+	}
+	dict, err := compression.BuildZstdDictionary(200001, samples, 4<<10)
+	require.NoError(t, err)
+
+	// the dictionary must be registered to write the chunks.
+	chk := NewZstdDictMemChunk(ChunkFormatV4, 200001, UnorderedWithStructuredMetadataHeadBlockFmt, testBlockSize, testTargetSize)
+	_, err = chk.Append(&logproto.Entry{Timestamp: time.Unix(0, 1), Line: line(1)})
+	require.NoError(t, err)
+	require.ErrorIs(t, chk.Close(), compression.ErrUnknownZstdDictionary)
+
+	// the dictionary must be registered to read the chunks.
+	b := []byte{0, 0, 0, 0}
+	binary.BigEndian.PutUint32(b, magicNumber)
+	b = append(b, ChunkFormatV4, byte(compression.ZstdDict), 0, 3, 13, 65)
+	_, err = NewByteChunk(b, testBlockSize, testTargetSize)
+	require.ErrorIs(t, err, compression.ErrUnknownZstdDictionary)
+
+	_, err = compression.RegisterZstdDictionary(dict)
+	require.NoError(t, err)
+
+	for _, f := range allPossibleFormats {
+		t.Run(fmt.Sprintf("chunkFormat:%v headBlockFmt:%v", f.chunkFormat, f.headBlockFmt), func(t *testing.T) {
+			chk := NewZstdDictMemChunk(f.chunkFormat, 200001, f.headBlockFmt, testBlockSize, testTargetSize)
+			for i := 1; i < 1000; i++ {
+				_, err := chk.Append(&logproto.Entry{Timestamp: time.Unix(0, int64(i)), Line: line(i)})
+				require.NoError(t, err)
+			}
+			require.NoError(t, chk.Close())
+
+			b, err := chk.Bytes()
+			require.NoError(t, err)
+			decoded, err := NewByteChunk(b, testBlockSize, testTargetSize)
+			require.NoError(t, err)
+			require.Equal(t, compression.ZstdDict, decoded.Encoding())
+			require.Equal(t, uint32(200001), decoded.zstdDictionary)
+
+			rebound, err := decoded.Rebound(time.Unix(0, 0), time.Unix(0, 10), nil)
+			require.NoError(t, err)
+			require.Equal(t, uint32(200001), rebound.(*MemChunk).zstdDictionary)
+
+			it, err := decoded.Iterator(context.Background(), time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, noopStreamPipeline)
+			require.NoError(t, err)
+			i := 1
+			for ; it.Next(); i++ {
+				require.Equal(t, line(i), it.At().Line)
+			}
+			require.NoError(t, it.Close())
+			require.Equal(t, 1000, i)
+		})
+	}
+}
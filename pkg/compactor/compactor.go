@@ -91,6 +91,8 @@ type Config struct {
 	RunOnce                     bool                `yaml:"_" doc:"hidden"`
 	TablesToCompact             int                 `yaml:"tables_to_compact"`
 	SkipLatestNTables           int                 `yaml:"skip_latest_n_tables"`
+
+	ZstdDictionaryTraining ZstdDictionaryTrainingConfig `yaml:"zstd_dictionary_training"`
 }
 
 // RegisterFlags registers flags.
@@ -114,6 +116,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.SkipLatestNTables, "compactor.skip-latest-n-tables", 0, "Do not compact N latest tables. Together with -compactor.run-once and -compactor.tables-to-compact, this is useful when clearing compactor backlogs.")
 
 	cfg.RetentionBackoffConfig.RegisterFlagsWithPrefix("compactor.retention-backoff-config", f)
+	cfg.ZstdDictionaryTraining.RegisterFlagsWithPrefix("compactor.zstd-dictionary-training", f)
 	// Ring
 	skipFlags := []string{
 		"compactor.ring.num-tokens",
@@ -157,7 +160,7 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
-	return nil
+	return cfg.ZstdDictionaryTraining.Validate()
 }
 
 type Compactor struct {
@@ -196,6 +199,7 @@ type storeContainer struct {
 	tableMarker        retention.TableMarker
 	sweeper            *retention.Sweeper
 	indexStorageClient storage.Client
+	dictionaryTrainer  *zstdDictionaryTrainer
 }
 
 type Limits interface {
@@ -284,6 +288,10 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 		}
 	}
 
+	if c.cfg.ZstdDictionaryTraining.Enabled && c.cfg.ZstdDictionaryTraining.Store == nil {
+		return fmt.Errorf("zstd dictionaries store not initialised when zstd dictionary training is enabled")
+	}
+
 	legacyMarkerDirs := make(map[string]struct{})
 	c.storeContainers = make(map[config.DayTime]storeContainer, len(objectStoreClients))
 	for from, objectClient := range objectStoreClients {
@@ -295,10 +303,12 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 		var sc storeContainer
 		sc.indexStorageClient = storage.NewIndexStorageClient(objectClient, period.IndexTables.PathPrefix)
 
+		if c.cfg.ZstdDictionaryTraining.Enabled {
+			sc.dictionaryTrainer = newZstdDictionaryTrainer(c.cfg.ZstdDictionaryTraining, newChunkClient(objectClient, schemaConfig))
+		}
+
 		if c.cfg.RetentionEnabled {
 			var (
-				raw              client.ObjectClient
-				encoder          client.KeyEncoder
 				name             = fmt.Sprintf("%s_%s", period.ObjectType, period.From.String())
 				retentionWorkDir = filepath.Join(c.cfg.WorkingDirectory, "retention", name)
 				r                = prometheus.WrapRegistererWith(prometheus.Labels{"from": name}, r)
@@ -317,15 +327,7 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 			// remove markers from the store dir after copying them to period specific dirs.
 			legacyMarkerDirs[period.ObjectType] = struct{}{}
 
-			if casted, ok := objectClient.(client.PrefixedObjectClient); ok {
-				raw = casted.GetDownstream()
-			} else {
-				raw = objectClient
-			}
-			if _, ok := raw.(*local.FSObjectClient); ok {
-				encoder = client.FSEncoder
-			}
-			chunkClient := client.NewClient(objectClient, encoder, schemaConfig)
+			chunkClient := newChunkClient(objectClient, schemaConfig)
 
 			sc.sweeper, err = retention.NewSweeper(retentionWorkDir, chunkClient, c.cfg.RetentionDeleteWorkCount, c.cfg.RetentionDeleteDelay, c.cfg.RetentionBackoffConfig, r)
 			if err != nil {
@@ -358,6 +360,22 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 	return nil
 }
 
+func newChunkClient(objectClient client.ObjectClient, schemaConfig config.SchemaConfig) client.Client {
+	var (
+		raw     client.ObjectClient
+		encoder client.KeyEncoder
+	)
+	if casted, ok := objectClient.(client.PrefixedObjectClient); ok {
+		raw = casted.GetDownstream()
+	} else {
+		raw = objectClient
+	}
+	if _, ok := raw.(*local.FSObjectClient); ok {
+		encoder = client.FSEncoder
+	}
+	return client.NewClient(objectClient, encoder, schemaConfig)
+}
+
 func (c *Compactor) initDeletes(objectClient client.ObjectClient, r prometheus.Registerer, limits Limits) error {
 	deletionWorkDir := filepath.Join(c.cfg.WorkingDirectory, "deletion")
 	store, err := deletion.NewDeleteStore(deletionWorkDir, storage.NewIndexStorageClient(objectClient, c.cfg.DeleteRequestStoreKeyPrefix))
@@ -650,6 +668,7 @@ func (c *Compactor) CompactTable(ctx context.Context, tableName string, applyRet
 		level.Error(util_log.Logger).Log("msg", "failed to initialize table for compaction", "table", tableName, "err", err)
 		return err
 	}
+	table.dictionaryTrainer = sc.dictionaryTrainer
 
 	interval := retention.ExtractIntervalFromTableName(tableName)
 	intervalMayHaveExpiredChunks := false
@@ -74,6 +74,7 @@ type table struct {
 	indexStorageClient storage.Client
 	indexCompactor     IndexCompactor
 	tableMarker        retention.TableMarker
+	dictionaryTrainer  *zstdDictionaryTrainer
 	expirationChecker  tableExpirationChecker
 	periodConfig       config.PeriodConfig
 
@@ -174,6 +175,10 @@ func (t *table) compact(applyRetention bool) error {
 		return err
 	}
 
+	if t.dictionaryTrainer != nil {
+		t.trainZstdDictionaries()
+	}
+
 	if applyRetention {
 		err := t.applyRetention()
 		if err != nil {
@@ -0,0 +1,164 @@
+package compactor
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"math"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	lokilog "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/dictionaries"
+)
+
+var errEnoughChunks = errors.New("enough chunks")
+
+// ZstdDictionaryTrainingConfig configures the training of the zstd dictionaries
+// of the zstd-dict chunk encoding by the compactor.
+type ZstdDictionaryTrainingConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	Interval       time.Duration `yaml:"interval"`
+	MaxChunks      int           `yaml:"max_chunks"`
+	MaxSampleBytes int           `yaml:"max_sample_bytes"`
+	DictionarySize int           `yaml:"dictionary_size"`
+
+	// Store is the store of the dictionaries, set by the module.
+	Store *dictionaries.Store `yaml:"-"`
+}
+
+func (cfg *ZstdDictionaryTrainingConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Train a zstd dictionary per tenant from the log lines of its chunks, for the zstd-dict chunk encoding. Requires the zstd dictionaries store to be configured. The dictionaries contain some of the log lines of the tenant, and are kept after the retention or the delete requests removed those log lines.")
+	f.DurationVar(&cfg.Interval, prefix+".interval", 24*time.Hour, "Interval at which a new version of the zstd dictionary of each tenant is trained. The dictionaries are trained while compacting the tables.")
+	f.IntVar(&cfg.MaxChunks, prefix+".max-chunks", 100, "Maximum number of chunks of a tenant read to train its zstd dictionary.")
+	f.IntVar(&cfg.MaxSampleBytes, prefix+".max-sample-bytes", 10<<20, "Maximum size of the log lines of a tenant used to train its zstd dictionary.")
+	f.IntVar(&cfg.DictionarySize, prefix+".dictionary-size", 64<<10, "Maximum size of the zstd dictionaries.")
+}
+
+func (cfg *ZstdDictionaryTrainingConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		return errors.New("the zstd dictionary training interval must be greater than 0")
+	}
+	if cfg.MaxChunks <= 0 || cfg.MaxSampleBytes <= 0 || cfg.DictionarySize <= 0 {
+		return errors.New("the zstd dictionary training max chunks, max sample bytes and dictionary size must be greater than 0")
+	}
+	return nil
+}
+
+// zstdDictionaryTrainer trains the zstd dictionaries of the tenants from the
+// log lines of the chunks of their compacted index.
+type zstdDictionaryTrainer struct {
+	cfg         ZstdDictionaryTrainingConfig
+	chunkClient client.Client
+}
+
+func newZstdDictionaryTrainer(cfg ZstdDictionaryTrainingConfig, chunkClient client.Client) *zstdDictionaryTrainer {
+	return &zstdDictionaryTrainer{
+		cfg:         cfg,
+		chunkClient: chunkClient,
+	}
+}
+
+// train trains a new version of the zstd dictionary of the tenant from the
+// chunks of the index, when its newest version is older than the interval.
+func (t *zstdDictionaryTrainer) train(ctx context.Context, userID string, index retention.ChunkIterator, logger log.Logger) error {
+	versions, err := t.cfg.Store.Versions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(versions) > 0 && time.Since(versions[len(versions)-1].CreatedAt) < t.cfg.Interval {
+		return nil
+	}
+
+	var chunks []chunk.Chunk
+	err = index.ForEachChunk(ctx, func(ce retention.ChunkEntry) (bool, error) {
+		c, err := chunk.ParseExternalKey(userID, string(ce.ChunkID))
+		if err != nil {
+			return false, err
+		}
+		chunks = append(chunks, c)
+		if len(chunks) >= t.cfg.MaxChunks {
+			return false, errEnoughChunks
+		}
+		return false, nil
+	})
+	if err != nil && !errors.Is(err, errEnoughChunks) {
+		return err
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	chunks, err = t.chunkClient.GetChunks(ctx, chunks)
+	if err != nil {
+		return err
+	}
+	var (
+		samples [][]byte
+		size    int
+	)
+	for _, c := range chunks {
+		if size >= t.cfg.MaxSampleBytes {
+			break
+		}
+		facade, ok := c.Data.(*chunkenc.Facade)
+		if !ok {
+			continue
+		}
+		it, err := facade.LokiChunk().Iterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, lokilog.NewNoopPipeline().ForStream(labels.Labels{}))
+		if err != nil {
+			return err
+		}
+		for it.Next() && size < t.cfg.MaxSampleBytes {
+			line := it.At().Line
+			samples = append(samples, []byte(line))
+			size += len(line)
+		}
+		if err := it.Close(); err != nil {
+			return err
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	id, err := t.cfg.Store.NewID(ctx)
+	if err != nil {
+		return err
+	}
+	dict, err := compression.BuildZstdDictionary(id, samples, t.cfg.DictionarySize)
+	if err != nil {
+		return err
+	}
+	version, err := t.cfg.Store.Add(ctx, userID, dict, time.Now())
+	if err != nil {
+		return err
+	}
+	level.Info(logger).Log("msg", "trained zstd dictionary", "id", version.ID, "size", len(dict), "chunks", len(chunks), "samples", len(samples))
+	return nil
+}
+
+// trainZstdDictionaries trains the zstd dictionaries of the tenants with a
+// compacted index in the table. A failed training doesn't fail the compaction.
+func (t *table) trainZstdDictionaries() {
+	for userID, is := range t.indexSets {
+		if userID == "" || is.compactedIndex == nil {
+			continue
+		}
+		if err := t.dictionaryTrainer.train(t.ctx, userID, is.compactedIndex, is.logger); err != nil {
+			level.Error(is.logger).Log("msg", "failed to train zstd dictionary", "err", err)
+		}
+	}
+}
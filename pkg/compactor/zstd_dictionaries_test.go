@@ -0,0 +1,117 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/dictionaries"
+)
+
+type chunkEntries []retention.ChunkEntry
+
+func (c chunkEntries) ForEachChunk(_ context.Context, callback retention.ChunkEntryCallback) error {
+	for _, entry := range c {
+		if _, err := callback(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestZstdDictionaryTrainer(t *testing.T) {
+	ctx := context.Background()
+	schemaCfg := config.SchemaConfig{
+		Configs: []config.PeriodConfig{
+			{
+				From:       config.DayTime{Time: 0},
+				IndexType:  "tsdb",
+				ObjectType: "inmemory",
+				Schema:     "v13",
+				IndexTables: config.IndexPeriodicTableConfig{
+					PeriodicTableConfig: config.PeriodicTableConfig{
+						Prefix: "index_",
+						Period: time.Hour * 24,
+					}},
+			},
+		},
+	}
+	chunkClient := client.NewClient(testutils.NewInMemoryObjectClient(), nil, schemaCfg)
+
+	lbs := labels.FromStrings("app", "foo")
+	var entries chunkEntries
+	for i := 0; i < 3; i++ {
+		memChunk := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, compression.Snappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 256*1024, 0)
+		for j := 0; j < 100; j++ {
+			_, err := memChunk.Append(&logproto.Entry{
+				Timestamp: time.Unix(int64(i*100+j), 0),
+				Line:      fmt.Sprintf(`level=info caller=http.go:194 msg="request completed" duration=%dms`, i*100+j),
+			})
+			require.NoError(t, err)
+		}
+		require.NoError(t, memChunk.Close())
+		from, through := memChunk.Bounds()
+		c := chunk.NewChunk("tenant-a", model.Fingerprint(lbs.Hash()), lbs, chunkenc.NewFacade(memChunk, 0, 0), model.TimeFromUnixNano(from.UnixNano()), model.TimeFromUnixNano(through.UnixNano()))
+		require.NoError(t, c.Encode())
+		require.NoError(t, chunkClient.PutChunks(ctx, []chunk.Chunk{c}))
+
+		entries = append(entries, retention.ChunkEntry{
+			ChunkRef: retention.ChunkRef{
+				UserID:  []byte("tenant-a"),
+				ChunkID: []byte(schemaCfg.ExternalKey(c.ChunkRef)),
+				From:    c.From,
+				Through: c.Through,
+			},
+			Labels: lbs,
+		})
+	}
+
+	store := dictionaries.NewStore(dictionaries.Config{
+		KeyPrefix:       "zstd-dictionaries/",
+		RefreshInterval: time.Hour,
+		ObjectClient:    testutils.NewInMemoryObjectClient(),
+	}, log.NewNopLogger())
+	trainer := newZstdDictionaryTrainer(ZstdDictionaryTrainingConfig{
+		Enabled:        true,
+		Interval:       time.Hour,
+		MaxChunks:      2,
+		MaxSampleBytes: 10 << 20,
+		DictionarySize: 1 << 10,
+		Store:          store,
+	}, chunkClient)
+
+	require.NoError(t, trainer.train(ctx, "tenant-a", entries, log.NewNopLogger()))
+	versions, err := store.Versions(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+
+	// the chunks are compressed with the trained dictionary.
+	_, err = compression.GetZstdDictWriterPool(versions[0].ID)
+	require.NoError(t, err)
+
+	// the dictionary is only trained again once the interval elapsed.
+	require.NoError(t, trainer.train(ctx, "tenant-a", entries, log.NewNopLogger()))
+	versions, err = store.Versions(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+
+	// no chunks, no dictionary.
+	require.NoError(t, trainer.train(ctx, "tenant-b", chunkEntries{}, log.NewNopLogger()))
+	versions, err = store.Versions(ctx, "tenant-b")
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
@@ -0,0 +1,331 @@
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	zstdlib "github.com/klauspost/compress/zstd"
+)
+
+// ErrUnknownZstdDictionary is returned when a zstd dictionary is neither
+// registered nor found by the loader.
+var ErrUnknownZstdDictionary = errors.New("unknown zstd dictionary")
+
+// ZstdDictionaryLoader loads the zstd dictionaries which are not registered,
+// typically from the object store.
+type ZstdDictionaryLoader func(id uint32) ([]byte, error)
+
+// zstdDictionaryIdleTimeout is how long the zstd dictionaries which can be
+// loaded again are kept once they are not used anymore.
+const zstdDictionaryIdleTimeout = time.Hour
+
+// zstdDictionaryRegistry holds the zstd dictionaries known by the process. The
+// data compressed with a dictionary references it by ID, so the registry is
+// shared by all the pools of the ZstdDict codec. Each dictionary has its own
+// pools of readers and writers.
+type zstdDictionaryRegistry struct {
+	mtx          sync.RWMutex
+	dictionaries map[uint32]*zstdDictionary
+	loader       ZstdDictionaryLoader
+	// evictedAt is the last time the idle dictionaries were evicted.
+	evictedAt time.Time
+	now       func() time.Time
+}
+
+type zstdDictionary struct {
+	readers zstdDictReaderPool
+	writers zstdDictWriterPool
+	// lastUsed is the last time the pools were got, in nanoseconds since the
+	// UNIX epoch.
+	lastUsed atomic.Int64
+}
+
+var zstdDictionaries = zstdDictionaryRegistry{
+	dictionaries: map[uint32]*zstdDictionary{},
+	now:          time.Now,
+}
+
+// RegisterZstdDictionary registers the zstd dictionary, so that the ZstdDict
+// codec can compress with it and decompress the data compressed with it. It
+// returns the ID of the dictionary.
+func RegisterZstdDictionary(dict []byte) (uint32, error) {
+	d, err := zstdlib.InspectDictionary(dict)
+	if err != nil {
+		return 0, fmt.Errorf("invalid zstd dictionary: %w", err)
+	}
+	id := d.ID()
+	if id == 0 {
+		return 0, errors.New("invalid zstd dictionary: the ID must not be 0")
+	}
+
+	zstdDictionaries.mtx.Lock()
+	defer zstdDictionaries.mtx.Unlock()
+	if _, ok := zstdDictionaries.dictionaries[id]; !ok {
+		dictionary := &zstdDictionary{
+			readers: zstdDictReaderPool{dict: dict},
+			writers: zstdDictWriterPool{dict: dict},
+		}
+		dictionary.lastUsed.Store(zstdDictionaries.now().UnixNano())
+		zstdDictionaries.dictionaries[id] = dictionary
+	}
+	return id, nil
+}
+
+// SetZstdDictionaryLoader sets the loader of the zstd dictionaries which are
+// not registered yet.
+func SetZstdDictionaryLoader(loader ZstdDictionaryLoader) {
+	zstdDictionaries.mtx.Lock()
+	defer zstdDictionaries.mtx.Unlock()
+	zstdDictionaries.loader = loader
+}
+
+// LoadZstdDictionary makes sure the zstd dictionary is registered, loading it
+// with the loader otherwise. The ID 0 means no dictionary.
+func LoadZstdDictionary(id uint32) error {
+	_, err := getZstdDictionary(id)
+	return err
+}
+
+// getZstdDictionary returns the registered zstd dictionary, loading it with the
+// loader if needed, and marks it as used.
+func getZstdDictionary(id uint32) (*zstdDictionary, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	zstdDictionaries.evictIdle()
+
+	zstdDictionaries.mtx.RLock()
+	dictionary, ok := zstdDictionaries.dictionaries[id]
+	loader := zstdDictionaries.loader
+	zstdDictionaries.mtx.RUnlock()
+	if ok {
+		dictionary.lastUsed.Store(zstdDictionaries.now().UnixNano())
+		return dictionary, nil
+	}
+	if loader == nil {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownZstdDictionary, id)
+	}
+
+	dict, err := loader(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load zstd dictionary %d: %w", id, err)
+	}
+	loaded, err := RegisterZstdDictionary(dict)
+	if err != nil {
+		return nil, err
+	}
+	if loaded != id {
+		return nil, fmt.Errorf("loaded zstd dictionary %d has the ID %d", id, loaded)
+	}
+
+	zstdDictionaries.mtx.RLock()
+	defer zstdDictionaries.mtx.RUnlock()
+	return zstdDictionaries.dictionaries[id], nil
+}
+
+// evictIdle forgets the dictionaries not used for zstdDictionaryIdleTimeout,
+// along with their pools, at most once per minute. The dictionaries are only
+// evicted when they can be loaded again.
+func (r *zstdDictionaryRegistry) evictIdle() {
+	now := r.now()
+
+	r.mtx.RLock()
+	skip := r.loader == nil || now.Sub(r.evictedAt) < time.Minute
+	r.mtx.RUnlock()
+	if skip {
+		return
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.evictedAt = now
+	for id, dictionary := range r.dictionaries {
+		if now.Sub(time.Unix(0, dictionary.lastUsed.Load())) >= zstdDictionaryIdleTimeout {
+			delete(r.dictionaries, id)
+		}
+	}
+}
+
+// GetZstdDictWriterPool returns the pool of the writers of the ZstdDict codec
+// compressing with the zstd dictionary, which is loaded if it is not
+// registered. The ID 0 means no dictionary.
+func GetZstdDictWriterPool(id uint32) (WriterPool, error) {
+	dictionary, err := getZstdDictionary(id)
+	if err != nil {
+		return nil, err
+	}
+	if dictionary == nil {
+		return &zstdDict, nil
+	}
+	return &dictionary.writers, nil
+}
+
+// GetZstdDictReaderPool returns the pool of the readers of the ZstdDict codec
+// decompressing the data compressed with the zstd dictionary, which is loaded
+// if it is not registered. The ID 0 means no dictionary. If the dictionary
+// can't be loaded, the readers of the returned pool fail.
+func GetZstdDictReaderPool(id uint32) ReaderPool {
+	dictionary, err := getZstdDictionary(id)
+	if err != nil {
+		return zstdDictErrorPool{err: err}
+	}
+	if dictionary == nil {
+		return &zstdDict
+	}
+	return &dictionary.readers
+}
+
+// BuildZstdDictionary builds a zstd dictionary of at most size bytes from the
+// samples, typically log lines. The most frequent samples are kept verbatim in
+// the dictionary content, so the dictionary must be stored like the samples.
+func BuildZstdDictionary(id uint32, samples [][]byte, size int) (dict []byte, err error) {
+	defer func() {
+		// BuildDict panics when the dictionary content has all the samples.
+		if r := recover(); r != nil {
+			dict, err = nil, fmt.Errorf("failed to build zstd dictionary: %v", r)
+		}
+	}()
+
+	counts := map[string]int{}
+	for _, s := range samples {
+		counts[string(s)]++
+	}
+	unique := make([]string, 0, len(counts))
+	for s := range counts {
+		unique = append(unique, s)
+	}
+	// The end of the content is the cheapest to reference, so it gets the most
+	// frequent samples.
+	slices.SortFunc(unique, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return len(a) - len(b)
+	})
+	var (
+		kept []string
+		n    int
+	)
+	for _, s := range unique {
+		if n+len(s) > size {
+			continue
+		}
+		kept = append(kept, s)
+		n += len(s)
+	}
+	history := make([]byte, 0, n)
+	for i := len(kept) - 1; i >= 0; i-- {
+		history = append(history, kept[i]...)
+	}
+
+	return zstdlib.BuildDict(zstdlib.BuildDictOptions{
+		ID:       id,
+		Contents: samples,
+		History:  history,
+		Offsets:  [3]int{1, 4, 8},
+		Level:    zstdlib.SpeedDefault,
+	})
+}
+
+// ZstdDictPool is the compression pool of the ZstdDict codec, which compresses
+// and decompresses without dictionary, see GetZstdDictWriterPool and
+// GetZstdDictReaderPool for the pools using a zstd dictionary.
+type ZstdDictPool struct{}
+
+// GetReader gets or creates a new CompressionReader and reset it to read from src
+func (pool *ZstdDictPool) GetReader(src io.Reader) (io.Reader, error) {
+	return zstd.GetReader(src)
+}
+
+// PutReader places back in the pool a CompressionReader
+func (pool *ZstdDictPool) PutReader(reader io.Reader) {
+	zstd.PutReader(reader)
+}
+
+// GetWriter gets or creates a new CompressionWriter compressing without
+// dictionary and reset it to write to dst
+func (pool *ZstdDictPool) GetWriter(dst io.Writer) io.WriteCloser {
+	return zstd.GetWriter(dst)
+}
+
+// PutWriter places back in the pool a CompressionWriter
+func (pool *ZstdDictPool) PutWriter(writer io.WriteCloser) {
+	zstd.PutWriter(writer)
+}
+
+// zstdDictReaderPool is the pool of the readers decompressing the data
+// compressed with a zstd dictionary.
+type zstdDictReaderPool struct {
+	dict    []byte
+	readers sync.Pool
+}
+
+// GetReader gets or creates a new CompressionReader and reset it to read from src
+func (pool *zstdDictReaderPool) GetReader(src io.Reader) (io.Reader, error) {
+	if r := pool.readers.Get(); r != nil {
+		reader := r.(*zstdlib.Decoder)
+		err := reader.Reset(src)
+		if err != nil {
+			return nil, err
+		}
+		return reader, nil
+	}
+	reader, err := zstdlib.NewReader(src, zstdlib.WithDecoderDicts(pool.dict))
+	if err != nil {
+		return nil, err
+	}
+	runtime.SetFinalizer(reader, (*zstdlib.Decoder).Close)
+	return reader, nil
+}
+
+// PutReader places back in the pool a CompressionReader
+func (pool *zstdDictReaderPool) PutReader(reader io.Reader) {
+	pool.readers.Put(reader)
+}
+
+// zstdDictErrorPool is the reader pool of a zstd dictionary which can't be
+// loaded.
+type zstdDictErrorPool struct {
+	err error
+}
+
+// GetReader returns the error of the dictionary.
+func (pool zstdDictErrorPool) GetReader(_ io.Reader) (io.Reader, error) {
+	return nil, pool.err
+}
+
+// PutReader does nothing, GetReader returns no readers.
+func (pool zstdDictErrorPool) PutReader(_ io.Reader) {}
+
+// zstdDictWriterPool is the pool of the writers compressing with a zstd
+// dictionary.
+type zstdDictWriterPool struct {
+	dict    []byte
+	writers sync.Pool
+}
+
+// GetWriter gets or creates a new CompressionWriter and reset it to write to dst
+func (pool *zstdDictWriterPool) GetWriter(dst io.Writer) io.WriteCloser {
+	if w := pool.writers.Get(); w != nil {
+		writer := w.(*zstdlib.Encoder)
+		writer.Reset(dst)
+		return writer
+	}
+
+	w, err := zstdlib.NewWriter(dst, zstdlib.WithEncoderDict(pool.dict))
+	if err != nil {
+		panic(err) // never happens, the dictionary is validated when registered.
+	}
+	return w
+}
+
+// PutWriter places back in the pool a CompressionWriter
+func (pool *zstdDictWriterPool) PutWriter(writer io.WriteCloser) {
+	pool.writers.Put(writer)
+}
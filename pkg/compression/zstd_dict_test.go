@@ -0,0 +1,154 @@
+package compression
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func compress(t *testing.T, pool WriterPool, data []byte) []byte {
+	var buf bytes.Buffer
+	w := pool.GetWriter(&buf)
+	defer pool.PutWriter(w)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func decompress(t *testing.T, pool ReaderPool, data []byte) []byte {
+	r, err := pool.GetReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	defer pool.PutReader(r)
+	res, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return res
+}
+
+func TestZstdDict(t *testing.T) {
+	var samples [][]byte
+	for i := 0; i < 1000; i++ {
+		samples = append(samples, []byte(fmt.Sprintf(`level=info ts=2024-01-01T00:00:%02dZ caller=http.go:194 component=frontend msg="request completed" status=200 duration=%dms`, i%60, i)))
+	}
+	dict, err := BuildZstdDictionary(100001, samples, 16<<10)
+	require.NoError(t, err)
+
+	data := []byte("plain")
+	require.Equal(t, data, decompress(t, GetReaderPool(ZstdDict), compress(t, GetWriterPool(ZstdDict), data)))
+
+	_, err = GetZstdDictWriterPool(100001)
+	require.ErrorIs(t, err, ErrUnknownZstdDictionary)
+
+	id, err := RegisterZstdDictionary(dict)
+	require.NoError(t, err)
+	require.Equal(t, uint32(100001), id)
+
+	pool, err := GetZstdDictWriterPool(id)
+	require.NoError(t, err)
+	line := []byte(`level=info ts=2024-01-02T00:00:00Z caller=http.go:194 component=frontend msg="request completed" status=200 duration=12ms`)
+	withDict := compress(t, pool, line)
+	require.Less(t, len(withDict), len(compress(t, GetWriterPool(Zstd), line))/2)
+	require.Equal(t, line, decompress(t, GetZstdDictReaderPool(id), withDict))
+	// data compressed without dictionary is readable by the readers of the dictionary.
+	require.Equal(t, data, decompress(t, GetZstdDictReaderPool(id), compress(t, GetWriterPool(ZstdDict), data)))
+
+	// the readers without dictionary don't know the dictionaries.
+	_, err = io.ReadAll(mustGetReader(t, GetReaderPool(ZstdDict), withDict))
+	require.Error(t, err)
+
+	// the readers of another dictionary don't know the dictionary.
+	other, err := BuildZstdDictionary(100004, [][]byte{[]byte("level=warn msg=\"slow query\""), []byte("level=warn msg=\"slow flush\"")}, 32)
+	require.NoError(t, err)
+	_, err = RegisterZstdDictionary(other)
+	require.NoError(t, err)
+	_, err = io.ReadAll(mustGetReader(t, GetZstdDictReaderPool(100004), withDict))
+	require.Error(t, err)
+
+	_, err = GetZstdDictReaderPool(100005).GetReader(bytes.NewReader(withDict))
+	require.ErrorIs(t, err, ErrUnknownZstdDictionary)
+}
+
+func mustGetReader(t *testing.T, pool ReaderPool, data []byte) io.Reader {
+	r, err := pool.GetReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	return r
+}
+
+func TestZstdDict_Loader(t *testing.T) {
+	dict, err := BuildZstdDictionary(100002, [][]byte{[]byte("level=debug msg=\"cache hit\""), []byte("level=debug msg=\"cache miss\"")}, 32)
+	require.NoError(t, err)
+
+	var loaded []uint32
+	SetZstdDictionaryLoader(func(id uint32) ([]byte, error) {
+		loaded = append(loaded, id)
+		if id == 100002 {
+			return dict, nil
+		}
+		return nil, fmt.Errorf("dictionary %d not found", id)
+	})
+	defer SetZstdDictionaryLoader(nil)
+
+	require.NoError(t, LoadZstdDictionary(0))
+	require.NoError(t, LoadZstdDictionary(100002))
+	require.NoError(t, LoadZstdDictionary(100002))
+	require.Error(t, LoadZstdDictionary(100003))
+	require.Equal(t, []uint32{100002, 100003}, loaded)
+
+	pool, err := GetZstdDictWriterPool(100002)
+	require.NoError(t, err)
+	line := []byte("level=debug msg=\"cache hit\"")
+	require.Equal(t, line, decompress(t, GetZstdDictReaderPool(100002), compress(t, pool, line)))
+}
+
+func TestZstdDict_EvictIdle(t *testing.T) {
+	dict, err := BuildZstdDictionary(100006, [][]byte{[]byte("level=info msg=\"compaction done\""), []byte("level=info msg=\"compaction started\"")}, 32)
+	require.NoError(t, err)
+
+	now := time.Now()
+	zstdDictionaries.now = func() time.Time { return now }
+	defer func() { zstdDictionaries.now = time.Now }()
+	registered := func(id uint32) bool {
+		zstdDictionaries.mtx.RLock()
+		defer zstdDictionaries.mtx.RUnlock()
+		_, ok := zstdDictionaries.dictionaries[id]
+		return ok
+	}
+
+	// without loader, the dictionaries can't be evicted.
+	_, err = RegisterZstdDictionary(dict)
+	require.NoError(t, err)
+	now = now.Add(2 * zstdDictionaryIdleTimeout)
+	require.NoError(t, LoadZstdDictionary(100006))
+	require.True(t, registered(100006))
+
+	var loaded int
+	SetZstdDictionaryLoader(func(id uint32) ([]byte, error) {
+		loaded++
+		if id == 100006 {
+			return dict, nil
+		}
+		return nil, fmt.Errorf("dictionary %d not found", id)
+	})
+	defer SetZstdDictionaryLoader(nil)
+
+	// the dictionaries used recently are kept.
+	now = now.Add(zstdDictionaryIdleTimeout / 2)
+	require.NoError(t, LoadZstdDictionary(100006))
+	now = now.Add(zstdDictionaryIdleTimeout / 2)
+	_, err = GetZstdDictWriterPool(100006)
+	require.NoError(t, err)
+	require.True(t, registered(100006))
+	require.Equal(t, 0, loaded)
+
+	// the idle dictionaries are evicted, and loaded again when needed.
+	now = now.Add(zstdDictionaryIdleTimeout)
+	require.Error(t, LoadZstdDictionary(100007))
+	require.False(t, registered(100006))
+	require.NoError(t, LoadZstdDictionary(100006))
+	require.True(t, registered(100006))
+	require.Equal(t, 2, loaded)
+}
@@ -0,0 +1,165 @@
+package distributor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const (
+	// sampleRateLabel is the structured metadata of the log lines kept by the
+	// adaptive sampling, set to the ratio of the log lines of the stream kept.
+	sampleRateLabel = "sample_rate"
+	// suppressedLinesLabel is the structured metadata of the summary log lines
+	// of the identical log lines suppressed by the adaptive sampling.
+	suppressedLinesLabel = "suppressed_lines"
+)
+
+// samplingResult are the log lines kept by the adaptive sampling of a stream
+// and the log lines it discarded.
+type samplingResult struct {
+	entries           []logproto.Entry
+	sampledLines      int
+	sampledBytes      int
+	deduplicatedLines int
+	deduplicatedBytes int
+}
+
+// sampleStream samples the log lines of the stream when its rate is above the
+// adaptive sampling rate threshold of the tenant, so that the rate of the kept
+// log lines is around the threshold. The rate is estimated from the rate store
+// by the adaptive sampler, which reports the rate of the kept log lines.
+// It returns whether the stream was sampled.
+func (d *Distributor) sampleStream(ctx context.Context, vContext validationContext, lbs labels.Labels, stream *logproto.Stream) bool {
+	cfg := vContext.adaptiveSampling
+	if !cfg.Enabled {
+		return false
+	}
+
+	rate, _ := d.rateStore.RateFor(vContext.userID, stream.Hash)
+	ratio := d.adaptiveSampler.Ratio(vContext.userID, stream.Hash, rate, int64(cfg.RateThreshold.Val()))
+	if ratio >= 1 {
+		return false
+	}
+
+	res := sampleEntries(stream.Entries, ratio, cfg.Deduplicate, vContext.allowStructuredMetadata)
+	stream.Entries = res.entries
+
+	d.sampledStreams.WithLabelValues(vContext.userID).Inc()
+	d.trackSampledData(ctx, vContext.userID, lbs, res.sampledLines, res.sampledBytes, validation.Sampled)
+	d.trackSampledData(ctx, vContext.userID, lbs, res.deduplicatedLines, res.deduplicatedBytes, validation.Deduplicated)
+	return true
+}
+
+func (d *Distributor) trackSampledData(ctx context.Context, tenantID string, lbs labels.Labels, lines, bytes int, reason string) {
+	if lines == 0 {
+		return
+	}
+	validation.DiscardedSamples.WithLabelValues(reason, tenantID).Add(float64(lines))
+	validation.DiscardedBytes.WithLabelValues(reason, tenantID).Add(float64(bytes))
+	if d.usageTracker != nil {
+		d.usageTracker.DiscardedBytesAdd(ctx, tenantID, reason, lbs, float64(bytes))
+	}
+}
+
+// duplicates are the identical log lines of a stream pushed together.
+type duplicates struct {
+	entry logproto.Entry
+	// count and bytes are the number and the size of the log lines identical to
+	// the entry, and last the timestamp of the last one.
+	count int
+	bytes int
+	last  time.Time
+}
+
+// sampleEntries keeps the given ratio of the log lines. When deduplicate is
+// set, the log lines identical to a previous log line are suppressed and
+// replaced by a summary log line. When tag is set, the kept log lines are
+// tagged with the sample rate structured metadata.
+//
+// The log lines are sampled by their content and timestamp, so that the same
+// log lines pushed again are sampled the same way.
+func sampleEntries(entries []logproto.Entry, ratio float64, deduplicate bool, tag bool) samplingResult {
+	var res samplingResult
+
+	groups := make([]duplicates, 0, len(entries))
+	var seen map[string]int
+	if deduplicate {
+		seen = make(map[string]int, len(entries))
+	}
+	for _, e := range entries {
+		if i, ok := seen[e.Line]; ok {
+			groups[i].count++
+			groups[i].bytes += len(e.Line)
+			groups[i].last = e.Timestamp
+			continue
+		}
+		if deduplicate {
+			seen[e.Line] = len(groups)
+		}
+		groups = append(groups, duplicates{entry: e})
+	}
+
+	sampleRate := strconv.FormatFloat(ratio, 'g', 3, 64)
+	var summaries []logproto.Entry
+	for _, g := range groups {
+		if !keepEntry(g.entry, ratio) {
+			res.sampledLines += 1 + g.count
+			res.sampledBytes += len(g.entry.Line) + g.bytes
+			continue
+		}
+
+		entry := g.entry
+		if tag {
+			entry.StructuredMetadata = append(entry.StructuredMetadata[:len(entry.StructuredMetadata):len(entry.StructuredMetadata)], logproto.LabelAdapter{Name: sampleRateLabel, Value: sampleRate})
+		}
+		res.entries = append(res.entries, entry)
+
+		if g.count == 0 {
+			continue
+		}
+		res.deduplicatedLines += g.count
+		res.deduplicatedBytes += g.bytes
+		summary := logproto.Entry{
+			Timestamp: g.last,
+			Line:      fmt.Sprintf("%d identical lines suppressed", g.count),
+		}
+		if tag {
+			summary.StructuredMetadata = []logproto.LabelAdapter{
+				{Name: sampleRateLabel, Value: sampleRate},
+				{Name: suppressedLinesLabel, Value: strconv.Itoa(g.count)},
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	// The summary log lines are added after the kept log lines, in the order
+	// of their timestamp.
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Timestamp.Before(summaries[j].Timestamp)
+	})
+	res.entries = append(res.entries, summaries...)
+	return res
+}
+
+func keepEntry(e logproto.Entry, ratio float64) bool {
+	if ratio >= 1 {
+		return true
+	}
+	var ts [8]byte
+	binary.LittleEndian.PutUint64(ts[:], uint64(e.Timestamp.UnixNano()))
+	h := xxhash.New()
+	_, _ = h.WriteString(e.Line)
+	_, _ = h.Write(ts[:])
+	return float64(h.Sum64()) < ratio*math.MaxUint64
+}
@@ -0,0 +1,150 @@
+package distributor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func Test_sampleEntries(t *testing.T) {
+	entry := func(ts int64, line string) logproto.Entry {
+		return logproto.Entry{Timestamp: time.Unix(0, ts), Line: line}
+	}
+
+	t.Run("deduplicate", func(t *testing.T) {
+		res := sampleEntries([]logproto.Entry{
+			entry(1, "panic: nil pointer"),
+			entry(2, "restarting"),
+			entry(3, "panic: nil pointer"),
+			entry(4, "panic: nil pointer"),
+		}, 1, true, true)
+
+		sampleRate := logproto.LabelAdapter{Name: sampleRateLabel, Value: "1"}
+		require.Equal(t, []logproto.Entry{
+			{Timestamp: time.Unix(0, 1), Line: "panic: nil pointer", StructuredMetadata: []logproto.LabelAdapter{sampleRate}},
+			{Timestamp: time.Unix(0, 2), Line: "restarting", StructuredMetadata: []logproto.LabelAdapter{sampleRate}},
+			{Timestamp: time.Unix(0, 4), Line: "2 identical lines suppressed", StructuredMetadata: []logproto.LabelAdapter{
+				sampleRate,
+				{Name: suppressedLinesLabel, Value: "2"},
+			}},
+		}, res.entries)
+		require.Equal(t, 2, res.deduplicatedLines)
+		require.Equal(t, 2*len("panic: nil pointer"), res.deduplicatedBytes)
+		require.Equal(t, 0, res.sampledLines)
+	})
+
+	t.Run("sample", func(t *testing.T) {
+		entries := make([]logproto.Entry, 0, 10000)
+		for i := 0; i < 10000; i++ {
+			entries = append(entries, entry(int64(i), fmt.Sprintf("request %d served", i)))
+		}
+
+		res := sampleEntries(entries, 0.25, true, false)
+		require.InDelta(t, 2500, len(res.entries), 250)
+		require.Equal(t, len(entries), len(res.entries)+res.sampledLines)
+		require.Equal(t, 0, res.deduplicatedLines)
+		for _, e := range res.entries {
+			require.Empty(t, e.StructuredMetadata)
+		}
+
+		// the same log lines are sampled the same way.
+		require.Equal(t, res, sampleEntries(entries, 0.25, true, false))
+	})
+}
+
+func TestDistributor_PushAdaptiveSampling(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.AllowStructuredMetadata = true
+	limits.DiscoverLogLevels = false
+	limits.AdaptiveSampling.Enabled = true
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	now := time.Now()
+	entries := make([]logproto.Entry, 0, 100)
+	for i := 0; i < 100; i++ {
+		entries = append(entries, logproto.Entry{Timestamp: now.Add(time.Duration(i)), Line: "panic: nil pointer"})
+	}
+	push := func() {
+		_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+			Labels:  `{app="crashloop"}`,
+			Entries: append([]logproto.Entry(nil), entries...),
+		}}})
+		require.NoError(t, err)
+	}
+
+	// the stream rate is below the threshold.
+	distributors[0].rateStore = &fakeRateStore{rate: int64(limits.AdaptiveSampling.RateThreshold.Val())}
+	push()
+	require.Len(t, ingester.Peek().Streams[0].Entries, 100)
+
+	// the stream rate is above the threshold.
+	distributors[0].rateStore = &fakeRateStore{rate: int64(limits.AdaptiveSampling.RateThreshold.Val()) + 1}
+	push()
+	got := ingester.pushed[len(ingester.pushed)-1].Streams[0].Entries
+	require.Len(t, got, 2)
+	require.Equal(t, "panic: nil pointer", got[0].Line)
+	require.Equal(t, "99 identical lines suppressed", got[1].Line)
+	require.Equal(t, now.Add(99), got[1].Timestamp)
+	require.Contains(t, got[1].StructuredMetadata, logproto.LabelAdapter{Name: suppressedLinesLabel, Value: "99"})
+	require.Equal(t, float64(1), testutil.ToFloat64(distributors[0].sampledStreams.WithLabelValues("test")))
+}
+
+func TestDistributor_PushAdaptiveSampling_RateUpdates(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.AdaptiveSampling.Enabled = true
+	limits.AdaptiveSampling.Deduplicate = false
+	require.NoError(t, limits.Validate())
+	threshold := int64(limits.AdaptiveSampling.RateThreshold.Val())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	now := time.Now()
+	entries := make([]logproto.Entry, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		entries = append(entries, logproto.Entry{Timestamp: now.Add(time.Duration(i)), Line: fmt.Sprintf("request %d served", i)})
+	}
+	pushes := 0
+	push := func(rate int64) int {
+		distributors[0].rateStore = &fakeRateStore{rate: rate}
+		_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+			Labels:  `{app="runaway"}`,
+			Entries: append([]logproto.Entry(nil), entries...),
+		}}})
+		require.NoError(t, err)
+
+		// the distributor returns once a quorum of the ingesters is pushed.
+		pushes++
+		require.Eventually(t, func() bool {
+			ingester.mu.Lock()
+			defer ingester.mu.Unlock()
+			return len(ingester.pushed) == 3*pushes
+		}, time.Second, 10*time.Millisecond)
+
+		ingester.mu.Lock()
+		defer ingester.mu.Unlock()
+		return len(ingester.pushed[len(ingester.pushed)-1].Streams[0].Entries)
+	}
+
+	sampled := push(4 * threshold)
+	require.InDelta(t, 250, sampled, 50)
+	// the updated rate is the rate of the log lines kept by the ingesters, so
+	// the stream stays sampled the same way.
+	require.Equal(t, sampled, push(threshold))
+	// the pushed rate goes below the threshold.
+	require.Equal(t, 1000, push(threshold/8))
+}
@@ -0,0 +1,24 @@
+package adaptivesampling
+
+import (
+	"flag"
+
+	"github.com/grafana/loki/v3/pkg/util/flagext"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled" json:"enabled" doc:"description=Sample the log lines of the streams whose rate is above the rate threshold instead of rejecting them once they reach the rate limits. The kept log lines are tagged with the 'sample_rate' structured metadata."`
+
+	// RateThreshold is the stream rate above which the stream is sampled.
+	// Expected to be in bytes.
+	RateThreshold flagext.ByteSize `yaml:"rate_threshold" json:"rate_threshold" doc:"description=Rate of a stream above which its log lines are sampled, so that the rate of the kept log lines is around the threshold."`
+
+	Deduplicate bool `yaml:"deduplicate" json:"deduplicate" doc:"description=Deduplicate the identical log lines of a sampled stream pushed together. The suppressed log lines are replaced by a 'N identical lines suppressed' summary log line."`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Sample the log lines of the streams whose rate is above the rate threshold instead of rejecting them once they reach the rate limits.")
+	cfg.RateThreshold.Set("1MB") //nolint:errcheck
+	fs.Var(&cfg.RateThreshold, prefix+".rate-threshold", "Rate of a stream above which its log lines are sampled, so that the rate of the kept log lines is around the threshold.")
+	fs.BoolVar(&cfg.Deduplicate, prefix+".deduplicate", true, "Deduplicate the identical log lines of a sampled stream pushed together.")
+}
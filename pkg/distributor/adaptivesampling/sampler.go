@@ -0,0 +1,109 @@
+package adaptivesampling
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// staleAfter is how long the sampling of a stream not pushed is remembered.
+	staleAfter = 10 * time.Minute
+	// pruneInterval is the interval at which the stale streams of a tenant are
+	// forgotten.
+	pruneInterval = time.Minute
+)
+
+// Sampler computes the ratio of the log lines of the streams of the tenants
+// to keep. The rates of the streams reported by the ingesters are the rates
+// of the log lines kept, so the rates of the sampled streams are scaled by
+// their ratio to estimate the rates of the pushed log lines. Otherwise, the
+// reported rate would drop to the threshold once a stream is sampled, and the
+// stream would stop being sampled at the next rate update.
+type Sampler struct {
+	mtx     sync.Mutex
+	tenants map[string]*tenantStreams
+	now     func() time.Time
+}
+
+// tenantStreams are the sampled streams of a tenant, by hash.
+type tenantStreams struct {
+	streams    map[uint64]*sampledStream
+	lastPruned time.Time
+}
+
+type sampledStream struct {
+	// rate is the last rate of the stream reported by the ingesters, and ratio
+	// the ratio of the log lines kept computed from it.
+	rate     int64
+	ratio    float64
+	lastSeen time.Time
+}
+
+func NewSampler() *Sampler {
+	return &Sampler{
+		tenants: make(map[string]*tenantStreams),
+		now:     time.Now,
+	}
+}
+
+// Ratio returns the ratio of the log lines of the stream of the tenant to
+// keep, given the rate of the stream reported by the ingesters, so that the
+// rate of the kept log lines is around the threshold. It returns 1 if the
+// stream is not sampled.
+func (s *Sampler) Ratio(tenant string, stream uint64, rate, threshold int64) float64 {
+	now := s.now()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	t := s.tenants[tenant]
+	if t != nil && now.Sub(t.lastPruned) >= pruneInterval {
+		t.prune(now.Add(-staleAfter))
+		t.lastPruned = now
+	}
+
+	var ss *sampledStream
+	if t != nil {
+		ss = t.streams[stream]
+	}
+	switch {
+	case ss == nil:
+		if rate <= threshold {
+			s.removeIfEmpty(tenant, t)
+			return 1
+		}
+		if t == nil {
+			t = &tenantStreams{streams: make(map[uint64]*sampledStream), lastPruned: now}
+			s.tenants[tenant] = t
+		}
+		ss = &sampledStream{rate: rate, ratio: float64(threshold) / float64(rate)}
+		t.streams[stream] = ss
+	case rate != ss.rate:
+		// The rate was updated since the ratio was computed: it is the rate of
+		// the log lines kept with the ratio.
+		estimated := float64(rate) / ss.ratio
+		if estimated <= float64(threshold) {
+			delete(t.streams, stream)
+			s.removeIfEmpty(tenant, t)
+			return 1
+		}
+		ss.rate, ss.ratio = rate, float64(threshold)/estimated
+	}
+	ss.lastSeen = now
+	return ss.ratio
+}
+
+func (s *Sampler) removeIfEmpty(tenant string, t *tenantStreams) {
+	if t != nil && len(t.streams) == 0 {
+		delete(s.tenants, tenant)
+	}
+}
+
+// prune forgets the streams not pushed since the given time.
+func (t *tenantStreams) prune(since time.Time) {
+	for hash, ss := range t.streams {
+		if ss.lastSeen.Before(since) {
+			delete(t.streams, hash)
+		}
+	}
+}
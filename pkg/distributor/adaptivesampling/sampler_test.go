@@ -0,0 +1,51 @@
+package adaptivesampling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampler(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewSampler()
+	s.now = func() time.Time { return now }
+
+	require.Equal(t, 1.0, s.Ratio("fake", 1, 100, 100))
+	require.Empty(t, s.tenants)
+
+	// the stream rate is 4 times the threshold.
+	require.Equal(t, 0.25, s.Ratio("fake", 1, 400, 100))
+	require.Equal(t, 0.25, s.Ratio("fake", 1, 400, 100))
+	require.Equal(t, 1.0, s.Ratio("fake", 2, 50, 100))
+	require.Equal(t, 1.0, s.Ratio("other", 1, 50, 100))
+
+	// the updated rate is the rate of the kept log lines, so the stream stays
+	// sampled while the pushed rate is the same.
+	require.Equal(t, 0.25, s.Ratio("fake", 1, 100, 100))
+	// the pushed rate doubles.
+	require.Equal(t, 0.125, s.Ratio("fake", 1, 200, 100))
+	// the pushed rate goes below the threshold.
+	require.Equal(t, 1.0, s.Ratio("fake", 1, 10, 100))
+	require.Empty(t, s.tenants)
+	require.Equal(t, 1.0, s.Ratio("fake", 1, 10, 100))
+}
+
+func TestSamplerPrune(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewSampler()
+	s.now = func() time.Time { return now }
+
+	require.Equal(t, 0.5, s.Ratio("fake", 1, 200, 100))
+	require.Equal(t, 0.5, s.Ratio("fake", 2, 200, 100))
+
+	now = now.Add(staleAfter / 2)
+	require.Equal(t, 0.5, s.Ratio("fake", 2, 200, 100))
+
+	// the streams not pushed are forgotten.
+	now = now.Add(staleAfter / 2).Add(time.Second)
+	require.Equal(t, 0.5, s.Ratio("fake", 2, 100, 100))
+	require.Len(t, s.tenants["fake"].streams, 1)
+	require.Equal(t, 0.5, s.Ratio("fake", 1, 200, 100))
+}
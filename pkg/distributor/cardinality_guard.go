@@ -0,0 +1,78 @@
+package distributor
+
+import (
+	"net/http"
+
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/distributor/cardinalityguard"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// demoteLabels moves the labels of the stream with too many distinct values,
+// as detected by the cardinality guard of the tenant, to the structured
+// metadata of its log lines. It returns the labels of the stream.
+func (d *Distributor) demoteLabels(vContext validationContext, lbs labels.Labels, stream *logproto.Stream) labels.Labels {
+	cfg := vContext.cardinalityGuard
+	if !cfg.Enabled || !vContext.allowStructuredMetadata {
+		return lbs
+	}
+
+	demoted := d.cardinalityGuard.Observe(vContext.userID, cfg, lbs)
+	// A stream must keep at least one label.
+	if len(demoted) == 0 || len(demoted) == lbs.Len() {
+		return lbs
+	}
+
+	b := labels.NewBuilder(lbs)
+	metadata := make([]logproto.LabelAdapter, 0, len(demoted))
+	for _, name := range demoted {
+		b.Del(name)
+		metadata = append(metadata, logproto.LabelAdapter{Name: name, Value: lbs.Get(name)})
+	}
+	for i := range stream.Entries {
+		stream.Entries[i].StructuredMetadata = appendMissingMetadata(stream.Entries[i].StructuredMetadata, metadata)
+	}
+
+	lbs = b.Labels()
+	stream.Labels = lbs.String()
+	stream.Hash = lbs.Hash()
+	d.demotedLabelsStreams.WithLabelValues(vContext.userID).Inc()
+	return lbs
+}
+
+// appendMissingMetadata appends the structured metadata not already set.
+func appendMissingMetadata(metadata []logproto.LabelAdapter, add []logproto.LabelAdapter) []logproto.LabelAdapter {
+	for _, l := range add {
+		found := false
+		for _, m := range metadata {
+			if m.Name == l.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			metadata = append(metadata, l)
+		}
+	}
+	return metadata
+}
+
+// DemotedLabelsHandler lists the labels demoted to structured metadata by the
+// cardinality guard of the distributor for the tenant of the request.
+func (d *Distributor) DemotedLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	demoted := d.cardinalityGuard.DemotedLabels(tenantID)
+	if demoted == nil {
+		demoted = []cardinalityguard.DemotedLabel{}
+	}
+	writeJSONResponse(w, http.StatusOK, struct {
+		DemotedLabels []cardinalityguard.DemotedLabel `json:"demoted_labels"`
+	}{DemotedLabels: demoted})
+}
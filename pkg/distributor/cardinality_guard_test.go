@@ -0,0 +1,87 @@
+package distributor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestDistributor_PushCardinalityGuard(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.CardinalityGuard.Enabled = true
+	limits.CardinalityGuard.MaxLabelValues = 2
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	pushStream := func(requestID int) logproto.Stream {
+		_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+			Labels: fmt.Sprintf(`{app="api", request_id="%d"}`, requestID),
+			Entries: []logproto.Entry{{
+				Timestamp:          time.Now(),
+				Line:               "GET /",
+				StructuredMetadata: push.LabelsAdapter{{Name: "trace_id", Value: "1"}},
+			}},
+		}}})
+		require.NoError(t, err)
+
+		ingester.mu.Lock()
+		defer ingester.mu.Unlock()
+		return ingester.pushed[len(ingester.pushed)-1].Streams[0]
+	}
+
+	require.Equal(t, `{app="api", request_id="0"}`, pushStream(0).Labels)
+	require.Equal(t, `{app="api", request_id="1"}`, pushStream(1).Labels)
+
+	// the label has too many distinct values.
+	stream := pushStream(2)
+	require.Equal(t, `{app="api"}`, stream.Labels)
+	require.Equal(t, push.LabelsAdapter{{Name: "trace_id", Value: "1"}, {Name: "request_id", Value: "2"}}, stream.Entries[0].StructuredMetadata)
+	require.Equal(t, `{app="api"}`, pushStream(0).Labels)
+	require.Equal(t, float64(2), testutil.ToFloat64(distributors[0].demotedLabelsStreams.WithLabelValues("test")))
+
+	w := httptest.NewRecorder()
+	distributors[0].DemotedLabelsHandler(w, httptest.NewRequest(http.MethodGet, "/distributor/demoted_labels", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// only the labels of the tenant of the request are listed.
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/distributor/demoted_labels", nil)
+	distributors[0].DemotedLabelsHandler(w, r.WithContext(user.InjectOrgID(r.Context(), "other")))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"demoted_labels":[]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	distributors[0].DemotedLabelsHandler(w, r.WithContext(user.InjectOrgID(r.Context(), "test")))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		DemotedLabels []struct {
+			Tenant         string `json:"tenant"`
+			Name           string `json:"name"`
+			DistinctValues int    `json:"distinct_values"`
+		} `json:"demoted_labels"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.DemotedLabels, 1)
+	require.Equal(t, "test", resp.DemotedLabels[0].Tenant)
+	require.Equal(t, "request_id", resp.DemotedLabels[0].Name)
+	require.Equal(t, 2, resp.DemotedLabels[0].DistinctValues)
+}
@@ -0,0 +1,51 @@
+package cardinalityguard
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/prometheus/common/model"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled" json:"enabled" doc:"description=Count the distinct values of each label name of the pushed streams, and move the labels with too many distinct values from the stream labels to the structured metadata of the log lines, instead of creating new streams."`
+
+	MaxLabelValues int `yaml:"max_label_values" json:"max_label_values" doc:"description=Number of distinct values of a label name in the window above which the label is demoted to structured metadata."`
+
+	Window model.Duration `yaml:"window" json:"window" doc:"description=Window in which the distinct values of the label names are counted. A demoted label is restored once its number of distinct values stays below the threshold for the whole window."`
+
+	ExemptLabels flagext.StringSliceCSV `yaml:"exempt_labels" json:"exempt_labels" doc:"description=Comma separated list of the labels which are never demoted."`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Move the labels with too many distinct values from the stream labels to the structured metadata of the log lines.")
+	fs.IntVar(&cfg.MaxLabelValues, prefix+".max-label-values", 10000, "Number of distinct values of a label name in the window above which the label is demoted to structured metadata.")
+	cfg.Window = model.Duration(time.Hour)
+	fs.Var(&cfg.Window, prefix+".window", "Window in which the distinct values of the label names are counted.")
+	cfg.ExemptLabels = []string{"service_name"}
+	fs.Var(&cfg.ExemptLabels, prefix+".exempt-labels", "Comma separated list of the labels which are never demoted.")
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.MaxLabelValues <= 0 {
+		return errors.New("distributor.cardinality-guard.max-label-values must be greater than 0")
+	}
+	if cfg.Window <= 0 {
+		return errors.New("distributor.cardinality-guard.window must be greater than 0")
+	}
+	return nil
+}
+
+func (cfg *Config) exempt(name string) bool {
+	for _, l := range cfg.ExemptLabels {
+		if l == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package cardinalityguard
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// pruneFraction is the fraction of the window after which the values of a
+// label not seen in the window can be forgotten again.
+const pruneFraction = 10
+
+// DemotedLabel is a label demoted to structured metadata by the guard.
+type DemotedLabel struct {
+	Tenant string `json:"tenant"`
+	Name   string `json:"name"`
+	// DistinctValues is the number of distinct values of the label counted in
+	// the window, capped to the threshold.
+	DistinctValues int       `json:"distinct_values"`
+	DemotedAt      time.Time `json:"demoted_at"`
+	DemotedUntil   time.Time `json:"demoted_until"`
+}
+
+// Guard counts the distinct values of the label names of the streams of the
+// tenants, to detect the labels with too many distinct values.
+type Guard struct {
+	mtx     sync.Mutex
+	tenants map[string]map[string]*labelValues
+	now     func() time.Time
+}
+
+// labelValues are the distinct values of a label name of a tenant.
+type labelValues struct {
+	// values holds the last time each value was seen, up to the threshold.
+	values     map[string]time.Time
+	lastPruned time.Time
+
+	demotedAt    time.Time
+	demotedUntil time.Time
+}
+
+func NewGuard() *Guard {
+	return &Guard{
+		tenants: make(map[string]map[string]*labelValues),
+		now:     time.Now,
+	}
+}
+
+// Observe counts the label values of a stream of the tenant, and returns the
+// names of its labels which are demoted.
+func (g *Guard) Observe(tenant string, cfg Config, lbs labels.Labels) []string {
+	now := g.now()
+	window := time.Duration(cfg.Window)
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	tenantLabels, ok := g.tenants[tenant]
+	if !ok {
+		tenantLabels = make(map[string]*labelValues)
+		g.tenants[tenant] = tenantLabels
+	}
+
+	var demoted []string
+	lbs.Range(func(l labels.Label) {
+		if cfg.exempt(l.Name) {
+			return
+		}
+		lv, ok := tenantLabels[l.Name]
+		if !ok {
+			lv = &labelValues{values: make(map[string]time.Time), lastPruned: now}
+			tenantLabels[l.Name] = lv
+		}
+		if lv.observe(l.Value, now, window, cfg.MaxLabelValues) {
+			demoted = append(demoted, l.Name)
+		}
+	})
+	return demoted
+}
+
+// observe records a value of the label, and returns whether the label is
+// demoted.
+func (lv *labelValues) observe(value string, now time.Time, window time.Duration, maxValues int) bool {
+	_, seen := lv.values[value]
+	if !seen && len(lv.values) >= maxValues && now.Sub(lv.lastPruned) >= window/pruneFraction {
+		lv.prune(now.Add(-window))
+		lv.lastPruned = now
+	}
+
+	if seen || len(lv.values) < maxValues {
+		lv.values[value] = now
+	} else {
+		// The threshold is exceeded, the new value is not recorded so that the
+		// values of the label are bounded.
+		if !now.Before(lv.demotedUntil) {
+			lv.demotedAt = now
+		}
+		lv.demotedUntil = now.Add(window)
+	}
+	return now.Before(lv.demotedUntil)
+}
+
+// prune forgets the values not seen since the given time.
+func (lv *labelValues) prune(since time.Time) {
+	for value, seen := range lv.values {
+		if seen.Before(since) {
+			delete(lv.values, value)
+		}
+	}
+}
+
+// DemotedLabels returns the labels of the tenant currently demoted, or of all
+// the tenants if the tenant is empty.
+func (g *Guard) DemotedLabels(tenant string) []DemotedLabel {
+	now := g.now()
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	var res []DemotedLabel
+	for t, tenantLabels := range g.tenants {
+		if tenant != "" && t != tenant {
+			continue
+		}
+		for name, lv := range tenantLabels {
+			if !now.Before(lv.demotedUntil) {
+				continue
+			}
+			res = append(res, DemotedLabel{
+				Tenant:         t,
+				Name:           name,
+				DistinctValues: len(lv.values),
+				DemotedAt:      lv.demotedAt,
+				DemotedUntil:   lv.demotedUntil,
+			})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Tenant != res[j].Tenant {
+			return res[i].Tenant < res[j].Tenant
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
@@ -0,0 +1,48 @@
+package cardinalityguard
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard(t *testing.T) {
+	now := time.Unix(0, 0)
+	g := NewGuard()
+	g.now = func() time.Time { return now }
+	cfg := Config{Enabled: true, MaxLabelValues: 3, Window: model.Duration(time.Hour), ExemptLabels: []string{"service_name"}}
+
+	observe := func(id string) []string {
+		return g.Observe("fake", cfg, labels.FromStrings("service_name", "api"+id, "request_id", id, "env", "prod"))
+	}
+
+	for i := 0; i < 3; i++ {
+		require.Empty(t, observe(fmt.Sprint(i)))
+	}
+	// the known values do not exceed the threshold.
+	require.Empty(t, observe("0"))
+
+	require.Equal(t, []string{"request_id"}, observe("3"))
+	// the label stays demoted for the known values too.
+	require.Equal(t, []string{"request_id"}, observe("0"))
+	require.Equal(t, []DemotedLabel{{
+		Tenant:         "fake",
+		Name:           "request_id",
+		DistinctValues: 3,
+		DemotedAt:      now,
+		DemotedUntil:   now.Add(time.Hour),
+	}}, g.DemotedLabels(""))
+	require.Empty(t, g.DemotedLabels("other"))
+
+	// the label is restored once it has few values for the whole window.
+	now = now.Add(30 * time.Minute)
+	require.Equal(t, []string{"request_id"}, observe("0"))
+	require.Equal(t, []string{"request_id"}, observe("1"))
+	now = now.Add(31 * time.Minute)
+	require.Empty(t, observe("4"))
+	require.Empty(t, g.DemotedLabels("fake"))
+}
@@ -0,0 +1,299 @@
+package distributor
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/v3/pkg/distributor/deadletter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/util"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+const defaultDeadLetterLimit = 100
+
+// deadLetter stores the entries of the stream rejected for the reason in the
+// dead-letter store, if enabled for the tenant.
+func (d *Distributor) deadLetter(vContext validationContext, reason, labels string, entries []logproto.Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	if vContext.deadLetterReplay != nil {
+		vContext.deadLetterReplay.add(reason, labels, entries)
+		return
+	}
+	if d.deadLetterStore == nil || !vContext.deadLetter.Stored(reason) {
+		return
+	}
+	d.deadLetterStore.Add(vContext.userID, reason, labels, entries)
+}
+
+// deadLetterStreams stores the entries of the streams rejected for the reason
+// in the dead-letter store, if enabled for the tenant.
+func (d *Distributor) deadLetterStreams(vContext validationContext, reason string, streams []logproto.Stream) {
+	for _, stream := range streams {
+		d.deadLetter(vContext, reason, stream.Labels, stream.Entries)
+	}
+}
+
+// neverReplayed are the reasons of the rejected log lines which can't be
+// accepted later, so they are not replayed.
+var neverReplayed = map[string]struct{}{
+	validation.GreaterThanMaxSampleAge: {},
+	validation.TooFarBehind:            {},
+	validation.OutOfOrder:              {},
+}
+
+type deadLetterReplayKey struct{}
+
+// deadLetterReplay records the log lines rejected while replaying an object of
+// the dead-letter store, whatever the reasons stored for the tenant, so that
+// they are stored again before the object is deleted.
+type deadLetterReplay struct {
+	mtx     sync.Mutex
+	streams map[string]*logproto.Stream
+	entries int
+	// failed is set when the push failed after sending the log lines to the
+	// ingesters, whose rejections are not recorded.
+	failed bool
+}
+
+func newDeadLetterReplay() *deadLetterReplay {
+	return &deadLetterReplay{streams: make(map[string]*logproto.Stream)}
+}
+
+// deadLetterReplayFromContext returns the replay of the push request, if any.
+func deadLetterReplayFromContext(ctx context.Context) *deadLetterReplay {
+	replay, _ := ctx.Value(deadLetterReplayKey{}).(*deadLetterReplay)
+	return replay
+}
+
+func (r *deadLetterReplay) add(reason, labels string, entries []logproto.Entry) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	stream, ok := r.streams[labels]
+	if !ok {
+		stream = &logproto.Stream{Labels: strings.Clone(labels)}
+		r.streams[stream.Labels] = stream
+	}
+	for _, e := range entries {
+		stream.Entries = append(stream.Entries, deadletter.WithReason(e, reason))
+	}
+	r.entries += len(entries)
+}
+
+func (r *deadLetterReplay) fail() {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.failed = true
+}
+
+// request returns the recorded log lines as a request of the dead-letter store.
+func (r *deadLetterReplay) request() *logproto.PushRequest {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	req := &logproto.PushRequest{Streams: make([]logproto.Stream, 0, len(r.streams))}
+	for _, stream := range r.streams {
+		req.Streams = append(req.Streams, *stream)
+	}
+	return req
+}
+
+type deadLetterEntry struct {
+	Labels             string                  `json:"labels"`
+	Timestamp          time.Time               `json:"timestamp"`
+	Line               string                  `json:"line"`
+	StructuredMetadata []logproto.LabelAdapter `json:"structured_metadata,omitempty"`
+	Reason             string                  `json:"reason"`
+}
+
+// DeadLetterHandler lists the rejected log lines of the tenant stored in the
+// dead-letter store between the start and end parameters, optionally only the
+// ones rejected for the reason of the reason parameter, up to the limit
+// parameter.
+func (d *Distributor) DeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, objects, ok := d.deadLetterObjects(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeadLetterLimit
+	if v := r.FormValue("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l <= 0 {
+			http.Error(w, "invalid limit: "+v, http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+	reasonFilter := r.FormValue("reason")
+
+	entries := []deadLetterEntry{}
+objects:
+	for _, o := range objects {
+		req, err := d.deadLetterStore.Read(r.Context(), o.Key)
+		if err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to read rejected log lines", "tenant", tenantID, "key", o.Key, "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, stream := range req.Streams {
+			for _, e := range stream.Entries {
+				reason := deadletter.RemoveReason(&e)
+				if reasonFilter != "" && reason != reasonFilter {
+					continue
+				}
+				if len(entries) == limit {
+					break objects
+				}
+				entries = append(entries, deadLetterEntry{
+					Labels:             stream.Labels,
+					Timestamp:          e.Timestamp,
+					Line:               e.Line,
+					StructuredMetadata: e.StructuredMetadata,
+					Reason:             reason,
+				})
+			}
+		}
+	}
+
+	writeJSONResponse(w, http.StatusOK, struct {
+		Entries []deadLetterEntry `json:"entries"`
+	}{Entries: entries})
+}
+
+// DeadLetterReplayHandler pushes again the rejected log lines of the tenant
+// stored in the dead-letter store between the start and end parameters, and
+// deletes them from the store. The log lines rejected again, and the ones
+// rejected for a reason which can't be accepted later, are stored again in the
+// dead-letter store before the replayed object is deleted.
+func (d *Distributor) DeadLetterReplayHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID, objects, ok := d.deadLetterObjects(w, r)
+	if !ok {
+		return
+	}
+
+	var replayedEntries, rejectedEntries, skippedEntries int
+	for _, o := range objects {
+		req, err := d.deadLetterStore.Read(r.Context(), o.Key)
+		if err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to read rejected log lines", "tenant", tenantID, "key", o.Key, "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		replay := newDeadLetterReplay()
+		pushReq := &logproto.PushRequest{Streams: make([]logproto.Stream, 0, len(req.Streams))}
+		for _, stream := range req.Streams {
+			entries := stream.Entries[:0]
+			for _, e := range stream.Entries {
+				reason := deadletter.RemoveReason(&e)
+				if _, ok := neverReplayed[reason]; ok {
+					replay.add(reason, stream.Labels, []logproto.Entry{e})
+					continue
+				}
+				entries = append(entries, e)
+			}
+			if len(entries) > 0 {
+				pushReq.Streams = append(pushReq.Streams, logproto.Stream{Labels: stream.Labels, Entries: entries})
+			}
+		}
+		skipped := replay.entries
+
+		entries := 0
+		if len(pushReq.Streams) > 0 {
+			for _, stream := range pushReq.Streams {
+				entries += len(stream.Entries)
+			}
+			// The log lines rejected by the distributor are recorded by the
+			// replay, so the object is kept if the push failed otherwise, or
+			// if none of its log lines were recorded as rejected.
+			_, err := d.Push(context.WithValue(r.Context(), deadLetterReplayKey{}, replay), pushReq)
+			if err != nil {
+				if resp, ok := httpgrpc.HTTPResponseFromError(err); !ok || resp.Code/100 == 5 || replay.failed || replay.entries == skipped {
+					level.Error(util_log.Logger).Log("msg", "failed to replay rejected log lines", "tenant", tenantID, "key", o.Key, "err", err)
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+
+		if replay.entries > 0 {
+			if err := d.deadLetterStore.Put(r.Context(), tenantID, replay.request()); err != nil {
+				level.Error(util_log.Logger).Log("msg", "failed to store rejected log lines again", "tenant", tenantID, "key", o.Key, "err", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := d.deadLetterStore.Delete(r.Context(), o.Key); err != nil {
+			level.Error(util_log.Logger).Log("msg", "failed to delete replayed log lines", "tenant", tenantID, "key", o.Key, "err", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rejected := replay.entries - skipped
+		replayedEntries += entries - rejected
+		rejectedEntries += rejected
+		skippedEntries += skipped
+	}
+
+	writeJSONResponse(w, http.StatusOK, struct {
+		ReplayedEntries int `json:"replayed_entries"`
+		RejectedEntries int `json:"rejected_entries"`
+		SkippedEntries  int `json:"skipped_entries"`
+	}{ReplayedEntries: replayedEntries, RejectedEntries: rejectedEntries, SkippedEntries: skippedEntries})
+}
+
+// deadLetterObjects returns the tenant of the request and its objects in the
+// dead-letter store between the start and end parameters of the request,
+// defaulting to the last day. It writes the error response and returns false
+// if the request is invalid.
+func (d *Distributor) deadLetterObjects(w http.ResponseWriter, r *http.Request) (string, []deadletter.Object, bool) {
+	if d.deadLetterStore == nil {
+		http.Error(w, "the dead-letter store is not configured", http.StatusNotFound)
+		return "", nil, false
+	}
+
+	tenantID, err := tenant.TenantID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return "", nil, false
+	}
+
+	through := time.Now()
+	if v := r.FormValue("end"); v != "" {
+		ms, err := util.ParseTime(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return "", nil, false
+		}
+		through = util.TimeFromMillis(ms)
+	}
+	from := through.Add(-24 * time.Hour)
+	if v := r.FormValue("start"); v != "" {
+		ms, err := util.ParseTime(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return "", nil, false
+		}
+		from = util.TimeFromMillis(ms)
+	}
+
+	objects, err := d.deadLetterStore.List(r.Context(), tenantID, from, through)
+	if err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to list rejected log lines", "tenant", tenantID, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return "", nil, false
+	}
+	return tenantID, objects, true
+}
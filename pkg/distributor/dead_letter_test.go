@@ -0,0 +1,183 @@
+package distributor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/grafana/dskit/services"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/distributor/deadletter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestDistributor_DeadLetter(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.MaxLineSize = 10
+	limits.DeadLetter.Enabled = true
+	limits.DeadLetter.Reasons = []string{validation.LineTooLong, validation.GreaterThanMaxSampleAge, validation.RateLimited}
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+	d := distributors[0]
+
+	objectClient := testutils.NewInMemoryObjectClient()
+	newStore := func() *deadletter.Store {
+		s := deadletter.NewStore(deadletter.StoreConfig{
+			KeyPrefix:     "dead-letter/",
+			FlushInterval: time.Hour,
+			ObjectClient:  objectClient,
+		}, log.NewNopLogger(), nil)
+		require.NoError(t, services.StartAndAwaitRunning(ctx, s))
+		d.deadLetterStore = s
+		return s
+	}
+
+	store := newStore()
+	now := time.Now()
+	_, err := d.Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{
+		{
+			Labels: `{app="api"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: now, Line: "ok"},
+				{Timestamp: now, Line: "this line is too long"},
+				{Timestamp: now.Add(-30 * 24 * time.Hour), Line: "too old"},
+			},
+		},
+		{
+			// the reason is not stored.
+			Labels:  `{app=`,
+			Entries: []logproto.Entry{{Timestamp: now, Line: "invalid labels"}},
+		},
+	}})
+	require.Error(t, err)
+	// the rejected log lines are written when the store stops.
+	require.NoError(t, services.StopAndAwaitTerminated(ctx, store))
+
+	listEntries := func(query string) []deadLetterEntry {
+		w := httptest.NewRecorder()
+		d.DeadLetterHandler(w, httptest.NewRequest(http.MethodGet, "/distributor/dead_letter"+query, nil).WithContext(ctx))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Entries []deadLetterEntry `json:"entries"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Entries
+	}
+
+	entries := listEntries("")
+	require.Len(t, entries, 2)
+	require.Equal(t, `{app="api"}`, entries[0].Labels)
+	require.Equal(t, "this line is too long", entries[0].Line)
+	require.Equal(t, validation.LineTooLong, entries[0].Reason)
+	require.Equal(t, "too old", entries[1].Line)
+	require.Equal(t, validation.GreaterThanMaxSampleAge, entries[1].Reason)
+
+	entries = listEntries("?reason=" + validation.GreaterThanMaxSampleAge)
+	require.Len(t, entries, 1)
+	require.Equal(t, "too old", entries[0].Line)
+	require.Len(t, listEntries("?limit=1"), 1)
+	require.Empty(t, listEntries("?end=0"))
+
+	type replayResponse struct {
+		ReplayedEntries int `json:"replayed_entries"`
+		RejectedEntries int `json:"rejected_entries"`
+		SkippedEntries  int `json:"skipped_entries"`
+	}
+	replay := func(expectedCode int) replayResponse {
+		w := httptest.NewRecorder()
+		d.DeadLetterReplayHandler(w, httptest.NewRequest(http.MethodPost, "/distributor/dead_letter/replay", nil).WithContext(ctx))
+		require.Equal(t, expectedCode, w.Code)
+
+		var resp replayResponse
+		if expectedCode == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return resp
+	}
+
+	// the log lines rejected again are stored again right away, and the too
+	// old ones are not replayed.
+	store = newStore()
+	require.Equal(t, replayResponse{RejectedEntries: 1, SkippedEntries: 1}, replay(http.StatusOK))
+	entries = listEntries("")
+	require.Len(t, entries, 2)
+	require.ElementsMatch(t, []string{validation.LineTooLong, validation.GreaterThanMaxSampleAge}, []string{entries[0].Reason, entries[1].Reason})
+	require.NoError(t, services.StopAndAwaitTerminated(ctx, store))
+	require.Len(t, listEntries(""), 2)
+
+	objectClient = testutils.NewInMemoryObjectClient()
+	store = newStore()
+	store.Add("test", validation.RateLimited, `{app="api"}`, []logproto.Entry{{Timestamp: now, Line: "replayed"}})
+	require.NoError(t, services.StopAndAwaitTerminated(ctx, store))
+
+	// the log lines are kept if the push fails.
+	ingester.failAfter = time.Millisecond
+	replay(http.StatusInternalServerError)
+	require.Len(t, listEntries(""), 1)
+	ingester.failAfter = 0
+
+	require.Equal(t, replayResponse{ReplayedEntries: 1}, replay(http.StatusOK))
+	require.Empty(t, listEntries(""))
+
+	ingester.mu.Lock()
+	defer ingester.mu.Unlock()
+	pushed := ingester.pushed[len(ingester.pushed)-1].Streams[0]
+	require.Equal(t, `{app="api"}`, pushed.Labels)
+	require.Equal(t, "replayed", pushed.Entries[0].Line)
+	require.Empty(t, pushed.Entries[0].StructuredMetadata)
+}
+
+func TestDistributor_DeadLetterReplay_Disabled(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.IngestionRateMB = 5.0 / bytesInMB
+	limits.IngestionBurstSizeMB = 5.0 / bytesInMB
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+	d := distributors[0]
+
+	objectClient := testutils.NewInMemoryObjectClient()
+	store := deadletter.NewStore(deadletter.StoreConfig{
+		KeyPrefix:     "dead-letter/",
+		FlushInterval: time.Hour,
+		ObjectClient:  objectClient,
+	}, log.NewNopLogger(), nil)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, store))
+	d.deadLetterStore = store
+	defer services.StopAndAwaitTerminated(ctx, store) //nolint:errcheck
+
+	require.NoError(t, store.Put(ctx, "test", &logproto.PushRequest{Streams: []logproto.Stream{{
+		Labels:  `{app="api"}`,
+		Entries: []logproto.Entry{deadletter.WithReason(logproto.Entry{Timestamp: time.Now(), Line: "rate limited"}, validation.RateLimited)},
+	}}}))
+
+	// the log lines rejected by the rate limit of the tenant are stored again,
+	// although the tenant disabled the dead-letter store.
+	w := httptest.NewRecorder()
+	d.DeadLetterReplayHandler(w, httptest.NewRequest(http.MethodPost, "/distributor/dead_letter/replay", nil).WithContext(ctx))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"replayed_entries": 0, "rejected_entries": 1, "skipped_entries": 0}`, w.Body.String())
+
+	objects, err := store.List(ctx, "test", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	req, err := store.Read(ctx, objects[0].Key)
+	require.NoError(t, err)
+	require.Len(t, req.Streams, 1)
+	require.Equal(t, "rate limited", req.Streams[0].Entries[0].Line)
+	require.Equal(t, validation.RateLimited, deadletter.RemoveReason(&req.Streams[0].Entries[0]))
+}
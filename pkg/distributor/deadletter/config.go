@@ -0,0 +1,67 @@
+package deadletter
+
+import (
+	"errors"
+	"flag"
+	"slices"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+)
+
+// Config configures the storage of the rejected log lines of a tenant in the
+// dead-letter store.
+type Config struct {
+	Enabled bool `yaml:"enabled" json:"enabled" doc:"description=Store the log lines rejected by the distributors in the dead-letter store, along with the reason they were rejected. The stored log lines can be listed and replayed with the /distributor/dead_letter endpoints. Requires the dead-letter store of the distributors to be configured."`
+
+	Reasons flagext.StringSliceCSV `yaml:"reasons" json:"reasons" doc:"description=Comma-separated list of the discard reasons of the log lines stored in the dead-letter store, for example 'greater_than_max_sample_age,rate_limited,line_too_long'. Empty stores the log lines rejected for any reason."`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Store the log lines rejected by the distributors in the dead-letter store.")
+	fs.Var(&cfg.Reasons, prefix+".reasons", "Comma-separated list of the discard reasons of the log lines stored in the dead-letter store. Empty stores the log lines rejected for any reason.")
+}
+
+// Stored returns whether the log lines rejected for the reason are stored.
+func (cfg Config) Stored(reason string) bool {
+	return cfg.Enabled && (len(cfg.Reasons) == 0 || slices.Contains(cfg.Reasons, reason))
+}
+
+// StoreConfig configures the dead-letter store of the distributors.
+type StoreConfig struct {
+	Store            string        `yaml:"store"`
+	KeyPrefix        string        `yaml:"key_prefix"`
+	FlushInterval    time.Duration `yaml:"flush_interval"`
+	MaxBufferedBytes int           `yaml:"max_buffered_bytes"`
+
+	// ObjectClient is the client of the store, set by the module.
+	ObjectClient client.ObjectClient `yaml:"-"`
+}
+
+func (cfg *StoreConfig) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.StringVar(&cfg.Store, prefix+".store", "", "Object store the log lines rejected by the distributors are written to, for the tenants with the dead-letter store enabled. Empty disables the dead-letter store.")
+	fs.StringVar(&cfg.KeyPrefix, prefix+".key-prefix", "dead-letter/", "Path prefix of the rejected log lines in the object store.")
+	fs.DurationVar(&cfg.FlushInterval, prefix+".flush-interval", 10*time.Second, "Interval at which the buffered rejected log lines are written to the object store.")
+	fs.IntVar(&cfg.MaxBufferedBytes, prefix+".max-buffered-bytes", 10<<20, "Maximum size of the rejected log lines buffered by each distributor until they are written to the object store. The log lines rejected while the buffer is full are not stored. 0 means no limit.")
+}
+
+// Enabled returns whether the dead-letter store is configured.
+func (cfg *StoreConfig) Enabled() bool {
+	return cfg.Store != ""
+}
+
+func (cfg *StoreConfig) Validate() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if cfg.FlushInterval <= 0 {
+		return errors.New("the flush interval of the dead-letter store must be greater than 0")
+	}
+	if cfg.MaxBufferedBytes < 0 {
+		return errors.New("the maximum buffered bytes of the dead-letter store must not be negative")
+	}
+	return config.ValidatePathPrefix(cfg.KeyPrefix)
+}
@@ -0,0 +1,261 @@
+package deadletter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/golang/snappy"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+// ReasonLabel is the name of the structured metadata of the stored log lines
+// holding the reason they were rejected.
+const ReasonLabel = "__discard_reason__"
+
+// Object is a batch of rejected log lines of a tenant in the store.
+type Object struct {
+	Key string
+	// Time is the time the batch was written to the store.
+	Time time.Time
+}
+
+// Store buffers the log lines rejected by the distributor, and writes them
+// periodically to the object store, one object per tenant. The objects are
+// snappy compressed push requests, whose log lines have the reason they were
+// rejected in their structured metadata.
+type Store struct {
+	services.Service
+
+	cfg    StoreConfig
+	logger log.Logger
+
+	mu            sync.Mutex
+	batches       map[string]map[string]*logproto.Stream // by tenant and stream labels
+	bufferedBytes int
+
+	storedEntries  *prometheus.CounterVec
+	droppedEntries *prometheus.CounterVec
+}
+
+func NewStore(cfg StoreConfig, logger log.Logger, registerer prometheus.Registerer) *Store {
+	s := &Store{
+		cfg:     cfg,
+		logger:  log.With(logger, "component", "dead-letter-store"),
+		batches: make(map[string]map[string]*logproto.Stream),
+		storedEntries: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_dead_letter_stored_entries_total",
+			Help:      "The total number of rejected log lines written to the dead-letter store.",
+		}, []string{"tenant"}),
+		droppedEntries: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_dead_letter_dropped_entries_total",
+			Help:      "The total number of rejected log lines not written to the dead-letter store because its buffer was full or the write failed.",
+		}, []string{"tenant"}),
+	}
+	s.Service = services.NewTimerService(cfg.FlushInterval, nil, s.iteration, s.stopping).WithName("dead-letter store")
+	return s
+}
+
+func (s *Store) iteration(ctx context.Context) error {
+	s.flush(ctx)
+	return nil
+}
+
+func (s *Store) stopping(_ error) error {
+	s.flush(context.Background())
+	return nil
+}
+
+// Add buffers the entries of the stream of the tenant rejected for the reason
+// until they are written to the object store.
+func (s *Store) Add(tenant, reason, labels string, entries []logproto.Entry) {
+	size := 0
+	for _, e := range entries {
+		size += len(e.Line) + len(reason)
+		for _, m := range e.StructuredMetadata {
+			size += len(m.Name) + len(m.Value)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MaxBufferedBytes > 0 && s.bufferedBytes+size > s.cfg.MaxBufferedBytes {
+		s.droppedEntries.WithLabelValues(tenant).Add(float64(len(entries)))
+		return
+	}
+	s.bufferedBytes += size
+
+	streams, ok := s.batches[tenant]
+	if !ok {
+		streams = make(map[string]*logproto.Stream)
+		s.batches[tenant] = streams
+	}
+	stream, ok := streams[labels]
+	if !ok {
+		// The rejected log lines outlive the push request, so they are copied.
+		stream = &logproto.Stream{Labels: strings.Clone(labels)}
+		streams[stream.Labels] = stream
+	}
+	for _, e := range entries {
+		stream.Entries = append(stream.Entries, WithReason(e, reason))
+	}
+}
+
+// Put writes the rejected log lines of the tenant to the object store right
+// away. Their entries must hold the reason they were rejected, see WithReason.
+func (s *Store) Put(ctx context.Context, tenant string, req *logproto.PushRequest) error {
+	if err := s.write(ctx, tenant, time.Now(), req); err != nil {
+		return err
+	}
+	entries := 0
+	for _, stream := range req.Streams {
+		entries += len(stream.Entries)
+	}
+	s.storedEntries.WithLabelValues(tenant).Add(float64(entries))
+	return nil
+}
+
+func (s *Store) flush(ctx context.Context) {
+	s.mu.Lock()
+	batches := s.batches
+	s.batches = make(map[string]map[string]*logproto.Stream)
+	s.bufferedBytes = 0
+	s.mu.Unlock()
+
+	now := time.Now()
+	for tenant, streams := range batches {
+		req := &logproto.PushRequest{Streams: make([]logproto.Stream, 0, len(streams))}
+		entries := 0
+		for _, stream := range streams {
+			req.Streams = append(req.Streams, *stream)
+			entries += len(stream.Entries)
+		}
+
+		if err := s.write(ctx, tenant, now, req); err != nil {
+			level.Error(s.logger).Log("msg", "failed to write rejected log lines", "tenant", tenant, "entries", entries, "err", err)
+			s.droppedEntries.WithLabelValues(tenant).Add(float64(entries))
+			continue
+		}
+		s.storedEntries.WithLabelValues(tenant).Add(float64(entries))
+	}
+}
+
+func (s *Store) write(ctx context.Context, tenant string, now time.Time, req *logproto.PushRequest) error {
+	buf, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+	// The time is zero padded so that the keys are ordered by time, and
+	// suffixed with a random number so that the distributors don't overwrite
+	// each other's objects.
+	key := fmt.Sprintf("%s%019d-%08x", s.tenantPrefix(tenant), now.UnixNano(), rand.Uint32())
+	return s.cfg.ObjectClient.PutObject(ctx, key, bytes.NewReader(snappy.Encode(nil, buf)))
+}
+
+func (s *Store) tenantPrefix(tenant string) string {
+	return s.cfg.KeyPrefix + tenant + "/"
+}
+
+// List returns the objects of the tenant written in [from, through), ordered
+// by time.
+func (s *Store) List(ctx context.Context, tenant string, from, through time.Time) ([]Object, error) {
+	prefix := s.tenantPrefix(tenant)
+	objects, _, err := s.cfg.ObjectClient.List(ctx, prefix, "")
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Object, 0, len(objects))
+	for _, o := range objects {
+		ts, _, ok := strings.Cut(strings.TrimPrefix(o.Key, prefix), "-")
+		if !ok {
+			continue
+		}
+		ns, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			continue
+		}
+		t := time.Unix(0, ns)
+		if t.Before(from) || !t.Before(through) {
+			continue
+		}
+		res = append(res, Object{Key: o.Key, Time: t})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Key < res[j].Key
+	})
+	return res, nil
+}
+
+// Read returns the rejected log lines of the object.
+func (s *Store) Read(ctx context.Context, key string) (*logproto.PushRequest, error) {
+	rc, _, err := s.cfg.ObjectClient.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	compressed, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	var req logproto.PushRequest
+	if err := req.Unmarshal(buf); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// Delete deletes the object.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	return s.cfg.ObjectClient.DeleteObject(ctx, key)
+}
+
+// WithReason returns a copy of the entry, which outlives the push request, with
+// the reason it was rejected in its structured metadata.
+func WithReason(entry logproto.Entry, reason string) logproto.Entry {
+	metadata := make([]logproto.LabelAdapter, 0, len(entry.StructuredMetadata)+1)
+	for _, m := range entry.StructuredMetadata {
+		metadata = append(metadata, logproto.LabelAdapter{Name: strings.Clone(m.Name), Value: strings.Clone(m.Value)})
+	}
+	metadata = append(metadata, logproto.LabelAdapter{Name: ReasonLabel, Value: reason})
+	return logproto.Entry{
+		Timestamp:          entry.Timestamp,
+		Line:               strings.Clone(entry.Line),
+		StructuredMetadata: metadata,
+	}
+}
+
+// RemoveReason removes the structured metadata holding the reason the log line
+// was rejected from the entry, and returns the reason.
+func RemoveReason(entry *logproto.Entry) string {
+	for i, m := range entry.StructuredMetadata {
+		if m.Name == ReasonLabel {
+			entry.StructuredMetadata = append(entry.StructuredMetadata[:i:i], entry.StructuredMetadata[i+1:]...)
+			return m.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package deadletter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(StoreConfig{
+		KeyPrefix:        "dead-letter/",
+		FlushInterval:    time.Hour,
+		MaxBufferedBytes: 100,
+		ObjectClient:     testutils.NewInMemoryObjectClient(),
+	}, log.NewNopLogger(), nil)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, s))
+
+	now := time.Unix(0, 0)
+	s.Add("tenant-a", "line_too_long", `{app="api"}`, []logproto.Entry{
+		{Timestamp: now, Line: "foo", StructuredMetadata: []logproto.LabelAdapter{{Name: "trace_id", Value: "1"}}},
+	})
+	s.Add("tenant-a", "rate_limited", `{app="api"}`, []logproto.Entry{{Timestamp: now, Line: "bar"}})
+	s.Add("tenant-b", "rate_limited", `{app="web"}`, []logproto.Entry{{Timestamp: now, Line: "baz"}})
+	// the buffer is full.
+	s.Add("tenant-b", "rate_limited", `{app="web"}`, []logproto.Entry{{Timestamp: now, Line: string(make([]byte, 100))}})
+	require.Equal(t, float64(1), testutil.ToFloat64(s.droppedEntries.WithLabelValues("tenant-b")))
+
+	// the rejected log lines are written when the store stops.
+	start := time.Now()
+	require.NoError(t, services.StopAndAwaitTerminated(ctx, s))
+	require.Equal(t, float64(2), testutil.ToFloat64(s.storedEntries.WithLabelValues("tenant-a")))
+
+	objects, err := s.List(ctx, "tenant-a", start.Add(-time.Minute), start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+
+	empty, err := s.List(ctx, "tenant-a", start.Add(time.Minute), start.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, empty)
+
+	req, err := s.Read(ctx, objects[0].Key)
+	require.NoError(t, err)
+	require.Len(t, req.Streams, 1)
+	require.Equal(t, `{app="api"}`, req.Streams[0].Labels)
+
+	entries := req.Streams[0].Entries
+	require.Len(t, entries, 2)
+	require.Equal(t, "line_too_long", RemoveReason(&entries[0]))
+	require.Equal(t, push.LabelsAdapter{{Name: "trace_id", Value: "1"}}, entries[0].StructuredMetadata)
+	require.Equal(t, "rate_limited", RemoveReason(&entries[1]))
+	require.Empty(t, entries[1].StructuredMetadata)
+	require.Equal(t, "", RemoveReason(&entries[1]))
+
+	require.NoError(t, s.Delete(ctx, objects[0].Key))
+	objects, err = s.List(ctx, "tenant-a", start.Add(-time.Minute), start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
+func TestConfig_Stored(t *testing.T) {
+	require.False(t, Config{}.Stored("rate_limited"))
+	require.True(t, Config{Enabled: true}.Stored("rate_limited"))
+	require.True(t, Config{Enabled: true, Reasons: []string{"rate_limited"}}.Stored("rate_limited"))
+	require.False(t, Config{Enabled: true, Reasons: []string{"line_too_long"}}.Stored("rate_limited"))
+}
@@ -41,7 +41,12 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/analytics"
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/distributor/adaptivesampling"
+	"github.com/grafana/loki/v3/pkg/distributor/cardinalityguard"
 	"github.com/grafana/loki/v3/pkg/distributor/clientpool"
+	"github.com/grafana/loki/v3/pkg/distributor/deadletter"
+	"github.com/grafana/loki/v3/pkg/distributor/idempotency"
+	"github.com/grafana/loki/v3/pkg/distributor/receivers"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
 	"github.com/grafana/loki/v3/pkg/ingester"
@@ -94,6 +99,12 @@ type Config struct {
 
 	OTLPConfig push.GlobalOTLPConfig `yaml:"otlp_config"`
 
+	// DeadLetter configures the store of the rejected log lines.
+	DeadLetter deadletter.StoreConfig `yaml:"dead_letter_store" category:"experimental" doc:"description=Object store the log lines rejected by the distributors are written to, for the tenants with the dead-letter store enabled."`
+
+	// Receivers configures the syslog and GELF listeners.
+	Receivers receivers.Config `yaml:"receivers" category:"experimental" doc:"description=Syslog and GELF listeners pushing the received log lines with the same validation and rate limits as the push API."`
+
 	KafkaEnabled    bool         `yaml:"kafka_writes_enabled"`
 	IngesterEnabled bool         `yaml:"ingester_writes_enabled"`
 	KafkaConfig     kafka.Config `yaml:"-"`
@@ -105,6 +116,8 @@ func (cfg *Config) RegisterFlags(fs *flag.FlagSet) {
 	cfg.DistributorRing.RegisterFlags(fs)
 	cfg.RateStore.RegisterFlagsWithPrefix("distributor.rate-store", fs)
 	cfg.WriteFailuresLogging.RegisterFlagsWithPrefix("distributor.write-failures-logging", fs)
+	cfg.Receivers.RegisterFlagsWithPrefix("distributor.receivers", fs)
+	cfg.DeadLetter.RegisterFlagsWithPrefix("distributor.dead-letter-store", fs)
 	fs.IntVar(&cfg.PushWorkerCount, "distributor.push-worker-count", 256, "Number of workers to push batches to ingesters.")
 	fs.BoolVar(&cfg.KafkaEnabled, "distributor.kafka-writes-enabled", false, "Enable writes to Kafka during Push requests.")
 	fs.BoolVar(&cfg.IngesterEnabled, "distributor.ingester-writes-enabled", true, "Enable writes to Ingesters during Push requests. Defaults to true.")
@@ -114,7 +127,10 @@ func (cfg *Config) Validate() error {
 	if !cfg.KafkaEnabled && !cfg.IngesterEnabled {
 		return fmt.Errorf("at least one of kafka and ingestor writes must be enabled")
 	}
-	return nil
+	if err := cfg.DeadLetter.Validate(); err != nil {
+		return err
+	}
+	return cfg.Receivers.Validate()
 }
 
 // RateStore manages the ingestion rate of streams, populated by data fetched from ingesters.
@@ -157,6 +173,19 @@ type Distributor struct {
 	// Per-user rate limiter.
 	ingestionRateLimiter *limiter.RateLimiter
 	labelCache           *lru.Cache
+	ingestionPipelines   ingestionPipelines
+
+	// Per-user and ingestion rate policy rate limiter.
+	ingestionRatePolicyLimiter *limiter.RateLimiter
+
+	// Idempotency keys of the push requests.
+	idempotencyKeys *idempotency.Cache
+
+	// Distinct values of the labels of the pushed streams.
+	cardinalityGuard *cardinalityguard.Guard
+
+	// Sampling ratios of the runaway streams.
+	adaptiveSampler *adaptivesampling.Sampler
 
 	// Push failures rate limiter.
 	writeFailuresManager *writefailures.Manager
@@ -168,6 +197,15 @@ type Distributor struct {
 	ingesterAppendTimeouts *prometheus.CounterVec
 	replicationFactor      prometheus.Gauge
 	streamShardCount       prometheus.Counter
+	redactedMatches        *prometheus.CounterVec
+	ratePolicyBytes        *prometheus.CounterVec
+	ratePolicyLimitedBytes *prometheus.CounterVec
+	sampledStreams         *prometheus.CounterVec
+	duplicatePushRequests  *prometheus.CounterVec
+	demotedLabelsStreams   *prometheus.CounterVec
+	skippedExtractedFields *prometheus.CounterVec
+
+	deadLetterStore *deadletter.Store
 
 	usageTracker   push.UsageTracker
 	ingesterTasks  chan pushIngesterTask
@@ -217,7 +255,7 @@ func New(
 	}
 
 	// Create the configured ingestion rate limit strategy (local or global).
-	var ingestionRateStrategy limiter.RateLimiterStrategy
+	var ingestionRateStrategy, ingestionRatePolicyStrategy limiter.RateLimiterStrategy
 	var distributorsLifecycler *ring.BasicLifecycler
 	var distributorsRing *ring.Ring
 
@@ -259,6 +297,9 @@ func New(
 		tee:                   tee,
 		usageTracker:          usageTracker,
 		ingesterTasks:         make(chan pushIngesterTask),
+		idempotencyKeys:       idempotency.NewCache(),
+		cardinalityGuard:      cardinalityguard.NewGuard(),
+		adaptiveSampler:       adaptivesampling.NewSampler(),
 		ingesterAppends: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
 			Namespace: constants.Loki,
 			Name:      "distributor_ingester_appends_total",
@@ -279,6 +320,41 @@ func New(
 			Name:      "stream_sharding_count",
 			Help:      "Total number of times the distributor has sharded streams",
 		}),
+		redactedMatches: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_redacted_matches_total",
+			Help:      "The total number of sensitive data matches redacted from the pushed log lines, by detector.",
+		}, []string{"tenant", "detector"}),
+		ratePolicyBytes: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_ingestion_rate_policy_bytes_total",
+			Help:      "The total number of bytes of the log lines matching an ingestion rate policy.",
+		}, []string{"tenant", "policy"}),
+		ratePolicyLimitedBytes: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_ingestion_rate_policy_limited_bytes_total",
+			Help:      "The total number of bytes of the log lines discarded because of the rate limit of an ingestion rate policy.",
+		}, []string{"tenant", "policy"}),
+		sampledStreams: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_adaptive_sampling_streams_total",
+			Help:      "The total number of pushed streams sampled because their rate is above the adaptive sampling rate threshold.",
+		}, []string{"tenant"}),
+		duplicatePushRequests: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_duplicate_push_requests_total",
+			Help:      "The total number of push requests acknowledged without being pushed because a push request with the same idempotency key succeeded.",
+		}, []string{"tenant"}),
+		demotedLabelsStreams: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_cardinality_guard_demoted_streams_total",
+			Help:      "The total number of pushed streams whose labels with too many distinct values were demoted to structured metadata.",
+		}, []string{"tenant"}),
+		skippedExtractedFields: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_extracted_fields_skipped_total",
+			Help:      "The total number of fields extracted from the pushed log lines which were not added to their structured metadata because of the structured metadata limits.",
+		}, []string{"tenant"}),
 		kafkaAppends: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
 			Namespace: constants.Loki,
 			Name:      "distributor_kafka_appends_total",
@@ -320,11 +396,14 @@ func New(
 		servs = append(servs, distributorsLifecycler, distributorsRing)
 
 		ingestionRateStrategy = newGlobalIngestionRateStrategy(overrides, d)
+		ingestionRatePolicyStrategy = newGlobalIngestionRatePolicyStrategy(overrides, d)
 	} else {
 		ingestionRateStrategy = newLocalIngestionRateStrategy(overrides)
+		ingestionRatePolicyStrategy = newLocalIngestionRatePolicyStrategy(overrides)
 	}
 
 	d.ingestionRateLimiter = limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second)
+	d.ingestionRatePolicyLimiter = limiter.NewRateLimiter(ingestionRatePolicyStrategy, 10*time.Second)
 	d.distributorsRing = distributorsRing
 	d.distributorsLifecycler = distributorsLifecycler
 
@@ -348,6 +427,17 @@ func New(
 	d.rateStore = rs
 
 	servs = append(servs, d.pool, rs)
+
+	if cfg.Receivers.Enabled() {
+		servs = append(servs, receivers.New(cfg.Receivers, d, logger, registerer))
+	}
+	if cfg.DeadLetter.Enabled() {
+		if cfg.DeadLetter.ObjectClient == nil {
+			return nil, errors.New("the object client of the dead-letter store is not set")
+		}
+		d.deadLetterStore = deadletter.NewStore(cfg.DeadLetter, logger, registerer)
+		servs = append(servs, d.deadLetterStore)
+	}
 	d.subservices, err = services.NewManager(servs...)
 	if err != nil {
 		return nil, errors.Wrap(err, "services manager")
@@ -438,6 +528,32 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 		return &logproto.PushResponse{}, nil
 	}
 
+	idempotencyKey := ""
+	// the streams pushed by a previous push request of the key, which
+	// rejected other streams, are not pushed again.
+	var appendedStreams map[string]struct{}
+	var pushedStreams []string
+	idempotencyCfg := d.validator.PushIdempotency(tenantID)
+	if req.IdempotencyKey != "" && idempotencyCfg.Enabled() {
+		if len(req.IdempotencyKey) > idempotency.MaxKeyLength {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, idempotency.KeyTooLongErrorMsg, len(req.IdempotencyKey), idempotency.MaxKeyLength)
+		}
+
+		switch d.idempotencyKeys.Reserve(tenantID, req.IdempotencyKey, idempotencyCfg) {
+		case idempotency.Done:
+			d.duplicatePushRequests.WithLabelValues(tenantID).Inc()
+			return &logproto.PushResponse{}, nil
+		case idempotency.InProgress:
+			return nil, httpgrpc.Errorf(http.StatusTooManyRequests, idempotency.KeyInProgressErrorMsg, req.IdempotencyKey)
+		}
+
+		// The key is committed once the streams are pushed, and released
+		// otherwise so that the failed push requests can be retried.
+		idempotencyKey = req.IdempotencyKey
+		defer d.idempotencyKeys.Release(tenantID, idempotencyKey)
+		appendedStreams = d.idempotencyKeys.Appended(tenantID, idempotencyKey)
+	}
+
 	// First we flatten out the request into a list of samples.
 	// We use the heuristic of 1 sample per TS to size the array.
 	// We also work out the hash value at the same time.
@@ -445,8 +561,32 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	validatedLineSize := 0
 	validatedLineCount := 0
 
-	var validationErrors util.GroupedErrors
+	var validationErrors, rateLimitErrors util.GroupedErrors
+	var validatedStreams []logproto.Stream
 	validationContext := d.validator.getValidationContextForTime(time.Now(), tenantID)
+	validationContext.deadLetterReplay = deadLetterReplayFromContext(ctx)
+	rejections := pushRejectionsFromContext(ctx)
+	if len(validationContext.ingestionPipelines) > 0 {
+		// the streams rewritten by the ingestion pipelines can't be mapped back
+		// to the pushed streams.
+		rejections.unmap()
+	}
+	// The tokens consumed from the limiters of the ingestion rate policies are
+	// given back if the whole push request is rejected afterwards.
+	var policyTokens map[string]int
+	if len(validationContext.ingestionRatePolicies) > 0 {
+		policyTokens = make(map[string]int)
+	}
+
+	// The ingestion pipelines run before the streams are validated, so that the
+	// labels and log lines they produce are validated too.
+	if len(validationContext.ingestionPipelines) > 0 {
+		res := d.ingestionPipelines.process(validationContext.ingestionPipelines, req.Streams)
+		if res.droppedLines > 0 {
+			d.trackDiscardedData(ctx, &logproto.PushRequest{Streams: res.dropped}, validationContext, tenantID, res.droppedLines, res.droppedBytes, validation.DroppedByIngestionPipeline)
+		}
+		req.Streams = res.streams
+	}
 
 	func() {
 		sp := opentracing.SpanFromContext(ctx)
@@ -462,13 +602,20 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 				continue
 			}
 
+			// Redact before truncating, so that no partial sensitive data is left
+			// at the end of the truncated lines.
+			d.redactLines(validationContext, &stream)
+
 			// Truncate first so subsequent steps have consistent line lengths
 			d.truncateLines(validationContext, &stream)
 
 			var lbs labels.Labels
+			streamLabels := stream.Labels
 			lbs, stream.Labels, stream.Hash, err = d.parseStreamLabels(validationContext, stream.Labels, stream)
 			if err != nil {
 				d.writeFailuresManager.Log(tenantID, err)
+				d.deadLetter(validationContext, validation.InvalidLabels, streamLabels, stream.Entries)
+				rejections.rejectStream(streamLabels, http.StatusBadRequest, err)
 				validationErrors.Add(err)
 				validation.DiscardedSamples.WithLabelValues(validation.InvalidLabels, tenantID).Add(float64(len(stream.Entries)))
 				bytes := 0
@@ -478,6 +625,21 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 				validation.DiscardedBytes.WithLabelValues(validation.InvalidLabels, tenantID).Add(float64(bytes))
 				continue
 			}
+			if _, ok := appendedStreams[stream.Labels]; ok {
+				continue
+			}
+			pushedLabels := stream.Labels
+
+			// Demote the labels with too many distinct values before the
+			// streams are sampled, as it changes the streams.
+			lbs = d.demoteLabels(validationContext, lbs, &stream)
+
+			// Sample the runaway streams before validating their entries, so that
+			// the summary log lines of the suppressed log lines are validated too.
+			sampled := d.sampleStream(ctx, validationContext, lbs, &stream)
+			if len(stream.Entries) == 0 {
+				continue
+			}
 
 			n := 0
 			pushSize := 0
@@ -485,13 +647,21 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 
 			shouldDiscoverLevels := validationContext.allowStructuredMetadata && validationContext.discoverLogLevels
 			levelFromLabel, hasLevelLabel := hasAnyLevelLabels(lbs)
-			for _, entry := range stream.Entries {
-				if err := d.validator.ValidateEntry(ctx, validationContext, lbs, entry); err != nil {
+			for i, entry := range stream.Entries {
+				if reason, err := d.validator.validateEntry(ctx, validationContext, lbs, entry); err != nil {
 					d.writeFailuresManager.Log(tenantID, err)
+					d.deadLetter(validationContext, reason, stream.Labels, []logproto.Entry{entry})
+					if sampled {
+						rejections.unmap()
+					} else {
+						rejections.rejectEntry(streamLabels, i, http.StatusBadRequest, err)
+					}
 					validationErrors.Add(err)
 					continue
 				}
 
+				entry.StructuredMetadata = d.extractFields(validationContext, entry)
+
 				structuredMetadata := logproto.FromLabelAdaptersToLabels(entry.StructuredMetadata)
 				if shouldDiscoverLevels {
 					var logLevel string
@@ -537,6 +707,28 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 				continue
 			}
 
+			if err := d.enforceIngestionRatePolicies(validationContext, lbs, stream, pushSize, policyTokens); err != nil {
+				d.trackDiscardedData(ctx, &logproto.PushRequest{Streams: []logproto.Stream{stream}}, validationContext, tenantID, n, pushSize, validation.IngestionRatePolicyLimited)
+				d.writeFailuresManager.Log(tenantID, err)
+				d.deadLetter(validationContext, validation.IngestionRatePolicyLimited, stream.Labels, stream.Entries)
+				rejections.rejectStream(streamLabels, http.StatusTooManyRequests, err)
+				rateLimitErrors.Add(err)
+				validatedLineCount -= n
+				validatedLineSize -= pushSize
+				continue
+			}
+
+			if validationContext.deadLetter.Enabled || validationContext.deadLetterReplay != nil {
+				// The streams are kept before they are sharded, so that the
+				// rejected log lines are stored with the labels they were pushed
+				// with. The replays record them even if the tenant disabled the
+				// dead-letter store since.
+				validatedStreams = append(validatedStreams, stream)
+			}
+			if idempotencyKey != "" {
+				pushedStreams = append(pushedStreams, pushedLabels)
+			}
+
 			shardStreamsCfg := d.validator.Limits.ShardStreams(tenantID)
 			if shardStreamsCfg.Enabled {
 				streams = append(streams, d.shardStream(stream, pushSize, tenantID)...)
@@ -550,7 +742,15 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 	}()
 
 	var validationErr error
-	if validationErrors.Err() != nil {
+	switch {
+	case rateLimitErrors.Err() != nil:
+		// Return a 429 to indicate to the client they are being rate limited,
+		// along with the validation errors of the other streams.
+		for _, err := range validationErrors.MultiError {
+			rateLimitErrors.Add(err)
+		}
+		validationErr = httpgrpc.Errorf(http.StatusTooManyRequests, "%s", rateLimitErrors.Error())
+	case validationErrors.Err() != nil:
 		validationErr = httpgrpc.Errorf(http.StatusBadRequest, "%s", validationErrors.Error())
 	}
 
@@ -566,6 +766,8 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 
 		err = fmt.Errorf(validation.BlockedIngestionErrorMsg, tenantID, until.Format(time.RFC3339), retStatusCode)
 		d.writeFailuresManager.Log(tenantID, err)
+		d.deadLetterStreams(validationContext, validation.BlockedIngestion, validatedStreams)
+		d.giveBackIngestionRatePolicyTokens(now, policyTokens)
 
 		// If the status code is 200, return success.
 		// Note that we still log the error and increment the metrics.
@@ -581,6 +783,8 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 
 		err = fmt.Errorf(validation.RateLimitedErrorMsg, tenantID, int(d.ingestionRateLimiter.Limit(now, tenantID)), validatedLineCount, validatedLineSize)
 		d.writeFailuresManager.Log(tenantID, err)
+		d.deadLetterStreams(validationContext, validation.RateLimited, validatedStreams)
+		d.giveBackIngestionRatePolicyTokens(now, policyTokens)
 		// Return a 429 to indicate to the client they are being rate limited
 		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, "%s", err.Error())
 	}
@@ -665,11 +869,12 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 					cancel()
 					return
 				case d.ingesterTasks <- pushIngesterTask{
-					ingester:      ingester,
-					streamTracker: samples,
-					pushTracker:   &tracker,
-					ctx:           localCtx,
-					cancel:        cancel,
+					ingester:       ingester,
+					streamTracker:  samples,
+					pushTracker:    &tracker,
+					idempotencyKey: idempotencyKey,
+					ctx:            localCtx,
+					cancel:         cancel,
 				}:
 					return
 				}
@@ -679,8 +884,20 @@ func (d *Distributor) Push(ctx context.Context, req *logproto.PushRequest) (*log
 
 	select {
 	case err := <-tracker.err:
+		if validationContext.deadLetterReplay != nil {
+			validationContext.deadLetterReplay.fail()
+		}
 		return nil, err
 	case <-tracker.done:
+		switch {
+		case idempotencyKey == "":
+		case validationErr == nil:
+			d.idempotencyKeys.Commit(tenantID, idempotencyKey, idempotencyCfg)
+		default:
+			// The retries of a push request with rejected streams push these
+			// streams only, rather than being acknowledged without pushing them.
+			d.idempotencyKeys.Release(tenantID, idempotencyKey, pushedStreams...)
+		}
 		return &logproto.PushResponse{}, validationErr
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -845,6 +1062,88 @@ func maxT(t1, t2 time.Time) time.Time {
 	return t1
 }
 
+// enforceIngestionRatePolicies returns an error if the log lines of the stream
+// exceed the rate limit of any of the ingestion rate policies matching it.
+// The rate of the policies is only consumed if the log lines are accepted by
+// all of them, in which case the consumed tokens are added to consumed.
+func (d *Distributor) enforceIngestionRatePolicies(vContext validationContext, lbs labels.Labels, stream logproto.Stream, pushSize int, consumed map[string]int) error {
+	now := time.Now()
+	var allowed []string
+	for _, policy := range vContext.ingestionRatePolicies {
+		if !matchesAll(policy.Matchers, lbs) {
+			continue
+		}
+		d.ratePolicyBytes.WithLabelValues(vContext.userID, policy.Name).Add(float64(pushSize))
+
+		key := ingestionRatePolicyKey(vContext.userID, policy.Name)
+		if !d.ingestionRatePolicyLimiter.AllowN(now, key, pushSize) {
+			// Give back the tokens consumed from the limiters of the previous
+			// policies: allowing a negative number of tokens adds them back.
+			for _, k := range allowed {
+				d.ingestionRatePolicyLimiter.AllowN(now, k, -pushSize)
+			}
+			d.ratePolicyLimitedBytes.WithLabelValues(vContext.userID, policy.Name).Add(float64(pushSize))
+			return fmt.Errorf(validation.IngestionRatePolicyLimitedErrorMsg, policy.Name, vContext.userID, int(d.ingestionRatePolicyLimiter.Limit(now, key)), len(stream.Entries), pushSize, stream.Labels)
+		}
+		allowed = append(allowed, key)
+	}
+	for _, key := range allowed {
+		consumed[key] += pushSize
+	}
+	return nil
+}
+
+// giveBackIngestionRatePolicyTokens gives back the tokens consumed from the
+// limiters of the ingestion rate policies by a push request rejected
+// afterwards, so that its retries don't consume them twice.
+func (d *Distributor) giveBackIngestionRatePolicyTokens(now time.Time, consumed map[string]int) {
+	for key, tokens := range consumed {
+		// allowing a negative number of tokens adds them back.
+		d.ingestionRatePolicyLimiter.AllowN(now, key, -tokens)
+	}
+}
+
+// redactLines replaces the sensitive data of the log lines and their
+// structured metadata values according to the redaction config of the tenant.
+func (d *Distributor) redactLines(vContext validationContext, stream *logproto.Stream) {
+	if !vContext.redaction.Enabled {
+		return
+	}
+
+	matches := make(map[string]int)
+	matched := func(detector string) { matches[detector]++ }
+
+	var redactedSamples, redactedBytes int
+	for i, e := range stream.Entries {
+		redacted := false
+
+		line := vContext.redaction.Redact(e.Line, matched)
+		if line != e.Line {
+			stream.Entries[i].Line = line
+			redactedBytes += len(e.Line)
+			redacted = true
+		}
+		for j, m := range e.StructuredMetadata {
+			value := vContext.redaction.Redact(m.Value, matched)
+			if value != m.Value {
+				stream.Entries[i].StructuredMetadata[j].Value = value
+				redactedBytes += len(m.Value)
+				redacted = true
+			}
+		}
+
+		if redacted {
+			redactedSamples++
+		}
+	}
+
+	for detector, n := range matches {
+		d.redactedMatches.WithLabelValues(vContext.userID, detector).Add(float64(n))
+	}
+	validation.MutatedSamples.WithLabelValues(validation.Redacted, vContext.userID).Add(float64(redactedSamples))
+	validation.MutatedBytes.WithLabelValues(validation.Redacted, vContext.userID).Add(float64(redactedBytes))
+}
+
 func (d *Distributor) truncateLines(vContext validationContext, stream *logproto.Stream) {
 	if !vContext.maxLineSizeTruncate {
 		return
@@ -865,11 +1164,12 @@ func (d *Distributor) truncateLines(vContext validationContext, stream *logproto
 }
 
 type pushIngesterTask struct {
-	streamTracker []*streamTracker
-	pushTracker   *pushTracker
-	ingester      ring.InstanceDesc
-	ctx           context.Context
-	cancel        context.CancelFunc
+	streamTracker  []*streamTracker
+	pushTracker    *pushTracker
+	ingester       ring.InstanceDesc
+	idempotencyKey string
+	ctx            context.Context
+	cancel         context.CancelFunc
 }
 
 func (d *Distributor) pushIngesterWorker(ctx context.Context) {
@@ -887,7 +1187,7 @@ func (d *Distributor) pushIngesterWorker(ctx context.Context) {
 // TODO taken from Cortex, see if we can refactor out an usable interface.
 func (d *Distributor) sendStreams(task pushIngesterTask) {
 	defer task.cancel()
-	err := d.sendStreamsErr(task.ctx, task.ingester, task.streamTracker)
+	err := d.sendStreamsErr(task.ctx, task.ingester, task.streamTracker, task.idempotencyKey)
 
 	// If we succeed, decrement each stream's pending count by one.
 	// If we reach the required number of successful puts on this stream, then
@@ -914,14 +1214,15 @@ func (d *Distributor) sendStreams(task pushIngesterTask) {
 }
 
 // TODO taken from Cortex, see if we can refactor out an usable interface.
-func (d *Distributor) sendStreamsErr(ctx context.Context, ingester ring.InstanceDesc, streams []*streamTracker) error {
+func (d *Distributor) sendStreamsErr(ctx context.Context, ingester ring.InstanceDesc, streams []*streamTracker, idempotencyKey string) error {
 	c, err := d.pool.GetClientFor(ingester.Addr)
 	if err != nil {
 		return err
 	}
 
 	req := &logproto.PushRequest{
-		Streams: make([]logproto.Stream, len(streams)),
+		Streams:        make([]logproto.Stream, len(streams)),
+		IdempotencyKey: idempotencyKey,
 	}
 	for i, s := range streams {
 		req.Streams[i] = s.Stream
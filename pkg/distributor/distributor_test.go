@@ -6,6 +6,8 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,6 +24,7 @@ import (
 	"github.com/grafana/dskit/services"
 	"github.com/grafana/dskit/user"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
@@ -33,6 +36,7 @@ import (
 
 	"github.com/grafana/loki/pkg/push"
 
+	"github.com/grafana/loki/v3/pkg/distributor/idempotency"
 	"github.com/grafana/loki/v3/pkg/ingester"
 	"github.com/grafana/loki/v3/pkg/ingester/client"
 	loghttp_push "github.com/grafana/loki/v3/pkg/loghttp/push"
@@ -1292,6 +1296,287 @@ func TestDistributor_PushIngestionBlocked(t *testing.T) {
 	}
 }
 
+func TestDistributor_PushIngestionPipelines(t *testing.T) {
+	limits := ingestionPipelinesLimits(t, validation.IngestionPipeline{
+		Selector: `{foo="bar"}`,
+		Pipeline: `!~ "^[0-4]"`,
+	})
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	discarded := testutil.ToFloat64(validation.DiscardedSamples.WithLabelValues(validation.DroppedByIngestionPipeline, "test"))
+
+	writeReq := makeWriteRequestWithLabels(10, 10, []string{`{foo="bar"}`})
+	response, err := distributors[0].Push(ctx, writeReq)
+	require.NoError(t, err)
+	require.Equal(t, success, response)
+
+	topVal := ingester.Peek()
+	require.Len(t, topVal.Streams, 1)
+	require.Len(t, topVal.Streams[0].Entries, 5)
+	require.Equal(t, discarded+5, testutil.ToFloat64(validation.DiscardedSamples.WithLabelValues(validation.DroppedByIngestionPipeline, "test")))
+}
+
+func TestDistributor_PushRedaction(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.AllowStructuredMetadata = true
+	limits.DiscoverLogLevels = false
+	limits.Redaction.Enabled = true
+	limits.Redaction.Detectors = []string{"email"}
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	writeReq := &logproto.PushRequest{Streams: []logproto.Stream{{
+		Labels: `{foo="bar"}`,
+		Entries: []logproto.Entry{{
+			Timestamp:          time.Now(),
+			Line:               "user jane@example.com logged in",
+			StructuredMetadata: push.LabelsAdapter{{Name: "user", Value: "jane@example.com"}},
+		}},
+	}}}
+	_, err := distributors[0].Push(ctx, writeReq)
+	require.NoError(t, err)
+
+	topVal := ingester.Peek()
+	require.Equal(t, "user [REDACTED] logged in", topVal.Streams[0].Entries[0].Line)
+	require.Equal(t, push.LabelsAdapter{{Name: "user", Value: "[REDACTED]"}}, topVal.Streams[0].Entries[0].StructuredMetadata)
+	require.Equal(t, float64(2), testutil.ToFloat64(distributors[0].redactedMatches.WithLabelValues("test", "email")))
+}
+
+func TestDistributor_PushIngestionRatePolicies(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.IngestionRatePolicies = []validation.IngestionRatePolicy{
+		{Name: "batch", Selector: `{namespace="batch"}`, RateMB: 1.0 / bytesInMB, BurstSizeMB: 20.0 / bytesInMB},
+	}
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	push := func(labels string) error {
+		_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+			Labels:  labels,
+			Entries: []logproto.Entry{{Timestamp: time.Now(), Line: strings.Repeat("x", 15)}},
+		}}})
+		return err
+	}
+
+	require.NoError(t, push(`{namespace="batch"}`))
+
+	// the burst of the policy is exceeded.
+	err := push(`{namespace="batch"}`)
+	require.Error(t, err)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	require.Contains(t, string(resp.Body), "Ingestion rate limit of policy batch exceeded for user test")
+
+	// the streams not matching the policy are not limited.
+	require.NoError(t, push(`{namespace="web"}`))
+
+	require.Equal(t, float64(30), testutil.ToFloat64(distributors[0].ratePolicyBytes.WithLabelValues("test", "batch")))
+	require.Equal(t, float64(15), testutil.ToFloat64(distributors[0].ratePolicyLimitedBytes.WithLabelValues("test", "batch")))
+}
+
+func TestDistributor_PushIngestionRatePolicies_Rejected(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.IngestionRatePolicies = []validation.IngestionRatePolicy{
+		{Name: "all", Selector: `{namespace=~".+"}`, RateMB: 1.0 / bytesInMB, BurstSizeMB: 20.0 / bytesInMB},
+		{Name: "batch", Selector: `{namespace="batch"}`, RateMB: 1.0 / bytesInMB, BurstSizeMB: 10.0 / bytesInMB},
+	}
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 5, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	push := func(labels string) error {
+		_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+			Labels:  labels,
+			Entries: []logproto.Entry{{Timestamp: time.Now(), Line: strings.Repeat("x", 15)}},
+		}}})
+		return err
+	}
+
+	// the lines rejected by the batch policy don't consume the rate of the
+	// all policy.
+	require.Error(t, push(`{namespace="batch"}`))
+	require.NoError(t, push(`{namespace="web"}`))
+	require.Error(t, push(`{namespace="web"}`))
+
+	require.Equal(t, float64(15), testutil.ToFloat64(distributors[0].ratePolicyLimitedBytes.WithLabelValues("test", "batch")))
+	require.Equal(t, float64(15), testutil.ToFloat64(distributors[0].ratePolicyLimitedBytes.WithLabelValues("test", "all")))
+}
+
+func TestDistributor_PushIngestionRatePolicies_PushRejected(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		limits       func(*validation.Limits)
+		expectedCode int32
+	}{
+		{
+			name: "tenant rate limit",
+			limits: func(limits *validation.Limits) {
+				limits.IngestionRateMB = 1.0 / bytesInMB
+				limits.IngestionBurstSizeMB = 20.0 / bytesInMB
+			},
+			expectedCode: http.StatusTooManyRequests,
+		},
+		{
+			name: "blocked ingestion",
+			limits: func(limits *validation.Limits) {
+				limits.BlockIngestionUntil = flagext.Time(time.Now().Add(time.Hour))
+				limits.BlockIngestionStatusCode = http.StatusLocked
+			},
+			expectedCode: http.StatusLocked,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			limits := &validation.Limits{}
+			flagext.DefaultValues(limits)
+			limits.DiscoverLogLevels = false
+			limits.IngestionRatePolicies = []validation.IngestionRatePolicy{
+				{Name: "batch", Selector: `{namespace="batch"}`, RateMB: 1.0 / bytesInMB, BurstSizeMB: 40.0 / bytesInMB},
+			}
+			tc.limits(limits)
+			require.NoError(t, limits.Validate())
+
+			ingester := &mockIngester{}
+			distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+			_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+				Labels:  `{namespace="batch"}`,
+				Entries: []logproto.Entry{{Timestamp: time.Now(), Line: strings.Repeat("x", 30)}},
+			}}})
+			resp, ok := httpgrpc.HTTPResponseFromError(err)
+			require.True(t, ok)
+			require.Equal(t, tc.expectedCode, resp.Code)
+
+			// the tokens consumed from the policy are given back.
+			require.True(t, distributors[0].ingestionRatePolicyLimiter.AllowN(time.Now(), ingestionRatePolicyKey("test", "batch"), 40))
+		})
+	}
+}
+
+func TestDistributor_PushIdempotencyKey(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.PushIdempotency.Window = model.Duration(time.Minute)
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	push := func(key string) error {
+		_, err := distributors[0].Push(ctx, &logproto.PushRequest{
+			Streams: []logproto.Stream{{
+				Labels:  `{foo="bar"}`,
+				Entries: []logproto.Entry{{Timestamp: time.Now(), Line: "line"}},
+			}},
+			IdempotencyKey: key,
+		})
+		return err
+	}
+	// the distributor returns once a quorum of the ingesters is pushed.
+	requirePushed := func(n int) {
+		require.Eventually(t, func() bool {
+			ingester.mu.Lock()
+			defer ingester.mu.Unlock()
+			return len(ingester.pushed) == n
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	require.NoError(t, push("a"))
+	requirePushed(3)
+	require.Equal(t, "a", ingester.Peek().IdempotencyKey)
+
+	// the retries are acknowledged without being pushed.
+	require.NoError(t, push("a"))
+	requirePushed(3)
+	require.Equal(t, float64(1), testutil.ToFloat64(distributors[0].duplicatePushRequests.WithLabelValues("test")))
+
+	// the push requests without a key are always pushed.
+	require.NoError(t, push(""))
+	require.NoError(t, push(""))
+	requirePushed(9)
+
+	err := push(strings.Repeat("x", idempotency.MaxKeyLength+1))
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+}
+
+func TestDistributor_PushIdempotencyKey_PartiallyRejected(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.PushIdempotency.Window = model.Duration(time.Minute)
+	limits.IngestionRatePolicies = []validation.IngestionRatePolicy{
+		{Name: "batch", Selector: `{namespace="batch"}`, RateMB: 100.0 / bytesInMB, BurstSizeMB: 20.0 / bytesInMB},
+	}
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	push := func() error {
+		req := &logproto.PushRequest{IdempotencyKey: "a"}
+		for _, labels := range []string{`{namespace="web"}`, `{namespace="batch", job="a"}`, `{namespace="batch", job="b"}`} {
+			req.Streams = append(req.Streams, logproto.Stream{
+				Labels:  labels,
+				Entries: []logproto.Entry{{Timestamp: time.Now(), Line: strings.Repeat("x", 15)}},
+			})
+		}
+		_, err := distributors[0].Push(ctx, req)
+		return err
+	}
+	// the distributor returns once a quorum of the ingesters is pushed.
+	requirePushed := func(expected ...string) {
+		require.Eventually(t, func() bool {
+			ingester.mu.Lock()
+			defer ingester.mu.Unlock()
+			var labels []string
+			for _, req := range ingester.pushed {
+				for _, stream := range req.Streams {
+					labels = append(labels, stream.Labels)
+				}
+			}
+			sort.Strings(labels)
+			return slices.Equal(expected, labels)
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	// the burst of the policy is exceeded by the last stream.
+	err := push()
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	requirePushed(
+		`{job="a", namespace="batch"}`, `{job="a", namespace="batch"}`, `{job="a", namespace="batch"}`,
+		`{namespace="web"}`, `{namespace="web"}`, `{namespace="web"}`,
+	)
+
+	// the retry only pushes the rejected stream.
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, push())
+	pushedStreams := []string{
+		`{job="a", namespace="batch"}`, `{job="a", namespace="batch"}`, `{job="a", namespace="batch"}`,
+		`{job="b", namespace="batch"}`, `{job="b", namespace="batch"}`, `{job="b", namespace="batch"}`,
+		`{namespace="web"}`, `{namespace="web"}`, `{namespace="web"}`,
+	}
+	requirePushed(pushedStreams...)
+
+	// the push request is now acknowledged without being pushed.
+	require.NoError(t, push())
+	requirePushed(pushedStreams...)
+	require.Equal(t, float64(1), testutil.ToFloat64(distributors[0].duplicatePushRequests.WithLabelValues("test")))
+}
+
 func prepare(t *testing.T, numDistributors, numIngesters int, limits *validation.Limits, factory func(addr string) (ring_client.PoolClient, error)) ([]*Distributor, []mockIngester) {
 	t.Helper()
 
@@ -0,0 +1,101 @@
+package distributor
+
+import (
+	"unsafe"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log/logfmt"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+// extractFields adds the fields of the JSON or logfmt log line of the entry
+// configured for the tenant to its structured metadata. The fields already
+// present in the structured metadata are not overridden, and the fields which
+// would exceed the structured metadata limits of the tenant are skipped.
+func (d *Distributor) extractFields(vContext validationContext, entry logproto.Entry) []logproto.LabelAdapter {
+	fields := vContext.extractFields
+	metadata := entry.StructuredMetadata
+	if len(fields) == 0 || !vContext.allowStructuredMetadata {
+		return metadata
+	}
+
+	values := extractFieldValues(entry.Line, fields)
+
+	size := 0
+	for _, m := range metadata {
+		size += len(m.Name) + len(m.Value)
+	}
+	count := len(metadata)
+
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		name := fields[i].MetadataName()
+		if hasMetadata(metadata, name) {
+			continue
+		}
+		if (vContext.maxStructuredMetadataSize != 0 && size+len(name)+len(value) > vContext.maxStructuredMetadataSize) ||
+			(vContext.maxStructuredMetadataCount != 0 && count+1 > vContext.maxStructuredMetadataCount) {
+			d.skippedExtractedFields.WithLabelValues(vContext.userID).Inc()
+			continue
+		}
+		metadata = append(metadata, logproto.LabelAdapter{Name: name, Value: value})
+		size += len(name) + len(value)
+		count++
+	}
+	return metadata
+}
+
+// extractFieldValues returns the values of the fields of the JSON or logfmt
+// log line, in the order of the fields. The values of the missing fields are
+// empty.
+func extractFieldValues(line string, fields []validation.ExtractedField) []string {
+	values := make([]string, len(fields))
+	lineSlice := unsafe.Slice(unsafe.StringData(line), len(line))
+
+	if isJSON(line) {
+		paths := make([][]string, len(fields))
+		for i, f := range fields {
+			paths[i] = f.Path
+		}
+		jsonparser.EachKey(lineSlice, func(idx int, value []byte, vt jsonparser.ValueType, err error) {
+			if err != nil {
+				return
+			}
+			switch vt {
+			case jsonparser.String:
+				if s, err := jsonparser.ParseString(value); err == nil {
+					values[idx] = s
+				}
+			case jsonparser.Number, jsonparser.Boolean:
+				values[idx] = string(value)
+			}
+		}, paths...)
+		return values
+	}
+
+	d := logfmt.NewDecoder(lineSlice)
+	for !d.EOL() && d.ScanKeyval() {
+		key := d.Key()
+		for i, f := range fields {
+			if values[i] == "" && string(key) == f.Field {
+				values[i] = string(d.Value())
+			}
+		}
+	}
+	return values
+}
+
+// hasMetadata returns whether the structured metadata has an entry with the
+// given name.
+func hasMetadata(metadata []logproto.LabelAdapter, name string) bool {
+	for _, m := range metadata {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	ring_client "github.com/grafana/dskit/ring/client"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestDistributor_PushExtractFields(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.DiscoverLogLevels = false
+	limits.ExtractFields = []validation.ExtractedField{
+		{Field: "trace_id"},
+		{Field: "user.id", Name: "user_id"},
+		{Field: "status"},
+	}
+	limits.MaxStructuredMetadataEntriesCount = 3
+	require.NoError(t, limits.Validate())
+
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	pushEntry := func(line string, metadata push.LabelsAdapter) push.LabelsAdapter {
+		_, err := distributors[0].Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{
+			Labels:  `{app="api"}`,
+			Entries: []logproto.Entry{{Timestamp: time.Now(), Line: line, StructuredMetadata: metadata}},
+		}}})
+		require.NoError(t, err)
+
+		ingester.mu.Lock()
+		defer ingester.mu.Unlock()
+		return ingester.pushed[len(ingester.pushed)-1].Streams[0].Entries[0].StructuredMetadata
+	}
+
+	require.Equal(t,
+		push.LabelsAdapter{{Name: "trace_id", Value: "abc"}, {Name: "user_id", Value: "42"}, {Name: "status", Value: "200"}},
+		pushEntry(`{"trace_id": "abc", "user": {"id": 42}, "status": "200"}`, nil),
+	)
+	require.Equal(t,
+		push.LabelsAdapter{{Name: "trace_id", Value: "abc"}, {Name: "user_id", Value: "42"}, {Name: "status", Value: "500"}},
+		pushEntry(`level=error trace_id=abc user.id=42 status=500`, nil),
+	)
+	// the structured metadata is not overridden.
+	require.Equal(t,
+		push.LabelsAdapter{{Name: "trace_id", Value: "def"}, {Name: "status", Value: "404"}},
+		pushEntry(`trace_id=abc status=404`, push.LabelsAdapter{{Name: "trace_id", Value: "def"}}),
+	)
+	// the fields exceeding the structured metadata limits are skipped.
+	require.Equal(t,
+		push.LabelsAdapter{{Name: "pod", Value: "a"}, {Name: "node", Value: "b"}, {Name: "trace_id", Value: "abc"}},
+		pushEntry(`trace_id=abc status=200`, push.LabelsAdapter{{Name: "pod", Value: "a"}, {Name: "node", Value: "b"}}),
+	)
+	require.Equal(t, float64(1), testutil.ToFloat64(distributors[0].skippedExtractedFields.WithLabelValues("test")))
+}
+
+func Test_ExtractFieldValues(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.ExtractFields = []validation.ExtractedField{
+		{Field: "trace_id"},
+		{Field: "http.status"},
+		{Field: "ok"},
+	}
+	require.NoError(t, limits.Validate())
+	require.Equal(t, "http_status", limits.ExtractFields[1].MetadataName())
+
+	for _, tc := range []struct {
+		name   string
+		line   string
+		values []string
+	}{
+		{
+			name:   "json",
+			line:   `{"trace_id": "a\"b", "http": {"status": 200}, "ok": true}`,
+			values: []string{`a"b`, "200", "true"},
+		},
+		{
+			name:   "json objects are skipped",
+			line:   `{"trace_id": {"id": "a"}, "http": {"status": [200]}}`,
+			values: []string{"", "", ""},
+		},
+		{
+			name:   "logfmt",
+			line:   `trace_id="a b" http.status=200 msg=hello`,
+			values: []string{"a b", "200", ""},
+		},
+		{
+			name:   "unstructured",
+			line:   `trace_id is missing`,
+			values: []string{"", "", ""},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.values, extractFieldValues(tc.line, limits.ExtractFields))
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/logproto"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
@@ -46,12 +47,24 @@ func (i *otelErrorHeaderInterceptor) WriteHeader(statusCode int) {
 }
 
 func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRequestParser push.RequestParser) {
+	tenantID, req, ok := d.parsePushRequest(w, r, pushRequestParser)
+	if !ok {
+		return
+	}
+
+	_, err := d.Push(r.Context(), req)
+	d.writePushResponse(w, r, tenantID, err)
+}
+
+// parsePushRequest parses the push request with the given parser. On failure,
+// it writes the error to the response and returns false.
+func (d *Distributor) parsePushRequest(w http.ResponseWriter, r *http.Request, pushRequestParser push.RequestParser) (string, *logproto.PushRequest, bool) {
 	logger := util_log.WithContext(r.Context(), util_log.Logger)
 	tenantID, err := tenant.TenantID(r.Context())
 	if err != nil {
 		level.Error(logger).Log("msg", "error getting tenant id", "err", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return "", nil, false
 	}
 
 	if d.RequestParserWrapper != nil {
@@ -71,7 +84,7 @@ func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRe
 		d.writeFailuresManager.Log(tenantID, fmt.Errorf("couldn't parse push request: %w", err))
 
 		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return "", nil, false
 	}
 
 	if logPushRequestStreams {
@@ -84,8 +97,13 @@ func (d *Distributor) pushHandler(w http.ResponseWriter, r *http.Request, pushRe
 			"streams", sb.String(),
 		)
 	}
+	return tenantID, req, true
+}
 
-	_, err = d.Push(r.Context(), req)
+// writePushResponse writes the response to a push request, given the error
+// returned by the push.
+func (d *Distributor) writePushResponse(w http.ResponseWriter, r *http.Request, tenantID string, err error) {
+	logger := util_log.WithContext(r.Context(), util_log.Logger)
 	if err == nil {
 		if d.tenantConfigs.LogPushRequest(tenantID) {
 			level.Debug(logger).Log(
@@ -0,0 +1,270 @@
+package distributor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/loghttp/push"
+)
+
+// elasticsearchVersion is the version of Elasticsearch reported to the
+// clients of the Elasticsearch compatible API.
+const elasticsearchVersion = "8.0.0"
+
+// Codes of the responses of the Splunk HTTP Event Collector API.
+const (
+	splunkHECSuccess           = 0
+	splunkHECInvalidDataFormat = 6
+	splunkHECServerBusy        = 9
+	splunkHECHealthy           = 17
+)
+
+// ElasticsearchBulkHandler implements the bulk API of Elasticsearch, pushing
+// the indexed documents as log lines.
+//
+// The log lines rejected by the distributor are recorded while pushing them,
+// so that only the items of the rejected documents fail, as Elasticsearch
+// reports the failures of a bulk request per item. All the items fail if the
+// whole push request failed, or if its rejected log lines can't be mapped
+// back to their documents.
+func (d *Distributor) ElasticsearchBulkHandler(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	resp := &push.ElasticsearchBulkResponse{Items: []map[string]push.ElasticsearchBulkItem{}}
+	rec := &pushResponseRecorder{header: make(http.Header)}
+
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	tenantID, req, ok := d.parsePushRequest(rec, r, push.ElasticsearchBulkParser(resp))
+	if !ok {
+		writeJSONResponse(w, rec.code, struct {
+			Error  push.ElasticsearchError `json:"error"`
+			Status int                     `json:"status"`
+		}{
+			Error:  elasticsearchError(rec.code, rec.message()),
+			Status: rec.code,
+		})
+		return
+	}
+
+	rejections := &pushRejections{}
+	pushResp, err := d.Push(context.WithValue(r.Context(), pushRejectionsKey{}, rejections), req)
+	rec = &pushResponseRecorder{header: make(http.Header)}
+	d.writePushResponse(rec, r, tenantID, err)
+	switch {
+	case !rec.failed():
+	case pushResp == nil || rejections.unmapped:
+		pushErr := elasticsearchError(rec.code, rec.message())
+		resp.Reject(func(string, int) (int, *push.ElasticsearchError) {
+			return rec.code, &pushErr
+		})
+	default:
+		resp.Reject(func(stream string, entry int) (int, *push.ElasticsearchError) {
+			rejection, ok := rejections.rejected(stream, entry)
+			if !ok {
+				return 0, nil
+			}
+			pushErr := elasticsearchError(rejection.code, rejection.err.Error())
+			return rejection.code, &pushErr
+		})
+	}
+
+	resp.Took = time.Since(start).Milliseconds()
+	writeJSONResponse(w, http.StatusOK, resp)
+}
+
+// elasticsearchError returns the Elasticsearch error of a failed push.
+func elasticsearchError(code int, reason string) push.ElasticsearchError {
+	errorType := "illegal_argument_exception"
+	switch {
+	case code == http.StatusTooManyRequests:
+		// the clients retry the requests and items rejected with this error.
+		errorType = "es_rejected_execution_exception"
+	case code >= http.StatusInternalServerError:
+		errorType = "exception"
+	}
+	return push.ElasticsearchError{Type: errorType, Reason: reason}
+}
+
+type pushRejectionsKey struct{}
+
+// pushRejections records the log lines of a push request rejected by the
+// distributor, by the labels of their stream as pushed and their index in the
+// stream, so that the compatible APIs reporting the failures per log line can
+// fail the rejected log lines only.
+type pushRejections struct {
+	streams map[string]*streamRejections
+	// unmapped is set when log lines were rejected after their stream was
+	// rewritten by the ingestion pipelines or sampled, so that they can't be
+	// mapped back to the pushed log lines.
+	unmapped bool
+}
+
+type streamRejections struct {
+	// stream is set when the whole stream was rejected.
+	stream  *pushRejection
+	entries map[int]pushRejection
+}
+
+// pushRejection is the status code and the error of a rejected log line.
+type pushRejection struct {
+	code int
+	err  error
+}
+
+// pushRejectionsFromContext returns the rejections recorder of the push
+// request, if any.
+func pushRejectionsFromContext(ctx context.Context) *pushRejections {
+	rejections, _ := ctx.Value(pushRejectionsKey{}).(*pushRejections)
+	return rejections
+}
+
+func (r *pushRejections) stream(labels string) *streamRejections {
+	if r.streams == nil {
+		r.streams = make(map[string]*streamRejections)
+	}
+	s, ok := r.streams[labels]
+	if !ok {
+		s = &streamRejections{}
+		r.streams[labels] = s
+	}
+	return s
+}
+
+// rejectStream records that the whole stream with the given labels was
+// rejected.
+func (r *pushRejections) rejectStream(labels string, code int, err error) {
+	if r == nil {
+		return
+	}
+	r.stream(labels).stream = &pushRejection{code: code, err: err}
+}
+
+// rejectEntry records that the log line at the given index of the stream
+// with the given labels was rejected.
+func (r *pushRejections) rejectEntry(labels string, entry int, code int, err error) {
+	if r == nil {
+		return
+	}
+	s := r.stream(labels)
+	if s.entries == nil {
+		s.entries = make(map[int]pushRejection)
+	}
+	s.entries[entry] = pushRejection{code: code, err: err}
+}
+
+// unmap records that log lines were rejected without being mapped back to the
+// pushed log lines.
+func (r *pushRejections) unmap() {
+	if r == nil {
+		return
+	}
+	r.unmapped = true
+}
+
+// rejected returns the rejection of the log line at the given index of the
+// stream with the given labels, if any.
+func (r *pushRejections) rejected(labels string, entry int) (pushRejection, bool) {
+	s, ok := r.streams[labels]
+	if !ok {
+		return pushRejection{}, false
+	}
+	if s.stream != nil {
+		return *s.stream, true
+	}
+	rejection, ok := s.entries[entry]
+	return rejection, ok
+}
+
+// ElasticsearchInfoHandler implements the root API of Elasticsearch, used by
+// the clients to check the version of the cluster before pushing documents.
+func (d *Distributor) ElasticsearchInfoHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	writeJSONResponse(w, http.StatusOK, map[string]any{
+		"name":         "loki",
+		"cluster_name": "loki",
+		"version": map[string]any{
+			"number":       elasticsearchVersion,
+			"build_flavor": "default",
+		},
+		"tagline": "You Know, for Search",
+	})
+}
+
+// SplunkHECHandler implements the event endpoint of the Splunk HTTP Event
+// Collector API.
+func (d *Distributor) SplunkHECHandler(w http.ResponseWriter, r *http.Request) {
+	rec := &pushResponseRecorder{header: make(http.Header)}
+	d.pushHandler(rec, r, push.ParseSplunkHECRequest)
+
+	if !rec.failed() {
+		writeSplunkHECResponse(w, http.StatusOK, "Success", splunkHECSuccess)
+		return
+	}
+
+	switch {
+	case rec.code == http.StatusTooManyRequests || rec.code >= http.StatusInternalServerError:
+		// the clients retry the requests rejected because the server is busy.
+		writeSplunkHECResponse(w, http.StatusServiceUnavailable, rec.message(), splunkHECServerBusy)
+	default:
+		writeSplunkHECResponse(w, rec.code, rec.message(), splunkHECInvalidDataFormat)
+	}
+}
+
+// SplunkHECHealthHandler implements the health endpoint of the Splunk HTTP
+// Event Collector API.
+func (d *Distributor) SplunkHECHealthHandler(w http.ResponseWriter, _ *http.Request) {
+	writeSplunkHECResponse(w, http.StatusOK, "HEC is healthy", splunkHECHealthy)
+}
+
+func writeSplunkHECResponse(w http.ResponseWriter, statusCode int, text string, code int) {
+	writeJSONResponse(w, statusCode, struct {
+		Text string `json:"text"`
+		Code int    `json:"code"`
+	}{Text: text, Code: code})
+}
+
+func writeJSONResponse(w http.ResponseWriter, statusCode int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(data)
+}
+
+// pushResponseRecorder records the response of the push handler, so that it
+// can be translated to the response of a compatible API.
+type pushResponseRecorder struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (r *pushResponseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *pushResponseRecorder) WriteHeader(statusCode int) {
+	r.code = statusCode
+}
+
+func (r *pushResponseRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *pushResponseRecorder) failed() bool {
+	return r.code >= http.StatusBadRequest
+}
+
+// message returns the error message written by the push handler.
+func (r *pushResponseRecorder) message() string {
+	return strings.TrimSpace(r.body.String())
+}
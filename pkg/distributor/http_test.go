@@ -2,12 +2,16 @@ package distributor
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-kit/log"
+	ring_client "github.com/grafana/dskit/ring/client"
 	"github.com/grafana/dskit/user"
 
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
@@ -115,6 +119,146 @@ func Test_OtelErrorHeaderInterceptor(t *testing.T) {
 	}
 }
 
+func TestElasticsearchBulkHandler(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.RejectOldSamples = false
+	limits.MaxLineSize = 10
+	limits.IngestionRatePolicies = []validation.IngestionRatePolicy{
+		{Name: "limited", Selector: `{index="limited"}`, RateMB: 1.0 / bytesInMB, BurstSizeMB: 1.0 / bytesInMB},
+	}
+	require.NoError(t, limits.Validate())
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	for _, tc := range []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "success",
+			body:         "{\"index\":{\"_index\":\"logs\"}}\n{\"message\":\"hello\"}\n{\"delete\":{\"_id\":\"1\"}}\n",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"errors":true,"items":[{"index":{"_index":"logs","status":201,"result":"created"}},{"delete":{"_id":"1","status":400,"error":{"type":"illegal_argument_exception","reason":"the delete action is not supported"}}}]}`,
+		},
+		{
+			name:         "partially rejected",
+			body:         "{\"index\":{\"_index\":\"big\"}}\n{\"message\":\"hello world!\"}\n{\"index\":{\"_index\":\"logs\"}}\n{\"message\":\"hello\"}\n",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"errors":true,"items":[{"index":{"_index":"big","status":400,"error":{"type":"illegal_argument_exception","reason":"Max entry size '10' bytes exceeded for stream '{index=\"big\", service_name=\"unknown_service\"}' while adding an entry with length '12' bytes"}}},{"index":{"_index":"logs","status":201,"result":"created"}}]}`,
+		},
+		{
+			name:         "partially rejected stream",
+			body:         "{\"index\":{\"_index\":\"logs\"}}\n{\"message\":\"hello world!\"}\n{\"index\":{\"_index\":\"logs\"}}\n{\"message\":\"hello\"}\n",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"errors":true,"items":[{"index":{"_index":"logs","status":400,"error":{"type":"illegal_argument_exception","reason":"Max entry size '10' bytes exceeded for stream '{index=\"logs\", service_name=\"unknown_service\"}' while adding an entry with length '12' bytes"}}},{"index":{"_index":"logs","status":201,"result":"created"}}]}`,
+		},
+		{
+			name:         "rate limited stream",
+			body:         "{\"index\":{\"_index\":\"limited\"}}\n{\"message\":\"hello\"}\n{\"index\":{\"_index\":\"logs\"}}\n{\"message\":\"hello\"}\n",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"errors":true,"items":[{"index":{"_index":"limited","status":429,"error":{"type":"es_rejected_execution_exception","reason":"Ingestion rate limit of policy limited exceeded for user test (limit: 1 bytes/sec) while attempting to ingest '1' lines totaling '5' bytes for stream {index=\"limited\", service_name=\"unknown_service\"}, reduce log volume or contact your Loki administrator to see if the limit can be increased"}}},{"index":{"_index":"logs","status":201,"result":"created"}}]}`,
+		},
+		{
+			name:         "malformed",
+			body:         "{\"index\":",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":{"type":"illegal_argument_exception","reason":"malformed action/metadata line [1], expected a single action"},"status":400}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/elasticsearch/_bulk", strings.NewReader(tc.body))
+			req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+			w := httptest.NewRecorder()
+			distributors[0].ElasticsearchBulkHandler(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, "Elasticsearch", w.Header().Get("X-Elastic-Product"))
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			delete(body, "took")
+			expected, err := json.Marshal(body)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expectedBody, string(expected))
+		})
+	}
+	require.Equal(t, `{index="logs", service_name="unknown_service"}`, ingester.Peek().Streams[0].Labels)
+
+	// the streams of a bulk request are pushed together, once per ingester.
+	requirePushed := func(n int) {
+		require.Eventually(t, func() bool {
+			ingester.mu.Lock()
+			defer ingester.mu.Unlock()
+			return len(ingester.pushed) == n
+		}, time.Second, 10*time.Millisecond)
+	}
+	requirePushed(12)
+	ingester.mu.Lock()
+	ingester.pushed = nil
+	ingester.mu.Unlock()
+	req := httptest.NewRequest(http.MethodPost, "/elasticsearch/_bulk", strings.NewReader("{\"index\":{\"_index\":\"a\"}}\n{\"message\":\"hello\"}\n{\"index\":{\"_index\":\"b\"}}\n{\"message\":\"hello\"}\n"))
+	req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+	w := httptest.NewRecorder()
+	distributors[0].ElasticsearchBulkHandler(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	requirePushed(3)
+	require.Len(t, ingester.Peek().Streams, 2)
+}
+
+func TestSplunkHECHandler(t *testing.T) {
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.RejectOldSamples = false
+	limits.IngestionRateMB = 100.0 / bytesInMB
+	limits.IngestionBurstSizeMB = 100.0 / bytesInMB
+	ingester := &mockIngester{}
+	distributors, _ := prepare(t, 1, 3, limits, func(_ string) (ring_client.PoolClient, error) { return ingester, nil })
+
+	for _, tc := range []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "success",
+			body:         `{"event":"hello","sourcetype":"app"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"text":"Success","code":0}`,
+		},
+		{
+			name:         "invalid",
+			body:         `{"host":"web1"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"text":"invalid event 0: event field is required","code":6}`,
+		},
+		{
+			name:         "rate limited",
+			body:         `{"event":"` + strings.Repeat("a", 200) + `"}`,
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/services/collector/event", strings.NewReader(tc.body))
+			req = req.WithContext(user.InjectOrgID(req.Context(), "test"))
+			w := httptest.NewRecorder()
+			distributors[0].SplunkHECHandler(w, req)
+
+			require.Equal(t, tc.expectedCode, w.Code, w.Body.String())
+			if tc.expectedBody != "" {
+				require.JSONEq(t, tc.expectedBody, w.Body.String())
+				return
+			}
+			var body struct{ Code int }
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			require.Equal(t, splunkHECServerBusy, body.Code)
+		})
+	}
+	require.Equal(t, `{service_name="unknown_service", sourcetype="app"}`, ingester.Peek().Streams[0].Labels)
+}
+
 func stubParser(
 	_ string,
 	_ *http.Request,
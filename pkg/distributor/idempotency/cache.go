@@ -0,0 +1,168 @@
+package idempotency
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Status is the status of the idempotency key of a push request.
+type Status int
+
+const (
+	// Reserved means that the key was not seen in the window, and is now
+	// reserved by the push request.
+	Reserved Status = iota
+	// InProgress means that another push request with the key is in progress.
+	InProgress
+	// Done means that a push request with the key succeeded in the window.
+	Done
+)
+
+// Cache remembers the idempotency keys of the push requests of the tenants
+// for a bounded window, so that the retries of the push requests can be
+// acknowledged without pushing their log lines again.
+type Cache struct {
+	mtx     sync.Mutex
+	tenants map[string]*tenantKeys
+	now     func() time.Time
+}
+
+type tenantKeys struct {
+	keys map[string]*list.Element
+	// order holds the entries from the first to the last one to expire.
+	order *list.List
+}
+
+type entry struct {
+	key        string
+	expires    time.Time
+	done       bool
+	inProgress bool
+	// appended holds the streams appended by the failed push requests of the
+	// key, which are skipped by its retries.
+	appended map[string]struct{}
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		tenants: make(map[string]*tenantKeys),
+		now:     time.Now,
+	}
+}
+
+// Reserve reserves the idempotency key of a push request of the tenant, unless
+// the key is already known. The key must be committed once the push request
+// succeeds, or released otherwise.
+func (c *Cache) Reserve(tenant, key string, cfg Config) Status {
+	now := c.now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	t, ok := c.tenants[tenant]
+	if !ok {
+		t = &tenantKeys{keys: make(map[string]*list.Element), order: list.New()}
+		c.tenants[tenant] = t
+	}
+	t.evictExpired(now)
+
+	if el, ok := t.keys[key]; ok {
+		e := el.Value.(*entry)
+		switch {
+		case e.done:
+			return Done
+		case e.inProgress:
+			return InProgress
+		}
+		// a retry of a push request which partially failed.
+		e.inProgress = true
+		return Reserved
+	}
+
+	for cfg.MaxKeys > 0 && t.order.Len() >= cfg.MaxKeys {
+		t.remove(t.order.Front())
+	}
+	t.keys[key] = t.order.PushBack(&entry{key: key, expires: now.Add(cfg.window()), inProgress: true})
+	return Reserved
+}
+
+// Appended returns the streams appended by the previous push requests of a
+// reserved key, which failed after appending them. The returned map must not
+// be modified.
+func (c *Cache) Appended(tenant, key string) map[string]struct{} {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	t, ok := c.tenants[tenant]
+	if !ok {
+		return nil
+	}
+	if el, ok := t.keys[key]; ok {
+		return el.Value.(*entry).appended
+	}
+	return nil
+}
+
+// Commit records that the push request of a reserved key succeeded, so that
+// the key is remembered for the window.
+func (c *Cache) Commit(tenant, key string, cfg Config) {
+	now := c.now()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	t, ok := c.tenants[tenant]
+	if !ok {
+		return
+	}
+	if el, ok := t.keys[key]; ok {
+		e := el.Value.(*entry)
+		e.done = true
+		e.inProgress = false
+		e.appended = nil
+		e.expires = now.Add(cfg.window())
+		t.order.MoveToBack(el)
+	}
+}
+
+// Release releases a reserved key whose push request failed, so that the push
+// request can be retried. The key is forgotten, unless the push request
+// appended some streams before failing: these are remembered for the window,
+// so that the retries only push the other streams.
+func (c *Cache) Release(tenant, key string, appended ...string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	t, ok := c.tenants[tenant]
+	if !ok {
+		return
+	}
+	if el, ok := t.keys[key]; ok && !el.Value.(*entry).done {
+		e := el.Value.(*entry)
+		e.inProgress = false
+		for _, stream := range appended {
+			if e.appended == nil {
+				e.appended = make(map[string]struct{}, len(appended))
+			}
+			e.appended[stream] = struct{}{}
+		}
+		if len(e.appended) == 0 {
+			t.remove(el)
+		}
+	}
+	if t.order.Len() == 0 {
+		delete(c.tenants, tenant)
+	}
+}
+
+func (t *tenantKeys) evictExpired(now time.Time) {
+	for el := t.order.Front(); el != nil && !el.Value.(*entry).expires.After(now); el = t.order.Front() {
+		t.remove(el)
+	}
+}
+
+func (t *tenantKeys) remove(el *list.Element) {
+	delete(t.keys, el.Value.(*entry).key)
+	t.order.Remove(el)
+}
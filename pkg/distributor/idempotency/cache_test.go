@@ -0,0 +1,76 @@
+package idempotency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewCache()
+	c.now = func() time.Time { return now }
+	cfg := Config{Window: model.Duration(time.Minute)}
+
+	require.Equal(t, Reserved, c.Reserve("fake", "a", cfg))
+	require.Equal(t, InProgress, c.Reserve("fake", "a", cfg))
+	require.Equal(t, Reserved, c.Reserve("other", "a", cfg))
+
+	c.Commit("fake", "a", cfg)
+	require.Equal(t, Done, c.Reserve("fake", "a", cfg))
+
+	// the failed push requests can be retried.
+	require.Equal(t, Reserved, c.Reserve("fake", "b", cfg))
+	c.Release("fake", "b")
+	require.Equal(t, Reserved, c.Reserve("fake", "b", cfg))
+
+	// the window starts when the push request succeeds.
+	now = now.Add(30 * time.Second)
+	c.Commit("fake", "b", cfg)
+	now = now.Add(40 * time.Second)
+	require.Equal(t, Reserved, c.Reserve("fake", "a", cfg))
+	require.Equal(t, Done, c.Reserve("fake", "b", cfg))
+}
+
+func TestCacheMaxKeys(t *testing.T) {
+	c := NewCache()
+	cfg := Config{Window: model.Duration(time.Minute), MaxKeys: 2}
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.Equal(t, Reserved, c.Reserve("fake", key, cfg))
+		c.Commit("fake", key, cfg)
+	}
+	require.Equal(t, Done, c.Reserve("fake", "c", cfg))
+	require.Equal(t, Done, c.Reserve("fake", "b", cfg))
+	require.Equal(t, Reserved, c.Reserve("fake", "a", cfg))
+}
+
+func TestCacheRelease(t *testing.T) {
+	c := NewCache()
+	cfg := Config{Window: model.Duration(time.Minute)}
+
+	require.Equal(t, Reserved, c.Reserve("fake", "a", cfg))
+	c.Commit("fake", "a", cfg)
+	// the committed keys are not released.
+	c.Release("fake", "a")
+	require.Equal(t, Done, c.Reserve("fake", "a", cfg))
+
+	require.Equal(t, Reserved, c.Reserve("other", "a", cfg))
+	c.Release("other", "a")
+	require.NotContains(t, c.tenants, "other")
+
+	// the streams appended before a failure are remembered for the retries.
+	require.Equal(t, Reserved, c.Reserve("fake", "b", cfg))
+	require.Nil(t, c.Appended("fake", "b"))
+	c.Release("fake", "b", `{foo="bar"}`)
+	require.Equal(t, Reserved, c.Reserve("fake", "b", cfg))
+	require.Equal(t, InProgress, c.Reserve("fake", "b", cfg))
+	require.Equal(t, map[string]struct{}{`{foo="bar"}`: {}}, c.Appended("fake", "b"))
+	c.Release("fake", "b", `{foo="baz"}`)
+	require.Equal(t, Reserved, c.Reserve("fake", "b", cfg))
+	require.Len(t, c.Appended("fake", "b"), 2)
+	c.Commit("fake", "b", cfg)
+	require.Equal(t, Done, c.Reserve("fake", "b", cfg))
+}
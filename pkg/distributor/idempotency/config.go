@@ -0,0 +1,38 @@
+package idempotency
+
+import (
+	"flag"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// MaxKeyLength is the maximum length of the idempotency keys of the push
+// requests.
+const MaxKeyLength = 256
+
+const (
+	KeyTooLongErrorMsg    = "idempotency key too long: '%d' bytes, limit: '%d' bytes"
+	KeyInProgressErrorMsg = "a push request with the idempotency key '%s' is in progress, retry later"
+)
+
+type Config struct {
+	Window model.Duration `yaml:"window" json:"window" doc:"description=Window during which the idempotency keys of the push requests are remembered by the distributors and the ingesters. The push requests with the key of a push request which succeeded in the window are acknowledged without pushing their log lines again, so that retried batches are not duplicated. The key is set with the 'Idempotency-Key' header or the 'idempotencyKey' field of the push request. 0 disables the idempotency keys."`
+
+	MaxKeys int `yaml:"max_keys" json:"max_keys" doc:"description=Maximum number of idempotency keys remembered per tenant by each distributor and ingester. The oldest keys are forgotten first. 0 means no limit."`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.Var(&cfg.Window, prefix+".window", "Window during which the idempotency keys of the push requests are remembered. 0 disables the idempotency keys.")
+	fs.IntVar(&cfg.MaxKeys, prefix+".max-keys", 100000, "Maximum number of idempotency keys remembered per tenant. 0 means no limit.")
+}
+
+// Enabled returns whether the idempotency keys of the push requests are
+// remembered.
+func (cfg Config) Enabled() bool {
+	return cfg.Window > 0
+}
+
+func (cfg Config) window() time.Duration {
+	return time.Duration(cfg.Window)
+}
@@ -0,0 +1,179 @@
+package distributor
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+// ingestionPipelines runs the ingestion pipelines of the tenants on the pushed
+// streams.
+//
+// The log pipelines are neither safe for concurrent use nor cheap to build, as
+// they compile their regular expressions and templates, so they are pooled by
+// expression.
+type ingestionPipelines struct {
+	pools sync.Map // string -> *sync.Pool
+}
+
+// pipelineResult are the streams produced by the ingestion pipelines and the
+// log lines they dropped.
+type pipelineResult struct {
+	streams      []logproto.Stream
+	dropped      []logproto.Stream
+	droppedLines int
+	droppedBytes int
+}
+
+// process runs the ingestion pipelines on the given streams. The pipelines are
+// run on the streams matching their selector in the order they are configured,
+// each one on the output of the previous one.
+//
+// A stream is split into several streams when the pipelines change its stream
+// labels differently for its log lines.
+func (p *ingestionPipelines) process(cfgs []validation.IngestionPipeline, streams []logproto.Stream) pipelineResult {
+	res := pipelineResult{streams: streams}
+	if len(cfgs) == 0 {
+		return res
+	}
+
+	res.streams = make([]logproto.Stream, 0, len(streams))
+	for _, stream := range streams {
+		lbs, err := syntax.ParseLabels(stream.Labels)
+		if err != nil {
+			// invalid labels are rejected by the validation of the stream.
+			res.streams = append(res.streams, stream)
+			continue
+		}
+
+		var matching []validation.IngestionPipeline
+		for _, cfg := range cfgs {
+			if cfg.Expr != nil && matchesAll(cfg.Expr.Matchers(), lbs) {
+				matching = append(matching, cfg)
+			}
+		}
+		if len(matching) == 0 {
+			res.streams = append(res.streams, stream)
+			continue
+		}
+
+		pipelines := make([]log.Pipeline, len(matching))
+		for i, cfg := range matching {
+			pipelines[i] = p.get(cfg)
+		}
+		p.processStream(&res, stream, lbs, matching, pipelines)
+		for i, cfg := range matching {
+			p.put(cfg, pipelines[i])
+		}
+	}
+	return res
+}
+
+func (p *ingestionPipelines) processStream(res *pipelineResult, stream logproto.Stream, lbs labels.Labels, cfgs []validation.IngestionPipeline, pipelines []log.Pipeline) {
+	// streams produced from this stream by their labels.
+	outputs := make(map[string]int)
+	var dropped []logproto.Entry
+
+	for _, entry := range stream.Entries {
+		entryLabels, line, metadata := lbs, entry.Line, logproto.FromLabelAdaptersToLabels(entry.StructuredMetadata)
+
+		kept := true
+		for i, pipeline := range pipelines {
+			resultLine, result, ok := pipeline.ForStream(entryLabels).ProcessString(entry.Timestamp.UnixNano(), line, metadata...)
+			if !ok {
+				kept = false
+				break
+			}
+			line = resultLine
+			entryLabels, metadata = splitPipelineLabels(cfgs[i], entryLabels, result)
+		}
+		if !kept {
+			res.droppedLines++
+			res.droppedBytes += len(entry.Line)
+			dropped = append(dropped, entry)
+			continue
+		}
+
+		key := entryLabels.String()
+		idx, ok := outputs[key]
+		if !ok {
+			idx = len(res.streams)
+			outputs[key] = idx
+			res.streams = append(res.streams, logproto.Stream{Labels: key})
+		}
+		res.streams[idx].Entries = append(res.streams[idx].Entries, logproto.Entry{
+			Timestamp:          entry.Timestamp,
+			Line:               line,
+			StructuredMetadata: logproto.FromLabelsToLabelAdapters(metadata),
+		})
+	}
+
+	if len(dropped) > 0 {
+		res.dropped = append(res.dropped, logproto.Stream{Labels: stream.Labels, Entries: dropped})
+	}
+}
+
+// splitPipelineLabels returns the stream labels and the structured metadata of
+// a log line processed by an ingestion pipeline. The labels extracted by the
+// pipeline are structured metadata, unless they override a stream label or are
+// configured as stream labels.
+func splitPipelineLabels(cfg validation.IngestionPipeline, stream labels.Labels, result log.LabelsResult) (labels.Labels, labels.Labels) {
+	streamBuilder := labels.NewBuilder(result.Stream())
+	metadata := labels.NewBuilder(result.StructuredMetadata())
+	for _, l := range result.Parsed() {
+		switch {
+		case l.Name == logqlmodel.ErrorLabel || l.Name == logqlmodel.ErrorDetailsLabel:
+			// the errors of the pipeline are not stored.
+		case stream.Has(l.Name) || slices.Contains(cfg.StreamLabels, l.Name):
+			streamBuilder.Set(l.Name, l.Value)
+			metadata.Del(l.Name)
+		default:
+			metadata.Set(l.Name, l.Value)
+		}
+	}
+	return streamBuilder.Labels(), metadata.Labels()
+}
+
+func (p *ingestionPipelines) get(cfg validation.IngestionPipeline) log.Pipeline {
+	pool, ok := p.pools.Load(pipelineKey(cfg))
+	if !ok {
+		pool, _ = p.pools.LoadOrStore(pipelineKey(cfg), &sync.Pool{})
+	}
+	if pipeline, ok := pool.(*sync.Pool).Get().(log.Pipeline); ok {
+		return pipeline
+	}
+	// the expression is validated when the limits are loaded.
+	pipeline, err := cfg.Expr.Pipeline()
+	if err != nil {
+		return log.NewNoopPipeline()
+	}
+	return pipeline
+}
+
+func (p *ingestionPipelines) put(cfg validation.IngestionPipeline, pipeline log.Pipeline) {
+	// the stream pipelines cached by the pipeline are not reused across pushes.
+	pipeline.Reset()
+	if pool, ok := p.pools.Load(pipelineKey(cfg)); ok {
+		pool.(*sync.Pool).Put(pipeline)
+	}
+}
+
+func pipelineKey(cfg validation.IngestionPipeline) string {
+	return cfg.Selector + " " + cfg.Pipeline
+}
+
+func matchesAll(matchers []*labels.Matcher, lbs labels.Labels) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/flagext"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func ingestionPipelinesLimits(t *testing.T, pipelines ...validation.IngestionPipeline) *validation.Limits {
+	t.Helper()
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionPipelines = pipelines
+	require.NoError(t, limits.Validate())
+	return limits
+}
+
+func Test_ingestionPipelines(t *testing.T) {
+	limits := ingestionPipelinesLimits(t,
+		validation.IngestionPipeline{
+			Selector:     `{app="nginx"}`,
+			Pipeline:     `!= "healthcheck" | regexp "user=(?P<user>\\w+)" | label_format team="{{.namespace}}"`,
+			StreamLabels: []string{"team"},
+		},
+		validation.IngestionPipeline{
+			Selector: `{app="nginx"}`,
+			Pipeline: "| line_format `{{ regexReplaceAll \"password=\\\\S+\" __line__ \"password=***\" }}`",
+		},
+	)
+
+	ts := time.Unix(0, 1)
+	entry := func(line string, metadata ...logproto.LabelAdapter) logproto.Entry {
+		return logproto.Entry{Timestamp: ts, Line: line, StructuredMetadata: metadata}
+	}
+
+	var pipelines ingestionPipelines
+	res := pipelines.process(limits.IngestionPipelines, []logproto.Stream{
+		{
+			Labels: `{app="nginx", namespace="dev"}`,
+			Entries: []logproto.Entry{
+				entry("GET /healthcheck"),
+				entry("user=alice password=secret", logproto.LabelAdapter{Name: "trace_id", Value: "1234"}),
+				entry("GET /"),
+			},
+		},
+		{
+			Labels:  `{app="api"}`,
+			Entries: []logproto.Entry{entry("GET /healthcheck")},
+		},
+	})
+
+	require.Equal(t, []logproto.Stream{
+		{
+			Labels: `{app="nginx", namespace="dev", team="dev"}`,
+			Entries: []logproto.Entry{
+				entry("user=alice password=***",
+					logproto.LabelAdapter{Name: "trace_id", Value: "1234"},
+					logproto.LabelAdapter{Name: "user", Value: "alice"},
+				),
+				entry("GET /"),
+			},
+		},
+		{
+			Labels:  `{app="api"}`,
+			Entries: []logproto.Entry{entry("GET /healthcheck")},
+		},
+	}, res.streams)
+
+	require.Equal(t, []logproto.Stream{
+		{
+			Labels:  `{app="nginx", namespace="dev"}`,
+			Entries: []logproto.Entry{entry("GET /healthcheck")},
+		},
+	}, res.dropped)
+	require.Equal(t, 1, res.droppedLines)
+	require.Equal(t, len("GET /healthcheck"), res.droppedBytes)
+}
+
+func Test_ingestionPipelinesSplitStreams(t *testing.T) {
+	limits := ingestionPipelinesLimits(t, validation.IngestionPipeline{
+		Selector: `{app="nginx"}`,
+		Pipeline: `| logfmt | label_format app="nginx-{{.status}}" | drop status`,
+	})
+
+	var pipelines ingestionPipelines
+	res := pipelines.process(limits.IngestionPipelines, []logproto.Stream{
+		{
+			Labels: `{app="nginx"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(0, 1), Line: "status=200"},
+				{Timestamp: time.Unix(0, 2), Line: "status=500"},
+				{Timestamp: time.Unix(0, 3), Line: "status=200"},
+			},
+		},
+	})
+
+	require.Len(t, res.streams, 2)
+	require.Equal(t, `{app="nginx-200"}`, res.streams[0].Labels)
+	require.Len(t, res.streams[0].Entries, 2)
+	require.Equal(t, `{app="nginx-500"}`, res.streams[1].Labels)
+	require.Len(t, res.streams[1].Entries, 1)
+	require.Empty(t, res.dropped)
+}
@@ -1,7 +1,12 @@
 package distributor
 
 import (
+	"strings"
+
 	"github.com/grafana/dskit/limiter"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 // ReadLifecycler represents the read interface to the lifecycler.
@@ -54,3 +59,90 @@ func (s *globalStrategy) Burst(userID string) int {
 	// to keep it easier to understand for users / operators.
 	return s.limits.IngestionBurstSizeBytes(userID)
 }
+
+// The rate limiters of the ingestion rate policies are keyed by tenant and
+// policy name. The tenant IDs can't contain a slash, unlike the policy names.
+const ingestionRatePolicyKeySeparator = "/"
+
+func ingestionRatePolicyKey(userID, policy string) string {
+	return userID + ingestionRatePolicyKeySeparator + policy
+}
+
+// ingestionRatePolicy returns the tenant and the ingestion rate policy of the
+// given rate limiter key.
+func ingestionRatePolicy(limits Limits, key string) (string, validation.IngestionRatePolicy, bool) {
+	userID, name, _ := strings.Cut(key, ingestionRatePolicyKeySeparator)
+	for _, p := range limits.IngestionRatePolicies(userID) {
+		if p.Name == name {
+			return userID, p, true
+		}
+	}
+	return userID, validation.IngestionRatePolicy{}, false
+}
+
+type localPolicyStrategy struct {
+	limits Limits
+}
+
+// newLocalIngestionRatePolicyStrategy returns the local strategy of the rate
+// limiters of the ingestion rate policies, keyed by ingestionRatePolicyKey.
+func newLocalIngestionRatePolicyStrategy(limits Limits) limiter.RateLimiterStrategy {
+	return &localPolicyStrategy{
+		limits: limits,
+	}
+}
+
+func (s *localPolicyStrategy) Limit(key string) float64 {
+	_, policy, ok := ingestionRatePolicy(s.limits, key)
+	if !ok {
+		// the policy has been removed since the rate limiter was created.
+		return float64(rate.Inf)
+	}
+	return policy.RateBytes()
+}
+
+func (s *localPolicyStrategy) Burst(key string) int {
+	return ingestionRatePolicyBurst(s.limits, key)
+}
+
+type globalPolicyStrategy struct {
+	limits Limits
+	ring   ReadLifecycler
+}
+
+// newGlobalIngestionRatePolicyStrategy returns the global strategy of the rate
+// limiters of the ingestion rate policies, keyed by ingestionRatePolicyKey.
+func newGlobalIngestionRatePolicyStrategy(limits Limits, ring ReadLifecycler) limiter.RateLimiterStrategy {
+	return &globalPolicyStrategy{
+		limits: limits,
+		ring:   ring,
+	}
+}
+
+func (s *globalPolicyStrategy) Limit(key string) float64 {
+	_, policy, ok := ingestionRatePolicy(s.limits, key)
+	if !ok {
+		// the policy has been removed since the rate limiter was created.
+		return float64(rate.Inf)
+	}
+
+	numDistributors := s.ring.HealthyInstancesCount()
+	if numDistributors == 0 {
+		return policy.RateBytes()
+	}
+	return policy.RateBytes() / float64(numDistributors)
+}
+
+func (s *globalPolicyStrategy) Burst(key string) int {
+	// As for the tenant rate limit, the meaning of burst doesn't change for
+	// the global strategy.
+	return ingestionRatePolicyBurst(s.limits, key)
+}
+
+func ingestionRatePolicyBurst(limits Limits, key string) int {
+	userID, policy, _ := ingestionRatePolicy(limits, key)
+	if burst := policy.BurstSizeBytes(); burst > 0 {
+		return burst
+	}
+	return limits.IngestionBurstSizeBytes(userID)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
 
 	"github.com/grafana/loki/v3/pkg/validation"
 )
@@ -86,6 +87,41 @@ func TestIngestionRateStrategy(t *testing.T) {
 	}
 }
 
+func TestIngestionRatePolicyStrategy(t *testing.T) {
+	limits := validation.Limits{
+		IngestionBurstSizeMB: 6.0,
+		IngestionRatePolicies: []validation.IngestionRatePolicy{
+			{Name: "batch", Selector: `{namespace="batch"}`, RateMB: 1.0, BurstSizeMB: 2.0},
+			{Name: "cron/daily", Selector: `{namespace="cron"}`, RateMB: 3.0},
+		},
+	}
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	ring := newReadLifecyclerMock()
+	ring.On("HealthyInstancesCount").Return(2)
+
+	local := newLocalIngestionRatePolicyStrategy(overrides)
+	global := newGlobalIngestionRatePolicyStrategy(overrides, ring)
+
+	key := ingestionRatePolicyKey("test", "batch")
+	assert.Equal(t, 1.0*float64(bytesInMB), local.Limit(key))
+	assert.Equal(t, 0.5*float64(bytesInMB), global.Limit(key))
+	assert.Equal(t, int(2.0*float64(bytesInMB)), local.Burst(key))
+	assert.Equal(t, int(2.0*float64(bytesInMB)), global.Burst(key))
+
+	// the burst size defaults to the tenant one.
+	key = ingestionRatePolicyKey("test", "cron/daily")
+	assert.Equal(t, 3.0*float64(bytesInMB), local.Limit(key))
+	assert.Equal(t, 1.5*float64(bytesInMB), global.Limit(key))
+	assert.Equal(t, int(6.0*float64(bytesInMB)), local.Burst(key))
+
+	// the removed policies are not limited anymore.
+	key = ingestionRatePolicyKey("test", "removed")
+	assert.Equal(t, float64(rate.Inf), local.Limit(key))
+	assert.Equal(t, float64(rate.Inf), global.Limit(key))
+}
+
 type readLifecyclerMock struct {
 	mock.Mock
 }
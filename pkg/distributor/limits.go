@@ -4,8 +4,14 @@ import (
 	"time"
 
 	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/distributor/adaptivesampling"
+	"github.com/grafana/loki/v3/pkg/distributor/cardinalityguard"
+	"github.com/grafana/loki/v3/pkg/distributor/deadletter"
+	"github.com/grafana/loki/v3/pkg/distributor/idempotency"
+	"github.com/grafana/loki/v3/pkg/distributor/redaction"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
+	"github.com/grafana/loki/v3/pkg/validation"
 )
 
 // Limits is an interface for distributor limits/related configs
@@ -24,18 +30,29 @@ type Limits interface {
 	IncrementDuplicateTimestamps(userID string) bool
 	DiscoverServiceName(userID string) []string
 	DiscoverLogLevels(userID string) bool
+	ExtractFields(userID string) []validation.ExtractedField
 
 	ShardStreams(userID string) shardstreams.Config
 	IngestionRateStrategy() string
 	IngestionRateBytes(userID string) float64
 	IngestionBurstSizeBytes(userID string) int
+	IngestionRatePolicies(userID string) []validation.IngestionRatePolicy
 	AllowStructuredMetadata(userID string) bool
 	MaxStructuredMetadataSize(userID string) int
 	MaxStructuredMetadataCount(userID string) int
 	OTLPConfig(userID string) push.OTLPConfig
+	ElasticsearchConfig(userID string) push.ElasticsearchConfig
+	SplunkHECConfig(userID string) push.SplunkHECConfig
 
 	BlockIngestionUntil(userID string) time.Time
 	BlockIngestionStatusCode(userID string) int
 
+	IngestionPipelines(userID string) []validation.IngestionPipeline
+	Redaction(userID string) redaction.Config
+	AdaptiveSampling(userID string) adaptivesampling.Config
+	PushIdempotency(userID string) idempotency.Config
+	CardinalityGuard(userID string) cardinalityguard.Config
+	DeadLetter(userID string) deadletter.Config
+
 	IngestionPartitionsTenantShardSize(userID string) int
 }
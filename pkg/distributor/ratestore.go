@@ -96,7 +96,7 @@ func NewRateStore(cfg RateStoreConfig, r ring.ReadRing, cf poolClientFactory, l
 }
 
 func (s *rateStore) instrumentedUpdateAllRates(ctx context.Context) error {
-	if !s.anyShardingEnabled() {
+	if !s.anyShardingEnabled() && !s.anyAdaptiveSamplingEnabled() {
 		return nil
 	}
 
@@ -228,6 +228,22 @@ func (s *rateStore) anyShardingEnabled() bool {
 	return false
 }
 
+func (s *rateStore) anyAdaptiveSamplingEnabled() bool {
+	limits := s.limits.AllByUserID()
+	if limits == nil {
+		// There aren't any tenant limits, check the default
+		return s.limits.AdaptiveSampling("fake").Enabled
+	}
+
+	for user := range limits {
+		if s.limits.AdaptiveSampling(user).Enabled {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *rateStore) aggregateByShard(ctx context.Context, streamRates map[string]map[uint64]*logproto.StreamRate) map[string]map[uint64]expiringRate {
 	if s.debug {
 		if sp := opentracing.SpanFromContext(ctx); sp != nil {
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/loki/v3/pkg/distributor/adaptivesampling"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/validation"
 
@@ -354,6 +355,10 @@ func (c *fakeOverrides) ShardStreams(_ string) shardstreams.Config {
 	}
 }
 
+func (c *fakeOverrides) AdaptiveSampling(_ string) adaptivesampling.Config {
+	return adaptivesampling.Config{}
+}
+
 type testContext struct {
 	ring       *fakeRing
 	clientPool *fakeClientPool
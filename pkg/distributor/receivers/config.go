@@ -0,0 +1,130 @@
+package receivers
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+
+	FormatRFC5424 = "rfc5424"
+	FormatRFC3164 = "rfc3164"
+)
+
+// Config configures the syslog and GELF listeners of the distributors.
+type Config struct {
+	Syslog []SyslogConfig `yaml:"syslog,omitempty" doc:"description=Syslog listeners. The log lines are the messages of the syslog messages.\nExample:\n syslog:\n - listen_address: 0.0.0.0:1514\n listen_protocol: tcp\n format: rfc5424\n tenant: network\n labels:\n job: syslog\n label_mapping:\n hostname: host\n app_name: app\nThe fields of the syslog messages which can be mapped to labels are 'hostname', 'app_name', 'proc_id', 'msg_id', 'facility', 'severity' and the 'sd.<id>.<param>' structured data parameters."`
+	GELF   []GELFConfig   `yaml:"gelf,omitempty" doc:"description=GELF listeners. The log lines are the JSON encoded GELF messages.\nExample:\n gelf:\n - listen_address: 0.0.0.0:12201\n listen_protocol: udp\n tenant: legacy\n label_mapping:\n host: host\n _container_name: container\nThe fields of the GELF messages which can be mapped to labels are 'host', 'level', 'facility', 'version' and the additional fields starting with an underscore."`
+
+	BatchWait time.Duration `yaml:"batch_wait"`
+	BatchSize int           `yaml:"batch_size"`
+}
+
+// ListenerConfig are the settings common to all listeners.
+type ListenerConfig struct {
+	ListenAddress  string `yaml:"listen_address" doc:"description=Address the listener listens on."`
+	ListenProtocol string `yaml:"listen_protocol" doc:"description=Protocol of the listener, 'tcp' or 'udp'."`
+
+	Tenant      string `yaml:"tenant" doc:"description=Tenant the log lines received by the listener are pushed to."`
+	TenantLabel string `yaml:"tenant_label" doc:"description=Label whose value is the tenant the log lines are pushed to, instead of the configured tenant. The label is removed from the stream labels."`
+	// AllowedTenants keeps the content of the messages from selecting any
+	// tenant with the tenant label.
+	AllowedTenants []string `yaml:"allowed_tenants" doc:"description=Tenants the tenant label can select. The log lines whose tenant label is not one of them are pushed to the configured tenant, or dropped if it isn't set. Required with the tenant label."`
+
+	Labels       map[string]string `yaml:"labels" doc:"description=Labels added to the streams of the received log lines."`
+	LabelMapping map[string]string `yaml:"label_mapping" doc:"description=Mapping of the fields of the received messages to the stream labels. Defaults to the host of the messages mapped to the 'host' label."`
+
+	UseIncomingTimestamp bool          `yaml:"use_incoming_timestamp" doc:"description=Use the timestamp of the received messages instead of the time they are received."`
+	MaxMessageLength     int           `yaml:"max_message_length" doc:"description=Maximum length of the received messages."`
+	IdleTimeout          time.Duration `yaml:"idle_timeout" doc:"description=Timeout of the idle TCP connections."`
+}
+
+// SyslogConfig configures a syslog listener.
+type SyslogConfig struct {
+	ListenerConfig `yaml:",inline"`
+
+	Format string `yaml:"format" doc:"description=Format of the syslog messages, 'rfc5424' or 'rfc3164'."`
+}
+
+// GELFConfig configures a GELF listener.
+type GELFConfig struct {
+	ListenerConfig `yaml:",inline"`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.DurationVar(&cfg.BatchWait, prefix+".batch-wait", time.Second, "Maximum amount of time the log lines received by the syslog and GELF listeners are batched before they are pushed.")
+	fs.IntVar(&cfg.BatchSize, prefix+".batch-size", 1000, "Maximum number of log lines received by the syslog and GELF listeners batched before they are pushed. The listeners stop receiving messages while a full batch is pushed.")
+}
+
+// Enabled returns whether any listener is configured.
+func (cfg *Config) Enabled() bool {
+	return len(cfg.Syslog) > 0 || len(cfg.GELF) > 0
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if cfg.BatchWait <= 0 {
+		return errors.New("the batch wait of the receivers must be greater than 0")
+	}
+	if cfg.BatchSize <= 0 {
+		return errors.New("the batch size of the receivers must be greater than 0")
+	}
+
+	for i := range cfg.Syslog {
+		l := &cfg.Syslog[i]
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("invalid syslog listener %s: %w", l.ListenAddress, err)
+		}
+		if l.Format == "" {
+			l.Format = FormatRFC5424
+		}
+		if l.Format != FormatRFC5424 && l.Format != FormatRFC3164 {
+			return fmt.Errorf("invalid syslog listener %s: unsupported format %q", l.ListenAddress, l.Format)
+		}
+		if l.LabelMapping == nil {
+			l.LabelMapping = map[string]string{"hostname": "host"}
+		}
+	}
+
+	for i := range cfg.GELF {
+		l := &cfg.GELF[i]
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("invalid GELF listener %s: %w", l.ListenAddress, err)
+		}
+		if l.LabelMapping == nil {
+			l.LabelMapping = map[string]string{"host": "host"}
+		}
+	}
+	return nil
+}
+
+func (cfg *ListenerConfig) validate() error {
+	if cfg.ListenAddress == "" {
+		return errors.New("the listen address must not be empty")
+	}
+	if cfg.ListenProtocol == "" {
+		cfg.ListenProtocol = ProtocolTCP
+	}
+	if cfg.ListenProtocol != ProtocolTCP && cfg.ListenProtocol != ProtocolUDP {
+		return fmt.Errorf("unsupported protocol %q", cfg.ListenProtocol)
+	}
+	if cfg.Tenant == "" && cfg.TenantLabel == "" {
+		return errors.New("either the tenant or the tenant label must be set")
+	}
+	if cfg.TenantLabel != "" && len(cfg.AllowedTenants) == 0 {
+		return errors.New("the allowed tenants must be set with the tenant label")
+	}
+	if cfg.MaxMessageLength <= 0 {
+		cfg.MaxMessageLength = defaultMaxMessageLength
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = defaultIdleTimeout
+	}
+	return nil
+}
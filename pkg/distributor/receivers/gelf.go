@@ -0,0 +1,197 @@
+package receivers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/grafana/go-gelf/v2/gelf"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const receiverGELF = "gelf"
+
+// gelfListener receives GELF messages over UDP, chunked and compressed or not,
+// or over TCP, delimited by null bytes.
+type gelfListener struct {
+	cfg GELFConfig
+	r   *Receivers
+
+	tcp net.Listener
+	udp *gelf.Reader
+
+	mtx   sync.Mutex
+	conns map[net.Conn]struct{}
+	// closed is set when the UDP reader is closed, as its errors don't wrap
+	// net.ErrClosed.
+	closed bool
+}
+
+func newGELFListener(cfg GELFConfig, r *Receivers) (*gelfListener, error) {
+	l := &gelfListener{cfg: cfg, r: r, conns: make(map[net.Conn]struct{})}
+
+	var err error
+	if cfg.ListenProtocol == ProtocolUDP {
+		l.udp, err = gelf.NewReader(cfg.ListenAddress)
+	} else {
+		l.tcp, err = net.Listen("tcp", cfg.ListenAddress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for GELF messages on %s: %w", cfg.ListenAddress, err)
+	}
+	level.Info(r.logger).Log("msg", "listening for GELF messages", "addr", l.addr(), "protocol", cfg.ListenProtocol)
+	return l, nil
+}
+
+func (l *gelfListener) addr() net.Addr {
+	if l.udp != nil {
+		addr, _ := net.ResolveUDPAddr("udp", l.udp.Addr())
+		return addr
+	}
+	return l.tcp.Addr()
+}
+
+func (l *gelfListener) close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.udp != nil {
+		l.closed = true
+		return l.udp.Close()
+	}
+
+	err := l.tcp.Close()
+	for conn := range l.conns {
+		_ = conn.Close()
+	}
+	return err
+}
+
+func (l *gelfListener) isClosed() bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.closed
+}
+
+func (l *gelfListener) run() {
+	if l.udp != nil {
+		for {
+			msg, err := l.udp.ReadMessage()
+			if l.isClosed() {
+				return
+			}
+			if err != nil {
+				l.r.metrics.errors.WithLabelValues(receiverGELF, ProtocolUDP).Inc()
+				continue
+			}
+			l.handleMessage(msg)
+		}
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.tcp.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			level.Warn(l.r.logger).Log("msg", "failed to accept GELF connection", "addr", l.addr(), "err", err)
+			continue
+		}
+
+		l.mtx.Lock()
+		l.conns[conn] = struct{}{}
+		l.mtx.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.handleConn(conn)
+
+			l.mtx.Lock()
+			delete(l.conns, conn)
+			l.mtx.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+// handleConn reads the null byte delimited GELF messages of a TCP connection.
+func (l *gelfListener) handleConn(conn net.Conn) {
+	scanner := bufio.NewScanner(&idleTimeoutReader{conn: conn, timeout: l.cfg.IdleTimeout})
+	scanner.Buffer(make([]byte, 0, 4096), l.cfg.MaxMessageLength)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var msg gelf.Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			l.r.metrics.messages.WithLabelValues(receiverGELF, ProtocolTCP).Inc()
+			l.r.metrics.errors.WithLabelValues(receiverGELF, ProtocolTCP).Inc()
+			continue
+		}
+		l.handleMessage(&msg)
+	}
+	if err := scanner.Err(); err != nil {
+		l.r.metrics.errors.WithLabelValues(receiverGELF, ProtocolTCP).Inc()
+		level.Debug(l.r.logger).Log("msg", "failed to read GELF messages", "remote_addr", conn.RemoteAddr(), "err", err)
+	}
+}
+
+func (l *gelfListener) handleMessage(msg *gelf.Message) {
+	l.r.metrics.messages.WithLabelValues(receiverGELF, l.cfg.ListenProtocol).Inc()
+
+	fields := map[string]string{
+		"host":     msg.Host,
+		"level":    strconv.Itoa(int(msg.Level)),
+		"facility": msg.Facility,
+		"version":  msg.Version,
+	}
+	for name, value := range msg.Extra {
+		switch v := value.(type) {
+		case string:
+			fields[name] = v
+		case float64:
+			fields[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			fields[name] = strconv.FormatBool(v)
+		}
+	}
+
+	var line bytes.Buffer
+	if err := msg.MarshalJSONBuf(&line); err != nil {
+		l.r.metrics.errors.WithLabelValues(receiverGELF, l.cfg.ListenProtocol).Inc()
+		return
+	}
+
+	var incoming time.Time
+	if msg.TimeUnix != 0 {
+		// TimeUnix is in seconds since the UNIX epoch with decimals for the
+		// fractional seconds.
+		incoming = time.Unix(0, int64(msg.TimeUnix*float64(time.Second)))
+	}
+
+	l.r.handle(receiverGELF, l.cfg.ListenerConfig, fields, logproto.Entry{
+		Timestamp: timestamp(l.cfg.ListenerConfig, incoming),
+		Line:      line.String(),
+	})
+}
@@ -0,0 +1,266 @@
+// Package receivers implements the syslog and GELF listeners of the
+// distributors, which push the received log lines like the push API.
+package receivers
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	defaultMaxMessageLength = 8192
+	defaultIdleTimeout      = 2 * time.Minute
+)
+
+// Pusher pushes the received log lines, validating and rate limiting them.
+type Pusher interface {
+	Push(ctx context.Context, req *logproto.PushRequest) (*logproto.PushResponse, error)
+}
+
+// listener receives the messages of a protocol on an address.
+type listener interface {
+	// run receives the messages until the listener is closed.
+	run()
+	close() error
+	addr() net.Addr
+}
+
+type metrics struct {
+	messages     *prometheus.CounterVec
+	errors       *prometheus.CounterVec
+	pushFailures *prometheus.CounterVec
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	return &metrics{
+		messages: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_receiver_messages_total",
+			Help:      "The total number of messages received by the syslog and GELF listeners.",
+		}, []string{"receiver", "protocol"}),
+		errors: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_receiver_errors_total",
+			Help:      "The total number of messages which couldn't be read or parsed by the syslog and GELF listeners.",
+		}, []string{"receiver", "protocol"}),
+		pushFailures: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "distributor_receiver_push_failures_total",
+			Help:      "The total number of log lines received by the syslog and GELF listeners which failed to be pushed.",
+		}, []string{"receiver", "protocol"}),
+	}
+}
+
+// Receivers runs the syslog and GELF listeners and pushes the log lines they
+// receive in batches.
+type Receivers struct {
+	services.Service
+
+	cfg     Config
+	pusher  Pusher
+	logger  log.Logger
+	metrics *metrics
+
+	listeners []listener
+	wg        sync.WaitGroup
+
+	mtx     sync.Mutex
+	batches map[string]*batch // by tenant
+	entries int               // in all the batches
+}
+
+// batch are the log lines of a tenant waiting to be pushed.
+type batch struct {
+	streams map[string]*logproto.Stream
+	entries int
+	// sources are the number of log lines received by each listener kind.
+	sources map[source]int
+}
+
+type source struct {
+	receiver, protocol string
+}
+
+// New returns the receivers of the config. The config must have been
+// validated.
+func New(cfg Config, pusher Pusher, logger log.Logger, registerer prometheus.Registerer) *Receivers {
+	r := &Receivers{
+		cfg:     cfg,
+		pusher:  pusher,
+		logger:  log.With(logger, "component", "receivers"),
+		metrics: newMetrics(registerer),
+		batches: make(map[string]*batch),
+	}
+	r.Service = services.NewBasicService(r.starting, r.running, r.stopping)
+	return r
+}
+
+func (r *Receivers) starting(_ context.Context) error {
+	for _, cfg := range r.cfg.Syslog {
+		l, err := newSyslogListener(cfg, r)
+		if err != nil {
+			r.closeListeners()
+			return err
+		}
+		r.listeners = append(r.listeners, l)
+	}
+	for _, cfg := range r.cfg.GELF {
+		l, err := newGELFListener(cfg, r)
+		if err != nil {
+			r.closeListeners()
+			return err
+		}
+		r.listeners = append(r.listeners, l)
+	}
+
+	for _, l := range r.listeners {
+		r.wg.Add(1)
+		go func(l listener) {
+			defer r.wg.Done()
+			l.run()
+		}(l)
+	}
+	return nil
+}
+
+func (r *Receivers) running(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.BatchWait)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.pushBatches()
+		}
+	}
+}
+
+func (r *Receivers) stopping(_ error) error {
+	r.closeListeners()
+	r.wg.Wait()
+	// push the log lines received before the listeners were closed.
+	r.pushBatches()
+	return nil
+}
+
+func (r *Receivers) closeListeners() {
+	for _, l := range r.listeners {
+		if err := l.close(); err != nil {
+			level.Warn(r.logger).Log("msg", "failed to close listener", "addr", l.addr(), "err", err)
+		}
+	}
+}
+
+// handle adds the log line of a message received by a listener to the batch
+// of its tenant. fields are the fields of the message which can be mapped to
+// labels.
+func (r *Receivers) handle(receiver string, cfg ListenerConfig, fields map[string]string, entry logproto.Entry) {
+	lb := labels.NewBuilder(nil)
+	for name, value := range cfg.Labels {
+		lb.Set(name, value)
+	}
+	for field, name := range cfg.LabelMapping {
+		if value, ok := fields[field]; ok && value != "" {
+			lb.Set(name, value)
+		}
+	}
+
+	src := source{receiver: receiver, protocol: cfg.ListenProtocol}
+	tenantID := cfg.Tenant
+	if cfg.TenantLabel != "" {
+		if value := lb.Get(cfg.TenantLabel); value != "" {
+			lb.Del(cfg.TenantLabel)
+			if slices.Contains(cfg.AllowedTenants, value) {
+				tenantID = value
+			} else {
+				level.Debug(r.logger).Log("msg", "tenant label is not an allowed tenant", "tenant", value)
+			}
+		}
+	}
+	if tenantID == "" {
+		level.Debug(r.logger).Log("msg", "dropping log line without a tenant")
+		r.metrics.pushFailures.WithLabelValues(src.receiver, src.protocol).Inc()
+		return
+	}
+	if err := tenant.ValidTenantID(tenantID); err != nil {
+		level.Debug(r.logger).Log("msg", "dropping log line with an invalid tenant", "tenant", tenantID, "err", err)
+		r.metrics.pushFailures.WithLabelValues(src.receiver, src.protocol).Inc()
+		return
+	}
+
+	lbs := lb.Labels().String()
+
+	r.mtx.Lock()
+	b, ok := r.batches[tenantID]
+	if !ok {
+		b = &batch{streams: make(map[string]*logproto.Stream), sources: make(map[source]int)}
+		r.batches[tenantID] = b
+	}
+	stream, ok := b.streams[lbs]
+	if !ok {
+		stream = &logproto.Stream{Labels: lbs}
+		b.streams[lbs] = stream
+	}
+	stream.Entries = append(stream.Entries, entry)
+	b.entries++
+	b.sources[src]++
+	r.entries++
+	full := r.entries >= r.cfg.BatchSize
+	r.mtx.Unlock()
+
+	// The full batches are pushed by the listener, which stops receiving
+	// messages in the meantime, so that the log lines waiting to be pushed
+	// are bounded even if the pushes are slower than the senders.
+	if full {
+		r.pushBatches()
+	}
+}
+
+// pushBatches pushes the log lines batched since the last push.
+func (r *Receivers) pushBatches() {
+	r.mtx.Lock()
+	batches := r.batches
+	r.batches = make(map[string]*batch, len(batches))
+	r.entries = 0
+	r.mtx.Unlock()
+
+	for tenantID, b := range batches {
+		req := &logproto.PushRequest{Streams: make([]logproto.Stream, 0, len(b.streams))}
+		for _, stream := range b.streams {
+			req.Streams = append(req.Streams, *stream)
+		}
+
+		ctx := user.InjectOrgID(context.Background(), tenantID)
+		if _, err := r.pusher.Push(ctx, req); err != nil {
+			level.Warn(r.logger).Log("msg", "failed to push received log lines", "tenant", tenantID, "entries", b.entries, "err", err)
+			for src, n := range b.sources {
+				r.metrics.pushFailures.WithLabelValues(src.receiver, src.protocol).Add(float64(n))
+			}
+		}
+	}
+}
+
+// timestamp returns the timestamp of a received log line.
+func timestamp(cfg ListenerConfig, incoming time.Time) time.Time {
+	if !cfg.UseIncomingTimestamp || incoming.IsZero() {
+		return time.Now()
+	}
+	return incoming
+}
@@ -0,0 +1,255 @@
+package receivers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/tenant"
+	"github.com/grafana/go-gelf/v2/gelf"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+type pushed struct {
+	tenant string
+	labels string
+	line   string
+	ts     time.Time
+}
+
+type fakePusher struct {
+	mtx    sync.Mutex
+	pushed []pushed
+}
+
+func (p *fakePusher) Push(ctx context.Context, req *logproto.PushRequest) (*logproto.PushResponse, error) {
+	tenantID, err := tenant.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for _, stream := range req.Streams {
+		for _, e := range stream.Entries {
+			p.pushed = append(p.pushed, pushed{tenant: tenantID, labels: stream.Labels, line: e.Line, ts: e.Timestamp})
+		}
+	}
+	return &logproto.PushResponse{}, nil
+}
+
+func (p *fakePusher) wait(t *testing.T, n int) []pushed {
+	t.Helper()
+
+	var res []pushed
+	require.Eventually(t, func() bool {
+		p.mtx.Lock()
+		defer p.mtx.Unlock()
+		res = append([]pushed(nil), p.pushed...)
+		return len(res) >= n
+	}, 5*time.Second, 10*time.Millisecond)
+
+	sort.Slice(res, func(i, j int) bool { return res[i].line < res[j].line })
+	return res
+}
+
+func startReceivers(t *testing.T, cfg Config) (*Receivers, *fakePusher) {
+	t.Helper()
+
+	cfg.BatchWait = 10 * time.Millisecond
+	cfg.BatchSize = 100
+	require.NoError(t, cfg.Validate())
+
+	pusher := &fakePusher{}
+	r := New(cfg, pusher, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), r))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), r))
+	})
+	return r, pusher
+}
+
+func TestSyslog(t *testing.T) {
+	listener := func(protocol, format string) SyslogConfig {
+		return SyslogConfig{
+			ListenerConfig: ListenerConfig{
+				ListenAddress:        "127.0.0.1:0",
+				ListenProtocol:       protocol,
+				Tenant:               "network",
+				TenantLabel:          "tenant",
+				AllowedTenants:       []string{"devices"},
+				Labels:               map[string]string{"job": "syslog"},
+				LabelMapping:         map[string]string{"hostname": "host", "app_name": "app", "sd.origin.tenant": "tenant"},
+				UseIncomingTimestamp: true,
+			},
+			Format: format,
+		}
+	}
+
+	r, pusher := startReceivers(t, Config{Syslog: []SyslogConfig{
+		listener(ProtocolTCP, FormatRFC5424),
+		listener(ProtocolUDP, FormatRFC5424),
+		listener(ProtocolTCP, FormatRFC3164),
+	}})
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// octet counting framing
+	conn, err := net.Dial("tcp", r.listeners[0].addr().String())
+	require.NoError(t, err)
+	msg := `<165>1 2024-01-02T03:04:05Z router1 sshd 42 ID47 - login failed`
+	_, err = fmt.Fprintf(conn, "%d %s", len(msg), msg)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	conn, err = net.Dial("udp", r.listeners[1].addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte(`<165>1 2024-01-02T03:04:05Z router2 - - - [origin tenant="devices"] link down`))
+	require.NoError(t, err)
+	// the tenants which aren't allowed fall back to the configured tenant.
+	_, err = conn.Write([]byte(`<165>1 2024-01-02T03:04:05Z router3 - - - [origin tenant="other"] link up`))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	// non-transparent framing
+	conn, err = net.Dial("tcp", r.listeners[2].addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("<34>Jan  2 03:04:05 legacy1 su: root login\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	got := pusher.wait(t, 4)
+	require.Len(t, got, 4)
+	require.Equal(t, pushed{tenant: "devices", labels: `{host="router2", job="syslog"}`, line: "link down", ts: ts}, got[0])
+	require.Equal(t, pushed{tenant: "network", labels: `{host="router3", job="syslog"}`, line: "link up", ts: ts}, got[1])
+	require.Equal(t, pushed{tenant: "network", labels: `{app="sshd", host="router1", job="syslog"}`, line: "login failed", ts: ts}, got[2])
+	require.Equal(t, "network", got[3].tenant)
+	require.Equal(t, `{app="su", host="legacy1", job="syslog"}`, got[3].labels)
+	require.Equal(t, "root login", got[3].line)
+	require.Equal(t, time.Now().Year(), got[3].ts.Year())
+}
+
+type failingPusher struct {
+	fakePusher
+}
+
+func (p *failingPusher) Push(ctx context.Context, req *logproto.PushRequest) (*logproto.PushResponse, error) {
+	_, _ = p.fakePusher.Push(ctx, req)
+	return nil, errors.New("push failed")
+}
+
+func TestHandle(t *testing.T) {
+	cfg := Config{
+		BatchWait: time.Hour,
+		BatchSize: 2,
+		Syslog: []SyslogConfig{{ListenerConfig: ListenerConfig{
+			ListenAddress:  "127.0.0.1:0",
+			ListenProtocol: ProtocolUDP,
+			TenantLabel:    "tenant",
+			AllowedTenants: []string{"a"},
+			LabelMapping:   map[string]string{"hostname": "host", "tenant": "tenant"},
+		}}},
+	}
+	require.NoError(t, cfg.Validate())
+	pusher := &failingPusher{}
+	r := New(cfg, pusher, log.NewNopLogger(), prometheus.NewRegistry())
+	listener := cfg.Syslog[0].ListenerConfig
+
+	// the log lines of the tenants which aren't allowed are dropped without a
+	// configured tenant.
+	r.handle(receiverSyslog, listener, map[string]string{"hostname": "h1", "tenant": "b"}, logproto.Entry{Line: "dropped"})
+	require.Equal(t, float64(1), testutil.ToFloat64(r.metrics.pushFailures.WithLabelValues(receiverSyslog, ProtocolUDP)))
+
+	// the batches are pushed by the listener once they are full.
+	r.handle(receiverSyslog, listener, map[string]string{"hostname": "h1", "tenant": "a"}, logproto.Entry{Line: "first"})
+	require.Empty(t, pusher.pushed)
+	r.handle(receiverSyslog, listener, map[string]string{"hostname": "h2", "tenant": "a"}, logproto.Entry{Line: "second"})
+	require.Len(t, pusher.pushed, 2)
+	require.Equal(t, 0, r.entries)
+	require.Equal(t, float64(3), testutil.ToFloat64(r.metrics.pushFailures.WithLabelValues(receiverSyslog, ProtocolUDP)))
+}
+
+func TestGELF(t *testing.T) {
+	listener := func(protocol string) GELFConfig {
+		return GELFConfig{ListenerConfig: ListenerConfig{
+			ListenAddress:        "127.0.0.1:0",
+			ListenProtocol:       protocol,
+			Tenant:               "legacy",
+			LabelMapping:         map[string]string{"host": "host", "_container": "container"},
+			UseIncomingTimestamp: true,
+		}}
+	}
+
+	r, pusher := startReceivers(t, Config{GELF: []GELFConfig{
+		listener(ProtocolUDP),
+		listener(ProtocolTCP),
+	}})
+
+	writer, err := gelf.NewUDPWriter(r.listeners[0].addr().String())
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteMessage(&gelf.Message{
+		Version:  "1.1",
+		Host:     "web1",
+		Short:    "request served",
+		TimeUnix: 1704164645,
+		Level:    6,
+		Extra:    map[string]interface{}{"_container": "nginx"},
+	}))
+	require.NoError(t, writer.Close())
+
+	conn, err := net.Dial("tcp", r.listeners[1].addr().String())
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, (&gelf.Message{Version: "1.1", Host: "web2", Short: "upstream timeout", TimeUnix: 1704164645.5}).MarshalJSONBuf(&buf))
+	buf.WriteByte(0)
+	buf.WriteString(`{"version":"1.1","host":"web2","short_message":"not json"`)
+	buf.WriteByte(0)
+	_, err = conn.Write(buf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	got := pusher.wait(t, 2)
+	require.Len(t, got, 2)
+	require.Equal(t, "legacy", got[0].tenant)
+	require.Equal(t, `{container="nginx", host="web1"}`, got[0].labels)
+	require.JSONEq(t, `{"version":"1.1","host":"web1","short_message":"request served","timestamp":1704164645,"level":6,"_container":"nginx"}`, got[0].line)
+	require.Equal(t, time.Unix(1704164645, 0), got[0].ts)
+	require.Equal(t, `{host="web2"}`, got[1].labels)
+	require.Equal(t, time.Unix(1704164645, int64(500*time.Millisecond)), got[1].ts)
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "no listeners"},
+		{name: "valid", cfg: Config{BatchWait: time.Second, BatchSize: 10, Syslog: []SyslogConfig{{ListenerConfig: ListenerConfig{ListenAddress: ":1514", Tenant: "a"}}}}},
+		{name: "no tenant", cfg: Config{BatchWait: time.Second, BatchSize: 10, GELF: []GELFConfig{{ListenerConfig: ListenerConfig{ListenAddress: ":12201"}}}}, err: "either the tenant or the tenant label must be set"},
+		{name: "no allowed tenants", cfg: Config{BatchWait: time.Second, BatchSize: 10, GELF: []GELFConfig{{ListenerConfig: ListenerConfig{ListenAddress: ":12201", TenantLabel: "tenant"}}}}, err: "the allowed tenants must be set with the tenant label"},
+		{name: "invalid protocol", cfg: Config{BatchWait: time.Second, BatchSize: 10, GELF: []GELFConfig{{ListenerConfig: ListenerConfig{ListenAddress: ":12201", ListenProtocol: "http", Tenant: "a"}}}}, err: `unsupported protocol "http"`},
+		{name: "invalid format", cfg: Config{BatchWait: time.Second, BatchSize: 10, Syslog: []SyslogConfig{{ListenerConfig: ListenerConfig{ListenAddress: ":1514", Tenant: "a"}, Format: "cef"}}}, err: `unsupported format "cef"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package receivers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/leodido/go-syslog/v4"
+	"github.com/leodido/go-syslog/v4/nontransparent"
+	"github.com/leodido/go-syslog/v4/octetcounting"
+	"github.com/leodido/go-syslog/v4/rfc3164"
+	"github.com/leodido/go-syslog/v4/rfc5424"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const receiverSyslog = "syslog"
+
+// syslogListener receives syslog messages over TCP, framed with octet counting
+// or newlines, or over UDP, one message per datagram.
+type syslogListener struct {
+	cfg SyslogConfig
+	r   *Receivers
+
+	tcp net.Listener
+	udp net.PacketConn
+
+	mtx   sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func newSyslogListener(cfg SyslogConfig, r *Receivers) (*syslogListener, error) {
+	l := &syslogListener{cfg: cfg, r: r, conns: make(map[net.Conn]struct{})}
+
+	var err error
+	if cfg.ListenProtocol == ProtocolUDP {
+		l.udp, err = net.ListenPacket("udp", cfg.ListenAddress)
+	} else {
+		l.tcp, err = net.Listen("tcp", cfg.ListenAddress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for syslog messages on %s: %w", cfg.ListenAddress, err)
+	}
+	level.Info(r.logger).Log("msg", "listening for syslog messages", "addr", l.addr(), "protocol", cfg.ListenProtocol, "format", cfg.Format)
+	return l, nil
+}
+
+func (l *syslogListener) addr() net.Addr {
+	if l.udp != nil {
+		return l.udp.LocalAddr()
+	}
+	return l.tcp.Addr()
+}
+
+func (l *syslogListener) close() error {
+	if l.udp != nil {
+		return l.udp.Close()
+	}
+
+	err := l.tcp.Close()
+	l.mtx.Lock()
+	for conn := range l.conns {
+		_ = conn.Close()
+	}
+	l.mtx.Unlock()
+	return err
+}
+
+func (l *syslogListener) run() {
+	if l.udp != nil {
+		l.runUDP()
+		return
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.tcp.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			level.Warn(l.r.logger).Log("msg", "failed to accept syslog connection", "addr", l.addr(), "err", err)
+			continue
+		}
+
+		l.mtx.Lock()
+		l.conns[conn] = struct{}{}
+		l.mtx.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.handleConn(conn)
+
+			l.mtx.Lock()
+			delete(l.conns, conn)
+			l.mtx.Unlock()
+			_ = conn.Close()
+		}()
+	}
+}
+
+func (l *syslogListener) handleConn(conn net.Conn) {
+	r := &idleTimeoutReader{conn: conn, timeout: l.cfg.IdleTimeout}
+	if err := parseSyslogStream(l.cfg.Format == FormatRFC3164, r, l.cfg.MaxMessageLength, l.handleResult); err != nil && !errors.Is(err, io.EOF) {
+		l.r.metrics.errors.WithLabelValues(receiverSyslog, ProtocolTCP).Inc()
+		level.Debug(l.r.logger).Log("msg", "failed to read syslog messages", "remote_addr", conn.RemoteAddr(), "err", err)
+	}
+}
+
+func (l *syslogListener) runUDP() {
+	machine := rfc5424.NewParser(rfc5424.WithBestEffort())
+	if l.cfg.Format == FormatRFC3164 {
+		machine = rfc3164.NewParser(rfc3164.WithBestEffort(), rfc3164.WithYear(rfc3164.CurrentYear{}))
+	}
+
+	buf := make([]byte, l.cfg.MaxMessageLength)
+	for {
+		n, _, err := l.udp.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			l.r.metrics.errors.WithLabelValues(receiverSyslog, ProtocolUDP).Inc()
+			continue
+		}
+		msg, err := machine.Parse(buf[:n])
+		l.handleResult(&syslog.Result{Message: msg, Error: err})
+	}
+}
+
+func (l *syslogListener) handleResult(res *syslog.Result) {
+	l.r.metrics.messages.WithLabelValues(receiverSyslog, l.cfg.ListenProtocol).Inc()
+	// In best effort mode, the partially parsed messages are returned along
+	// with the error.
+	if res.Message == nil || !res.Message.Valid() {
+		l.r.metrics.errors.WithLabelValues(receiverSyslog, l.cfg.ListenProtocol).Inc()
+		return
+	}
+
+	var (
+		base   syslog.Base
+		fields = make(map[string]string)
+	)
+	switch msg := res.Message.(type) {
+	case *rfc5424.SyslogMessage:
+		base = msg.Base
+		if msg.StructuredData != nil {
+			for id, params := range *msg.StructuredData {
+				for name, value := range params {
+					fields["sd."+id+"."+name] = value
+				}
+			}
+		}
+	case *rfc3164.SyslogMessage:
+		base = msg.Base
+	default:
+		l.r.metrics.errors.WithLabelValues(receiverSyslog, l.cfg.ListenProtocol).Inc()
+		return
+	}
+	if base.Message == nil {
+		// there is nothing to store.
+		return
+	}
+
+	setField(fields, "hostname", base.Hostname)
+	setField(fields, "app_name", base.Appname)
+	setField(fields, "proc_id", base.ProcID)
+	setField(fields, "msg_id", base.MsgID)
+	setField(fields, "facility", res.Message.FacilityLevel())
+	setField(fields, "severity", res.Message.SeverityLevel())
+
+	var incoming time.Time
+	if base.Timestamp != nil {
+		incoming = *base.Timestamp
+		if incoming.Year() == 0 {
+			// the RFC3164 timestamps don't have a year.
+			incoming = incoming.AddDate(time.Now().Year(), 0, 0)
+		}
+	}
+
+	l.r.handle(receiverSyslog, l.cfg.ListenerConfig, fields, logproto.Entry{
+		Timestamp: timestamp(l.cfg.ListenerConfig, incoming),
+		Line:      *base.Message,
+	})
+}
+
+func setField(fields map[string]string, name string, value *string) {
+	if value != nil {
+		fields[name] = *value
+	}
+}
+
+// parseSyslogStream parses the syslog messages of a stream, calling the
+// callback for each of them. The framing of the messages, octet counting or
+// non-transparent, is detected from the first byte of the stream.
+func parseSyslogStream(isRFC3164 bool, r io.Reader, maxMessageLength int, callback func(res *syslog.Result)) error {
+	buf := bufio.NewReaderSize(r, 1<<10)
+
+	b, err := buf.ReadByte()
+	if err != nil {
+		return err
+	}
+	_ = buf.UnreadByte()
+
+	opts := []syslog.ParserOption{syslog.WithListener(callback), syslog.WithMaxMessageLength(maxMessageLength), syslog.WithBestEffort()}
+	switch {
+	case b == '<' && isRFC3164:
+		nontransparent.NewParserRFC3164(opts...).Parse(buf)
+	case b == '<':
+		nontransparent.NewParser(opts...).Parse(buf)
+	case b >= '0' && b <= '9' && isRFC3164:
+		octetcounting.NewParserRFC3164(opts...).Parse(buf)
+	case b >= '0' && b <= '9':
+		octetcounting.NewParser(opts...).Parse(buf)
+	default:
+		return fmt.Errorf("invalid or unsupported framing, first byte: %s", strconv.QuoteRune(rune(b)))
+	}
+	return nil
+}
+
+// idleTimeoutReader closes the idle connections by extending the read
+// deadline of the connection before each read.
+type idleTimeoutReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	if err := r.conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+		return 0, err
+	}
+	return r.conn.Read(p)
+}
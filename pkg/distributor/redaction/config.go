@@ -0,0 +1,85 @@
+package redaction
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/dskit/flagext"
+)
+
+const (
+	// ModeFixed replaces the sensitive data with a fixed token.
+	ModeFixed = "fixed"
+	// ModeHash replaces the sensitive data with a hash of it, so that the log
+	// lines with the same sensitive data can still be correlated.
+	ModeHash = "hash"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled" json:"enabled" doc:"description=Redact the sensitive data found by the detectors in the log lines and the structured metadata before they are ingested."`
+
+	Detectors flagext.StringSliceCSV `yaml:"detectors" json:"detectors" doc:"description=Built-in detectors used to find sensitive data. Supported values are 'credit_card' for Luhn-validated card numbers, 'jwt', 'bearer_token', 'aws_access_key' and 'email'."`
+
+	CustomPatterns []CustomPattern `yaml:"custom_patterns,omitempty" json:"custom_patterns,omitempty" doc:"description=Additional patterns of sensitive data.\nExample:\n custom_patterns:\n - name: employee_id\n regex: 'EMP-[0-9]{6}'"`
+
+	Mode string `yaml:"mode" json:"mode" doc:"description=How the sensitive data is replaced. Supported values are 'fixed', to replace it with the token, and 'hash', to replace it with the detector name and a salted hash of the data."`
+
+	Token string `yaml:"token" json:"token" doc:"description=Token the sensitive data is replaced with in the 'fixed' mode."`
+
+	HashSalt string `yaml:"hash_salt" json:"hash_salt" doc:"description=Salt of the hashes in the 'hash' mode. Without a salt, the hashes of data with few possible values such as card numbers can be reversed."`
+
+	detectors []detector
+}
+
+// CustomPattern is a regular expression matching sensitive data.
+type CustomPattern struct {
+	Name  string `yaml:"name" json:"name"`
+	Regex string `yaml:"regex" json:"regex"`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.BoolVar(&cfg.Enabled, prefix+".enabled", false, "Redact the sensitive data found by the detectors in the log lines and the structured metadata before they are ingested.")
+	cfg.Detectors = builtinDetectorNames()
+	fs.Var(&cfg.Detectors, prefix+".detectors", "Comma separated list of the built-in detectors used to find sensitive data. Supported values are 'credit_card' for Luhn-validated card numbers, 'jwt', 'bearer_token', 'aws_access_key' and 'email'.")
+	fs.StringVar(&cfg.Mode, prefix+".mode", ModeFixed, "How the sensitive data is replaced. Supported values are 'fixed', to replace it with the token, and 'hash', to replace it with the detector name and a salted hash of the data.")
+	fs.StringVar(&cfg.Token, prefix+".token", "[REDACTED]", "Token the sensitive data is replaced with in the 'fixed' mode.")
+	fs.StringVar(&cfg.HashSalt, prefix+".hash-salt", "", "Salt of the hashes in the 'hash' mode. Without a salt, the hashes of data with few possible values such as card numbers can be reversed.")
+}
+
+// Validate validates the config and compiles its detectors.
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if !slices.Contains([]string{ModeFixed, ModeHash}, cfg.Mode) {
+		return fmt.Errorf("invalid redaction mode %q, supported modes are %s and %s", cfg.Mode, ModeFixed, ModeHash)
+	}
+
+	detectors := make([]detector, 0, len(cfg.Detectors)+len(cfg.CustomPatterns))
+	for _, name := range cfg.Detectors {
+		d, ok := builtinDetectors[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("unknown redaction detector %q, supported detectors are %s", name, strings.Join(builtinDetectorNames(), ", "))
+		}
+		detectors = append(detectors, d)
+	}
+
+	for _, p := range cfg.CustomPatterns {
+		if p.Name == "" {
+			return errors.New("redaction custom pattern name must not be empty")
+		}
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid redaction custom pattern %s: %w", p.Name, err)
+		}
+		detectors = append(detectors, detector{name: p.Name, re: re})
+	}
+
+	cfg.detectors = detectors
+	return nil
+}
@@ -0,0 +1,196 @@
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// detector finds sensitive data with a regular expression. When the regular
+// expression has a capturing group, only the first group is redacted.
+type detector struct {
+	name string
+	re   *regexp.Regexp
+	// spans returns the parts of a match to redact, if set. It discards the
+	// false positives of the regular expression.
+	spans func(match string) [][2]int
+}
+
+var builtinDetectors = map[string]detector{
+	"credit_card": {
+		name:  "credit_card",
+		re:    regexp.MustCompile(`\b\d(?:[ -]?\d){12,}\b`),
+		spans: cardNumbers,
+	},
+	"jwt": {
+		name: "jwt",
+		re:   regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	},
+	"bearer_token": {
+		name: "bearer_token",
+		re:   regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/-]+=*)`),
+	},
+	"aws_access_key": {
+		name: "aws_access_key",
+		re:   regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`),
+	},
+	"email": {
+		name: "email",
+		re:   regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+}
+
+func builtinDetectorNames() []string {
+	names := make([]string, 0, len(builtinDetectors))
+	for name := range builtinDetectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cardNumbers returns the spans of the card numbers in a sequence of digit
+// groups. A card number is made of whole groups, so that the digits next to it,
+// e.g. `paid 4111111111111111 200 OK`, are not taken as part of it.
+func cardNumbers(match string) [][2]int {
+	var groups [][2]int
+	for i := 0; i < len(match); {
+		if !isDigit(match[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(match) && isDigit(match[j]) {
+			j++
+		}
+		groups = append(groups, [2]int{i, j})
+		i = j
+	}
+
+	var spans [][2]int
+	for first := 0; first < len(groups); first++ {
+		if !cardPrefix(match[groups[first][0]:groups[first][1]]) {
+			continue
+		}
+		// the longest card number starting with this group is redacted.
+		last, digits := -1, 0
+		for i := first; i < len(groups); i++ {
+			digits += groups[i][1] - groups[i][0]
+			if digits > 19 {
+				break
+			}
+			if digits >= 13 && luhnValid(match[groups[first][0]:groups[i][1]]) {
+				last = i
+			}
+		}
+		if last < 0 {
+			continue
+		}
+		spans = append(spans, [2]int{groups[first][0], groups[last][1]})
+		first = last
+	}
+	return spans
+}
+
+// cardPrefix returns whether the first digit group of a card number starts
+// like the card numbers of the major networks: 3 to 6, or 2221 to 2720 for the
+// Mastercard 2-series. This excludes the Unix timestamps.
+func cardPrefix(group string) bool {
+	switch c := group[0]; {
+	case c >= '3' && c <= '6':
+		return true
+	case c == '2' && len(group) >= 4:
+		prefix := group[:4]
+		return prefix >= "2221" && prefix <= "2720"
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// luhnValid returns whether the digits of s pass the Luhn checksum used by the
+// card numbers.
+func luhnValid(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if !isDigit(c) {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
+
+// Redact replaces the sensitive data found by the detectors of the config in
+// s. matched is called with the name of the detector for every match.
+// The config must have been validated.
+func (cfg *Config) Redact(s string, matched func(detector string)) string {
+	for _, d := range cfg.detectors {
+		s = cfg.redact(d, s, matched)
+	}
+	return s
+}
+
+func (cfg *Config) redact(d detector, s string, matched func(detector string)) string {
+	indexes := d.re.FindAllStringSubmatchIndex(s, -1)
+	if len(indexes) == 0 {
+		return s
+	}
+
+	var (
+		sb       strings.Builder
+		last     int
+		replaced bool
+	)
+	for _, idx := range indexes {
+		start, end := idx[0], idx[1]
+		// only the first capturing group is redacted.
+		if len(idx) >= 4 && idx[2] >= 0 {
+			start, end = idx[2], idx[3]
+		}
+		match := s[start:end]
+		if match == "" {
+			continue
+		}
+		spans := [][2]int{{0, len(match)}}
+		if d.spans != nil {
+			spans = d.spans(match)
+		}
+		for _, span := range spans {
+			sb.WriteString(s[last : start+span[0]])
+			sb.WriteString(cfg.replacement(d.name, match[span[0]:span[1]]))
+			last, replaced = start+span[1], true
+			if matched != nil {
+				matched(d.name)
+			}
+		}
+	}
+	if !replaced {
+		return s
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+func (cfg *Config) replacement(detector, match string) string {
+	if cfg.Mode != ModeHash {
+		return cfg.Token
+	}
+	h := hmac.New(sha256.New, []byte(cfg.HashSalt))
+	h.Write([]byte(match))
+	return "[" + detector + ":" + hex.EncodeToString(h.Sum(nil))[:16] + "]"
+}
@@ -9,6 +9,10 @@ import (
 
 	"github.com/prometheus/prometheus/model/labels"
 
+	"github.com/grafana/loki/v3/pkg/distributor/adaptivesampling"
+	"github.com/grafana/loki/v3/pkg/distributor/cardinalityguard"
+	"github.com/grafana/loki/v3/pkg/distributor/deadletter"
+	"github.com/grafana/loki/v3/pkg/distributor/redaction"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/validation"
@@ -45,6 +49,7 @@ type validationContext struct {
 	incrementDuplicateTimestamps bool
 	discoverServiceName          []string
 	discoverLogLevels            bool
+	extractFields                []validation.ExtractedField
 
 	allowStructuredMetadata    bool
 	maxStructuredMetadataSize  int
@@ -53,6 +58,16 @@ type validationContext struct {
 	blockIngestionUntil      time.Time
 	blockIngestionStatusCode int
 
+	ingestionRatePolicies []validation.IngestionRatePolicy
+	ingestionPipelines    []validation.IngestionPipeline
+	redaction             redaction.Config
+	adaptiveSampling      adaptivesampling.Config
+	cardinalityGuard      cardinalityguard.Config
+	deadLetter            deadletter.Config
+	// deadLetterReplay records the rejected log lines instead of the
+	// dead-letter store while replaying it.
+	deadLetterReplay *deadLetterReplay
+
 	userID string
 }
 
@@ -70,16 +85,29 @@ func (v Validator) getValidationContextForTime(now time.Time, userID string) val
 		incrementDuplicateTimestamps: v.IncrementDuplicateTimestamps(userID),
 		discoverServiceName:          v.DiscoverServiceName(userID),
 		discoverLogLevels:            v.DiscoverLogLevels(userID),
+		extractFields:                v.ExtractFields(userID),
 		allowStructuredMetadata:      v.AllowStructuredMetadata(userID),
 		maxStructuredMetadataSize:    v.MaxStructuredMetadataSize(userID),
 		maxStructuredMetadataCount:   v.MaxStructuredMetadataCount(userID),
 		blockIngestionUntil:          v.BlockIngestionUntil(userID),
 		blockIngestionStatusCode:     v.BlockIngestionStatusCode(userID),
+		ingestionRatePolicies:        v.IngestionRatePolicies(userID),
+		ingestionPipelines:           v.IngestionPipelines(userID),
+		redaction:                    v.Redaction(userID),
+		adaptiveSampling:             v.AdaptiveSampling(userID),
+		cardinalityGuard:             v.CardinalityGuard(userID),
+		deadLetter:                   v.DeadLetter(userID),
 	}
 }
 
 // ValidateEntry returns an error if the entry is invalid and report metrics for invalid entries accordingly.
 func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, labels labels.Labels, entry logproto.Entry) error {
+	_, err := v.validateEntry(ctx, vCtx, labels, entry)
+	return err
+}
+
+// validateEntry is ValidateEntry, also returning the reason the entry is discarded.
+func (v Validator) validateEntry(ctx context.Context, vCtx validationContext, labels labels.Labels, entry logproto.Entry) (string, error) {
 	ts := entry.Timestamp.UnixNano()
 	validation.LineLengthHist.Observe(float64(len(entry.Line)))
 
@@ -92,7 +120,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 		if v.usageTracker != nil {
 			v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.GreaterThanMaxSampleAge, labels, float64(len(entry.Line)))
 		}
-		return fmt.Errorf(validation.GreaterThanMaxSampleAgeErrorMsg, labels, formatedEntryTime, formatedRejectMaxAgeTime)
+		return validation.GreaterThanMaxSampleAge, fmt.Errorf(validation.GreaterThanMaxSampleAgeErrorMsg, labels, formatedEntryTime, formatedRejectMaxAgeTime)
 	}
 
 	if ts > vCtx.creationGracePeriod {
@@ -102,7 +130,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 		if v.usageTracker != nil {
 			v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.TooFarInFuture, labels, float64(len(entry.Line)))
 		}
-		return fmt.Errorf(validation.TooFarInFutureErrorMsg, labels, formatedEntryTime)
+		return validation.TooFarInFuture, fmt.Errorf(validation.TooFarInFutureErrorMsg, labels, formatedEntryTime)
 	}
 
 	if maxSize := vCtx.maxLineSize; maxSize != 0 && len(entry.Line) > maxSize {
@@ -115,7 +143,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 		if v.usageTracker != nil {
 			v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.LineTooLong, labels, float64(len(entry.Line)))
 		}
-		return fmt.Errorf(validation.LineTooLongErrorMsg, maxSize, labels, len(entry.Line))
+		return validation.LineTooLong, fmt.Errorf(validation.LineTooLongErrorMsg, maxSize, labels, len(entry.Line))
 	}
 
 	if len(entry.StructuredMetadata) > 0 {
@@ -125,7 +153,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 			if v.usageTracker != nil {
 				v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.DisallowedStructuredMetadata, labels, float64(len(entry.Line)))
 			}
-			return fmt.Errorf(validation.DisallowedStructuredMetadataErrorMsg, labels)
+			return validation.DisallowedStructuredMetadata, fmt.Errorf(validation.DisallowedStructuredMetadataErrorMsg, labels)
 		}
 
 		var structuredMetadataSizeBytes, structuredMetadataCount int
@@ -140,7 +168,7 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 			if v.usageTracker != nil {
 				v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.StructuredMetadataTooLarge, labels, float64(len(entry.Line)))
 			}
-			return fmt.Errorf(validation.StructuredMetadataTooLargeErrorMsg, labels, structuredMetadataSizeBytes, vCtx.maxStructuredMetadataSize)
+			return validation.StructuredMetadataTooLarge, fmt.Errorf(validation.StructuredMetadataTooLargeErrorMsg, labels, structuredMetadataSizeBytes, vCtx.maxStructuredMetadataSize)
 		}
 
 		if maxCount := vCtx.maxStructuredMetadataCount; maxCount != 0 && structuredMetadataCount > maxCount {
@@ -149,11 +177,11 @@ func (v Validator) ValidateEntry(ctx context.Context, vCtx validationContext, la
 			if v.usageTracker != nil {
 				v.usageTracker.DiscardedBytesAdd(ctx, vCtx.userID, validation.StructuredMetadataTooMany, labels, float64(len(entry.Line)))
 			}
-			return fmt.Errorf(validation.StructuredMetadataTooManyErrorMsg, labels, structuredMetadataCount, vCtx.maxStructuredMetadataCount)
+			return validation.StructuredMetadataTooMany, fmt.Errorf(validation.StructuredMetadataTooManyErrorMsg, labels, structuredMetadataCount, vCtx.maxStructuredMetadataCount)
 		}
 	}
 
-	return nil
+	return "", nil
 }
 
 // Validate labels returns an error if the labels are invalid
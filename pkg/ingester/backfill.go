@@ -0,0 +1,86 @@
+package ingester
+
+import (
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// storeBackfill appends the entries accepted by the out-of-order window of the
+// stream but too far behind its head chunk to the backfill chunk of the stream.
+// The backfill chunk batches them apart from the head chunk until it is full
+// or older than the backfill max age, and is then flushed like the other
+// chunks. The duplicated entries are skipped.
+// Must hold chunkMtx.
+func (s *stream) storeBackfill(entries []logproto.Entry) (int, []logproto.Entry, []entryWithError) {
+	if len(entries) == 0 {
+		return 0, nil, nil
+	}
+
+	var (
+		bytesAdded int
+		invalid    []entryWithError
+		stored     = make([]logproto.Entry, 0, len(entries))
+		watermark  = s.snapshotWatermark.Load()
+		chunk      = s.backfillChunk()
+	)
+	for i := range entries {
+		if chunk == nil || !chunk.chunk.SpaceFor(&entries[i]) {
+			if chunk != nil {
+				chunk.closed = true
+			}
+			chunk = s.cutBackfillChunk()
+		}
+
+		chunk.lastUpdated = time.Now()
+		dup, err := chunk.chunk.Append(&entries[i])
+		if err != nil {
+			invalid = append(invalid, entryWithError{&entries[i], err})
+			continue
+		}
+		if dup {
+			s.handleLoggingOfDuplicateEntry(entries[i])
+			continue
+		}
+		if watermark != nil {
+			watermark.appended(entries[i].Timestamp)
+		}
+
+		s.entryCt++
+		bytesAdded += len(entries[i].Line)
+		stored = append(stored, entries[i])
+	}
+	s.metrics.backfilledEntriesTotal.WithLabelValues(s.tenant).Add(float64(len(stored)))
+	return bytesAdded, stored, invalid
+}
+
+// backfillChunk returns the chunk of the stream batching the backfilled
+// entries, if any.
+// Must hold chunkMtx.
+func (s *stream) backfillChunk() *chunkDesc {
+	for i := range s.chunks {
+		if !s.chunks[i].backfillSince.IsZero() && !s.chunks[i].closed {
+			return &s.chunks[i]
+		}
+	}
+	return nil
+}
+
+// cutBackfillChunk adds a new backfill chunk in front of the chunks of the
+// stream, so that the last chunk stays the head chunk.
+// Must hold chunkMtx.
+func (s *stream) cutBackfillChunk() *chunkDesc {
+	now := time.Now()
+	// NB: the chunks are copied to a new slice rather than shifted in place,
+	// since the chunks being flushed are referenced by their position.
+	chunks := make([]chunkDesc, 0, len(s.chunks)+1)
+	chunks = append(chunks, chunkDesc{
+		chunk:         s.NewChunk(),
+		backfillSince: now,
+		lastUpdated:   now,
+	})
+	s.chunks = append(chunks, s.chunks...)
+	s.metrics.chunksCreatedTotal.Inc()
+	s.metrics.chunkCreatedStats.Inc(1)
+	return &s.chunks[0]
+}
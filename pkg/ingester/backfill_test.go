@@ -0,0 +1,100 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	gokitlog "github.com/go-kit/log"
+	"github.com/grafana/dskit/services"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
+	"github.com/grafana/loki/v3/pkg/ingester/client"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/runtime"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func TestBackfill(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.MaxChunkAge = 10 * time.Second
+	cfg.BackfillMaxAge = 10 * time.Millisecond
+	limits := defaultLimitsTestConfig()
+	limits.OutOfOrderWindows = []validation.OutOfOrderWindow{
+		{Selector: `{source="iot"}`, Window: model.Duration(10 * time.Minute), Backfill: true},
+	}
+	require.NoError(t, limits.Validate())
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	store := &testStore{chunks: map[string][]chunk.Chunk{}}
+	ing, err := New(cfg, client.Config{}, store, overrides, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, gokitlog.NewNopLogger(), nil, mockReadRingWithOneActiveIngester(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	push := func(labels string, secs ...int64) error {
+		var entries []logproto.Entry
+		for _, sec := range secs {
+			entries = append(entries, logproto.Entry{Timestamp: time.Unix(sec, 0), Line: fmt.Sprint(sec)})
+		}
+		_, err := ing.Push(ctx, &logproto.PushRequest{Streams: []logproto.Stream{{Labels: labels, Entries: entries}}})
+		return err
+	}
+
+	// the entries within the in-memory window are appended to the stream, the
+	// ones within the out-of-order window are backfilled, the others are
+	// discarded.
+	require.Error(t, push(`{source="iot"}`, 900, 800, 898, 100, 400))
+	// the streams not matching the window keep the default behavior.
+	require.Error(t, push(`{source="app"}`, 900, 800))
+
+	inst, ok := ing.getInstanceByID("test")
+	require.True(t, ok)
+	s, ok := inst.streams.Load(`{source="iot"}`)
+	require.True(t, ok)
+	require.Len(t, s.chunks, 2)
+	stream := buildStreamsFromChunk(t, `{source="iot"}`, s.chunks[1].chunk)
+	require.Equal(t, []logproto.Entry{
+		{Timestamp: time.Unix(898, 0), Line: "898"},
+		{Timestamp: time.Unix(900, 0), Line: "900"},
+	}, stream.Entries)
+
+	// the backfilled entries are batched in memory rather than written by the
+	// push.
+	require.Equal(t, s.backfillChunk(), &s.chunks[0])
+	require.Empty(t, store.getChunksForUser("test"))
+
+	// the backfill chunk is flushed once older than the backfill max age,
+	// while the head chunk stays in memory.
+	time.Sleep(2 * cfg.BackfillMaxAge)
+	ing.sweepUsers(false, false)
+	require.Eventually(t, func() bool {
+		return len(store.getChunksForUser("test")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	backfilled := store.getChunksForUser("test")[0]
+	require.Equal(t, `{source="iot"}`, backfilled.Metric.String())
+	require.Equal(t, model.TimeFromUnix(400), backfilled.From)
+	require.Equal(t, model.TimeFromUnix(800), backfilled.Through)
+	stream = buildStreamsFromChunk(t, `{source="iot"}`, backfilled.Data.(*chunkenc.Facade).LokiChunk())
+	require.Equal(t, []logproto.Entry{
+		{Timestamp: time.Unix(400, 0), Line: "400"},
+		{Timestamp: time.Unix(800, 0), Line: "800"},
+	}, stream.Entries)
+
+	s.chunkMtx.RLock()
+	defer s.chunkMtx.RUnlock()
+	require.Equal(t, flushReasonMaxAge, s.chunks[0].reason)
+	require.False(t, s.chunks[0].flushed.IsZero())
+	require.True(t, s.chunks[1].flushed.IsZero())
+	require.Nil(t, s.backfillChunk())
+}
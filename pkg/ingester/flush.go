@@ -361,6 +361,10 @@ func (i *Ingester) shouldFlushChunk(chunk *chunkDesc) (bool, string) {
 		return true, flushReasonFull
 	}
 
+	if !chunk.backfillSince.IsZero() && time.Since(chunk.backfillSince) > i.cfg.BackfillMaxAge {
+		return true, flushReasonMaxAge
+	}
+
 	if time.Since(chunk.lastUpdated) > i.cfg.MaxChunkIdle {
 		return true, flushReasonIdle
 	}
@@ -457,6 +461,9 @@ func (i *Ingester) flushChunks(ctx context.Context, fp model.Fingerprint, labelP
 		}()
 
 		i.reportFlushedChunkStatistics(&ch, c, sizePerTenant, countPerTenant, reason)
+		if !c.backfillSince.IsZero() {
+			i.metrics.backfilledChunksTotal.WithLabelValues(userID).Inc()
+		}
 		i.markChunkAsFlushed(cs[j], chunkMtx)
 	}
 
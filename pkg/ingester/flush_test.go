@@ -69,6 +69,78 @@ func TestChunkFlushingShutdown(t *testing.T) {
 	store.checkData(t, testData)
 }
 
+func TestChunkFlushingColumnarChunks(t *testing.T) {
+	periodConfigs := []config.PeriodConfig{defaultPeriodConfigs[0]}
+	periodConfigs[0].ColumnarChunks = true
+	store := &testStore{
+		chunks:        map[string][]chunk.Chunk{},
+		schemaConfigs: periodConfigs,
+	}
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	cfg := defaultIngesterTestConfig(t)
+	cfg.BlockSize = 128
+	ing, err := New(cfg, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, gokitlog.NewNopLogger(), nil, mockReadRingWithOneActiveIngester(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+
+	testData := pushTestSamples(t, ing)
+	inst, ok := ing.getInstanceByID("1")
+	require.True(t, ok)
+	require.NoError(t, inst.forAllStreams(context.Background(), func(s *stream) error {
+		require.Equal(t, chunkenc.ChunkFormatV5, s.chunkFormat)
+		return nil
+	}))
+
+	// the blocks cut from the head are skipped with their summaries.
+	query := func(selector string) (int, int64) {
+		ctx := user.InjectOrgID(context.Background(), "1")
+		result := mockQuerierServer{ctx: ctx}
+		require.NoError(t, ing.Query(&logproto.QueryRequest{
+			Selector:  selector,
+			Limit:     numSeries * samplesPerSeries,
+			Start:     time.Unix(0, 0),
+			End:       time.Unix(1000, 0),
+			Direction: logproto.FORWARD,
+		}, &result))
+		var entries int
+		var decompressedLines int64
+		for _, resp := range result.resps {
+			for _, s := range resp.Streams {
+				entries += len(s.Entries)
+			}
+			decompressedLines += resp.Stats.Store.Chunk.DecompressedLines
+		}
+		return entries, decompressedLines
+	}
+	entries, decompressedLines := query(`{job="testjob"} |= "line"`)
+	require.Equal(t, numSeries*samplesPerSeries, entries)
+	require.Greater(t, decompressedLines, int64(0))
+	entries, decompressedLines = query(`{job="testjob"} |= "missing"`)
+	require.Equal(t, 0, entries)
+	require.Equal(t, int64(0), decompressedLines)
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+	store.checkData(t, testData)
+
+	// the flushed chunks are written and read back in the columnar format.
+	for userID, expected := range testData {
+		var streams []logproto.Stream
+		for _, c := range store.getChunksForUser(userID) {
+			b, err := c.Data.(*chunkenc.Facade).LokiChunk().Bytes()
+			require.NoError(t, err)
+			require.Equal(t, chunkenc.ChunkFormatV5, b[4])
+			chk, err := chunkenc.NewByteChunk(b, 0, 0)
+			require.NoError(t, err)
+			streams = append(streams, buildStreamsFromChunk(t, c.Metric.String(), chk))
+		}
+		sort.Slice(streams, func(i, j int) bool {
+			return streams[i].Labels < streams[j].Labels
+		})
+		require.Equal(t, expected, streams)
+	}
+}
+
 type fullWAL struct{}
 
 func (fullWAL) Log(_ *wal.Record) error { return &os.PathError{Err: syscall.ENOSPC} }
@@ -378,6 +450,8 @@ type testStore struct {
 	// Chunks keyed by userID.
 	chunks map[string][]chunk.Chunk
 	onPut  func(ctx context.Context, chunks []chunk.Chunk) error
+	// schemaConfigs overrides defaultPeriodConfigs.
+	schemaConfigs []config.PeriodConfig
 }
 
 // Note: the ingester New() function creates it's own WAL first which we then override if specified.
@@ -494,6 +568,9 @@ func (s *testStore) HasForSeries(_, _ model.Time) (sharding.ForSeries, bool) {
 }
 
 func (s *testStore) GetSchemaConfigs() []config.PeriodConfig {
+	if s.schemaConfigs != nil {
+		return s.schemaConfigs
+	}
 	return defaultPeriodConfigs
 }
 
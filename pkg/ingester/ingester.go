@@ -18,6 +18,7 @@ import (
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/backoff"
 	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/kv"
 	"github.com/grafana/dskit/modules"
 	"github.com/grafana/dskit/multierror"
@@ -33,6 +34,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/analytics"
 	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/distributor/idempotency"
 	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
 	"github.com/grafana/loki/v3/pkg/ingester/client"
 	"github.com/grafana/loki/v3/pkg/ingester/index"
@@ -100,6 +102,7 @@ type Config struct {
 	ChunkEncoding       string            `yaml:"chunk_encoding"`
 	parsedEncoding      compression.Codec `yaml:"-"` // placeholder for validated encoding
 	MaxChunkAge         time.Duration     `yaml:"max_chunk_age"`
+	BackfillMaxAge      time.Duration     `yaml:"backfill_max_age"`
 	AutoForgetUnhealthy bool              `yaml:"autoforget_unhealthy"`
 
 	// Synchronization settings. Used to make sure that ingesters cut their chunks at the same moments.
@@ -116,10 +119,15 @@ type Config struct {
 
 	WAL WALConfig `yaml:"wal,omitempty" doc:"description=The ingester WAL (Write Ahead Log) records incoming logs and stores them on the local file systems in order to guarantee persistence of acknowledged data in the event of a process crash."`
 
+	QuerySnapshots QuerySnapshotConfig `yaml:"query_snapshots" category:"experimental" doc:"description=Experimental: The query snapshots are on-disk snapshots of the streams, periodically cut by the ingester, from which the queries matching many streams are served so that they don't stall the pushes."`
+
 	ChunkFilterer          chunk.RequestChunkFilterer     `yaml:"-"`
 	PipelineWrapper        lokilog.PipelineWrapper        `yaml:"-"`
 	SampleExtractorWrapper lokilog.SampleExtractorWrapper `yaml:"-"`
 
+	// ZstdDictionaries provides the zstd dictionaries of the zstd-dict chunk encoding.
+	ZstdDictionaries ZstdDictionaries `yaml:"-"`
+
 	// Optional wrapper that can be used to modify the behaviour of the ingester
 	Wrapper Wrapper `yaml:"-"`
 
@@ -138,6 +146,7 @@ type Config struct {
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.LifecyclerConfig.RegisterFlags(f, util_log.Logger)
 	cfg.WAL.RegisterFlags(f)
+	cfg.QuerySnapshots.RegisterFlags(f)
 	cfg.KafkaIngestion.RegisterFlags(f)
 
 	f.IntVar(&cfg.ConcurrentFlushes, "ingester.concurrent-flushes", 32, "How many flushes can happen concurrently from each stream.")
@@ -155,6 +164,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.Float64Var(&cfg.SyncMinUtilization, "ingester.sync-min-utilization", 0.1, "Minimum utilization of chunk when doing synchronization.")
 	f.IntVar(&cfg.MaxReturnedErrors, "ingester.max-ignored-stream-errors", 10, "The maximum number of errors a stream will report to the user when a push fails. 0 to make unlimited.")
 	f.DurationVar(&cfg.MaxChunkAge, "ingester.max-chunk-age", 2*time.Hour, "The maximum duration of a timeseries chunk in memory. If a timeseries runs for longer than this, the current chunk will be flushed to the store and a new chunk created.")
+	f.DurationVar(&cfg.BackfillMaxAge, "ingester.backfill-max-age", time.Minute, "How long the log lines backfilled by the out-of-order windows are batched in memory per stream before being flushed to the store, if their chunk isn't full before.")
 	f.DurationVar(&cfg.QueryStoreMaxLookBackPeriod, "ingester.query-store-max-look-back-period", 0, "How far back should an ingester be allowed to query the store for data, for use only with boltdb-shipper/tsdb index and filesystem object store. -1 for infinite.")
 	f.BoolVar(&cfg.AutoForgetUnhealthy, "ingester.autoforget-unhealthy", false, "Forget about ingesters having heartbeat timestamps older than `ring.kvstore.heartbeat_timeout`. This is equivalent to clicking on the `/ring` `forget` button in the UI: the ingester is removed from the ring. This is a useful setting when you are sure that an unhealthy node won't return. An example is when not using stateful sets or the equivalent. Use `memberlist.rejoin_interval` > 0 to handle network partition cases when using a memberlist.")
 	f.IntVar(&cfg.IndexShards, "ingester.index-shards", index.DefaultIndexShards, "Shard factor used in the ingesters for the in process reverse index. This MUST be evenly divisible by ALL schema shard factors or Loki will not start.")
@@ -174,6 +184,10 @@ func (cfg *Config) Validate() error {
 		return err
 	}
 
+	if err = cfg.QuerySnapshots.Validate(); err != nil {
+		return err
+	}
+
 	if cfg.FlushOpBackoff.MinBackoff > cfg.FlushOpBackoff.MaxBackoff {
 		return errors.New("invalid flush op min backoff: cannot be larger than max backoff")
 	}
@@ -288,6 +302,9 @@ type Ingester struct {
 
 	writeLogManager *writefailures.Manager
 
+	// Idempotency keys of the push requests.
+	idempotencyKeys *idempotency.Cache
+
 	customStreamsTracker push.UsageTracker
 
 	// recalculateOwnedStreams periodically checks the ring for changes and recalculates owned streams for each instance.
@@ -329,6 +346,7 @@ func New(cfg Config, clientConfig client.Config, store Store, limits Limits, con
 		terminateOnShutdown:   false,
 		streamRateCalculator:  NewStreamRateCalculator(),
 		writeLogManager:       writefailures.NewManager(logger, registerer, writeFailuresCfg, configs, "ingester"),
+		idempotencyKeys:       idempotency.NewCache(),
 		customStreamsTracker:  customStreamsTracker,
 		readRing:              readRing,
 	}
@@ -346,6 +364,12 @@ func New(cfg Config, clientConfig client.Config, store Store, limits Limits, con
 		}
 	}
 
+	if cfg.QuerySnapshots.Enabled {
+		if err := removeQuerySnapshots(cfg.QuerySnapshots.Dir); err != nil {
+			return nil, fmt.Errorf("removing query snapshots at %q: %w", cfg.QuerySnapshots.Dir, err)
+		}
+	}
+
 	wal, err := newWAL(cfg.WAL, registerer, metrics, newIngesterSeriesIter(i))
 	if err != nil {
 		return nil, err
@@ -615,6 +639,11 @@ func (i *Ingester) starting(ctx context.Context) (err error) {
 	i.loopDone.Add(1)
 	go i.loop()
 
+	if i.cfg.QuerySnapshots.Enabled {
+		i.loopDone.Add(1)
+		go i.querySnapshotsLoop()
+	}
+
 	// When kafka ingestion is enabled, we have to make sure that reader catches up replaying the partition
 	// BEFORE the ingester ring lifecycler is started, because once the ingester ring lifecycler will start
 	// it will switch the ingester state in the ring to ACTIVE.
@@ -996,6 +1025,26 @@ func (i *Ingester) Push(ctx context.Context, req *logproto.PushRequest) (*logpro
 	if err != nil {
 		return &logproto.PushResponse{}, err
 	}
+
+	if cfg := i.limiter.limits.PushIdempotency(instanceID); req.IdempotencyKey != "" && cfg.Enabled() {
+		switch i.idempotencyKeys.Reserve(instanceID, req.IdempotencyKey, cfg) {
+		case idempotency.Done:
+			i.metrics.duplicatePushRequestsTotal.WithLabelValues(instanceID).Inc()
+			return &logproto.PushResponse{}, nil
+		case idempotency.InProgress:
+			return &logproto.PushResponse{}, httpgrpc.Errorf(http.StatusTooManyRequests, idempotency.KeyInProgressErrorMsg, req.IdempotencyKey)
+		}
+
+		// the streams appended by a previous push request of the key, which
+		// failed on other streams, are not pushed again.
+		appended, err := instance.push(ctx, req, i.idempotencyKeys.Appended(instanceID, req.IdempotencyKey))
+		if err != nil {
+			i.idempotencyKeys.Release(instanceID, req.IdempotencyKey, appended...)
+			return &logproto.PushResponse{}, err
+		}
+		i.idempotencyKeys.Commit(instanceID, req.IdempotencyKey, cfg)
+		return &logproto.PushResponse{}, nil
+	}
 	return &logproto.PushResponse{}, instance.Push(ctx, req)
 }
 
@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/dskit/services"
 	"github.com/grafana/dskit/user"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
@@ -533,6 +534,82 @@ func (m *mockQuerierServer) Context() context.Context {
 	return m.ctx
 }
 
+func TestIngesterPushIdempotencyKey(t *testing.T) {
+	ingesterConfig := defaultIngesterTestConfig(t)
+	defaultLimits := defaultLimitsTestConfig()
+	defaultLimits.PushIdempotency.Window = model.Duration(time.Minute)
+	limits, err := validation.NewOverrides(defaultLimits, nil)
+	require.NoError(t, err)
+
+	store := &mockStore{
+		chunks: map[string][]chunk.Chunk{},
+	}
+
+	i, err := New(ingesterConfig, client.Config{}, store, limits, runtime.DefaultTenantConfigs(), nil, writefailures.Cfg{}, constants.Loki, log.NewNopLogger(), nil, mockReadRingWithOneActiveIngester(), nil)
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	req := func(labels, line, key string) *logproto.PushRequest {
+		return &logproto.PushRequest{
+			Streams: []logproto.Stream{{
+				Labels:  labels,
+				Entries: []logproto.Entry{{Timestamp: time.Unix(0, 0), Line: line}},
+			}},
+			IdempotencyKey: key,
+		}
+	}
+	countEntries := func() int {
+		result := mockQuerierServer{ctx: ctx}
+		require.NoError(t, i.Query(&logproto.QueryRequest{
+			Selector: `{foo="bar"}`,
+			Limit:    100,
+			Start:    time.Unix(0, 0),
+			End:      time.Unix(1, 0),
+		}, &result))
+		n := 0
+		for _, resp := range result.resps {
+			for _, s := range resp.Streams {
+				n += len(s.Entries)
+			}
+		}
+		return n
+	}
+
+	_, err = i.Push(ctx, req(`{foo="bar"}`, "line 1", "a"))
+	require.NoError(t, err)
+	// the retries are acknowledged without being appended, even if their log
+	// lines were altered.
+	_, err = i.Push(ctx, req(`{foo="bar"}`, "line 1 altered", "a"))
+	require.NoError(t, err)
+	require.Equal(t, 1, countEntries())
+	require.Equal(t, float64(1), testutil.ToFloat64(i.metrics.duplicatePushRequestsTotal.WithLabelValues("test")))
+
+	// the key of a failed push request is not remembered.
+	_, err = i.Push(ctx, req(`{foo=}`, "line 2", "b"))
+	require.Error(t, err)
+	_, err = i.Push(ctx, req(`{foo="bar"}`, "line 2", "b"))
+	require.NoError(t, err)
+	require.Equal(t, 2, countEntries())
+
+	// the retries of a push request which partially failed only push the
+	// streams which were not appended.
+	partial := req(`{foo="bar"}`, "line 3", "c")
+	partial.Streams = append(partial.Streams, req(`{foo=}`, "line 3", "c").Streams...)
+	_, err = i.Push(ctx, partial)
+	require.Error(t, err)
+	require.Equal(t, 3, countEntries())
+	_, err = i.Push(ctx, partial)
+	require.Error(t, err)
+	require.Equal(t, 3, countEntries())
+	_, err = i.Push(ctx, req(`{foo="bar"}`, "line 3 altered", "c"))
+	require.NoError(t, err)
+	require.Equal(t, 3, countEntries())
+	_, err = i.Push(ctx, req(`{foo="bar"}`, "line 3 altered", "c"))
+	require.NoError(t, err)
+	require.Equal(t, float64(2), testutil.ToFloat64(i.metrics.duplicatePushRequestsTotal.WithLabelValues("test")))
+}
+
 func defaultLimitsTestConfig() validation.Limits {
 	limits := validation.Limits{}
 	flagext.DefaultValues(&limits)
@@ -754,7 +831,7 @@ func TestValidate(t *testing.T) {
 				FlushOpTimeout: 15 * time.Second,
 				IndexShards:    index.DefaultIndexShards,
 			},
-			expectedErr: "invalid encoding: bad-enc, supported: none, gzip, lz4-64k, snappy, lz4-256k, lz4-1M, lz4, flate, zstd",
+			expectedErr: "invalid encoding: bad-enc, supported: none, gzip, lz4-64k, snappy, lz4-256k, lz4-1M, lz4, flate, zstd, zstd-dict",
 		},
 		{
 			in: Config{
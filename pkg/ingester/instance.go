@@ -126,6 +126,10 @@ type instance struct {
 	schemaconfig *config.SchemaConfig
 
 	customStreamsTracker push.UsageTracker
+
+	// querySnapshot is the current query snapshot of the streams, if any.
+	querySnapshot    *querySnapshot
+	querySnapshotMtx sync.RWMutex
 }
 
 func newInstance(
@@ -222,6 +226,14 @@ func (i *instance) consumeChunk(ctx context.Context, ls labels.Labels, chunk *lo
 // happened to *the last stream in the request*. Ex: if three streams are part of the PushRequest
 // and all three failed, the returned error only describes what happened to the last processed stream.
 func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
+	_, err := i.push(ctx, req, nil)
+	return err
+}
+
+// push pushes the streams of the request, except the skipped ones, and returns
+// the labels of the streams appended without error.
+func (i *instance) push(ctx context.Context, req *logproto.PushRequest, skip map[string]struct{}) ([]string, error) {
+	var appended []string
 	record := recordPool.GetRecord()
 	record.UserID = i.instanceID
 	defer recordPool.PutRecord(record)
@@ -229,6 +241,9 @@ func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
 
 	var appendErr error
 	for _, reqStream := range req.Streams {
+		if _, ok := skip[reqStream.Labels]; ok {
+			continue
+		}
 
 		s, _, err := i.streams.LoadOrStoreNew(reqStream.Labels,
 			func() (*stream, error) {
@@ -249,8 +264,14 @@ func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
 			continue
 		}
 
+		s.setOutOfOrderWindow(i.limiter.OutOfOrderWindow(i.instanceID, s.labels))
+
 		_, appendErr = s.Push(ctx, reqStream.Entries, record, 0, false, rateLimitWholeStream, i.customStreamsTracker)
 		s.chunkMtx.Unlock()
+
+		if appendErr == nil {
+			appended = append(appended, reqStream.Labels)
+		}
 	}
 
 	if !record.IsEmpty() {
@@ -264,12 +285,12 @@ func (i *instance) Push(ctx context.Context, req *logproto.PushRequest) error {
 					)
 				})
 			} else {
-				return err
+				return appended, err
 			}
 		}
 	}
 
-	return appendErr
+	return appended, appendErr
 }
 
 func (i *instance) createStream(ctx context.Context, pushReqStream logproto.Stream, record *wal.Record) (*stream, error) {
@@ -476,25 +497,56 @@ func (i *instance) query(ctx context.Context, req logql.SelectLogParams) (iter.E
 		return nil, err
 	}
 
+	// The queries matching many streams read the entries of the query snapshot
+	// from disk, and only the entries pushed since it was cut from memory.
+	snapshot, snapshotStreams, err := i.matchingSnapshotStreams(ctx, req.Start, expr.Matchers(), shard)
+	if err != nil {
+		return nil, err
+	}
+	openChunks := newOpenSnapshotChunks(i.cfg.QuerySnapshots.MaxOpenChunks)
+
 	err = i.forMatchingStreams(
 		ctx,
 		req.Start,
 		expr.Matchers(),
 		shard,
 		func(stream *stream) error {
-			iter, err := stream.Iterator(ctx, stats, req.Start, req.End, req.Direction, pipeline.ForStream(stream.labels))
+			from := req.Start
+			ss := snapshotStreamFor(snapshotStreams, stream)
+			if ss != nil {
+				from = snapshot.liveFrom(stream, from)
+				if !from.Before(req.End) {
+					iters = append(iters, snapshot.Iterator(ctx, stats, openChunks, ss, req.Start, req.End, req.Direction, pipeline.ForStream(ss.labels)))
+					return nil
+				}
+			}
+			it, err := stream.Iterator(ctx, stats, from, req.End, req.Direction, pipeline.ForStream(stream.labels))
 			if err != nil {
 				return err
 			}
-			iters = append(iters, iter)
+			if ss != nil {
+				// the entries of both ranges are deduplicated.
+				it = iter.NewMergeEntryIterator(ctx, []iter.EntryIterator{snapshot.Iterator(ctx, stats, openChunks, ss, req.Start, req.End, req.Direction, pipeline.ForStream(ss.labels)), it}, req.Direction)
+			}
+			iters = append(iters, it)
 			return nil
 		},
 	)
 	if err != nil {
+		if snapshot != nil {
+			snapshot.release()
+		}
 		return nil, err
 	}
+	if snapshot == nil {
+		return iter.NewSortEntryIterator(iters, req.Direction), nil
+	}
 
-	return iter.NewSortEntryIterator(iters, req.Direction), nil
+	// the streams flushed since the snapshot was cut.
+	for _, ss := range snapshotStreams {
+		iters = append(iters, snapshot.Iterator(ctx, stats, openChunks, ss, req.Start, req.End, req.Direction, pipeline.ForStream(ss.labels)))
+	}
+	return &snapshotReleasingIterator[logproto.Entry]{StreamIterator: iter.NewSortEntryIterator(iters, req.Direction), snapshot: snapshot}, nil
 }
 
 func (i *instance) QuerySample(ctx context.Context, req logql.SelectSampleParams) (iter.SampleIterator, error) {
@@ -539,25 +591,56 @@ func (i *instance) querySample(ctx context.Context, req logql.SelectSampleParams
 	if err != nil {
 		return nil, err
 	}
+	// The queries matching many streams read the samples of the query snapshot
+	// from disk, and only the samples pushed since it was cut from memory.
+	snapshot, snapshotStreams, err := i.matchingSnapshotStreams(ctx, req.Start, selector.Matchers(), shard)
+	if err != nil {
+		return nil, err
+	}
+	openChunks := newOpenSnapshotChunks(i.cfg.QuerySnapshots.MaxOpenChunks)
+
 	err = i.forMatchingStreams(
 		ctx,
 		req.Start,
 		selector.Matchers(),
 		shard,
 		func(stream *stream) error {
-			iter, err := stream.SampleIterator(ctx, stats, req.Start, req.End, extractor.ForStream(stream.labels))
+			from := req.Start
+			ss := snapshotStreamFor(snapshotStreams, stream)
+			if ss != nil {
+				from = snapshot.liveFrom(stream, from)
+				if !from.Before(req.End) {
+					iters = append(iters, snapshot.SampleIterator(ctx, stats, openChunks, ss, req.Start, req.End, extractor.ForStream(ss.labels)))
+					return nil
+				}
+			}
+			it, err := stream.SampleIterator(ctx, stats, from, req.End, extractor.ForStream(stream.labels))
 			if err != nil {
 				return err
 			}
-			iters = append(iters, iter)
+			if ss != nil {
+				// the samples of both ranges are deduplicated.
+				it = iter.NewMergeSampleIterator(ctx, []iter.SampleIterator{snapshot.SampleIterator(ctx, stats, openChunks, ss, req.Start, req.End, extractor.ForStream(ss.labels)), it})
+			}
+			iters = append(iters, it)
 			return nil
 		},
 	)
 	if err != nil {
+		if snapshot != nil {
+			snapshot.release()
+		}
 		return nil, err
 	}
+	if snapshot == nil {
+		return iter.NewSortSampleIterator(iters), nil
+	}
 
-	return iter.NewSortSampleIterator(iters), nil
+	// the streams flushed since the snapshot was cut.
+	for _, ss := range snapshotStreams {
+		iters = append(iters, snapshot.SampleIterator(ctx, stats, openChunks, ss, req.Start, req.End, extractor.ForStream(ss.labels)))
+	}
+	return &snapshotReleasingIterator[logproto.Sample]{StreamIterator: iter.NewSortSampleIterator(iters), snapshot: snapshot}, nil
 }
 
 // Label returns the label names or values depending on the given request
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/grafana/dskit/ring"
+	"github.com/prometheus/prometheus/model/labels"
 	"golang.org/x/time/rate"
 
+	"github.com/grafana/loki/v3/pkg/distributor/idempotency"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
@@ -27,12 +29,14 @@ type RingCount interface {
 
 type Limits interface {
 	UnorderedWrites(userID string) bool
+	OutOfOrderWindows(userID string) []validation.OutOfOrderWindow
 	UseOwnedStreamCount(userID string) bool
 	MaxLocalStreamsPerUser(userID string) int
 	MaxGlobalStreamsPerUser(userID string) int
 	PerStreamRateLimit(userID string) validation.RateLimit
 	ShardStreams(userID string) shardstreams.Config
 	IngestionPartitionsTenantShardSize(userID string) int
+	PushIdempotency(userID string) idempotency.Config
 }
 
 // Limiter implements primitives to get the maximum number of streams
@@ -87,6 +91,29 @@ func (l *Limiter) UnorderedWrites(userID string) bool {
 	return l.limits.UnorderedWrites(userID)
 }
 
+// OutOfOrderWindow returns the out-of-order window of the stream with the given
+// labels, if any matches it. In case multiple windows are matching, the highest
+// priority one is picked, and the smallest one on equal priorities.
+func (l *Limiter) OutOfOrderWindow(userID string, lbs labels.Labels) (validation.OutOfOrderWindow, bool) {
+	var (
+		matched validation.OutOfOrderWindow
+		found   bool
+	)
+Outer:
+	for _, w := range l.limits.OutOfOrderWindows(userID) {
+		for _, m := range w.Matchers {
+			if !m.Matches(lbs.Get(m.Name)) {
+				continue Outer
+			}
+		}
+		if found && (matched.Priority > w.Priority || matched.Priority == w.Priority && matched.Window <= w.Window) {
+			continue
+		}
+		matched, found = w, true
+	}
+	return matched, found
+}
+
 func (l *Limiter) GetStreamCountLimit(tenantID string) (calculatedLimit, localLimit, globalLimit, adjustedGlobalLimit int) {
 	// Start by setting the local limit either from override or default
 	localLimit = l.limits.MaxLocalStreamsPerUser(tenantID)
@@ -7,11 +7,13 @@ import (
 	"time"
 
 	"github.com/grafana/dskit/ring"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
 	"golang.org/x/time/rate"
 
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/validation"
 )
 
@@ -145,6 +147,39 @@ func TestStreamCountLimiter_AssertNewStreamAllowed(t *testing.T) {
 	}
 }
 
+func TestLimiter_OutOfOrderWindow(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.OutOfOrderWindows = []validation.OutOfOrderWindow{
+		{Selector: `{source="iot"}`, Window: model.Duration(6 * time.Hour)},
+		{Selector: `{source="iot", device="meter"}`, Window: model.Duration(12 * time.Hour), Priority: 1},
+		{Selector: `{namespace="apps"}`, Window: model.Duration(5 * time.Minute)},
+		{Selector: `{namespace=~"apps|jobs"}`, Window: model.Duration(10 * time.Minute)},
+	}
+	require.NoError(t, limits.Validate())
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(overrides, NilMetrics, &fixedStrategy{}, nil)
+
+	for _, tc := range []struct {
+		labels   string
+		expected time.Duration
+	}{
+		{labels: `{source="iot"}`, expected: 6 * time.Hour},
+		{labels: `{source="iot", device="meter"}`, expected: 12 * time.Hour},
+		{labels: `{namespace="apps"}`, expected: 5 * time.Minute},
+		{labels: `{namespace="jobs"}`, expected: 10 * time.Minute},
+		{labels: `{namespace="other"}`},
+	} {
+		t.Run(tc.labels, func(t *testing.T) {
+			lbs, err := syntax.ParseLabels(tc.labels)
+			require.NoError(t, err)
+			w, ok := limiter.OutOfOrderWindow("fake", lbs)
+			require.Equal(t, tc.expected != 0, ok)
+			require.Equal(t, tc.expected, time.Duration(w.Window))
+		})
+	}
+}
+
 func TestLimiter_minNonZero(t *testing.T) {
 	t.Parallel()
 
@@ -70,6 +70,16 @@ type ingesterMetrics struct {
 	flushQueueLength       prometheus.Gauge
 	duplicateLogBytesTotal *prometheus.CounterVec
 	streamsOwnershipCheck  prometheus.Histogram
+
+	duplicatePushRequestsTotal *prometheus.CounterVec
+
+	querySnapshotCreationFail  prometheus.Counter
+	querySnapshotCreationTotal prometheus.Counter
+	querySnapshotDuration      prometheus.Summary
+	querySnapshotQueriesTotal  prometheus.Counter
+
+	backfilledEntriesTotal *prometheus.CounterVec
+	backfilledChunksTotal  *prometheus.CounterVec
 }
 
 // setRecoveryBytesInUse bounds the bytes reports to >= 0.
@@ -328,5 +338,50 @@ func newIngesterMetrics(r prometheus.Registerer, metricsNamespace string) *inges
 			Name:      "duplicate_log_bytes_total",
 			Help:      "The total number of bytes that were discarded for duplicate log lines.",
 		}, []string{"tenant"}),
+
+		duplicatePushRequestsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "ingester",
+			Name:      "duplicate_push_requests_total",
+			Help:      "The total number of push requests acknowledged without being appended because a push request with the same idempotency key succeeded.",
+		}, []string{"tenant"}),
+
+		querySnapshotCreationFail: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "ingester",
+			Name:      "query_snapshot_creations_failed_total",
+			Help:      "Total number of query snapshot creations that failed.",
+		}),
+		querySnapshotCreationTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "ingester",
+			Name:      "query_snapshot_creations_total",
+			Help:      "Total number of query snapshots created.",
+		}),
+		querySnapshotDuration: promauto.With(r).NewSummary(prometheus.SummaryOpts{
+			Namespace:  metricsNamespace,
+			Subsystem:  "ingester",
+			Name:       "query_snapshot_duration_seconds",
+			Help:       "Time taken to create a query snapshot.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		}),
+		querySnapshotQueriesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "ingester",
+			Name:      "query_snapshot_queries_total",
+			Help:      "Total number of queries served from the query snapshots.",
+		}),
+		backfilledEntriesTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "ingester",
+			Name:      "backfilled_entries_total",
+			Help:      "Total number of entries too far behind the head chunk of their stream appended to its backfill chunks.",
+		}, []string{"tenant"}),
+		backfilledChunksTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "ingester",
+			Name:      "backfilled_chunks_total",
+			Help:      "Total number of backfill chunks flushed to the store.",
+		}, []string{"tenant"}),
 	}
 }
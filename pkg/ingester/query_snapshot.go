@@ -0,0 +1,702 @@
+package ingester
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/fileutil"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/ingester/index"
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/util"
+)
+
+// QuerySnapshotConfig configures the query snapshots: on-disk snapshots of the
+// streams of the tenants, periodically cut by the ingesters, which serve the
+// queries matching many streams without holding the locks of the streams.
+type QuerySnapshotConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Dir           string        `yaml:"dir"`
+	Interval      time.Duration `yaml:"interval"`
+	MinStreams    int           `yaml:"min_streams"`
+	MaxOpenChunks int           `yaml:"max_open_chunks"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *QuerySnapshotConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "ingester.query-snapshots.enabled", false, "Serve the queries matching many streams from on-disk snapshots of the streams, so that they don't stall the pushes. The entries pushed since the last snapshot are still read from memory.")
+	f.StringVar(&cfg.Dir, "ingester.query-snapshots.dir", "query-snapshots", "Directory where the query snapshots are stored. Its content is deleted when the ingester starts.")
+	f.DurationVar(&cfg.Interval, "ingester.query-snapshots.interval", time.Minute, "Interval at which the query snapshots are cut.")
+	f.IntVar(&cfg.MinStreams, "ingester.query-snapshots.min-streams", 1000, "Minimum number of streams of the snapshot a query must match to be served from it. 0 serves all the queries from the snapshots.")
+	f.IntVar(&cfg.MaxOpenChunks, "ingester.query-snapshots.max-open-chunks", 100, "Maximum number of chunks of the snapshot a query reads from disk at once. The chunks closed to stay under the limit are read again when iterated over. 0 means no limit.")
+}
+
+func (cfg *QuerySnapshotConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Dir == "" {
+		return errors.New("the query snapshots directory must be set")
+	}
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("invalid query snapshots interval: %v", cfg.Interval)
+	}
+	if cfg.MinStreams < 0 {
+		return fmt.Errorf("invalid query snapshots min streams: %d", cfg.MinStreams)
+	}
+	if cfg.MaxOpenChunks < 0 {
+		return fmt.Errorf("invalid query snapshots max open chunks: %d", cfg.MaxOpenChunks)
+	}
+	return nil
+}
+
+const (
+	querySnapshotPrefix = "snapshot."
+	// querySnapshotChunksFile is the file of the query snapshot holding the
+	// records of the chunks of its streams.
+	querySnapshotChunksFile = "chunks"
+	// querySnapshotChecksumSize is the size of the checksum heading each
+	// record of the chunks file.
+	querySnapshotChecksumSize = 4
+)
+
+var querySnapshotCastagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// querySnapshot is a snapshot of the streams of a tenant, written with one
+// record per chunk. The streams and the offset of the records of their chunks
+// are indexed in memory, so that the chunks are only read from disk when they
+// are iterated over.
+type querySnapshot struct {
+	dir   string
+	cutAt time.Time
+
+	index   *index.Multi
+	streams map[model.Fingerprint]*snapshotStream
+	chunks  *os.File
+
+	blockSize, targetSize int
+
+	// refs counts the instance and the queries using the snapshot, which is
+	// deleted once it drops to 0.
+	refs atomic.Int64
+}
+
+type snapshotStream struct {
+	labels  labels.Labels
+	fp      model.Fingerprint
+	headFmt chunkenc.HeadBlockFmt
+	chunks  []snapshotChunk
+}
+
+type snapshotChunk struct {
+	from, through time.Time
+	offset        int64
+	size          int
+}
+
+// appendWatermark tracks the oldest entry appended to a stream since it was
+// written to the query snapshot cut at cutAt.
+type appendWatermark struct {
+	cutAt  int64
+	oldest atomic.Int64
+}
+
+func newAppendWatermark(cutAt time.Time) *appendWatermark {
+	w := &appendWatermark{cutAt: cutAt.UnixNano()}
+	w.oldest.Store(math.MaxInt64)
+	return w
+}
+
+// appended records an entry appended to the stream, the appends of a stream
+// are serialized by its chunkMtx.
+func (w *appendWatermark) appended(ts time.Time) {
+	if ts.UnixNano() < w.oldest.Load() {
+		w.oldest.Store(ts.UnixNano())
+	}
+}
+
+// cutQuerySnapshot cuts a new query snapshot of the streams of the instance
+// under the directory, and replaces the previous one with it.
+func (i *instance) cutQuerySnapshot(dir string) error {
+	cutAt := time.Now()
+	final := filepath.Join(dir, i.instanceID, fmt.Sprintf(querySnapshotPrefix+"%d", cutAt.UnixNano()))
+	tmp := final + ".tmp"
+	if err := os.MkdirAll(tmp, 0777); err != nil {
+		return fmt.Errorf("create query snapshot dir: %w", err)
+	}
+	snapshot, err := i.writeQuerySnapshot(tmp, cutAt)
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		return err
+	}
+	if err := fileutil.Replace(tmp, final); err != nil {
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("rename query snapshot directory: %w", err)
+	}
+	snapshot.dir = final
+	if snapshot.chunks, err = os.Open(filepath.Join(final, querySnapshotChunksFile)); err != nil {
+		_ = os.RemoveAll(final)
+		return fmt.Errorf("open query snapshot: %w", err)
+	}
+	snapshot.refs.Store(1)
+
+	i.querySnapshotMtx.Lock()
+	previous := i.querySnapshot
+	i.querySnapshot = snapshot
+	i.querySnapshotMtx.Unlock()
+	if previous != nil {
+		previous.release()
+	}
+	return nil
+}
+
+// releaseQuerySnapshot releases the current query snapshot of the instance,
+// which is deleted once the queries using it are done.
+func (i *instance) releaseQuerySnapshot() {
+	i.querySnapshotMtx.Lock()
+	previous := i.querySnapshot
+	i.querySnapshot = nil
+	i.querySnapshotMtx.Unlock()
+	if previous != nil {
+		previous.release()
+	}
+}
+
+// writeQuerySnapshot writes the chunks of the streams of the instance to the
+// chunks file of the directory, and indexes the streams and the offset of the
+// records of their chunks. The append watermarks of the streams are reset, so
+// that the queries only read from memory the entries appended since.
+func (i *instance) writeQuerySnapshot(dir string, cutAt time.Time) (*querySnapshot, error) {
+	invertedIndex, err := index.NewMultiInvertedIndex(i.schemaconfig.Configs, uint32(i.cfg.IndexShards))
+	if err != nil {
+		return nil, err
+	}
+	s := &querySnapshot{
+		dir:        dir,
+		cutAt:      cutAt,
+		index:      invertedIndex,
+		streams:    map[model.Fingerprint]*snapshotStream{},
+		blockSize:  i.cfg.BlockSize,
+		targetSize: i.cfg.TargetChunkSize,
+	}
+
+	f, err := os.Create(filepath.Join(dir, querySnapshotChunksFile))
+	if err != nil {
+		return nil, fmt.Errorf("create query snapshot: %w", err)
+	}
+	streams := make([]*stream, 0, i.streams.Len())
+	_ = i.forAllStreams(context.Background(), func(s *stream) error {
+		streams = append(streams, s)
+		return nil
+	})
+
+	var (
+		w      = bufio.NewWriter(f)
+		offset int64
+		buffer []chunkWithBuffer
+		rec    []byte
+	)
+	// release the buffers of the chunks.
+	defer func() { _, _ = toWireChunks(nil, buffer) }()
+	for _, stream := range streams {
+		stream.chunkMtx.RLock()
+		chunks, err := toWireChunks(stream.chunks, buffer)
+		if err == nil {
+			stream.snapshotWatermark.Store(newAppendWatermark(cutAt))
+		}
+		stream.chunkMtx.RUnlock()
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		buffer = chunks
+		if len(chunks) == 0 {
+			// the stream has been flushed in between.
+			continue
+		}
+
+		_, headFmt, err := i.chunkFormatAt(model.TimeFromUnixNano(chunks[0].From.UnixNano()))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		ss := &snapshotStream{
+			labels:  invertedIndex.Add(logproto.FromLabelsToLabelAdapters(stream.labels), stream.fp),
+			fp:      stream.fp,
+			headFmt: headFmt,
+		}
+		for _, c := range chunks {
+			size := querySnapshotChecksumSize + c.Chunk.Size()
+			if cap(rec) < size {
+				rec = make([]byte, size)
+			}
+			rec = rec[:size]
+			if _, err := c.Chunk.MarshalToSizedBuffer(rec[querySnapshotChecksumSize:]); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+			binary.BigEndian.PutUint32(rec, crc32.Checksum(rec[querySnapshotChecksumSize:], querySnapshotCastagnoliTable))
+			if _, err := w.Write(rec); err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("write query snapshot: %w", err)
+			}
+			ss.chunks = append(ss.chunks, snapshotChunk{from: c.From, through: c.To, offset: offset, size: size})
+			offset += int64(size)
+		}
+		s.streams[stream.fp] = ss
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("write query snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("close query snapshot: %w", err)
+	}
+	return s, nil
+}
+
+// acquireQuerySnapshot returns the current query snapshot of the instance, if
+// any, which must be released once the query is done.
+func (i *instance) acquireQuerySnapshot() *querySnapshot {
+	i.querySnapshotMtx.RLock()
+	defer i.querySnapshotMtx.RUnlock()
+	if i.querySnapshot == nil {
+		return nil
+	}
+	i.querySnapshot.refs.Inc()
+	return i.querySnapshot
+}
+
+func (s *querySnapshot) release() {
+	if s.refs.Dec() == 0 {
+		s.close()
+	}
+}
+
+func (s *querySnapshot) close() {
+	_ = s.chunks.Close()
+	_ = os.RemoveAll(s.dir)
+}
+
+// matchingSnapshotStreams returns the query snapshot and its streams matching
+// a query starting at ts, when the query matches enough streams to be served
+// from the snapshot. The snapshot must be released once the query is done.
+func (i *instance) matchingSnapshotStreams(
+	ctx context.Context,
+	ts time.Time,
+	matchers []*labels.Matcher,
+	shard *logql.Shard,
+) (*querySnapshot, map[model.Fingerprint]*snapshotStream, error) {
+	snapshot := i.acquireQuerySnapshot()
+	if snapshot == nil {
+		return nil, nil, nil
+	}
+	// the entries of the query are all pushed after the snapshot was cut.
+	if !ts.Before(snapshot.cutAt) {
+		snapshot.release()
+		return nil, nil, nil
+	}
+
+	filters, matchers := util.SplitFiltersAndMatchers(matchers)
+	ids, err := snapshot.index.Lookup(ts, matchers, shard)
+	if err != nil {
+		snapshot.release()
+		return nil, nil, err
+	}
+	var chunkFilter chunk.Filterer
+	if i.chunkFilter != nil {
+		chunkFilter = i.chunkFilter.ForRequest(ctx)
+	}
+	streams := make(map[model.Fingerprint]*snapshotStream, len(ids))
+outer:
+	for _, id := range ids {
+		stream, ok := snapshot.streams[id]
+		if !ok {
+			continue
+		}
+		for _, filter := range filters {
+			if !filter.Matches(stream.labels.Get(filter.Name)) {
+				continue outer
+			}
+		}
+		if chunkFilter != nil && chunkFilter.ShouldFilter(stream.labels) {
+			continue
+		}
+		streams[id] = stream
+	}
+	if len(streams) == 0 || len(streams) < i.cfg.QuerySnapshots.MinStreams {
+		snapshot.release()
+		return nil, nil, nil
+	}
+	i.metrics.querySnapshotQueriesTotal.Inc()
+	return snapshot, streams, nil
+}
+
+// snapshotStreamFor returns the stream of the snapshot which is the given live
+// stream, removing it from the streams.
+func snapshotStreamFor(streams map[model.Fingerprint]*snapshotStream, s *stream) *snapshotStream {
+	ss, ok := streams[s.fp]
+	if !ok || !labels.Equal(ss.labels, s.labels) {
+		return nil
+	}
+	delete(streams, s.fp)
+	return ss
+}
+
+// liveFrom returns the start of the range of the stream which is read from
+// memory, when the snapshot has the stream: the entries appended since the
+// stream was written to the snapshot are not older than its append watermark.
+func (s *querySnapshot) liveFrom(st *stream, from time.Time) time.Time {
+	w := st.snapshotWatermark.Load()
+	if w == nil || w.cutAt != s.cutAt.UnixNano() {
+		// the stream has been written to a newer snapshot since.
+		return from
+	}
+	if oldest := w.oldest.Load(); oldest > from.UnixNano() {
+		return time.Unix(0, oldest)
+	}
+	return from
+}
+
+// Iterator returns an iterator over the entries of the snapshot stream, which
+// reads the chunks from disk when they are iterated over.
+func (s *querySnapshot) Iterator(ctx context.Context, statsCtx *stats.Context, open *openSnapshotChunks, ss *snapshotStream, from, through time.Time, direction logproto.Direction, pipeline log.StreamPipeline) iter.EntryIterator {
+	iterators := make([]iter.EntryIterator, 0, len(ss.chunks))
+
+	var lastMax time.Time
+	ordered := true
+
+	for _, c := range ss.chunks {
+		// skip this chunk
+		if through.Before(c.from) || c.through.Before(from) {
+			continue
+		}
+
+		if c.from.Before(lastMax) {
+			ordered = false
+		}
+		lastMax = c.through
+
+		c := c
+		iterators = append(iterators, &lazySnapshotIterator[logproto.Entry]{
+			open:      open,
+			from:      from,
+			through:   through,
+			direction: direction,
+			timestamp: func(e logproto.Entry) int64 { return e.Timestamp.UnixNano() },
+			load: func(from, through time.Time) (iter.StreamIterator[logproto.Entry], error) {
+				mc, err := s.loadChunk(ss, c)
+				if err != nil {
+					return nil, err
+				}
+				return mc.Iterator(ctx, from, through, direction, pipeline)
+			},
+		})
+	}
+
+	if direction != logproto.FORWARD {
+		for left, right := 0, len(iterators)-1; left < right; left, right = left+1, right-1 {
+			iterators[left], iterators[right] = iterators[right], iterators[left]
+		}
+	}
+
+	if statsCtx != nil {
+		statsCtx.AddIngesterTotalChunkMatched(int64(len(iterators)))
+	}
+
+	if ordered {
+		return iter.NewNonOverlappingIterator(iterators)
+	}
+	return iter.NewSortEntryIterator(iterators, direction)
+}
+
+// SampleIterator returns an iterator over the samples of the snapshot stream,
+// which reads the chunks from disk when they are iterated over.
+func (s *querySnapshot) SampleIterator(ctx context.Context, statsCtx *stats.Context, open *openSnapshotChunks, ss *snapshotStream, from, through time.Time, extractor log.StreamSampleExtractor) iter.SampleIterator {
+	iterators := make([]iter.SampleIterator, 0, len(ss.chunks))
+
+	var lastMax time.Time
+	ordered := true
+
+	for _, c := range ss.chunks {
+		// skip this chunk
+		if through.Before(c.from) || c.through.Before(from) {
+			continue
+		}
+
+		if c.from.Before(lastMax) {
+			ordered = false
+		}
+		lastMax = c.through
+
+		c := c
+		iterators = append(iterators, &lazySnapshotIterator[logproto.Sample]{
+			open:      open,
+			from:      from,
+			through:   through,
+			direction: logproto.FORWARD,
+			timestamp: func(s logproto.Sample) int64 { return s.Timestamp },
+			load: func(from, through time.Time) (iter.StreamIterator[logproto.Sample], error) {
+				mc, err := s.loadChunk(ss, c)
+				if err != nil {
+					return nil, err
+				}
+				return mc.SampleIterator(ctx, from, through, extractor), nil
+			},
+		})
+	}
+
+	if statsCtx != nil {
+		statsCtx.AddIngesterTotalChunkMatched(int64(len(iterators)))
+	}
+
+	if ordered {
+		return iter.NewNonOverlappingSampleIterator(iterators)
+	}
+	return iter.NewSortSampleIterator(iterators)
+}
+
+func (s *querySnapshot) loadChunk(ss *snapshotStream, c snapshotChunk) (*chunkenc.MemChunk, error) {
+	rec := make([]byte, c.size)
+	if _, err := s.chunks.ReadAt(rec, c.offset); err != nil {
+		return nil, fmt.Errorf("read query snapshot chunk: %w", err)
+	}
+	expected := binary.BigEndian.Uint32(rec)
+	rec = rec[querySnapshotChecksumSize:]
+	if sum := crc32.Checksum(rec, querySnapshotCastagnoliTable); sum != expected {
+		return nil, fmt.Errorf("unexpected query snapshot chunk checksum %x, expected %x", sum, expected)
+	}
+	var chunk Chunk
+	if err := chunk.Unmarshal(rec); err != nil {
+		return nil, err
+	}
+	return chunkenc.MemchunkFromCheckpoint(chunk.Data, chunk.Head, ss.headFmt, s.blockSize, s.targetSize)
+}
+
+// openSnapshotChunks bounds the number of chunks of the query snapshot a query
+// reads at once: when a chunk is read over the limit, the least recently
+// iterated one is closed and read again once iterated over.
+type openSnapshotChunks struct {
+	max int
+	lru *list.List
+}
+
+// newOpenSnapshotChunks returns the open chunks of a query, with at most max
+// open chunks. 0 means no limit.
+func newOpenSnapshotChunks(max int) *openSnapshotChunks {
+	return &openSnapshotChunks{max: max, lru: list.New()}
+}
+
+// snapshotChunkIterator is an iterator over a chunk of the query snapshot
+// which can be closed before it is iterated over entirely.
+type snapshotChunkIterator interface {
+	closeChunk()
+}
+
+// add adds the chunk of the iterator, closing the least recently iterated
+// chunks over the limit.
+func (o *openSnapshotChunks) add(it snapshotChunkIterator) *list.Element {
+	e := o.lru.PushFront(it)
+	for o.max > 0 && o.lru.Len() > o.max {
+		back := o.lru.Back()
+		o.lru.Remove(back)
+		back.Value.(snapshotChunkIterator).closeChunk()
+	}
+	return e
+}
+
+// lazySnapshotIterator reads its chunk from the query snapshot when it is first
+// iterated over. When its chunk is closed to stay under the limit of open
+// chunks, the chunk is read again from the timestamp of the last entry, and
+// the entries already returned at that timestamp are skipped.
+type lazySnapshotIterator[T logproto.Entry | logproto.Sample] struct {
+	open          *openSnapshotChunks
+	elem          *list.Element
+	from, through time.Time
+	direction     logproto.Direction
+	timestamp     func(T) int64
+	load          func(from, through time.Time) (iter.StreamIterator[T], error)
+
+	it     iter.StreamIterator[T]
+	cur    T
+	labels string
+	hash   uint64
+	// returned counts the entries returned at the timestamp of cur.
+	returned int
+	err      error
+	done     bool
+}
+
+func (l *lazySnapshotIterator[T]) Next() bool {
+	if l.done || l.err != nil {
+		return false
+	}
+	if l.it == nil && !l.loadChunk() {
+		return false
+	}
+	l.open.lru.MoveToFront(l.elem)
+
+	if !l.it.Next() {
+		l.err = l.it.Err()
+		if err := l.Close(); err != nil && l.err == nil {
+			l.err = err
+		}
+		return false
+	}
+	next := l.it.At()
+	if l.returned > 0 && l.timestamp(next) == l.timestamp(l.cur) {
+		l.returned++
+	} else {
+		l.returned = 1
+	}
+	l.cur, l.labels, l.hash = next, l.it.Labels(), l.it.StreamHash()
+	return true
+}
+
+// loadChunk reads the chunk, resuming after the entries already returned.
+func (l *lazySnapshotIterator[T]) loadChunk() bool {
+	from, through := l.from, l.through
+	if l.returned > 0 {
+		ts := time.Unix(0, l.timestamp(l.cur))
+		if l.direction == logproto.FORWARD {
+			from = ts
+		} else {
+			through = ts.Add(time.Nanosecond)
+		}
+	}
+	it, err := l.load(from, through)
+	if err != nil {
+		l.err = err
+		return false
+	}
+	l.it = it
+	l.elem = l.open.add(l)
+	// skip the entries already returned.
+	for skipped := 0; skipped < l.returned; skipped++ {
+		if !l.it.Next() {
+			break
+		}
+	}
+	return true
+}
+
+func (l *lazySnapshotIterator[T]) closeChunk() {
+	if err := l.it.Close(); err != nil && l.err == nil {
+		l.err = err
+	}
+	l.it, l.elem = nil, nil
+}
+
+func (l *lazySnapshotIterator[T]) At() T {
+	return l.cur
+}
+
+func (l *lazySnapshotIterator[T]) Labels() string {
+	return l.labels
+}
+
+func (l *lazySnapshotIterator[T]) StreamHash() uint64 {
+	return l.hash
+}
+
+func (l *lazySnapshotIterator[T]) Err() error {
+	if l.err != nil {
+		return l.err
+	}
+	if l.it == nil {
+		return nil
+	}
+	return l.it.Err()
+}
+
+func (l *lazySnapshotIterator[T]) Close() error {
+	l.done = true
+	if l.it == nil {
+		return nil
+	}
+	l.open.lru.Remove(l.elem)
+	err := l.it.Close()
+	// drop the chunk once iterated over.
+	l.it, l.elem, l.load = nil, nil, nil
+	return err
+}
+
+// snapshotReleasingIterator releases the query snapshot when closed.
+type snapshotReleasingIterator[T logproto.Entry | logproto.Sample] struct {
+	iter.StreamIterator[T]
+	snapshot *querySnapshot
+	once     sync.Once
+}
+
+func (s *snapshotReleasingIterator[T]) Close() error {
+	err := s.StreamIterator.Close()
+	s.once.Do(s.snapshot.release)
+	return err
+}
+
+// querySnapshotsLoop periodically cuts the query snapshots of the instances.
+func (i *Ingester) querySnapshotsLoop() {
+	defer i.loopDone.Done()
+
+	ticker := time.NewTicker(i.cfg.QuerySnapshots.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, instance := range i.getInstances() {
+				start := time.Now()
+				if err := instance.cutQuerySnapshot(i.cfg.QuerySnapshots.Dir); err != nil {
+					i.metrics.querySnapshotCreationFail.Inc()
+					level.Error(i.logger).Log("msg", "failed to cut query snapshot", "tenant", instance.instanceID, "err", err)
+					continue
+				}
+				i.metrics.querySnapshotCreationTotal.Inc()
+				i.metrics.querySnapshotDuration.Observe(time.Since(start).Seconds())
+			}
+		case <-i.loopQuit:
+			for _, instance := range i.getInstances() {
+				instance.releaseQuerySnapshot()
+			}
+			return
+		}
+	}
+}
+
+// removeQuerySnapshots removes the query snapshots left by a previous run.
+func removeQuerySnapshots(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
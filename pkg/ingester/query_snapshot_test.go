@@ -0,0 +1,261 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	loki_runtime "github.com/grafana/loki/v3/pkg/runtime"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+type queriedEntry struct {
+	labels string
+	ts     time.Time
+	line   string
+}
+
+func queryEntries(t *testing.T, inst *instance, selector string, direction logproto.Direction) []queriedEntry {
+	t.Helper()
+	it, err := inst.Query(context.Background(), logql.SelectLogParams{
+		QueryRequest: &logproto.QueryRequest{
+			Selector:  selector,
+			Limit:     1000,
+			Start:     time.Unix(0, 0),
+			End:       time.Unix(200, 0),
+			Direction: direction,
+			Plan:      &plan.QueryPlan{AST: syntax.MustParseExpr(selector)},
+		},
+	})
+	require.NoError(t, err)
+	defer it.Close()
+
+	var res []queriedEntry
+	for it.Next() {
+		res = append(res, queriedEntry{labels: it.Labels(), ts: it.At().Timestamp, line: it.At().Line})
+	}
+	require.NoError(t, it.Err())
+	return res
+}
+
+func querySamples(t *testing.T, inst *instance, selector string) int {
+	t.Helper()
+	it, err := inst.QuerySample(context.Background(), logql.SelectSampleParams{
+		SampleQueryRequest: &logproto.SampleQueryRequest{
+			Selector: selector,
+			Start:    time.Unix(0, 0),
+			End:      time.Unix(200, 0),
+			Plan:     &plan.QueryPlan{AST: syntax.MustParseExpr(selector)},
+		},
+	})
+	require.NoError(t, err)
+	defer it.Close()
+
+	var n int
+	for it.Next() {
+		n++
+	}
+	require.NoError(t, it.Err())
+	return n
+}
+
+func TestQuerySnapshot(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.MaxChunkAge = 2 * time.Minute
+	cfg.QuerySnapshots = QuerySnapshotConfig{Enabled: true, Dir: t.TempDir(), Interval: time.Minute}
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, newIngesterRingLimiterStrategy(&ringCountMock{count: 1}, 1), &TenantBasedStrategy{limits: limits})
+	inst, err := newInstance(cfg, defaultPeriodConfigs, "test", limiter, loki_runtime.DefaultTenantConfigs(), noopWAL{}, NilMetrics, &OnceSwitch{}, nil, nil, nil, NewStreamRateCalculator(), nil, nil)
+	require.NoError(t, err)
+
+	push := func(labels string, from, through int, line func(int) string) {
+		var entries []logproto.Entry
+		for i := from; i < through; i++ {
+			entries = append(entries, logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: line(i)})
+		}
+		require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{{Labels: labels, Entries: entries}}}))
+	}
+	push(`{app="a"}`, 0, 100, func(i int) string { return fmt.Sprintf("a-%d", i) })
+	push(`{app="b"}`, 0, 100, func(i int) string { return fmt.Sprintf("b-%d", i) })
+	bigLine := strings.Repeat("c", 100<<10)
+	push(`{app="c"}`, 50, 51, func(int) string { return bigLine })
+	// the entries at the same timestamp are returned once when the chunk is read again.
+	push(`{app="d"}`, 0, 3, func(i int) string { return fmt.Sprintf("d-%d", i) })
+	require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{{Labels: `{app="d"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(2, 0), Line: "d-2bis"}}}}}))
+
+	var expected []queriedEntry
+	for i := 0; i < 120; i++ {
+		expected = append(expected, queriedEntry{`{app="a"}`, time.Unix(int64(i), 0), fmt.Sprintf("a-%d", i)})
+		if i < 100 {
+			expected = append(expected, queriedEntry{`{app="b"}`, time.Unix(int64(i), 0), fmt.Sprintf("b-%d", i)})
+		}
+		if i < 3 {
+			expected = append(expected, queriedEntry{`{app="d"}`, time.Unix(int64(i), 0), fmt.Sprintf("d-%d", i)})
+		}
+		if i == 2 {
+			expected = append(expected, queriedEntry{`{app="d"}`, time.Unix(int64(i), 0), "d-2bis"})
+		}
+		if i == 50 {
+			expected = append(expected, queriedEntry{`{app="c"}`, time.Unix(int64(i), 0), bigLine})
+		}
+		if i == 95 {
+			expected = append(expected, queriedEntry{`{app="a"}`, time.Unix(95, 5e8), "a-95.5"})
+		}
+	}
+
+	require.NoError(t, inst.cutQuerySnapshot(cfg.QuerySnapshots.Dir))
+	first := inst.querySnapshot.dir
+	a, ok := inst.streams.Load(`{app="a"}`)
+	require.True(t, ok)
+	// nothing is read from memory until entries are pushed.
+	require.Equal(t, time.Unix(0, math.MaxInt64), inst.querySnapshot.liveFrom(a, time.Unix(0, 0)))
+
+	// pushed since the snapshot was cut, including an out-of-order entry.
+	push(`{app="a"}`, 100, 120, func(i int) string { return fmt.Sprintf("a-%d", i) })
+	require.NoError(t, inst.Push(context.Background(), &logproto.PushRequest{Streams: []logproto.Stream{{Labels: `{app="a"}`, Entries: []logproto.Entry{{Timestamp: time.Unix(95, 5e8), Line: "a-95.5"}}}}}))
+	// flushed since the snapshot was cut.
+	b, ok := inst.streams.Load(`{app="b"}`)
+	require.True(t, ok)
+	inst.removeStream(b)
+	// only the entries pushed since the snapshot was cut are read from memory.
+	require.Equal(t, time.Unix(95, 5e8), inst.querySnapshot.liveFrom(a, time.Unix(0, 0)))
+	require.Equal(t, time.Unix(96, 0), inst.querySnapshot.liveFrom(a, time.Unix(96, 0)))
+
+	// with a single open chunk, the chunks are read again when iterated over.
+	for _, maxOpenChunks := range []int{0, 1} {
+		inst.cfg.QuerySnapshots.MaxOpenChunks = maxOpenChunks
+
+		queries := testutil.ToFloat64(NilMetrics.querySnapshotQueriesTotal)
+		forward := queryEntries(t, inst, `{app=~"a|b|c|d"}`, logproto.FORWARD)
+		require.Equal(t, queries+1, testutil.ToFloat64(NilMetrics.querySnapshotQueriesTotal))
+		require.Equal(t, len(expected), len(forward))
+		for i := range expected {
+			require.Equal(t, expected[i].ts, forward[i].ts)
+		}
+		require.ElementsMatch(t, expected, forward)
+
+		backward := queryEntries(t, inst, `{app=~"a|b|c|d"}`, logproto.BACKWARD)
+		require.Equal(t, len(expected), len(backward))
+		for i := range expected {
+			require.Equal(t, expected[len(expected)-1-i].ts, backward[i].ts)
+		}
+		require.ElementsMatch(t, expected, backward)
+
+		require.Equal(t, len(expected), querySamples(t, inst, `count_over_time({app=~"a|b|c|d"}[1m])`))
+	}
+
+	// the queries matching fewer streams are served from memory, without the
+	// flushed stream.
+	inst.cfg.QuerySnapshots.MinStreams = 5
+	queries := testutil.ToFloat64(NilMetrics.querySnapshotQueriesTotal)
+	require.Len(t, queryEntries(t, inst, `{app=~"a|b|c|d"}`, logproto.FORWARD), 126)
+	require.Equal(t, queries, testutil.ToFloat64(NilMetrics.querySnapshotQueriesTotal))
+	inst.cfg.QuerySnapshots.MinStreams = 0
+
+	// a snapshot is deleted once replaced and released by the queries.
+	it, err := inst.Query(context.Background(), logql.SelectLogParams{
+		QueryRequest: &logproto.QueryRequest{
+			Selector:  `{app="a"}`,
+			Limit:     1000,
+			Start:     time.Unix(0, 0),
+			End:       time.Unix(200, 0),
+			Direction: logproto.FORWARD,
+			Plan:      &plan.QueryPlan{AST: syntax.MustParseExpr(`{app="a"}`)},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, inst.cutQuerySnapshot(cfg.QuerySnapshots.Dir))
+	_, err = os.Stat(first)
+	require.NoError(t, err)
+	require.Equal(t, 121, countEntries(t, it))
+	_, err = os.Stat(first)
+	require.True(t, os.IsNotExist(err))
+
+	second := inst.querySnapshot.dir
+	inst.releaseQuerySnapshot()
+	_, err = os.Stat(second)
+	require.True(t, os.IsNotExist(err))
+}
+
+func countEntries(t *testing.T, it iter.EntryIterator) int {
+	t.Helper()
+	var n int
+	for it.Next() {
+		n++
+	}
+	require.NoError(t, it.Err())
+	require.NoError(t, it.Close())
+	return n
+}
+
+func TestLazySnapshotIterator(t *testing.T) {
+	entries := []logproto.Entry{
+		{Timestamp: time.Unix(1, 0), Line: "a"},
+		{Timestamp: time.Unix(2, 0), Line: "b"},
+		{Timestamp: time.Unix(2, 0), Line: "c"},
+		{Timestamp: time.Unix(2, 0), Line: "d"},
+		{Timestamp: time.Unix(3, 0), Line: "e"},
+	}
+	for _, direction := range []logproto.Direction{logproto.FORWARD, logproto.BACKWARD} {
+		t.Run(direction.String(), func(t *testing.T) {
+			var loads int
+			newIterator := func(open *openSnapshotChunks) *lazySnapshotIterator[logproto.Entry] {
+				return &lazySnapshotIterator[logproto.Entry]{
+					open:      open,
+					from:      time.Unix(0, 0),
+					through:   time.Unix(10, 0),
+					direction: direction,
+					timestamp: func(e logproto.Entry) int64 { return e.Timestamp.UnixNano() },
+					load: func(from, through time.Time) (iter.StreamIterator[logproto.Entry], error) {
+						loads++
+						var selected []logproto.Entry
+						for _, e := range entries {
+							if !e.Timestamp.Before(from) && e.Timestamp.Before(through) {
+								selected = append(selected, e)
+							}
+						}
+						if direction == logproto.BACKWARD {
+							slices.Reverse(selected)
+						}
+						return iter.NewStreamIterator(logproto.Stream{Labels: `{app="a"}`, Entries: selected}), nil
+					},
+				}
+			}
+
+			open := newOpenSnapshotChunks(1)
+			it := newIterator(open)
+			var lines []string
+			for it.Next() {
+				lines = append(lines, it.At().Line)
+				// the chunk is closed to open the chunk of another iterator.
+				other := newIterator(open)
+				require.True(t, other.Next())
+				require.NoError(t, other.Close())
+			}
+			require.NoError(t, it.Err())
+			require.NoError(t, it.Close())
+
+			expected := []string{"a", "b", "c", "d", "e"}
+			if direction == logproto.BACKWARD {
+				slices.Reverse(expected)
+			}
+			require.Equal(t, expected, lines)
+			require.Equal(t, 2*len(entries)+1, loads)
+			require.Zero(t, open.lru.Len())
+		})
+	}
+}
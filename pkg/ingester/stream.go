@@ -16,8 +16,10 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
+	"go.uber.org/atomic"
 
 	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compression"
 	"github.com/grafana/loki/v3/pkg/distributor/writefailures"
 	"github.com/grafana/loki/v3/pkg/ingester/wal"
 	"github.com/grafana/loki/v3/pkg/iter"
@@ -76,6 +78,19 @@ type stream struct {
 	unorderedWrites      bool
 	streamRateCalculator *StreamRateCalculator
 
+	// outOfOrderWindow is the out-of-order window matching the stream, if
+	// any, which replaces the default window of unordered writes.
+	outOfOrderWindow atomic.Pointer[validation.OutOfOrderWindow]
+	// backfill holds the entries of the push being validated accepted by the
+	// out-of-order window but older than the in-memory window of the stream,
+	// which are appended to its backfill chunk instead of its head chunk.
+	// Not thread-safe; assume accesses to this are locked by caller.
+	backfill []logproto.Entry
+
+	// snapshotWatermark tracks the oldest entry appended to the in-memory
+	// chunks since the stream was written to a query snapshot, if any.
+	snapshotWatermark atomic.Pointer[appendWatermark]
+
 	writeFailures *writefailures.Manager
 
 	chunkFormat          byte
@@ -92,6 +107,10 @@ type chunkDesc struct {
 	reason  string
 
 	lastUpdated time.Time
+
+	// backfillSince is when the chunk started to batch the backfilled entries
+	// of the stream, if it does.
+	backfillSince time.Time
 }
 
 type entryWithError struct {
@@ -169,7 +188,17 @@ func (s *stream) setChunks(chunks []Chunk) (bytesAdded, entriesAdded int, err er
 	return bytesAdded, entriesAdded, nil
 }
 
+// ZstdDictionaries provides the current zstd dictionary of the tenants.
+type ZstdDictionaries interface {
+	// Current returns the ID of the current dictionary of the tenant, 0 meaning
+	// no dictionary.
+	Current(tenant string) uint32
+}
+
 func (s *stream) NewChunk() *chunkenc.MemChunk {
+	if s.cfg.parsedEncoding == compression.ZstdDict && s.cfg.ZstdDictionaries != nil {
+		return chunkenc.NewZstdDictMemChunk(s.chunkFormat, s.cfg.ZstdDictionaries.Current(s.tenant), s.chunkHeadBlockFormat, s.cfg.BlockSize, s.cfg.TargetChunkSize)
+	}
 	return chunkenc.NewMemChunk(s.chunkFormat, s.cfg.parsedEncoding, s.chunkHeadBlockFormat, s.cfg.BlockSize, s.cfg.TargetChunkSize)
 }
 
@@ -211,6 +240,7 @@ func (s *stream) Push(
 
 	toStore, invalid := s.validateEntries(ctx, entries, isReplay, rateLimitWholeStream, usageTracker)
 	if rateLimitWholeStream && hasRateLimitErr(invalid) {
+		s.backfill = nil
 		return 0, errorForFailedEntries(s, invalid, len(entries))
 	}
 
@@ -224,7 +254,11 @@ func (s *stream) Push(
 	}
 
 	bytesAdded, storedEntries, entriesWithErr := s.storeEntries(ctx, toStore, usageTracker)
-	s.recordAndSendToTailers(record, storedEntries)
+	backfilledBytes, backfilledEntries, backfillErrs := s.storeBackfill(s.backfill)
+	s.backfill = nil
+	bytesAdded += backfilledBytes
+	entriesWithErr = append(entriesWithErr, backfillErrs...)
+	s.recordAndSendToTailers(record, append(storedEntries, backfilledEntries...))
 
 	if len(s.chunks) != prevNumChunks {
 		s.metrics.memoryChunks.Add(float64(len(s.chunks) - prevNumChunks))
@@ -333,6 +367,7 @@ func (s *stream) storeEntries(ctx context.Context, entries []logproto.Entry, usa
 
 	var invalid []entryWithError
 	storedEntries := make([]logproto.Entry, 0, len(entries))
+	watermark := s.snapshotWatermark.Load()
 	for i := 0; i < len(entries); i++ {
 		chunk := &s.chunks[len(s.chunks)-1]
 		if chunk.closed || !chunk.chunk.SpaceFor(&entries[i]) || s.cutChunkForSynchronization(entries[i].Timestamp, s.highestTs, chunk, s.cfg.SyncPeriod, s.cfg.SyncMinUtilization) {
@@ -353,6 +388,9 @@ func (s *stream) storeEntries(ctx context.Context, entries []logproto.Entry, usa
 		if dup {
 			s.handleLoggingOfDuplicateEntry(entries[i])
 		}
+		if watermark != nil {
+			watermark.appended(entries[i].Timestamp)
+		}
 
 		s.entryCt++
 		s.lastLine.ts = entries[i].Timestamp
@@ -383,6 +421,50 @@ func (s *stream) handleLoggingOfDuplicateEntry(entry logproto.Entry) {
 
 }
 
+// outOfOrderCutoff returns the oldest timestamp of the entries appended to the
+// in-memory chunks of the stream given its highest timestamp. The validity
+// window for unordered writes is the highest timestamp present minus
+// 1/2 * max-chunk-age, unless an out-of-order window matches the stream.
+func (s *stream) outOfOrderCutoff(highestTs time.Time) time.Time {
+	memory, _ := s.outOfOrderWindows()
+	return highestTs.Add(-memory)
+}
+
+// outOfOrderWindows returns how far behind the highest timestamp of the stream
+// entries are appended to its in-memory chunks, and how far behind they are
+// accepted at all. The entries in between are backfilled.
+func (s *stream) outOfOrderWindows() (memory, accepted time.Duration) {
+	memory = s.cfg.MaxChunkAge / 2
+	w := s.outOfOrderWindow.Load()
+	if w == nil {
+		return memory, memory
+	}
+	accepted = time.Duration(w.Window)
+	if !w.Backfill || accepted < memory {
+		memory = accepted
+	}
+	return memory, accepted
+}
+
+// setOutOfOrderWindow sets the out-of-order window matching the stream, or
+// resets it to the default window if ok is false.
+func (s *stream) setOutOfOrderWindow(w validation.OutOfOrderWindow, ok bool) {
+	if !ok {
+		s.outOfOrderWindow.Store(nil)
+		return
+	}
+	if cur := s.outOfOrderWindow.Load(); cur != nil && cur.Window == w.Window && cur.Backfill == w.Backfill {
+		return
+	}
+	s.outOfOrderWindow.Store(&w)
+}
+
+// acceptsOutOfOrder returns whether the too far behind entries are rejected,
+// or stored regardless of their timestamp.
+func (s *stream) acceptsOutOfOrder() bool {
+	return s.unorderedWrites || s.outOfOrderWindow.Load() != nil
+}
+
 func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry, isReplay, rateLimitWholeStream bool, usageTracker push.UsageTracker) ([]logproto.Entry, []entryWithError) {
 
 	var (
@@ -394,6 +476,8 @@ func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry,
 		lastLine                             = s.lastLine
 		highestTs                            = s.highestTs
 		toStore                              = make([]logproto.Entry, 0, len(entries))
+		acceptsOutOfOrder                    = s.acceptsOutOfOrder()
+		memoryWindow, acceptedWindow         = s.outOfOrderWindows()
 	)
 
 	for i := range entries {
@@ -421,10 +505,18 @@ func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry,
 			continue
 		}
 
-		// The validity window for unordered writes is the highest timestamp present minus 1/2 * max-chunk-age.
-		cutoff := highestTs.Add(-s.cfg.MaxChunkAge / 2)
-		if !isReplay && s.unorderedWrites && !highestTs.IsZero() && cutoff.After(entries[i].Timestamp) {
-			failedEntriesWithError = append(failedEntriesWithError, entryWithError{&entries[i], chunkenc.ErrTooFarBehind(entries[i].Timestamp, cutoff)})
+		cutoff := highestTs.Add(-memoryWindow)
+		if !isReplay && acceptsOutOfOrder && !highestTs.IsZero() && cutoff.After(entries[i].Timestamp) {
+			// the entries within the accepted window but too far behind for
+			// the in-memory chunks are backfilled.
+			acceptedCutoff := highestTs.Add(-acceptedWindow)
+			if !acceptedCutoff.After(entries[i].Timestamp) {
+				// the backfilled entries count against the rate limit of the stream.
+				validBytes += lineBytes
+				s.backfill = append(s.backfill, entries[i])
+				continue
+			}
+			failedEntriesWithError = append(failedEntriesWithError, entryWithError{&entries[i], chunkenc.ErrTooFarBehind(entries[i].Timestamp, acceptedCutoff)})
 			s.writeFailures.Log(s.tenant, fmt.Errorf("%w for stream %s", failedEntriesWithError[len(failedEntriesWithError)-1].e, s.labels))
 			outOfOrderSamples++
 			outOfOrderBytes += lineBytes
@@ -448,11 +540,12 @@ func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry,
 	now := time.Now()
 	if rateLimitWholeStream && !s.limiter.AllowN(now, validBytes) {
 		// Report that the whole stream was rate limited
-		rateLimitedSamples = len(toStore)
-		failedEntriesWithError = make([]entryWithError, 0, len(toStore))
-		for i := 0; i < len(toStore); i++ {
-			failedEntriesWithError = append(failedEntriesWithError, entryWithError{&toStore[i], &validation.ErrStreamRateLimit{RateLimit: flagext.ByteSize(limit), Labels: s.labelsString, Bytes: flagext.ByteSize(len(toStore[i].Line))}})
-			rateLimitedBytes += len(toStore[i].Line)
+		rateLimited := append(toStore, s.backfill...)
+		rateLimitedSamples = len(rateLimited)
+		failedEntriesWithError = make([]entryWithError, 0, len(rateLimited))
+		for i := 0; i < len(rateLimited); i++ {
+			failedEntriesWithError = append(failedEntriesWithError, entryWithError{&rateLimited[i], &validation.ErrStreamRateLimit{RateLimit: flagext.ByteSize(limit), Labels: s.labelsString, Bytes: flagext.ByteSize(len(rateLimited[i].Line))}})
+			rateLimitedBytes += len(rateLimited[i].Line)
 		}
 
 		// Log the only last error to the write failures manager.
@@ -467,7 +560,7 @@ func (s *stream) validateEntries(ctx context.Context, entries []logproto.Entry,
 func (s *stream) reportMetrics(ctx context.Context, outOfOrderSamples, outOfOrderBytes, rateLimitedSamples, rateLimitedBytes int, usageTracker push.UsageTracker) {
 	if outOfOrderSamples > 0 {
 		name := validation.OutOfOrder
-		if s.unorderedWrites {
+		if s.acceptsOutOfOrder() {
 			name = validation.TooFarBehind
 		}
 		validation.DiscardedSamples.WithLabelValues(name, s.tenant).Add(float64(outOfOrderSamples))
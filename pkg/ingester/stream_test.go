@@ -643,6 +643,108 @@ func Benchmark_PushStream(b *testing.B) {
 	}
 }
 
+type zstdDictionariesMock map[string]uint32
+
+func (m zstdDictionariesMock) Current(tenant string) uint32 {
+	return m[tenant]
+}
+
+func TestOutOfOrderWindow(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.MaxChunkAge = 10 * time.Second
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, newIngesterRingLimiterStrategy(&ringCountMock{count: 1}, 1), &TenantBasedStrategy{limits: limits})
+	chunkfmt, headfmt := defaultChunkFormat(t)
+
+	// the window applies even if the unordered writes are disabled.
+	s := newStream(chunkfmt, headfmt, &cfg, limiter.rateLimitStrategy, "fake", model.Fingerprint(0), labels.Labels{{Name: "foo", Value: "bar"}}, false, NewStreamRateCalculator(), NilMetrics, nil, nil)
+	push := func(sec int64) error {
+		_, err := s.Push(context.Background(), []logproto.Entry{{Timestamp: time.Unix(sec, 0), Line: fmt.Sprint(sec)}}, recordPool.GetRecord(), 0, true, false, nil)
+		return err
+	}
+
+	s.setOutOfOrderWindow(validation.OutOfOrderWindow{Window: model.Duration(time.Minute)}, true)
+	require.NoError(t, push(100))
+	require.NoError(t, push(50))
+	require.Error(t, push(30))
+	require.Nil(t, s.backfillChunk())
+	require.Equal(t, time.Unix(40, 0), s.outOfOrderCutoff(s.highestTs))
+
+	// the entries older than half of the max chunk age are backfilled.
+	s.setOutOfOrderWindow(validation.OutOfOrderWindow{Window: model.Duration(time.Minute), Backfill: true}, true)
+	require.NoError(t, push(98))
+	require.NoError(t, push(60))
+	// the duplicated backfilled entries are skipped.
+	require.NoError(t, push(60))
+	err = push(10)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), chunkenc.ErrTooFarBehind(time.Unix(10, 0), time.Unix(40, 0)).Error())
+	require.Len(t, s.chunks, 2)
+	require.Equal(t, s.backfillChunk(), &s.chunks[0])
+	require.Equal(t, []logproto.Entry{{Timestamp: time.Unix(60, 0), Line: "60"}}, buildStreamsFromChunk(t, s.labelsString, s.chunks[0].chunk).Entries)
+	require.Equal(t, time.Unix(95, 0), s.outOfOrderCutoff(s.highestTs))
+
+	it, err := s.Iterator(context.Background(), nil, time.Unix(0, 0), time.Unix(200, 0), logproto.FORWARD, log.NewNoopPipeline().ForStream(s.labels))
+	require.NoError(t, err)
+	var ts []int64
+	for it.Next() {
+		ts = append(ts, it.At().Timestamp.Unix())
+	}
+	require.NoError(t, it.Close())
+	require.Equal(t, []int64{50, 60, 98, 100}, ts)
+
+	// without a window, the stream is back to the default behavior.
+	s.setOutOfOrderWindow(validation.OutOfOrderWindow{}, false)
+	require.NoError(t, push(10))
+}
+
+func TestOutOfOrderWindowBackfillRateLimit(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.MaxChunkAge = 10 * time.Second
+	limits, err := validation.NewOverrides(validation.Limits{PerStreamRateLimit: 15, PerStreamRateLimitBurst: 15}, nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, newIngesterRingLimiterStrategy(&ringCountMock{count: 1}, 1), &TenantBasedStrategy{limits: limits})
+	chunkfmt, headfmt := defaultChunkFormat(t)
+
+	s := newStream(chunkfmt, headfmt, &cfg, limiter.rateLimitStrategy, "fake", model.Fingerprint(0), labels.Labels{{Name: "foo", Value: "bar"}}, true, NewStreamRateCalculator(), NilMetrics, nil, nil)
+	s.setOutOfOrderWindow(validation.OutOfOrderWindow{Window: model.Duration(time.Minute), Backfill: true}, true)
+	push := func(entries ...logproto.Entry) error {
+		_, err := s.Push(context.Background(), entries, recordPool.GetRecord(), 0, true, true, nil)
+		return err
+	}
+
+	require.NoError(t, push(logproto.Entry{Timestamp: time.Unix(100, 0), Line: "head"}))
+	// the backfilled entries count against the rate limit of the whole stream.
+	err = push(logproto.Entry{Timestamp: time.Unix(60, 0), Line: "backfill"}, logproto.Entry{Timestamp: time.Unix(99, 0), Line: "head"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "total ignored: 2 out of 2")
+	require.Nil(t, s.backfillChunk())
+	require.Len(t, s.chunks, 1)
+}
+
+func TestStreamNewChunk_ZstdDict(t *testing.T) {
+	limits, err := validation.NewOverrides(defaultLimitsTestConfig(), nil)
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, NilMetrics, newIngesterRingLimiterStrategy(&ringCountMock{count: 1}, 1), &TenantBasedStrategy{limits: limits})
+	chunkfmt, headfmt := defaultChunkFormat(t)
+
+	cfg := defaultConfig()
+	cfg.parsedEncoding = compression.ZstdDict
+	cfg.ZstdDictionaries = zstdDictionariesMock{}
+	s := newStream(chunkfmt, headfmt, cfg, limiter.rateLimitStrategy, "fake", model.Fingerprint(0), labels.Labels{{Name: "foo", Value: "bar"}}, true, NewStreamRateCalculator(), NilMetrics, nil, nil)
+
+	c := s.NewChunk()
+	require.Equal(t, compression.ZstdDict, c.Encoding())
+	_, err = c.Append(&logproto.Entry{Timestamp: time.Unix(1, 0), Line: "line"})
+	require.NoError(t, err)
+	require.NoError(t, c.Close())
+	b, err := c.Bytes()
+	require.NoError(t, err)
+	_, err = chunkenc.NewByteChunk(b, 0, 0)
+	require.NoError(t, err)
+}
+
 func defaultChunkFormat(t testing.TB) (byte, chunkenc.HeadBlockFmt) {
 	t.Helper()
 
@@ -232,6 +232,44 @@ func parseRegexQuery(httpRequest *http.Request) (string, error) {
 	return query, nil
 }
 
+// sampleQuery adds a `| sample` stage to all the log selectors of the query
+// when the sample parameter is set, e.g. `sample=0.01` keeps 1% of the lines.
+func sampleQuery(r *http.Request, query string) (string, error) {
+	s := r.Form.Get("sample")
+	if s == "" {
+		return query, nil
+	}
+	ratio, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", errors.Errorf("cannot parse %q to a valid sample ratio", s)
+	}
+	if !log.ValidSampleRatio(ratio) {
+		return "", errInvalidSample
+	}
+
+	expr, err := syntax.ParseExpr(query)
+	if err != nil {
+		return "", err
+	}
+	expr, err = syntax.AddSamplingExpr(expr, ratio)
+	if err != nil {
+		return "", err
+	}
+	return expr.String(), nil
+}
+
+func explain(r *http.Request) (bool, error) {
+	s := r.Form.Get("explain")
+	if s == "" {
+		return false, nil
+	}
+	explain, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.Errorf("cannot parse %q to a valid explain flag", s)
+	}
+	return explain, nil
+}
+
 func parseBytes(r *http.Request, field string, optional bool) (val datasize.ByteSize, err error) {
 	s := r.Form.Get(field)
 
@@ -0,0 +1,207 @@
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const (
+	elasticsearchIndexAction  = "index"
+	elasticsearchCreateAction = "create"
+	elasticsearchUpdateAction = "update"
+	elasticsearchDeleteAction = "delete"
+)
+
+// ElasticsearchBulkResponse is the response of the Elasticsearch bulk API,
+// with the result of each action of the request.
+type ElasticsearchBulkResponse struct {
+	Took   int64                              `json:"took"`
+	Errors bool                               `json:"errors"`
+	Items  []map[string]ElasticsearchBulkItem `json:"items"`
+
+	// streams holds the labels of the stream of the document of each item,
+	// empty for the items without a document.
+	streams []string
+}
+
+// ElasticsearchBulkItem is the result of an action of a bulk request.
+type ElasticsearchBulkItem struct {
+	Index  string              `json:"_index,omitempty"`
+	ID     string              `json:"_id,omitempty"`
+	Status int                 `json:"status"`
+	Result string              `json:"result,omitempty"`
+	Error  *ElasticsearchError `json:"error,omitempty"`
+}
+
+// ElasticsearchError is an error of the Elasticsearch APIs.
+type ElasticsearchError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func (r *ElasticsearchBulkResponse) add(action string, item ElasticsearchBulkItem, stream string) {
+	if item.Error != nil {
+		r.Errors = true
+		stream = ""
+	} else {
+		item.Status = http.StatusCreated
+		item.Result = "created"
+	}
+	r.Items = append(r.Items, map[string]ElasticsearchBulkItem{action: item})
+	r.streams = append(r.streams, stream)
+}
+
+// Reject marks the items whose documents were rejected as failed. rejected is
+// called with the labels of the stream of each document and the index of the
+// document in the stream, and returns the status and the error of the item if
+// the document was rejected.
+func (r *ElasticsearchBulkResponse) Reject(rejected func(stream string, entry int) (int, *ElasticsearchError)) {
+	entries := make(map[string]int)
+	for i, s := range r.streams {
+		if s == "" {
+			continue
+		}
+		entry := entries[s]
+		entries[s]++
+
+		status, err := rejected(s, entry)
+		if err == nil {
+			continue
+		}
+		for action, item := range r.Items[i] {
+			item.Status = status
+			item.Result = ""
+			item.Error = err
+			r.Items[i][action] = item
+		}
+		r.Errors = true
+	}
+}
+
+// elasticsearchBulkAction is the metadata of an action of a bulk request.
+type elasticsearchBulkAction struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id"`
+}
+
+// ElasticsearchBulkParser returns the parser of the requests of the
+// Elasticsearch bulk API, which records the result of their actions in the
+// response. The documents of the index and create actions are pushed as log
+// lines, the other actions fail.
+func ElasticsearchBulkParser(resp *ElasticsearchBulkResponse) RequestParser {
+	return func(userID string, r *http.Request, tenantsRetention TenantsRetention, limits Limits, tracker UsageTracker, logPushRequestStreams bool, logger log.Logger) (*logproto.PushRequest, *Stats, error) {
+		stats := newPushStats()
+		body, err := readRequestBody(r, stats)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cfg := limits.ElasticsearchConfig(userID)
+		builder := newEventsBuilder(r, userID, tenantsRetention, limits, tracker, stats, logPushRequestStreams, logger)
+		defaultIndex := mux.Vars(r)["index"]
+		now := time.Now()
+
+		lines := bytes.Split(body, []byte("\n"))
+		for i := 0; i < len(lines); i++ {
+			if len(bytes.TrimSpace(lines[i])) == 0 {
+				continue
+			}
+
+			var actions map[string]elasticsearchBulkAction
+			if err := json.Unmarshal(lines[i], &actions); err != nil || len(actions) != 1 {
+				return nil, nil, fmt.Errorf("malformed action/metadata line [%d], expected a single action", i+1)
+			}
+			for action, meta := range actions {
+				item := ElasticsearchBulkItem{Index: meta.Index, ID: meta.ID}
+				if item.Index == "" {
+					item.Index = defaultIndex
+				}
+
+				switch action {
+				case elasticsearchIndexAction, elasticsearchCreateAction:
+				case elasticsearchUpdateAction, elasticsearchDeleteAction:
+					if action == elasticsearchUpdateAction {
+						// skip the partial document of the update.
+						i++
+					}
+					item.Status = http.StatusBadRequest
+					item.Error = &ElasticsearchError{Type: "illegal_argument_exception", Reason: fmt.Sprintf("the %s action is not supported", action)}
+					resp.add(action, item, "")
+					continue
+				default:
+					return nil, nil, fmt.Errorf("malformed action/metadata line [%d], unknown action [%s]", i+1, action)
+				}
+
+				i++
+				if i == len(lines) {
+					return nil, nil, fmt.Errorf("the %s action on line [%d] is missing its document", action, i)
+				}
+				stream, err := addElasticsearchDocument(builder, cfg, item.Index, lines[i], now)
+				if err != nil {
+					item.Status = http.StatusBadRequest
+					item.Error = &ElasticsearchError{Type: "document_parsing_exception", Reason: err.Error()}
+				}
+				resp.add(action, item, stream)
+			}
+		}
+
+		return builder.pushRequest(), stats, nil
+	}
+}
+
+// addElasticsearchDocument adds the log line of a document of the given index
+// and returns the labels of its stream.
+func addElasticsearchDocument(builder *eventsBuilder, cfg ElasticsearchConfig, index string, doc []byte, now time.Time) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var object map[string]any
+	if err := dec.Decode(&object); err != nil {
+		return "", fmt.Errorf("failed to parse the document: %w", err)
+	}
+
+	var (
+		line      = string(bytes.TrimSpace(doc))
+		ts        = now
+		allFields = flattenFields(object, "")
+		fields    = allFields[:0]
+	)
+	for _, f := range allFields {
+		switch f.name {
+		case cfg.MessageField:
+			line = f.value
+		case cfg.TimestampField:
+			t, err := parseElasticsearchTimestamp(f.value)
+			if err != nil {
+				return "", fmt.Errorf("failed to parse the timestamp field [%s]: %w", f.name, err)
+			}
+			ts = t
+		default:
+			fields = append(fields, f)
+		}
+	}
+
+	var lbs model.LabelSet
+	if cfg.IndexLabel != "" && index != "" {
+		lbs = model.LabelSet{model.LabelName(cfg.IndexLabel): model.LabelValue(index)}
+	}
+	return builder.add(lbs, ts, line, fields, cfg.ActionForField)
+}
+
+// parseElasticsearchTimestamp parses a timestamp in RFC3339 format or in
+// milliseconds since the UNIX epoch, the default formats of the Elasticsearch
+// dates.
+func parseElasticsearchTimestamp(s string) (time.Time, error) {
+	if t, err := parseEpochTimestamp(s, time.Millisecond); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
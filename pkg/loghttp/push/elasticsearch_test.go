@@ -0,0 +1,131 @@
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+type fieldsLimits struct {
+	EmptyLimits
+	elasticsearch ElasticsearchConfig
+	splunkHEC     SplunkHECConfig
+}
+
+func (l fieldsLimits) ElasticsearchConfig(string) ElasticsearchConfig {
+	return l.elasticsearch
+}
+
+func (l fieldsLimits) SplunkHECConfig(string) SplunkHECConfig {
+	return l.splunkHEC
+}
+
+func sortStreams(streams []logproto.Stream) []logproto.Stream {
+	sort.Slice(streams, func(i, j int) bool { return streams[i].Labels < streams[j].Labels })
+	return streams
+}
+
+func TestParseElasticsearchBulkRequest(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	body := strings.Join([]string{
+		`{"index":{"_index":"nginx","_id":"1"}}`,
+		`{"@timestamp":"2024-01-02T03:04:05Z","message":"GET / 200","host":{"name":"web1"},"http":{"status":200},"tags":["a","b"]}`,
+		`{"create":{}}`,
+		`{"@timestamp":1704164645000,"level":"info","host":{"name":"web2"}}`,
+		`{"delete":{"_index":"nginx","_id":"1"}}`,
+		`{"update":{"_index":"nginx","_id":"2"}}`,
+		`{"doc":{"message":"updated"}}`,
+		`{"index":{"_index":"nginx"}}`,
+		`{"message":`,
+		`{"index":{"_index":"nginx"}}`,
+		`{"@timestamp":"yesterday","message":"invalid timestamp"}`,
+		``,
+	}, "\n")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/elasticsearch/logs/_bulk", &buf)
+	r.Header.Set("Content-Encoding", "gzip")
+	r = mux.SetURLVars(r, map[string]string{"index": "logs"})
+
+	limits := fieldsLimits{elasticsearch: DefaultElasticsearchConfig()}
+	limits.elasticsearch.FieldsConfig = append(limits.elasticsearch.FieldsConfig, AttributesConfig{Action: Drop, Regex: relabel.MustNewRegexp("tags")})
+
+	resp := &ElasticsearchBulkResponse{}
+	req, stats, err := ElasticsearchBulkParser(resp)("fake", r, nil, limits, nil, false, log.NewNopLogger())
+	require.NoError(t, err)
+
+	require.Equal(t, []logproto.Stream{
+		{
+			Labels: `{host_name="web1", index="nginx"}`,
+			Entries: []push.Entry{
+				{Timestamp: ts, Line: "GET / 200", StructuredMetadata: push.LabelsAdapter{{Name: "http_status", Value: "200"}}},
+			},
+		},
+		{
+			Labels: `{host_name="web2", index="logs"}`,
+			Entries: []push.Entry{
+				{Timestamp: time.Unix(1704164645, 0), Line: `{"@timestamp":1704164645000,"level":"info","host":{"name":"web2"}}`, StructuredMetadata: push.LabelsAdapter{{Name: "level", Value: "info"}}},
+			},
+		},
+	}, sortStreams(req.Streams))
+	require.Equal(t, int64(2), stats.NumLines)
+	require.Equal(t, "gzip", stats.ContentEncoding)
+
+	require.True(t, resp.Errors)
+	require.Len(t, resp.Items, 6)
+	require.Equal(t, ElasticsearchBulkItem{Index: "nginx", ID: "1", Status: http.StatusCreated, Result: "created"}, resp.Items[0]["index"])
+	require.Equal(t, ElasticsearchBulkItem{Index: "logs", Status: http.StatusCreated, Result: "created"}, resp.Items[1]["create"])
+	for i, action := range []string{"delete", "update", "index", "index"} {
+		item := resp.Items[i+2][action]
+		require.Equal(t, http.StatusBadRequest, item.Status, action)
+		require.NotNil(t, item.Error, action)
+	}
+	require.Equal(t, "the delete action is not supported", resp.Items[2]["delete"].Error.Reason)
+}
+
+func TestParseElasticsearchBulkRequestMalformed(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		err  string
+	}{
+		{name: "invalid action", body: `{"index":`, err: "malformed action/metadata line [1]"},
+		{name: "multiple actions", body: `{"index":{},"create":{}}`, err: "malformed action/metadata line [1]"},
+		{name: "unknown action", body: `{"upsert":{}}`, err: "unknown action [upsert]"},
+		{name: "missing document", body: `{"index":{}}`, err: "missing its document"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/elasticsearch/_bulk", strings.NewReader(tc.body))
+			_, _, err := ElasticsearchBulkParser(&ElasticsearchBulkResponse{})("fake", r, nil, EmptyLimits{}, nil, false, log.NewNopLogger())
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestElasticsearchConfigValidate(t *testing.T) {
+	cfg := DefaultElasticsearchConfig()
+	require.NoError(t, cfg.Validate())
+
+	cfg.IndexLabel = "es-index"
+	require.EqualError(t, cfg.Validate(), `invalid index label "es-index"`)
+}
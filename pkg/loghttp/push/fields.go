@@ -0,0 +1,246 @@
+package push
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/storage/remote/otlptranslator/prometheus"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	loki_util "github.com/grafana/loki/v3/pkg/util"
+)
+
+// This file has the code shared by the Elasticsearch and Splunk HEC compatible
+// APIs, which receive log events made of JSON fields.
+
+// readRequestBody reads the body of the request, decompressing it when it is
+// gzip encoded.
+func readRequestBody(r *http.Request, stats *Stats) ([]byte, error) {
+	stats.ContentType = r.Header.Get(contentType)
+	stats.ContentEncoding = r.Header.Get(contentEnc)
+	// bodySize should always reflect the compressed size of the request body
+	bodySize := loki_util.NewSizeReader(r.Body)
+	var body io.Reader = bodySize
+	switch stats.ContentEncoding {
+	case "":
+	case gzipContentEncoding:
+		gzipReader, err := gzip.NewReader(bodySize)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	default:
+		return nil, fmt.Errorf("Content-Encoding %q not supported", stats.ContentEncoding)
+	}
+
+	buf, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	stats.BodySize = bodySize.Size()
+	return buf, nil
+}
+
+// field is a field of a log event, named by its path for the fields of the
+// nested objects.
+type field struct {
+	name  string
+	value string
+}
+
+// flattenFields returns the fields of the object, sorted by name. The fields
+// of the nested objects are flattened and the arrays are encoded in JSON.
+func flattenFields(object map[string]any, prefix string) []field {
+	fields := make([]field, 0, len(object))
+	for name, value := range object {
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		switch v := value.(type) {
+		case nil:
+		case map[string]any:
+			fields = append(fields, flattenFields(v, name)...)
+		default:
+			fields = append(fields, field{name: name, value: fieldValue(v)})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	return fields
+}
+
+// fieldValue returns the value of a field decoded with json.Decoder.UseNumber
+// as a string.
+func fieldValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// parseEpochTimestamp parses a timestamp in the given unit since the UNIX
+// epoch, with optional decimals for the fractions of the unit.
+func parseEpochTimestamp(s string, unit time.Duration) (time.Time, error) {
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	i, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ts := time.Duration(i) * unit
+	if fracPart != "" {
+		// nanoseconds are the maximum precision of the timestamps.
+		if len(fracPart) > 9 {
+			fracPart = fracPart[:9]
+		}
+		frac, err := strconv.ParseUint(fracPart, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		ts += time.Duration(frac) * unit / time.Duration(pow10(len(fracPart)))
+	}
+	return time.Unix(0, int64(ts)), nil
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
+
+// eventsBuilder groups the log events in streams, mapping their fields to
+// labels or structured metadata.
+type eventsBuilder struct {
+	ctx                   context.Context
+	userID                string
+	tenantsRetention      TenantsRetention
+	discoverServiceName   []string
+	tracker               UsageTracker
+	stats                 *Stats
+	logPushRequestStreams bool
+	logger                log.Logger
+
+	streams map[string]*logproto.Stream
+}
+
+func newEventsBuilder(r *http.Request, userID string, tenantsRetention TenantsRetention, limits Limits, tracker UsageTracker, stats *Stats, logPushRequestStreams bool, logger log.Logger) *eventsBuilder {
+	return &eventsBuilder{
+		ctx:                   r.Context(),
+		userID:                userID,
+		tenantsRetention:      tenantsRetention,
+		discoverServiceName:   limits.DiscoverServiceName(userID),
+		tracker:               tracker,
+		stats:                 stats,
+		logPushRequestStreams: logPushRequestStreams,
+		logger:                logger,
+		streams:               make(map[string]*logproto.Stream),
+	}
+}
+
+// add adds a log event to its stream, identified by the given labels and the
+// fields stored as index labels, and returns the labels of the stream. The
+// action for each field tells whether it is stored as an index label, as
+// structured metadata or dropped.
+func (b *eventsBuilder) add(lbs model.LabelSet, ts time.Time, line string, fields []field, actionForField func(string) Action) (string, error) {
+	streamLabels := make(model.LabelSet, len(lbs)+8)
+	for name, value := range lbs {
+		streamLabels[name] = value
+	}
+	structuredMetadata := make(push.LabelsAdapter, 0, len(fields))
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		switch actionForField(f.name) {
+		case IndexLabel:
+			streamLabels[model.LabelName(prometheus.NormalizeLabel(f.name))] = model.LabelValue(f.value)
+		case StructuredMetadata:
+			structuredMetadata = append(structuredMetadata, push.LabelAdapter{Name: prometheus.NormalizeLabel(f.name), Value: f.value})
+		}
+	}
+
+	if _, ok := streamLabels[LabelServiceName]; !ok && len(b.discoverServiceName) > 0 {
+		serviceName := model.LabelValue(ServiceUnknown)
+		for _, labelName := range b.discoverServiceName {
+			if value, ok := streamLabels[model.LabelName(labelName)]; ok {
+				serviceName = value
+				break
+			}
+		}
+		if b.logPushRequestStreams {
+			level.Debug(b.logger).Log(
+				"msg", "push request stream before service name discovery",
+				"labels", streamLabels.String(),
+				"service_name", serviceName,
+			)
+		}
+		streamLabels[LabelServiceName] = serviceName
+	}
+
+	if err := streamLabels.Validate(); err != nil {
+		return "", fmt.Errorf("invalid labels: %w", err)
+	}
+
+	labelsStr := streamLabels.String()
+	labels := modelLabelsSetToLabelsList(streamLabels)
+	stream, ok := b.streams[labelsStr]
+	if !ok {
+		stream = &logproto.Stream{Labels: labelsStr}
+		b.streams[labelsStr] = stream
+		b.stats.StreamLabelsSize += int64(labelsSize(logproto.FromLabelsToLabelAdapters(labels)))
+	}
+	stream.Entries = append(stream.Entries, push.Entry{
+		Timestamp:          ts,
+		Line:               line,
+		StructuredMetadata: structuredMetadata,
+	})
+
+	var retentionPeriod time.Duration
+	if b.tenantsRetention != nil {
+		retentionPeriod = b.tenantsRetention.RetentionPeriodFor(b.userID, labels)
+	}
+	metadataSize := int64(labelsSize(structuredMetadata))
+	b.stats.LogLinesBytes[retentionPeriod] += int64(len(line))
+	b.stats.StructuredMetadataBytes[retentionPeriod] += metadataSize
+	if b.tracker != nil {
+		b.tracker.ReceivedBytesAdd(b.ctx, b.userID, retentionPeriod, labels, float64(len(line)))
+		b.tracker.ReceivedBytesAdd(b.ctx, b.userID, retentionPeriod, labels, float64(metadataSize))
+	}
+
+	b.stats.NumLines++
+	if ts.After(b.stats.MostRecentEntryTimestamp) {
+		b.stats.MostRecentEntryTimestamp = ts
+	}
+	return labelsStr, nil
+}
+
+func (b *eventsBuilder) pushRequest() *logproto.PushRequest {
+	req := &logproto.PushRequest{
+		Streams: make([]logproto.Stream, 0, len(b.streams)),
+	}
+	for _, stream := range b.streams {
+		req.Streams = append(req.Streams, *stream)
+	}
+	return req
+}
@@ -0,0 +1,73 @@
+package push
+
+import (
+	"fmt"
+
+	"github.com/prometheus/common/model"
+)
+
+// ElasticsearchConfig configures how the documents pushed with the
+// Elasticsearch bulk API are converted to log lines.
+type ElasticsearchConfig struct {
+	IndexLabel     string             `yaml:"index_label" json:"index_label" doc:"description=Label to store the index of the documents in. The index is not stored when empty."`
+	MessageField   string             `yaml:"message_field" json:"message_field" doc:"description=Field of the documents used as log line. The documents without this field are stored as log line, encoded in JSON."`
+	TimestampField string             `yaml:"timestamp_field" json:"timestamp_field" doc:"description=Field of the documents used as timestamp of the log lines, in RFC3339 format or in milliseconds since the UNIX epoch. The time of the push is used for the documents without this field."`
+	FieldsConfig   []AttributesConfig `yaml:"fields_config,omitempty" json:"fields_config,omitempty" doc:"description=Configuration for the fields of the documents to store them as index labels or Structured Metadata or drop them altogether. The fields of the nested objects are named by their path, for example host.name. The fields which are not configured are stored as Structured Metadata."`
+}
+
+// DefaultElasticsearchConfig returns the default config of the Elasticsearch
+// bulk API, which stores the Elastic Common Schema fields identifying the
+// source of the documents as index labels.
+func DefaultElasticsearchConfig() ElasticsearchConfig {
+	return ElasticsearchConfig{
+		IndexLabel:     "index",
+		MessageField:   "message",
+		TimestampField: "@timestamp",
+		FieldsConfig: []AttributesConfig{
+			{
+				Action: IndexLabel,
+				Attributes: []string{
+					"service.name",
+					"host.name",
+					"kubernetes.namespace",
+					"kubernetes.container.name",
+				},
+			},
+		},
+	}
+}
+
+func (c *ElasticsearchConfig) ActionForField(field string) Action {
+	return actionForAttribute(field, c.FieldsConfig)
+}
+
+func (c *ElasticsearchConfig) Validate() error {
+	if c.IndexLabel != "" && !model.LabelName(c.IndexLabel).IsValid() {
+		return fmt.Errorf("invalid index label %q", c.IndexLabel)
+	}
+	return nil
+}
+
+// SplunkHECConfig configures how the events pushed with the Splunk HTTP Event
+// Collector API are converted to log lines.
+type SplunkHECConfig struct {
+	FieldsConfig []AttributesConfig `yaml:"fields_config,omitempty" json:"fields_config,omitempty" doc:"description=Configuration for the metadata of the events (host, source, sourcetype and index) and their indexed fields to store them as index labels or Structured Metadata or drop them altogether. The fields which are not configured are stored as Structured Metadata."`
+}
+
+// DefaultSplunkHECConfig returns the default config of the Splunk HTTP Event
+// Collector API, which stores the index, source type and host of the events
+// as index labels.
+func DefaultSplunkHECConfig() SplunkHECConfig {
+	return SplunkHECConfig{
+		FieldsConfig: []AttributesConfig{
+			{
+				Action:     IndexLabel,
+				Attributes: []string{"index", "sourcetype", "host"},
+			},
+		},
+	}
+}
+
+func (c *SplunkHECConfig) ActionForField(field string) Action {
+	return actionForAttribute(field, c.FieldsConfig)
+}
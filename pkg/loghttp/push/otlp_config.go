@@ -85,7 +85,7 @@ func (c *OTLPConfig) ApplyGlobalOTLPConfig(config GlobalOTLPConfig) {
 	}
 }
 
-func (c *OTLPConfig) actionForAttribute(attribute string, cfgs []AttributesConfig) Action {
+func actionForAttribute(attribute string, cfgs []AttributesConfig) Action {
 	for i := 0; i < len(cfgs); i++ {
 		if cfgs[i].Regex.Regexp != nil && cfgs[i].Regex.MatchString(attribute) {
 			return cfgs[i].Action
@@ -101,15 +101,15 @@ func (c *OTLPConfig) actionForAttribute(attribute string, cfgs []AttributesConfi
 }
 
 func (c *OTLPConfig) ActionForResourceAttribute(attribute string) Action {
-	return c.actionForAttribute(attribute, c.ResourceAttributes.AttributesConfig)
+	return actionForAttribute(attribute, c.ResourceAttributes.AttributesConfig)
 }
 
 func (c *OTLPConfig) ActionForScopeAttribute(attribute string) Action {
-	return c.actionForAttribute(attribute, c.ScopeAttributes)
+	return actionForAttribute(attribute, c.ScopeAttributes)
 }
 
 func (c *OTLPConfig) ActionForLogAttribute(attribute string) Action {
-	return c.actionForAttribute(attribute, c.LogAttributes)
+	return actionForAttribute(attribute, c.LogAttributes)
 }
 
 func (c *OTLPConfig) Validate() error {
@@ -62,6 +62,10 @@ const (
 	LabelServiceName      = "service_name"
 	ServiceUnknown        = "unknown_service"
 	AggregatedMetricLabel = "__aggregated_metric__"
+
+	// IdempotencyKeyHeader is the header of the idempotency key of the push
+	// requests, which takes precedence over the key of the request body.
+	IdempotencyKeyHeader = "Idempotency-Key"
 )
 
 type TenantsRetention interface {
@@ -71,6 +75,8 @@ type TenantsRetention interface {
 type Limits interface {
 	OTLPConfig(userID string) OTLPConfig
 	DiscoverServiceName(userID string) []string
+	ElasticsearchConfig(userID string) ElasticsearchConfig
+	SplunkHECConfig(userID string) SplunkHECConfig
 }
 
 type EmptyLimits struct{}
@@ -83,6 +89,14 @@ func (EmptyLimits) DiscoverServiceName(string) []string {
 	return nil
 }
 
+func (EmptyLimits) ElasticsearchConfig(string) ElasticsearchConfig {
+	return DefaultElasticsearchConfig()
+}
+
+func (EmptyLimits) SplunkHECConfig(string) SplunkHECConfig {
+	return DefaultSplunkHECConfig()
+}
+
 type (
 	RequestParser        func(userID string, r *http.Request, tenantsRetention TenantsRetention, limits Limits, tracker UsageTracker, logPushRequestStreams bool, logger log.Logger) (*logproto.PushRequest, *Stats, error)
 	RequestParserWrapper func(inner RequestParser) RequestParser
@@ -111,6 +125,9 @@ func ParseRequest(logger log.Logger, userID string, r *http.Request, tenantsRete
 	if err != nil {
 		return nil, err
 	}
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		req.IdempotencyKey = key
+	}
 
 	var (
 		entriesSize            int64
@@ -463,6 +463,40 @@ func (f *fakeLimits) DiscoverServiceName(_ string) []string {
 	}
 }
 
+func (f *fakeLimits) ElasticsearchConfig(_ string) ElasticsearchConfig {
+	return DefaultElasticsearchConfig()
+}
+
+func (f *fakeLimits) SplunkHECConfig(_ string) SplunkHECConfig {
+	return DefaultSplunkHECConfig()
+}
+
+func TestParseRequestIdempotencyKey(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		body   string
+		header string
+		key    string
+	}{
+		{name: "no key", body: `{"streams": []}`},
+		{name: "body", body: `{"streams": [], "idempotencyKey": "body-key"}`, key: "body-key"},
+		{name: "header", body: `{"streams": []}`, header: "header-key", key: "header-key"},
+		{name: "header takes precedence", body: `{"streams": [], "idempotencyKey": "body-key"}`, header: "header-key", key: "header-key"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest("POST", "/loki/api/v1/push", strings.NewReader(tc.body))
+			request.Header.Add("Content-Type", "application/json")
+			if tc.header != "" {
+				request.Header.Add(IdempotencyKeyHeader, tc.header)
+			}
+
+			data, err := ParseRequest(util_log.Logger, "fake", request, nil, &fakeLimits{}, ParseLokiRequest, nil, false)
+			require.NoError(t, err)
+			require.Equal(t, tc.key, data.IdempotencyKey)
+		})
+	}
+}
+
 type MockCustomTracker struct {
 	receivedBytes  map[string]float64
 	discardedBytes map[string]float64
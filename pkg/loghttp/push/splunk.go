@@ -0,0 +1,102 @@
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/log"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// splunkHECEvent is an event of the Splunk HTTP Event Collector API.
+type splunkHECEvent struct {
+	// Time is in seconds since the UNIX epoch, with optional decimals, as a
+	// number or a string.
+	Time       any             `json:"time"`
+	Host       string          `json:"host"`
+	Source     string          `json:"source"`
+	SourceType string          `json:"sourcetype"`
+	Index      string          `json:"index"`
+	Event      json.RawMessage `json:"event"`
+	Fields     map[string]any  `json:"fields"`
+}
+
+// ParseSplunkHECRequest parses the requests of the Splunk HTTP Event Collector
+// API, made of one or more events. The events which are strings are pushed as
+// log lines, the others are pushed encoded in JSON.
+func ParseSplunkHECRequest(userID string, r *http.Request, tenantsRetention TenantsRetention, limits Limits, tracker UsageTracker, logPushRequestStreams bool, logger log.Logger) (*logproto.PushRequest, *Stats, error) {
+	stats := newPushStats()
+	body, err := readRequestBody(r, stats)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := limits.SplunkHECConfig(userID)
+	builder := newEventsBuilder(r, userID, tenantsRetention, limits, tracker, stats, logPushRequestStreams, logger)
+	now := time.Now()
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	for i := 0; ; i++ {
+		var event splunkHECEvent
+		if err := dec.Decode(&event); errors.Is(err, io.EOF) {
+			if i == 0 {
+				return nil, nil, errors.New("no data")
+			}
+			break
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("invalid event %d: %w", i, err)
+		}
+
+		line, err := splunkHECEventLine(event.Event)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid event %d: %w", i, err)
+		}
+
+		ts := now
+		if event.Time != nil {
+			if ts, err = parseEpochTimestamp(fieldValue(event.Time), time.Second); err != nil {
+				return nil, nil, fmt.Errorf("invalid event %d: invalid time: %w", i, err)
+			}
+		}
+
+		fields := append(flattenFields(event.Fields, ""),
+			field{name: "host", value: event.Host},
+			field{name: "source", value: event.Source},
+			field{name: "sourcetype", value: event.SourceType},
+			field{name: "index", value: event.Index},
+		)
+		if _, err := builder.add(nil, ts, line, fields, cfg.ActionForField); err != nil {
+			return nil, nil, fmt.Errorf("invalid event %d: %w", i, err)
+		}
+	}
+
+	return builder.pushRequest(), stats, nil
+}
+
+// splunkHECEventLine returns the log line of an event.
+func splunkHECEventLine(event json.RawMessage) (string, error) {
+	if len(event) == 0 || string(event) == "null" {
+		return "", errors.New("event field is required")
+	}
+
+	var line string
+	if err := json.Unmarshal(event, &line); err != nil {
+		// the event is not a string.
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, event); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	if line == "" {
+		return "", errors.New("event field cannot be blank")
+	}
+	return line, nil
+}
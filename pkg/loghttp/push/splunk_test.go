@@ -0,0 +1,91 @@
+package push
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+func TestParseSplunkHECRequest(t *testing.T) {
+	body := `{"time":1704164645.5,"host":"web1","source":"/var/log/nginx.log","sourcetype":"nginx","index":"main","event":"GET / 200","fields":{"region":"eu","status":200}}
+{"time":"1704164645","host":"web1","sourcetype":"nginx","index":"main","event":{"msg":"timeout", "upstream":"api"}}{"event":"no metadata"}`
+
+	r := httptest.NewRequest(http.MethodPost, "/services/collector/event", strings.NewReader(body))
+	limits := fieldsLimits{splunkHEC: DefaultSplunkHECConfig()}
+	limits.splunkHEC.FieldsConfig = append(limits.splunkHEC.FieldsConfig, AttributesConfig{Action: Drop, Attributes: []string{"status"}})
+
+	before := time.Now()
+	req, stats, err := ParseSplunkHECRequest("fake", r, nil, limits, nil, false, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), stats.NumLines)
+
+	streams := sortStreams(req.Streams)
+	require.Len(t, streams, 2)
+	require.Equal(t, logproto.Stream{
+		Labels: `{host="web1", index="main", sourcetype="nginx"}`,
+		Entries: []push.Entry{
+			{
+				Timestamp: time.Unix(1704164645, int64(500*time.Millisecond)),
+				Line:      "GET / 200",
+				StructuredMetadata: push.LabelsAdapter{
+					{Name: "region", Value: "eu"},
+					{Name: "source", Value: "/var/log/nginx.log"},
+				},
+			},
+			{
+				Timestamp:          time.Unix(1704164645, 0),
+				Line:               `{"msg":"timeout","upstream":"api"}`,
+				StructuredMetadata: push.LabelsAdapter{},
+			},
+		},
+	}, streams[0])
+	require.Equal(t, "{}", streams[1].Labels)
+	require.Equal(t, "no metadata", streams[1].Entries[0].Line)
+	require.False(t, streams[1].Entries[0].Timestamp.Before(before))
+}
+
+func TestParseSplunkHECRequestInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		err  string
+	}{
+		{name: "no data", body: " ", err: "no data"},
+		{name: "invalid json", body: `{"event":"a"}{"event":`, err: "invalid event 1"},
+		{name: "missing event", body: `{"host":"web1"}`, err: "invalid event 0: event field is required"},
+		{name: "blank event", body: `{"event":""}`, err: "invalid event 0: event field cannot be blank"},
+		{name: "invalid time", body: `{"event":"a","time":"now"}`, err: "invalid event 0: invalid time"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/services/collector/event", strings.NewReader(tc.body))
+			_, _, err := ParseSplunkHECRequest("fake", r, nil, EmptyLimits{}, nil, false, log.NewNopLogger())
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestParseEpochTimestamp(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		unit time.Duration
+		exp  time.Time
+	}{
+		{in: "1704164645", unit: time.Second, exp: time.Unix(1704164645, 0)},
+		{in: "1704164645.123", unit: time.Second, exp: time.Unix(1704164645, int64(123*time.Millisecond))},
+		{in: "1704164645.1234567891", unit: time.Second, exp: time.Unix(1704164645, 123456789)},
+		{in: "1704164645123.5", unit: time.Millisecond, exp: time.Unix(1704164645, int64(123*time.Millisecond+500*time.Microsecond))},
+	} {
+		ts, err := parseEpochTimestamp(tc.in, tc.unit)
+		require.NoError(t, err)
+		require.Equal(t, tc.exp, ts, tc.in)
+	}
+}
@@ -30,6 +30,7 @@ var (
 	errNegativeStep       = errors.New("negative query resolution step widths are not accepted. Try a positive integer")
 	errStepTooSmall       = errors.New("exceeded maximum resolution of 11,000 points per time series. Try increasing the value of the step parameter")
 	errNegativeInterval   = errors.New("interval must be >= 0")
+	errInvalidSample      = errors.New("sample must be > 0 and <= 1")
 )
 
 // QueryStatus holds the status of a query
@@ -90,7 +91,8 @@ func unescapeJSONString(b []byte) string {
 
 // PushRequest models a log stream push but is unmarshalled to proto push format.
 type PushRequest struct {
-	Streams []LogProtoStream `json:"streams"`
+	Streams        []LogProtoStream `json:"streams"`
+	IdempotencyKey string           `json:"idempotencyKey,omitempty"`
 }
 
 // LogProtoStream helps with unmarshalling of each log stream for push request.
@@ -420,6 +422,11 @@ func ParseInstantQuery(r *http.Request) (*InstantQuery, error) {
 		return nil, err
 	}
 
+	request.Query, err = sampleQuery(r, request.Query)
+	if err != nil {
+		return nil, err
+	}
+
 	return request, nil
 }
 
@@ -433,6 +440,8 @@ type RangeQuery struct {
 	Direction logproto.Direction
 	Limit     uint32
 	Shards    []string
+	// Explain requests the query plan instead of the query results.
+	Explain bool
 }
 
 func NewRangeQueryWithDefaults() *RangeQuery {
@@ -521,6 +530,16 @@ func ParseRangeQuery(r *http.Request) (*RangeQuery, error) {
 		}
 	}
 
+	result.Query, err = sampleQuery(r, result.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Explain, err = explain(r)
+	if err != nil {
+		return nil, err
+	}
+
 	return &result, nil
 }
 
@@ -71,6 +71,45 @@ func TestParseRangeQuery(t *testing.T) {
 				Limit:     1000,
 			}, false,
 		},
+		{
+			"bad sample",
+			&http.Request{
+				URL: mustParseURL(`?query={foo="bar"}&start=2017-06-10T21:42:24.760738998Z&end=2017-07-10T21:42:24.760738998Z&step=3600&sample=2`),
+			}, nil, true,
+		},
+		{
+			"sampled",
+			&http.Request{
+				URL: mustParseURL(`?query={foo="bar"}&start=2017-06-10T21:42:24.760738998Z&end=2017-07-10T21:42:24.760738998Z&limit=1000&direction=BACKWARD&step=3600&sample=0.01`),
+			}, &RangeQuery{
+				Step:      time.Hour,
+				Query:     `{foo="bar"} | sample 0.01`,
+				Direction: logproto.BACKWARD,
+				Start:     time.Date(2017, 06, 10, 21, 42, 24, 760738998, time.UTC),
+				End:       time.Date(2017, 07, 10, 21, 42, 24, 760738998, time.UTC),
+				Limit:     1000,
+			}, false,
+		},
+		{
+			"bad explain",
+			&http.Request{
+				URL: mustParseURL(`?query={foo="bar"}&start=2017-06-10T21:42:24.760738998Z&end=2017-07-10T21:42:24.760738998Z&step=3600&explain=maybe`),
+			}, nil, true,
+		},
+		{
+			"explained",
+			&http.Request{
+				URL: mustParseURL(`?query={foo="bar"}&start=2017-06-10T21:42:24.760738998Z&end=2017-07-10T21:42:24.760738998Z&limit=1000&direction=BACKWARD&step=3600&explain=true`),
+			}, &RangeQuery{
+				Step:      time.Hour,
+				Query:     `{foo="bar"}`,
+				Direction: logproto.BACKWARD,
+				Start:     time.Date(2017, 06, 10, 21, 42, 24, 760738998, time.UTC),
+				End:       time.Date(2017, 07, 10, 21, 42, 24, 760738998, time.UTC),
+				Limit:     1000,
+				Explain:   true,
+			}, false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -115,6 +154,23 @@ func TestParseInstantQuery(t *testing.T) {
 				Limit:     1000,
 			}, false,
 		},
+		{
+			"bad sample",
+			&http.Request{
+				URL: mustParseURL(`?query={foo="bar"}&time=2017-06-10T21:42:24.760738998Z&sample=0`),
+			}, nil, true,
+		},
+		{
+			"sampled",
+			&http.Request{
+				URL: mustParseURL(`?query=count_over_time({foo="bar"}[5m])&time=2017-06-10T21:42:24.760738998Z&limit=1000&direction=BACKWARD&sample=0.25`),
+			}, &InstantQuery{
+				Query:     `count_over_time({foo="bar"} | sample 0.25[5m])`,
+				Direction: logproto.BACKWARD,
+				Ts:        time.Date(2017, 06, 10, 21, 42, 24, 760738998, time.UTC),
+				Limit:     1000,
+			}, false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -639,6 +639,151 @@ func (m *TopKMatrix_Vector) GetTimestampMs() int64 {
 	return 0
 }
 
+type DistinctSketchMatrix struct {
+	Values []*DistinctSketchVector `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (m *DistinctSketchMatrix) Reset()      { *m = DistinctSketchMatrix{} }
+func (*DistinctSketchMatrix) ProtoMessage() {}
+func (*DistinctSketchMatrix) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7f9fd40e59b87ff3, []int{8}
+}
+func (m *DistinctSketchMatrix) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DistinctSketchMatrix) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DistinctSketchMatrix.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DistinctSketchMatrix) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DistinctSketchMatrix.Merge(m, src)
+}
+func (m *DistinctSketchMatrix) XXX_Size() int {
+	return m.Size()
+}
+func (m *DistinctSketchMatrix) XXX_DiscardUnknown() {
+	xxx_messageInfo_DistinctSketchMatrix.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DistinctSketchMatrix proto.InternalMessageInfo
+
+func (m *DistinctSketchMatrix) GetValues() []*DistinctSketchVector {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type DistinctSketchVector struct {
+	Samples []*DistinctSketchSample `protobuf:"bytes,1,rep,name=samples,proto3" json:"samples,omitempty"`
+}
+
+func (m *DistinctSketchVector) Reset()      { *m = DistinctSketchVector{} }
+func (*DistinctSketchVector) ProtoMessage() {}
+func (*DistinctSketchVector) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7f9fd40e59b87ff3, []int{9}
+}
+func (m *DistinctSketchVector) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DistinctSketchVector) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DistinctSketchVector.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DistinctSketchVector) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DistinctSketchVector.Merge(m, src)
+}
+func (m *DistinctSketchVector) XXX_Size() int {
+	return m.Size()
+}
+func (m *DistinctSketchVector) XXX_DiscardUnknown() {
+	xxx_messageInfo_DistinctSketchVector.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DistinctSketchVector proto.InternalMessageInfo
+
+func (m *DistinctSketchVector) GetSamples() []*DistinctSketchSample {
+	if m != nil {
+		return m.Samples
+	}
+	return nil
+}
+
+type DistinctSketchSample struct {
+	Hyperloglog []byte       `protobuf:"bytes,1,opt,name=hyperloglog,proto3" json:"hyperloglog,omitempty"`
+	TimestampMs int64        `protobuf:"varint,2,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	Metric      []*LabelPair `protobuf:"bytes,3,rep,name=metric,proto3" json:"metric,omitempty"`
+}
+
+func (m *DistinctSketchSample) Reset()      { *m = DistinctSketchSample{} }
+func (*DistinctSketchSample) ProtoMessage() {}
+func (*DistinctSketchSample) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7f9fd40e59b87ff3, []int{10}
+}
+func (m *DistinctSketchSample) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DistinctSketchSample) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DistinctSketchSample.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DistinctSketchSample) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DistinctSketchSample.Merge(m, src)
+}
+func (m *DistinctSketchSample) XXX_Size() int {
+	return m.Size()
+}
+func (m *DistinctSketchSample) XXX_DiscardUnknown() {
+	xxx_messageInfo_DistinctSketchSample.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DistinctSketchSample proto.InternalMessageInfo
+
+func (m *DistinctSketchSample) GetHyperloglog() []byte {
+	if m != nil {
+		return m.Hyperloglog
+	}
+	return nil
+}
+
+func (m *DistinctSketchSample) GetTimestampMs() int64 {
+	if m != nil {
+		return m.TimestampMs
+	}
+	return 0
+}
+
+func (m *DistinctSketchSample) GetMetric() []*LabelPair {
+	if m != nil {
+		return m.Metric
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*QuantileSketchMatrix)(nil), "logproto.QuantileSketchMatrix")
 	proto.RegisterType((*QuantileSketchVector)(nil), "logproto.QuantileSketchVector")
@@ -651,52 +796,57 @@ func init() {
 	proto.RegisterType((*TopK_Pair)(nil), "logproto.TopK.Pair")
 	proto.RegisterType((*TopKMatrix)(nil), "logproto.TopKMatrix")
 	proto.RegisterType((*TopKMatrix_Vector)(nil), "logproto.TopKMatrix.Vector")
+	proto.RegisterType((*DistinctSketchMatrix)(nil), "logproto.DistinctSketchMatrix")
+	proto.RegisterType((*DistinctSketchVector)(nil), "logproto.DistinctSketchVector")
+	proto.RegisterType((*DistinctSketchSample)(nil), "logproto.DistinctSketchSample")
 }
 
 func init() { proto.RegisterFile("pkg/logproto/sketch.proto", fileDescriptor_7f9fd40e59b87ff3) }
 
 var fileDescriptor_7f9fd40e59b87ff3 = []byte{
-	// 626 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x41, 0x4f, 0xd4, 0x4e,
-	0x14, 0xef, 0xfc, 0x77, 0xff, 0xcb, 0xf2, 0x16, 0x88, 0x8e, 0xc4, 0xd4, 0xc5, 0x4c, 0xd6, 0x1e,
-	0x84, 0x68, 0xdc, 0x4d, 0x20, 0x21, 0x9c, 0xc1, 0x03, 0x89, 0xa2, 0x38, 0x10, 0x63, 0x4c, 0x8c,
-	0x19, 0xda, 0xa1, 0x3b, 0xd9, 0xb6, 0xd3, 0x74, 0x66, 0x01, 0x6f, 0x7e, 0x02, 0x63, 0xfc, 0x14,
-	0x5e, 0xfd, 0x08, 0xde, 0x3c, 0x72, 0xe4, 0x28, 0xe5, 0xe2, 0x91, 0x8f, 0x60, 0x66, 0xda, 0x2e,
-	0x74, 0x89, 0xd1, 0xd3, 0xce, 0xfb, 0xbd, 0xdf, 0xfb, 0xcd, 0x6f, 0xde, 0xeb, 0x5b, 0xb8, 0x97,
-	0x8e, 0xc2, 0x41, 0x24, 0xc3, 0x34, 0x93, 0x5a, 0x0e, 0xd4, 0x88, 0x6b, 0x7f, 0xd8, 0xb7, 0x01,
-	0x6e, 0x57, 0x70, 0x77, 0xa9, 0x46, 0xaa, 0x0e, 0x05, 0xcd, 0x7b, 0x01, 0x8b, 0xaf, 0xc6, 0x2c,
-	0xd1, 0x22, 0xe2, 0x7b, 0xb6, 0x7c, 0x87, 0xe9, 0x4c, 0x9c, 0xe0, 0x75, 0x68, 0x1d, 0xb1, 0x68,
-	0xcc, 0x95, 0x8b, 0x7a, 0x8d, 0x95, 0xce, 0x2a, 0xe9, 0x4f, 0x0a, 0xeb, 0xfc, 0xd7, 0xdc, 0xd7,
-	0x32, 0xa3, 0x25, 0xdb, 0xdb, 0x9d, 0xd6, 0x2b, 0xf2, 0x78, 0x03, 0x66, 0x14, 0x8b, 0xd3, 0xe8,
-	0xef, 0x82, 0x7b, 0x96, 0x46, 0x2b, 0xba, 0xf7, 0x09, 0x4d, 0x4b, 0x16, 0x0c, 0xfc, 0x10, 0xd0,
-	0xa1, 0x8b, 0x7a, 0x68, 0xa5, 0xb3, 0xea, 0xfe, 0x49, 0x8c, 0xa2, 0x43, 0xfc, 0x00, 0xe6, 0xb4,
-	0x88, 0xb9, 0xd2, 0x2c, 0x4e, 0xdf, 0xc7, 0xca, 0xfd, 0xaf, 0x87, 0x56, 0x1a, 0xb4, 0x33, 0xc1,
-	0x76, 0x14, 0x7e, 0x0c, 0xad, 0x98, 0xeb, 0x4c, 0xf8, 0x6e, 0xc3, 0x9a, 0xbb, 0x73, 0xa5, 0xf7,
-	0x9c, 0x1d, 0xf0, 0x68, 0x97, 0x89, 0x8c, 0x96, 0x14, 0x2f, 0x84, 0x85, 0xfa, 0x25, 0xf8, 0x09,
-	0xcc, 0xe8, 0x40, 0x84, 0x5c, 0xe9, 0xd2, 0xcf, 0xed, 0xab, 0xfa, 0xfd, 0xa7, 0x36, 0xb1, 0xed,
-	0xd0, 0x8a, 0x83, 0xef, 0x43, 0x3b, 0x08, 0x8a, 0x61, 0x59, 0x33, 0x73, 0xdb, 0x0e, 0x9d, 0x20,
-	0x9b, 0x6d, 0x68, 0x15, 0x27, 0xef, 0x3b, 0x82, 0x99, 0xb2, 0x1c, 0xdf, 0x82, 0x46, 0x2c, 0x12,
-	0x2b, 0x8f, 0xa8, 0x39, 0x5a, 0x84, 0x9d, 0x58, 0x01, 0x83, 0xb0, 0x13, 0xdc, 0x83, 0x8e, 0x2f,
-	0xe3, 0x34, 0xe3, 0x4a, 0x09, 0x99, 0xb8, 0x0d, 0x9b, 0xb9, 0x0e, 0xe1, 0x0d, 0x98, 0x4d, 0x33,
-	0xe9, 0x73, 0xa5, 0x78, 0xe0, 0x36, 0xed, 0x53, 0xbb, 0x37, 0xac, 0xf6, 0xb7, 0x78, 0xa2, 0x33,
-	0x29, 0x02, 0x7a, 0x45, 0xee, 0xae, 0x43, 0xbb, 0x82, 0x31, 0x86, 0x66, 0xcc, 0x59, 0x65, 0xc6,
-	0x9e, 0xf1, 0x5d, 0x68, 0x1d, 0x73, 0x11, 0x0e, 0x75, 0x69, 0xa8, 0x8c, 0xbc, 0x37, 0xb0, 0xb0,
-	0x25, 0xc7, 0x89, 0xde, 0x11, 0x49, 0xd9, 0xac, 0x45, 0xf8, 0x3f, 0xe0, 0xa9, 0x1e, 0xda, 0xf2,
-	0x79, 0x5a, 0x04, 0x06, 0x3d, 0x16, 0x81, 0x2e, 0x1a, 0x32, 0x4f, 0x8b, 0x00, 0x77, 0xa1, 0xed,
-	0x9b, 0x6a, 0x9e, 0x29, 0x3b, 0x99, 0x79, 0x3a, 0x89, 0xbd, 0x6f, 0x08, 0x9a, 0xfb, 0x32, 0x7d,
-	0x86, 0x1f, 0x41, 0xc3, 0x8f, 0xd5, 0xcd, 0x2f, 0xa1, 0x7e, 0x2f, 0x35, 0x24, 0xbc, 0x0c, 0xcd,
-	0x48, 0x28, 0x63, 0x72, 0x6a, 0xcc, 0x46, 0xa9, 0x6f, 0xc7, 0x6c, 0x09, 0xa6, 0x97, 0xc3, 0x0f,
-	0x29, 0xcf, 0x22, 0x19, 0x46, 0x32, 0xb4, 0xbd, 0x9c, 0xa3, 0xd7, 0xa1, 0xee, 0x2a, 0x34, 0x0d,
-	0xdf, 0x38, 0xe7, 0x47, 0x3c, 0x29, 0x46, 0x3f, 0x4b, 0x8b, 0xc0, 0xa0, 0xd6, 0x69, 0xf5, 0x1e,
-	0x1b, 0x78, 0x5f, 0x10, 0x80, 0xb9, 0xa9, 0x5c, 0xb2, 0xb5, 0xa9, 0x25, 0x5b, 0xaa, 0xfb, 0x29,
-	0x58, 0xfd, 0xfa, 0x86, 0x75, 0x5f, 0x42, 0xab, 0xdc, 0x29, 0x0f, 0x9a, 0x5a, 0xa6, 0xa3, 0xf2,
-	0xe5, 0x0b, 0xf5, 0x62, 0x6a, 0x73, 0xff, 0xf0, 0xf1, 0x6f, 0xbe, 0x3b, 0x3d, 0x27, 0xce, 0xd9,
-	0x39, 0x71, 0x2e, 0xcf, 0x09, 0xfa, 0x98, 0x13, 0xf4, 0x35, 0x27, 0xe8, 0x47, 0x4e, 0xd0, 0x69,
-	0x4e, 0xd0, 0xcf, 0x9c, 0xa0, 0x5f, 0x39, 0x71, 0x2e, 0x73, 0x82, 0x3e, 0x5f, 0x10, 0xe7, 0xf4,
-	0x82, 0x38, 0x67, 0x17, 0xc4, 0x79, 0xbb, 0x1c, 0x0a, 0x3d, 0x1c, 0x1f, 0xf4, 0x7d, 0x19, 0x0f,
-	0xc2, 0x8c, 0x1d, 0xb2, 0x84, 0x0d, 0x22, 0x39, 0x12, 0x83, 0xa3, 0xb5, 0xc1, 0xf5, 0x3f, 0x9c,
-	0x83, 0x96, 0xfd, 0x59, 0xfb, 0x1d, 0x00, 0x00, 0xff, 0xff, 0x9e, 0x82, 0x05, 0x2f, 0xac, 0x04,
-	0x00, 0x00,
+	// 665 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x54, 0xc1, 0x6e, 0xd3, 0x4c,
+	0x10, 0xf6, 0x36, 0xf9, 0xd3, 0x74, 0xd2, 0x56, 0x3f, 0x4b, 0x84, 0x4c, 0x8a, 0x56, 0xc1, 0x07,
+	0x5a, 0x81, 0x48, 0xa4, 0x56, 0xaa, 0x7a, 0x6e, 0x7b, 0xa8, 0x04, 0x85, 0xb2, 0xad, 0x10, 0x42,
+	0x42, 0xc8, 0xb5, 0xb7, 0xce, 0x2a, 0xb6, 0xd7, 0xf2, 0x6e, 0xda, 0x72, 0xe3, 0xc8, 0x09, 0x21,
+	0x9e, 0x82, 0x2b, 0x8f, 0xc0, 0x8d, 0x63, 0x8f, 0x3d, 0x52, 0xf7, 0xc2, 0xb1, 0x8f, 0x80, 0xbc,
+	0xb6, 0xd3, 0x38, 0x09, 0xd0, 0x03, 0x27, 0xef, 0x7c, 0xf3, 0xcd, 0xec, 0xb7, 0x33, 0x9e, 0x81,
+	0xbb, 0x51, 0xdf, 0xeb, 0xfa, 0xc2, 0x8b, 0x62, 0xa1, 0x44, 0x57, 0xf6, 0x99, 0x72, 0x7a, 0x1d,
+	0x6d, 0xe0, 0x7a, 0x01, 0xb7, 0x96, 0x4a, 0xa4, 0xe2, 0x90, 0xd1, 0xac, 0x67, 0xd0, 0x7c, 0x31,
+	0xb0, 0x43, 0xc5, 0x7d, 0xb6, 0xaf, 0xc3, 0x77, 0x6d, 0x15, 0xf3, 0x53, 0xbc, 0x0e, 0xb5, 0x63,
+	0xdb, 0x1f, 0x30, 0x69, 0xa2, 0x76, 0x65, 0xa5, 0xb1, 0x4a, 0x3a, 0xc3, 0xc0, 0x32, 0xff, 0x25,
+	0x73, 0x94, 0x88, 0x69, 0xce, 0xb6, 0xf6, 0xa0, 0x39, 0xcd, 0x8f, 0x37, 0x60, 0x56, 0xda, 0x41,
+	0xe4, 0xff, 0x3d, 0xe1, 0xbe, 0xa6, 0xd1, 0x82, 0x6e, 0x7d, 0x44, 0xd0, 0x9c, 0xc6, 0xc0, 0x0f,
+	0x00, 0x1d, 0x99, 0xa8, 0x8d, 0x56, 0x1a, 0xab, 0xe6, 0xef, 0x92, 0x51, 0x74, 0x84, 0xef, 0xc3,
+	0xbc, 0xe2, 0x01, 0x93, 0xca, 0x0e, 0xa2, 0xb7, 0x81, 0x34, 0x67, 0xda, 0x68, 0xa5, 0x42, 0x1b,
+	0x43, 0x6c, 0x57, 0xe2, 0x47, 0x50, 0x0b, 0x98, 0x8a, 0xb9, 0x63, 0x56, 0xb4, 0xb8, 0xdb, 0xd7,
+	0xf9, 0x9e, 0xda, 0x87, 0xcc, 0xdf, 0xb3, 0x79, 0x4c, 0x73, 0x8a, 0xe5, 0xc1, 0x62, 0xf9, 0x12,
+	0xfc, 0x18, 0x66, 0x95, 0xcb, 0x3d, 0x26, 0x55, 0xae, 0xe7, 0xd6, 0x75, 0xfc, 0xc1, 0xb6, 0x76,
+	0xec, 0x18, 0xb4, 0xe0, 0xe0, 0x7b, 0x50, 0x77, 0xdd, 0xac, 0x59, 0x5a, 0xcc, 0xfc, 0x8e, 0x41,
+	0x87, 0xc8, 0x66, 0x1d, 0x6a, 0xd9, 0xc9, 0xfa, 0x86, 0x60, 0x36, 0x0f, 0xc7, 0xff, 0x43, 0x25,
+	0xe0, 0xa1, 0x4e, 0x8f, 0x68, 0x7a, 0xd4, 0x88, 0x7d, 0x6a, 0xce, 0xe4, 0x88, 0x7d, 0x8a, 0xdb,
+	0xd0, 0x70, 0x44, 0x10, 0xc5, 0x4c, 0x4a, 0x2e, 0x42, 0xb3, 0xa2, 0x3d, 0xa3, 0x10, 0xde, 0x80,
+	0xb9, 0x28, 0x16, 0x0e, 0x93, 0x92, 0xb9, 0x66, 0x55, 0x3f, 0xb5, 0x35, 0x21, 0xb5, 0xb3, 0xc5,
+	0x42, 0x15, 0x0b, 0xee, 0xd2, 0x6b, 0x72, 0x6b, 0x1d, 0xea, 0x05, 0x8c, 0x31, 0x54, 0x03, 0x66,
+	0x17, 0x62, 0xf4, 0x19, 0xdf, 0x81, 0xda, 0x09, 0xe3, 0x5e, 0x4f, 0xe5, 0x82, 0x72, 0xcb, 0x7a,
+	0x05, 0x8b, 0x5b, 0x62, 0x10, 0xaa, 0x5d, 0x1e, 0xe6, 0xc5, 0x6a, 0xc2, 0x7f, 0x2e, 0x8b, 0x54,
+	0x4f, 0x87, 0x2f, 0xd0, 0xcc, 0x48, 0xd1, 0x13, 0xee, 0xaa, 0xac, 0x20, 0x0b, 0x34, 0x33, 0x70,
+	0x0b, 0xea, 0x4e, 0x1a, 0xcd, 0x62, 0xa9, 0x3b, 0xb3, 0x40, 0x87, 0xb6, 0xf5, 0x15, 0x41, 0xf5,
+	0x40, 0x44, 0x4f, 0xf0, 0x43, 0xa8, 0x38, 0x81, 0x9c, 0xfc, 0x13, 0xca, 0xf7, 0xd2, 0x94, 0x84,
+	0x97, 0xa1, 0xea, 0x73, 0x99, 0x8a, 0x1c, 0x6b, 0x73, 0x9a, 0xa9, 0xa3, 0xdb, 0xac, 0x09, 0x69,
+	0x2d, 0x7b, 0xef, 0x22, 0x16, 0xfb, 0xc2, 0xf3, 0x85, 0xa7, 0x6b, 0x39, 0x4f, 0x47, 0xa1, 0xd6,
+	0x2a, 0x54, 0x53, 0x7e, 0xaa, 0x9c, 0x1d, 0xb3, 0x30, 0x6b, 0xfd, 0x1c, 0xcd, 0x8c, 0x14, 0xd5,
+	0x4a, 0x8b, 0xf7, 0x68, 0xc3, 0xfa, 0x8c, 0x00, 0xd2, 0x9b, 0xf2, 0x21, 0x5b, 0x1b, 0x1b, 0xb2,
+	0xa5, 0xb2, 0x9e, 0x8c, 0xd5, 0x29, 0x4f, 0x58, 0xeb, 0x39, 0xd4, 0xf2, 0x99, 0xb2, 0xa0, 0xaa,
+	0x44, 0xd4, 0xcf, 0x5f, 0xbe, 0x58, 0x0e, 0xa6, 0xda, 0x77, 0x83, 0x9f, 0x3f, 0x5d, 0x01, 0xdb,
+	0x5c, 0x2a, 0x1e, 0x3a, 0xea, 0xa6, 0x2b, 0xa0, 0xcc, 0x9f, 0x5c, 0x01, 0xd3, 0xfc, 0x7f, 0x5c,
+	0x01, 0xe5, 0x80, 0xf1, 0x15, 0xf0, 0x01, 0x41, 0x73, 0x1a, 0x63, 0xbc, 0x4b, 0x68, 0xa2, 0x4b,
+	0xff, 0x7a, 0xf8, 0x37, 0xdf, 0x9c, 0x5d, 0x10, 0xe3, 0xfc, 0x82, 0x18, 0x57, 0x17, 0x04, 0xbd,
+	0x4f, 0x08, 0xfa, 0x92, 0x10, 0xf4, 0x3d, 0x21, 0xe8, 0x2c, 0x21, 0xe8, 0x47, 0x42, 0xd0, 0xcf,
+	0x84, 0x18, 0x57, 0x09, 0x41, 0x9f, 0x2e, 0x89, 0x71, 0x76, 0x49, 0x8c, 0xf3, 0x4b, 0x62, 0xbc,
+	0x5e, 0xf6, 0xb8, 0xea, 0x0d, 0x0e, 0x3b, 0x8e, 0x08, 0xba, 0x5e, 0x6c, 0x1f, 0xd9, 0xa1, 0xdd,
+	0xf5, 0x45, 0x9f, 0x77, 0x8f, 0xd7, 0xba, 0xa3, 0xdb, 0xf9, 0xb0, 0xa6, 0x3f, 0x6b, 0xbf, 0x06,
+	0x00, 0xd2, 0xea, 0x93, 0x55, 0xd9, 0x05, 0x00, 0x00,
 }
 
 func (this *QuantileSketchMatrix) Equal(that interface{}) bool {
@@ -1088,6 +1238,99 @@ func (this *TopKMatrix_Vector) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *DistinctSketchMatrix) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DistinctSketchMatrix)
+	if !ok {
+		that2, ok := that.(DistinctSketchMatrix)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Values) != len(that1.Values) {
+		return false
+	}
+	for i := range this.Values {
+		if !this.Values[i].Equal(that1.Values[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *DistinctSketchVector) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DistinctSketchVector)
+	if !ok {
+		that2, ok := that.(DistinctSketchVector)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Samples) != len(that1.Samples) {
+		return false
+	}
+	for i := range this.Samples {
+		if !this.Samples[i].Equal(that1.Samples[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *DistinctSketchSample) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DistinctSketchSample)
+	if !ok {
+		that2, ok := that.(DistinctSketchSample)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.Hyperloglog, that1.Hyperloglog) {
+		return false
+	}
+	if this.TimestampMs != that1.TimestampMs {
+		return false
+	}
+	if len(this.Metric) != len(that1.Metric) {
+		return false
+	}
+	for i := range this.Metric {
+		if !this.Metric[i].Equal(that1.Metric[i]) {
+			return false
+		}
+	}
+	return true
+}
 func (this *QuantileSketchMatrix) GoString() string {
 	if this == nil {
 		return "nil"
@@ -1246,6 +1489,44 @@ func (this *TopKMatrix_Vector) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *DistinctSketchMatrix) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&logproto.DistinctSketchMatrix{")
+	if this.Values != nil {
+		s = append(s, "Values: "+fmt.Sprintf("%#v", this.Values)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DistinctSketchVector) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&logproto.DistinctSketchVector{")
+	if this.Samples != nil {
+		s = append(s, "Samples: "+fmt.Sprintf("%#v", this.Samples)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *DistinctSketchSample) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&logproto.DistinctSketchSample{")
+	s = append(s, "Hyperloglog: "+fmt.Sprintf("%#v", this.Hyperloglog)+",\n")
+	s = append(s, "TimestampMs: "+fmt.Sprintf("%#v", this.TimestampMs)+",\n")
+	if this.Metric != nil {
+		s = append(s, "Metric: "+fmt.Sprintf("%#v", this.Metric)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringSketch(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -1758,37 +2039,160 @@ func (m *TopKMatrix_Vector) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintSketch(dAtA []byte, offset int, v uint64) int {
-	offset -= sovSketch(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *DistinctSketchMatrix) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *QuantileSketchMatrix) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+
+func (m *DistinctSketchMatrix) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DistinctSketchMatrix) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
 	if len(m.Values) > 0 {
-		for _, e := range m.Values {
-			l = e.Size()
-			n += 1 + l + sovSketch(uint64(l))
+		for iNdEx := len(m.Values) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Values[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintSketch(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
 		}
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *QuantileSketchVector) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
+func (m *DistinctSketchVector) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DistinctSketchVector) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DistinctSketchVector) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Samples) > 0 {
+		for iNdEx := len(m.Samples) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Samples[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintSketch(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DistinctSketchSample) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DistinctSketchSample) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DistinctSketchSample) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Metric) > 0 {
+		for iNdEx := len(m.Metric) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Metric[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintSketch(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.TimestampMs != 0 {
+		i = encodeVarintSketch(dAtA, i, uint64(m.TimestampMs))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Hyperloglog) > 0 {
+		i -= len(m.Hyperloglog)
+		copy(dAtA[i:], m.Hyperloglog)
+		i = encodeVarintSketch(dAtA, i, uint64(len(m.Hyperloglog)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintSketch(dAtA []byte, offset int, v uint64) int {
+	offset -= sovSketch(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *QuantileSketchMatrix) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, e := range m.Values {
+			l = e.Size()
+			n += 1 + l + sovSketch(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *QuantileSketchVector) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
 	_ = l
 	if len(m.Samples) > 0 {
 		for _, e := range m.Samples {
@@ -1988,6 +2392,58 @@ func (m *TopKMatrix_Vector) Size() (n int) {
 	return n
 }
 
+func (m *DistinctSketchMatrix) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Values) > 0 {
+		for _, e := range m.Values {
+			l = e.Size()
+			n += 1 + l + sovSketch(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *DistinctSketchVector) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Samples) > 0 {
+		for _, e := range m.Samples {
+			l = e.Size()
+			n += 1 + l + sovSketch(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *DistinctSketchSample) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Hyperloglog)
+	if l > 0 {
+		n += 1 + l + sovSketch(uint64(l))
+	}
+	if m.TimestampMs != 0 {
+		n += 1 + sovSketch(uint64(m.TimestampMs))
+	}
+	if len(m.Metric) > 0 {
+		for _, e := range m.Metric {
+			l = e.Size()
+			n += 1 + l + sovSketch(uint64(l))
+		}
+	}
+	return n
+}
+
 func sovSketch(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -2166,6 +2622,53 @@ func (this *TopKMatrix_Vector) String() string {
 	}, "")
 	return s
 }
+func (this *DistinctSketchMatrix) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForValues := "[]*DistinctSketchVector{"
+	for _, f := range this.Values {
+		repeatedStringForValues += strings.Replace(f.String(), "DistinctSketchVector", "DistinctSketchVector", 1) + ","
+	}
+	repeatedStringForValues += "}"
+	s := strings.Join([]string{`&DistinctSketchMatrix{`,
+		`Values:` + repeatedStringForValues + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DistinctSketchVector) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForSamples := "[]*DistinctSketchSample{"
+	for _, f := range this.Samples {
+		repeatedStringForSamples += strings.Replace(f.String(), "DistinctSketchSample", "DistinctSketchSample", 1) + ","
+	}
+	repeatedStringForSamples += "}"
+	s := strings.Join([]string{`&DistinctSketchVector{`,
+		`Samples:` + repeatedStringForSamples + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *DistinctSketchSample) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForMetric := "[]*LabelPair{"
+	for _, f := range this.Metric {
+		repeatedStringForMetric += strings.Replace(fmt.Sprintf("%v", f), "LabelPair", "LabelPair", 1) + ","
+	}
+	repeatedStringForMetric += "}"
+	s := strings.Join([]string{`&DistinctSketchSample{`,
+		`Hyperloglog:` + fmt.Sprintf("%v", this.Hyperloglog) + `,`,
+		`TimestampMs:` + fmt.Sprintf("%v", this.TimestampMs) + `,`,
+		`Metric:` + repeatedStringForMetric + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringSketch(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -3429,6 +3932,320 @@ func (m *TopKMatrix_Vector) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *DistinctSketchMatrix) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSketch
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DistinctSketchMatrix: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DistinctSketchMatrix: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSketch
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, &DistinctSketchVector{})
+			if err := m.Values[len(m.Values)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSketch(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DistinctSketchVector) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSketch
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DistinctSketchVector: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DistinctSketchVector: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Samples", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSketch
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Samples = append(m.Samples, &DistinctSketchSample{})
+			if err := m.Samples[len(m.Samples)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSketch(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DistinctSketchSample) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSketch
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DistinctSketchSample: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DistinctSketchSample: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hyperloglog", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthSketch
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hyperloglog = append(m.Hyperloglog[:0], dAtA[iNdEx:postIndex]...)
+			if m.Hyperloglog == nil {
+				m.Hyperloglog = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TimestampMs", wireType)
+			}
+			m.TimestampMs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TimestampMs |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metric", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSketch
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSketch
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metric = append(m.Metric, &LabelPair{})
+			if err := m.Metric[len(m.Metric)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSketch(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthSketch
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipSketch(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
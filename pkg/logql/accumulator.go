@@ -7,6 +7,7 @@ import (
 	"sort"
 	"time"
 
+	promql_parser "github.com/prometheus/prometheus/promql/parser"
 	"golang.org/x/exp/maps"
 
 	"github.com/grafana/loki/v3/pkg/logproto"
@@ -39,30 +40,53 @@ func (a *BufferedAccumulator) Result() []logqlmodel.Result {
 	return a.results
 }
 
-type QuantileSketchAccumulator struct {
-	matrix ProbabilisticQuantileMatrix
+// sketchMatrix is a matrix of sketches returned by sharded probabilistic
+// queries, which can be merged with the matrix of another shard.
+type sketchMatrix[M any] interface {
+	promql_parser.Value
+	Merge(right M) (M, error)
+}
+
+type SketchAccumulator[M sketchMatrix[M]] struct {
+	matrix    M
+	hasMatrix bool
 
 	stats    stats.Result        // for accumulating statistics from downstream requests
 	headers  map[string][]string // for accumulating headers from downstream requests
 	warnings map[string]struct{} // for accumulating warnings from downstream requests}
 }
 
+type (
+	QuantileSketchAccumulator = SketchAccumulator[ProbabilisticQuantileMatrix]
+	DistinctSketchAccumulator = SketchAccumulator[DistinctSketchMatrix]
+)
+
 // newQuantileSketchAccumulator returns an accumulator for sharded
 // probabilistic quantile queries that merges results as they come in.
 func newQuantileSketchAccumulator() *QuantileSketchAccumulator {
-	return &QuantileSketchAccumulator{
+	return newSketchAccumulator[ProbabilisticQuantileMatrix]()
+}
+
+// newDistinctSketchAccumulator returns an accumulator for sharded
+// approximate distinct_over_time queries that merges results as they come in.
+func newDistinctSketchAccumulator() *DistinctSketchAccumulator {
+	return newSketchAccumulator[DistinctSketchMatrix]()
+}
+
+func newSketchAccumulator[M sketchMatrix[M]]() *SketchAccumulator[M] {
+	return &SketchAccumulator[M]{
 		headers:  make(map[string][]string),
 		warnings: make(map[string]struct{}),
 	}
 }
 
-func (a *QuantileSketchAccumulator) Accumulate(_ context.Context, res logqlmodel.Result, _ int) error {
-	if res.Data.Type() != QuantileSketchMatrixType {
-		return fmt.Errorf("unexpected matrix data type: got (%s), want (%s)", res.Data.Type(), QuantileSketchMatrixType)
+func (a *SketchAccumulator[M]) Accumulate(_ context.Context, res logqlmodel.Result, _ int) error {
+	if res.Data.Type() != a.matrix.Type() {
+		return fmt.Errorf("unexpected matrix data type: got (%s), want (%s)", res.Data.Type(), a.matrix.Type())
 	}
-	data, ok := res.Data.(ProbabilisticQuantileMatrix)
+	data, ok := res.Data.(M)
 	if !ok {
-		return fmt.Errorf("unexpected matrix type: got (%T), want (ProbabilisticQuantileMatrix)", res.Data)
+		return fmt.Errorf("unexpected matrix type: got (%T), want (%T)", res.Data, a.matrix)
 	}
 
 	// TODO(owen-d/ewelch): Shard counts should be set by the querier
@@ -79,8 +103,9 @@ func (a *QuantileSketchAccumulator) Accumulate(_ context.Context, res logqlmodel
 		a.warnings[w] = struct{}{}
 	}
 
-	if a.matrix == nil {
+	if !a.hasMatrix {
 		a.matrix = data
+		a.hasMatrix = true
 		return nil
 	}
 
@@ -90,7 +115,7 @@ func (a *QuantileSketchAccumulator) Accumulate(_ context.Context, res logqlmodel
 	return err
 }
 
-func (a *QuantileSketchAccumulator) Result() []logqlmodel.Result {
+func (a *SketchAccumulator[M]) Result() []logqlmodel.Result {
 	headers := make([]*definitions.PrometheusResponseHeader, 0, len(a.headers))
 	for name, vals := range a.headers {
 		headers = append(
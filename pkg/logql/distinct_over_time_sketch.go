@@ -0,0 +1,394 @@
+package logql
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/axiomhq/hyperloglog"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	promql_parser "github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+const (
+	DistinctSketchMatrixType = "DistinctSketchMatrix"
+)
+
+type (
+	DistinctSketchVector []DistinctSketchSample
+	DistinctSketchMatrix []DistinctSketchVector
+)
+
+// DistinctSketchSample holds a HyperLogLog sketch of the distinct values of a
+// series within a range.
+type DistinctSketchSample struct {
+	T int64
+	F *hyperloglog.Sketch
+
+	Metric labels.Labels
+}
+
+func newDistinctSketch() *hyperloglog.Sketch {
+	return hyperloglog.New14()
+}
+
+// distinctValueKey returns the key a value is counted as by
+// distinct_over_time. All NaNs are counted as a single value and negative zero
+// is counted as zero.
+func distinctValueKey(v float64) uint64 {
+	switch {
+	case math.IsNaN(v):
+		return math.Float64bits(math.NaN())
+	case v == 0:
+		return 0
+	default:
+		return math.Float64bits(v)
+	}
+}
+
+func (v DistinctSketchVector) Merge(right DistinctSketchVector) (DistinctSketchVector, error) {
+	// labels hash to vector index map
+	groups := streamHashPool.Get().(map[uint64]int)
+	defer func() {
+		clear(groups)
+		streamHashPool.Put(groups)
+	}()
+	for i, sample := range v {
+		groups[sample.Metric.Hash()] = i
+	}
+
+	for _, sample := range right {
+		i, ok := groups[sample.Metric.Hash()]
+		if !ok {
+			v = append(v, sample)
+			continue
+		}
+
+		if err := v[i].F.Merge(sample.F); err != nil {
+			return v, err
+		}
+	}
+
+	return v, nil
+}
+
+func (DistinctSketchVector) SampleVector() promql.Vector {
+	return promql.Vector{}
+}
+
+func (DistinctSketchVector) QuantileSketchVec() ProbabilisticQuantileVector {
+	return ProbabilisticQuantileVector{}
+}
+
+func (v DistinctSketchVector) DistinctSketchVec() DistinctSketchVector {
+	return v
+}
+
+func (v DistinctSketchVector) ToProto() (*logproto.DistinctSketchVector, error) {
+	samples := make([]*logproto.DistinctSketchSample, len(v))
+	for i, sample := range v {
+		s, err := sample.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		samples[i] = s
+	}
+	return &logproto.DistinctSketchVector{Samples: samples}, nil
+}
+
+func DistinctSketchVectorFromProto(proto *logproto.DistinctSketchVector) (DistinctSketchVector, error) {
+	out := make(DistinctSketchVector, len(proto.Samples))
+	for i, sample := range proto.Samples {
+		s, err := distinctSketchSampleFromProto(sample)
+		if err != nil {
+			return DistinctSketchVector{}, err
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+func (DistinctSketchMatrix) String() string {
+	return "DistinctSketchMatrix()"
+}
+
+func (m DistinctSketchMatrix) Merge(right DistinctSketchMatrix) (DistinctSketchMatrix, error) {
+	if len(m) != len(right) {
+		return nil, fmt.Errorf("failed to merge distinct sketch matrix: lengths differ %d!=%d", len(m), len(right))
+	}
+	var err error
+	for i, vec := range m {
+		m[i], err = vec.Merge(right[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge distinct sketch matrix: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+func (DistinctSketchMatrix) Type() promql_parser.ValueType { return DistinctSketchMatrixType }
+
+func (m DistinctSketchMatrix) ToProto() (*logproto.DistinctSketchMatrix, error) {
+	values := make([]*logproto.DistinctSketchVector, len(m))
+	for i, vec := range m {
+		v, err := vec.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return &logproto.DistinctSketchMatrix{Values: values}, nil
+}
+
+func DistinctSketchMatrixFromProto(proto *logproto.DistinctSketchMatrix) (DistinctSketchMatrix, error) {
+	out := make(DistinctSketchMatrix, len(proto.Values))
+	for i, v := range proto.Values {
+		vec, err := DistinctSketchVectorFromProto(v)
+		if err != nil {
+			return DistinctSketchMatrix{}, err
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func (s DistinctSketchSample) ToProto() (*logproto.DistinctSketchSample, error) {
+	metric := make([]*logproto.LabelPair, len(s.Metric))
+	for i, m := range s.Metric {
+		metric[i] = &logproto.LabelPair{Name: m.Name, Value: m.Value}
+	}
+
+	hll, err := s.F.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	return &logproto.DistinctSketchSample{
+		Hyperloglog: hll,
+		TimestampMs: s.T,
+		Metric:      metric,
+	}, nil
+}
+
+func distinctSketchSampleFromProto(proto *logproto.DistinctSketchSample) (DistinctSketchSample, error) {
+	s := newDistinctSketch()
+	if err := s.UnmarshalBinary(proto.Hyperloglog); err != nil {
+		return DistinctSketchSample{}, err
+	}
+	out := DistinctSketchSample{
+		T:      proto.TimestampMs,
+		F:      s,
+		Metric: make(labels.Labels, len(proto.Metric)),
+	}
+
+	for i, p := range proto.Metric {
+		out.Metric[i] = labels.Label{Name: p.Name, Value: p.Value}
+	}
+
+	return out, nil
+}
+
+type DistinctSketchStepEvaluator struct {
+	iter RangeVectorIterator
+
+	err error
+}
+
+func (e *DistinctSketchStepEvaluator) Next() (bool, int64, StepResult) {
+	next := e.iter.Next()
+	if !next {
+		return false, 0, DistinctSketchVector{}
+	}
+	ts, r := e.iter.At()
+	vec := r.DistinctSketchVec()
+	for _, s := range vec {
+		// Errors are not allowed in metrics unless they've been specifically requested.
+		if s.Metric.Has(logqlmodel.ErrorLabel) && s.Metric.Get(logqlmodel.PreserveErrorLabel) != trueString {
+			e.err = logqlmodel.NewPipelineErr(s.Metric)
+			return false, 0, DistinctSketchVector{}
+		}
+	}
+	return true, ts, vec
+}
+
+func (e *DistinctSketchStepEvaluator) Close() error { return e.iter.Close() }
+
+func (e *DistinctSketchStepEvaluator) Error() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.iter.Error()
+}
+
+func (e *DistinctSketchStepEvaluator) Explain(parent Node) {
+	parent.Child("DistinctSketch")
+}
+
+func newDistinctSketchIterator(
+	it iter.PeekingSampleIterator,
+	selRange, step, start, end, offset int64,
+) RangeVectorIterator {
+	inner := &batchRangeVectorIterator{
+		iter:     it,
+		step:     step,
+		end:      end,
+		selRange: selRange,
+		metrics:  map[string]labels.Labels{},
+		window:   map[string]*promql.Series{},
+		agg:      nil,
+		current:  start - step, // first loop iteration will set it to start
+		offset:   offset,
+	}
+	return &distinctSketchBatchRangeVectorIterator{
+		batchRangeVectorIterator: inner,
+	}
+}
+
+type distinctSketchBatchRangeVectorIterator struct {
+	*batchRangeVectorIterator
+	buf [8]byte
+}
+
+func (r *distinctSketchBatchRangeVectorIterator) At() (int64, StepResult) {
+	at := make(DistinctSketchVector, 0, len(r.window))
+	// convert ts from nano to milli seconds as the iterator work with nanoseconds
+	ts := r.current/1e+6 + r.offset/1e+6
+	for _, series := range r.window {
+		at = append(at, DistinctSketchSample{
+			F:      r.agg(series.Floats),
+			T:      ts,
+			Metric: series.Metric,
+		})
+	}
+	return ts, at
+}
+
+func (r *distinctSketchBatchRangeVectorIterator) agg(samples []promql.FPoint) *hyperloglog.Sketch {
+	s := newDistinctSketch()
+	for _, v := range samples {
+		binary.LittleEndian.PutUint64(r.buf[:], distinctValueKey(v.F))
+		s.Insert(r.buf[:])
+	}
+	return s
+}
+
+// MergeDistinctSketchVector joins the results from stepEvaluator into a DistinctSketchMatrix.
+func MergeDistinctSketchVector(next bool, r StepResult, stepEvaluator StepEvaluator, params Params) (promql_parser.Value, error) {
+	vec := r.DistinctSketchVec()
+	if stepEvaluator.Error() != nil {
+		return nil, stepEvaluator.Error()
+	}
+
+	if GetRangeType(params) == InstantType {
+		return DistinctSketchMatrix{vec}, nil
+	}
+
+	stepCount := int(math.Ceil(float64(params.End().Sub(params.Start()).Nanoseconds()) / float64(params.Step().Nanoseconds())))
+	if stepCount <= 0 {
+		stepCount = 1
+	}
+
+	result := make(DistinctSketchMatrix, 0, stepCount)
+
+	for next {
+		result = append(result, vec)
+		next, _, r = stepEvaluator.Next()
+		vec = r.DistinctSketchVec()
+		if stepEvaluator.Error() != nil {
+			return nil, stepEvaluator.Error()
+		}
+	}
+
+	return result, stepEvaluator.Error()
+}
+
+// DistinctSketchMatrixStepEvaluator steps through a matrix of HyperLogLog
+// sketch vectors.
+type DistinctSketchMatrixStepEvaluator struct {
+	start, end, ts time.Time
+	step           time.Duration
+	m              DistinctSketchMatrix
+}
+
+func NewDistinctSketchMatrixStepEvaluator(m DistinctSketchMatrix, params Params) *DistinctSketchMatrixStepEvaluator {
+	var (
+		start = params.Start()
+		end   = params.End()
+		step  = params.Step()
+	)
+	return &DistinctSketchMatrixStepEvaluator{
+		start: start,
+		end:   end,
+		ts:    start.Add(-step), // will be corrected on first Next() call
+		step:  step,
+		m:     m,
+	}
+}
+
+func (m *DistinctSketchMatrixStepEvaluator) Next() (bool, int64, StepResult) {
+	m.ts = m.ts.Add(m.step)
+	if m.ts.After(m.end) {
+		return false, 0, nil
+	}
+
+	ts := m.ts.UnixNano() / int64(time.Millisecond)
+
+	if len(m.m) == 0 {
+		return false, 0, nil
+	}
+
+	vec := m.m[0]
+
+	// Reset for next step
+	m.m = m.m[1:]
+
+	return true, ts, vec
+}
+
+func (*DistinctSketchMatrixStepEvaluator) Close() error { return nil }
+
+func (*DistinctSketchMatrixStepEvaluator) Error() error { return nil }
+
+func (*DistinctSketchMatrixStepEvaluator) Explain(parent Node) {
+	parent.Child("DistinctSketchMatrix")
+}
+
+// DistinctSketchVectorStepEvaluator evaluates HyperLogLog sketches into the
+// estimated number of distinct values.
+type DistinctSketchVectorStepEvaluator struct {
+	inner StepEvaluator
+}
+
+var _ StepEvaluator = NewDistinctSketchVectorStepEvaluator(nil)
+
+func NewDistinctSketchVectorStepEvaluator(inner StepEvaluator) *DistinctSketchVectorStepEvaluator {
+	return &DistinctSketchVectorStepEvaluator{
+		inner: inner,
+	}
+}
+
+func (e *DistinctSketchVectorStepEvaluator) Next() (bool, int64, StepResult) {
+	ok, ts, r := e.inner.Next()
+	if !ok {
+		return false, 0, SampleVector{}
+	}
+	vec := r.DistinctSketchVec()
+
+	result := make(promql.Vector, len(vec))
+	for i, s := range vec {
+		result[i] = promql.Sample{Metric: s.Metric, T: s.T, F: float64(s.F.Estimate())}
+	}
+
+	return ok, ts, SampleVector(result)
+}
+
+func (*DistinctSketchVectorStepEvaluator) Close() error { return nil }
+
+func (*DistinctSketchVectorStepEvaluator) Error() error { return nil }
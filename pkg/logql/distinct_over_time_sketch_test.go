@@ -0,0 +1,80 @@
+package logql
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/axiomhq/hyperloglog"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+func distinctSketchOf(values ...float64) *hyperloglog.Sketch {
+	s := newDistinctSketch()
+	var buf [8]byte
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], distinctValueKey(v))
+		s.Insert(buf[:])
+	}
+	return s
+}
+
+func TestDistinctSketchMatrixSerialization(t *testing.T) {
+	matrix := DistinctSketchMatrix{
+		DistinctSketchVector{
+			{T: 42, F: distinctSketchOf(1, 2, 3), Metric: labels.FromStrings("foo", "bar")},
+		},
+	}
+
+	proto, err := matrix.ToProto()
+	require.NoError(t, err)
+
+	actual, err := DistinctSketchMatrixFromProto(proto)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	require.Len(t, actual[0], 1)
+	require.Equal(t, int64(42), actual[0][0].T)
+	require.Equal(t, labels.FromStrings("foo", "bar"), actual[0][0].Metric)
+	require.Equal(t, uint64(3), actual[0][0].F.Estimate())
+}
+
+func TestDistinctSketchMatrixMerge(t *testing.T) {
+	left := DistinctSketchMatrix{
+		DistinctSketchVector{
+			{T: 1, F: distinctSketchOf(1, 2), Metric: labels.FromStrings("foo", "a")},
+		},
+	}
+	right := DistinctSketchMatrix{
+		DistinctSketchVector{
+			{T: 1, F: distinctSketchOf(2, 3), Metric: labels.FromStrings("foo", "a")},
+			{T: 1, F: distinctSketchOf(5), Metric: labels.FromStrings("foo", "b")},
+		},
+	}
+
+	merged, err := left.Merge(right)
+	require.NoError(t, err)
+	require.Len(t, merged[0], 2)
+	require.Equal(t, uint64(3), merged[0][0].F.Estimate())
+	require.Equal(t, uint64(1), merged[0][1].F.Estimate())
+
+	_, err = merged.Merge(DistinctSketchMatrix{})
+	require.Error(t, err)
+}
+
+func TestDistinctSketchStepEvaluatorError(t *testing.T) {
+	iter := errorRangeVectorIterator{
+		result: DistinctSketchVector{
+			{T: 43, F: nil, Metric: labels.Labels{{Name: logqlmodel.ErrorLabel, Value: "my error"}}},
+		},
+	}
+	ev := DistinctSketchStepEvaluator{
+		iter: iter,
+	}
+	ok, _, _ := ev.Next()
+	require.False(t, ok)
+
+	err := ev.Error()
+	require.ErrorContains(t, err, "my error")
+}
@@ -301,6 +301,36 @@ func (e *QuantileSketchMergeExpr) Walk(f syntax.WalkFn) {
 	}
 }
 
+// DistinctSketchEvalExpr merges the HyperLogLog sketches of its downstream
+// queries and evaluates them to the estimated number of distinct values.
+type DistinctSketchEvalExpr struct {
+	syntax.SampleExpr
+	downstreams []DownstreamSampleExpr
+}
+
+func (e DistinctSketchEvalExpr) String() string {
+	var sb strings.Builder
+	for i, d := range e.downstreams {
+		if i >= defaultMaxDepth {
+			break
+		}
+
+		if i > 0 {
+			sb.WriteString(" ++ ")
+		}
+
+		sb.WriteString(d.String())
+	}
+	return fmt.Sprintf("distinctSketchEval<%s>", sb.String())
+}
+
+func (e *DistinctSketchEvalExpr) Walk(f syntax.WalkFn) {
+	f(e)
+	for _, d := range e.downstreams {
+		d.Walk(f)
+	}
+}
+
 type MergeFirstOverTimeExpr struct {
 	syntax.SampleExpr
 	downstreams []DownstreamSampleExpr
@@ -527,6 +557,33 @@ func (ev *DownstreamEvaluator) NewStepEvaluator(
 		}
 		inner := NewQuantileSketchMatrixStepEvaluator(matrix, params)
 		return NewQuantileSketchVectorStepEvaluator(inner, *e.quantile), nil
+	case *DistinctSketchEvalExpr:
+		queries := make([]DownstreamQuery, len(e.downstreams))
+		for i, d := range e.downstreams {
+			queries[i] = DownstreamQuery{
+				Params: ParamsWithExpressionOverride{
+					Params:             ParamOverridesFromShard(params, d.shard),
+					ExpressionOverride: d.SampleExpr,
+				},
+			}
+		}
+
+		acc := newDistinctSketchAccumulator()
+		results, err := ev.Downstream(ctx, queries, acc)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(results) != 1 {
+			return nil, fmt.Errorf("unexpected results length for sharded distinct_over_time: got (%d), want (1)", len(results))
+		}
+
+		matrix, ok := results[0].Data.(DistinctSketchMatrix)
+		if !ok {
+			return nil, fmt.Errorf("unexpected matrix type: got (%T), want (DistinctSketchMatrix)", results[0].Data)
+		}
+		inner := NewDistinctSketchMatrixStepEvaluator(matrix, params)
+		return NewDistinctSketchVectorStepEvaluator(inner), nil
 	case *MergeFirstOverTimeExpr:
 		queries := make([]DownstreamQuery, len(e.downstreams))
 
@@ -74,6 +74,24 @@ func TestMappingEquivalence(t *testing.T) {
 		{`first_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, false, []string{ShardFirstOverTime}},
 		{`last_over_time({a=~".+"} | logfmt | unwrap value [1s])`, false, []string{ShardLastOverTime}},
 		{`last_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, false, []string{ShardLastOverTime}},
+		{`max_over_time(sum by (a) (rate({a=~".+"}[1s]))[5s:1s])`, false, nil},
+		{`avg_over_time(rate({a=~".+"}[2s])[10s:2s] offset 1s)`, false, nil},
+		{`sum(count_over_time(max(rate({a=~".+"}[1s]))[4s:]))`, false, nil},
+		{`histogram_over_time({a=~".+"} | logfmt | unwrap value [1s])`, false, nil},
+		{`histogram_over_time({a=~".+"} | logfmt | unwrap value [1s]) by (a)`, false, nil},
+		{`histogram_quantile(0.9, sum by (le) (histogram_over_time({a=~".+"} | logfmt | unwrap value [2s])))`, false, nil},
+		{`sum by (a) (rate({a=~".+"}[2s] @ 10))`, false, nil},
+		{`count_over_time({a=~".+"}[3s] offset 1s @ end())`, false, nil},
+		{`sum(rate({a=~".+"}[2s])) - sum(rate({a=~".+"}[2s] @ start()))`, false, nil},
+		{`changes_over_time({a=~".+"} | logfmt | unwrap value [2s])`, false, nil},
+		{`distinct_over_time({a=~".+"} | logfmt | unwrap value [2s])`, false, nil},
+		{`distinct_over_time({a=~".+"} | logfmt | unwrap value [2s]) by (a)`, true, []string{ShardDistinctOverTime}},
+		{`changes_over_time({a=~".+"} | logfmt | unwrap label(line) [2s])`, false, nil},
+		{`distinct_over_time({a=~".+"} | logfmt | unwrap label(line) [2s])`, false, nil},
+		{`distinct_over_time({a=~".+"} | logfmt | unwrap label(line) [2s]) by (a)`, true, []string{ShardDistinctOverTime}},
+		{`count_values("v", count_over_time({a=~".+"}[2s]))`, false, nil},
+		{`count_values by (a) ("v", count_over_time({a=~".+"}[2s]))`, false, nil},
+		{`count_values without (a) ("v", count_over_time({a=~".+"}[2s]))`, false, nil},
 		// topk prefers already-seen values in tiebreakers. Since the test data generates
 		// the same log lines for each series & the resulting promql.Vectors aren't deterministically
 		// sorted by labels, we don't expect this to pass.
@@ -351,205 +369,216 @@ func TestRangeMappingEquivalence(t *testing.T) {
 	for _, tc := range []struct {
 		query           string
 		splitByInterval time.Duration
+		approximate     bool
 	}{
 		// Range vector aggregators
-		{`bytes_over_time({a=~".+"}[2s])`, time.Second},
-		{`count_over_time({a=~".+"}[2s])`, time.Second},
-		{`sum_over_time({a=~".+"} | unwrap b [2s])`, time.Second},
-		{`max_over_time({a=~".+"} | unwrap b [2s])`, time.Second},
-		{`max_over_time({a=~".+"} | unwrap b [2s]) by (a)`, time.Second},
-		{`min_over_time({a=~".+"} | unwrap b [2s])`, time.Second},
-		{`min_over_time({a=~".+"} | unwrap b [2s]) by (a)`, time.Second},
-		{`rate({a=~".+"}[2s])`, time.Second},
-		{`rate({a=~".+"} | unwrap b [2s])`, time.Second},
-		{`bytes_rate({a=~".+"}[2s])`, time.Second},
+		{`bytes_over_time({a=~".+"}[2s])`, time.Second, false},
+		{`count_over_time({a=~".+"}[2s])`, time.Second, false},
+		{`sum_over_time({a=~".+"} | unwrap b [2s])`, time.Second, false},
+		{`max_over_time({a=~".+"} | unwrap b [2s])`, time.Second, false},
+		{`max_over_time({a=~".+"} | unwrap b [2s]) by (a)`, time.Second, false},
+		{`min_over_time({a=~".+"} | unwrap b [2s])`, time.Second, false},
+		{`min_over_time({a=~".+"} | unwrap b [2s]) by (a)`, time.Second, false},
+		{`rate({a=~".+"}[2s])`, time.Second, false},
+		{`rate({a=~".+"} | unwrap b [2s])`, time.Second, false},
+		{`bytes_rate({a=~".+"}[2s])`, time.Second, false},
 
 		// sum
-		{`sum(bytes_over_time({a=~".+"}[2s]))`, time.Second},
-		{`sum(count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`sum(sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`sum(max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`sum(max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`sum(min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`sum(min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`sum(rate({a=~".+"}[2s]))`, time.Second},
-		{`sum(rate({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`sum(bytes_rate({a=~".+"}[2s]))`, time.Second},
+		{`sum(bytes_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`sum(count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`sum(sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`sum(max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`sum(max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`sum(min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`sum(min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`sum(rate({a=~".+"}[2s]))`, time.Second, false},
+		{`sum(rate({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`sum(bytes_rate({a=~".+"}[2s]))`, time.Second, false},
+
+		// subqueries
+		{`max_over_time(count_over_time({a=~".+"}[2s])[5s:1s])`, time.Second, false},
+		// the rates are summed in a different order once split.
+		{`sum(avg_over_time(sum by (a) (rate({a=~".+"}[2s]))[5s:] offset 1s))`, time.Second, true},
 
 		// sum by
-		{`sum by (a) (bytes_over_time({a=~".+"}[2s]))`, time.Second},
-		{`sum by (a) (count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`sum by (a) (sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`sum by (a) (max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`sum by (a) (max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`sum by (a) (min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`sum by (a) (min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`sum by (a) (rate({a=~".+"}[2s]))`, time.Second},
-		{`sum by (a) (rate({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`sum by (a) (bytes_rate({a=~".+"}[2s]))`, time.Second},
+		{`sum by (a) (bytes_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`sum by (a) (count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`sum by (a) (sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`sum by (a) (max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`sum by (a) (max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`sum by (a) (min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`sum by (a) (min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`sum by (a) (rate({a=~".+"}[2s]))`, time.Second, false},
+		{`sum by (a) (rate({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`sum by (a) (bytes_rate({a=~".+"}[2s]))`, time.Second, false},
 
 		// count
-		{`count(bytes_over_time({a=~".+"}[2s]))`, time.Second},
-		{`count(count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`count(sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`count(max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`count(max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`count(min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`count(min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`count(rate({a=~".+"}[2s]))`, time.Second},
-		{`count(rate({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`count(bytes_rate({a=~".+"}[2s]))`, time.Second},
+		{`count(bytes_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`count(count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`count(sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`count(max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`count(max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`count(min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`count(min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`count(rate({a=~".+"}[2s]))`, time.Second, false},
+		{`count(rate({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`count(bytes_rate({a=~".+"}[2s]))`, time.Second, false},
 
 		// count by
-		{`count by (a) (bytes_over_time({a=~".+"}[2s]))`, time.Second},
-		{`count by (a) (count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`count by (a) (sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`count by (a) (max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`count by (a) (max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`count by (a) (min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`count by (a) (min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`count by (a) (rate({a=~".+"}[2s]))`, time.Second},
-		{`count by (a) (rate({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`count by (a) (bytes_rate({a=~".+"}[2s]))`, time.Second},
+		{`count by (a) (bytes_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`count by (a) (count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`count by (a) (sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`count by (a) (max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`count by (a) (max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`count by (a) (min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`count by (a) (min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`count by (a) (rate({a=~".+"}[2s]))`, time.Second, false},
+		{`count by (a) (rate({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`count by (a) (bytes_rate({a=~".+"}[2s]))`, time.Second, false},
 
 		// max
-		{`max(bytes_over_time({a=~".+"}[2s]))`, time.Second},
-		{`max(count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`max(sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`max(max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`max(max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`max(min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`max(min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`max(rate({a=~".+"}[2s]))`, time.Second},
-		{`max(rate({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`max(bytes_rate({a=~".+"}[2s]))`, time.Second},
+		{`max(bytes_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`max(count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`max(sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`max(max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`max(max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`max(min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`max(min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`max(rate({a=~".+"}[2s]))`, time.Second, false},
+		{`max(rate({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`max(bytes_rate({a=~".+"}[2s]))`, time.Second, false},
 
 		// max by
-		{`max by (a) (bytes_over_time({a=~".+"}[2s]))`, time.Second},
-		{`max by (a) (count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`max by (a) (sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`max by (a) (max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`max by (a) (max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`max by (a) (min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`max by (a) (min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`max by (a) (rate({a=~".+"}[2s]))`, time.Second},
-		{`max by (a) (rate({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`max by (a) (bytes_rate({a=~".+"}[2s]))`, time.Second},
+		{`max by (a) (bytes_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`max by (a) (count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`max by (a) (sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`max by (a) (max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`max by (a) (max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`max by (a) (min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`max by (a) (min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`max by (a) (rate({a=~".+"}[2s]))`, time.Second, false},
+		{`max by (a) (rate({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`max by (a) (bytes_rate({a=~".+"}[2s]))`, time.Second, false},
 
 		// min
-		{`min(bytes_over_time({a=~".+"}[2s]))`, time.Second},
-		{`min(count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`min(sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`min(max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`min(max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`min(min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`min(min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`min(rate({a=~".+"}[2s]))`, time.Second},
-		{`min(rate({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`min(bytes_rate({a=~".+"}[2s]))`, time.Second},
+		{`min(bytes_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`min(count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`min(sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`min(max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`min(max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`min(min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`min(min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`min(rate({a=~".+"}[2s]))`, time.Second, false},
+		{`min(rate({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`min(bytes_rate({a=~".+"}[2s]))`, time.Second, false},
 
 		// min by
-		{`min by (a) (bytes_over_time({a=~".+"}[2s]))`, time.Second},
-		{`min by (a) (count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`min by (a) (sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`min by (a) (max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`min by (a) (max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`min by (a) (min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`min by (a) (min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second},
-		{`min by (a) (rate({a=~".+"}[2s]))`, time.Second},
-		{`min by (a) (rate({a=~".+"} | unwrap b [2s]))`, time.Second},
-		{`min by (a) (bytes_rate({a=~".+"}[2s]))`, time.Second},
+		{`min by (a) (bytes_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`min by (a) (count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`min by (a) (sum_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`min by (a) (max_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`min by (a) (max_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`min by (a) (min_over_time({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`min by (a) (min_over_time({a=~".+"} | unwrap b [2s]) by (a))`, time.Second, false},
+		{`min by (a) (rate({a=~".+"}[2s]))`, time.Second, false},
+		{`min by (a) (rate({a=~".+"} | unwrap b [2s]))`, time.Second, false},
+		{`min by (a) (bytes_rate({a=~".+"}[2s]))`, time.Second, false},
 
 		// Label extraction stage
-		{`max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a)`, time.Second},
-		{`min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a)`, time.Second},
-
-		{`sum(bytes_over_time({a=~".+"} | logfmt | line > 5 [2s]))`, time.Second},
-		{`sum(count_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`sum(sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`sum(max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`sum(max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`sum(min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`sum(min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`sum(rate({a=~".+"} | logfmt[2s]))`, time.Second},
-		{`sum(rate({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`sum(bytes_rate({a=~".+"} | logfmt[2s]))`, time.Second},
-		{`sum by (a) (bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`sum by (a) (count_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`sum by (a) (sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`sum by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`sum by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`sum by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`sum by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`sum by (a) (rate({a=~".+"} | logfmt[2s]))`, time.Second},
-		{`sum by (a) (rate({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`sum by (a) (bytes_rate({a=~".+"} | logfmt[2s]))`, time.Second},
-
-		{`count(max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`count(min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`count by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`count by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-
-		{`max(bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`max(count_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`max(sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`max(max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`max(max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`max(min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`max(min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`max by (a) (bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`max by (a) (count_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`max by (a) (sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`max by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`max by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`max by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`max by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-
-		{`min(bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`min(count_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`min(sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`min(max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`min(max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`min(min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`min(min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`min by (a) (bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`min by (a) (count_over_time({a=~".+"} | logfmt [2s]))`, time.Second},
-		{`min by (a) (sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`min by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`min by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
-		{`min by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second},
-		{`min by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second},
+		{`max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a)`, time.Second, false},
+		{`min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a)`, time.Second, false},
+
+		{`sum(bytes_over_time({a=~".+"} | logfmt | line > 5 [2s]))`, time.Second, false},
+		{`sum(count_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`sum(sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`sum(max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`sum(max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`sum(min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`sum(min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`sum(rate({a=~".+"} | logfmt[2s]))`, time.Second, false},
+		{`sum(rate({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`sum(bytes_rate({a=~".+"} | logfmt[2s]))`, time.Second, false},
+		{`sum by (a) (bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`sum by (a) (count_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`sum by (a) (sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`sum by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`sum by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`sum by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`sum by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`sum by (a) (rate({a=~".+"} | logfmt[2s]))`, time.Second, false},
+		{`sum by (a) (rate({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`sum by (a) (bytes_rate({a=~".+"} | logfmt[2s]))`, time.Second, false},
+
+		{`count(max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`count(min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`count by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`count by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+
+		{`max(bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`max(count_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`max(sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`max(max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`max(max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`max(min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`max(min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`max by (a) (bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`max by (a) (count_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`max by (a) (sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`max by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`max by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`max by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`max by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+
+		{`min(bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`min(count_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`min(sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`min(max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`min(max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`min(min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`min(min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`min by (a) (bytes_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`min by (a) (count_over_time({a=~".+"} | logfmt [2s]))`, time.Second, false},
+		{`min by (a) (sum_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`min by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`min by (a) (max_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
+		{`min by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]))`, time.Second, false},
+		{`min by (a) (min_over_time({a=~".+"} | logfmt | unwrap line [2s]) by (a))`, time.Second, false},
 
 		// Binary operations
-		{`2 * bytes_over_time({a=~".+"}[3s])`, time.Second},
-		{`count_over_time({a=~".+"}[3s]) * 2`, time.Second},
-		{`bytes_over_time({a=~".+"}[3s]) + count_over_time({a=~".+"}[5s])`, time.Second},
-		{`sum(count_over_time({a=~".+"}[3s]) * count(sum_over_time({a=~".+"} | unwrap b [5s])))`, time.Second},
-		{`sum by (a) (count_over_time({a=~".+"} | logfmt | line > 5 [3s])) / sum by (a) (count_over_time({a=~".+"} [3s]))`, time.Second},
+		{`2 * bytes_over_time({a=~".+"}[3s])`, time.Second, false},
+		{`count_over_time({a=~".+"}[3s]) * 2`, time.Second, false},
+		{`bytes_over_time({a=~".+"}[3s]) + count_over_time({a=~".+"}[5s])`, time.Second, false},
+		{`sum(count_over_time({a=~".+"}[3s]) * count(sum_over_time({a=~".+"} | unwrap b [5s])))`, time.Second, false},
+		{`sum by (a) (count_over_time({a=~".+"} | logfmt | line > 5 [3s])) / sum by (a) (count_over_time({a=~".+"} [3s]))`, time.Second, false},
 
 		// Multi vector aggregator layer queries
-		{`sum(max(bytes_over_time({a=~".+"}[3s])))`, time.Second},
-		{`sum(min by (a)(max(sum by (b) (count_over_time({a=~".+"} [2s])))))`, time.Second},
+		{`sum(max(bytes_over_time({a=~".+"}[3s])))`, time.Second, false},
+		{`sum(min by (a)(max(sum by (b) (count_over_time({a=~".+"} [2s])))))`, time.Second, false},
 
 		// Non-splittable vector aggregators
 		// TODO: Fix topk
-		//{`topk(2, count_over_time({a=~".+"}[2s]))`, time.Second},
-		{`avg(count_over_time({a=~".+"}[2s]))`, time.Second},
+		//{`topk(2, count_over_time({a=~".+"}[2s]))`, time.Second, false},
+		{`avg(count_over_time({a=~".+"}[2s]))`, time.Second, false},
 
 		// Uneven split times
-		{`bytes_over_time({a=~".+"}[3s])`, 2 * time.Second},
-		{`count_over_time({a=~".+"}[5s])`, 2 * time.Second},
+		{`bytes_over_time({a=~".+"}[3s])`, 2 * time.Second, false},
+		{`count_over_time({a=~".+"}[5s])`, 2 * time.Second, false},
 
 		// range with offset
-		{`rate({a=~".+"}[2s] offset 2s)`, time.Second},
-		{`rate({a=~".+"}[4s] offset 1s)`, 2 * time.Second},
-		{`rate({a=~".+"}[3s] offset 1s)`, 2 * time.Second},
-		{`rate({a=~".+"}[5s] offset 0s)`, 2 * time.Second},
-		{`rate({a=~".+"}[3s] offset -1s)`, 2 * time.Second},
+		{`rate({a=~".+"}[2s] offset 2s)`, time.Second, false},
+		{`rate({a=~".+"}[4s] offset 1s)`, 2 * time.Second, false},
+		{`rate({a=~".+"}[3s] offset 1s)`, 2 * time.Second, false},
+		{`rate({a=~".+"}[5s] offset 0s)`, 2 * time.Second, false},
+		{`rate({a=~".+"}[3s] offset -1s)`, 2 * time.Second, false},
+
+		// range with @ modifier
+		{`rate({a=~".+"}[4s] @ 10)`, 2 * time.Second, false},
+		{`sum by (a) (count_over_time({a=~".+"}[5s] offset 1s @ 12))`, 2 * time.Second, false},
+		{`max_over_time({a=~".+"} | unwrap b [3s] @ end())`, time.Second, false},
 
 		// label_replace
-		{`label_replace(sum by (a) (count_over_time({a=~".+"}[3s])), "", "", "", "")`, time.Second},
-		{`label_replace(sum by (a) (count_over_time({a=~".+"}[3s])), "foo", "$1", "a", "(.*)")`, time.Second},
+		{`label_replace(sum by (a) (count_over_time({a=~".+"}[3s])), "", "", "", "")`, time.Second, false},
+		{`label_replace(sum by (a) (count_over_time({a=~".+"}[3s])), "foo", "$1", "a", "(.*)")`, time.Second, false},
 	} {
 		q := NewMockQuerier(
 			shards,
@@ -593,7 +622,11 @@ func TestRangeMappingEquivalence(t *testing.T) {
 			rangeRes, err := rangeQry.Exec(ctx)
 			require.Nil(t, err)
 
-			require.Equal(t, res.Data, rangeRes.Data)
+			if tc.approximate {
+				approximatelyEquals(t, res.Data.(promql.Matrix), rangeRes.Data.(promql.Matrix))
+			} else {
+				require.Equal(t, res.Data, rangeRes.Data)
+			}
 		})
 	}
 }
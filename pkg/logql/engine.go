@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -219,6 +220,8 @@ func (q *query) resultLength(res promql_parser.Value) int {
 		return int(r.Lines())
 	case ProbabilisticQuantileMatrix:
 		return len(r)
+	case DistinctSketchMatrix:
+		return len(r)
 	default:
 		// for `scalar` or `string` or any other return type, we just return `0` as result length.
 		return 0
@@ -299,13 +302,23 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 		return nil, logqlmodel.ErrBlocked
 	}
 
-	switch e := q.params.GetExpression().(type) {
+	// macros are usually expanded by the query frontend, but queries can also
+	// be sent to queriers directly or evaluated by the ruler.
+	expr, expanded, err := ExpandQueryMacros(ctx, q.params.GetExpression(), q.limits)
+	if err != nil {
+		return nil, err
+	}
+	if expanded {
+		q.params = ParamsWithExpressionOverride{Params: q.params, ExpressionOverride: expr}
+	}
+
+	switch e := expr.(type) {
 	case syntax.SampleExpr:
 		value, err := q.evalSample(ctx, e)
 		return value, err
 
 	case syntax.LogSelectorExpr:
-		itr, err := q.evaluator.NewIterator(ctx, e, q.params)
+		itr, err := q.newLogIterator(ctx, e, q.params)
 		if err != nil {
 			return nil, err
 		}
@@ -323,6 +336,56 @@ func (q *query) Eval(ctx context.Context) (promql_parser.Value, error) {
 	}
 }
 
+// newLogIterator returns the entries of a log query. Joins are evaluated by the
+// engine, any other log selector is passed to the evaluator.
+func (q *query) newLogIterator(ctx context.Context, expr syntax.LogSelectorExpr, params Params) (iter.EntryIterator, error) {
+	join, ok := expr.(*syntax.JoinExpr)
+	if !ok {
+		return q.evaluator.NewIterator(ctx, expr, params)
+	}
+
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	maxEntriesCapture := func(id string) int { return q.limits.MaxQueryJoinEntries(ctx, id) }
+	maxEntries := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, maxEntriesCapture)
+	limit := uint32(math.MaxUint32)
+	if maxEntries > 0 {
+		// read one more entry than allowed to detect when the limit is exceeded.
+		limit = uint32(maxEntries) + 1
+	}
+
+	// The right-hand side is selected over the query range extended by the
+	// join window on both ends, so that entries at the edges can be matched.
+	right, err := q.newLogIterator(ctx, join.Right, ParamsWithLimitOverride{
+		Params: ParamsWithTimeRangeOverride{
+			Params:        params,
+			StartOverride: params.Start().Add(-join.Within),
+			EndOverride:   params.End().Add(join.Within),
+			StepOverride:  params.Step(),
+		},
+		LimitOverride: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := newJoinKeyer(join.On)
+	index, err := newJoinIndex(right, keys, maxEntries)
+	util.LogErrorWithContext(ctx, "closing iterator", right.Close)
+	if err != nil {
+		return nil, err
+	}
+
+	// The left-hand side is streamed, so it isn't limited by the join limit.
+	// The query limit only applies to the joined entries.
+	left, err := q.newLogIterator(ctx, join.Left, ParamsWithLimitOverride{Params: params, LimitOverride: math.MaxUint32})
+	if err != nil {
+		return nil, err
+	}
+	return newJoinIterator(left, index, keys, join.Within), nil
+}
+
 func (q *query) checkBlocked(ctx context.Context, tenants []string) bool {
 	blocker := newQueryBlocker(ctx, q)
 
@@ -363,6 +426,7 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 	if err != nil {
 		return nil, err
 	}
+	syntax.ResolveAtModifiers(expr, q.params.Start(), q.params.End())
 
 	stepEvaluator, err := q.evaluator.NewStepEvaluator(ctx, q.evaluator, expr, q.params)
 	if err != nil {
@@ -387,6 +451,8 @@ func (q *query) evalSample(ctx context.Context, expr syntax.SampleExpr) (promql_
 			return q.JoinSampleVector(next, vec, stepEvaluator, maxSeries, mfl)
 		case ProbabilisticQuantileVector:
 			return MergeQuantileSketchVector(next, vec, stepEvaluator, q.params)
+		case DistinctSketchVector:
+			return MergeDistinctSketchVector(next, vec, stepEvaluator, q.params)
 		default:
 			return nil, fmt.Errorf("unsupported result type: %T", r)
 		}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/iter"
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/sketch"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
@@ -952,6 +953,84 @@ func TestEngine_InstantQuery(t *testing.T) {
 				{T: 60 * 1000, F: 0, Metric: labels.FromStrings("app", "foo", "machine", "fuzz", "pool", "foo")},
 			},
 		},
+		{
+			`sum_over_time(count_over_time({app="foo"}[10s])[1m:10s])`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, factor(20, identity), `{app="foo"}`)}, // 0, 20, 40, 60 ...
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(-10, 0), End: time.Unix(60, 0), Selector: `count_over_time({app="foo"}[10s])`}},
+			},
+			// the inner query is evaluated at 0s, 10s, ..., 60s and only has samples at 20s, 40s and 60s within (0s, 60s]
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 3, Metric: labels.FromStrings("app", "foo")}},
+		},
+		{
+			`max_over_time(sum by (app) (count_over_time({app="foo"}[30s]))[1m:] offset 30s)`, time.Unix(90, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, factor(10, identity), `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(-30, 0), End: time.Unix(60, 0), Selector: `sum by (app) (count_over_time({app="foo"}[30s]))`}},
+			},
+			// instant subqueries without a step are evaluated at a resolution of 1m, so only at 60s
+			promql.Vector{promql.Sample{T: 90 * 1000, F: 3, Metric: labels.FromStrings("app", "foo")}},
+		},
+		{
+			`histogram_over_time({app="foo"} | unwrap foo [30s])`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, incValue(0), `{app="foo"}`)}, // values are equal to the timestamps in seconds
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(60, 0), Selector: `histogram_over_time({app="foo"} | unwrap foo [30s])`}},
+			},
+			// 31 and 32 are <= 32, the other 28 values are <= 64
+			histogramVector(60*1000, labels.FromStrings("app", "foo"),
+				sketch.HistogramBucket{UpperBound: 32, Count: 2},
+				sketch.HistogramBucket{UpperBound: 64, Count: 30},
+			),
+		},
+		{
+			`histogram_quantile(0.5, sum by (le) (histogram_over_time({app="foo"} | unwrap foo [30s])))`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, incValue(0), `{app="foo"}`), newSeries(testSize, incValue(0), `{app="bar"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(60, 0), Selector: `sum by (le) (histogram_over_time({app="foo"} | unwrap foo [30s]))`}},
+			},
+			// the median (rank 30 out of 60) is interpolated within the (32, 64] bucket holding 56 values: 32 + 32 * 26/56
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 46.85714285714286, Metric: labels.EmptyLabels()}},
+		},
+		{
+			`changes_over_time({app="foo"} | unwrap foo [30s])`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, incValue(0), `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(60, 0), Selector: `changes_over_time({app="foo"} | unwrap foo [30s])`}},
+			},
+			// 30 increasing values change 29 times
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 29, Metric: labels.FromStrings("app", "foo")}},
+		},
+		{
+			`distinct_over_time({app="foo"} | unwrap foo [30s])`, time.Unix(60, 0), logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, constantValue(2), `{app="foo"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(30, 0), End: time.Unix(60, 0), Selector: `distinct_over_time({app="foo"} | unwrap foo [30s])`}},
+			},
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 1, Metric: labels.FromStrings("app", "foo")}},
+		},
+		{
+			`count_values("count", count_over_time({app=~"foo|bar"} |~".+bar" [1m]))`, time.Unix(60, 0), logproto.FORWARD, 100,
+			[][]logproto.Series{
+				{newSeries(testSize, factor(10, identity), `{app="foo"}`), newSeries(testSize, factor(10, identity), `{app="bar"}`)},
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(0, 0), End: time.Unix(60, 0), Selector: `count_over_time({app=~"foo|bar"}|~".+bar"[1m])`}},
+			},
+			promql.Vector{promql.Sample{T: 60 * 1000, F: 2, Metric: labels.FromStrings("count", "6")}},
+		},
 	} {
 		t.Run(fmt.Sprintf("%s %s", test.qs, test.direction), func(t *testing.T) {
 			eng := NewEngine(EngineOpts{}, newQuerierRecorder(t, test.data, test.params), NoLimits, log.NewNopLogger())
@@ -2253,6 +2332,53 @@ func TestEngine_RangeQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			`count_over_time(count_over_time({app="foo"}[10s])[30s:10s])`, time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, factor(20, identity), `{app="foo"}`)}, // 0, 20, 40, 60 ...
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(20, 0), End: time.Unix(120, 0), Selector: `count_over_time({app="foo"}[10s])`}},
+			},
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 2}, {T: 90 * 1000, F: 1}, {T: 120 * 1000, F: 2}},
+				},
+			},
+		},
+		{
+			`count_over_time({app="foo"}[10s] @ 30)`, time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, factor(5, identity), `{app="foo"}`)}, // 0, 5, 10, 15 ...
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(20, 0), End: time.Unix(30, 0), Selector: `count_over_time({app="foo"}[10s] @ 30.000)`}},
+			},
+			// 25 and 30 are counted at every step
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 2}, {T: 90 * 1000, F: 2}, {T: 120 * 1000, F: 2}},
+				},
+			},
+		},
+		{
+			`sum by (app) (count_over_time({app="foo"}[20s] offset 10s @ end()))`, time.Unix(60, 0), time.Unix(120, 0), 30 * time.Second, 0, logproto.FORWARD, 10,
+			[][]logproto.Series{
+				{newSeries(testSize, factor(5, identity), `{app="foo"}`)}, // 0, 5, 10, 15 ...
+			},
+			[]SelectSampleParams{
+				{&logproto.SampleQueryRequest{Start: time.Unix(90, 0), End: time.Unix(110, 0), Selector: `sum by (app) (count_over_time({app="foo"}[20s] offset 10s @ 120.000))`}},
+			},
+			// 95, 100, 105 and 110 are counted at every step
+			promql.Matrix{
+				promql.Series{
+					Metric: labels.FromStrings("app", "foo"),
+					Floats: []promql.FPoint{{T: 60 * 1000, F: 4}, {T: 90 * 1000, F: 4}, {T: 120 * 1000, F: 4}},
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("%s %s", test.qs, test.direction), func(t *testing.T) {
 			t.Parallel()
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -204,6 +205,33 @@ func (p ParamsWithShardsOverride) Shards() []string {
 	return p.ShardsOverride
 }
 
+// ParamsWithTimeRangeOverride overrides the start, end and step of the query.
+// It is used to evaluate the inner expression of a subquery at the subquery
+// resolution.
+type ParamsWithTimeRangeOverride struct {
+	Params
+	StartOverride, EndOverride time.Time
+	StepOverride               time.Duration
+}
+
+// Start returns the overwriting start time.
+func (p ParamsWithTimeRangeOverride) Start() time.Time { return p.StartOverride }
+
+// End returns the overwriting end time.
+func (p ParamsWithTimeRangeOverride) End() time.Time { return p.EndOverride }
+
+// Step returns the overwriting step.
+func (p ParamsWithTimeRangeOverride) Step() time.Duration { return p.StepOverride }
+
+// ParamsWithLimitOverride overrides the entries limit of the query.
+type ParamsWithLimitOverride struct {
+	Params
+	LimitOverride uint32
+}
+
+// Limit returns the overwriting limit.
+func (p ParamsWithLimitOverride) Limit() uint32 { return p.LimitOverride }
+
 type ParamsWithChunkOverrides struct {
 	Params
 	StoreChunksOverride *logproto.ChunkRefGroup
@@ -329,12 +357,13 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 			// if range expression is wrapped with a vector expression
 			// we should send the vector expression for allowing reducing labels at the source.
 			nextEvFactory = SampleEvaluatorFunc(func(ctx context.Context, _ SampleEvaluatorFactory, _ syntax.SampleExpr, _ Params) (StepEvaluator, error) {
+				rq := rangeAggParams(rangExpr.Left, q)
 				it, err := ev.querier.SelectSamples(ctx, SelectSampleParams{
 					&logproto.SampleQueryRequest{
 						// extend startTs backwards by step
-						Start: q.Start().Add(-rangExpr.Left.Interval).Add(-rangExpr.Left.Offset),
+						Start: rq.Start().Add(-rangExpr.Left.Interval).Add(-rangExpr.Left.Offset),
 						// add leap nanosecond to endTs to include lines exactly at endTs. range iterators work on start exclusive, end inclusive ranges
-						End: q.End().Add(-rangExpr.Left.Offset).Add(time.Nanosecond),
+						End: rq.End().Add(-rangExpr.Left.Offset).Add(time.Nanosecond),
 						// intentionally send the vector for reducing labels.
 						Selector: e.String(),
 						Shards:   q.Shards(),
@@ -347,17 +376,18 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 				if err != nil {
 					return nil, err
 				}
-				return newRangeAggEvaluator(iter.NewPeekingSampleIterator(it), rangExpr, q, rangExpr.Left.Offset)
+				return newPinnableRangeAggEvaluator(iter.NewPeekingSampleIterator(it), rangExpr, q, rq)
 			})
 		}
 		return newVectorAggEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.RangeAggregationExpr:
+		rq := rangeAggParams(e.Left, q)
 		it, err := ev.querier.SelectSamples(ctx, SelectSampleParams{
 			&logproto.SampleQueryRequest{
 				// extend startTs backwards by step
-				Start: q.Start().Add(-e.Left.Interval).Add(-e.Left.Offset),
+				Start: rq.Start().Add(-e.Left.Interval).Add(-e.Left.Offset),
 				// add leap nanosecond to endTs to include lines exactly at endTs. range iterators work on start exclusive, end inclusive ranges
-				End: q.End().Add(-e.Left.Offset).Add(time.Nanosecond),
+				End: rq.End().Add(-e.Left.Offset).Add(time.Nanosecond),
 				// intentionally send the vector for reducing labels.
 				Selector: e.String(),
 				Shards:   q.Shards(),
@@ -370,11 +400,15 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 		if err != nil {
 			return nil, err
 		}
-		return newRangeAggEvaluator(iter.NewPeekingSampleIterator(it), e, q, e.Left.Offset)
+		return newPinnableRangeAggEvaluator(iter.NewPeekingSampleIterator(it), e, q, rq)
 	case *syntax.BinOpExpr:
 		return newBinOpStepEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.LabelReplaceExpr:
 		return newLabelReplaceEvaluator(ctx, nextEvFactory, e, q)
+	case *syntax.SubqueryExpr:
+		return newSubqueryEvaluator(ctx, nextEvFactory, e, q)
+	case *syntax.HistogramQuantileExpr:
+		return newHistogramQuantileEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.VectorExpr:
 		val, err := e.Value()
 		if err != nil {
@@ -401,9 +435,16 @@ func newVectorAggEvaluator(
 	}
 	sort.Strings(expr.Grouping.Groups)
 
+	grouping := expr.Grouping
+	if expr.Operation == syntax.OpTypeCountValues {
+		grouping = expr.CountValuesGrouping()
+		sort.Strings(grouping.Groups)
+	}
+
 	return &VectorAggEvaluator{
 		nextEvaluator: nextEvaluator,
 		expr:          expr,
+		grouping:      grouping,
 		buf:           make([]byte, 0, 1024),
 		lb:            labels.NewBuilder(nil),
 	}, nil
@@ -412,8 +453,11 @@ func newVectorAggEvaluator(
 type VectorAggEvaluator struct {
 	nextEvaluator StepEvaluator
 	expr          *syntax.VectorAggregationExpr
-	buf           []byte
-	lb            *labels.Builder
+	// grouping is the grouping of the resulting series, which for count_values
+	// includes the value label.
+	grouping *syntax.Grouping
+	buf      []byte
+	lb       *labels.Builder
 }
 
 func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
@@ -431,27 +475,32 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 	}
 	for _, s := range vec {
 		metric := s.Metric
+		if e.expr.Operation == syntax.OpTypeCountValues {
+			e.lb.Reset(metric)
+			e.lb.Set(e.expr.ValueLabel, strconv.FormatFloat(s.F, 'f', -1, 64))
+			metric = e.lb.Labels()
+		}
 
 		var groupingKey uint64
-		if e.expr.Grouping.Without {
-			groupingKey, e.buf = metric.HashWithoutLabels(e.buf, e.expr.Grouping.Groups...)
+		if e.grouping.Without {
+			groupingKey, e.buf = metric.HashWithoutLabels(e.buf, e.grouping.Groups...)
 		} else {
-			groupingKey, e.buf = metric.HashForLabels(e.buf, e.expr.Grouping.Groups...)
+			groupingKey, e.buf = metric.HashForLabels(e.buf, e.grouping.Groups...)
 		}
 		group, ok := result[groupingKey]
 		// Add a new group if it doesn't exist.
 		if !ok {
 			var m labels.Labels
 
-			if e.expr.Grouping.Without {
+			if e.grouping.Without {
 				e.lb.Reset(metric)
-				e.lb.Del(e.expr.Grouping.Groups...)
+				e.lb.Del(e.grouping.Groups...)
 				e.lb.Del(labels.MetricName)
 				m = e.lb.Labels()
 			} else {
-				m = make(labels.Labels, 0, len(e.expr.Grouping.Groups))
+				m = make(labels.Labels, 0, len(e.grouping.Groups))
 				for _, l := range metric {
-					for _, n := range e.expr.Grouping.Groups {
+					for _, n := range e.grouping.Groups {
 						if l.Name == n {
 							m = append(m, l)
 							break
@@ -519,7 +568,7 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 				group.value = s.F
 			}
 
-		case syntax.OpTypeCount:
+		case syntax.OpTypeCount, syntax.OpTypeCountValues:
 			group.groupCount++
 
 		case syntax.OpTypeStddev, syntax.OpTypeStdvar:
@@ -569,7 +618,7 @@ func (e *VectorAggEvaluator) Next() (bool, int64, StepResult) {
 		case syntax.OpTypeAvg:
 			aggr.value = aggr.mean
 
-		case syntax.OpTypeCount:
+		case syntax.OpTypeCount, syntax.OpTypeCountValues:
 			aggr.value = float64(aggr.groupCount)
 
 		case syntax.OpTypeStddev:
@@ -620,6 +669,38 @@ func (e *VectorAggEvaluator) Error() error {
 	return e.nextEvaluator.Error()
 }
 
+// rangeAggParams returns the params a log range is evaluated with. A range
+// pinned with an `@` modifier is only evaluated at its pinned time.
+func rangeAggParams(r *syntax.LogRange, q Params) Params {
+	if r.At == nil {
+		return q
+	}
+	at := r.At.Time()
+	return ParamsWithTimeRangeOverride{
+		Params:        q,
+		StartOverride: at,
+		EndOverride:   at,
+	}
+}
+
+// newPinnableRangeAggEvaluator returns the evaluator of a range aggregation
+// given the params returned by rangeAggParams. The result of a range pinned
+// with an `@` modifier is repeated at every step of the query.
+func newPinnableRangeAggEvaluator(
+	it iter.PeekingSampleIterator,
+	expr *syntax.RangeAggregationExpr,
+	q, rq Params,
+) (StepEvaluator, error) {
+	ev, err := newRangeAggEvaluator(it, expr, rq, expr.Left.Offset)
+	if err != nil {
+		return nil, err
+	}
+	if expr.Left.At == nil {
+		return ev, nil
+	}
+	return newPinnedStepEvaluator(ev, q), nil
+}
+
 func newRangeAggEvaluator(
 	it iter.PeekingSampleIterator,
 	expr *syntax.RangeAggregationExpr,
@@ -657,6 +738,28 @@ func newRangeAggEvaluator(
 		return &QuantileSketchStepEvaluator{
 			iter: iter,
 		}, nil
+	case syntax.OpRangeTypeDistinctSketch:
+		iter := newDistinctSketchIterator(
+			it,
+			expr.Left.Interval.Nanoseconds(),
+			q.Step().Nanoseconds(),
+			q.Start().UnixNano(), q.End().UnixNano(), o.Nanoseconds(),
+		)
+
+		return &DistinctSketchStepEvaluator{
+			iter: iter,
+		}, nil
+	case syntax.OpRangeTypeHistogram:
+		iter := newHistogramIterator(
+			it,
+			expr.Left.Interval.Nanoseconds(),
+			q.Step().Nanoseconds(),
+			q.Start().UnixNano(), q.End().UnixNano(), o.Nanoseconds(),
+		)
+
+		return &RangeVectorEvaluator{
+			iter: iter,
+		}, nil
 	case syntax.OpRangeTypeFirstWithTimestamp:
 		iter := newFirstWithTimestampIterator(
 			it,
@@ -1328,3 +1431,124 @@ func absentLabels(expr syntax.SampleExpr) (labels.Labels, error) {
 	}
 	return m, nil
 }
+
+// defaultSubqueryStep is the resolution of a subquery without an explicit step
+// when it is evaluated as part of an instant query.
+const defaultSubqueryStep = time.Minute
+
+// newSubqueryEvaluator evaluates the inner expression of a subquery as a range
+// query at the subquery resolution and applies the range aggregation of the
+// subquery to the resulting samples.
+func newSubqueryEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.SubqueryExpr,
+	q Params,
+) (*SubqueryEvaluator, error) {
+	step := expr.Step
+	if step == 0 {
+		step = q.Step()
+	}
+	if step == 0 {
+		step = defaultSubqueryStep
+	}
+
+	// Like Prometheus, the steps of the inner query are aligned to multiples of
+	// the step, so that the results do not depend on the start of the query.
+	start := q.Start().Add(-expr.Offset).Add(-expr.Range)
+	if rem := start.UnixNano() % step.Nanoseconds(); rem != 0 {
+		start = start.Add(step - time.Duration(rem))
+	}
+	end := q.End().Add(-expr.Offset)
+
+	var it iter.SampleIterator = iter.NoopSampleIterator
+	var nextEvaluator StepEvaluator = EmptyEvaluator[SampleVector]{value: SampleVector{}}
+	if !start.After(end) {
+		var err error
+		nextEvaluator, err = evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, ParamsWithTimeRangeOverride{
+			Params: ParamsWithExpressionOverride{
+				Params:             q,
+				ExpressionOverride: expr.Left,
+			},
+			StartOverride: start,
+			EndOverride:   end,
+			StepOverride:  step,
+		})
+		if err != nil {
+			return nil, err
+		}
+		it = newStepEvaluatorSampleIterator(nextEvaluator)
+	}
+
+	rangeEvaluator, err := newRangeAggEvaluator(iter.NewPeekingSampleIterator(it), &syntax.RangeAggregationExpr{
+		Left: &syntax.LogRange{
+			Interval: expr.Range,
+			Offset:   expr.Offset,
+		},
+		Operation: expr.Operation,
+		Params:    expr.Params,
+	}, q, expr.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SubqueryEvaluator{
+		StepEvaluator: rangeEvaluator,
+		nextEvaluator: nextEvaluator,
+		expr:          expr,
+	}, nil
+}
+
+// SubqueryEvaluator is the StepEvaluator of a subquery. It embeds the range
+// aggregation over the samples of the inner step evaluator.
+type SubqueryEvaluator struct {
+	StepEvaluator
+	nextEvaluator StepEvaluator
+	expr          *syntax.SubqueryExpr
+}
+
+// stepEvaluatorSampleIterator turns the vectors of a step evaluator into a
+// sample iterator ordered by timestamp.
+type stepEvaluatorSampleIterator struct {
+	ev  StepEvaluator
+	vec promql.Vector
+	ts  int64
+	i   int
+
+	cur    logproto.Sample
+	curLbs string
+}
+
+func newStepEvaluatorSampleIterator(ev StepEvaluator) *stepEvaluatorSampleIterator {
+	return &stepEvaluatorSampleIterator{ev: ev}
+}
+
+func (it *stepEvaluatorSampleIterator) Next() bool {
+	for it.i >= len(it.vec) {
+		next, ts, r := it.ev.Next()
+		if !next {
+			return false
+		}
+		it.vec, it.ts, it.i = r.SampleVector(), ts, 0
+	}
+	s := it.vec[it.i]
+	it.i++
+	it.cur = logproto.Sample{
+		// step evaluators work with milliseconds, sample iterators with nanoseconds
+		Timestamp: it.ts * int64(time.Millisecond),
+		Value:     s.F,
+		Hash:      s.Metric.Hash(),
+	}
+	it.curLbs = s.Metric.String()
+	return true
+}
+
+func (it *stepEvaluatorSampleIterator) At() logproto.Sample { return it.cur }
+
+func (it *stepEvaluatorSampleIterator) Labels() string { return it.curLbs }
+
+func (it *stepEvaluatorSampleIterator) StreamHash() uint64 { return it.cur.Hash }
+
+func (it *stepEvaluatorSampleIterator) Err() error { return it.ev.Error() }
+
+func (it *stepEvaluatorSampleIterator) Close() error { return it.ev.Close() }
@@ -1,5 +1,10 @@
 package logql
 
+import (
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
 // MaxChildrenDisplay defines the maximum number of children that should be
 // shown by explain.
 const MaxChildrenDisplay = 3
@@ -44,6 +49,11 @@ func (r *RangeVectorEvaluator) Explain(parent Node) {
 	parent.Child("RangeVectorAgg")
 }
 
+func (e *pinnedStepEvaluator) Explain(parent Node) {
+	b := parent.Child("Pinned")
+	e.next.Explain(b)
+}
+
 func (e *AbsentRangeVectorEvaluator) Explain(parent Node) {
 	parent.Child("Absent RangeVectorAgg")
 }
@@ -63,6 +73,11 @@ func (e *QuantileSketchVectorStepEvaluator) Explain(parent Node) {
 	e.inner.Explain(b)
 }
 
+func (e *DistinctSketchVectorStepEvaluator) Explain(parent Node) {
+	b := parent.Child("DistinctSketchVector")
+	e.inner.Explain(b)
+}
+
 func (e *mergeOverTimeStepEvaluator) Explain(parent Node) {
 	parent.Child("MergeFirstOverTime")
 }
@@ -70,3 +85,92 @@ func (e *mergeOverTimeStepEvaluator) Explain(parent Node) {
 func (EmptyEvaluator[SampleVector]) Explain(parent Node) {
 	parent.Child("Empty")
 }
+
+func (e *HistogramQuantileEvaluator) Explain(parent Node) {
+	b := parent.Childf("%v HistogramQuantile", e.expr.Quantile)
+	e.nextEvaluator.Explain(b)
+}
+
+func (e *SubqueryEvaluator) Explain(parent Node) {
+	b := parent.Childf("[%s, %s] Subquery", e.expr.Operation, e.expr.RangeString())
+	e.nextEvaluator.Explain(b)
+}
+
+// DownstreamLeaf is a subquery of a query mapped by the ShardMapper, which is
+// executed by the queriers.
+type DownstreamLeaf struct {
+	Expr syntax.Expr
+	// Shard is nil if the subquery is not sharded.
+	Shard *Shard
+	// Chunks are the precomputed chunk refs of the shard, if any.
+	Chunks *logproto.ChunkRefGroup
+}
+
+// DownstreamLeaves returns the subqueries of a query mapped by the ShardMapper.
+// The parts of the query which are evaluated by the queriers but are not
+// wrapped into a downstream expression are returned as unsharded subqueries.
+// It must not be called with the expression of a no-op mapping, which is
+// executed as a single subquery.
+func DownstreamLeaves(expr syntax.Expr) []DownstreamLeaf {
+	return appendDownstreamLeaves(nil, expr)
+}
+
+func appendDownstreamLeaves(leaves []DownstreamLeaf, expr syntax.Expr) []DownstreamLeaf {
+	switch e := expr.(type) {
+	case DownstreamSampleExpr:
+		return append(leaves, newDownstreamLeaf(e.SampleExpr, e.shard))
+	case DownstreamLogSelectorExpr:
+		return append(leaves, newDownstreamLeaf(e.LogSelectorExpr, e.shard))
+	case *ConcatSampleExpr:
+		for c := e; c != nil; c = c.next {
+			leaves = appendDownstreamLeaves(leaves, c.DownstreamSampleExpr)
+		}
+		return leaves
+	case *ConcatLogSelectorExpr:
+		for c := e; c != nil; c = c.next {
+			leaves = appendDownstreamLeaves(leaves, c.DownstreamLogSelectorExpr)
+		}
+		return leaves
+	case *QuantileSketchEvalExpr:
+		return appendDownstreamLeaves(leaves, e.quantileMergeExpr)
+	case *QuantileSketchMergeExpr:
+		return appendDownstreamSampleLeaves(leaves, e.downstreams)
+	case *DistinctSketchEvalExpr:
+		return appendDownstreamSampleLeaves(leaves, e.downstreams)
+	case *MergeFirstOverTimeExpr:
+		return appendDownstreamSampleLeaves(leaves, e.downstreams)
+	case *MergeLastOverTimeExpr:
+		return appendDownstreamSampleLeaves(leaves, e.downstreams)
+	case *syntax.VectorAggregationExpr:
+		return appendDownstreamLeaves(leaves, e.Left)
+	case *syntax.LabelReplaceExpr:
+		return appendDownstreamLeaves(leaves, e.Left)
+	case *syntax.SubqueryExpr:
+		return appendDownstreamLeaves(leaves, e.Left)
+	case *syntax.HistogramQuantileExpr:
+		return appendDownstreamLeaves(leaves, e.Left)
+	case *syntax.BinOpExpr:
+		leaves = appendDownstreamLeaves(leaves, e.SampleExpr)
+		return appendDownstreamLeaves(leaves, e.RHS)
+	case *syntax.LiteralExpr, *syntax.VectorExpr:
+		return leaves
+	default:
+		return append(leaves, DownstreamLeaf{Expr: expr})
+	}
+}
+
+func appendDownstreamSampleLeaves(leaves []DownstreamLeaf, downstreams []DownstreamSampleExpr) []DownstreamLeaf {
+	for _, d := range downstreams {
+		leaves = appendDownstreamLeaves(leaves, d)
+	}
+	return leaves
+}
+
+func newDownstreamLeaf(expr syntax.Expr, shard *ShardWithChunkRefs) DownstreamLeaf {
+	leaf := DownstreamLeaf{Expr: expr}
+	if shard != nil {
+		leaf.Shard = &shard.Shard
+		leaf.Chunks = shard.chunks
+	}
+	return leaf
+}
@@ -55,3 +55,61 @@ func TestExplain(t *testing.T) {
 `
 	require.Equal(t, expected, tree.String())
 }
+
+func TestDownstreamLeaves(t *testing.T) {
+	strategy := NewPowerOfTwoStrategy(ConstantShards(2))
+	mapper := NewShardMapper(strategy, nilShardMetrics, nil)
+
+	for _, tc := range []struct {
+		query    string
+		expected []string
+	}{
+		{
+			query: `sum(rate({app="foo"}[1m])) / sum(rate({app="bar"}[1m]))`,
+			expected: []string{
+				`sum(rate({app="foo"}[1m])) 0_of_2`,
+				`sum(rate({app="foo"}[1m])) 1_of_2`,
+				`sum(rate({app="bar"}[1m])) 0_of_2`,
+				`sum(rate({app="bar"}[1m])) 1_of_2`,
+			},
+		},
+		{
+			query: `{app="foo"} |= "bar"`,
+			expected: []string{
+				`{app="foo"} |= "bar" 0_of_2`,
+				`{app="foo"} |= "bar" 1_of_2`,
+			},
+		},
+		{
+			query: `sum(rate({app="foo"}[1m])) / max(quantile_over_time(0.99, {app="bar"} | unwrap latency [1m]))`,
+			expected: []string{
+				`sum(rate({app="foo"}[1m])) 0_of_2`,
+				`sum(rate({app="foo"}[1m])) 1_of_2`,
+				`max(quantile_over_time(0.99,{app="bar"} | unwrap latency[1m])) unsharded`,
+			},
+		},
+		{
+			// literals are evaluated by the frontend.
+			query: `sum(count_over_time({app="foo"}[1m])) * 2`,
+			expected: []string{
+				`sum(count_over_time({app="foo"}[1m])) 0_of_2`,
+				`sum(count_over_time({app="foo"}[1m])) 1_of_2`,
+			},
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			_, _, mapped, err := mapper.Parse(syntax.MustParseExpr(tc.query))
+			require.NoError(t, err)
+
+			var got []string
+			for _, leaf := range DownstreamLeaves(mapped) {
+				shard := "unsharded"
+				if leaf.Shard != nil {
+					shard = leaf.Shard.PowerOfTwo.String()
+				}
+				got = append(got, leaf.Expr.String()+" "+shard)
+			}
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
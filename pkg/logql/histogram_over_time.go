@@ -0,0 +1,242 @@
+package logql
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logql/sketch"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// newHistogramIterator returns an iterator that turns the unwrapped samples of
+// each series within the window into cumulative `le` bucket series.
+func newHistogramIterator(
+	it iter.PeekingSampleIterator,
+	selRange, step, start, end, offset int64,
+) RangeVectorIterator {
+	// forces at least one step.
+	if step == 0 {
+		step = 1
+	}
+	if offset != 0 {
+		start = start - offset
+		end = end - offset
+	}
+	inner := &batchRangeVectorIterator{
+		iter:     it,
+		step:     step,
+		end:      end,
+		selRange: selRange,
+		metrics:  map[string]labels.Labels{},
+		window:   map[string]*promql.Series{},
+		agg:      nil,
+		current:  start - step, // first loop iteration will set it to start
+		offset:   offset,
+	}
+	return &histogramBatchRangeVectorIterator{
+		batchRangeVectorIterator: inner,
+		bucketLabels:             map[uint64]map[float64]labels.Labels{},
+	}
+}
+
+type histogramBatchRangeVectorIterator struct {
+	*batchRangeVectorIterator
+	at []promql.Sample
+
+	// bucketLabels caches the labels of each bucket series by series hash and
+	// upper bound, as the same buckets are usually returned at every step.
+	bucketLabels map[uint64]map[float64]labels.Labels
+}
+
+// At aggregates the underlying window into one sample per bucket and series.
+func (r *histogramBatchRangeVectorIterator) At() (int64, StepResult) {
+	if r.at == nil {
+		r.at = make([]promql.Sample, 0, len(r.window))
+	}
+	r.at = r.at[:0]
+	// convert ts from nano to milli seconds as the iterator work with nanoseconds
+	ts := r.current/1e+6 + r.offset/1e+6
+	for _, series := range r.window {
+		for _, b := range r.agg(series.Floats).Buckets() {
+			r.at = append(r.at, promql.Sample{
+				F:      b.Count,
+				T:      ts,
+				Metric: r.bucketMetric(series.Metric, b.UpperBound),
+			})
+		}
+	}
+	return ts, SampleVector(r.at)
+}
+
+func (r *histogramBatchRangeVectorIterator) agg(samples []promql.FPoint) *sketch.Histogram {
+	h := sketch.NewHistogram()
+	for _, v := range samples {
+		h.Add(v.F)
+	}
+	return h
+}
+
+func (r *histogramBatchRangeVectorIterator) bucketMetric(metric labels.Labels, upperBound float64) labels.Labels {
+	hash := metric.Hash()
+	buckets, ok := r.bucketLabels[hash]
+	if !ok {
+		buckets = map[float64]labels.Labels{}
+		r.bucketLabels[hash] = buckets
+	}
+	if lbs, ok := buckets[upperBound]; ok {
+		return lbs
+	}
+	lbs := labels.NewBuilder(metric).Set(model.BucketLabel, formatBucketBound(upperBound)).Labels()
+	buckets[upperBound] = lbs
+	return lbs
+}
+
+// formatBucketBound formats an upper bound the same way Prometheus client
+// libraries do for the `le` label.
+func formatBucketBound(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func newHistogramQuantileEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.HistogramQuantileExpr,
+	q Params,
+) (*HistogramQuantileEvaluator, error) {
+	nextEvaluator, err := evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistogramQuantileEvaluator{
+		nextEvaluator: nextEvaluator,
+		expr:          expr,
+		buf:           make([]byte, 0, 1024),
+	}, nil
+}
+
+// HistogramQuantileEvaluator calculates a quantile for every group of `le`
+// bucket series returned by the next evaluator.
+type HistogramQuantileEvaluator struct {
+	nextEvaluator StepEvaluator
+	expr          *syntax.HistogramQuantileExpr
+	buf           []byte
+}
+
+type histogramQuantileGroup struct {
+	metric  labels.Labels
+	buckets []sketch.HistogramBucket
+}
+
+func (e *HistogramQuantileEvaluator) Next() (bool, int64, StepResult) {
+	next, ts, r := e.nextEvaluator.Next()
+	if !next {
+		return false, 0, SampleVector{}
+	}
+	vec := r.SampleVector()
+
+	groups := map[uint64]*histogramQuantileGroup{}
+	order := make([]uint64, 0, len(vec))
+	var hash uint64
+	for _, s := range vec {
+		upperBound, err := strconv.ParseFloat(s.Metric.Get(model.BucketLabel), 64)
+		if err != nil {
+			// Series without a valid `le` label are not buckets and are ignored.
+			continue
+		}
+		hash, e.buf = s.Metric.HashWithoutLabels(e.buf, model.BucketLabel)
+		g, ok := groups[hash]
+		if !ok {
+			g = &histogramQuantileGroup{
+				metric: labels.NewBuilder(s.Metric).Del(model.BucketLabel, labels.MetricName).Labels(),
+			}
+			groups[hash] = g
+			order = append(order, hash)
+		}
+		g.buckets = append(g.buckets, sketch.HistogramBucket{UpperBound: upperBound, Count: s.F})
+	}
+
+	res := make(SampleVector, 0, len(order))
+	for _, hash := range order {
+		g := groups[hash]
+		res = append(res, promql.Sample{
+			T:      ts,
+			F:      bucketQuantile(e.expr.Quantile, g.buckets),
+			Metric: g.metric,
+		})
+	}
+	return next, ts, res
+}
+
+func (e *HistogramQuantileEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *HistogramQuantileEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
+// bucketQuantile calculates the quantile q from cumulative buckets by linear
+// interpolation within the bucket the quantile falls into, following the
+// semantics of the PromQL histogram_quantile function:
+//   - the bucket with the highest upper bound must be +Inf, otherwise NaN is returned.
+//   - if the quantile falls into the +Inf bucket, the upper bound of the
+//     second highest bucket is returned.
+//   - the lower bound of the lowest bucket is assumed to be 0 if its upper bound is positive.
+func bucketQuantile(q float64, buckets []sketch.HistogramBucket) float64 {
+	if math.IsNaN(q) {
+		return math.NaN()
+	}
+	if q < 0 {
+		return math.Inf(-1)
+	}
+	if q > 1 {
+		return math.Inf(1)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].UpperBound < buckets[j].UpperBound })
+	if len(buckets) < 2 || !math.IsInf(buckets[len(buckets)-1].UpperBound, 1) {
+		return math.NaN()
+	}
+	// Counts may not be monotonic when buckets were merged from series
+	// evaluated at slightly different times.
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].Count < buckets[i-1].Count {
+			buckets[i].Count = buckets[i-1].Count
+		}
+	}
+
+	observations := buckets[len(buckets)-1].Count
+	if observations == 0 {
+		return math.NaN()
+	}
+	rank := q * observations
+	b := sort.Search(len(buckets)-1, func(i int) bool { return buckets[i].Count >= rank })
+
+	if b == len(buckets)-1 {
+		return buckets[len(buckets)-2].UpperBound
+	}
+	if b == 0 && buckets[0].UpperBound <= 0 {
+		return buckets[0].UpperBound
+	}
+	var (
+		bucketStart float64
+		bucketEnd   = buckets[b].UpperBound
+		count       = buckets[b].Count
+	)
+	if b > 0 {
+		bucketStart = buckets[b-1].UpperBound
+		count -= buckets[b-1].Count
+		rank -= buckets[b-1].Count
+	}
+	return bucketStart + (bucketEnd-bucketStart)*(rank/count)
+}
@@ -0,0 +1,99 @@
+package logql
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logql/sketch"
+)
+
+func TestBucketQuantile(t *testing.T) {
+	inf := math.Inf(1)
+	for _, tc := range []struct {
+		name     string
+		q        float64
+		buckets  []sketch.HistogramBucket
+		expected float64
+	}{
+		{
+			name:     "interpolates within the first bucket",
+			q:        0.25,
+			buckets:  []sketch.HistogramBucket{{UpperBound: 4, Count: 4}, {UpperBound: 8, Count: 8}, {UpperBound: inf, Count: 8}},
+			expected: 2,
+		},
+		{
+			name:     "interpolates within a bucket",
+			q:        0.75,
+			buckets:  []sketch.HistogramBucket{{UpperBound: inf, Count: 8}, {UpperBound: 8, Count: 8}, {UpperBound: 4, Count: 4}},
+			expected: 6,
+		},
+		{
+			name:     "quantile in the +Inf bucket",
+			q:        0.99,
+			buckets:  []sketch.HistogramBucket{{UpperBound: 4, Count: 4}, {UpperBound: 8, Count: 8}, {UpperBound: inf, Count: 10}},
+			expected: 8,
+		},
+		{
+			name:     "non-positive lowest bucket",
+			q:        0.1,
+			buckets:  []sketch.HistogramBucket{{UpperBound: 0, Count: 5}, {UpperBound: 1, Count: 10}, {UpperBound: inf, Count: 10}},
+			expected: 0,
+		},
+		{
+			name:     "non monotonic counts",
+			q:        0.5,
+			buckets:  []sketch.HistogramBucket{{UpperBound: 2, Count: 4}, {UpperBound: 4, Count: 3}, {UpperBound: 8, Count: 8}, {UpperBound: inf, Count: 8}},
+			expected: 2,
+		},
+		{
+			name:     "missing +Inf bucket",
+			q:        0.5,
+			buckets:  []sketch.HistogramBucket{{UpperBound: 4, Count: 4}, {UpperBound: 8, Count: 8}},
+			expected: math.NaN(),
+		},
+		{
+			name:     "no observations",
+			q:        0.5,
+			buckets:  []sketch.HistogramBucket{{UpperBound: 4, Count: 0}, {UpperBound: inf, Count: 0}},
+			expected: math.NaN(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := bucketQuantile(tc.q, tc.buckets)
+			if math.IsNaN(tc.expected) {
+				require.True(t, math.IsNaN(got), "expected NaN, got %f", got)
+				return
+			}
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+// histogramVector returns the bucket series of a histogram_over_time result
+// given its lowest buckets. All higher buckets up to the +Inf bucket hold the
+// count of the last given bucket.
+func histogramVector(ts int64, metric labels.Labels, lowest ...sketch.HistogramBucket) promql.Vector {
+	last := lowest[len(lowest)-1]
+	buckets := append([]sketch.HistogramBucket{}, lowest...)
+	for ub := last.UpperBound * 2; ub <= sketch.HistogramMaxUpperBound; ub *= 2 {
+		buckets = append(buckets, sketch.HistogramBucket{UpperBound: ub, Count: last.Count})
+	}
+	buckets = append(buckets, sketch.HistogramBucket{UpperBound: math.Inf(1), Count: last.Count})
+
+	res := make(promql.Vector, 0, len(buckets))
+	for _, b := range buckets {
+		res = append(res, promql.Sample{
+			T:      ts,
+			F:      b.Count,
+			Metric: labels.NewBuilder(metric).Set(model.BucketLabel, formatBucketBound(b.UpperBound)).Labels(),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return labels.Compare(res[i].Metric, res[j].Metric) < 0 })
+	return res
+}
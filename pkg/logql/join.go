@@ -0,0 +1,141 @@
+package logql
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// joinKeySeparator separates the label values of a join key.
+const joinKeySeparator = "\xff"
+
+// joinKeyer computes the join key of an entry from the values of the join
+// labels. Values are looked up in the stream labels first, then in the
+// structured metadata and the parsed labels of the entry.
+type joinKeyer struct {
+	on     []string
+	values []string
+	// streams caches the parsed labels of each stream.
+	streams map[string]labels.Labels
+}
+
+func newJoinKeyer(on []string) *joinKeyer {
+	return &joinKeyer{
+		on:      on,
+		values:  make([]string, len(on)),
+		streams: map[string]labels.Labels{},
+	}
+}
+
+// key returns the join key of the entry, or false if any of the join labels
+// is missing.
+func (k *joinKeyer) key(streamLabels string, entry logproto.Entry) (string, bool) {
+	lbs, ok := k.streams[streamLabels]
+	if !ok {
+		// unparsable labels are treated as empty, the entry can still be joined
+		// on its structured metadata or parsed labels.
+		lbs, _ = syntax.ParseLabels(streamLabels)
+		k.streams[streamLabels] = lbs
+	}
+
+	for i, name := range k.on {
+		v := lbs.Get(name)
+		if v == "" {
+			v = labelAdapterValue(entry.StructuredMetadata, name)
+		}
+		if v == "" {
+			v = labelAdapterValue(entry.Parsed, name)
+		}
+		if v == "" {
+			return "", false
+		}
+		k.values[i] = v
+	}
+	return strings.Join(k.values, joinKeySeparator), true
+}
+
+func labelAdapterValue(lbs []logproto.LabelAdapter, name string) string {
+	for _, l := range lbs {
+		if l.Name == name {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+// joinIndex holds the timestamps of the right-hand side entries of a join
+// keyed by their join key.
+type joinIndex struct {
+	timestamps map[string][]int64
+}
+
+// newJoinIndex reads all entries of the iterator into a joinIndex. An error is
+// returned if more than maxEntries are read, unless maxEntries is 0.
+func newJoinIndex(it iter.EntryIterator, keys *joinKeyer, maxEntries int) (*joinIndex, error) {
+	idx := &joinIndex{timestamps: map[string][]int64{}}
+	var n int
+	for it.Next() {
+		n++
+		if maxEntries > 0 && n > maxEntries {
+			return nil, logqlmodel.NewJoinEntriesLimitError(maxEntries)
+		}
+		entry := it.At()
+		key, ok := keys.key(it.Labels(), entry)
+		if !ok {
+			continue
+		}
+		idx.timestamps[key] = append(idx.timestamps[key], entry.Timestamp.UnixNano())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, ts := range idx.timestamps {
+		sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+	}
+	return idx, nil
+}
+
+// matches returns true if an entry with the given key has been indexed within
+// the window around ts.
+func (idx *joinIndex) matches(key string, ts int64, within time.Duration) bool {
+	timestamps := idx.timestamps[key]
+	i := sort.Search(len(timestamps), func(i int) bool { return timestamps[i] >= ts-int64(within) })
+	return i < len(timestamps) && timestamps[i] <= ts+int64(within)
+}
+
+// joinIterator returns the entries of the left-hand side of a join which have
+// a matching entry in the right-hand side index.
+type joinIterator struct {
+	iter.EntryIterator
+	index  *joinIndex
+	keys   *joinKeyer
+	within time.Duration
+}
+
+func newJoinIterator(left iter.EntryIterator, index *joinIndex, keys *joinKeyer, within time.Duration) iter.EntryIterator {
+	return &joinIterator{
+		EntryIterator: left,
+		index:         index,
+		keys:          keys,
+		within:        within,
+	}
+}
+
+func (it *joinIterator) Next() bool {
+	for it.EntryIterator.Next() {
+		entry := it.At()
+		key, ok := it.keys.key(it.Labels(), entry)
+		if ok && it.index.matches(key, entry.Timestamp.UnixNano(), it.within) {
+			return true
+		}
+	}
+	return false
+}
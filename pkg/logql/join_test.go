@@ -0,0 +1,141 @@
+package logql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+func joinTestStreams() []logproto.Stream {
+	return []logproto.Stream{
+		{
+			Labels: `{app="a"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(10, 0), Line: "trace_id=1 msg=start"},
+				{Timestamp: time.Unix(20, 0), Line: "trace_id=2 msg=start"},
+				{Timestamp: time.Unix(100, 0), Line: "trace_id=3 msg=start"},
+				{Timestamp: time.Unix(100, 0), Line: "msg=untraced"},
+			},
+		},
+		{
+			Labels: `{app="b"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(15, 0), Line: "trace_id=1 level=error"},
+				{Timestamp: time.Unix(90, 0), Line: "trace_id=2 level=error"},
+				{Timestamp: time.Unix(105, 0), Line: "trace_id=3 level=info"},
+			},
+		},
+	}
+}
+
+func TestEngine_Join(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		limits   *fakeLimits
+		expected []string
+		err      error
+	}{
+		{
+			query:    `{app="a"} | logfmt | join on(trace_id) within 30s {app="b"} | logfmt | level="error"`,
+			limits:   &fakeLimits{},
+			expected: []string{"trace_id=1 msg=start"},
+		},
+		{
+			// the right-hand side is selected beyond the end of the query range.
+			query:    `{app="a"} | logfmt | join on(trace_id) within 30s {app="b"} | logfmt`,
+			limits:   &fakeLimits{},
+			expected: []string{"trace_id=1 msg=start", "trace_id=3 msg=start"},
+		},
+		{
+			query:    `{app="a"} | logfmt | join on(trace_id) within 5s {app="b"} | logfmt`,
+			limits:   &fakeLimits{},
+			expected: []string{"trace_id=1 msg=start", "trace_id=3 msg=start"},
+		},
+		{
+			query:    `{app="a"} | logfmt | join on(trace_id) within 1s {app="b"} | logfmt`,
+			limits:   &fakeLimits{},
+			expected: []string{},
+		},
+		{
+			query:    `{app="a"} | logfmt | join on(trace_id, app) within 30s {app="b"} | logfmt`,
+			limits:   &fakeLimits{},
+			expected: []string{},
+		},
+		{
+			query:    `{app="a"} | logfmt | join on(trace_id) within 30s {app="a"} | logfmt | join on(trace_id) within 30s {app="b"} | logfmt | level="error"`,
+			limits:   &fakeLimits{},
+			expected: []string{"trace_id=1 msg=start"},
+		},
+		{
+			// only the 3 entries of the right-hand side count toward the limit.
+			query:    `{app="a"} | logfmt | join on(trace_id) within 30s {app="b"} | logfmt`,
+			limits:   &fakeLimits{maxJoinEntries: 3},
+			expected: []string{"trace_id=1 msg=start", "trace_id=3 msg=start"},
+		},
+		{
+			query:  `{app="a"} | logfmt | join on(trace_id) within 30s {app="b"} | logfmt`,
+			limits: &fakeLimits{maxJoinEntries: 2},
+			err:    logqlmodel.NewJoinEntriesLimitError(2),
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			eng := NewEngine(EngineOpts{}, NewMockQuerier(0, joinTestStreams()), tc.limits, log.NewNopLogger())
+			params, err := NewLiteralParams(tc.query, time.Unix(0, 0), time.Unix(101, 0), 0, 0, logproto.FORWARD, 1000, nil, nil)
+			require.NoError(t, err)
+
+			res, err := eng.Query(params).Exec(user.InjectOrgID(context.Background(), "fake"))
+			if tc.err != nil {
+				require.ErrorIs(t, err, logqlmodel.ErrLimit)
+				require.EqualError(t, err, tc.err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			lines := []string{}
+			for _, stream := range res.Data.(logqlmodel.Streams) {
+				for _, e := range stream.Entries {
+					lines = append(lines, e.Line)
+				}
+			}
+			require.ElementsMatch(t, tc.expected, lines)
+		})
+	}
+}
+
+func TestJoinIndex(t *testing.T) {
+	keys := newJoinKeyer([]string{"trace_id"})
+	right := []logproto.Stream{
+		{
+			Labels: `{app="b"}`,
+			Entries: []logproto.Entry{
+				{Timestamp: time.Unix(30, 0), StructuredMetadata: []logproto.LabelAdapter{{Name: "trace_id", Value: "1"}}},
+				{Timestamp: time.Unix(10, 0), StructuredMetadata: []logproto.LabelAdapter{{Name: "trace_id", Value: "1"}}},
+				{Timestamp: time.Unix(20, 0), Parsed: []logproto.LabelAdapter{{Name: "trace_id", Value: "2"}}},
+				{Timestamp: time.Unix(20, 0)},
+			},
+		},
+	}
+
+	idx, err := newJoinIndex(iter.NewStreamsIterator(right, logproto.FORWARD), keys, 0)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]int64{
+		"1": {time.Unix(10, 0).UnixNano(), time.Unix(30, 0).UnixNano()},
+		"2": {time.Unix(20, 0).UnixNano()},
+	}, idx.timestamps)
+
+	require.True(t, idx.matches("1", time.Unix(20, 0).UnixNano(), 10*time.Second))
+	require.False(t, idx.matches("1", time.Unix(20, 0).UnixNano(), 9*time.Second))
+	require.True(t, idx.matches("2", time.Unix(15, 0).UnixNano(), 5*time.Second))
+	require.False(t, idx.matches("3", time.Unix(20, 0).UnixNano(), time.Hour))
+
+	_, err = newJoinIndex(iter.NewStreamsIterator(right, logproto.FORWARD), keys, 3)
+	require.ErrorIs(t, err, logqlmodel.ErrLimit)
+}
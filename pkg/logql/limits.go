@@ -15,23 +15,31 @@ var (
 // Limits allow the engine to fetch limits for a given users.
 type Limits interface {
 	MaxQuerySeries(context.Context, string) int
+	MaxQueryJoinEntries(context.Context, string) int
 	MaxQueryRange(ctx context.Context, userID string) time.Duration
 	QueryTimeout(context.Context, string) time.Duration
 	BlockedQueries(context.Context, string) []*validation.BlockedQuery
+	QueryMacros(context.Context, string) map[string]string
 }
 
 type fakeLimits struct {
 	maxSeries      int
+	maxJoinEntries int
 	timeout        time.Duration
 	blockedQueries []*validation.BlockedQuery
 	rangeLimit     time.Duration
 	requiredLabels []string
+	queryMacros    map[string]string
 }
 
 func (f fakeLimits) MaxQuerySeries(_ context.Context, _ string) int {
 	return f.maxSeries
 }
 
+func (f fakeLimits) MaxQueryJoinEntries(_ context.Context, _ string) int {
+	return f.maxJoinEntries
+}
+
 func (f fakeLimits) MaxQueryRange(_ context.Context, _ string) time.Duration {
 	return f.rangeLimit
 }
@@ -44,6 +52,10 @@ func (f fakeLimits) BlockedQueries(_ context.Context, _ string) []*validation.Bl
 	return f.blockedQueries
 }
 
+func (f fakeLimits) QueryMacros(_ context.Context, _ string) map[string]string {
+	return f.queryMacros
+}
+
 func (f fakeLimits) RequiredLabels(_ context.Context, _ string) []string {
 	return f.requiredLabels
 }
@@ -0,0 +1,150 @@
+package log
+
+import (
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+)
+
+// ContentRequirer is implemented by the StreamPipeline and StreamSampleExtractor
+// implementations that can tell what all the log lines they keep contain, so
+// that the chunks can skip the blocks whose summary shows that none of their
+// log lines does.
+type ContentRequirer interface {
+	// RequiredLineContents returns the strings all the kept log lines contain.
+	RequiredLineContents() [][]byte
+	// RequiredStructuredMetadata returns the names of the structured metadata
+	// all the kept log lines have, normalized as label names.
+	RequiredStructuredMetadata() []string
+}
+
+// RequiredLineContents returns the strings all the log lines kept by the
+// StreamPipeline or StreamSampleExtractor contain, if it can tell.
+func RequiredLineContents(v interface{}) [][]byte {
+	if r, ok := v.(ContentRequirer); ok {
+		return r.RequiredLineContents()
+	}
+	return nil
+}
+
+// RequiredStructuredMetadata returns the names of the structured metadata all
+// the log lines kept by the StreamPipeline or StreamSampleExtractor have, if it
+// can tell.
+func RequiredStructuredMetadata(v interface{}) []string {
+	if r, ok := v.(ContentRequirer); ok {
+		return r.RequiredStructuredMetadata()
+	}
+	return nil
+}
+
+// lineFilterStage is the Stage of a line filter. It keeps the filter to tell
+// the strings the log lines it keeps contain.
+type lineFilterStage struct {
+	filter Filterer
+}
+
+func (s lineFilterStage) Process(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+	return line, s.filter.Filter(line)
+}
+
+func (lineFilterStage) RequiredLabelNames() []string { return []string{} }
+
+// requiredContents returns the strings all the log lines kept by the filter
+// contain. Case insensitive matches are ignored.
+func requiredContents(f Filterer) [][]byte {
+	var res [][]byte
+	switch f := f.(type) {
+	case *containsFilter:
+		if !f.caseInsensitive {
+			res = append(res, f.match)
+		}
+	case containsAllFilter:
+		for _, m := range f.matches {
+			res = append(res, requiredContents(&m)...)
+		}
+	case *containsAllFilter:
+		res = requiredContents(*f)
+	case equalFilter:
+		if !f.caseInsensitive {
+			res = append(res, f.match)
+		}
+	case andFilter:
+		res = append(requiredContents(f.left), requiredContents(f.right)...)
+	case andFilters:
+		for _, filter := range f.filters {
+			res = append(res, requiredContents(filter)...)
+		}
+	case wrapper:
+		if f.IsFilterer() {
+			res = requiredContents(f.Filterer)
+		}
+	}
+	return res
+}
+
+// requiredLabels returns the names of the labels which must not be empty for
+// the label filter to keep a log line.
+func requiredLabels(f LabelFilterer) []string {
+	switch f := f.(type) {
+	case *StringLabelFilter:
+		if !f.Matches("") {
+			return []string{f.Name}
+		}
+	case *LineFilterLabelFilter:
+		if !f.Matches("") {
+			return []string{f.Name}
+		}
+	case *BinaryLabelFilter:
+		if f.And {
+			return append(requiredLabels(f.Left), requiredLabels(f.Right)...)
+		}
+	}
+	return nil
+}
+
+// contentRequirements are what all the log lines kept by a list of stages
+// contain.
+type contentRequirements struct {
+	lineContents [][]byte
+	// the names of the required labels, which are structured metadata unless
+	// they are stream labels.
+	labels []string
+}
+
+func newContentRequirements(stages []Stage) contentRequirements {
+	var (
+		r      contentRequirements
+		parsed bool
+	)
+	for _, s := range stages {
+		switch s := s.(type) {
+		case lineFilterStage:
+			r.lineContents = append(r.lineContents, requiredContents(s.filter)...)
+		case LabelFilterer:
+			// The labels filtered after a parser may be extracted from the log line.
+			if !parsed {
+				r.labels = append(r.labels, requiredLabels(s)...)
+			}
+		case *noopStage, *DropLabels, *KeepLabels:
+		case *JSONParser, *JSONExpressionParser, *LogfmtParser, *LogfmtExpressionParser, *RegexpParser, *PatternParser:
+			parsed = true
+		default:
+			// The next stages may see a different log line.
+			return r
+		}
+	}
+	return r
+}
+
+// structuredMetadata returns the names of the required structured metadata of
+// the stream.
+func (r contentRequirements) structuredMetadata(stream labels.Labels) []string {
+	var res []string
+	for _, name := range r.labels {
+		if strings.HasPrefix(name, "__") || stream.Has(name) {
+			continue
+		}
+		res = append(res, name)
+	}
+	return res
+}
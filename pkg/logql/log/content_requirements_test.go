@@ -0,0 +1,77 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentRequirements(t *testing.T) {
+	stream := labels.FromStrings("app", "foo")
+	lineFilter := func(match string, mt LineMatchType) Stage {
+		return mustFilter(NewFilter(match, mt)).ToStage()
+	}
+	labelFilter := func(name, value string) LabelFilterer {
+		return NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, name, value))
+	}
+
+	for _, tc := range []struct {
+		name                       string
+		stages                     []Stage
+		expectedLineContents       [][]byte
+		expectedStructuredMetadata []string
+	}{
+		{
+			name: "no stages",
+		},
+		{
+			name:                 "line filters",
+			stages:               []Stage{lineFilter("OOMKilled", LineMatchEqual), lineFilter("debug", LineMatchNotEqual), lineFilter("(?i)error", LineMatchRegexp)},
+			expectedLineContents: [][]byte{[]byte("OOMKilled")},
+		},
+		{
+			name:                 "chained line filters",
+			stages:               []Stage{lineFilter("foo", LineMatchEqual), NewAndFilters([]Filterer{mustFilter(NewFilter("bar", LineMatchEqual)), mustFilter(NewFilter("baz", LineMatchEqual))}).ToStage()},
+			expectedLineContents: [][]byte{[]byte("foo"), []byte("bar"), []byte("baz")},
+		},
+		{
+			name:                 "or line filter",
+			stages:               []Stage{ChainOrFilter(mustFilter(NewFilter("foo", LineMatchEqual)), mustFilter(NewFilter("bar", LineMatchEqual))).ToStage()},
+			expectedLineContents: nil,
+		},
+		{
+			name: "label filters",
+			stages: []Stage{
+				labelFilter("trace_id", "123"),
+				labelFilter("app", "foo"),
+				labelFilter("user", ""),
+				NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "__error__", "")),
+				NewOrLabelFilter(labelFilter("a", "1"), labelFilter("b", "1")),
+				NewAndLabelFilter(labelFilter("c", "1"), labelFilter("d", "1")),
+			},
+			expectedStructuredMetadata: []string{"trace_id", "c", "d"},
+		},
+		{
+			name:                       "stages after a parser",
+			stages:                     []Stage{labelFilter("trace_id", "123"), NewLogfmtParser(false, false), labelFilter("level", "error"), lineFilter("foo", LineMatchEqual)},
+			expectedLineContents:       [][]byte{[]byte("foo")},
+			expectedStructuredMetadata: []string{"trace_id"},
+		},
+		{
+			name:                 "stages after line_format",
+			stages:               []Stage{lineFilter("foo", LineMatchEqual), newMustLineFormatter("{{.app}}"), lineFilter("bar", LineMatchEqual), labelFilter("trace_id", "123")},
+			expectedLineContents: [][]byte{[]byte("foo")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPipeline(tc.stages).ForStream(stream)
+			require.Equal(t, tc.expectedLineContents, RequiredLineContents(p))
+			require.Equal(t, tc.expectedStructuredMetadata, RequiredStructuredMetadata(p))
+
+			ex := mustSampleExtractor(NewLineSampleExtractor(CountExtractor, tc.stages, nil, false, false)).ForStream(stream)
+			require.Equal(t, tc.expectedLineContents, RequiredLineContents(ex))
+			require.Equal(t, tc.expectedStructuredMetadata, RequiredStructuredMetadata(ex))
+		})
+	}
+}
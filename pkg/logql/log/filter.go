@@ -212,11 +212,7 @@ func (a andFilter) Filter(line []byte) bool {
 }
 
 func (a andFilter) ToStage() Stage {
-	return StageFunc{
-		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
-			return line, a.Filter(line)
-		},
-	}
+	return lineFilterStage{a}
 }
 
 func (a andFilter) Matches(test Checker) bool {
@@ -293,11 +289,7 @@ func (a andFilters) Filter(line []byte) bool {
 }
 
 func (a andFilters) ToStage() Stage {
-	return StageFunc{
-		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
-			return line, a.Filter(line)
-		},
-	}
+	return lineFilterStage{a}
 }
 
 type orFilter struct {
@@ -405,11 +397,7 @@ func (l equalFilter) Filter(line []byte) bool {
 }
 
 func (l equalFilter) ToStage() Stage {
-	return StageFunc{
-		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
-			return line, l.Filter(line)
-		},
-	}
+	return lineFilterStage{l}
 }
 
 func (l equalFilter) Matches(test Checker) bool {
@@ -481,11 +469,7 @@ func containsLower(line, substr []byte) bool {
 }
 
 func (l containsFilter) ToStage() Stage {
-	return StageFunc{
-		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
-			return line, l.Filter(line)
-		},
-	}
+	return lineFilterStage{&l}
 }
 
 // Matches implements Matcher
@@ -533,11 +517,7 @@ func (f containsAllFilter) Filter(line []byte) bool {
 }
 
 func (f containsAllFilter) ToStage() Stage {
-	return StageFunc{
-		process: func(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
-			return line, f.Filter(line)
-		},
-	}
+	return lineFilterStage{f}
 }
 
 func (f containsAllFilter) Matches(test Checker) bool {
@@ -147,6 +147,7 @@ type BaseLabelsBuilder struct {
 // LabelsBuilder is the same as labels.Builder but tailored for this package.
 type LabelsBuilder struct {
 	base          labels.Labels
+	baseHash      uint64
 	buf           labels.Labels
 	currentResult LabelsResult
 	groupedResult LabelsResult
@@ -188,6 +189,7 @@ func (b *BaseLabelsBuilder) ForLabels(lbs labels.Labels, hash uint64) *LabelsBui
 	if labelResult, ok := b.resultCache[hash]; ok {
 		res := &LabelsBuilder{
 			base:              lbs,
+			baseHash:          hash,
 			currentResult:     labelResult,
 			BaseLabelsBuilder: b,
 		}
@@ -197,6 +199,7 @@ func (b *BaseLabelsBuilder) ForLabels(lbs labels.Labels, hash uint64) *LabelsBui
 	b.resultCache[hash] = labelResult
 	res := &LabelsBuilder{
 		base:              lbs,
+		baseHash:          hash,
 		currentResult:     labelResult,
 		BaseLabelsBuilder: b,
 	}
@@ -286,6 +289,11 @@ func (b *LabelsBuilder) BaseHas(key string) bool {
 	return b.base.Has(key)
 }
 
+// BaseHash returns the hash of the base labels of the stream.
+func (b *LabelsBuilder) BaseHash() uint64 {
+	return b.baseHash
+}
+
 // GetWithCategory returns the value and the category of a labels key if it exists.
 func (b *LabelsBuilder) GetWithCategory(key string) (string, LabelCategory, bool) {
 	v, category, ok := b.getWithCategory(key)
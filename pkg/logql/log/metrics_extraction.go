@@ -2,10 +2,12 @@ package log
 
 import (
 	"context"
+	"reflect"
 	"sort"
 	"strconv"
 	"time"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/pkg/errors"
 	"github.com/prometheus/prometheus/model/labels"
 
@@ -16,6 +18,7 @@ const (
 	ConvertBytes    = "bytes"
 	ConvertDuration = "duration"
 	ConvertFloat    = "float"
+	ConvertHash     = "hash"
 )
 
 // LineExtractor extracts a float64 from a log line.
@@ -50,6 +53,8 @@ type lineSampleExtractor struct {
 	Stage
 	LineExtractor
 
+	readsLine        bool
+	requirements     contentRequirements
 	baseBuilder      *BaseLabelsBuilder
 	streamExtractors map[uint64]StreamSampleExtractor
 }
@@ -62,6 +67,8 @@ func NewLineSampleExtractor(ex LineExtractor, stages []Stage, groups []string, w
 	return &lineSampleExtractor{
 		Stage:            s,
 		LineExtractor:    ex,
+		readsLine:        stagesReadLine(stages) || !isCountExtractor(ex),
+		requirements:     newContentRequirements(stages),
 		baseBuilder:      NewBaseLabelsBuilderWithGrouping(groups, hints, without, noLabels),
 		streamExtractors: make(map[uint64]StreamSampleExtractor),
 	}, nil
@@ -76,22 +83,44 @@ func (l *lineSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtrac
 	res := &streamLineSampleExtractor{
 		Stage:         l.Stage,
 		LineExtractor: l.LineExtractor,
+		readsLine:     l.readsLine,
+		requirements:  l.requirements,
 		builder:       l.baseBuilder.ForLabels(labels, hash),
 	}
 	l.streamExtractors[hash] = res
 	return res
 }
 
+// isCountExtractor returns whether the LineExtractor is the CountExtractor,
+// the only one not reading the log line.
+func isCountExtractor(ex LineExtractor) bool {
+	return reflect.ValueOf(ex).Pointer() == reflect.ValueOf(CountExtractor).Pointer()
+}
+
 type streamLineSampleExtractor struct {
 	Stage
 	LineExtractor
-	builder *LabelsBuilder
+	readsLine    bool
+	requirements contentRequirements
+	builder      *LabelsBuilder
 }
 
 func (l *streamLineSampleExtractor) ReferencedStructuredMetadata() bool {
 	return l.builder.referencedStructuredMetadata
 }
 
+func (l *streamLineSampleExtractor) ReadsLine() bool {
+	return l.readsLine
+}
+
+func (l *streamLineSampleExtractor) RequiredLineContents() [][]byte {
+	return l.requirements.lineContents
+}
+
+func (l *streamLineSampleExtractor) RequiredStructuredMetadata() []string {
+	return l.requirements.structuredMetadata(l.builder.base)
+}
+
 func (l *streamLineSampleExtractor) Process(ts int64, line []byte, structuredMetadata ...labels.Label) (float64, LabelsResult, bool) {
 	l.builder.Reset()
 	l.builder.Add(StructuredMetadataLabel, structuredMetadata...)
@@ -122,6 +151,8 @@ type labelSampleExtractor struct {
 	postFilter   Stage
 	labelName    string
 	conversionFn convertionFn
+	readsLine    bool
+	requirements contentRequirements
 
 	baseBuilder      *BaseLabelsBuilder
 	streamExtractors map[uint64]StreamSampleExtractor
@@ -144,6 +175,8 @@ func LabelExtractorWithStages(
 		convFn = convertDuration
 	case ConvertFloat:
 		convFn = convertFloat
+	case ConvertHash:
+		convFn = convertHash
 	default:
 		return nil, errors.Errorf("unsupported conversion operation %s", conversion)
 	}
@@ -159,6 +192,8 @@ func LabelExtractorWithStages(
 		conversionFn:     convFn,
 		labelName:        labelName,
 		postFilter:       postFilter,
+		readsLine:        stagesReadLine(preStages) || stagesReadLine([]Stage{postFilter}),
+		requirements:     newContentRequirements(preStages),
 		baseBuilder:      NewBaseLabelsBuilderWithGrouping(groups, hints, without, noLabels),
 		streamExtractors: make(map[uint64]StreamSampleExtractor),
 	}, nil
@@ -173,6 +208,18 @@ func (l *labelSampleExtractor) ReferencedStructuredMetadata() bool {
 	return l.baseBuilder.referencedStructuredMetadata
 }
 
+func (l *labelSampleExtractor) ReadsLine() bool {
+	return l.readsLine
+}
+
+func (l *streamLabelSampleExtractor) RequiredLineContents() [][]byte {
+	return l.requirements.lineContents
+}
+
+func (l *streamLabelSampleExtractor) RequiredStructuredMetadata() []string {
+	return l.requirements.structuredMetadata(l.builder.base)
+}
+
 func (l *labelSampleExtractor) ForStream(labels labels.Labels) StreamSampleExtractor {
 	hash := l.baseBuilder.Hash(labels)
 	if res, ok := l.streamExtractors[hash]; ok {
@@ -267,6 +314,23 @@ func (sp *filteringStreamExtractor) ReferencedStructuredMetadata() bool {
 	return false
 }
 
+func (sp *filteringStreamExtractor) ReadsLine() bool {
+	for _, filter := range sp.filters {
+		if ReadsLine(filter.pipeline) {
+			return true
+		}
+	}
+	return ReadsLine(sp.extractor)
+}
+
+func (sp *filteringStreamExtractor) RequiredLineContents() [][]byte {
+	return RequiredLineContents(sp.extractor)
+}
+
+func (sp *filteringStreamExtractor) RequiredStructuredMetadata() []string {
+	return RequiredStructuredMetadata(sp.extractor)
+}
+
 func (sp *filteringStreamExtractor) BaseLabels() LabelsResult {
 	return sp.extractor.BaseLabels()
 }
@@ -313,6 +377,13 @@ func convertDuration(v string) (float64, error) {
 	return d.Seconds(), nil
 }
 
+// convertHash converts a value to its hash, keeping the 53 bits exactly
+// represented by a float64 so that equal values and only them are converted to
+// equal floats, but for the unlikely collisions.
+func convertHash(v string) (float64, error) {
+	return float64(xxhash.Sum64String(v) >> 11), nil
+}
+
 func convertBytes(v string) (float64, error) {
 	b, err := humanize.ParseBytes(v)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cespare/xxhash/v2"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -178,6 +179,18 @@ func Test_labelSampleExtractor_Extract(t *testing.T) {
 			),
 			wantOk: true,
 		},
+		{
+			name: "convert hash",
+			ex: mustSampleExtractor(LabelExtractorWithStages(
+				"foo", ConvertHash, []string{"bar"}, false, false, nil, NoopStage,
+			)),
+			in: labels.FromStrings("foo", "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				"bar", "foo",
+			),
+			want:    float64(xxhash.Sum64String("3fa85f64-5717-4562-b3fc-2c963f66afa6") >> 11),
+			wantLbs: labels.FromStrings("bar", "foo"),
+			wantOk:  true,
+		},
 		{
 			name: "not convertable",
 			ex: mustSampleExtractor(LabelExtractorWithStages(
@@ -248,6 +261,20 @@ func Test_labelSampleExtractor_Extract(t *testing.T) {
 	}
 }
 
+func Test_convertHash(t *testing.T) {
+	a, err := convertHash("user-a")
+	require.NoError(t, err)
+	b, err := convertHash("user-b")
+	require.NoError(t, err)
+	again, err := convertHash("user-a")
+	require.NoError(t, err)
+
+	require.Equal(t, a, again)
+	require.NotEqual(t, a, b)
+	// the hash is an integer exactly represented by a float64.
+	require.Equal(t, a, float64(uint64(a)))
+}
+
 func Test_Extract_ExpectedLabels(t *testing.T) {
 	ex := mustSampleExtractor(LabelExtractorWithStages("duration", ConvertDuration, []string{"foo"}, false, false, []Stage{NewJSONParser()}, NoopStage))
 
@@ -2,8 +2,11 @@ package log
 
 import (
 	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -21,6 +24,7 @@ import (
 
 const (
 	jsonSpacer      = '_'
+	xmlSpacer       = '_'
 	duplicateSuffix = "_extracted"
 	trueString      = "true"
 	falseString     = "false"
@@ -33,6 +37,9 @@ var (
 	_ Stage = &JSONParser{}
 	_ Stage = &RegexpParser{}
 	_ Stage = &LogfmtParser{}
+	_ Stage = &CSVParser{}
+	_ Stage = &XMLParser{}
+	_ Stage = &XMLExpressionParser{}
 
 	trueBytes = []byte("true")
 
@@ -40,6 +47,8 @@ var (
 	errMissingCapture       = errors.New("at least one named capture must be supplied")
 	errFoundAllLabels       = errors.New("found all required labels")
 	errLabelDoesNotMatch    = errors.New("found a label with a matcher that didn't match")
+	errUnterminatedQuote    = errors.New("unterminated quoted field")
+	errMissingXMLElement    = errors.New("expecting xml element, but it is not")
 
 	// the rune error replacement is rejected by Prometheus hence replacing them with space.
 	removeInvalidUtf = func(r rune) rune {
@@ -767,3 +776,629 @@ func (u *UnpackParser) unpack(entry []byte, lbs *LabelsBuilder) ([]byte, error)
 	}
 	return entry, nil
 }
+
+type CSVParser struct {
+	delimiter byte
+	quote     byte
+	// names holds the label name of each column, an empty name means the column is not extracted.
+	names []string
+	// mapped tells if only the columns selected by expressions are extracted.
+	mapped bool
+	fields [][]byte
+
+	keys internedStringSet
+}
+
+// NewCSVParser creates a new log stage that can extract labels from a delimiter separated log line.
+// The header is parsed using the same delimiter and quote characters as the log lines and gives the name of each column.
+// If expressions are provided only the columns referenced by the expressions are extracted, using the expression
+// identifier as label name, otherwise every column is extracted using its header name.
+// An empty quote disables quoted fields.
+func NewCSVParser(header, delimiter, quote string, expressions []LabelExtractionExpr) (*CSVParser, error) {
+	if len(delimiter) != 1 {
+		return nil, fmt.Errorf("csv delimiter must be a single character, got '%s'", delimiter)
+	}
+	if len(quote) > 1 {
+		return nil, fmt.Errorf("csv quote must be a single character, got '%s'", quote)
+	}
+	p := &CSVParser{
+		delimiter: delimiter[0],
+		keys:      internedStringSet{},
+	}
+	if len(quote) == 1 {
+		p.quote = quote[0]
+		if p.quote == p.delimiter {
+			return nil, fmt.Errorf("csv quote and delimiter must be different")
+		}
+	}
+
+	columns, err := p.split([]byte(header), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse csv header [%s]: %w", header, err)
+	}
+
+	p.names = make([]string, len(columns))
+	if len(expressions) == 0 {
+		unique := make(map[string]struct{}, len(columns))
+		for i, c := range columns {
+			name := sanitizeLabelKey(string(c), true)
+			if name == "" {
+				continue
+			}
+			if _, ok := unique[name]; ok {
+				return nil, fmt.Errorf("duplicate csv column name '%s'", name)
+			}
+			unique[name] = struct{}{}
+			p.names[i] = name
+		}
+		return p, nil
+	}
+
+	p.mapped = true
+	for _, exp := range expressions {
+		if !model.LabelName(exp.Identifier).IsValid() {
+			return nil, fmt.Errorf("invalid extracted label name '%s'", exp.Identifier)
+		}
+		found := false
+		for i, c := range columns {
+			if string(c) == exp.Expression {
+				if p.names[i] != "" {
+					return nil, fmt.Errorf("csv column '%s' is extracted more than once", exp.Expression)
+				}
+				p.names[i] = exp.Identifier
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("csv column '%s' not found in header", exp.Expression)
+		}
+	}
+	return p, nil
+}
+
+func (c *CSVParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	parserHints := lbs.ParserLabelHints()
+	if !c.mapped && parserHints.NoLabels() {
+		return line, true
+	}
+
+	var err error
+	c.fields, err = c.split(line, c.fields[:0])
+	if err != nil {
+		addErrLabel(errCSV, err, lbs)
+		return line, true
+	}
+
+	for i, name := range c.names {
+		if name == "" {
+			continue
+		}
+		if i >= len(c.fields) {
+			if !c.mapped {
+				break
+			}
+			// Ensure there's a label for every mapped column.
+			if _, ok := lbs.Get(name); !ok {
+				lbs.Set(ParsedLabel, name, "")
+			}
+			continue
+		}
+
+		key, ok := c.keys.Get(unsafeGetBytes(name), func() (string, bool) {
+			field := name
+			if lbs.BaseHas(field) {
+				field = field + duplicateSuffix
+			}
+			if !c.mapped && !parserHints.ShouldExtract(field) {
+				return "", false
+			}
+			return field, true
+		})
+		if !ok {
+			continue
+		}
+
+		val := c.fields[i]
+		if bytes.ContainsRune(val, utf8.RuneError) {
+			val = bytes.Map(removeInvalidUtf, val)
+		}
+
+		lbs.Set(ParsedLabel, key, string(val))
+		if !parserHints.ShouldContinueParsingLine(key, lbs) {
+			return line, false
+		}
+
+		if !c.mapped && parserHints.AllRequiredExtracted() {
+			break
+		}
+	}
+	return line, true
+}
+
+// split splits a single csv record into fields appended to dst.
+// Quoted fields may contain the delimiter and quote characters escaped by doubling them.
+// Unless they contain escaped quotes, returned fields reference the line.
+func (c *CSVParser) split(line []byte, dst [][]byte) ([][]byte, error) {
+	i := 0
+	for {
+		if c.quote != 0 && i < len(line) && line[i] == c.quote {
+			escaped := false
+			j := i + 1
+			for {
+				k := bytes.IndexByte(line[j:], c.quote)
+				if k < 0 {
+					return dst, errUnterminatedQuote
+				}
+				j += k
+				if j+1 < len(line) && line[j+1] == c.quote {
+					escaped = true
+					j += 2
+					continue
+				}
+				break
+			}
+
+			field := line[i+1 : j]
+			if escaped {
+				field = bytes.ReplaceAll(field, []byte{c.quote, c.quote}, []byte{c.quote})
+			}
+			dst = append(dst, field)
+
+			i = j + 1
+			if i == len(line) {
+				return dst, nil
+			}
+			if line[i] != c.delimiter {
+				return dst, fmt.Errorf("unexpected character %q after quoted field", line[i])
+			}
+			i++
+			continue
+		}
+
+		k := bytes.IndexByte(line[i:], c.delimiter)
+		if k < 0 {
+			return append(dst, line[i:]), nil
+		}
+		dst = append(dst, line[i:i+k])
+		i += k + 1
+	}
+}
+
+func (c *CSVParser) RequiredLabelNames() []string { return []string{} }
+
+// xmlFrame is an element currently opened while parsing an xml log line.
+type xmlFrame struct {
+	name      string
+	index     int
+	prefixLen int
+	leaf      bool
+	text      []byte
+}
+
+type XMLParser struct {
+	prefixBuffer []byte // buffer used to build xml keys
+	stack        []xmlFrame
+	reader       bytes.Reader
+
+	keys internedStringSet
+}
+
+// NewXMLParser creates a log stage that can parse a xml log line and add elements and attributes as labels.
+// The root element is not part of the label names, nested elements and attributes are joined using an underscore:
+// `<event><user id="1">bob</user></event>` is extracted as `user="bob"` and `user_id="1"`.
+// Only elements without child elements are extracted.
+func NewXMLParser() *XMLParser {
+	return &XMLParser{
+		prefixBuffer: make([]byte, 0, 1024),
+		stack:        make([]xmlFrame, 0, 16),
+		keys:         internedStringSet{},
+	}
+}
+
+func (x *XMLParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	parserHints := lbs.ParserLabelHints()
+	if parserHints.NoLabels() {
+		return line, true
+	}
+
+	if !isValidXMLStart(line) {
+		addErrLabel(errXML, errMissingXMLElement, lbs)
+		return line, true
+	}
+
+	// reset the state.
+	x.prefixBuffer = x.prefixBuffer[:0]
+	x.stack = x.stack[:0]
+	x.reader.Reset(line)
+
+	dec := xml.NewDecoder(&x.reader)
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errMissingXMLElement
+			}
+			addErrLabel(errXML, err, lbs)
+			return line, true
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			prefixLen := len(x.prefixBuffer)
+			if len(x.stack) > 0 {
+				x.stack[len(x.stack)-1].leaf = false
+				if len(x.prefixBuffer) != 0 {
+					x.prefixBuffer = append(x.prefixBuffer, byte(xmlSpacer))
+				}
+				x.prefixBuffer = appendSanitized(x.prefixBuffer, []byte(t.Name.Local))
+				if !parserHints.ShouldExtractPrefix(unsafeGetString(x.prefixBuffer)) {
+					x.prefixBuffer = x.prefixBuffer[:prefixLen]
+					if err := dec.Skip(); err != nil {
+						addErrLabel(errXML, err, lbs)
+						return line, true
+					}
+					continue
+				}
+			}
+			x.stack = append(x.stack, xmlFrame{prefixLen: prefixLen, leaf: true})
+
+			for _, attr := range t.Attr {
+				if isXMLNamespaceDecl(attr) {
+					continue
+				}
+				if err := x.setLabel([]byte(attr.Name.Local), []byte(attr.Value), lbs); err != nil {
+					return line, x.handleErr(err, lbs)
+				}
+			}
+		case xml.CharData:
+			if len(x.stack) > 0 {
+				frame := &x.stack[len(x.stack)-1]
+				frame.text = append(frame.text[:len(frame.text):len(frame.text)], t...)
+			}
+		case xml.EndElement:
+			frame := x.stack[len(x.stack)-1]
+			x.stack = x.stack[:len(x.stack)-1]
+			if len(x.stack) == 0 {
+				// we're done with the root element.
+				return line, true
+			}
+			if frame.leaf {
+				if err := x.setLabel(nil, bytes.TrimSpace(frame.text), lbs); err != nil {
+					return line, x.handleErr(err, lbs)
+				}
+			}
+			// rollback the prefix as we exit the current element.
+			x.prefixBuffer = x.prefixBuffer[:frame.prefixLen]
+		}
+	}
+}
+
+func (x *XMLParser) handleErr(err error, lbs *LabelsBuilder) bool {
+	switch {
+	case errors.Is(err, errFoundAllLabels):
+		// Short-circuited
+		return true
+	case errors.Is(err, errLabelDoesNotMatch):
+		// one of the label matchers does not match. The whole line can be thrown away
+		return false
+	}
+	addErrLabel(errXML, err, lbs)
+	return true
+}
+
+// setLabel sets the label named after the current prefix and the given attribute name if any.
+func (x *XMLParser) setLabel(attr, value []byte, lbs *LabelsBuilder) error {
+	prefixLen := len(x.prefixBuffer)
+	if len(attr) > 0 {
+		if len(x.prefixBuffer) != 0 {
+			x.prefixBuffer = append(x.prefixBuffer, byte(xmlSpacer))
+		}
+		x.prefixBuffer = appendSanitized(x.prefixBuffer, attr)
+	}
+	if len(x.prefixBuffer) == 0 {
+		return nil
+	}
+
+	parserHints := lbs.ParserLabelHints()
+	key, ok := x.keys.Get(x.prefixBuffer, func() (string, bool) {
+		field := string(x.prefixBuffer)
+		if lbs.BaseHas(field) {
+			field = field + duplicateSuffix
+		}
+		if !parserHints.ShouldExtract(field) {
+			return "", false
+		}
+		return field, true
+	})
+
+	// reset the prefix position
+	x.prefixBuffer = x.prefixBuffer[:prefixLen]
+	if !ok {
+		return nil
+	}
+
+	if bytes.ContainsRune(value, utf8.RuneError) {
+		value = bytes.Map(removeInvalidUtf, value)
+	}
+	lbs.Set(ParsedLabel, key, string(value))
+	if !parserHints.ShouldContinueParsingLine(key, lbs) {
+		return errLabelDoesNotMatch
+	}
+	if parserHints.AllRequiredExtracted() {
+		return errFoundAllLabels
+	}
+	return nil
+}
+
+func (x *XMLParser) RequiredLabelNames() []string { return []string{} }
+
+func isXMLNamespaceDecl(attr xml.Attr) bool {
+	return attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns")
+}
+
+func isValidXMLStart(data []byte) bool {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	return len(data) > 0 && data[0] == '<'
+}
+
+// xmlPathStep is a single element step of a xml path expression.
+type xmlPathStep struct {
+	name string
+	// index is the 1-based position of the element among its siblings with the same name, 0 matches any position.
+	index int
+}
+
+func (s xmlPathStep) matches(f xmlFrame) bool {
+	return (s.name == "*" || s.name == f.name) && (s.index == 0 || s.index == f.index)
+}
+
+// xmlPath is a XPath-like expression selecting an element text or attribute.
+type xmlPath struct {
+	steps []xmlPathStep
+	attr  string
+	// descendant tells if the path can start at any depth (`//a/b`) instead of the root element.
+	descendant bool
+}
+
+// parseXMLPath parses a subset of XPath location paths: `/root/child`, `root/child[2]`, `//child`, `/root/*/@attr`.
+func parseXMLPath(expr string) (xmlPath, error) {
+	var p xmlPath
+	s := expr
+	if strings.HasPrefix(s, "//") {
+		p.descendant = true
+		s = s[2:]
+	} else {
+		s = strings.TrimPrefix(s, "/")
+	}
+
+	parts := strings.Split(s, "/")
+	for i, part := range parts {
+		if part == "" {
+			return p, fmt.Errorf("empty step in xml path '%s'", expr)
+		}
+		if strings.HasPrefix(part, "@") {
+			if i != len(parts)-1 {
+				return p, fmt.Errorf("attribute must be the last step of xml path '%s'", expr)
+			}
+			p.attr = localXMLName(part[1:])
+			if p.attr == "" {
+				return p, fmt.Errorf("empty attribute name in xml path '%s'", expr)
+			}
+			continue
+		}
+
+		step := xmlPathStep{name: part}
+		if j := strings.IndexByte(part, '['); j >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return p, fmt.Errorf("missing closing ']' in xml path '%s'", expr)
+			}
+			index, err := strconv.Atoi(part[j+1 : len(part)-1])
+			if err != nil || index < 1 {
+				return p, fmt.Errorf("invalid index in xml path '%s'", expr)
+			}
+			step.name, step.index = part[:j], index
+		}
+		step.name = localXMLName(step.name)
+		if step.name == "" {
+			return p, fmt.Errorf("empty element name in xml path '%s'", expr)
+		}
+		p.steps = append(p.steps, step)
+	}
+
+	if len(p.steps) == 0 {
+		return p, fmt.Errorf("xml path '%s' must select at least one element", expr)
+	}
+	return p, nil
+}
+
+// localXMLName strips the namespace prefix of a xml name.
+func localXMLName(name string) string {
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func (p xmlPath) matches(stack []xmlFrame) bool {
+	if len(stack) < len(p.steps) || (!p.descendant && len(stack) != len(p.steps)) {
+		return false
+	}
+	offset := len(stack) - len(p.steps)
+	for i, step := range p.steps {
+		if !step.matches(stack[offset+i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// xmlCapture collects the text of an element selected by an expression.
+type xmlCapture struct {
+	expr  int
+	depth int
+	text  []byte
+}
+
+type XMLExpressionParser struct {
+	ids      []string
+	paths    []xmlPath
+	indexed  bool
+	stack    []xmlFrame
+	siblings []map[string]int
+	captures []xmlCapture
+	matched  []bool
+	reader   bytes.Reader
+
+	keys internedStringSet
+}
+
+// NewXMLExpressionParser creates a log stage extracting the element text or attribute selected by each
+// XPath-like expression of a xml log line. Only the first element matching an expression is used.
+func NewXMLExpressionParser(expressions []LabelExtractionExpr) (*XMLExpressionParser, error) {
+	if len(expressions) == 0 {
+		return nil, fmt.Errorf("no xml expression provided")
+	}
+	x := &XMLExpressionParser{
+		keys: internedStringSet{},
+	}
+	for _, exp := range expressions {
+		path, err := parseXMLPath(exp.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse expression [%s]: %w", exp.Expression, err)
+		}
+
+		if !model.LabelName(exp.Identifier).IsValid() {
+			return nil, fmt.Errorf("invalid extracted label name '%s'", exp.Identifier)
+		}
+
+		for _, step := range path.steps {
+			x.indexed = x.indexed || step.index != 0
+		}
+		x.ids = append(x.ids, exp.Identifier)
+		x.paths = append(x.paths, path)
+	}
+	x.matched = make([]bool, len(x.ids))
+	return x, nil
+}
+
+func (x *XMLExpressionParser) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	if len(line) == 0 || lbs.ParserLabelHints().NoLabels() {
+		return line, true
+	}
+
+	if !isValidXMLStart(line) {
+		addErrLabel(errXML, errMissingXMLElement, lbs)
+		x.setMissing(lbs)
+		return line, true
+	}
+
+	// reset the state.
+	x.stack = x.stack[:0]
+	x.siblings = x.siblings[:0]
+	x.captures = x.captures[:0]
+	for i := range x.matched {
+		x.matched[i] = false
+	}
+	x.reader.Reset(line)
+
+	dec := xml.NewDecoder(&x.reader)
+	matches := 0
+loop:
+	for matches < len(x.ids) {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errMissingXMLElement
+			}
+			addErrLabel(errXML, err, lbs)
+			break loop
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			frame := xmlFrame{name: t.Name.Local}
+			if x.indexed {
+				depth := len(x.stack)
+				if len(x.siblings) <= depth {
+					x.siblings = append(x.siblings, map[string]int{})
+				}
+				x.siblings[depth][frame.name]++
+				frame.index = x.siblings[depth][frame.name]
+				// children of the new element have their own positions.
+				if len(x.siblings) > depth+1 {
+					clear(x.siblings[depth+1])
+				}
+			}
+			x.stack = append(x.stack, frame)
+
+			for i, path := range x.paths {
+				if x.matched[i] || !path.matches(x.stack) {
+					continue
+				}
+				if path.attr == "" {
+					x.matched[i] = true
+					x.captures = append(x.captures, xmlCapture{expr: i, depth: len(x.stack)})
+					continue
+				}
+				for _, attr := range t.Attr {
+					if attr.Name.Local == path.attr {
+						x.matched[i] = true
+						x.set(i, []byte(attr.Value), lbs)
+						matches++
+						break
+					}
+				}
+			}
+		case xml.CharData:
+			for i := range x.captures {
+				x.captures[i].text = append(x.captures[i].text, t...)
+			}
+		case xml.EndElement:
+			for i := 0; i < len(x.captures); i++ {
+				if x.captures[i].depth != len(x.stack) {
+					continue
+				}
+				x.set(x.captures[i].expr, bytes.TrimSpace(x.captures[i].text), lbs)
+				matches++
+				x.captures = append(x.captures[:i], x.captures[i+1:]...)
+				i--
+			}
+			x.stack = x.stack[:len(x.stack)-1]
+			if len(x.stack) == 0 {
+				// we're done with the root element.
+				break loop
+			}
+		}
+	}
+
+	// Ensure there's a label for every value
+	if matches < len(x.ids) {
+		x.setMissing(lbs)
+	}
+	return line, true
+}
+
+func (x *XMLExpressionParser) set(i int, value []byte, lbs *LabelsBuilder) {
+	identifier := x.ids[i]
+	key, _ := x.keys.Get(unsafeGetBytes(identifier), func() (string, bool) {
+		if lbs.BaseHas(identifier) {
+			identifier = identifier + duplicateSuffix
+		}
+		return identifier, true
+	})
+	if bytes.ContainsRune(value, utf8.RuneError) {
+		value = bytes.Map(removeInvalidUtf, value)
+	}
+	lbs.Set(ParsedLabel, key, string(value))
+}
+
+func (x *XMLExpressionParser) setMissing(lbs *LabelsBuilder) {
+	for _, id := range x.ids {
+		if _, ok := lbs.Get(id); !ok {
+			lbs.Set(ParsedLabel, id, "")
+		}
+	}
+}
+
+func (x *XMLExpressionParser) RequiredLabelNames() []string { return []string{} }
@@ -1480,3 +1480,345 @@ func BenchmarkJsonExpressionParser(b *testing.B) {
 		})
 	}
 }
+
+func Test_csvParser_Parse(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		delimiter   string
+		quote       string
+		expressions []LabelExtractionExpr
+		line        []byte
+		lbs         labels.Labels
+		want        labels.Labels
+		hints       ParserHint
+	}{
+		{
+			"header mapping",
+			"ts,method,path,status",
+			",", `"`,
+			nil,
+			[]byte(`2024-01-01T00:00:00Z,GET,/api/v1/push,204`),
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo",
+				"ts", "2024-01-01T00:00:00Z",
+				"method", "GET",
+				"path", "/api/v1/push",
+				"status", "204",
+			),
+			NoParserHints(),
+		},
+		{
+			"quoted fields",
+			"method,msg,status",
+			",", `"`,
+			nil,
+			[]byte(`POST,"failed, ""retrying""",500`),
+			labels.EmptyLabels(),
+			labels.FromStrings("method", "POST",
+				"msg", `failed, "retrying"`,
+				"status", "500",
+			),
+			NoParserHints(),
+		},
+		{
+			"custom delimiter and quote",
+			"method;msg",
+			";", "'",
+			nil,
+			[]byte(`GET;'a;b'`),
+			labels.EmptyLabels(),
+			labels.FromStrings("method", "GET",
+				"msg", "a;b",
+			),
+			NoParserHints(),
+		},
+		{
+			"no quote",
+			"a|b",
+			"|", "",
+			nil,
+			[]byte(`"x|y"`),
+			labels.EmptyLabels(),
+			labels.FromStrings("a", `"x`,
+				"b", `y"`,
+			),
+			NoParserHints(),
+		},
+		{
+			"empty header columns are skipped and extra fields ignored",
+			"a,,c",
+			",", `"`,
+			nil,
+			[]byte(`1,2,3,4`),
+			labels.EmptyLabels(),
+			labels.FromStrings("a", "1",
+				"c", "3",
+			),
+			NoParserHints(),
+		},
+		{
+			"sanitized header and duplicate",
+			"app,status-code",
+			",", `"`,
+			nil,
+			[]byte(`bar,200`),
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo",
+				"app_extracted", "bar",
+				"status_code", "200",
+			),
+			NoParserHints(),
+		},
+		{
+			"expressions",
+			"ts,method,path,status",
+			",", `"`,
+			[]LabelExtractionExpr{
+				NewLabelExtractionExpr("verb", "method"),
+				NewLabelExtractionExpr("status", "status"),
+			},
+			[]byte(`2024-01-01T00:00:00Z,GET,/api/v1/push`),
+			labels.EmptyLabels(),
+			labels.FromStrings("verb", "GET",
+				"status", "",
+			),
+			NoParserHints(),
+		},
+		{
+			"hints",
+			"ts,method,path,status",
+			",", `"`,
+			nil,
+			[]byte(`2024-01-01T00:00:00Z,GET,/api/v1/push,204`),
+			labels.EmptyLabels(),
+			labels.FromStrings("status", "204"),
+			NewParserHint([]string{"status"}, nil, false, true, "", nil),
+		},
+		{
+			"unterminated quote",
+			"a,b",
+			",", `"`,
+			nil,
+			[]byte(`1,"2`),
+			labels.EmptyLabels(),
+			labels.FromStrings("__error__", "CSVParserErr",
+				"__error_details__", "unterminated quoted field",
+			),
+			NoParserHints(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewCSVParser(tt.header, tt.delimiter, tt.quote, tt.expressions)
+			require.NoError(t, err)
+
+			b := NewBaseLabelsBuilderWithGrouping(nil, tt.hints, false, false).ForLabels(tt.lbs, tt.lbs.Hash())
+			b.Reset()
+			_, _ = p.Process(0, tt.line, b)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
+func TestNewCSVParserFailures(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		delimiter   string
+		quote       string
+		expressions []LabelExtractionExpr
+		error       string
+	}{
+		{"empty delimiter", "a,b", "", `"`, nil, "csv delimiter must be a single character, got ''"},
+		{"long quote", "a,b", ",", `''`, nil, "csv quote must be a single character, got ''''"},
+		{"same delimiter and quote", "a,b", ",", ",", nil, "csv quote and delimiter must be different"},
+		{"duplicate column", "a,b,a", ",", `"`, nil, "duplicate csv column name 'a'"},
+		{"bad header", `a,"b`, ",", `"`, nil, `cannot parse csv header [a,"b]: unterminated quoted field`},
+		{"unknown column", "a,b", ",", `"`, []LabelExtractionExpr{NewLabelExtractionExpr("c", "c")}, "csv column 'c' not found in header"},
+		{"invalid label", "a,b", ",", `"`, []LabelExtractionExpr{NewLabelExtractionExpr("1a", "a")}, "invalid extracted label name '1a'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCSVParser(tt.header, tt.delimiter, tt.quote, tt.expressions)
+			require.EqualError(t, err, tt.error)
+		})
+	}
+}
+
+func Test_xmlParser_Parse(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  []byte
+		lbs   labels.Labels
+		want  labels.Labels
+		hints ParserHint
+	}{
+		{
+			"multi depth",
+			[]byte(`<event level="info"><user id="1">bob</user><http><method>GET</method><status>200</status></http></event>`),
+			labels.EmptyLabels(),
+			labels.FromStrings("level", "info",
+				"user", "bob",
+				"user_id", "1",
+				"http_method", "GET",
+				"http_status", "200",
+			),
+			NoParserHints(),
+		},
+		{
+			"declaration namespaces and whitespaces",
+			[]byte(`<?xml version="1.0"?> <s:Envelope xmlns:s="urn:x"> <s:Body> <code> 42 </code> </s:Body> </s:Envelope>`),
+			labels.EmptyLabels(),
+			labels.FromStrings("Body_code", "42"),
+			NoParserHints(),
+		},
+		{
+			"duplicate extraction",
+			[]byte(`<e><app>bar</app><bad-key>1</bad-key></e>`),
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo",
+				"app_extracted", "bar",
+				"bad_key", "1",
+			),
+			NoParserHints(),
+		},
+		{
+			"hints",
+			[]byte(`<event><user id="1">bob</user><http><method>GET</method><status>200</status></http></event>`),
+			labels.EmptyLabels(),
+			labels.FromStrings("http_status", "200"),
+			NewParserHint([]string{"http_status"}, nil, false, true, "", nil),
+		},
+		{
+			"not xml",
+			[]byte(`level=info`),
+			labels.EmptyLabels(),
+			labels.FromStrings("__error__", "XMLParserErr",
+				"__error_details__", "expecting xml element, but it is not",
+			),
+			NoParserHints(),
+		},
+		{
+			"malformed",
+			[]byte(`<event><a>1</b></event>`),
+			labels.EmptyLabels(),
+			labels.FromStrings("__error__", "XMLParserErr",
+				"__error_details__", "XML syntax error on line 1: element <a> closed by </b>",
+			),
+			NoParserHints(),
+		},
+	}
+	for _, tt := range tests {
+		x := NewXMLParser()
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBaseLabelsBuilderWithGrouping(nil, tt.hints, false, false).ForLabels(tt.lbs, tt.lbs.Hash())
+			b.Reset()
+			_, _ = x.Process(0, tt.line, b)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
+func TestXMLExpressionParser(t *testing.T) {
+	testLine := []byte(`<?xml version="1.0"?><event id="7"><user id="1">bob</user><items><item sku="a">first</item><item sku="b">second <b>bold</b></item></items></event>`)
+
+	tests := []struct {
+		name        string
+		line        []byte
+		expressions []LabelExtractionExpr
+		lbs         labels.Labels
+		want        labels.Labels
+	}{
+		{
+			"element",
+			testLine,
+			[]LabelExtractionExpr{NewLabelExtractionExpr("user", "/event/user")},
+			labels.EmptyLabels(),
+			labels.FromStrings("user", "bob"),
+		},
+		{
+			"attributes",
+			testLine,
+			[]LabelExtractionExpr{
+				NewLabelExtractionExpr("event_id", "/event/@id"),
+				NewLabelExtractionExpr("user_id", "event/user/@id"),
+			},
+			labels.EmptyLabels(),
+			labels.FromStrings("event_id", "7", "user_id", "1"),
+		},
+		{
+			"index and descendant text",
+			testLine,
+			[]LabelExtractionExpr{
+				NewLabelExtractionExpr("second", "/event/items/item[2]"),
+				NewLabelExtractionExpr("sku", "//item[2]/@sku"),
+			},
+			labels.EmptyLabels(),
+			labels.FromStrings("second", "second bold", "sku", "b"),
+		},
+		{
+			"first match and wildcard",
+			testLine,
+			[]LabelExtractionExpr{NewLabelExtractionExpr("item", "/event/*/item")},
+			labels.EmptyLabels(),
+			labels.FromStrings("item", "first"),
+		},
+		{
+			"missing",
+			testLine,
+			[]LabelExtractionExpr{NewLabelExtractionExpr("missing", "/event/missing")},
+			labels.EmptyLabels(),
+			labels.FromStrings("missing", ""),
+		},
+		{
+			"duplicate extraction",
+			testLine,
+			[]LabelExtractionExpr{NewLabelExtractionExpr("app", "//user")},
+			labels.FromStrings("app", "foo"),
+			labels.FromStrings("app", "foo", "app_extracted", "bob"),
+		},
+		{
+			"not xml",
+			[]byte(`{"user":"bob"}`),
+			[]LabelExtractionExpr{NewLabelExtractionExpr("user", "//user")},
+			labels.EmptyLabels(),
+			labels.FromStrings("user", "",
+				"__error__", "XMLParserErr",
+				"__error_details__", "expecting xml element, but it is not",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, err := NewXMLExpressionParser(tt.expressions)
+			require.NoError(t, err)
+
+			b := NewBaseLabelsBuilder().ForLabels(tt.lbs, tt.lbs.Hash())
+			b.Reset()
+			_, _ = x.Process(0, tt.line, b)
+			require.Equal(t, tt.want, b.LabelsResult().Labels())
+		})
+	}
+}
+
+func TestXMLExpressionParserFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression LabelExtractionExpr
+		error      string
+	}{
+		{"empty", NewLabelExtractionExpr("app", ``), "empty step in xml path ''"},
+		{"empty step", NewLabelExtractionExpr("app", `a//b`), "empty step in xml path 'a//b'"},
+		{"attribute not last", NewLabelExtractionExpr("app", `a/@b/c`), "attribute must be the last step of xml path 'a/@b/c'"},
+		{"only attribute", NewLabelExtractionExpr("app", `/@b`), "xml path '/@b' must select at least one element"},
+		{"bad index", NewLabelExtractionExpr("app", `a[0]`), "invalid index in xml path 'a[0]'"},
+		{"missing bracket", NewLabelExtractionExpr("app", `a[1`), "missing closing ']' in xml path 'a[1'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewXMLExpressionParser([]LabelExtractionExpr{tt.expression})
+			require.EqualError(t, err, fmt.Sprintf("cannot parse expression [%s]: %s", tt.expression.Expression, tt.error))
+		})
+	}
+}
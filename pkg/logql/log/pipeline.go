@@ -30,6 +30,34 @@ type StreamPipeline interface {
 	ReferencedStructuredMetadata() bool
 }
 
+// LineReader is implemented by the StreamPipeline and StreamSampleExtractor
+// implementations that can tell whether they read the log lines. The ones that
+// don't, like metric queries without line filters or parsers, return the log
+// line unchanged and don't need it to process an entry.
+type LineReader interface {
+	ReadsLine() bool
+}
+
+// ReadsLine returns whether the StreamPipeline or StreamSampleExtractor reads
+// the log lines it processes, defaulting to true if it can't tell.
+func ReadsLine(v interface{}) bool {
+	r, ok := v.(LineReader)
+	return !ok || r.ReadsLine()
+}
+
+// stagesReadLine returns whether any of the stages reads the log line, that
+// is does more than filtering and dropping labels.
+func stagesReadLine(stages []Stage) bool {
+	for _, s := range stages {
+		switch s.(type) {
+		case *noopStage, LabelFilterer, *DropLabels, *KeepLabels:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
 // Stage is a single step of a Pipeline.
 // A Stage implementation should never mutate the line passed, but instead either
 // return the line unchanged or allocate a new line.
@@ -101,6 +129,10 @@ func (n noopStreamPipeline) ReferencedStructuredMetadata() bool {
 	return false
 }
 
+func (n noopStreamPipeline) ReadsLine() bool {
+	return false
+}
+
 func (n noopStreamPipeline) Process(_ int64, line []byte, structuredMetadata ...labels.Label) ([]byte, LabelsResult, bool) {
 	n.builder.Reset()
 	for i, lb := range structuredMetadata {
@@ -184,10 +216,13 @@ type streamPipeline struct {
 	stages     []Stage
 	builder    *LabelsBuilder
 	offsetsBuf []int
+	readsLine  bool
+
+	requirements contentRequirements
 }
 
 func NewStreamPipeline(stages []Stage, labelsBuilder *LabelsBuilder) StreamPipeline {
-	return &streamPipeline{stages, labelsBuilder, make([]int, 0, 10)}
+	return &streamPipeline{stages, labelsBuilder, make([]int, 0, 10), stagesReadLine(stages), newContentRequirements(stages)}
 }
 
 func (p *pipeline) ForStream(labels labels.Labels) StreamPipeline {
@@ -223,6 +258,18 @@ func (p *streamPipeline) ReferencedStructuredMetadata() bool {
 	return p.builder.referencedStructuredMetadata
 }
 
+func (p *streamPipeline) ReadsLine() bool {
+	return p.readsLine
+}
+
+func (p *streamPipeline) RequiredLineContents() [][]byte {
+	return p.requirements.lineContents
+}
+
+func (p *streamPipeline) RequiredStructuredMetadata() []string {
+	return p.requirements.structuredMetadata(p.builder.base)
+}
+
 func (p *streamPipeline) Process(ts int64, line []byte, structuredMetadata ...labels.Label) ([]byte, LabelsResult, bool) {
 	var ok bool
 	p.builder.Reset()
@@ -323,6 +370,23 @@ func (sp *filteringStreamPipeline) ReferencedStructuredMetadata() bool {
 	return false
 }
 
+func (sp *filteringStreamPipeline) ReadsLine() bool {
+	for _, filter := range sp.filters {
+		if ReadsLine(filter.pipeline) {
+			return true
+		}
+	}
+	return ReadsLine(sp.pipeline)
+}
+
+func (sp *filteringStreamPipeline) RequiredLineContents() [][]byte {
+	return RequiredLineContents(sp.pipeline)
+}
+
+func (sp *filteringStreamPipeline) RequiredStructuredMetadata() []string {
+	return RequiredStructuredMetadata(sp.pipeline)
+}
+
 func (sp *filteringStreamPipeline) BaseLabels() LabelsResult {
 	return sp.pipeline.BaseLabels()
 }
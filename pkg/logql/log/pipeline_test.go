@@ -117,6 +117,29 @@ func TestPipeline(t *testing.T) {
 	}
 }
 
+func TestReadsLine(t *testing.T) {
+	lbs := labels.FromStrings("foo", "bar")
+	labelFilter := NewStringLabelFilter(labels.MustNewMatcher(labels.MatchEqual, "trace_id", "123"))
+
+	require.False(t, ReadsLine(NewNoopPipeline().ForStream(lbs)))
+	require.False(t, ReadsLine(NewPipeline([]Stage{labelFilter, NewDropLabels(nil)}).ForStream(lbs)))
+	require.True(t, ReadsLine(NewPipeline([]Stage{labelFilter, newMustLineFormatter("{{.foo}}")}).ForStream(lbs)))
+	require.True(t, ReadsLine(NewPipeline([]Stage{NewLogfmtParser(false, false), labelFilter}).ForStream(lbs)))
+	require.True(t, ReadsLine(&stubStreamPipeline{}))
+
+	count := mustSampleExtractor(NewLineSampleExtractor(CountExtractor, []Stage{labelFilter}, nil, false, false))
+	require.False(t, ReadsLine(count.ForStream(lbs)))
+	bytes := mustSampleExtractor(NewLineSampleExtractor(BytesExtractor, []Stage{labelFilter}, nil, false, false))
+	require.True(t, ReadsLine(bytes.ForStream(lbs)))
+	unwrap := mustSampleExtractor(LabelExtractorWithStages("latency", ConvertFloat, nil, false, false, []Stage{labelFilter}, NoopStage))
+	require.False(t, ReadsLine(unwrap.ForStream(lbs)))
+
+	filtered := NewFilteringPipeline([]PipelineFilter{
+		newPipelineFilter(0, 10, labels.FromStrings("foo", "bar"), labels.EmptyLabels(), "baz"),
+	}, NewNoopPipeline())
+	require.True(t, ReadsLine(filtered.ForStream(lbs)))
+}
+
 func TestPipelineWithStructuredMetadata(t *testing.T) {
 	lbs := labels.FromStrings("foo", "bar")
 	structuredMetadata := labels.FromStrings("user", "bob")
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"errors"
+	"math"
+)
+
+var ErrSampleInvalidRatio = errors.New("sample: ratio must be greater than 0 and at most 1")
+
+// Sampler is a `Stage` keeping a deterministic ratio of the log lines, e.g.
+// `| sample 0.01`.
+//
+// A line is kept if the hash of its stream and its timestamp falls within the
+// lowest ratio of the hash space, so every stream is sampled with the same
+// ratio and the same line is always sampled.
+type Sampler struct {
+	cutoff uint64
+}
+
+// NewSampler creates a Sampler keeping the given ratio of the lines.
+func NewSampler(ratio float64) (*Sampler, error) {
+	if !ValidSampleRatio(ratio) {
+		return nil, ErrSampleInvalidRatio
+	}
+	return &Sampler{cutoff: sampleCutoff(ratio)}, nil
+}
+
+// ValidSampleRatio returns true if ratio is in the range (0, 1].
+func ValidSampleRatio(ratio float64) bool {
+	return ratio > 0 && ratio <= 1
+}
+
+// sampleCutoff returns the highest line hash sampled with the given ratio.
+func sampleCutoff(ratio float64) uint64 {
+	if ratio >= 1 {
+		return math.MaxUint64
+	}
+	// multiplying a value below 1 by 2^64 cannot overflow an uint64.
+	return uint64(ratio * (1 << 64))
+}
+
+// Process implements `Stage` interface
+func (s *Sampler) Process(ts int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	return line, mixHash(lbs.BaseHash()^mixHash(uint64(ts))) <= s.cutoff
+}
+
+// RequiredLabelNames implements `Stage` interface
+func (s *Sampler) RequiredLabelNames() []string {
+	return []string{}
+}
+
+// mixHash is the finalizer of splitmix64, it spreads the bits of x evenly so
+// that close timestamps of a stream are sampled independently.
+func mixHash(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"math"
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewSampler(t *testing.T) {
+	for _, ratio := range []float64{0, -0.1, 1.1, math.NaN()} {
+		_, err := NewSampler(ratio)
+		require.ErrorIs(t, err, ErrSampleInvalidRatio)
+	}
+
+	s, err := NewSampler(1)
+	require.NoError(t, err)
+	lbs := labels.FromStrings("app", "foo")
+	b := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+	for ts := int64(0); ts < 100; ts++ {
+		_, ok := s.Process(ts, []byte("line"), b)
+		require.True(t, ok)
+	}
+}
+
+func Test_sampleCutoff(t *testing.T) {
+	require.Equal(t, uint64(math.MaxUint64), sampleCutoff(1))
+	require.Equal(t, uint64(1)<<62, sampleCutoff(0.25))
+	require.Equal(t, uint64(1)<<63, sampleCutoff(0.5))
+}
+
+func Test_Sampler(t *testing.T) {
+	s, err := NewSampler(0.01)
+	require.NoError(t, err)
+
+	base := NewBaseLabelsBuilder()
+	var sampled, total int
+	for i := 0; i < 1000; i++ {
+		lbs := labels.FromStrings("app", "foo", "pod", string(rune('a'+i%26))+string(rune('a'+i/26)))
+		b := base.ForLabels(lbs, lbs.Hash())
+
+		var streamSampled int
+		for ts := int64(0); ts < 1000; ts++ {
+			total++
+			_, ok := s.Process(ts*1e9, []byte("line"), b)
+			if !ok {
+				continue
+			}
+			sampled++
+			streamSampled++
+
+			// the same entry is always sampled
+			_, again := s.Process(ts*1e9, []byte("another line"), b)
+			require.True(t, again)
+		}
+		// every stream is sampled with the same ratio.
+		require.InDelta(t, 10, streamSampled, 15)
+	}
+	require.InDelta(t, 0.01, float64(sampled)/float64(total), 0.001)
+}
@@ -0,0 +1,225 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrWordFilterInvalidPattern   = errors.New("word: pattern must contain between 1 and 64 terms")
+	ErrWordFilterInvalidOperation = errors.New("word: invalid operation")
+)
+
+// Terms splits s into its lower-cased terms. A term is a run of letters,
+// digits and underscores, any other character separates terms. Duplicate
+// terms are only returned once.
+//
+// Terms is shared by the word filters and the bloom tokenizer, so that the
+// terms tested at query time are the same that have been indexed.
+func Terms(s string) []string {
+	var terms []string
+	for len(s) > 0 {
+		start := strings.IndexFunc(s, isTermRune)
+		if start < 0 {
+			break
+		}
+		s = s[start:]
+		end := strings.IndexFunc(s, isNotTermRune)
+		if end < 0 {
+			end = len(s)
+		}
+		term := strings.ToLower(s[:end])
+		s = s[end:]
+
+		if !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func isTermRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isNotTermRune(r rune) bool {
+	return !isTermRune(r)
+}
+
+// maxWordFilterTerms is the maximum number of terms of a word filter pattern.
+const maxWordFilterTerms = 64
+
+// wordFilter matches inputs containing all of its terms as whole words,
+// regardless of case.
+type wordFilter struct {
+	terms [][]byte
+}
+
+func newWordFilter(pattern string) (*wordFilter, error) {
+	terms := Terms(pattern)
+	if len(terms) == 0 || len(terms) > maxWordFilterTerms {
+		return nil, ErrWordFilterInvalidPattern
+	}
+	f := &wordFilter{terms: make([][]byte, 0, len(terms))}
+	for _, t := range terms {
+		f.terms = append(f.terms, []byte(t))
+	}
+	return f, nil
+}
+
+func (f *wordFilter) filter(input []byte) bool {
+	// found is a bitmap of the terms seen so far.
+	var found uint64
+	all := uint64(1)<<len(f.terms) - 1
+	for len(input) > 0 {
+		start := bytes.IndexFunc(input, isTermRune)
+		if start < 0 {
+			return false
+		}
+		input = input[start:]
+		end := bytes.IndexFunc(input, isNotTermRune)
+		if end < 0 {
+			end = len(input)
+		}
+		word := input[:end]
+		input = input[end:]
+
+		for i, t := range f.terms {
+			if bytes.EqualFold(word, t) {
+				found |= 1 << i
+				if found == all {
+					return true
+				}
+				break
+			}
+		}
+	}
+	return false
+}
+
+// WordLineFilter is a `LineFilter` matching lines which contain all the terms of
+// the pattern as whole words, e.g. `|= word("timeout")`.
+type WordLineFilter struct {
+	word *wordFilter
+	ty   LineMatchType
+}
+
+// NewWordLineFilter is used to construct a word filter as a `LineFilter`.
+func NewWordLineFilter(pattern string, ty LineMatchType) (*WordLineFilter, error) {
+	switch ty {
+	case LineMatchEqual, LineMatchNotEqual:
+	default:
+		return nil, ErrWordFilterInvalidOperation
+	}
+
+	word, err := newWordFilter(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &WordLineFilter{
+		word: word,
+		ty:   ty,
+	}, nil
+}
+
+// Filter implements `Filterer` interface.
+func (f *WordLineFilter) Filter(line []byte) bool {
+	if f.ty == LineMatchNotEqual {
+		return !f.word.filter(line)
+	}
+	return f.word.filter(line)
+}
+
+// ToStage implements `Filterer` interface.
+func (f *WordLineFilter) ToStage() Stage {
+	return f
+}
+
+// `Process` implements `Stage` interface
+func (f *WordLineFilter) Process(_ int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+	return line, f.Filter(line)
+}
+
+// `RequiredLabelNames` implements `Stage` interface
+func (f *WordLineFilter) RequiredLabelNames() []string {
+	return []string{} // empty for line filter
+}
+
+// WordLabelFilter matches labels whose value contains all the terms of the
+// pattern as whole words, e.g. `| request_body = word("timeout")`.
+type WordLabelFilter struct {
+	word *wordFilter
+	Ty   LabelFilterType
+
+	// Label is the name of the label whose value is matched.
+	Label string
+
+	// patError records if given pattern is invalid.
+	patError error
+
+	// Pattern is the original pattern, kept to display it in errors.
+	Pattern string
+}
+
+// NewWordLabelFilter is used to construct a word filter as a `LabelFilterer`.
+func NewWordLabelFilter(pattern, label string, ty LabelFilterType) *WordLabelFilter {
+	word, err := newWordFilter(pattern)
+	return &WordLabelFilter{
+		word:     word,
+		Label:    label,
+		Ty:       ty,
+		patError: err,
+		Pattern:  pattern,
+	}
+}
+
+// `Process` implements `Stage` interface
+func (f *WordLabelFilter) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	return line, f.filterTy(lbs)
+}
+
+func (f *WordLabelFilter) isLabelFilterer() {}
+
+// `RequiredLabelNames` implements `Stage` interface
+func (f *WordLabelFilter) RequiredLabelNames() []string {
+	return []string{f.Label}
+}
+
+// PatternError returns the error of an invalid pattern, so that a proper 400
+// error is returned to the client.
+func (f *WordLabelFilter) PatternError() error {
+	return f.patError
+}
+
+func (f *WordLabelFilter) filterTy(lbs *LabelsBuilder) bool {
+	if lbs.HasErr() {
+		// only the string matchers can filter out errors.
+		return true
+	}
+	input, ok := lbs.Get(f.Label)
+	if !ok || f.word == nil {
+		return false
+	}
+
+	switch f.Ty {
+	case LabelFilterEqual:
+		return f.word.filter([]byte(input))
+	case LabelFilterNotEqual:
+		return !f.word.filter([]byte(input))
+	}
+	return false
+}
+
+// `String` implements fmt.Stringer interface, by which also implements `LabelFilterer` interface.
+func (f *WordLabelFilter) String() string {
+	eq := "=" // LabelFilterEqual -> "==", we don't want in string representation of word label filter.
+	if f.Ty == LabelFilterNotEqual {
+		eq = LabelFilterNotEqual.String()
+	}
+
+	return fmt.Sprintf("%s%sword(%q)", f.Label, eq, f.Pattern)
+}
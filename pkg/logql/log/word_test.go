@@ -0,0 +1,171 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Terms(t *testing.T) {
+	for _, c := range []struct {
+		in       string
+		expected []string
+	}{
+		{in: "", expected: nil},
+		{in: " -- ", expected: nil},
+		{in: "timeout", expected: []string{"timeout"}},
+		{in: "Upstream TIMEOUT after 30s", expected: []string{"upstream", "timeout", "after", "30s"}},
+		{in: "java.net.SocketTimeoutException: Read timed out", expected: []string{"java", "net", "sockettimeoutexception", "read", "timed", "out"}},
+		{in: "request_id=abc-123 request_id=abc", expected: []string{"request_id", "abc", "123"}},
+		{in: "Größe überschritten", expected: []string{"größe", "überschritten"}},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			require.Equal(t, c.expected, Terms(c.in))
+		})
+	}
+}
+
+func Test_WordLineFilterTy(t *testing.T) {
+	cases := []struct {
+		name     string
+		pat      string
+		ty       LineMatchType
+		input    []string
+		expected []int // matched line indexes from the input
+
+		err error
+	}{
+		{
+			name: "single term",
+			pat:  "timeout",
+			ty:   LineMatchEqual,
+			input: []string{
+				"upstream timeout after 30s",
+				"upstream TimeOut after 30s",
+				"upstream timeouts after 30s",
+				"read_timeout=30s",
+				"timeout",
+				"",
+			},
+			expected: []int{0, 1, 4},
+		},
+		{
+			name: "multiple terms in any order",
+			pat:  "after timeout",
+			ty:   LineMatchEqual,
+			input: []string{
+				"upstream timeout after 30s",
+				"after the request, timeout",
+				"upstream timeout",
+			},
+			expected: []int{0, 1},
+		},
+		{
+			name: "not equal operator",
+			pat:  "timeout",
+			ty:   LineMatchNotEqual,
+			input: []string{
+				"upstream timeout after 30s",
+				"upstream timeouts after 30s",
+			},
+			expected: []int{1},
+		},
+		{
+			name: "regex operator",
+			pat:  "timeout",
+			ty:   LineMatchRegexp, // not supported
+			err:  ErrWordFilterInvalidOperation,
+		},
+		{
+			name: "pattern without terms",
+			pat:  "--",
+			ty:   LineMatchEqual,
+			err:  ErrWordFilterInvalidPattern,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, err := NewWordLineFilter(c.pat, c.ty)
+			if c.err != nil {
+				require.ErrorIs(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []int
+			for i, line := range c.input {
+				if f.Filter([]byte(line)) {
+					got = append(got, i)
+				}
+				_, ok := f.Process(0, []byte(line), nil)
+				assert.Equal(t, f.Filter([]byte(line)), ok)
+			}
+			assert.Equal(t, c.expected, got)
+		})
+	}
+}
+
+func Test_WordLabelFilterTy(t *testing.T) {
+	cases := []struct {
+		name          string
+		pat           string
+		ty            LabelFilterType
+		label         string
+		val           string
+		expectedMatch bool
+
+		fail bool
+	}{
+		{
+			name:          "equal operator",
+			pat:           "timeout",
+			ty:            LabelFilterEqual,
+			label:         "body",
+			val:           "upstream timeout after 30s",
+			expectedMatch: true,
+		},
+		{
+			name:          "not equal operator",
+			pat:           "refused",
+			ty:            LabelFilterNotEqual,
+			label:         "body",
+			val:           "upstream timeout after 30s",
+			expectedMatch: true,
+		},
+		{
+			name:          "missing label",
+			pat:           "timeout",
+			ty:            LabelFilterEqual,
+			label:         "other",
+			val:           "upstream timeout after 30s",
+			expectedMatch: false,
+		},
+		{
+			name:  "pattern-invalid",
+			pat:   "",
+			ty:    LabelFilterEqual,
+			label: "body",
+			val:   "upstream timeout after 30s",
+			fail:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			lf := NewWordLabelFilter(c.pat, c.label, c.ty)
+			if c.fail {
+				assert.ErrorIs(t, lf.PatternError(), ErrWordFilterInvalidPattern)
+				return
+			}
+
+			lbs := labels.FromStrings("body", c.val)
+			lbb := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+			_, ok := lf.Process(0, []byte("x"), lbb)
+			require.Empty(t, lbb.GetErr())
+			assert.Equal(t, c.expectedMatch, ok)
+		})
+	}
+}
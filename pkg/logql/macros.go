@@ -0,0 +1,50 @@
+package logql
+
+import (
+	"context"
+	"maps"
+
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// ExpandQueryMacros replaces the `| @name` macros referenced by expr with the
+// pipelines of the query macros of the tenants. The expression is modified in
+// place, the returned boolean reports if any macro has been expanded.
+func ExpandQueryMacros(ctx context.Context, expr syntax.Expr, limits Limits) (syntax.Expr, bool, error) {
+	if !syntax.HasMacros(expr) {
+		return expr, false, nil
+	}
+
+	tenants, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	expanded, err := syntax.ExpandMacros(expr, tenantsQueryMacros(ctx, tenants, limits))
+	if err != nil {
+		return nil, false, err
+	}
+	return expanded, true, nil
+}
+
+// tenantsQueryMacros returns the query macros usable by a query of the given
+// tenants. In multi-tenant queries, only the macros defined with the same
+// pipeline by all the tenants can be used.
+func tenantsQueryMacros(ctx context.Context, tenants []string, limits Limits) map[string]string {
+	var macros map[string]string
+	for i, id := range tenants {
+		tenantMacros := limits.QueryMacros(ctx, id)
+		if i == 0 {
+			macros = maps.Clone(tenantMacros)
+			continue
+		}
+		for name, pipeline := range macros {
+			if p, ok := tenantMacros[name]; !ok || p != pipeline {
+				delete(macros, name)
+			}
+		}
+	}
+	return macros
+}
@@ -0,0 +1,90 @@
+package logql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+type tenantsMacrosLimits struct {
+	fakeLimits
+	macros map[string]map[string]string
+}
+
+func (l tenantsMacrosLimits) QueryMacros(_ context.Context, userID string) map[string]string {
+	return l.macros[userID]
+}
+
+func TestExpandQueryMacros(t *testing.T) {
+	limits := tenantsMacrosLimits{
+		macros: map[string]map[string]string{
+			"a": {"parse": `| json`, "errors": `|= "error"`},
+			"b": {"parse": `| json`, "errors": `|~ "(?i)error"`},
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		tenants  string
+		query    string
+		expected string
+		err      string
+	}{
+		{"no macro", "a", `{app="foo"} | json`, `{app="foo"} | json`, ""},
+		{"single tenant", "a", `{app="foo"} | @errors | @parse`, `{app="foo"} |= "error" | json`, ""},
+		{"multi tenants", "a|b", `{app="foo"} | @parse`, `{app="foo"} | json`, ""},
+		{"multi tenants with diverging macro", "a|b", `{app="foo"} | @errors`, "", "undefined macro: @errors"},
+		{"unknown tenant", "c", `{app="foo"} | @parse`, "", "undefined macro: @parse"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := syntax.ParseExpr(tc.query)
+			require.NoError(t, err)
+
+			ctx := user.InjectOrgID(context.Background(), tc.tenants)
+			expanded, ok, err := ExpandQueryMacros(ctx, expr, limits)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				require.ErrorIs(t, err, logqlmodel.ErrParse)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.query != tc.expected, ok)
+			require.Equal(t, tc.expected, expanded.String())
+		})
+	}
+}
+
+func TestEngine_QueryMacros(t *testing.T) {
+	limits := &fakeLimits{
+		maxSeries:   100000,
+		queryMacros: map[string]string{"filter": `|= "1" |= "2"`},
+	}
+	eng := NewEngine(EngineOpts{}, getLocalQuerier(100000), limits, log.NewNopLogger())
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	exec := func(qs string) interface{} {
+		params, err := NewLiteralParams(qs, time.Unix(0, 0), time.Unix(1000, 0), 60*time.Second, 0, logproto.FORWARD, 1000, nil, nil)
+		require.NoError(t, err)
+		res, err := eng.Query(params).Exec(ctx)
+		require.NoError(t, err)
+		return res.Data
+	}
+
+	require.Equal(t,
+		exec(`sum(count_over_time({app="foo"} |= "1" |= "2" [1m]))`),
+		exec(`sum(count_over_time({app="foo"} | @filter [1m]))`),
+	)
+
+	params, err := NewLiteralParams(`{app="foo"} | @unknown`, time.Unix(0, 0), time.Unix(1000, 0), 0, 0, logproto.FORWARD, 1000, nil, nil)
+	require.NoError(t, err)
+	_, err = eng.Query(params).Exec(ctx)
+	require.ErrorIs(t, err, logqlmodel.ErrParse)
+}
@@ -250,6 +250,10 @@ func aggregator(r *syntax.RangeAggregationExpr) (BatchRangeVectorAggregator, err
 		return last, nil
 	case syntax.OpRangeTypeAbsent:
 		return one, nil
+	case syntax.OpRangeTypeChanges:
+		return changesOverTime, nil
+	case syntax.OpRangeTypeDistinct:
+		return distinctOverTime, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -504,6 +508,31 @@ func one(_ []promql.FPoint) float64 {
 	return 1.0
 }
 
+// changesOverTime returns the number of times the value changed within the
+// range, following the semantics of the PromQL changes function.
+func changesOverTime(samples []promql.FPoint) float64 {
+	var changes float64
+	for i := 1; i < len(samples); i++ {
+		if valueChanged(samples[i-1].F, samples[i].F) {
+			changes++
+		}
+	}
+	return changes
+}
+
+func valueChanged(prev, cur float64) bool {
+	return cur != prev && !(math.IsNaN(cur) && math.IsNaN(prev))
+}
+
+// distinctOverTime returns the exact number of distinct values within the range.
+func distinctOverTime(samples []promql.FPoint) float64 {
+	values := make(map[uint64]struct{}, len(samples))
+	for _, v := range samples {
+		values[distinctValueKey(v.F)] = struct{}{}
+	}
+	return float64(len(values))
+}
+
 // streaming range agg
 type streamRangeVectorIterator struct {
 	iter                                 iter.PeekingSampleIterator
@@ -627,6 +656,10 @@ func streamingAggregator(r *syntax.RangeAggregationExpr) (RangeStreamingAgg, err
 		return &LastOverTime{}, nil
 	case syntax.OpRangeTypeAbsent:
 		return &OneOverTime{}, nil
+	case syntax.OpRangeTypeChanges:
+		return &ChangesOverTime{}, nil
+	case syntax.OpRangeTypeDistinct:
+		return &DistinctOverTime{values: map[uint64]struct{}{}}, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -843,6 +876,36 @@ func (a *LastOverTime) at() float64 {
 	return a.v
 }
 
+type ChangesOverTime struct {
+	prev    float64
+	hasData bool
+	changes float64
+}
+
+func (a *ChangesOverTime) agg(sample promql.FPoint) {
+	if a.hasData && valueChanged(a.prev, sample.F) {
+		a.changes++
+	}
+	a.prev = sample.F
+	a.hasData = true
+}
+
+func (a *ChangesOverTime) at() float64 {
+	return a.changes
+}
+
+type DistinctOverTime struct {
+	values map[uint64]struct{}
+}
+
+func (a *DistinctOverTime) agg(sample promql.FPoint) {
+	a.values[distinctValueKey(sample.F)] = struct{}{}
+}
+
+func (a *DistinctOverTime) at() float64 {
+	return float64(len(a.values))
+}
+
 type OneOverTime struct {
 }
 
@@ -851,3 +914,58 @@ func (a *OneOverTime) agg(_ promql.FPoint) {
 func (a *OneOverTime) at() float64 {
 	return 1.0
 }
+
+// pinnedStepEvaluator returns the result of a range aggregation pinned with an
+// `@` modifier, which is evaluated only once at the pinned time, at every step
+// of the query.
+type pinnedStepEvaluator struct {
+	next StepEvaluator
+
+	step, end int64
+	current   int64
+
+	loaded bool
+	vec    promql.Vector
+}
+
+func newPinnedStepEvaluator(next StepEvaluator, q Params) *pinnedStepEvaluator {
+	step := q.Step().Milliseconds()
+	// forces at least one step.
+	if step == 0 {
+		step = 1
+	}
+	return &pinnedStepEvaluator{
+		next:    next,
+		step:    step,
+		end:     q.End().UnixMilli(),
+		current: q.Start().UnixMilli() - step, // first call to Next will set it to start
+	}
+}
+
+func (e *pinnedStepEvaluator) Next() (bool, int64, StepResult) {
+	if !e.loaded {
+		e.loaded = true
+		if ok, _, r := e.next.Next(); ok {
+			// the result is copied as evaluators may reuse their buffers.
+			e.vec = append(promql.Vector(nil), r.SampleVector()...)
+		}
+	}
+	if e.next.Error() != nil {
+		return false, 0, SampleVector{}
+	}
+
+	e.current += e.step
+	if e.current > e.end {
+		return false, 0, SampleVector{}
+	}
+	res := make(SampleVector, len(e.vec))
+	for i, s := range e.vec {
+		s.T = e.current
+		res[i] = s
+	}
+	return true, e.current, res
+}
+
+func (e *pinnedStepEvaluator) Close() error { return e.next.Close() }
+
+func (e *pinnedStepEvaluator) Error() error { return e.next.Error() }
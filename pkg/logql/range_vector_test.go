@@ -3,6 +3,7 @@ package logql
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 	"testing"
@@ -537,6 +538,8 @@ func Test_InstantQueryRangeVectorAggregations(t *testing.T) {
 		{"first", 1., syntax.OpRangeTypeFirst, false},
 		{"last", 3., syntax.OpRangeTypeLast, false},
 		{"absent", 1., syntax.OpRangeTypeAbsent, false},
+		{"changes", 2., syntax.OpRangeTypeChanges, false},
+		{"distinct", 3., syntax.OpRangeTypeDistinct, false},
 	}
 
 	var start, end int64 = 4, 4 // Instant query
@@ -556,6 +559,38 @@ func Test_InstantQueryRangeVectorAggregations(t *testing.T) {
 	}
 }
 
+func Test_ChangesAndDistinctOverTime(t *testing.T) {
+	// NaNs are equal to each other and negative zero is equal to zero.
+	values := []float64{1, 1, 2, math.NaN(), math.NaN(), math.Copysign(0, -1), 0, 2}
+	samples := make([]promql.FPoint, len(values))
+	for i, v := range values {
+		samples[i] = promql.FPoint{T: int64(i), F: v}
+	}
+
+	for _, tc := range []struct {
+		op       string
+		expected float64
+	}{
+		{syntax.OpRangeTypeChanges, 4},
+		{syntax.OpRangeTypeDistinct, 4},
+	} {
+		t.Run(tc.op, func(t *testing.T) {
+			expr := &syntax.RangeAggregationExpr{Operation: tc.op}
+
+			agg, err := aggregator(expr)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, agg(samples))
+
+			streaming, err := streamingAggregator(expr)
+			require.NoError(t, err)
+			for _, s := range samples {
+				streaming.agg(s)
+			}
+			require.Equal(t, tc.expected, streaming.at())
+		})
+	}
+}
+
 func sampleIter(negative bool) iter.PeekingSampleIterator {
 	return iter.NewPeekingSampleIterator(
 		iter.NewSortSampleIterator([]iter.SampleIterator{
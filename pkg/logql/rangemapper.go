@@ -14,14 +14,15 @@ import (
 )
 
 var splittableVectorOp = map[string]struct{}{
-	syntax.OpTypeSum:      {},
-	syntax.OpTypeCount:    {},
-	syntax.OpTypeMax:      {},
-	syntax.OpTypeMin:      {},
-	syntax.OpTypeAvg:      {},
-	syntax.OpTypeTopK:     {},
-	syntax.OpTypeSort:     {},
-	syntax.OpTypeSortDesc: {},
+	syntax.OpTypeSum:         {},
+	syntax.OpTypeCount:       {},
+	syntax.OpTypeCountValues: {},
+	syntax.OpTypeMax:         {},
+	syntax.OpTypeMin:         {},
+	syntax.OpTypeAvg:         {},
+	syntax.OpTypeTopK:        {},
+	syntax.OpTypeSort:        {},
+	syntax.OpTypeSortDesc:    {},
 }
 
 var splittableRangeVectorOp = map[string]struct{}{
@@ -175,6 +176,34 @@ func (m RangeMapper) Map(expr syntax.SampleExpr, vectorAggrPushdown *syntax.Vect
 		}
 		e.Left = lhsMapped
 		return e, nil
+	case *syntax.HistogramQuantileExpr:
+		// The quantile is calculated per group of buckets, so vector aggregations
+		// cannot be pushed down through it.
+		lhsMapped, err := m.Map(e.Left, nil, recorder)
+		if err != nil {
+			return nil, err
+		}
+		if e.Left.String() == lhsMapped.String() {
+			return e, nil
+		}
+		e.Left = lhsMapped
+		return e, nil
+	case *syntax.SubqueryExpr:
+		// The inner expression is evaluated as a range query at the subquery
+		// resolution, so vector aggregations cannot be pushed down through the
+		// subquery aggregation.
+		lhsMapped, err := m.Map(e.Left, nil, recorder)
+		if err != nil {
+			return nil, err
+		}
+		// if the inner expression is a noop, we need to return the original
+		// expression so the whole expression is a noop and thus not executed
+		// using the downstream engine.
+		if e.Left.String() == lhsMapped.String() {
+			return e, nil
+		}
+		e.Left = lhsMapped
+		return e, nil
 	case *syntax.LiteralExpr:
 		return e, nil
 	case *syntax.VectorExpr:
@@ -201,7 +230,7 @@ func getRangeInterval(expr syntax.SampleExpr) time.Duration {
 }
 
 // hasLabelExtractionStage returns true if an expression contains a stage for label extraction,
-// such as `| json` or `| logfmt`, that would result in an exploding amount of series in downstream queries.
+// such as `| json`, `| logfmt` or `| xml`, that would result in an exploding amount of series in downstream queries.
 func hasLabelExtractionStage(expr syntax.SampleExpr) bool {
 	found := false
 	expr.Walk(func(e syntax.Expr) {
@@ -211,7 +240,7 @@ func hasLabelExtractionStage(expr syntax.SampleExpr) bool {
 		case *syntax.LabelParserExpr:
 			// It will **not** return true for `regexp`, `unpack` and `pattern`, since these label extraction
 			// stages can control how many labels, and therefore the resulting amount of series, are extracted.
-			if concrete.Op == syntax.OpParserTypeJSON {
+			if concrete.Op == syntax.OpParserTypeJSON || concrete.Op == syntax.OpParserTypeXML {
 				found = true
 			}
 		}
@@ -453,10 +482,11 @@ func (m RangeMapper) mapVectorAggregationExpr(expr *syntax.VectorAggregationExpr
 
 	// In order to minimize the amount of streams on the downstream query,
 	// we can push down the outer vector aggregation to the downstream query.
-	// This does not work for `count()` and `topk()`, though.
+	// This does not work for `count()`, `count_values()` and `topk()`, though.
 	// We also do not want to push down, if the inner expression is a binary operation.
 	var vectorAggrPushdown *syntax.VectorAggregationExpr
-	if _, ok := expr.Left.(*syntax.BinOpExpr); !ok && expr.Operation != syntax.OpTypeCount && expr.Operation != syntax.OpTypeTopK && expr.Operation != syntax.OpTypeSort && expr.Operation != syntax.OpTypeSortDesc {
+	if _, ok := expr.Left.(*syntax.BinOpExpr); !ok && expr.Operation != syntax.OpTypeCount && expr.Operation != syntax.OpTypeCountValues &&
+		expr.Operation != syntax.OpTypeTopK && expr.Operation != syntax.OpTypeSort && expr.Operation != syntax.OpTypeSortDesc {
 		vectorAggrPushdown = expr
 	}
 
@@ -467,10 +497,11 @@ func (m RangeMapper) mapVectorAggregationExpr(expr *syntax.VectorAggregationExpr
 	}
 
 	return &syntax.VectorAggregationExpr{
-		Left:      lhsMapped,
-		Grouping:  expr.Grouping,
-		Params:    expr.Params,
-		Operation: expr.Operation,
+		Left:       lhsMapped,
+		Grouping:   expr.Grouping,
+		Params:     expr.Params,
+		ValueLabel: expr.ValueLabel,
+		Operation:  expr.Operation,
 	}, nil
 }
 
@@ -570,6 +601,10 @@ func isSplittableByRange(expr syntax.SampleExpr) bool {
 		return isSplittableByRange(e.SampleExpr) || literalLHS && isSplittableByRange(e.RHS) || literalRHS
 	case *syntax.LabelReplaceExpr:
 		return isSplittableByRange(e.Left)
+	case *syntax.SubqueryExpr:
+		return isSplittableByRange(e.Left)
+	case *syntax.HistogramQuantileExpr:
+		return isSplittableByRange(e.Left)
 	case *syntax.VectorExpr:
 		return false
 	default:
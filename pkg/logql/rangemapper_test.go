@@ -1809,6 +1809,34 @@ func Test_SplitRangeVectorMapping(t *testing.T) {
 			)`,
 			3,
 		},
+
+		// Subqueries
+		{
+			`max_over_time(count_over_time({app="foo"}[3m])[1h:1m])`,
+			`max_over_time(
+				sum without () (
+					downstream<count_over_time({app="foo"}[1m] offset 2m0s), shard=<nil>>
+					++ downstream<count_over_time({app="foo"}[1m] offset 1m0s), shard=<nil>>
+					++ downstream<count_over_time({app="foo"}[1m]), shard=<nil>>
+				)[1h:1m]
+			)`,
+			3,
+		},
+		{
+			`sum by (app) (max_over_time(sum by (app) (bytes_over_time({app="foo"}[3m]))[1h:] offset 5m))`,
+			`sum by (app) (
+				max_over_time(
+					sum by (app) (
+						sum without () (
+							downstream<sum by (app) (bytes_over_time({app="foo"}[1m] offset 2m0s)), shard=<nil>>
+							++ downstream<sum by (app) (bytes_over_time({app="foo"}[1m] offset 1m0s)), shard=<nil>>
+							++ downstream<sum by (app) (bytes_over_time({app="foo"}[1m])), shard=<nil>>
+						)
+					)[1h:] offset 5m0s
+				)
+			)`,
+			3,
+		},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			t.Parallel()
@@ -1847,6 +1875,10 @@ func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
 			`bytes_over_time({app="foo"}[1m])`,
 			`bytes_over_time({app="foo"}[1m])`,
 		},
+		{
+			`max_over_time(bytes_over_time({app="foo"}[1m])[1h:1m])`,
+			`max_over_time(bytes_over_time({app="foo"}[1m])[1h:1m])`,
+		},
 
 		// should be noop if inner range aggregation includes a stage for label extraction such as `| json` or `| logfmt`
 		// because otherwise the downstream queries would result in too many series
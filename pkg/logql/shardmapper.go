@@ -2,10 +2,13 @@ package logql
 
 import (
 	"fmt"
+	"math"
+	"slices"
 
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/index"
@@ -16,6 +19,8 @@ const (
 	ShardLastOverTime     = "last_over_time"
 	ShardFirstOverTime    = "first_over_time"
 	ShardQuantileOverTime = "quantile_over_time"
+	ShardDistinctOverTime = "distinct_over_time"
+	ShardSample           = "sample"
 )
 
 type ShardMapper struct {
@@ -24,12 +29,16 @@ type ShardMapper struct {
 	quantileOverTimeSharding bool
 	lastOverTimeSharding     bool
 	firstOverTimeSharding    bool
+	distinctOverTimeSharding bool
+	sampleSharding           bool
 }
 
 func NewShardMapper(strategy ShardingStrategy, metrics *MapperMetrics, shardAggregation []string) ShardMapper {
 	quantileOverTimeSharding := false
 	lastOverTimeSharding := false
 	firstOverTimeSharding := false
+	distinctOverTimeSharding := false
+	sampleSharding := false
 	for _, a := range shardAggregation {
 		switch a {
 		case ShardQuantileOverTime:
@@ -38,6 +47,10 @@ func NewShardMapper(strategy ShardingStrategy, metrics *MapperMetrics, shardAggr
 			lastOverTimeSharding = true
 		case ShardFirstOverTime:
 			firstOverTimeSharding = true
+		case ShardDistinctOverTime:
+			distinctOverTimeSharding = true
+		case ShardSample:
+			sampleSharding = true
 		}
 	}
 	return ShardMapper{
@@ -46,6 +59,8 @@ func NewShardMapper(strategy ShardingStrategy, metrics *MapperMetrics, shardAggr
 		quantileOverTimeSharding: quantileOverTimeSharding,
 		firstOverTimeSharding:    firstOverTimeSharding,
 		lastOverTimeSharding:     lastOverTimeSharding,
+		distinctOverTimeSharding: distinctOverTimeSharding,
+		sampleSharding:           sampleSharding,
 	}
 }
 
@@ -88,10 +103,18 @@ func (m ShardMapper) Map(expr syntax.Expr, r *downstreamRecorder, topLevel bool)
 		return e, 0, nil
 	case *syntax.MatchersExpr, *syntax.PipelineExpr:
 		return m.mapLogSelectorExpr(e.(syntax.LogSelectorExpr), r)
+	case *syntax.JoinExpr:
+		// joins match entries across all streams of both sides, they are
+		// evaluated unsharded on a single querier.
+		return noOp(e, m.shards.Resolver())
 	case *syntax.VectorAggregationExpr:
 		return m.mapVectorAggregationExpr(e, r, topLevel)
 	case *syntax.LabelReplaceExpr:
 		return m.mapLabelReplaceExpr(e, r, topLevel)
+	case *syntax.SubqueryExpr:
+		return m.mapSubqueryExpr(e, r)
+	case *syntax.HistogramQuantileExpr:
+		return m.mapHistogramQuantileExpr(e, r, topLevel)
 	case *syntax.RangeAggregationExpr:
 		return m.mapRangeAggregationExpr(e, r, topLevel)
 	case *syntax.BinOpExpr:
@@ -153,9 +176,93 @@ func (m ShardMapper) mapBinOpExpr(e *syntax.BinOpExpr, r *downstreamRecorder, to
 	return e, bytesPerShard, nil
 }
 
+// sampledShards returns the shards of expr. If sampled shards are enabled and
+// all the log selectors of expr are sampled, only a subset of the shards is
+// returned, so that their chunks are never fetched, and the returned copy of
+// expr samples the lines of the remaining shards with a higher ratio. The
+// expected number of sampled lines is unchanged, however the lines are then
+// taken from the streams of fewer shards, so this is an approximation.
+func sampledShards[E syntax.Expr](m ShardMapper, expr E) ([]ShardWithChunkRefs, E, uint64, error) {
+	shards, maxBytesPerShard, err := m.shards.Shards(expr)
+	if err != nil {
+		return nil, expr, 0, err
+	}
+	if !m.sampleSharding || len(shards) < 2 {
+		return shards, expr, maxBytesPerShard, nil
+	}
+
+	ratio, ok := samplingRatio(expr)
+	if !ok {
+		return shards, expr, maxBytesPerShard, nil
+	}
+
+	// Keeping √ratio of the shards and sampling their lines with a ratio of
+	// about √ratio evenly splits the reduction between fewer shards and fewer
+	// lines per shard.
+	kept := int(math.Ceil(math.Sqrt(ratio) * float64(len(shards))))
+	if kept >= len(shards) {
+		return shards, expr, maxBytesPerShard, nil
+	}
+
+	scaled, err := syntax.Clone(expr)
+	if err != nil {
+		return nil, expr, 0, err
+	}
+	scale := float64(len(shards)) / float64(kept)
+	scaled.Walk(func(e syntax.Expr) {
+		if p, ok := e.(*syntax.PipelineExpr); ok {
+			scaleSampling(p, scale)
+		}
+	})
+	return shards[:kept], scaled, maxBytesPerShard, nil
+}
+
+// samplingRatio returns the highest ratio of the lines sampled by the log
+// selectors of expr. It returns false if some log selectors of expr are not
+// sampled.
+func samplingRatio(expr syntax.Expr) (float64, bool) {
+	var (
+		ratio     float64
+		selectors int
+		sampled   int
+	)
+	expr.Walk(func(e syntax.Expr) {
+		switch e := e.(type) {
+		case *syntax.MatchersExpr:
+			selectors++
+		case *syntax.PipelineExpr:
+			if s := lowestSampling(e); s != nil {
+				sampled++
+				ratio = max(ratio, s.Ratio)
+			}
+		}
+	})
+	return ratio, selectors > 0 && sampled == selectors
+}
+
+// lowestSampling returns the `| sample` stage of the pipeline with the lowest
+// ratio, or nil if the pipeline isn't sampled.
+func lowestSampling(p *syntax.PipelineExpr) *syntax.SamplingExpr {
+	var lowest *syntax.SamplingExpr
+	for _, stage := range p.MultiStages {
+		if s, ok := stage.(*syntax.SamplingExpr); ok && (lowest == nil || s.Ratio < lowest.Ratio) {
+			lowest = s
+		}
+	}
+	return lowest
+}
+
+// scaleSampling multiplies the ratio of the lowest `| sample` stage of the
+// pipeline by scale.
+func scaleSampling(p *syntax.PipelineExpr, scale float64) {
+	if s := lowestSampling(p); s != nil {
+		s.Ratio = min(1, s.Ratio*scale)
+	}
+}
+
 func (m ShardMapper) mapLogSelectorExpr(expr syntax.LogSelectorExpr, r *downstreamRecorder) (syntax.LogSelectorExpr, uint64, error) {
 	var head *ConcatLogSelectorExpr
-	shards, maxBytesPerShard, err := m.shards.Shards(expr)
+	shards, expr, maxBytesPerShard, err := sampledShards(m, expr)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -184,7 +291,7 @@ func (m ShardMapper) mapLogSelectorExpr(expr syntax.LogSelectorExpr, r *downstre
 
 func (m ShardMapper) mapSampleExpr(expr syntax.SampleExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
 	var head *ConcatSampleExpr
-	shards, maxBytesPerShard, err := m.shards.Shards(expr)
+	shards, expr, maxBytesPerShard, err := sampledShards(m, expr)
 
 	if err != nil {
 		return nil, 0, err
@@ -286,6 +393,24 @@ func (m ShardMapper) mapVectorAggregationExpr(expr *syntax.VectorAggregationExpr
 				Grouping:  expr.Grouping,
 				Operation: syntax.OpTypeSum,
 			}, bytesPerShard, nil
+		case syntax.OpTypeCountValues:
+			if syntax.ReducesLabels(expr.Left) {
+				// skip sharding optimizations at this level. If labels are reduced,
+				// the same series may exist on multiple shards and must be aggregated
+				// together before their values are counted
+				break
+			}
+
+			// count_values("v", x) -> sum by (v) (count_values("v", x, shard=1) ++ count_values("v", x, shard=2)...)
+			sharded, bytesPerShard, err := m.mapSampleExpr(expr, r)
+			if err != nil {
+				return nil, 0, err
+			}
+			return &syntax.VectorAggregationExpr{
+				Left:      sharded,
+				Grouping:  expr.CountValuesGrouping(),
+				Operation: syntax.OpTypeSum,
+			}, bytesPerShard, nil
 		default:
 			// this should not be reachable. If an operation is shardable it should
 			// have an optimization listed. Nonetheless, we log this as a warning
@@ -315,10 +440,11 @@ func (m ShardMapper) mapVectorAggregationExpr(expr *syntax.VectorAggregationExpr
 	}
 
 	return &syntax.VectorAggregationExpr{
-		Left:      sampleExpr,
-		Grouping:  expr.Grouping,
-		Params:    expr.Params,
-		Operation: expr.Operation,
+		Left:       sampleExpr,
+		Grouping:   expr.Grouping,
+		Params:     expr.Params,
+		ValueLabel: expr.ValueLabel,
+		Operation:  expr.Operation,
 	}, bytesPerShard, nil
 
 }
@@ -333,6 +459,34 @@ func (m ShardMapper) mapLabelReplaceExpr(expr *syntax.LabelReplaceExpr, r *downs
 	return &cpy, bytesPerShard, nil
 }
 
+// mapSubqueryExpr shards the inner expression of a subquery. The subquery
+// aggregation itself is evaluated on the frontend, since it needs all samples
+// of a series across shards.
+func (m ShardMapper) mapSubqueryExpr(expr *syntax.SubqueryExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
+	// The inner expression is never top level: its results are aggregated over time
+	// by the subquery, which prevents e.g. probabilistic quantiles from being used.
+	subMapped, bytesPerShard, err := m.Map(expr.Left, r, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = subMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
+// mapHistogramQuantileExpr shards the inner expression of histogram_quantile.
+// The quantile itself is calculated on the frontend once all buckets of a
+// histogram have been merged.
+func (m ShardMapper) mapHistogramQuantileExpr(expr *syntax.HistogramQuantileExpr, r *downstreamRecorder, topLevel bool) (syntax.SampleExpr, uint64, error) {
+	subMapped, bytesPerShard, err := m.Map(expr.Left, r, topLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = subMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
 // These functions require a different merge strategy than the default
 // concatenation.
 // This is because the same label sets may exist on multiple shards when label-reducing parsing is applied or when
@@ -345,6 +499,7 @@ var rangeMergeMap = map[string]string{
 	syntax.OpRangeTypeBytes:     syntax.OpTypeSum,
 	syntax.OpRangeTypeBytesRate: syntax.OpTypeSum,
 	syntax.OpRangeTypeSum:       syntax.OpTypeSum,
+	syntax.OpRangeTypeHistogram: syntax.OpTypeSum,
 
 	// min & max require taking the min|max of the shards
 	syntax.OpRangeTypeMin: syntax.OpTypeMin,
@@ -358,7 +513,8 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 
 	switch expr.Operation {
 
-	case syntax.OpRangeTypeCount, syntax.OpRangeTypeRate, syntax.OpRangeTypeBytes, syntax.OpRangeTypeBytesRate, syntax.OpRangeTypeSum, syntax.OpRangeTypeMax, syntax.OpRangeTypeMin:
+	case syntax.OpRangeTypeCount, syntax.OpRangeTypeRate, syntax.OpRangeTypeBytes, syntax.OpRangeTypeBytesRate, syntax.OpRangeTypeSum, syntax.OpRangeTypeMax, syntax.OpRangeTypeMin,
+		syntax.OpRangeTypeHistogram:
 		// if the expr can reduce labels, it can cause the same labelset to
 		// exist on separate shards and we'll need to merge the results
 		// accordingly. If it does not reduce labels and has no special grouping
@@ -375,6 +531,10 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 		if grouping == nil {
 			grouping = &syntax.Grouping{Without: true}
 		}
+		// histogram buckets of a series must not be merged with each other.
+		if expr.Operation == syntax.OpRangeTypeHistogram && !grouping.Without {
+			grouping = &syntax.Grouping{Groups: append(slices.Clone(grouping.Groups), model.BucketLabel)}
+		}
 
 		mapped, bytes, err := m.mapSampleExpr(expr, r)
 		// max_over_time(_) -> max without() (max_over_time(_) ++ max_over_time(_)...)
@@ -452,7 +612,9 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 		}, bytesPerShard, nil
 
 	case syntax.OpRangeTypeQuantile:
-		if !m.quantileOverTimeSharding {
+		// quantile sketches of ranges pinned with an `@` modifier would be
+		// shared by all the steps of the query, so they are not used.
+		if !m.quantileOverTimeSharding || expr.Left.At != nil {
 			return noOp(expr, m.shards.Resolver())
 		}
 
@@ -498,6 +660,52 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 			quantile: expr.Params,
 		}, bytesPerShard, nil
 
+	case syntax.OpRangeTypeChanges:
+		// the changes of a series can only be counted when all of its samples
+		// are seen together, so only series that stay on a single shard are sharded.
+		potentialConflict := syntax.ReducesLabels(expr)
+		if !potentialConflict && (expr.Grouping == nil || expr.Grouping.Noop()) {
+			return m.mapSampleExpr(expr, r)
+		}
+		return noOp(expr, m.shards.Resolver())
+
+	case syntax.OpRangeTypeDistinct:
+		potentialConflict := syntax.ReducesLabels(expr)
+		if !potentialConflict && (expr.Grouping == nil || expr.Grouping.Noop()) {
+			return m.mapSampleExpr(expr, r)
+		}
+
+		// Merging the distinct values of the shards is approximated with
+		// HyperLogLog sketches, so it has to be enabled explicitly. Sketches of
+		// ranges pinned with an `@` modifier would be shared by all the steps of
+		// the query, so they are not used.
+		if !m.distinctOverTimeSharding || expr.Left.At != nil {
+			return noOp(expr, m.shards.Resolver())
+		}
+
+		shards, expr, bytesPerShard, err := sampledShards(m, expr)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(shards) == 0 {
+			return noOp(expr, m.shards.Resolver())
+		}
+
+		// distinct_over_time() by (foo) ->
+		// distinct_sketch_eval(__distinct_sketch_over_time__() by (foo) ++ ...)
+		downstreams := make([]DownstreamSampleExpr, 0, len(shards))
+		expr.Operation = syntax.OpRangeTypeDistinctSketch
+		for i := len(shards) - 1; i >= 0; i-- {
+			downstreams = append(downstreams, DownstreamSampleExpr{
+				shard:      &shards[i],
+				SampleExpr: expr,
+			})
+		}
+
+		return &DistinctSketchEvalExpr{
+			downstreams: downstreams,
+		}, bytesPerShard, nil
+
 	case syntax.OpRangeTypeFirst:
 		if !m.firstOverTimeSharding {
 			return noOp(expr, m.shards.Resolver())
@@ -508,7 +716,7 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 			return m.mapSampleExpr(expr, r)
 		}
 
-		shards, bytesPerShard, err := m.shards.Shards(expr)
+		shards, expr, bytesPerShard, err := sampledShards(m, expr)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -539,7 +747,7 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 			return m.mapSampleExpr(expr, r)
 		}
 
-		shards, bytesPerShard, err := m.shards.Shards(expr)
+		shards, expr, bytesPerShard, err := sampledShards(m, expr)
 		if err != nil {
 			return nil, 0, err
 		}
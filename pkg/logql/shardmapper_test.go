@@ -465,6 +465,75 @@ func TestMappingStrings(t *testing.T) {
 			in:  `count by (foo) (sum by (foo, bar) (rate({job="bar"}[1m])))`,
 			out: `countby(foo)(sumby(foo,bar)(downstream<sumby(foo,bar)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo,bar)(rate({job="bar"}[1m])),shard=1_of_2>))`,
 		},
+		{
+			// the inner expression of a subquery is sharded, the subquery is evaluated on the frontend
+			in:  `max_over_time(sum by (foo) (rate({job="bar"}[1m]))[1h:1m])`,
+			out: `max_over_time(sumby(foo)(downstream<sumby(foo)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo)(rate({job="bar"}[1m])),shard=1_of_2>)[1h:1m])`,
+		},
+		{
+			// the inner expression of a subquery is never top level and can therefore not use probabilistic quantiles
+			in:  `max_over_time(quantile_over_time(0.99, {job="bar"} | unwrap latency [1m])[1h:])`,
+			out: `max_over_time(quantile_over_time(0.99,{job="bar"}|unwraplatency[1m])[1h:])`,
+		},
+		{
+			// histogram buckets of the same series on different shards are summed
+			in:  `histogram_over_time({job="bar"} | unwrap latency [1m]) by (foo)`,
+			out: `sumby(foo,le)(downstream<histogram_over_time({job="bar"}|unwraplatency[1m])by(foo),shard=0_of_2>++downstream<histogram_over_time({job="bar"}|unwraplatency[1m])by(foo),shard=1_of_2>)`,
+		},
+		{
+			// the quantile is calculated on the frontend once all buckets are merged
+			in:  `histogram_quantile(0.99, sum by (le) (histogram_over_time({job="bar"} | unwrap latency [1m])))`,
+			out: `histogram_quantile(0.99,sumby(le)(downstream<sumby(le)(histogram_over_time({job="bar"}|unwraplatency[1m])),shard=0_of_2>++downstream<sumby(le)(histogram_over_time({job="bar"}|unwraplatency[1m])),shard=1_of_2>))`,
+		},
+		{
+			in:  `sum(avg_over_time(rate({job="bar"}[1m])[10m:1m] offset 5m))`,
+			out: `sum(avg_over_time(downstream<rate({job="bar"}[1m]),shard=0_of_2>++downstream<rate({job="bar"}[1m]),shard=1_of_2>[10m:1m] offset 5m0s))`,
+		},
+		{
+			// joins are evaluated unsharded
+			in:  `{job="foo"} | logfmt | join on(trace_id) within 30s {job="bar"} |= "error"`,
+			out: `{job="foo"}|logfmt|joinon(trace_id)within30s{job="bar"}|="error"`,
+		},
+		{
+			in:  `changes_over_time({job="bar"} | unwrap latency [1m])`,
+			out: `downstream<changes_over_time({job="bar"}|unwraplatency[1m]),shard=0_of_2>++downstream<changes_over_time({job="bar"}|unwraplatency[1m]),shard=1_of_2>`,
+		},
+		{
+			// the changes of a series can't be merged across shards
+			in:  `changes_over_time({job="bar"} | unwrap latency [1m]) by (foo)`,
+			out: `changes_over_time({job="bar"}|unwraplatency[1m])by(foo)`,
+		},
+		{
+			in:  `distinct_over_time({job="bar"} | unwrap latency [1m])`,
+			out: `downstream<distinct_over_time({job="bar"}|unwraplatency[1m]),shard=0_of_2>++downstream<distinct_over_time({job="bar"}|unwraplatency[1m]),shard=1_of_2>`,
+		},
+		{
+			// series merged by the grouping may be spread over several shards
+			in:  `sum(distinct_over_time({job="bar"} | unwrap latency [1m]) by (foo))`,
+			out: `sum(distinct_over_time({job="bar"}|unwraplatency[1m])by(foo))`,
+		},
+		{
+			in:  `sum by (foo) (distinct_over_time({job="bar"} | unwrap latency [1m]))`,
+			out: `sumby(foo)(downstream<sumby(foo)(distinct_over_time({job="bar"}|unwraplatency[1m])),shard=0_of_2>++downstream<sumby(foo)(distinct_over_time({job="bar"}|unwraplatency[1m])),shard=1_of_2>)`,
+		},
+		{
+			// approximate distinct_over_time sharding is not enabled
+			in:  `distinct_over_time({job="bar"} | unwrap latency [1m]) by (foo)`,
+			out: `distinct_over_time({job="bar"}|unwraplatency[1m])by(foo)`,
+		},
+		{
+			in:  `count_values("value", rate({job="bar"}[1m]))`,
+			out: `sumby(value)(downstream<count_values("value",rate({job="bar"}[1m])),shard=0_of_2>++downstream<count_values("value",rate({job="bar"}[1m])),shard=1_of_2>)`,
+		},
+		{
+			in:  `count_values without (foo, value) ("value", rate({job="bar"}[1m]))`,
+			out: `sumwithout(foo)(downstream<count_values without (foo,value)("value",rate({job="bar"}[1m])),shard=0_of_2>++downstream<count_values without (foo,value)("value",rate({job="bar"}[1m])),shard=1_of_2>)`,
+		},
+		{
+			// labels are reduced, so the same series may exist on multiple shards
+			in:  `count_values by (foo) ("value", sum by (foo) (rate({job="bar"}[1m])))`,
+			out: `count_values by (foo)("value",sumby(foo)(downstream<sumby(foo)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo)(rate({job="bar"}[1m])),shard=1_of_2>))`,
+		},
 	} {
 		t.Run(tc.in, func(t *testing.T) {
 			ast, err := syntax.ParseExpr(tc.in)
@@ -524,6 +593,113 @@ func TestMappingStrings_NoProbabilisticSharding(t *testing.T) {
 	}
 }
 
+func TestMappingStrings_DistinctOverTime(t *testing.T) {
+	m := NewShardMapper(NewPowerOfTwoStrategy(ConstantShards(2)), nilShardMetrics, []string{ShardDistinctOverTime})
+	for _, tc := range []struct {
+		in  string
+		out string
+	}{
+		{
+			in:  `distinct_over_time({job="bar"} | unwrap latency [1m])`,
+			out: `downstream<distinct_over_time({job="bar"}|unwraplatency[1m]),shard=0_of_2>++downstream<distinct_over_time({job="bar"}|unwraplatency[1m]),shard=1_of_2>`,
+		},
+		{
+			in:  `distinct_over_time({job="bar"} | unwrap latency [1m]) by (foo)`,
+			out: `distinctSketchEval<downstream<__distinct_sketch_over_time__({job="bar"}|unwraplatency[1m])by(foo),shard=1_of_2>++downstream<__distinct_sketch_over_time__({job="bar"}|unwraplatency[1m])by(foo),shard=0_of_2>>`,
+		},
+		{
+			// sketches of a pinned range would be shared by all steps
+			in:  `distinct_over_time({job="bar"} | unwrap latency [1m] @ 100) by (foo)`,
+			out: `distinct_over_time({job="bar"}|unwraplatency[1m]@100.000)by(foo)`,
+		},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			ast, err := syntax.ParseExpr(tc.in)
+			require.Nil(t, err)
+
+			mapped, _, err := m.Map(ast, nilShardMetrics.downstreamRecorder(), true)
+			require.Nil(t, err)
+
+			require.Equal(t, removeWhiteSpace(tc.out), removeWhiteSpace(mapped.String()))
+		})
+	}
+}
+
+func TestMappingStrings_Sampling(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		out      string
+		sampling string
+	}{
+		{
+			in:  `{foo="bar"} | sample 0.01`,
+			out: `downstream<{foo="bar"}|sample0.01,shard=0_of_4>++downstream<{foo="bar"}|sample0.01,shard=1_of_4>++downstream<{foo="bar"}|sample0.01,shard=2_of_4>++downstream<{foo="bar"}|sample0.01,shard=3_of_4>`,
+		},
+		{
+			in:       `{foo="bar"} | sample 0.01`,
+			out:      `downstream<{foo="bar"}|sample0.04,shard=0_of_4>`,
+			sampling: ShardSample,
+		},
+		{
+			in:       `{foo="bar"} | sample 0.01 | sample 0.5`,
+			out:      `downstream<{foo="bar"}|sample0.04|sample0.5,shard=0_of_4>`,
+			sampling: ShardSample,
+		},
+		{
+			in:       `sum(count_over_time({foo="bar"} | sample 0.2 [1m]))`,
+			out:      `sum(downstream<sum(count_over_time({foo="bar"}|sample0.4[1m])),shard=0_of_4>++downstream<sum(count_over_time({foo="bar"}|sample0.4[1m])),shard=1_of_4>)`,
+			sampling: ShardSample,
+		},
+		{
+			in:       `avg(count_over_time({foo="bar"} | sample 0.2 [1m]))`,
+			out:      `(sum(downstream<sum(count_over_time({foo="bar"}|sample0.4[1m])),shard=0_of_4>++downstream<sum(count_over_time({foo="bar"}|sample0.4[1m])),shard=1_of_4>)/sum(downstream<count(count_over_time({foo="bar"}|sample0.4[1m])),shard=0_of_4>++downstream<count(count_over_time({foo="bar"}|sample0.4[1m])),shard=1_of_4>))`,
+			sampling: ShardSample,
+		},
+		{
+			in:       `sum(count_over_time({foo="bar"} | sample 0.2 [1m])) / sum(count_over_time({foo="baz"}[1m]))`,
+			out:      `(sum(downstream<sum(count_over_time({foo="bar"}|sample0.4[1m])),shard=0_of_4>++downstream<sum(count_over_time({foo="bar"}|sample0.4[1m])),shard=1_of_4>)/sum(downstream<sum(count_over_time({foo="baz"}[1m])),shard=0_of_4>++downstream<sum(count_over_time({foo="baz"}[1m])),shard=1_of_4>++downstream<sum(count_over_time({foo="baz"}[1m])),shard=2_of_4>++downstream<sum(count_over_time({foo="baz"}[1m])),shard=3_of_4>))`,
+			sampling: ShardSample,
+		},
+		{
+			in:       `{foo="bar"} | sample 1`,
+			out:      `downstream<{foo="bar"}|sample1,shard=0_of_4>++downstream<{foo="bar"}|sample1,shard=1_of_4>++downstream<{foo="bar"}|sample1,shard=2_of_4>++downstream<{foo="bar"}|sample1,shard=3_of_4>`,
+			sampling: ShardSample,
+		},
+	} {
+		t.Run(tc.sampling+tc.in, func(t *testing.T) {
+			m := NewShardMapper(NewPowerOfTwoStrategy(ConstantShards(4)), nilShardMetrics, []string{tc.sampling})
+			ast, err := syntax.ParseExpr(tc.in)
+			require.Nil(t, err)
+
+			mapped, _, err := m.Map(ast, nilShardMetrics.downstreamRecorder(), true)
+			require.Nil(t, err)
+
+			require.Equal(t, removeWhiteSpace(tc.out), removeWhiteSpace(mapped.String()))
+		})
+	}
+}
+
+func TestSamplingRatio(t *testing.T) {
+	for query, expected := range map[string]bool{
+		`{foo="bar"}`:                          false,
+		`{foo="bar"} |= "error"`:               false,
+		`{foo="bar"} | sample 0.01`:            true,
+		`{foo="bar"} | sample 0.01 | sample 1`: true,
+		`sum(count_over_time({foo="bar"} | sample 0.01 [1m])) / sum(count_over_time({foo="bar"}[1m]))`:                false,
+		`sum(count_over_time({foo="bar"} | sample 0.01 [1m])) / sum(count_over_time({foo="bar"} | sample 0.01 [1m]))`: true,
+	} {
+		t.Run(query, func(t *testing.T) {
+			expr, err := syntax.ParseExpr(query)
+			require.NoError(t, err)
+			ratio, ok := samplingRatio(expr)
+			require.Equal(t, expected, ok)
+			if ok {
+				require.Equal(t, 0.01, ratio)
+			}
+		})
+	}
+}
+
 func TestMapping(t *testing.T) {
 	strategy := NewPowerOfTwoStrategy(ConstantShards(2))
 	m := NewShardMapper(strategy, nilShardMetrics, []string{})
@@ -0,0 +1,100 @@
+package sketch
+
+import (
+	"math"
+)
+
+// Histogram counts values into exponential buckets whose upper bounds are
+// powers of two. This is the bucket layout of Prometheus native histograms
+// with schema 0, which makes the buckets stable across series and time and
+// lets histograms be merged by simply adding up bucket counts.
+//
+// Values less than or equal to zero are counted in a single bucket with an
+// upper bound of zero. Values greater than HistogramMaxUpperBound, NaN and
+// +Inf only contribute to the +Inf bucket.
+type Histogram struct {
+	// buckets maps the exponent k of the upper bound 2^k to the number of values
+	// in (2^(k-1), 2^k].
+	buckets map[int]float64
+	zero    float64
+	count   float64
+}
+
+// histogramMaxExponent is the exponent of the highest finite upper bound.
+const histogramMaxExponent = 30
+
+// HistogramMaxUpperBound is the highest finite upper bound of a Histogram.
+const HistogramMaxUpperBound = 1 << histogramMaxExponent
+
+// HistogramBucket is a cumulative bucket of a Histogram: Count is the number
+// of observed values less than or equal to UpperBound.
+type HistogramBucket struct {
+	UpperBound float64
+	Count      float64
+}
+
+func NewHistogram() *Histogram {
+	return &Histogram{buckets: map[int]float64{}}
+}
+
+// Add observes a single value.
+func (h *Histogram) Add(v float64) {
+	h.count++
+	switch {
+	case math.IsNaN(v), v > HistogramMaxUpperBound:
+	case v <= 0:
+		h.zero++
+	default:
+		h.buckets[bucketExponent(v)]++
+	}
+}
+
+// Merge adds the bucket counts of other into h.
+func (h *Histogram) Merge(other *Histogram) *Histogram {
+	for k, c := range other.buckets {
+		h.buckets[k] += c
+	}
+	h.zero += other.zero
+	h.count += other.count
+	return h
+}
+
+// Count returns the total number of observed values.
+func (h *Histogram) Count() float64 {
+	return h.count
+}
+
+// Buckets returns the cumulative buckets of the histogram ordered by upper bound.
+// Buckets are returned from the lowest populated one up to HistogramMaxUpperBound,
+// followed by the +Inf bucket holding the total count. Omitting empty buckets at
+// the low end is safe because their cumulative count is zero, while always
+// returning the high end keeps the buckets of different histograms summable.
+func (h *Histogram) Buckets() []HistogramBucket {
+	res := make([]HistogramBucket, 0, histogramMaxExponent+2)
+	cumulative := h.zero
+	if h.zero > 0 {
+		res = append(res, HistogramBucket{UpperBound: 0, Count: cumulative})
+	}
+	if len(h.buckets) > 0 {
+		lowest := histogramMaxExponent
+		for k := range h.buckets {
+			lowest = min(lowest, k)
+		}
+		for k := lowest; k <= histogramMaxExponent; k++ {
+			cumulative += h.buckets[k]
+			res = append(res, HistogramBucket{UpperBound: math.Ldexp(1, k), Count: cumulative})
+		}
+	}
+	return append(res, HistogramBucket{UpperBound: math.Inf(1), Count: h.count})
+}
+
+// bucketExponent returns the smallest k for which v <= 2^k.
+func bucketExponent(v float64) int {
+	frac, exp := math.Frexp(v)
+	// v = frac * 2^exp with frac in [0.5, 1), so exact powers of two
+	// belong to the bucket below.
+	if frac == 0.5 {
+		return exp - 1
+	}
+	return exp
+}
@@ -0,0 +1,86 @@
+package sketch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		values []float64
+		// exp are the expected lowest buckets, all remaining finite buckets
+		// must hold the count of the last one.
+		exp []HistogramBucket
+	}{
+		{
+			name:   "powers of two are upper bounds",
+			values: []float64{1, 2, 4},
+			exp: []HistogramBucket{
+				{UpperBound: 1, Count: 1},
+				{UpperBound: 2, Count: 2},
+				{UpperBound: 4, Count: 3},
+			},
+		},
+		{
+			name:   "gaps are filled",
+			values: []float64{0.3, 5, 20, 20},
+			exp: []HistogramBucket{
+				{UpperBound: 0.5, Count: 1},
+				{UpperBound: 1, Count: 1},
+				{UpperBound: 2, Count: 1},
+				{UpperBound: 4, Count: 1},
+				{UpperBound: 8, Count: 2},
+				{UpperBound: 16, Count: 2},
+				{UpperBound: 32, Count: 4},
+			},
+		},
+		{
+			name:   "zero, negative and special values",
+			values: []float64{-3, 0, 3, HistogramMaxUpperBound * 2, math.NaN(), math.Inf(1), math.Inf(-1)},
+			exp: []HistogramBucket{
+				{UpperBound: 0, Count: 3},
+				{UpperBound: 4, Count: 4},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHistogram()
+			for _, v := range tc.values {
+				h.Add(v)
+			}
+			buckets := h.Buckets()
+			require.Equal(t, tc.exp, buckets[:len(tc.exp)])
+
+			last := tc.exp[len(tc.exp)-1]
+			for _, b := range buckets[len(tc.exp) : len(buckets)-1] {
+				require.Equal(t, last.Count, b.Count)
+				require.Greater(t, b.UpperBound, last.UpperBound)
+			}
+			require.Equal(t, float64(HistogramMaxUpperBound), buckets[len(buckets)-2].UpperBound)
+			require.Equal(t, HistogramBucket{UpperBound: math.Inf(1), Count: float64(len(tc.values))}, buckets[len(buckets)-1])
+			require.Equal(t, float64(len(tc.values)), h.Count())
+		})
+	}
+}
+
+func TestHistogramBucketsEmpty(t *testing.T) {
+	require.Equal(t, []HistogramBucket{{UpperBound: math.Inf(1), Count: 0}}, NewHistogram().Buckets())
+}
+
+func TestHistogramMerge(t *testing.T) {
+	a, b, all := NewHistogram(), NewHistogram(), NewHistogram()
+	for i, v := range []float64{0.1, 1, 3, 7, 7, 100, 0, 1e6} {
+		if i%2 == 0 {
+			a.Add(v)
+		} else {
+			b.Add(v)
+		}
+		all.Add(v)
+	}
+
+	require.Equal(t, all.Buckets(), a.Merge(b).Buckets())
+	require.Equal(t, all.Count(), a.Count())
+}
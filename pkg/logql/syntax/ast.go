@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -98,6 +99,8 @@ func ExtractLabelFiltersBeforeParser(e Expr) []*LabelFilterExpr {
 		VisitLabelParserFn:            func(_ RootVisitor, _ *LabelParserExpr) { foundParseStage = true },
 		VisitJSONExpressionParserFn:   func(_ RootVisitor, _ *JSONExpressionParser) { foundParseStage = true },
 		VisitLogfmtExpressionParserFn: func(_ RootVisitor, _ *LogfmtExpressionParser) { foundParseStage = true },
+		VisitCSVParserFn:              func(_ RootVisitor, _ *CSVParserExpr) { foundParseStage = true },
+		VisitXMLExpressionParserFn:    func(_ RootVisitor, _ *XMLExpressionParser) { foundParseStage = true },
 		VisitLabelFmtFn:               func(_ RootVisitor, _ *LabelFmtExpr) { foundParseStage = true },
 		VisitKeepLabelFn:              func(_ RootVisitor, _ *KeepLabelsExpr) { foundParseStage = true },
 		VisitDropLabelsFn:             func(_ RootVisitor, _ *DropLabelsExpr) { foundParseStage = true },
@@ -389,6 +392,67 @@ func (e *PipelineExpr) HasFilter() bool {
 	return false
 }
 
+// JoinExpr is a log query returning the entries of Left that share the values
+// of the On labels with an entry of Right at most Within apart, e.g.
+// `{app="a"} | join on(trace_id) within 30s {app="b"} |= "error"`.
+// Joins are evaluated by the query engine which selects both sides separately.
+type JoinExpr struct {
+	Left   LogSelectorExpr
+	Right  LogSelectorExpr
+	On     []string
+	Within time.Duration
+	implicit
+}
+
+func newJoinExpr(left LogSelectorExpr, on []string, within time.Duration, right LogSelectorExpr) LogSelectorExpr {
+	return &JoinExpr{
+		Left:   left,
+		Right:  right,
+		On:     on,
+		Within: within,
+	}
+}
+
+func (e *JoinExpr) isLogSelectorExpr() {}
+
+// Shardable returns false: the entries of both sides would have to be
+// selected from the same shard to be joined.
+func (e *JoinExpr) Shardable(_ bool) bool { return false }
+
+func (e *JoinExpr) Walk(f WalkFn) {
+	f(e)
+	walkAll(f, e.Left, e.Right)
+}
+
+func (e *JoinExpr) Accept(v RootVisitor) { v.VisitJoin(e) }
+
+func (e *JoinExpr) Matchers() []*labels.Matcher {
+	return e.Left.Matchers()
+}
+
+func (e *JoinExpr) Pipeline() (log.Pipeline, error) {
+	return nil, fmt.Errorf("%s can only be evaluated by the query engine", OpJoin)
+}
+
+func (e *JoinExpr) HasFilter() bool {
+	return true
+}
+
+func (e *JoinExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Left.String())
+	sb.WriteString(" ")
+	sb.WriteString(e.joinClause())
+	sb.WriteString(" ")
+	sb.WriteString(e.Right.String())
+	return sb.String()
+}
+
+// joinClause returns the `| join on(<labels>) within <duration>` clause of the join.
+func (e *JoinExpr) joinClause() string {
+	return fmt.Sprintf("%s %s %s(%s) %s %s", OpPipe, OpJoin, OpOn, strings.Join(e.On, ", "), OpWithin, model.Duration(e.Within))
+}
+
 type LineFilter struct {
 	Ty    log.LineMatchType
 	Match string
@@ -569,21 +633,11 @@ func (e *LineFilterExpr) Filter() (log.Filterer, error) {
 			}
 			acc = append(acc, next)
 		} else {
-			switch curr.Op {
-			case OpFilterIP:
-				next, err := log.NewIPLineFilter(curr.Match, curr.Ty)
-				if err != nil {
-					return nil, err
-				}
-				acc = append(acc, next)
-			default:
-				next, err = log.NewFilter(curr.Match, curr.Ty)
-				if err != nil {
-					return nil, err
-				}
-
-				acc = append(acc, next)
+			next, err = newLineFilter(curr.LineFilter)
+			if err != nil {
+				return nil, err
 			}
+			acc = append(acc, next)
 		}
 	}
 
@@ -600,14 +654,27 @@ func (e *LineFilterExpr) Filter() (log.Filterer, error) {
 	return log.NewAndFilters(acc), nil
 }
 
+// newLineFilter returns the filter of a single line filter, taking its filter
+// function into account.
+func newLineFilter(f LineFilter) (log.Filterer, error) {
+	switch f.Op {
+	case OpFilterIP:
+		return log.NewIPLineFilter(f.Match, f.Ty)
+	case OpFilterWord:
+		return log.NewWordLineFilter(f.Match, f.Ty)
+	default:
+		return log.NewFilter(f.Match, f.Ty)
+	}
+}
+
 func newOrFilter(f *LineFilterExpr) (log.Filterer, error) {
-	orFilter, err := log.NewFilter(f.Match, f.Ty)
+	orFilter, err := newLineFilter(f.LineFilter)
 	if err != nil {
 		return nil, err
 	}
 
 	for or := f.Or; or != nil; or = or.Or {
-		filter, err := log.NewFilter(or.Match, or.Ty)
+		filter, err := newLineFilter(or.LineFilter)
 		if err != nil {
 			return nil, err
 		}
@@ -721,6 +788,8 @@ func (e *LabelParserExpr) Stage() (log.Stage, error) {
 		return log.NewUnpackParser(), nil
 	case OpParserTypePattern:
 		return log.NewPatternParser(e.Param)
+	case OpParserTypeXML:
+		return log.NewXMLParser(), nil
 	default:
 		return nil, fmt.Errorf("unknown parser operator: %s", e.Op)
 	}
@@ -761,9 +830,11 @@ func (e *LabelFilterExpr) Walk(f WalkFn) { f(e) }
 func (e *LabelFilterExpr) Accept(v RootVisitor) { v.VisitLabelFilter(e) }
 
 func (e *LabelFilterExpr) Stage() (log.Stage, error) {
-	switch ip := e.LabelFilterer.(type) {
+	switch f := e.LabelFilterer.(type) {
 	case *log.IPLabelFilter:
-		return ip, ip.PatternError()
+		return f, f.PatternError()
+	case *log.WordLabelFilter:
+		return f, f.PatternError()
 	case *log.NoopLabelFilter:
 		return log.NoopStage, nil
 	}
@@ -893,6 +964,120 @@ func (e *KeepLabelsExpr) Walk(f WalkFn) { f(e) }
 
 func (e *KeepLabelsExpr) Accept(v RootVisitor) { v.VisitKeepLabel(e) }
 
+// SamplingExpr keeps a deterministic ratio of the log lines, e.g. `| sample 0.01`.
+type SamplingExpr struct {
+	Ratio float64
+	implicit
+}
+
+func newSamplingExpr(ratio string) *SamplingExpr {
+	r, err := strconv.ParseFloat(ratio, 64)
+	if err != nil {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid ratio for %s: %s", OpSample, err.Error()), 0, 0))
+	}
+	if !log.ValidSampleRatio(r) {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid ratio for %s: %s, must be greater than 0 and at most 1", OpSample, ratio), 0, 0))
+	}
+	return &SamplingExpr{Ratio: r}
+}
+
+func (*SamplingExpr) isStageExpr() {}
+
+func (e *SamplingExpr) Shardable(_ bool) bool { return true }
+
+func (e *SamplingExpr) Stage() (log.Stage, error) {
+	return log.NewSampler(e.Ratio)
+}
+
+func (e *SamplingExpr) String() string {
+	return fmt.Sprintf("%s %s %s", OpPipe, OpSample, strconv.FormatFloat(e.Ratio, 'f', -1, 64))
+}
+
+func (e *SamplingExpr) Walk(f WalkFn) { f(e) }
+
+func (e *SamplingExpr) Accept(v RootVisitor) { v.VisitSampling(e) }
+
+// MacroExpr references a named pipeline fragment of the tenant, e.g.
+// `| @nginx_parse`. Macros are replaced by their pipeline stages before a
+// query is mapped or evaluated, see ExpandMacros.
+type MacroExpr struct {
+	Name string
+	implicit
+}
+
+func newMacroExpr(name string) *MacroExpr {
+	return &MacroExpr{Name: name}
+}
+
+func (*MacroExpr) isStageExpr() {}
+
+// Shardable returns false since the stages of the macro are unknown until it
+// has been expanded.
+func (e *MacroExpr) Shardable(_ bool) bool { return false }
+
+func (e *MacroExpr) Stage() (log.Stage, error) {
+	return nil, logqlmodel.NewParseError(fmt.Sprintf("macro %s%s has not been expanded", OpAt, e.Name), 0, 0)
+}
+
+func (e *MacroExpr) String() string {
+	return fmt.Sprintf("%s %s%s", OpPipe, OpAt, e.Name)
+}
+
+func (e *MacroExpr) Walk(f WalkFn) { f(e) }
+
+func (e *MacroExpr) Accept(v RootVisitor) { v.VisitMacro(e) }
+
+// AddSamplingExpr adds a `| sample` stage with the given ratio to all the log
+// selectors of an expression.
+func AddSamplingExpr(expr Expr, ratio float64) (Expr, error) {
+	if !log.ValidSampleRatio(ratio) {
+		return nil, log.ErrSampleInvalidRatio
+	}
+	switch e := expr.(type) {
+	case SampleExpr:
+		// literals and vectors are also log selectors, they have no log range
+		// to sample and are returned as is.
+		var err error
+		e.Walk(func(e Expr) {
+			r, ok := e.(*LogRange)
+			if !ok || err != nil {
+				return
+			}
+			r.Left, err = addSamplingExpr(r.Left, ratio)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	case LogSelectorExpr:
+		return addSamplingExpr(e, ratio)
+	default:
+		return nil, fmt.Errorf("unknown expression: %v+", expr)
+	}
+}
+
+func addSamplingExpr(expr LogSelectorExpr, ratio float64) (LogSelectorExpr, error) {
+	sampling := &SamplingExpr{Ratio: ratio}
+	switch e := expr.(type) {
+	case *MatchersExpr:
+		return newPipelineExpr(e, MultiStageExpr{sampling}), nil
+	case *PipelineExpr:
+		e.MultiStages = append(e.MultiStages, sampling)
+		return e, nil
+	case *JoinExpr:
+		var err error
+		if e.Left, err = addSamplingExpr(e.Left, ratio); err != nil {
+			return nil, err
+		}
+		if e.Right, err = addSamplingExpr(e.Right, ratio); err != nil {
+			return nil, err
+		}
+		return e, nil
+	default:
+		return nil, fmt.Errorf("unknown LogSelector: %v+", expr)
+	}
+}
+
 func (*LineFmtExpr) isStageExpr() {}
 
 func (e *LineFmtExpr) Shardable(_ bool) bool { return true }
@@ -995,6 +1180,128 @@ func (j *JSONExpressionParser) String() string {
 	return sb.String()
 }
 
+type XMLExpressionParser struct {
+	Expressions []log.LabelExtractionExpr
+
+	implicit
+}
+
+func newXMLExpressionParser(expressions []log.LabelExtractionExpr) *XMLExpressionParser {
+	if _, err := log.NewXMLExpressionParser(expressions); err != nil {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid xml parser: %s", err.Error()), 0, 0))
+	}
+	return &XMLExpressionParser{
+		Expressions: expressions,
+	}
+}
+
+func (*XMLExpressionParser) isStageExpr() {}
+
+func (x *XMLExpressionParser) Shardable(_ bool) bool { return true }
+
+func (x *XMLExpressionParser) Walk(f WalkFn) { f(x) }
+
+func (x *XMLExpressionParser) Accept(v RootVisitor) { v.VisitXMLExpressionParser(x) }
+
+func (x *XMLExpressionParser) Stage() (log.Stage, error) {
+	return log.NewXMLExpressionParser(x.Expressions)
+}
+
+func (x *XMLExpressionParser) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s ", OpPipe, OpParserTypeXML))
+	for i, exp := range x.Expressions {
+		sb.WriteString(exp.Identifier)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(exp.Expression))
+
+		if i+1 != len(x.Expressions) {
+			sb.WriteString(",")
+		}
+	}
+	return sb.String()
+}
+
+const (
+	defaultCSVDelimiter = ","
+	defaultCSVQuote     = `"`
+)
+
+// parserOption is a parser flag with a value, e.g. `--delimiter=";"`.
+type parserOption struct {
+	name, value string
+}
+
+type CSVParserExpr struct {
+	Header      string
+	Delimiter   string
+	Quote       string
+	Expressions []log.LabelExtractionExpr
+
+	implicit
+}
+
+func newCSVParserExpr(header string, options []parserOption, expressions []log.LabelExtractionExpr) *CSVParserExpr {
+	e := CSVParserExpr{
+		Header:      header,
+		Delimiter:   defaultCSVDelimiter,
+		Quote:       defaultCSVQuote,
+		Expressions: expressions,
+	}
+	for _, o := range options {
+		switch o.name {
+		case OpDelimiter:
+			e.Delimiter = o.value
+		case OpQuote:
+			e.Quote = o.value
+		default:
+			panic(logqlmodel.NewParseError(fmt.Sprintf("invalid csv parser flag: %s", o.name), 0, 0))
+		}
+	}
+	if _, err := log.NewCSVParser(e.Header, e.Delimiter, e.Quote, e.Expressions); err != nil {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid csv parser: %s", err.Error()), 0, 0))
+	}
+	return &e
+}
+
+func (*CSVParserExpr) isStageExpr() {}
+
+func (c *CSVParserExpr) Shardable(_ bool) bool { return true }
+
+func (c *CSVParserExpr) Walk(f WalkFn) { f(c) }
+
+func (c *CSVParserExpr) Accept(v RootVisitor) { v.VisitCSVParser(c) }
+
+func (c *CSVParserExpr) Stage() (log.Stage, error) {
+	return log.NewCSVParser(c.Header, c.Delimiter, c.Quote, c.Expressions)
+}
+
+func (c *CSVParserExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s ", OpPipe, OpParserTypeCSV))
+	if c.Delimiter != defaultCSVDelimiter {
+		sb.WriteString(fmt.Sprintf("%s=%s ", OpDelimiter, strconv.Quote(c.Delimiter)))
+	}
+	if c.Quote != defaultCSVQuote {
+		sb.WriteString(fmt.Sprintf("%s=%s ", OpQuote, strconv.Quote(c.Quote)))
+	}
+	sb.WriteString(strconv.Quote(c.Header))
+
+	for i, exp := range c.Expressions {
+		if i == 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(exp.Identifier)
+		sb.WriteString("=")
+		sb.WriteString(strconv.Quote(exp.Expression))
+
+		if i+1 != len(c.Expressions) {
+			sb.WriteString(",")
+		}
+	}
+	return sb.String()
+}
+
 type internedStringSet map[string]struct {
 	s  string
 	ok bool
@@ -1126,6 +1433,7 @@ type LogRange struct {
 	Left     LogSelectorExpr
 	Interval time.Duration
 	Offset   time.Duration
+	At       *AtModifier
 
 	Unwrap *UnwrapExpr
 
@@ -1144,6 +1452,9 @@ func (r LogRange) String() string {
 		offsetExpr := OffsetExpr{Offset: r.Offset}
 		sb.WriteString(offsetExpr.String())
 	}
+	if r.At != nil {
+		sb.WriteString(r.At.String())
+	}
 	return sb.String()
 }
 
@@ -1167,33 +1478,49 @@ func (r *LogRange) WithoutUnwrap() (*LogRange, error) {
 	if err != nil {
 		return nil, err
 	}
+	var at *AtModifier
+	if r.At != nil {
+		copied := *r.At
+		at = &copied
+	}
 	return &LogRange{
 		Left:     left,
 		Interval: r.Interval,
 		Offset:   r.Offset,
+		At:       at,
 	}, nil
 }
 
 func newLogRange(left LogSelectorExpr, interval time.Duration, u *UnwrapExpr, o *OffsetExpr) *LogRange {
 	var offset time.Duration
+	var at *AtModifier
 	if o != nil {
 		offset = o.Offset
+		at = o.At
 	}
 	return &LogRange{
 		Left:     left,
 		Interval: interval,
 		Unwrap:   u,
 		Offset:   offset,
+		At:       at,
 	}
 }
 
+// OffsetExpr holds the modifiers of a range: its offset and its `@` modifier.
 type OffsetExpr struct {
 	Offset time.Duration
+	At     *AtModifier
 }
 
 func (o *OffsetExpr) String() string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf(" %s %s", OpOffset, o.Offset.String()))
+	if o.Offset != 0 || o.At == nil {
+		sb.WriteString(fmt.Sprintf(" %s %s", OpOffset, o.Offset.String()))
+	}
+	if o.At != nil {
+		sb.WriteString(o.At.String())
+	}
 	return sb.String()
 }
 
@@ -1203,6 +1530,87 @@ func newOffsetExpr(offset time.Duration) *OffsetExpr {
 	}
 }
 
+func (o *OffsetExpr) withOffset(offset time.Duration) *OffsetExpr {
+	o.Offset = offset
+	return o
+}
+
+// newAtExpr parses the timestamp of an `@ <timestamp>` modifier, given in
+// seconds since the epoch like in PromQL.
+func newAtExpr(ts string) *OffsetExpr {
+	f, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid timestamp for %s modifier: %s", OpAt, err.Error()), 0, 0))
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) || f*1000 > math.MaxInt64 || f*1000 < math.MinInt64 {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("timestamp out of bounds for %s modifier: %s", OpAt, ts), 0, 0))
+	}
+	return &OffsetExpr{At: &AtModifier{Timestamp: int64(math.Round(f * 1000))}}
+}
+
+func newAtStartOrEndExpr(startOrEnd string) *OffsetExpr {
+	return &OffsetExpr{At: &AtModifier{StartOrEnd: startOrEnd}}
+}
+
+// AtModifier pins the evaluation time of a log range, like the PromQL `@`
+// modifier: `count_over_time({app="foo"}[1h] @ 1609746000)` counts the lines
+// of the hour before 2021-01-04T07:40:00Z at every step of the query.
+type AtModifier struct {
+	// Timestamp is the unix timestamp in milliseconds the range is evaluated at.
+	Timestamp int64
+	// StartOrEnd is set to OpAtStart or OpAtEnd for `@ start()` and `@ end()`
+	// until the modifier is resolved using ResolveAtModifiers.
+	StartOrEnd string
+}
+
+func (a *AtModifier) String() string {
+	if a.StartOrEnd != "" {
+		return fmt.Sprintf(" %s %s()", OpAt, a.StartOrEnd)
+	}
+	return fmt.Sprintf(" %s %.3f", OpAt, float64(a.Timestamp)/1000)
+}
+
+// Time returns the time the range is evaluated at.
+func (a *AtModifier) Time() time.Time {
+	return time.UnixMilli(a.Timestamp).UTC()
+}
+
+// HasAtModifier returns true if any log range of the expression has an `@` modifier.
+func HasAtModifier(expr Expr) bool {
+	var found bool
+	expr.Walk(func(e Expr) {
+		if r, ok := e.(*LogRange); ok && r.At != nil {
+			found = true
+		}
+	})
+	return found
+}
+
+// ResolveAtModifiers replaces the `@ start()` and `@ end()` modifiers of the
+// log ranges of the expression with the given start and end time of the query.
+// It returns true if any modifier was resolved.
+// Queries must be resolved before being split by time, as the start and end of
+// the splits differ from the ones of the original query.
+func ResolveAtModifiers(expr Expr, start, end time.Time) bool {
+	var resolved bool
+	expr.Walk(func(e Expr) {
+		r, ok := e.(*LogRange)
+		if !ok || r.At == nil {
+			return
+		}
+		switch r.At.StartOrEnd {
+		case OpAtStart:
+			r.At = &AtModifier{Timestamp: start.UnixMilli()}
+		case OpAtEnd:
+			r.At = &AtModifier{Timestamp: end.UnixMilli()}
+		default:
+			return
+		}
+		resolved = true
+	})
+	return resolved
+}
+
 const (
 	// vector ops
 	OpTypeSum      = "sum"
@@ -1217,6 +1625,8 @@ const (
 	OpTypeSort     = "sort"
 	OpTypeSortDesc = "sort_desc"
 
+	OpTypeCountValues = "count_values"
+
 	// range vector ops
 	OpRangeTypeCount       = "count_over_time"
 	OpRangeTypeRate        = "rate"
@@ -1233,6 +1643,9 @@ const (
 	OpRangeTypeFirst       = "first_over_time"
 	OpRangeTypeLast        = "last_over_time"
 	OpRangeTypeAbsent      = "absent_over_time"
+	OpRangeTypeHistogram   = "histogram_over_time"
+	OpRangeTypeChanges     = "changes_over_time"
+	OpRangeTypeDistinct    = "distinct_over_time"
 
 	//vector
 	OpTypeVector = "vector"
@@ -1264,6 +1677,8 @@ const (
 	OpParserTypeRegexp  = "regexp"
 	OpParserTypeUnpack  = "unpack"
 	OpParserTypePattern = "pattern"
+	OpParserTypeCSV     = "csv"
+	OpParserTypeXML     = "xml"
 
 	OpFmtLine    = "line_format"
 	OpFmtLabel   = "label_format"
@@ -1273,9 +1688,18 @@ const (
 	OpUnwrap = "unwrap"
 	OpOffset = "offset"
 
+	// @ modifier
+	OpAt      = "@"
+	OpAtStart = "start"
+	OpAtEnd   = "end"
+
 	OpOn       = "on"
 	OpIgnoring = "ignoring"
 
+	// log query join
+	OpJoin   = "join"
+	OpWithin = "within"
+
 	OpGroupLeft  = "group_left"
 	OpGroupRight = "group_right"
 
@@ -1283,11 +1707,17 @@ const (
 	OpConvBytes           = "bytes"
 	OpConvDuration        = "duration"
 	OpConvDurationSeconds = "duration_seconds"
+	// OpConvLabel unwraps the hash of the value of a label, so that the
+	// aggregations comparing values can be applied to string labels.
+	OpConvLabel = "label"
 
 	OpLabelReplace = "label_replace"
 
+	OpHistogramQuantile = "histogram_quantile"
+
 	// function filters
-	OpFilterIP = "ip"
+	OpFilterIP   = "ip"
+	OpFilterWord = "word"
 
 	// drop labels
 	OpDrop = "drop"
@@ -1295,16 +1725,24 @@ const (
 	// keep labels
 	OpKeep = "keep"
 
+	// sampling
+	OpSample = "sample"
+
 	// parser flags
 	OpStrict    = "--strict"
 	OpKeepEmpty = "--keep-empty"
 
+	// csv parser flags
+	OpDelimiter = "--delimiter"
+	OpQuote     = "--quote"
+
 	// internal expressions not represented in LogQL. These are used to
 	// evaluate expressions differently resulting in intermediate formats
 	// that are not consumable by LogQL clients but are used for sharding.
 	OpRangeTypeQuantileSketch     = "__quantile_sketch_over_time__"
 	OpRangeTypeFirstWithTimestamp = "__first_over_time_ts__"
 	OpRangeTypeLastWithTimestamp  = "__last_over_time_ts__"
+	OpRangeTypeDistinctSketch     = "__distinct_sketch_over_time__"
 )
 
 func IsComparisonOperator(op string) bool {
@@ -1399,6 +1837,7 @@ func (e *RangeAggregationExpr) MatcherGroups() ([]MatcherRange, error) {
 				Matchers: xs,
 				Interval: e.Left.Interval,
 				Offset:   e.Left.Offset,
+				At:       e.Left.At,
 			},
 		}, nil
 	}
@@ -1410,17 +1849,27 @@ func (e RangeAggregationExpr) validate() error {
 		switch e.Operation {
 		case OpRangeTypeAvg, OpRangeTypeStddev, OpRangeTypeStdvar, OpRangeTypeQuantile,
 			OpRangeTypeQuantileSketch, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeFirst,
-			OpRangeTypeLast, OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp:
+			OpRangeTypeLast, OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp,
+			OpRangeTypeHistogram, OpRangeTypeChanges, OpRangeTypeDistinct, OpRangeTypeDistinctSketch:
 		default:
 			return fmt.Errorf("grouping not allowed for %s aggregation", e.Operation)
 		}
 	}
 	if e.Left.Unwrap != nil {
+		if e.Left.Unwrap.Operation == OpConvLabel {
+			switch e.Operation {
+			case OpRangeTypeChanges, OpRangeTypeDistinct, OpRangeTypeDistinctSketch:
+				return nil
+			default:
+				return fmt.Errorf("invalid aggregation %s with unwrap %s(), only %s and %s are supported", e.Operation, OpConvLabel, OpRangeTypeChanges, OpRangeTypeDistinct)
+			}
+		}
 		switch e.Operation {
 		case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev,
 			OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeRate, OpRangeTypeRateCounter,
 			OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast, OpRangeTypeQuantileSketch,
-			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp:
+			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp, OpRangeTypeHistogram,
+			OpRangeTypeChanges, OpRangeTypeDistinct, OpRangeTypeDistinctSketch:
 			return nil
 		default:
 			return fmt.Errorf("invalid aggregation %s with unwrap", e.Operation)
@@ -1525,7 +1974,9 @@ type VectorAggregationExpr struct {
 	Grouping  *Grouping `json:"grouping,omitempty"`
 	Params    int       `json:"params"`
 	Operation string    `json:"operation"`
-	err       error
+	// ValueLabel is the name of the label holding the counted sample values of a count_values aggregation.
+	ValueLabel string `json:"value_label,omitempty"`
+	err        error
 	implicit
 }
 
@@ -1561,6 +2012,39 @@ func mustNewVectorAggregationExpr(left SampleExpr, operation string, gr *Groupin
 	}
 }
 
+func newCountValuesExpr(left SampleExpr, valueLabel string, gr *Grouping) SampleExpr {
+	if !model.LabelName(valueLabel).IsValid() {
+		return &VectorAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid label name %q for operation %s", valueLabel, OpTypeCountValues), 0, 0)}
+	}
+	if gr == nil {
+		gr = &Grouping{}
+	}
+	return &VectorAggregationExpr{
+		Left:       left,
+		Operation:  OpTypeCountValues,
+		Grouping:   gr,
+		ValueLabel: valueLabel,
+	}
+}
+
+// CountValuesGrouping returns the grouping of the series produced by a
+// count_values aggregation: the value label is always kept, like in PromQL.
+func (e *VectorAggregationExpr) CountValuesGrouping() *Grouping {
+	if e.Grouping == nil {
+		return &Grouping{Groups: []string{e.ValueLabel}}
+	}
+	if !e.Grouping.Without {
+		return &Grouping{Groups: append(slices.Clone(e.Grouping.Groups), e.ValueLabel)}
+	}
+	groups := make([]string, 0, len(e.Grouping.Groups))
+	for _, g := range e.Grouping.Groups {
+		if g != e.ValueLabel {
+			groups = append(groups, g)
+		}
+	}
+	return &Grouping{Groups: groups, Without: true}
+}
+
 func (e *VectorAggregationExpr) isSampleExpr() {}
 
 func (e *VectorAggregationExpr) MatcherGroups() ([]MatcherRange, error) {
@@ -1611,6 +2095,8 @@ func (e *VectorAggregationExpr) String() string {
 	// bottomK and topk can have first parameter as 0
 	case OpTypeBottomK, OpTypeTopK:
 		params = []string{fmt.Sprintf("%d", e.Params), e.Left.String()}
+	case OpTypeCountValues:
+		params = []string{strconv.Quote(e.ValueLabel), e.Left.String()}
 	default:
 		if e.Params != 0 {
 			params = []string{fmt.Sprintf("%d", e.Params), e.Left.String()}
@@ -1629,7 +2115,7 @@ func (e *VectorAggregationExpr) Shardable(topLevel bool) bool {
 
 	switch e.Operation {
 
-	case OpTypeCount, OpTypeAvg:
+	case OpTypeCount, OpTypeAvg, OpTypeCountValues:
 		// count is shardable if labels are not mutated
 		// otherwise distinct values can be present in multiple shards and
 		// counted twice.
@@ -1667,6 +2153,14 @@ func (e *VectorAggregationExpr) Shardable(topLevel bool) bool {
 				return false
 			}
 		}
+		// The changes and distinct values of a series spread over several
+		// shards can't be added up.
+		if child, ok := e.Left.(*RangeAggregationExpr); ok && ReducesLabels(child) {
+			switch child.Operation {
+			case OpRangeTypeChanges, OpRangeTypeDistinct:
+				return false
+			}
+		}
 		return true
 
 	}
@@ -2250,6 +2744,266 @@ func (e *LabelReplaceExpr) String() string {
 	return sb.String()
 }
 
+// subqueryRange is the `[<range>:<step>]` suffix of a subquery as scanned by the lexer.
+type subqueryRange struct {
+	Range time.Duration
+	Step  time.Duration
+}
+
+// subqueryOps lists the range aggregations that can be applied to the samples of a subquery.
+// Operations that count log lines or bytes are not meaningful on samples and are rejected.
+var subqueryOps = map[string]struct{}{
+	OpRangeTypeAvg:      {},
+	OpRangeTypeChanges:  {},
+	OpRangeTypeCount:    {},
+	OpRangeTypeDistinct: {},
+	OpRangeTypeFirst:    {},
+	OpRangeTypeLast:     {},
+	OpRangeTypeMax:      {},
+	OpRangeTypeMin:      {},
+	OpRangeTypeQuantile: {},
+	OpRangeTypeStddev:   {},
+	OpRangeTypeStdvar:   {},
+	OpRangeTypeSum:      {},
+}
+
+// SubqueryExpr applies a range aggregation to the samples of an inner metric
+// expression evaluated at the subquery resolution, e.g.
+// `max_over_time(rate({app="foo"}[5m])[1h:1m])`.
+// A zero Step means the step of the outer query is used.
+type SubqueryExpr struct {
+	Left      SampleExpr
+	Operation string
+	Range     time.Duration
+	Step      time.Duration
+	Offset    time.Duration
+
+	Params *float64
+	err    error
+	implicit
+}
+
+func newSubqueryExpr(left SampleExpr, operation string, rng subqueryRange, offset *OffsetExpr, stringParams *string) SampleExpr {
+	var params *float64
+	if stringParams != nil {
+		if operation != OpRangeTypeQuantile {
+			return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
+		}
+		var err error
+		params = new(float64)
+		*params, err = strconv.ParseFloat(*stringParams, 64)
+		if err != nil {
+			return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", operation, err), 0, 0)}
+		}
+	} else if operation == OpRangeTypeQuantile {
+		return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
+	}
+
+	e := &SubqueryExpr{
+		Left:      left,
+		Operation: operation,
+		Range:     rng.Range,
+		Step:      rng.Step,
+		Params:    params,
+	}
+	if offset != nil {
+		if offset.At != nil {
+			return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("%s modifier is not supported with subqueries", OpAt), 0, 0)}
+		}
+		e.Offset = offset.Offset
+	}
+	if err := e.validate(); err != nil {
+		return &SubqueryExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	return e
+}
+
+func (e *SubqueryExpr) validate() error {
+	if _, ok := subqueryOps[e.Operation]; !ok {
+		return fmt.Errorf("invalid aggregation %s with subquery", e.Operation)
+	}
+	if e.Range <= 0 {
+		return fmt.Errorf("invalid subquery range %s: must be greater than 0", model.Duration(e.Range))
+	}
+	if e.Step < 0 {
+		return fmt.Errorf("invalid subquery step %s: must not be negative", model.Duration(e.Step))
+	}
+	if _, ok := e.Left.(*LiteralExpr); ok {
+		return fmt.Errorf("invalid subquery: expected a metric expression but got a literal")
+	}
+	return nil
+}
+
+func (e *SubqueryExpr) isSampleExpr() {}
+
+func (e *SubqueryExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Selector()
+}
+
+// MatcherGroups returns the matcher groups of the inner expression with the
+// subquery range and offset added on top of their own, unless their
+// evaluation time is pinned with an `@` modifier.
+func (e *SubqueryExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	groups, err := e.Left.MatcherGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].At != nil {
+			continue
+		}
+		groups[i].Interval += e.Range
+		groups[i].Offset += e.Offset
+	}
+	return groups, nil
+}
+
+func (e *SubqueryExpr) Extractor() (SampleExtractor, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Extractor()
+}
+
+// Shardable returns false because the subquery aggregation needs all the
+// samples of a series. The inner expression is sharded independently.
+func (e *SubqueryExpr) Shardable(_ bool) bool {
+	return false
+}
+
+func (e *SubqueryExpr) Walk(f WalkFn) {
+	f(e)
+	if e.Left == nil {
+		return
+	}
+	e.Left.Walk(f)
+}
+
+func (e *SubqueryExpr) Accept(v RootVisitor) { v.VisitSubquery(e) }
+
+// RangeString returns the `[<range>:<step>]` part of the subquery.
+func (e *SubqueryExpr) RangeString() string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	sb.WriteString(model.Duration(e.Range).String())
+	sb.WriteString(":")
+	if e.Step != 0 {
+		sb.WriteString(model.Duration(e.Step).String())
+	}
+	sb.WriteString("]")
+	if e.Offset != 0 {
+		offsetExpr := OffsetExpr{Offset: e.Offset}
+		sb.WriteString(offsetExpr.String())
+	}
+	return sb.String()
+}
+
+func (e *SubqueryExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	if e.Params != nil {
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+		sb.WriteString(",")
+	}
+	sb.WriteString(e.Left.String())
+	sb.WriteString(e.RangeString())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// HistogramQuantileExpr calculates the φ-quantile from the cumulative `le`
+// buckets returned by the inner expression, e.g.
+// `histogram_quantile(0.99, sum by (le) (histogram_over_time({app="foo"} | unwrap latency [5m])))`.
+type HistogramQuantileExpr struct {
+	Left     SampleExpr
+	Quantile float64
+	err      error
+
+	implicit
+}
+
+func newHistogramQuantileExpr(left SampleExpr, stringQuantile string) SampleExpr {
+	q, err := strconv.ParseFloat(stringQuantile, 64)
+	if err != nil {
+		return &HistogramQuantileExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", OpHistogramQuantile, err), 0, 0)}
+	}
+	e := &HistogramQuantileExpr{
+		Left:     left,
+		Quantile: q,
+	}
+	if err := e.validate(); err != nil {
+		return &HistogramQuantileExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	return e
+}
+
+func (e *HistogramQuantileExpr) validate() error {
+	if e.Quantile < 0 || e.Quantile > 1 {
+		return fmt.Errorf("invalid quantile %s for operation %s: must be between 0 and 1", strconv.FormatFloat(e.Quantile, 'f', -1, 64), OpHistogramQuantile)
+	}
+	if _, ok := e.Left.(*LiteralExpr); ok {
+		return fmt.Errorf("invalid %s: expected a metric expression but got a literal", OpHistogramQuantile)
+	}
+	return nil
+}
+
+func (e *HistogramQuantileExpr) isSampleExpr() {}
+
+func (e *HistogramQuantileExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Selector()
+}
+
+func (e *HistogramQuantileExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.MatcherGroups()
+}
+
+func (e *HistogramQuantileExpr) Extractor() (SampleExtractor, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Extractor()
+}
+
+// Shardable returns false because all buckets of a histogram are required to
+// calculate the quantile. The inner expression is sharded independently.
+func (e *HistogramQuantileExpr) Shardable(_ bool) bool {
+	return false
+}
+
+func (e *HistogramQuantileExpr) Walk(f WalkFn) {
+	f(e)
+	if e.Left == nil {
+		return
+	}
+	e.Left.Walk(f)
+}
+
+func (e *HistogramQuantileExpr) Accept(v RootVisitor) { v.VisitHistogramQuantile(e) }
+
+func (e *HistogramQuantileExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(OpHistogramQuantile)
+	sb.WriteString("(")
+	sb.WriteString(strconv.FormatFloat(e.Quantile, 'f', -1, 64))
+	sb.WriteString(",")
+	sb.WriteString(e.Left.String())
+	sb.WriteString(")")
+	return sb.String()
+}
+
 // shardableOps lists the operations which may be sharded, but are not
 // guaranteed to be. See the `Shardable()` implementations
 // on the respective expr types for more details.
@@ -2277,6 +3031,8 @@ var shardableOps = map[string]bool{
 	OpTypeCount: true,
 	OpTypeMax:   true,
 	OpTypeMin:   true,
+	// count_values is remapped into a sum of the counts of each shard.
+	OpTypeCountValues: true,
 
 	// range vector ops
 	OpRangeTypeAvg:       true,
@@ -2290,6 +3046,9 @@ var shardableOps = map[string]bool{
 	OpRangeTypeMax:       true,
 	OpRangeTypeMin:       true,
 	OpRangeTypeQuantile:  true,
+	OpRangeTypeHistogram: true,
+	OpRangeTypeChanges:   true,
+	OpRangeTypeDistinct:  true,
 
 	// binops - arith
 	OpTypeAdd: true,
@@ -2299,6 +3058,8 @@ var shardableOps = map[string]bool{
 type MatcherRange struct {
 	Matchers         []*labels.Matcher
 	Interval, Offset time.Duration
+	// At is the `@` modifier of the range, if any.
+	At *AtModifier
 }
 
 func MatcherGroups(expr Expr) ([]MatcherRange, error) {
@@ -50,6 +50,12 @@ func Test_logSelectorExpr_String(t *testing.T) {
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | regexp "(?P<foo>foo|bar)"`, true},
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | regexp "(?P<foo>foo|bar)" | ( ( foo<5.01 , bar>20ms ) or foo="bar" ) | line_format "blip{{.boop}}bap" | label_format foo=bar,bar="blip{{.blop}}"`, true},
 		{`{foo="bar"} | logfmt | counter>-1 | counter>=-1 | counter<-1 | counter<=-1 | counter!=-1 | counter==-1`, true},
+		{`{foo="bar"} |= "baz" | xml | level="error"`, true},
+		{`{foo="bar"} |= "baz" | xml level="/event/@level",user="//user[1]" | level="error"`, true},
+		{`{foo="bar"} |= "baz" | csv "ts,method,status" | status>=500`, true},
+		{`{foo="bar"} |= "baz" | csv --delimiter=";" --quote="'" "ts;method;status" verb="method",status="status" | status>=500`, true},
+		{`{foo="bar"} | csv --quote="" "ts,method,status"`, true},
+		{`{foo="bar"} |= "baz" | sample 0.01`, true},
 	}
 
 	for _, tt := range tests {
@@ -93,6 +99,7 @@ func Test_SampleExpr_String(t *testing.T) {
 		`sum(count_over_time({job="mysql"} | logfmt --strict [5m] offset 10m))`,
 		`sum(count_over_time({job="mysql"} | pattern "<foo> bar <buzz>" | json [5m]))`,
 		`sum(count_over_time({job="mysql"} | unpack | json [5m]))`,
+		`sum(count_over_time({job="mysql"} | sample 0.25 [5m]))`,
 		`sum(count_over_time({job="mysql"} | regexp "(?P<foo>foo|bar)" [5m]))`,
 		`sum(count_over_time({job="mysql"} | regexp "(?P<foo>foo|bar)" [5m] offset 10y))`,
 		`topk(10,sum(rate({region="us-east1"}[5m])) by (name))`,
@@ -187,6 +194,19 @@ func Test_SampleExpr_String(t *testing.T) {
 				or on ()
 				((sum by(typename,pool,commandname,colo) (sum_over_time({_namespace_="appspace", _schema_="appspace-1h", pool=~"r1testlvs", colo=~"slc|lvs|rno", env!~"(pre-production|sandbox)"} | logfmt | status!="0" | ( ( type=~"(?i)^(Error|Exception|Fatal|ERRPAGE|ValidationError)$" or typename=~"(?i)^(Error|Exception|Fatal|ERRPAGE|ValidationError)$" ) or status=~"(?i)^(Error|Exception|Fatal|ERRPAGE|ValidationError)$" ) | commandname=~"(?i).*|UNSET" | unwrap sumcount[5m])) / 60) / 60))`,
 		`{app="foo"} | logfmt code="response.code", IPAddress="host"`,
+		`max_over_time(rate({app="foo"}[5m])[1h:1m])`,
+		`avg_over_time(sum by (app) (rate({app="foo"}[5m]))[1h:] offset 10m)`,
+		`quantile_over_time(0.9, (rate({app="foo"}[5m]) / rate({app="bar"}[5m]))[1d:5m])`,
+		`max_over_time(max_over_time(rate({app="foo"}[1m])[10m:1m])[1h:10m])`,
+		`histogram_over_time({app="foo"} | unwrap latency [5m]) by (app)`,
+		`histogram_quantile(0.99, sum by (le) (histogram_over_time({app="foo"} | unwrap latency [5m])))`,
+		`changes_over_time({app="foo"} | unwrap latency [5m]) by (app)`,
+		`changes_over_time(sum by (app) (rate({app="foo"}[5m]))[1h:5m])`,
+		`distinct_over_time({app="foo"} | json | unwrap status [5m])`,
+		`count_values("status", sum by (app) (distinct_over_time({app="foo"} | json | unwrap status [5m])))`,
+		`count_values without (app) ("value", rate({app="foo"}[5m]))`,
+		`count_over_time({app="foo"}[1h] offset 1d @ 1609746000.123)`,
+		`sum(rate({app="foo"}[5m] @ start())) / sum(rate({app="foo"}[5m] @ end()))`,
 	} {
 		t.Run(tc, func(t *testing.T) {
 			expr, err := ParseExpr(tc)
@@ -261,6 +281,18 @@ func TestMatcherGroups(t *testing.T) {
 				},
 			},
 		},
+		{
+			query: `max_over_time(count_over_time({job="foo"}[5m] @ 100)[1h:])`,
+			exp: []MatcherRange{
+				{
+					Interval: 5 * time.Minute,
+					At:       &AtModifier{Timestamp: 100000},
+					Matchers: []*labels.Matcher{
+						labels.MustNewMatcher(labels.MatchEqual, "job", "foo"),
+					},
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprint(i), func(t *testing.T) {
 			expr, err := ParseExpr(tc.query)
@@ -272,6 +304,36 @@ func TestMatcherGroups(t *testing.T) {
 	}
 }
 
+func TestResolveAtModifiers(t *testing.T) {
+	start, end := time.Unix(100, 0), time.Unix(200, 0)
+	for _, tc := range []struct {
+		query    string
+		expected string
+		resolved bool
+	}{
+		{
+			query:    `count_over_time({job="foo"}[5m])`,
+			expected: `count_over_time({job="foo"}[5m])`,
+		},
+		{
+			query:    `count_over_time({job="foo"}[5m] @ 50)`,
+			expected: `count_over_time({job="foo"}[5m] @ 50.000)`,
+		},
+		{
+			query:    `count_over_time({job="foo"}[5m] @ start()) / count_over_time({job="foo"}[5m] offset 1m @ end())`,
+			expected: `(count_over_time({job="foo"}[5m] @ 100.000) / count_over_time({job="foo"}[5m] offset 1m0s @ 200.000))`,
+			resolved: true,
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := ParseExpr(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.resolved, ResolveAtModifiers(expr, start, end))
+			require.Equal(t, tc.expected, expr.String())
+		})
+	}
+}
+
 func Test_NilFilterDoesntPanic(t *testing.T) {
 	t.Parallel()
 	for _, tc := range []string{
@@ -544,6 +606,20 @@ func Test_FilterMatcher(t *testing.T) {
 			},
 			[]linecheck{{"foo", false}, {"bar", true}, {"127.0.0.2", true}, {"127.0.0.1", false}},
 		},
+		{
+			`{app="foo"} |= word("timeout") or word("refused")`,
+			[]*labels.Matcher{
+				mustNewMatcher(labels.MatchEqual, "app", "foo"),
+			},
+			[]linecheck{{"upstream Timeout", true}, {"connection refused", true}, {"timeouts", false}, {"none", false}},
+		},
+		{
+			`{app="foo"} != word("timeout")`,
+			[]*labels.Matcher{
+				mustNewMatcher(labels.MatchEqual, "app", "foo"),
+			},
+			[]linecheck{{"upstream timeout", false}, {"read_timeout=5s", true}},
+		},
 		{
 			`{app="foo"} |> "<_>foo<_>" or "<_>bar<_>"`,
 			[]*labels.Matcher{
@@ -865,6 +941,7 @@ func Test_parserExpr_Parser(t *testing.T) {
 	}{
 		{"json", OpParserTypeJSON, "", log.NewJSONParser(), false, false},
 		{"unpack", OpParserTypeUnpack, "", log.NewUnpackParser(), false, false},
+		{"xml", OpParserTypeXML, "", log.NewXMLParser(), false, false},
 		{"pattern", OpParserTypePattern, "<foo> bar <buzz>", mustNewPatternParser("<foo> bar <buzz>"), false, false},
 		{"pattern err", OpParserTypePattern, "bar", nil, true, true},
 		{"regexp", OpParserTypeRegexp, "(?P<foo>foo)", mustNewRegexParser("(?P<foo>foo)"), false, false},
@@ -905,6 +982,7 @@ func Test_parserExpr_String(t *testing.T) {
 		{"valid pattern", OpParserTypePattern, "buzz", `| pattern "buzz"`},
 		{"empty pattern", OpParserTypePattern, "", `| pattern ""`},
 		{"valid json", OpParserTypeJSON, "", `| json`},
+		{"valid xml", OpParserTypeXML, "", `| xml`},
 	}
 
 	for _, tt := range tests {
@@ -1068,6 +1146,27 @@ func TestParseLargeQuery(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAddSamplingExpr(t *testing.T) {
+	for query, expected := range map[string]string{
+		`{foo="bar"}`:                     `{foo="bar"} | sample 0.01`,
+		`{foo="bar"} |= "error" | logfmt`: `{foo="bar"} |= "error" | logfmt | sample 0.01`,
+		`sum(rate({foo="bar"}[5m])) / sum(rate({foo="baz"}[5m]))`: `(sum(rate({foo="bar"} | sample 0.01[5m])) / sum(rate({foo="baz"} | sample 0.01[5m])))`,
+		`{foo="bar"} | json | join on(id) within 1m {foo="baz"}`:  `{foo="bar"} | json | sample 0.01 | join on(id) within 1m {foo="baz"} | sample 0.01`,
+		`vector(1)`: `vector(1.000000)`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			expr, err := ParseExpr(query)
+			require.NoError(t, err)
+			expr, err = AddSamplingExpr(expr, 0.01)
+			require.NoError(t, err)
+			require.Equal(t, expected, expr.String())
+		})
+	}
+
+	_, err := AddSamplingExpr(&MatchersExpr{}, 1.5)
+	require.ErrorIs(t, err, log.ErrSampleInvalidRatio)
+}
+
 func TestLogSelectorExprHasFilter(t *testing.T) {
 	for query, hasFilter := range map[string]bool{
 		`{foo="bar"} |= ""`:                  false,
@@ -52,9 +52,10 @@ func (v *cloneVisitor) VisitBinOp(e *BinOpExpr) {
 
 func (v *cloneVisitor) VisitVectorAggregation(e *VectorAggregationExpr) {
 	copied := &VectorAggregationExpr{
-		Left:      MustClone[SampleExpr](e.Left),
-		Params:    e.Params,
-		Operation: e.Operation,
+		Left:       MustClone[SampleExpr](e.Left),
+		Params:     e.Params,
+		Operation:  e.Operation,
+		ValueLabel: e.ValueLabel,
 	}
 
 	if e.Grouping != nil {
@@ -82,11 +83,35 @@ func (v *cloneVisitor) VisitRangeAggregation(e *RangeAggregationExpr) {
 	v.cloned = copied
 }
 
+func (v *cloneVisitor) VisitHistogramQuantile(e *HistogramQuantileExpr) {
+	v.cloned = &HistogramQuantileExpr{
+		Left:     MustClone[SampleExpr](e.Left),
+		Quantile: e.Quantile,
+	}
+}
+
 func (v *cloneVisitor) VisitLabelReplace(e *LabelReplaceExpr) {
 	left := MustClone[SampleExpr](e.Left)
 	v.cloned = mustNewLabelReplaceExpr(left, e.Dst, e.Replacement, e.Src, e.Regex)
 }
 
+func (v *cloneVisitor) VisitSubquery(e *SubqueryExpr) {
+	copied := &SubqueryExpr{
+		Left:      MustClone[SampleExpr](e.Left),
+		Operation: e.Operation,
+		Range:     e.Range,
+		Step:      e.Step,
+		Offset:    e.Offset,
+	}
+
+	if e.Params != nil {
+		tmp := *e.Params
+		copied.Params = &tmp
+	}
+
+	v.cloned = copied
+}
+
 func (v *cloneVisitor) VisitLiteral(e *LiteralExpr) {
 	v.cloned = &LiteralExpr{Val: e.Val}
 }
@@ -101,6 +126,10 @@ func (v *cloneVisitor) VisitLogRange(e *LogRange) {
 		Interval: e.Interval,
 		Offset:   e.Offset,
 	}
+	if e.At != nil {
+		at := *e.At
+		copied.At = &at
+	}
 	if e.Unwrap != nil {
 		copied.Unwrap = &UnwrapExpr{
 			Identifier: e.Unwrap.Identifier,
@@ -140,6 +169,32 @@ func (v *cloneVisitor) VisitPipeline(e *PipelineExpr) {
 	v.cloned = copied
 }
 
+func (v *cloneVisitor) VisitJoin(e *JoinExpr) {
+	copied := &JoinExpr{
+		Left:   MustClone[LogSelectorExpr](e.Left),
+		Right:  MustClone[LogSelectorExpr](e.Right),
+		On:     make([]string, len(e.On)),
+		Within: e.Within,
+	}
+	copy(copied.On, e.On)
+
+	v.cloned = copied
+}
+
+func (v *cloneVisitor) VisitCSVParser(e *CSVParserExpr) {
+	copied := &CSVParserExpr{
+		Header:    e.Header,
+		Delimiter: e.Delimiter,
+		Quote:     e.Quote,
+	}
+	if e.Expressions != nil {
+		copied.Expressions = make([]log.LabelExtractionExpr, len(e.Expressions))
+		copy(copied.Expressions, e.Expressions)
+	}
+
+	v.cloned = copied
+}
+
 func (v *cloneVisitor) VisitDecolorize(*DecolorizeExpr) {
 	v.cloned = &DecolorizeExpr{}
 }
@@ -159,6 +214,14 @@ func (v *cloneVisitor) VisitDropLabels(e *DropLabelsExpr) {
 	v.cloned = copied
 }
 
+func (v *cloneVisitor) VisitMacro(e *MacroExpr) {
+	v.cloned = &MacroExpr{Name: e.Name}
+}
+
+func (v *cloneVisitor) VisitSampling(e *SamplingExpr) {
+	v.cloned = &SamplingExpr{Ratio: e.Ratio}
+}
+
 func (v *cloneVisitor) VisitJSONExpressionParser(e *JSONExpressionParser) {
 	copied := &JSONExpressionParser{
 		Expressions: make([]log.LabelExtractionExpr, len(e.Expressions)),
@@ -239,6 +302,8 @@ func cloneLabelFilterer(filter log.LabelFilterer) log.LabelFilterer {
 		return copied
 	case *log.IPLabelFilter:
 		return log.NewIPLabelFilter(concrete.Pattern, concrete.Label, concrete.Ty)
+	case *log.WordLabelFilter:
+		return log.NewWordLabelFilter(concrete.Pattern, concrete.Label, concrete.Ty)
 	}
 	return nil
 }
@@ -300,3 +365,12 @@ func (v *cloneVisitor) VisitLogfmtParser(e *LogfmtParserExpr) {
 		KeepEmpty: e.KeepEmpty,
 	}
 }
+
+func (v *cloneVisitor) VisitXMLExpressionParser(e *XMLExpressionParser) {
+	copied := &XMLExpressionParser{
+		Expressions: make([]log.LabelExtractionExpr, len(e.Expressions)),
+	}
+	copy(copied.Expressions, e.Expressions)
+
+	v.cloned = copied
+}
@@ -34,6 +34,7 @@ import (
   bytes                   uint64
   str                     string
   duration                time.Duration
+  subqueryRange           subqueryRange
   LiteralExpr             *LiteralExpr
   BinOpModifier           *BinOpOptions
   BoolModifier            *BinOpOptions
@@ -61,6 +62,10 @@ import (
   LabelExtractionExpressionList []log.LabelExtractionExpr
   JSONExpressionParser          *JSONExpressionParser
   LogfmtExpressionParser        *LogfmtExpressionParser
+  XMLExpressionParser           *XMLExpressionParser
+  CSVParser                     *CSVParserExpr
+  ParserOption                  parserOption
+  ParserOptions                 []parserOption
 
   UnwrapExpr              *UnwrapExpr
   DecolorizeExpr          *DecolorizeExpr
@@ -96,6 +101,8 @@ import (
 %type <BinOpExpr>             binOpExpr
 %type <LiteralExpr>           literalExpr
 %type <LabelReplaceExpr>      labelReplaceExpr
+%type <MetricExpr>            subqueryExpr
+%type <MetricExpr>            histogramQuantileExpr
 %type <BinOpModifier>         binOpModifier
 %type <BoolModifier>          boolModifier
 %type <OnOrIgnoringModifier>  onOrIgnoringModifier
@@ -106,7 +113,7 @@ import (
 %type <BytesFilter>           bytesFilter
 %type <NumberFilter>          numberFilter
 %type <DurationFilter>        durationFilter
-%type <LabelFilter>           labelFilter
+%type <LabelFilter>           labelFilter wordLabelFilter
 %type <LineFilters>           lineFilters
 %type <LineFilter>            lineFilter
 %type <OrFilter>              orFilter
@@ -126,20 +133,26 @@ import (
 %type <LabelExtractionExpressionList>    labelExtractionExpressionList
 %type <LogfmtExpressionParser>           logfmtExpressionParser
 %type <JSONExpressionParser>             jsonExpressionParser
+%type <XMLExpressionParser>              xmlExpressionParser
+%type <CSVParser>                        csvParser
+%type <ParserOption>                     parserOption
+%type <ParserOptions>                    parserOptions
 %type <UnwrapExpr>            unwrapExpr
 %type <UnitFilter>            unitFilter
 %type <IPLabelFilter>         ipLabelFilter
-%type <OffsetExpr>            offsetExpr
+%type <OffsetExpr>            offsetExpr atExpr
 
 %token <bytes> BYTES
 %token <str>      IDENTIFIER STRING NUMBER PARSER_FLAG
 %token <duration> DURATION RANGE
+%token <subqueryRange> SUBQUERY_RANGE
 %token <val>      MATCHERS LABELS EQ RE NRE NPA OPEN_BRACE CLOSE_BRACE OPEN_BRACKET CLOSE_BRACKET COMMA DOT PIPE_MATCH PIPE_EXACT PIPE_PATTERN
                   OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE RATE_COUNTER SUM SORT SORT_DESC AVG MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK
                   BYTES_OVER_TIME BYTES_RATE BOOL JSON REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
-                  MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
+                  MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV LABEL_CONV
                   FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
-                  DECOLORIZE DROP KEEP
+                  DECOLORIZE DROP KEEP CSV XML HISTOGRAM_OVER_TIME HISTOGRAM_QUANTILE AT START END
+                  CHANGES_OVER_TIME DISTINCT_OVER_TIME COUNT_VALUES JOIN WITHIN WORD SAMPLE
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -164,6 +177,8 @@ metricExpr:
     | binOpExpr                                     { $$ = $1 }
     | literalExpr                                   { $$ = $1 }
     | labelReplaceExpr                              { $$ = $1 }
+    | subqueryExpr                                  { $$ = $1 }
+    | histogramQuantileExpr                         { $$ = $1 }
     | vectorExpr                                    { $$ = $1 }
     | OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS { $$ = $2 }
     ;
@@ -172,6 +187,8 @@ logExpr:
       selector                                    { $$ = newMatcherExpr($1)}
     | selector pipelineExpr                       { $$ = newPipelineExpr(newMatcherExpr($1), $2)}
     | OPEN_PARENTHESIS logExpr CLOSE_PARENTHESIS  { $$ = $2 }
+    | selector PIPE JOIN ON OPEN_PARENTHESIS labels CLOSE_PARENTHESIS WITHIN DURATION logExpr               { $$ = newJoinExpr(newMatcherExpr($1), $6, $9, $10) }
+    | selector pipelineExpr PIPE JOIN ON OPEN_PARENTHESIS labels CLOSE_PARENTHESIS WITHIN DURATION logExpr  { $$ = newJoinExpr(newPipelineExpr(newMatcherExpr($1), $2), $7, $10, $11) }
     ;
 
 logRangeExpr:
@@ -213,6 +230,7 @@ convOp:
     BYTES_CONV              { $$ = OpConvBytes }
   | DURATION_CONV           { $$ = OpConvDuration }
   | DURATION_SECONDS_CONV   { $$ = OpConvDurationSeconds }
+  | LABEL_CONV              { $$ = OpConvLabel }
   ;
 
 rangeAggregationExpr:
@@ -222,6 +240,13 @@ rangeAggregationExpr:
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExpr($5, $1, $7, &$3) }
     ;
 
+subqueryExpr:
+      rangeOp OPEN_PARENTHESIS metricExpr SUBQUERY_RANGE CLOSE_PARENTHESIS                               { $$ = newSubqueryExpr($3, $1, $4, nil, nil) }
+    | rangeOp OPEN_PARENTHESIS metricExpr SUBQUERY_RANGE offsetExpr CLOSE_PARENTHESIS                    { $$ = newSubqueryExpr($3, $1, $4, $5, nil) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA metricExpr SUBQUERY_RANGE CLOSE_PARENTHESIS                  { $$ = newSubqueryExpr($5, $1, $6, nil, &$3) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA metricExpr SUBQUERY_RANGE offsetExpr CLOSE_PARENTHESIS       { $$ = newSubqueryExpr($5, $1, $6, $7, &$3) }
+    ;
+
 vectorAggregationExpr:
     // Aggregations with 1 argument.
       vectorOp OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS                               { $$ = mustNewVectorAggregationExpr($3, $1, nil, nil) }
@@ -231,6 +256,10 @@ vectorAggregationExpr:
     | vectorOp OPEN_PARENTHESIS NUMBER COMMA metricExpr CLOSE_PARENTHESIS                 { $$ = mustNewVectorAggregationExpr($5, $1, nil, &$3) }
     | vectorOp OPEN_PARENTHESIS NUMBER COMMA metricExpr CLOSE_PARENTHESIS grouping        { $$ = mustNewVectorAggregationExpr($5, $1, $7, &$3) }
     | vectorOp grouping OPEN_PARENTHESIS NUMBER COMMA metricExpr CLOSE_PARENTHESIS        { $$ = mustNewVectorAggregationExpr($6, $1, $2, &$4) }
+    // count_values takes the name of the label holding the values.
+    | COUNT_VALUES OPEN_PARENTHESIS STRING COMMA metricExpr CLOSE_PARENTHESIS             { $$ = newCountValuesExpr($5, $3, nil) }
+    | COUNT_VALUES OPEN_PARENTHESIS STRING COMMA metricExpr CLOSE_PARENTHESIS grouping    { $$ = newCountValuesExpr($5, $3, $7) }
+    | COUNT_VALUES grouping OPEN_PARENTHESIS STRING COMMA metricExpr CLOSE_PARENTHESIS    { $$ = newCountValuesExpr($6, $4, $2) }
     ;
 
 labelReplaceExpr:
@@ -238,6 +267,10 @@ labelReplaceExpr:
       { $$ = mustNewLabelReplaceExpr($3, $5, $7, $9, $11)}
     ;
 
+histogramQuantileExpr:
+    HISTOGRAM_QUANTILE OPEN_PARENTHESIS NUMBER COMMA metricExpr CLOSE_PARENTHESIS { $$ = newHistogramQuantileExpr($5, $3) }
+    ;
+
 filter:
       PIPE_MATCH                       { $$ = log.LineMatchRegexp }
     | PIPE_EXACT                       { $$ = log.LineMatchEqual }
@@ -276,16 +309,21 @@ pipelineStage:
   | PIPE labelParser             { $$ = $2 }
   | PIPE jsonExpressionParser    { $$ = $2 }
   | PIPE logfmtExpressionParser  { $$ = $2 }
+  | PIPE xmlExpressionParser     { $$ = $2 }
+  | PIPE csvParser               { $$ = $2 }
   | PIPE labelFilter             { $$ = &LabelFilterExpr{LabelFilterer: $2 }}
   | PIPE lineFormatExpr          { $$ = $2 }
   | PIPE decolorizeExpr          { $$ = $2 }
   | PIPE labelFormatExpr         { $$ = $2 }
   | PIPE dropLabelsExpr          { $$ = $2 }
   | PIPE keepLabelsExpr          { $$ = $2 }
+  | PIPE SAMPLE NUMBER           { $$ = newSamplingExpr($3) }
+  | PIPE AT IDENTIFIER           { $$ = newMacroExpr($3) }
   ;
 
 filterOp:
-  IP { $$ = OpFilterIP }
+    IP   { $$ = OpFilterIP }
+  | WORD { $$ = OpFilterWord }
   ;
 
 orFilter:
@@ -321,6 +359,7 @@ labelParser:
   | REGEXP STRING       { $$ = newLabelParserExpr(OpParserTypeRegexp, $2) }
   | UNPACK              { $$ = newLabelParserExpr(OpParserTypeUnpack, "") }
   | PATTERN STRING      { $$ = newLabelParserExpr(OpParserTypePattern, $2) }
+  | XML                 { $$ = newLabelParserExpr(OpParserTypeXML, "") }
   ;
 
 jsonExpressionParser:
@@ -331,6 +370,26 @@ logfmtExpressionParser:
   | LOGFMT labelExtractionExpressionList              { $$ = newLogfmtExpressionParser($2, nil)}
   ;
 
+xmlExpressionParser:
+    XML labelExtractionExpressionList { $$ = newXMLExpressionParser($2) }
+  ;
+
+parserOption:
+    PARSER_FLAG EQ STRING     { $$ = parserOption{ name: $1, value: $3 } }
+  ;
+
+parserOptions:
+    parserOption                 { $$ = []parserOption{ $1 } }
+  | parserOptions parserOption   { $$ = append($1, $2) }
+  ;
+
+csvParser:
+    CSV STRING                                                { $$ = newCSVParserExpr($2, nil, nil) }
+  | CSV STRING labelExtractionExpressionList                  { $$ = newCSVParserExpr($2, nil, $3) }
+  | CSV parserOptions STRING                                  { $$ = newCSVParserExpr($3, $2, nil) }
+  | CSV parserOptions STRING labelExtractionExpressionList    { $$ = newCSVParserExpr($3, $2, $4) }
+  ;
+
 lineFormatExpr: LINE_FMT STRING { $$ = newLineFmtExpr($2) };
 
 decolorizeExpr: DECOLORIZE { $$ = newDecolorizeExpr() };
@@ -352,6 +411,7 @@ labelFormatExpr:
 labelFilter:
       matcher                                        { $$ = log.NewStringLabelFilter($1) }
     | ipLabelFilter                                  { $$ = $1 }
+    | wordLabelFilter                                { $$ = $1 }
     | unitFilter                                     { $$ = $1 }
     | numberFilter                                   { $$ = $1 }
     | OPEN_PARENTHESIS labelFilter CLOSE_PARENTHESIS { $$ = $2 }
@@ -375,6 +435,11 @@ ipLabelFilter:
   | IDENTIFIER NEQ IP OPEN_PARENTHESIS STRING CLOSE_PARENTHESIS { $$ = log.NewIPLabelFilter($5, $1, log.LabelFilterNotEqual) }
   ;
 
+wordLabelFilter:
+    IDENTIFIER EQ WORD OPEN_PARENTHESIS STRING CLOSE_PARENTHESIS  { $$ = log.NewWordLabelFilter($5, $1, log.LabelFilterEqual) }
+  | IDENTIFIER NEQ WORD OPEN_PARENTHESIS STRING CLOSE_PARENTHESIS { $$ = log.NewWordLabelFilter($5, $1, log.LabelFilterNotEqual) }
+  ;
+
 unitFilter:
       durationFilter { $$ = $1 }
     | bytesFilter    { $$ = $1 }
@@ -563,10 +628,23 @@ rangeOp:
     | FIRST_OVER_TIME    { $$ = OpRangeTypeFirst }
     | LAST_OVER_TIME     { $$ = OpRangeTypeLast }
     | ABSENT_OVER_TIME   { $$ = OpRangeTypeAbsent }
+    | HISTOGRAM_OVER_TIME { $$ = OpRangeTypeHistogram }
+    | CHANGES_OVER_TIME  { $$ = OpRangeTypeChanges }
+    | DISTINCT_OVER_TIME { $$ = OpRangeTypeDistinct }
     ;
 
 offsetExpr:
-    OFFSET DURATION { $$ = newOffsetExpr( $2 ) }
+      OFFSET DURATION               { $$ = newOffsetExpr( $2 ) }
+    | atExpr                        { $$ = $1 }
+    | OFFSET DURATION atExpr        { $$ = $3.withOffset( $2 ) }
+    | atExpr OFFSET DURATION        { $$ = $1.withOffset( $3 ) }
+    ;
+
+atExpr:
+      AT NUMBER                                       { $$ = newAtExpr( $2 ) }
+    | AT START OPEN_PARENTHESIS CLOSE_PARENTHESIS     { $$ = newAtStartOrEndExpr( OpAtStart ) }
+    | AT END OPEN_PARENTHESIS CLOSE_PARENTHESIS       { $$ = newAtStartOrEndExpr( OpAtEnd ) }
+    ;
 
 labels:
       IDENTIFIER                 { $$ = []string{ $1 } }
@@ -40,6 +40,7 @@ type exprSymType struct {
 	bytes                 uint64
 	str                   string
 	duration              time.Duration
+	subqueryRange         subqueryRange
 	LiteralExpr           *LiteralExpr
 	BinOpModifier         *BinOpOptions
 	BoolModifier          *BinOpOptions
@@ -67,6 +68,10 @@ type exprSymType struct {
 	LabelExtractionExpressionList []log.LabelExtractionExpr
 	JSONExpressionParser          *JSONExpressionParser
 	LogfmtExpressionParser        *LogfmtExpressionParser
+	XMLExpressionParser           *XMLExpressionParser
+	CSVParser                     *CSVParserExpr
+	ParserOption                  parserOption
+	ParserOptions                 []parserOption
 
 	UnwrapExpr     *UnwrapExpr
 	DecolorizeExpr *DecolorizeExpr
@@ -86,90 +91,106 @@ const NUMBER = 57349
 const PARSER_FLAG = 57350
 const DURATION = 57351
 const RANGE = 57352
-const MATCHERS = 57353
-const LABELS = 57354
-const EQ = 57355
-const RE = 57356
-const NRE = 57357
-const NPA = 57358
-const OPEN_BRACE = 57359
-const CLOSE_BRACE = 57360
-const OPEN_BRACKET = 57361
-const CLOSE_BRACKET = 57362
-const COMMA = 57363
-const DOT = 57364
-const PIPE_MATCH = 57365
-const PIPE_EXACT = 57366
-const PIPE_PATTERN = 57367
-const OPEN_PARENTHESIS = 57368
-const CLOSE_PARENTHESIS = 57369
-const BY = 57370
-const WITHOUT = 57371
-const COUNT_OVER_TIME = 57372
-const RATE = 57373
-const RATE_COUNTER = 57374
-const SUM = 57375
-const SORT = 57376
-const SORT_DESC = 57377
-const AVG = 57378
-const MAX = 57379
-const MIN = 57380
-const COUNT = 57381
-const STDDEV = 57382
-const STDVAR = 57383
-const BOTTOMK = 57384
-const TOPK = 57385
-const BYTES_OVER_TIME = 57386
-const BYTES_RATE = 57387
-const BOOL = 57388
-const JSON = 57389
-const REGEXP = 57390
-const LOGFMT = 57391
-const PIPE = 57392
-const LINE_FMT = 57393
-const LABEL_FMT = 57394
-const UNWRAP = 57395
-const AVG_OVER_TIME = 57396
-const SUM_OVER_TIME = 57397
-const MIN_OVER_TIME = 57398
-const MAX_OVER_TIME = 57399
-const STDVAR_OVER_TIME = 57400
-const STDDEV_OVER_TIME = 57401
-const QUANTILE_OVER_TIME = 57402
-const BYTES_CONV = 57403
-const DURATION_CONV = 57404
-const DURATION_SECONDS_CONV = 57405
-const FIRST_OVER_TIME = 57406
-const LAST_OVER_TIME = 57407
-const ABSENT_OVER_TIME = 57408
-const VECTOR = 57409
-const LABEL_REPLACE = 57410
-const UNPACK = 57411
-const OFFSET = 57412
-const PATTERN = 57413
-const IP = 57414
-const ON = 57415
-const IGNORING = 57416
-const GROUP_LEFT = 57417
-const GROUP_RIGHT = 57418
-const DECOLORIZE = 57419
-const DROP = 57420
-const KEEP = 57421
-const OR = 57422
-const AND = 57423
-const UNLESS = 57424
-const CMP_EQ = 57425
-const NEQ = 57426
-const LT = 57427
-const LTE = 57428
-const GT = 57429
-const GTE = 57430
-const ADD = 57431
-const SUB = 57432
-const MUL = 57433
-const DIV = 57434
-const MOD = 57435
-const POW = 57436
+const SUBQUERY_RANGE = 57353
+const MATCHERS = 57354
+const LABELS = 57355
+const EQ = 57356
+const RE = 57357
+const NRE = 57358
+const NPA = 57359
+const OPEN_BRACE = 57360
+const CLOSE_BRACE = 57361
+const OPEN_BRACKET = 57362
+const CLOSE_BRACKET = 57363
+const COMMA = 57364
+const DOT = 57365
+const PIPE_MATCH = 57366
+const PIPE_EXACT = 57367
+const PIPE_PATTERN = 57368
+const OPEN_PARENTHESIS = 57369
+const CLOSE_PARENTHESIS = 57370
+const BY = 57371
+const WITHOUT = 57372
+const COUNT_OVER_TIME = 57373
+const RATE = 57374
+const RATE_COUNTER = 57375
+const SUM = 57376
+const SORT = 57377
+const SORT_DESC = 57378
+const AVG = 57379
+const MAX = 57380
+const MIN = 57381
+const COUNT = 57382
+const STDDEV = 57383
+const STDVAR = 57384
+const BOTTOMK = 57385
+const TOPK = 57386
+const BYTES_OVER_TIME = 57387
+const BYTES_RATE = 57388
+const BOOL = 57389
+const JSON = 57390
+const REGEXP = 57391
+const LOGFMT = 57392
+const PIPE = 57393
+const LINE_FMT = 57394
+const LABEL_FMT = 57395
+const UNWRAP = 57396
+const AVG_OVER_TIME = 57397
+const SUM_OVER_TIME = 57398
+const MIN_OVER_TIME = 57399
+const MAX_OVER_TIME = 57400
+const STDVAR_OVER_TIME = 57401
+const STDDEV_OVER_TIME = 57402
+const QUANTILE_OVER_TIME = 57403
+const BYTES_CONV = 57404
+const DURATION_CONV = 57405
+const DURATION_SECONDS_CONV = 57406
+const LABEL_CONV = 57407
+const FIRST_OVER_TIME = 57408
+const LAST_OVER_TIME = 57409
+const ABSENT_OVER_TIME = 57410
+const VECTOR = 57411
+const LABEL_REPLACE = 57412
+const UNPACK = 57413
+const OFFSET = 57414
+const PATTERN = 57415
+const IP = 57416
+const ON = 57417
+const IGNORING = 57418
+const GROUP_LEFT = 57419
+const GROUP_RIGHT = 57420
+const DECOLORIZE = 57421
+const DROP = 57422
+const KEEP = 57423
+const CSV = 57424
+const XML = 57425
+const HISTOGRAM_OVER_TIME = 57426
+const HISTOGRAM_QUANTILE = 57427
+const AT = 57428
+const START = 57429
+const END = 57430
+const CHANGES_OVER_TIME = 57431
+const DISTINCT_OVER_TIME = 57432
+const COUNT_VALUES = 57433
+const JOIN = 57434
+const WITHIN = 57435
+const WORD = 57436
+const SAMPLE = 57437
+const OR = 57438
+const AND = 57439
+const UNLESS = 57440
+const CMP_EQ = 57441
+const NEQ = 57442
+const LT = 57443
+const LTE = 57444
+const GT = 57445
+const GTE = 57446
+const ADD = 57447
+const SUB = 57448
+const MUL = 57449
+const DIV = 57450
+const MOD = 57451
+const POW = 57452
 
 var exprToknames = [...]string{
 	"$end",
@@ -182,6 +203,7 @@ var exprToknames = [...]string{
 	"PARSER_FLAG",
 	"DURATION",
 	"RANGE",
+	"SUBQUERY_RANGE",
 	"MATCHERS",
 	"LABELS",
 	"EQ",
@@ -235,6 +257,7 @@ var exprToknames = [...]string{
 	"BYTES_CONV",
 	"DURATION_CONV",
 	"DURATION_SECONDS_CONV",
+	"LABEL_CONV",
 	"FIRST_OVER_TIME",
 	"LAST_OVER_TIME",
 	"ABSENT_OVER_TIME",
@@ -251,6 +274,20 @@ var exprToknames = [...]string{
 	"DECOLORIZE",
 	"DROP",
 	"KEEP",
+	"CSV",
+	"XML",
+	"HISTOGRAM_OVER_TIME",
+	"HISTOGRAM_QUANTILE",
+	"AT",
+	"START",
+	"END",
+	"CHANGES_OVER_TIME",
+	"DISTINCT_OVER_TIME",
+	"COUNT_VALUES",
+	"JOIN",
+	"WITHIN",
+	"WORD",
+	"SAMPLE",
 	"OR",
 	"AND",
 	"UNLESS",
@@ -274,7 +311,7 @@ const exprEofCode = 1
 const exprErrCode = 2
 const exprInitialStackSize = 16
 
-//line expr.y:582
+//line expr.y:660
 
 //line yacctab:1
 var exprExca = [...]int8{
@@ -285,166 +322,221 @@ var exprExca = [...]int8{
 
 const exprPrivate = 57344
 
-const exprLast = 639
+const exprLast = 1001
 
 var exprAct = [...]int16{
-	289, 228, 84, 4, 214, 64, 182, 126, 204, 189,
-	75, 200, 197, 63, 237, 5, 152, 187, 77, 2,
-	56, 80, 48, 49, 50, 57, 58, 61, 62, 59,
-	60, 51, 52, 53, 54, 55, 56, 283, 10, 49,
-	50, 57, 58, 61, 62, 59, 60, 51, 52, 53,
-	54, 55, 56, 57, 58, 61, 62, 59, 60, 51,
-	52, 53, 54, 55, 56, 53, 54, 55, 56, 109,
-	217, 139, 215, 115, 51, 52, 53, 54, 55, 56,
-	266, 140, 221, 16, 216, 265, 292, 156, 136, 166,
-	167, 297, 262, 161, 220, 16, 294, 261, 154, 148,
-	150, 151, 366, 281, 184, 339, 16, 67, 280, 130,
-	163, 207, 150, 151, 168, 169, 170, 171, 172, 173,
-	174, 175, 176, 177, 178, 179, 180, 181, 278, 164,
-	165, 16, 366, 277, 239, 94, 293, 194, 142, 386,
-	191, 275, 202, 206, 16, 294, 274, 142, 264, 340,
-	85, 86, 292, 141, 272, 219, 316, 16, 381, 271,
-	260, 306, 235, 185, 183, 17, 18, 356, 229, 224,
-	149, 231, 232, 110, 306, 240, 294, 17, 18, 136,
-	355, 213, 208, 211, 212, 209, 210, 374, 17, 18,
-	248, 249, 250, 269, 331, 184, 16, 306, 268, 373,
-	130, 255, 306, 354, 252, 342, 343, 344, 353, 136,
-	239, 72, 74, 17, 18, 371, 363, 339, 306, 69,
-	70, 71, 285, 384, 308, 184, 17, 18, 287, 290,
-	130, 296, 314, 299, 369, 109, 302, 115, 303, 17,
-	18, 291, 154, 288, 359, 300, 263, 267, 270, 273,
-	276, 279, 282, 349, 185, 183, 330, 294, 293, 310,
-	312, 315, 317, 318, 72, 74, 202, 206, 325, 320,
-	324, 295, 69, 70, 71, 346, 72, 74, 17, 18,
-	73, 306, 239, 239, 69, 70, 71, 307, 328, 136,
-	239, 332, 304, 334, 336, 224, 338, 109, 294, 230,
-	243, 337, 348, 333, 313, 311, 109, 239, 295, 350,
-	130, 230, 241, 72, 74, 83, 224, 85, 86, 292,
-	301, 69, 70, 71, 233, 347, 144, 13, 136, 238,
-	143, 327, 153, 73, 360, 361, 155, 326, 284, 109,
-	362, 225, 13, 247, 184, 73, 364, 365, 230, 130,
-	246, 155, 370, 245, 244, 218, 160, 159, 158, 227,
-	16, 90, 89, 82, 72, 74, 376, 380, 377, 378,
-	13, 352, 69, 70, 71, 253, 298, 305, 259, 6,
-	382, 258, 73, 21, 22, 23, 36, 45, 46, 37,
-	39, 40, 38, 41, 42, 43, 44, 24, 25, 230,
-	256, 242, 234, 226, 183, 257, 254, 26, 27, 28,
-	29, 30, 31, 32, 81, 379, 368, 33, 34, 35,
-	47, 19, 236, 227, 367, 162, 345, 79, 72, 74,
-	335, 190, 13, 73, 251, 88, 69, 70, 71, 322,
-	323, 6, 17, 18, 87, 21, 22, 23, 36, 45,
-	46, 37, 39, 40, 38, 41, 42, 43, 44, 24,
-	25, 190, 375, 230, 188, 385, 383, 372, 358, 26,
-	27, 28, 29, 30, 31, 32, 146, 357, 3, 33,
-	34, 35, 47, 19, 157, 76, 329, 319, 309, 286,
-	72, 74, 145, 321, 13, 147, 198, 73, 69, 70,
-	71, 223, 222, 6, 17, 18, 136, 21, 22, 23,
-	36, 45, 46, 37, 39, 40, 38, 41, 42, 43,
-	44, 24, 25, 221, 220, 230, 195, 130, 193, 192,
-	351, 26, 27, 28, 29, 30, 31, 32, 205, 136,
-	201, 33, 34, 35, 47, 19, 190, 81, 122, 123,
-	121, 198, 131, 133, 297, 72, 74, 127, 128, 73,
-	130, 113, 114, 69, 70, 71, 17, 18, 196, 118,
-	124, 203, 125, 120, 199, 91, 119, 117, 132, 134,
-	135, 122, 123, 121, 116, 131, 133, 186, 65, 137,
-	66, 129, 138, 111, 112, 93, 92, 11, 9, 20,
-	12, 15, 8, 124, 341, 125, 14, 7, 78, 68,
-	1, 132, 134, 135, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 73, 95, 96, 97, 98, 99,
-	100, 101, 102, 103, 104, 105, 106, 107, 108,
+	3, 336, 264, 91, 338, 72, 271, 83, 247, 237,
+	208, 143, 215, 233, 217, 225, 70, 230, 4, 172,
+	63, 326, 95, 5, 19, 82, 325, 87, 328, 84,
+	2, 55, 56, 57, 64, 65, 68, 69, 66, 67,
+	58, 59, 60, 61, 62, 63, 64, 65, 68, 69,
+	66, 67, 58, 59, 60, 61, 62, 63, 10, 56,
+	57, 64, 65, 68, 69, 66, 67, 58, 59, 60,
+	61, 62, 63, 250, 154, 158, 120, 60, 61, 62,
+	63, 248, 128, 58, 59, 60, 61, 62, 63, 471,
+	457, 210, 311, 159, 254, 19, 148, 310, 337, 339,
+	306, 343, 253, 19, 397, 305, 190, 191, 174, 177,
+	188, 189, 339, 175, 285, 249, 207, 184, 240, 170,
+	171, 396, 20, 21, 323, 349, 104, 19, 449, 322,
+	128, 187, 168, 170, 171, 192, 193, 194, 195, 196,
+	197, 198, 199, 200, 201, 202, 203, 204, 205, 161,
+	219, 449, 92, 93, 222, 436, 74, 358, 483, 227,
+	164, 161, 308, 456, 235, 239, 209, 154, 320, 162,
+	303, 19, 337, 319, 473, 317, 464, 83, 19, 252,
+	316, 162, 309, 401, 398, 399, 339, 348, 274, 148,
+	304, 160, 261, 20, 21, 82, 266, 257, 269, 337,
+	265, 20, 21, 246, 241, 244, 245, 242, 243, 463,
+	137, 138, 136, 339, 149, 151, 343, 335, 169, 401,
+	287, 288, 289, 392, 349, 20, 21, 291, 349, 314,
+	157, 348, 19, 139, 313, 140, 294, 446, 15, 462,
+	295, 150, 152, 153, 142, 141, 461, 478, 135, 416,
+	94, 273, 92, 93, 206, 460, 459, 134, 273, 330,
+	349, 337, 332, 458, 342, 344, 345, 120, 352, 20,
+	21, 354, 349, 128, 371, 339, 20, 21, 334, 333,
+	346, 369, 350, 175, 454, 273, 358, 355, 365, 367,
+	370, 372, 429, 374, 154, 361, 257, 154, 364, 307,
+	312, 315, 318, 321, 324, 327, 154, 375, 368, 377,
+	402, 210, 384, 235, 239, 383, 148, 379, 341, 148,
+	358, 358, 353, 210, 79, 81, 427, 426, 148, 298,
+	20, 21, 76, 77, 78, 452, 413, 389, 257, 273,
+	137, 138, 136, 400, 149, 151, 343, 408, 358, 410,
+	411, 128, 120, 414, 425, 434, 120, 423, 128, 340,
+	412, 409, 366, 139, 258, 140, 420, 404, 405, 406,
+	407, 150, 152, 153, 142, 141, 418, 358, 135, 417,
+	428, 421, 154, 424, 121, 211, 209, 134, 90, 443,
+	92, 93, 394, 391, 356, 437, 435, 211, 209, 210,
+	438, 79, 81, 442, 148, 19, 154, 280, 80, 76,
+	77, 78, 444, 358, 358, 120, 15, 447, 448, 360,
+	359, 451, 273, 273, 453, 6, 479, 267, 148, 25,
+	26, 27, 43, 52, 53, 44, 46, 47, 45, 48,
+	49, 50, 51, 28, 29, 275, 272, 466, 163, 441,
+	468, 440, 469, 30, 31, 32, 33, 34, 35, 36,
+	388, 387, 79, 81, 37, 38, 39, 54, 22, 474,
+	76, 77, 78, 477, 386, 385, 373, 480, 329, 481,
+	286, 19, 40, 23, 284, 80, 283, 41, 42, 18,
+	282, 281, 15, 251, 183, 181, 180, 347, 179, 100,
+	99, 176, 98, 20, 21, 25, 26, 27, 43, 52,
+	53, 44, 46, 47, 45, 48, 49, 50, 51, 28,
+	29, 89, 470, 292, 422, 362, 357, 302, 166, 30,
+	31, 32, 33, 34, 35, 36, 301, 299, 279, 278,
+	37, 38, 39, 54, 22, 165, 80, 276, 167, 268,
+	259, 300, 88, 297, 293, 393, 260, 270, 40, 23,
+	212, 467, 450, 41, 42, 18, 86, 445, 15, 415,
+	476, 472, 439, 395, 296, 218, 226, 6, 290, 20,
+	21, 25, 26, 27, 43, 52, 53, 44, 46, 47,
+	45, 48, 49, 50, 51, 28, 29, 223, 218, 226,
+	482, 216, 186, 381, 382, 30, 31, 32, 33, 34,
+	35, 36, 185, 79, 81, 97, 37, 38, 39, 54,
+	22, 76, 77, 78, 96, 475, 455, 433, 432, 431,
+	430, 390, 465, 178, 40, 23, 378, 376, 363, 41,
+	42, 18, 380, 331, 15, 231, 144, 277, 119, 256,
+	255, 254, 253, 6, 228, 20, 21, 25, 26, 27,
+	43, 52, 53, 44, 46, 47, 45, 48, 49, 50,
+	51, 28, 29, 221, 220, 182, 273, 419, 238, 234,
+	218, 30, 31, 32, 33, 34, 35, 36, 88, 231,
+	213, 146, 37, 38, 39, 54, 22, 80, 224, 127,
+	126, 124, 125, 229, 131, 236, 133, 232, 132, 173,
+	40, 23, 130, 129, 214, 41, 42, 18, 73, 145,
+	15, 155, 147, 156, 122, 123, 103, 102, 13, 176,
+	12, 20, 21, 25, 26, 27, 43, 52, 53, 44,
+	46, 47, 45, 48, 49, 50, 51, 28, 29, 11,
+	9, 154, 24, 14, 17, 8, 403, 30, 31, 32,
+	33, 34, 35, 36, 16, 7, 79, 81, 37, 38,
+	39, 54, 22, 148, 76, 77, 78, 85, 75, 1,
+	0, 0, 0, 0, 0, 0, 40, 23, 0, 0,
+	154, 41, 42, 18, 137, 138, 136, 0, 149, 151,
+	343, 347, 0, 0, 0, 79, 81, 20, 21, 0,
+	0, 0, 148, 76, 77, 78, 0, 139, 0, 140,
+	0, 0, 337, 0, 0, 150, 152, 153, 142, 141,
+	0, 154, 135, 137, 138, 136, 339, 149, 151, 0,
+	71, 134, 0, 0, 0, 263, 0, 0, 0, 0,
+	80, 79, 81, 148, 0, 0, 139, 0, 140, 76,
+	77, 78, 0, 351, 150, 152, 153, 142, 141, 0,
+	0, 135, 0, 0, 137, 138, 136, 206, 149, 151,
+	134, 0, 0, 0, 0, 0, 262, 341, 0, 80,
+	0, 0, 0, 79, 81, 101, 0, 139, 0, 140,
+	0, 76, 77, 78, 0, 150, 152, 153, 142, 141,
+	263, 0, 135, 0, 0, 0, 79, 81, 121, 0,
+	0, 134, 0, 0, 76, 77, 78, 0, 340, 0,
+	0, 0, 0, 0, 0, 80, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 262, 105, 106, 107, 108, 109, 110, 111, 112,
+	113, 114, 115, 116, 117, 118, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 80, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	80,
 }
 
 var exprPact = [...]int16{
-	353, -1000, -58, -1000, -1000, 540, 353, -1000, -1000, -1000,
-	-1000, -1000, -1000, 409, 337, 289, -1000, 437, 428, 336,
-	335, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	398, -1000, -65, -1000, -1000, 789, 398, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, 547, 494, 361, 223, -1000,
+	617, 608, 475, 473, 472, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 89, 89,
-	89, 89, 89, 89, 89, 89, 89, 89, 89, 89,
-	89, 89, 89, 540, -1000, 196, 534, -9, 75, -1000,
-	-1000, -1000, -1000, -1000, -1000, 303, 299, -58, 474, -1000,
-	-1000, 86, 325, 477, 332, 331, 330, -1000, -1000, 353,
-	418, 353, 56, 14, -1000, 353, 353, 353, 353, 353,
-	353, 353, 353, 353, 353, 353, 353, 353, 353, -1000,
-	-1000, -1000, -1000, -1000, -1000, 83, -1000, -1000, -1000, -1000,
-	-1000, 456, 541, 523, -1000, 522, -1000, -1000, -1000, -1000,
-	284, 520, -1000, 546, 535, 533, 98, -1000, -1000, 66,
-	-10, 329, -1000, -1000, -1000, -1000, -1000, 542, 518, 517,
-	496, 495, 314, 382, 413, 310, 297, 381, 415, 302,
-	285, 380, 273, -42, 328, 327, 324, 317, -30, -30,
-	-26, -26, -74, -74, -74, -74, -15, -15, -15, -15,
-	-15, -15, 83, 284, 284, 284, 426, 354, -1000, -1000,
-	393, 354, -1000, -1000, 174, -1000, 379, -1000, 392, 360,
-	-1000, 86, -1000, 357, -1000, 86, -1000, 88, 76, 189,
-	150, 137, 124, 99, -1000, -43, 312, 66, 483, -1000,
-	-1000, -1000, -1000, -1000, -1000, 122, 310, 249, 126, 261,
-	501, 349, 293, 122, 353, 265, 356, 260, -1000, -1000,
-	197, -1000, 482, -1000, 278, 277, 205, 129, 204, 83,
-	323, -1000, 354, 541, 481, -1000, 491, 434, 535, 533,
-	311, -1000, -1000, -1000, 305, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, 66, 480, -1000, 229, -1000, 167, 475,
-	46, 475, 421, 16, 284, 16, 95, 144, 416, 248,
-	298, -1000, -1000, 226, -1000, 353, 525, -1000, -1000, 350,
-	181, -1000, 176, -1000, -1000, 153, -1000, 140, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, 471, 462, -1000, 217,
-	-1000, 122, 46, 475, 46, -1000, -1000, 83, -1000, 16,
-	-1000, 190, -1000, -1000, -1000, 82, 414, 406, 207, 122,
-	188, -1000, 461, -1000, -1000, -1000, -1000, 172, 160, -1000,
-	-1000, 46, -1000, 457, 52, 46, 38, 16, 16, 405,
-	-1000, -1000, 346, -1000, -1000, 131, 46, -1000, -1000, 16,
-	460, -1000, -1000, 202, 459, 112, -1000,
+	-1000, -1000, -1000, -1000, -1000, 79, 79, 79, 79, 79,
+	79, 79, 79, 79, 79, 79, 79, 79, 79, 79,
+	597, 826, -1000, 385, -21, 87, -1000, -1000, -1000, -1000,
+	-1000, -1000, 420, 132, -65, 526, -1000, -1000, 118, 702,
+	626, 471, 469, 468, 669, 467, -1000, -1000, 398, 605,
+	595, 398, 35, 29, -1000, 398, 398, 398, 398, 398,
+	398, 398, 398, 398, 398, 398, 398, 398, 398, 785,
+	-1000, 41, -1000, -1000, -1000, -1000, -1000, -1000, 289, -1000,
+	-1000, -1000, -1000, -1000, 553, 685, 593, 675, 668, -1000,
+	667, 675, 591, -1000, -1000, -1000, -1000, -1000, 401, 648,
+	-1000, 684, 674, 673, 104, -1000, -1000, -1000, 75, -23,
+	466, -1000, -1000, -1000, -1000, -1000, -1000, 683, 646, 645,
+	644, 643, 336, 528, 545, 900, 474, 399, 527, 550,
+	418, 417, 525, 641, 517, 516, 379, -38, 464, 463,
+	459, 457, -53, -53, -30, -30, -90, -90, -90, -90,
+	-22, -22, -22, -22, -22, -22, 39, 453, 289, 401,
+	401, 401, -1000, -1000, 570, 501, -1000, -1000, 540, 501,
+	-1000, -1000, 501, 675, 568, -1000, 539, 301, -1000, 515,
+	-1000, 537, 514, -1000, 118, -1000, 505, -1000, 118, -1000,
+	96, 88, 225, 171, 164, 120, 17, -1000, -68, 451,
+	75, 637, -1000, -1000, -1000, -1000, -1000, -1000, 123, 474,
+	189, 877, 292, 750, 177, 835, 294, 123, 398, 366,
+	504, 392, -1000, -1000, 391, -1000, 398, 503, 632, 398,
+	-1000, 334, 280, 253, 246, 449, 671, 377, 289, 69,
+	-1000, 501, 675, 631, 501, -1000, 675, 630, -1000, 640,
+	598, 674, 673, 448, 447, -1000, -1000, -1000, 434, 433,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 75, 625,
+	-1000, 365, -1000, 195, 544, -1000, 364, 564, 49, 97,
+	162, 26, 173, 305, 446, 74, 446, 746, 26, 401,
+	308, 559, 221, -1000, -1000, 351, -1000, 398, 672, -1000,
+	-1000, 338, 398, 502, 329, 355, -1000, 326, -1000, -1000,
+	299, -1000, 298, 671, 264, -1000, -1000, 501, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, 624, 623, 622, 621, -1000,
+	327, -1000, 123, 127, -1000, 13, 563, -1000, 424, 422,
+	-1000, 26, -1000, 362, -1000, -1000, -1000, -1000, 74, 446,
+	74, -1000, 289, 557, 209, 100, 552, 123, 307, -1000,
+	123, 256, 620, -1000, -1000, -1000, -1000, -1000, 135, -3,
+	235, 228, 227, 218, -1000, -1000, -1000, 211, -1000, -1000,
+	181, 148, -1000, 627, 74, 26, 551, 77, 74, 47,
+	26, -1000, -1000, -1000, -1000, 500, -4, 562, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, 146, -1000, 26, 74, -1000,
+	619, 561, 220, -1000, -1000, 404, 220, -1000, 220, 594,
+	-1000, 132, 130, -1000,
 }
 
 var exprPgo = [...]int16{
-	0, 610, 18, 609, 2, 14, 478, 3, 16, 7,
-	608, 607, 606, 604, 15, 602, 601, 600, 599, 84,
-	598, 38, 597, 575, 596, 595, 594, 593, 13, 5,
-	592, 591, 589, 6, 588, 107, 4, 587, 584, 577,
-	576, 574, 11, 573, 571, 8, 569, 12, 568, 9,
-	17, 562, 561, 1, 558, 557, 0,
+	0, 779, 29, 778, 3, 6, 0, 18, 19, 11,
+	777, 765, 764, 756, 23, 755, 754, 753, 752, 115,
+	750, 58, 749, 730, 728, 895, 727, 726, 725, 724,
+	16, 5, 723, 722, 721, 10, 719, 718, 156, 8,
+	714, 713, 712, 708, 707, 13, 706, 705, 9, 704,
+	17, 703, 14, 12, 702, 701, 700, 699, 15, 698,
+	2, 691, 646, 1, 4,
 }
 
 var exprR1 = [...]int8{
 	0, 1, 2, 2, 7, 7, 7, 7, 7, 7,
-	7, 6, 6, 6, 8, 8, 8, 8, 8, 8,
+	7, 7, 7, 6, 6, 6, 6, 6, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
-	53, 53, 53, 13, 13, 13, 11, 11, 11, 11,
-	15, 15, 15, 15, 15, 15, 22, 3, 3, 3,
-	3, 3, 3, 14, 14, 14, 10, 10, 9, 9,
-	9, 9, 28, 28, 29, 29, 29, 29, 29, 29,
-	29, 29, 29, 29, 29, 19, 36, 36, 36, 35,
-	35, 35, 34, 34, 34, 37, 37, 27, 27, 26,
-	26, 26, 26, 52, 51, 51, 38, 39, 47, 47,
-	48, 48, 48, 46, 33, 33, 33, 33, 33, 33,
-	33, 33, 33, 49, 49, 50, 50, 55, 55, 54,
-	54, 32, 32, 32, 32, 32, 32, 32, 30, 30,
-	30, 30, 30, 30, 30, 31, 31, 31, 31, 31,
-	31, 31, 42, 42, 41, 41, 40, 45, 45, 44,
-	44, 43, 20, 20, 20, 20, 20, 20, 20, 20,
-	20, 20, 20, 20, 20, 20, 20, 24, 24, 25,
-	25, 25, 25, 23, 23, 23, 23, 23, 23, 23,
-	23, 21, 21, 21, 17, 18, 16, 16, 16, 16,
+	8, 8, 8, 8, 60, 60, 60, 13, 13, 13,
+	13, 11, 11, 11, 11, 23, 23, 23, 23, 15,
+	15, 15, 15, 15, 15, 15, 15, 15, 22, 24,
+	3, 3, 3, 3, 3, 3, 14, 14, 14, 10,
+	10, 9, 9, 9, 9, 30, 30, 31, 31, 31,
+	31, 31, 31, 31, 31, 31, 31, 31, 31, 31,
+	31, 31, 19, 19, 39, 39, 39, 38, 38, 38,
+	37, 37, 37, 40, 40, 29, 29, 28, 28, 28,
+	28, 28, 55, 54, 54, 56, 58, 59, 59, 57,
+	57, 57, 57, 41, 42, 50, 50, 51, 51, 51,
+	49, 35, 35, 35, 35, 35, 35, 35, 35, 35,
+	35, 52, 52, 53, 53, 62, 62, 36, 36, 61,
+	61, 34, 34, 34, 34, 34, 34, 34, 32, 32,
+	32, 32, 32, 32, 32, 33, 33, 33, 33, 33,
+	33, 33, 45, 45, 44, 44, 43, 48, 48, 47,
+	47, 46, 20, 20, 20, 20, 20, 20, 20, 20,
+	20, 20, 20, 20, 20, 20, 20, 26, 26, 27,
+	27, 27, 27, 25, 25, 25, 25, 25, 25, 25,
+	25, 21, 21, 21, 17, 18, 16, 16, 16, 16,
 	16, 16, 16, 16, 16, 16, 16, 12, 12, 12,
 	12, 12, 12, 12, 12, 12, 12, 12, 12, 12,
-	12, 12, 56, 5, 5, 4, 4, 4, 4,
+	12, 12, 12, 12, 12, 63, 63, 63, 63, 64,
+	64, 64, 5, 5, 4, 4, 4, 4,
 }
 
 var exprR2 = [...]int8{
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	3, 1, 2, 3, 2, 3, 4, 5, 3, 4,
-	5, 6, 3, 4, 5, 6, 3, 4, 5, 6,
-	4, 5, 6, 7, 3, 4, 4, 5, 3, 2,
-	3, 6, 3, 1, 1, 1, 4, 6, 5, 7,
-	4, 5, 5, 6, 7, 7, 12, 1, 1, 1,
-	1, 1, 1, 3, 3, 2, 1, 3, 3, 3,
-	3, 3, 1, 2, 1, 2, 2, 2, 2, 2,
-	2, 2, 2, 2, 2, 1, 1, 4, 3, 2,
-	5, 4, 1, 3, 2, 1, 2, 1, 2, 1,
-	2, 1, 2, 2, 3, 2, 2, 1, 3, 3,
-	1, 3, 3, 2, 1, 1, 1, 1, 3, 2,
-	3, 3, 3, 3, 1, 1, 3, 6, 6, 1,
+	1, 1, 3, 1, 2, 3, 10, 11, 2, 3,
+	4, 5, 3, 4, 5, 6, 3, 4, 5, 6,
+	3, 4, 5, 6, 4, 5, 6, 7, 3, 4,
+	4, 5, 3, 2, 3, 6, 3, 1, 1, 1,
+	1, 4, 6, 5, 7, 5, 6, 7, 8, 4,
+	5, 5, 6, 7, 7, 6, 7, 7, 12, 6,
+	1, 1, 1, 1, 1, 1, 3, 3, 2, 1,
+	3, 3, 3, 3, 3, 1, 2, 1, 2, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2,
+	3, 3, 1, 1, 1, 4, 3, 2, 5, 4,
+	1, 3, 2, 1, 2, 1, 2, 1, 2, 1,
+	2, 1, 2, 3, 2, 2, 3, 1, 2, 2,
+	3, 3, 4, 2, 1, 3, 3, 1, 3, 3,
+	2, 1, 1, 1, 1, 1, 3, 2, 3, 3,
+	3, 3, 1, 1, 3, 6, 6, 6, 6, 1,
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 1, 1, 1, 3, 2, 1, 1, 1,
@@ -454,91 +546,112 @@ var exprR2 = [...]int8{
 	5, 1, 2, 2, 4, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 2, 1, 3, 4, 4, 3, 3,
+	1, 1, 1, 1, 1, 2, 1, 3, 3, 2,
+	4, 4, 1, 3, 4, 4, 3, 3,
 }
 
 var exprChk = [...]int16{
-	-1000, -1, -2, -6, -7, -14, 26, -11, -15, -20,
-	-21, -22, -17, 17, -12, -16, 7, 89, 90, 68,
-	-18, 30, 31, 32, 44, 45, 54, 55, 56, 57,
-	58, 59, 60, 64, 65, 66, 33, 36, 39, 37,
-	38, 40, 41, 42, 43, 34, 35, 67, 80, 81,
-	82, 89, 90, 91, 92, 93, 94, 83, 84, 87,
-	88, 85, 86, -28, -29, -34, 50, -35, -3, 23,
-	24, 25, 15, 84, 16, -7, -6, -2, -10, 18,
-	-9, 5, 26, 26, -4, 28, 29, 7, 7, 26,
-	26, -23, -24, -25, 46, -23, -23, -23, -23, -23,
-	-23, -23, -23, -23, -23, -23, -23, -23, -23, -29,
-	-35, -27, -26, -52, -51, -33, -38, -39, -46, -40,
-	-43, 49, 47, 48, 69, 71, -9, -55, -54, -31,
-	26, 51, 77, 52, 78, 79, 5, -32, -30, 80,
-	6, -19, 72, 27, 27, 18, 2, 21, 13, 84,
-	14, 15, -8, 7, -14, 26, -7, 7, 26, 26,
-	26, -7, 7, -2, 73, 74, 75, 76, -2, -2,
-	-2, -2, -2, -2, -2, -2, -2, -2, -2, -2,
-	-2, -2, -33, 81, 21, 80, -37, -50, 8, -49,
-	5, -50, 6, 6, -33, 6, -48, -47, 5, -41,
-	-42, 5, -9, -44, -45, 5, -9, 13, 84, 87,
-	88, 85, 86, 83, -36, 6, -19, 80, 26, -9,
-	6, 6, 6, 6, 2, 27, 21, 10, -53, -28,
-	50, -14, -8, 27, 21, -7, 7, -5, 27, 5,
-	-5, 27, 21, 27, 26, 26, 26, 26, -33, -33,
-	-33, 8, -50, 21, 13, 27, 21, 13, 21, 21,
-	72, 9, 4, -21, 72, 9, 4, -21, 9, 4,
-	-21, 9, 4, -21, 9, 4, -21, 9, 4, -21,
-	9, 4, -21, 80, 26, -36, 6, -4, -8, -56,
-	-53, -28, 70, 10, 50, 10, -53, 53, 27, -53,
-	-28, 27, -4, -7, 27, 21, 21, 27, 27, 6,
-	-5, 27, -5, 27, 27, -5, 27, -5, -49, 6,
-	-47, 2, 5, 6, -42, -45, 26, 26, -36, 6,
-	27, 27, -53, -28, -53, 9, -56, -33, -56, 10,
-	5, -13, 61, 62, 63, 10, 27, 27, -53, 27,
-	-7, 5, 21, 27, 27, 27, 27, 6, 6, 27,
-	-4, -53, -56, 26, -56, -53, 50, 10, 10, 27,
-	-4, 27, 6, 27, 27, 5, -53, -56, -56, 10,
-	21, 27, -56, 6, 21, 6, 27,
+	-1000, -1, -2, -6, -7, -14, 27, -11, -15, -20,
+	-21, -22, -23, -24, -17, 18, -12, -16, 91, 7,
+	105, 106, 70, 85, -18, 31, 32, 33, 45, 46,
+	55, 56, 57, 58, 59, 60, 61, 66, 67, 68,
+	84, 89, 90, 34, 37, 40, 38, 39, 41, 42,
+	43, 44, 35, 36, 69, 96, 97, 98, 105, 106,
+	107, 108, 109, 110, 99, 100, 103, 104, 101, 102,
+	-30, 51, -31, -37, -38, -3, 24, 25, 26, 16,
+	100, 17, -7, -6, -2, -10, 19, -9, 5, 27,
+	27, -4, 29, 30, 27, -4, 7, 7, 27, 27,
+	27, -25, -26, -27, 47, -25, -25, -25, -25, -25,
+	-25, -25, -25, -25, -25, -25, -25, -25, -25, 51,
+	-31, 92, -29, -28, -55, -54, -56, -57, -35, -41,
+	-42, -49, -43, -46, 95, 86, 50, 48, 49, 71,
+	73, 83, 82, -9, -62, -36, -61, -33, 27, 52,
+	79, 53, 80, 81, 5, -34, -32, -38, 96, 6,
+	-19, 74, 94, 28, 28, 19, 2, 22, 14, 100,
+	15, 16, -8, 7, -7, -14, 27, -7, 7, 27,
+	27, 27, 6, 27, -7, 7, 7, -2, 75, 76,
+	77, 78, -2, -2, -2, -2, -2, -2, -2, -2,
+	-2, -2, -2, -2, -2, -2, 92, 75, -35, 97,
+	22, 96, 7, 5, -40, -53, 8, -52, 5, -53,
+	6, 6, -53, 6, -59, -58, 8, -35, 6, -51,
+	-50, 5, -44, -45, 5, -9, -47, -48, 5, -9,
+	14, 100, 103, 104, 101, 102, 99, -39, 6, -19,
+	96, 27, -9, 6, 6, 6, 6, 2, 28, 22,
+	11, -30, 51, 10, -60, -14, -8, 28, 22, -7,
+	7, -5, 28, 5, -5, 28, 22, 6, 22, 22,
+	28, 27, 27, 27, 27, 75, 27, -35, -35, -35,
+	8, -53, 22, 14, -53, -58, 6, 14, 28, 22,
+	14, 22, 22, 74, 94, 9, 4, -21, 74, 94,
+	9, 4, -21, 9, 4, -21, 9, 4, -21, 9,
+	4, -21, 9, 4, -21, 9, 4, -21, 96, 27,
+	-39, 6, -4, -8, -7, 28, -63, 72, -64, 86,
+	51, 10, -60, 54, -63, -60, -30, 51, 10, 51,
+	-30, 28, -60, 28, -4, -7, 28, 22, 22, 28,
+	28, -7, 22, 6, -7, -5, 28, -5, 28, 28,
+	-5, 28, -5, 27, -5, -52, 6, -53, 6, -50,
+	2, 5, 6, -45, -48, 27, 27, 27, 27, -39,
+	6, 28, 28, 11, 28, 9, 72, 7, 87, 88,
+	-63, 10, 5, -13, 62, 63, 64, 65, -60, -30,
+	-60, -63, -35, 28, -60, 10, 28, 28, -7, 5,
+	28, -7, 22, 28, 28, 28, 28, 28, -5, 28,
+	6, 6, 6, 6, 28, -4, 28, -63, -64, 9,
+	27, 27, -63, 27, -60, 10, 28, -63, -60, 51,
+	10, -4, 28, -4, 28, 6, 28, 93, 28, 28,
+	28, 28, 28, 28, 28, 5, -63, 10, -60, -63,
+	22, 93, 9, 28, -63, 6, 9, -6, 27, 22,
+	-6, -6, 6, 28,
 }
 
 var exprDef = [...]int16{
-	0, -2, 1, 2, 3, 11, 0, 4, 5, 6,
-	7, 8, 9, 0, 0, 0, 191, 0, 0, 0,
-	0, 207, 208, 209, 210, 211, 212, 213, 214, 215,
-	216, 217, 218, 219, 220, 221, 196, 197, 198, 199,
-	200, 201, 202, 203, 204, 205, 206, 195, 177, 177,
-	177, 177, 177, 177, 177, 177, 177, 177, 177, 177,
-	177, 177, 177, 12, 72, 74, 0, 92, 0, 57,
-	58, 59, 60, 61, 62, 3, 2, 0, 0, 65,
-	66, 0, 0, 0, 0, 0, 0, 192, 193, 0,
-	0, 0, 183, 184, 178, 0, 0, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 0, 0, 0, 73,
-	94, 75, 76, 77, 78, 79, 80, 81, 82, 83,
-	84, 97, 99, 0, 101, 0, 114, 115, 116, 117,
-	0, 0, 107, 0, 0, 0, 0, 129, 130, 0,
-	89, 0, 85, 10, 13, 63, 64, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 3, 191, 0, 0,
-	0, 3, 0, 162, 0, 0, 185, 188, 163, 164,
-	165, 166, 167, 168, 169, 170, 171, 172, 173, 174,
-	175, 176, 119, 0, 0, 0, 98, 105, 95, 125,
-	124, 103, 100, 102, 0, 106, 113, 110, 0, 156,
-	154, 152, 153, 161, 159, 157, 158, 0, 0, 0,
-	0, 0, 0, 0, 93, 86, 0, 0, 0, 67,
-	68, 69, 70, 71, 39, 46, 0, 14, 0, 0,
-	0, 0, 0, 50, 0, 3, 191, 0, 227, 223,
-	0, 228, 0, 194, 0, 0, 0, 0, 120, 121,
-	122, 96, 104, 0, 0, 118, 0, 0, 0, 0,
-	0, 136, 143, 150, 0, 135, 142, 149, 131, 138,
-	145, 132, 139, 146, 133, 140, 147, 134, 141, 148,
-	137, 144, 151, 0, 0, 91, 0, 48, 0, 15,
-	18, 34, 0, 22, 0, 26, 0, 0, 0, 0,
-	0, 38, 52, 3, 51, 0, 0, 225, 226, 0,
-	0, 180, 0, 182, 186, 0, 189, 0, 126, 123,
-	111, 112, 108, 109, 155, 160, 0, 0, 88, 0,
-	90, 47, 19, 35, 36, 222, 23, 42, 27, 30,
-	40, 0, 43, 44, 45, 16, 0, 0, 0, 53,
-	3, 224, 0, 179, 181, 187, 190, 0, 0, 87,
-	49, 37, 31, 0, 17, 20, 0, 24, 28, 0,
-	54, 55, 0, 127, 128, 0, 21, 25, 29, 32,
-	0, 41, 33, 0, 0, 0, 56,
+	0, -2, 1, 2, 3, 13, 0, 4, 5, 6,
+	7, 8, 9, 10, 11, 0, 0, 0, 0, 221,
+	0, 0, 0, 0, 0, 237, 238, 239, 240, 241,
+	242, 243, 244, 245, 246, 247, 248, 249, 250, 251,
+	252, 253, 254, 226, 227, 228, 229, 230, 231, 232,
+	233, 234, 235, 236, 225, 207, 207, 207, 207, 207,
+	207, 207, 207, 207, 207, 207, 207, 207, 207, 207,
+	14, 0, 85, 87, 110, 0, 70, 71, 72, 73,
+	74, 75, 3, 2, 0, 0, 78, 79, 0, 0,
+	0, 0, 0, 0, 0, 0, 222, 223, 0, 0,
+	0, 0, 213, 214, 208, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	86, 0, 88, 89, 90, 91, 92, 93, 94, 95,
+	96, 97, 98, 99, 0, 0, 115, 117, 0, 119,
+	0, 121, 0, 141, 142, 143, 144, 145, 0, 0,
+	134, 0, 0, 0, 0, 159, 160, 112, 0, 107,
+	0, 102, 103, 12, 15, 76, 77, 0, 0, 0,
+	0, 0, 0, 221, 3, 13, 0, 3, 221, 0,
+	0, 0, 0, 0, 3, 0, 0, 192, 0, 0,
+	215, 218, 193, 194, 195, 196, 197, 198, 199, 200,
+	201, 202, 203, 204, 205, 206, 0, 0, 147, 0,
+	0, 0, 100, 101, 116, 124, 113, 153, 152, 122,
+	118, 120, 125, 129, 0, 127, 0, 0, 133, 140,
+	137, 0, 186, 184, 182, 183, 191, 189, 187, 188,
+	0, 0, 0, 0, 0, 0, 0, 111, 104, 0,
+	0, 0, 80, 81, 82, 83, 84, 43, 51, 0,
+	0, 14, 0, 18, 0, 13, 0, 59, 0, 3,
+	221, 0, 266, 262, 0, 267, 0, 0, 0, 0,
+	224, 0, 0, 0, 0, 0, 0, 148, 149, 150,
+	114, 123, 0, 0, 130, 128, 131, 0, 146, 0,
+	0, 0, 0, 0, 0, 166, 173, 180, 0, 0,
+	165, 172, 179, 161, 168, 175, 162, 169, 176, 163,
+	170, 177, 164, 171, 178, 167, 174, 181, 0, 0,
+	109, 0, 53, 0, 3, 55, 0, 0, 256, 0,
+	0, 30, 0, 0, 19, 22, 38, 0, 26, 0,
+	14, 0, 0, 42, 61, 3, 60, 0, 0, 264,
+	265, 3, 0, 0, 3, 0, 210, 0, 212, 216,
+	0, 219, 0, 0, 0, 154, 151, 132, 126, 138,
+	139, 135, 136, 185, 190, 0, 0, 0, 0, 106,
+	0, 108, 52, 0, 56, 255, 0, 259, 0, 0,
+	31, 34, 44, 0, 47, 48, 49, 50, 23, 39,
+	40, 27, 46, 0, 0, 20, 0, 62, 3, 263,
+	65, 3, 0, 69, 209, 211, 217, 220, 0, 0,
+	0, 0, 0, 0, 105, 54, 57, 0, 257, 258,
+	0, 0, 35, 0, 41, 32, 0, 21, 24, 0,
+	28, 63, 64, 66, 67, 0, 0, 0, 155, 157,
+	156, 158, 58, 260, 261, 0, 33, 36, 25, 29,
+	0, 0, 0, 45, 37, 0, 0, 16, 0, 0,
+	17, 0, 0, 68,
 }
 
 var exprTok1 = [...]int8{
@@ -555,7 +668,8 @@ var exprTok2 = [...]int8{
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
 	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
 	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
-	92, 93, 94,
+	92, 93, 94, 95, 96, 97, 98, 99, 100, 101,
+	102, 103, 104, 105, 106, 107, 108, 109, 110,
 }
 
 var exprTok3 = [...]int8{
@@ -901,1368 +1015,1602 @@ exprdefault:
 
 	case 1:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:154
+//line expr.y:167
 		{
 			exprlex.(*parser).expr = exprDollar[1].Expr
 		}
 	case 2:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:157
+//line expr.y:170
 		{
 			exprVAL.Expr = exprDollar[1].LogExpr
 		}
 	case 3:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:158
+//line expr.y:171
 		{
 			exprVAL.Expr = exprDollar[1].MetricExpr
 		}
 	case 4:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:162
+//line expr.y:175
 		{
 			exprVAL.MetricExpr = exprDollar[1].RangeAggregationExpr
 		}
 	case 5:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:163
+//line expr.y:176
 		{
 			exprVAL.MetricExpr = exprDollar[1].VectorAggregationExpr
 		}
 	case 6:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:164
+//line expr.y:177
 		{
 			exprVAL.MetricExpr = exprDollar[1].BinOpExpr
 		}
 	case 7:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:165
+//line expr.y:178
 		{
 			exprVAL.MetricExpr = exprDollar[1].LiteralExpr
 		}
 	case 8:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:166
+//line expr.y:179
 		{
 			exprVAL.MetricExpr = exprDollar[1].LabelReplaceExpr
 		}
 	case 9:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:167
+//line expr.y:180
 		{
-			exprVAL.MetricExpr = exprDollar[1].VectorExpr
+			exprVAL.MetricExpr = exprDollar[1].MetricExpr
 		}
 	case 10:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:181
+		{
+			exprVAL.MetricExpr = exprDollar[1].MetricExpr
+		}
+	case 11:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:182
+		{
+			exprVAL.MetricExpr = exprDollar[1].VectorExpr
+		}
+	case 12:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:168
+//line expr.y:183
 		{
 			exprVAL.MetricExpr = exprDollar[2].MetricExpr
 		}
-	case 11:
+	case 13:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:172
+//line expr.y:187
 		{
 			exprVAL.LogExpr = newMatcherExpr(exprDollar[1].Selector)
 		}
-	case 12:
+	case 14:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:173
+//line expr.y:188
 		{
 			exprVAL.LogExpr = newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr)
 		}
-	case 13:
+	case 15:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:174
+//line expr.y:189
 		{
 			exprVAL.LogExpr = exprDollar[2].LogExpr
 		}
-	case 14:
+	case 16:
+		exprDollar = exprS[exprpt-10 : exprpt+1]
+//line expr.y:190
+		{
+			exprVAL.LogExpr = newJoinExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[6].Labels, exprDollar[9].duration, exprDollar[10].LogExpr)
+		}
+	case 17:
+		exprDollar = exprS[exprpt-11 : exprpt+1]
+//line expr.y:191
+		{
+			exprVAL.LogExpr = newJoinExpr(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[7].Labels, exprDollar[10].duration, exprDollar[11].LogExpr)
+		}
+	case 18:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:178
+//line expr.y:195
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, nil, nil)
 		}
-	case 15:
+	case 19:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:179
+//line expr.y:196
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, nil, exprDollar[3].OffsetExpr)
 		}
-	case 16:
+	case 20:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:180
+//line expr.y:197
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, nil, nil)
 		}
-	case 17:
+	case 21:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:181
+//line expr.y:198
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, nil, exprDollar[5].OffsetExpr)
 		}
-	case 18:
+	case 22:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:182
+//line expr.y:199
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, exprDollar[3].UnwrapExpr, nil)
 		}
-	case 19:
+	case 23:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:183
+//line expr.y:200
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].duration, exprDollar[4].UnwrapExpr, exprDollar[3].OffsetExpr)
 		}
-	case 20:
+	case 24:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:184
+//line expr.y:201
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, exprDollar[5].UnwrapExpr, nil)
 		}
-	case 21:
+	case 25:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:185
+//line expr.y:202
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[4].duration, exprDollar[6].UnwrapExpr, exprDollar[5].OffsetExpr)
 		}
-	case 22:
+	case 26:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:186
+//line expr.y:203
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].duration, exprDollar[2].UnwrapExpr, nil)
 		}
-	case 23:
+	case 27:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:187
+//line expr.y:204
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].duration, exprDollar[2].UnwrapExpr, exprDollar[4].OffsetExpr)
 		}
-	case 24:
+	case 28:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:188
+//line expr.y:205
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[5].duration, exprDollar[3].UnwrapExpr, nil)
 		}
-	case 25:
+	case 29:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:189
+//line expr.y:206
 		{
 			exprVAL.LogRangeExpr = newLogRange(newMatcherExpr(exprDollar[2].Selector), exprDollar[5].duration, exprDollar[3].UnwrapExpr, exprDollar[6].OffsetExpr)
 		}
-	case 26:
+	case 30:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:190
+//line expr.y:207
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[3].duration, nil, nil)
 		}
-	case 27:
+	case 31:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:191
+//line expr.y:208
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[3].duration, nil, exprDollar[4].OffsetExpr)
 		}
-	case 28:
+	case 32:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:192
+//line expr.y:209
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[5].duration, nil, nil)
 		}
-	case 29:
+	case 33:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:193
+//line expr.y:210
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[5].duration, nil, exprDollar[6].OffsetExpr)
 		}
-	case 30:
+	case 34:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:194
+//line expr.y:211
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[4].duration, exprDollar[3].UnwrapExpr, nil)
 		}
-	case 31:
+	case 35:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:195
+//line expr.y:212
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[2].PipelineExpr), exprDollar[4].duration, exprDollar[3].UnwrapExpr, exprDollar[5].OffsetExpr)
 		}
-	case 32:
+	case 36:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:196
+//line expr.y:213
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[6].duration, exprDollar[4].UnwrapExpr, nil)
 		}
-	case 33:
+	case 37:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
-//line expr.y:197
+//line expr.y:214
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[2].Selector), exprDollar[3].PipelineExpr), exprDollar[6].duration, exprDollar[4].UnwrapExpr, exprDollar[7].OffsetExpr)
 		}
-	case 34:
+	case 38:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:198
+//line expr.y:215
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].PipelineExpr), exprDollar[2].duration, nil, nil)
 		}
-	case 35:
+	case 39:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:199
+//line expr.y:216
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[4].PipelineExpr), exprDollar[2].duration, nil, exprDollar[3].OffsetExpr)
 		}
-	case 36:
+	case 40:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:200
+//line expr.y:217
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[3].PipelineExpr), exprDollar[2].duration, exprDollar[4].UnwrapExpr, nil)
 		}
-	case 37:
+	case 41:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:201
+//line expr.y:218
 		{
 			exprVAL.LogRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(exprDollar[1].Selector), exprDollar[4].PipelineExpr), exprDollar[2].duration, exprDollar[5].UnwrapExpr, exprDollar[3].OffsetExpr)
 		}
-	case 38:
+	case 42:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:202
+//line expr.y:219
 		{
 			exprVAL.LogRangeExpr = exprDollar[2].LogRangeExpr
 		}
-	case 40:
+	case 44:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:207
+//line expr.y:224
 		{
 			exprVAL.UnwrapExpr = newUnwrapExpr(exprDollar[3].str, "")
 		}
-	case 41:
+	case 45:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:208
+//line expr.y:225
 		{
 			exprVAL.UnwrapExpr = newUnwrapExpr(exprDollar[5].str, exprDollar[3].ConvOp)
 		}
-	case 42:
+	case 46:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:209
+//line expr.y:226
 		{
 			exprVAL.UnwrapExpr = exprDollar[1].UnwrapExpr.addPostFilter(exprDollar[3].LabelFilter)
 		}
-	case 43:
+	case 47:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:213
+//line expr.y:230
 		{
 			exprVAL.ConvOp = OpConvBytes
 		}
-	case 44:
+	case 48:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:214
+//line expr.y:231
 		{
 			exprVAL.ConvOp = OpConvDuration
 		}
-	case 45:
+	case 49:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:215
+//line expr.y:232
 		{
 			exprVAL.ConvOp = OpConvDurationSeconds
 		}
-	case 46:
+	case 50:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:233
+		{
+			exprVAL.ConvOp = OpConvLabel
+		}
+	case 51:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:219
+//line expr.y:237
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[3].LogRangeExpr, exprDollar[1].RangeOp, nil, nil)
 		}
-	case 47:
+	case 52:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:220
+//line expr.y:238
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, nil, &exprDollar[3].str)
 		}
-	case 48:
+	case 53:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:221
+//line expr.y:239
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[3].LogRangeExpr, exprDollar[1].RangeOp, exprDollar[5].Grouping, nil)
 		}
-	case 49:
+	case 54:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
-//line expr.y:222
+//line expr.y:240
 		{
 			exprVAL.RangeAggregationExpr = newRangeAggregationExpr(exprDollar[5].LogRangeExpr, exprDollar[1].RangeOp, exprDollar[7].Grouping, &exprDollar[3].str)
 		}
-	case 50:
+	case 55:
+		exprDollar = exprS[exprpt-5 : exprpt+1]
+//line expr.y:244
+		{
+			exprVAL.MetricExpr = newSubqueryExpr(exprDollar[3].MetricExpr, exprDollar[1].RangeOp, exprDollar[4].subqueryRange, nil, nil)
+		}
+	case 56:
+		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line expr.y:245
+		{
+			exprVAL.MetricExpr = newSubqueryExpr(exprDollar[3].MetricExpr, exprDollar[1].RangeOp, exprDollar[4].subqueryRange, exprDollar[5].OffsetExpr, nil)
+		}
+	case 57:
+		exprDollar = exprS[exprpt-7 : exprpt+1]
+//line expr.y:246
+		{
+			exprVAL.MetricExpr = newSubqueryExpr(exprDollar[5].MetricExpr, exprDollar[1].RangeOp, exprDollar[6].subqueryRange, nil, &exprDollar[3].str)
+		}
+	case 58:
+		exprDollar = exprS[exprpt-8 : exprpt+1]
+//line expr.y:247
+		{
+			exprVAL.MetricExpr = newSubqueryExpr(exprDollar[5].MetricExpr, exprDollar[1].RangeOp, exprDollar[6].subqueryRange, exprDollar[7].OffsetExpr, &exprDollar[3].str)
+		}
+	case 59:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:227
+//line expr.y:252
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[3].MetricExpr, exprDollar[1].VectorOp, nil, nil)
 		}
-	case 51:
+	case 60:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:228
+//line expr.y:253
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[4].MetricExpr, exprDollar[1].VectorOp, exprDollar[2].Grouping, nil)
 		}
-	case 52:
+	case 61:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:229
+//line expr.y:254
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[3].MetricExpr, exprDollar[1].VectorOp, exprDollar[5].Grouping, nil)
 		}
-	case 53:
+	case 62:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:231
+//line expr.y:256
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[5].MetricExpr, exprDollar[1].VectorOp, nil, &exprDollar[3].str)
 		}
-	case 54:
+	case 63:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
-//line expr.y:232
+//line expr.y:257
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[5].MetricExpr, exprDollar[1].VectorOp, exprDollar[7].Grouping, &exprDollar[3].str)
 		}
-	case 55:
+	case 64:
 		exprDollar = exprS[exprpt-7 : exprpt+1]
-//line expr.y:233
+//line expr.y:258
 		{
 			exprVAL.VectorAggregationExpr = mustNewVectorAggregationExpr(exprDollar[6].MetricExpr, exprDollar[1].VectorOp, exprDollar[2].Grouping, &exprDollar[4].str)
 		}
-	case 56:
+	case 65:
+		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line expr.y:260
+		{
+			exprVAL.VectorAggregationExpr = newCountValuesExpr(exprDollar[5].MetricExpr, exprDollar[3].str, nil)
+		}
+	case 66:
+		exprDollar = exprS[exprpt-7 : exprpt+1]
+//line expr.y:261
+		{
+			exprVAL.VectorAggregationExpr = newCountValuesExpr(exprDollar[5].MetricExpr, exprDollar[3].str, exprDollar[7].Grouping)
+		}
+	case 67:
+		exprDollar = exprS[exprpt-7 : exprpt+1]
+//line expr.y:262
+		{
+			exprVAL.VectorAggregationExpr = newCountValuesExpr(exprDollar[6].MetricExpr, exprDollar[4].str, exprDollar[2].Grouping)
+		}
+	case 68:
 		exprDollar = exprS[exprpt-12 : exprpt+1]
-//line expr.y:238
+//line expr.y:267
 		{
 			exprVAL.LabelReplaceExpr = mustNewLabelReplaceExpr(exprDollar[3].MetricExpr, exprDollar[5].str, exprDollar[7].str, exprDollar[9].str, exprDollar[11].str)
 		}
-	case 57:
+	case 69:
+		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line expr.y:271
+		{
+			exprVAL.MetricExpr = newHistogramQuantileExpr(exprDollar[5].MetricExpr, exprDollar[3].str)
+		}
+	case 70:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:242
+//line expr.y:275
 		{
 			exprVAL.Filter = log.LineMatchRegexp
 		}
-	case 58:
+	case 71:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:243
+//line expr.y:276
 		{
 			exprVAL.Filter = log.LineMatchEqual
 		}
-	case 59:
+	case 72:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:244
+//line expr.y:277
 		{
 			exprVAL.Filter = log.LineMatchPattern
 		}
-	case 60:
+	case 73:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:245
+//line expr.y:278
 		{
 			exprVAL.Filter = log.LineMatchNotRegexp
 		}
-	case 61:
+	case 74:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:246
+//line expr.y:279
 		{
 			exprVAL.Filter = log.LineMatchNotEqual
 		}
-	case 62:
+	case 75:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:247
+//line expr.y:280
 		{
 			exprVAL.Filter = log.LineMatchNotPattern
 		}
-	case 63:
+	case 76:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:251
+//line expr.y:284
 		{
 			exprVAL.Selector = exprDollar[2].Matchers
 		}
-	case 64:
+	case 77:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:252
+//line expr.y:285
 		{
 			exprVAL.Selector = exprDollar[2].Matchers
 		}
-	case 65:
+	case 78:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:253
+//line expr.y:286
 		{
 		}
-	case 66:
+	case 79:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:257
+//line expr.y:290
 		{
 			exprVAL.Matchers = []*labels.Matcher{exprDollar[1].Matcher}
 		}
-	case 67:
+	case 80:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:258
+//line expr.y:291
 		{
 			exprVAL.Matchers = append(exprDollar[1].Matchers, exprDollar[3].Matcher)
 		}
-	case 68:
+	case 81:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:262
+//line expr.y:295
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchEqual, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 69:
+	case 82:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:263
+//line expr.y:296
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchNotEqual, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 70:
+	case 83:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:264
+//line expr.y:297
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchRegexp, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 71:
+	case 84:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:265
+//line expr.y:298
 		{
 			exprVAL.Matcher = mustNewMatcher(labels.MatchNotRegexp, exprDollar[1].str, exprDollar[3].str)
 		}
-	case 72:
+	case 85:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:269
+//line expr.y:302
 		{
 			exprVAL.PipelineExpr = MultiStageExpr{exprDollar[1].PipelineStage}
 		}
-	case 73:
+	case 86:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:270
+//line expr.y:303
 		{
 			exprVAL.PipelineExpr = append(exprDollar[1].PipelineExpr, exprDollar[2].PipelineStage)
 		}
-	case 74:
+	case 87:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:274
+//line expr.y:307
 		{
 			exprVAL.PipelineStage = exprDollar[1].LineFilters
 		}
-	case 75:
+	case 88:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:275
+//line expr.y:308
 		{
 			exprVAL.PipelineStage = exprDollar[2].LogfmtParser
 		}
-	case 76:
+	case 89:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:276
+//line expr.y:309
 		{
 			exprVAL.PipelineStage = exprDollar[2].LabelParser
 		}
-	case 77:
+	case 90:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:277
+//line expr.y:310
 		{
 			exprVAL.PipelineStage = exprDollar[2].JSONExpressionParser
 		}
-	case 78:
+	case 91:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:278
+//line expr.y:311
 		{
 			exprVAL.PipelineStage = exprDollar[2].LogfmtExpressionParser
 		}
-	case 79:
+	case 92:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:279
+//line expr.y:312
+		{
+			exprVAL.PipelineStage = exprDollar[2].XMLExpressionParser
+		}
+	case 93:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line expr.y:313
+		{
+			exprVAL.PipelineStage = exprDollar[2].CSVParser
+		}
+	case 94:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line expr.y:314
 		{
 			exprVAL.PipelineStage = &LabelFilterExpr{LabelFilterer: exprDollar[2].LabelFilter}
 		}
-	case 80:
+	case 95:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:280
+//line expr.y:315
 		{
 			exprVAL.PipelineStage = exprDollar[2].LineFormatExpr
 		}
-	case 81:
+	case 96:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:281
+//line expr.y:316
 		{
 			exprVAL.PipelineStage = exprDollar[2].DecolorizeExpr
 		}
-	case 82:
+	case 97:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:282
+//line expr.y:317
 		{
 			exprVAL.PipelineStage = exprDollar[2].LabelFormatExpr
 		}
-	case 83:
+	case 98:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:283
+//line expr.y:318
 		{
 			exprVAL.PipelineStage = exprDollar[2].DropLabelsExpr
 		}
-	case 84:
+	case 99:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:284
+//line expr.y:319
 		{
 			exprVAL.PipelineStage = exprDollar[2].KeepLabelsExpr
 		}
-	case 85:
+	case 100:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line expr.y:320
+		{
+			exprVAL.PipelineStage = newSamplingExpr(exprDollar[3].str)
+		}
+	case 101:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line expr.y:321
+		{
+			exprVAL.PipelineStage = newMacroExpr(exprDollar[3].str)
+		}
+	case 102:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:288
+//line expr.y:325
 		{
 			exprVAL.FilterOp = OpFilterIP
 		}
-	case 86:
+	case 103:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:326
+		{
+			exprVAL.FilterOp = OpFilterWord
+		}
+	case 104:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:292
+//line expr.y:330
 		{
 			exprVAL.OrFilter = newLineFilterExpr(log.LineMatchEqual, "", exprDollar[1].str)
 		}
-	case 87:
+	case 105:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:293
+//line expr.y:331
 		{
 			exprVAL.OrFilter = newLineFilterExpr(log.LineMatchEqual, exprDollar[1].FilterOp, exprDollar[3].str)
 		}
-	case 88:
+	case 106:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:294
+//line expr.y:332
 		{
 			exprVAL.OrFilter = newOrLineFilter(newLineFilterExpr(log.LineMatchEqual, "", exprDollar[1].str), exprDollar[3].OrFilter)
 		}
-	case 89:
+	case 107:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:298
+//line expr.y:336
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, "", exprDollar[2].str)
 		}
-	case 90:
+	case 108:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:299
+//line expr.y:337
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, exprDollar[2].FilterOp, exprDollar[4].str)
 		}
-	case 91:
+	case 109:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:300
+//line expr.y:338
 		{
 			exprVAL.LineFilter = newOrLineFilter(newLineFilterExpr(exprDollar[1].Filter, "", exprDollar[2].str), exprDollar[4].OrFilter)
 		}
-	case 92:
+	case 110:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:304
+//line expr.y:342
 		{
 			exprVAL.LineFilters = exprDollar[1].LineFilter
 		}
-	case 93:
+	case 111:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:305
+//line expr.y:343
 		{
 			exprVAL.LineFilters = newOrLineFilter(exprDollar[1].LineFilter, exprDollar[3].OrFilter)
 		}
-	case 94:
+	case 112:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:306
+//line expr.y:344
 		{
 			exprVAL.LineFilters = newNestedLineFilterExpr(exprDollar[1].LineFilters, exprDollar[2].LineFilter)
 		}
-	case 95:
+	case 113:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:310
+//line expr.y:348
 		{
 			exprVAL.ParserFlags = []string{exprDollar[1].str}
 		}
-	case 96:
+	case 114:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:311
+//line expr.y:349
 		{
 			exprVAL.ParserFlags = append(exprDollar[1].ParserFlags, exprDollar[2].str)
 		}
-	case 97:
+	case 115:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:315
+//line expr.y:353
 		{
 			exprVAL.LogfmtParser = newLogfmtParserExpr(nil)
 		}
-	case 98:
+	case 116:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:316
+//line expr.y:354
 		{
 			exprVAL.LogfmtParser = newLogfmtParserExpr(exprDollar[2].ParserFlags)
 		}
-	case 99:
+	case 117:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:320
+//line expr.y:358
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeJSON, "")
 		}
-	case 100:
+	case 118:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:321
+//line expr.y:359
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeRegexp, exprDollar[2].str)
 		}
-	case 101:
+	case 119:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:322
+//line expr.y:360
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeUnpack, "")
 		}
-	case 102:
+	case 120:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:323
+//line expr.y:361
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypePattern, exprDollar[2].str)
 		}
-	case 103:
+	case 121:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:362
+		{
+			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeXML, "")
+		}
+	case 122:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:327
+//line expr.y:366
 		{
 			exprVAL.JSONExpressionParser = newJSONExpressionParser(exprDollar[2].LabelExtractionExpressionList)
 		}
-	case 104:
+	case 123:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:330
+//line expr.y:369
 		{
 			exprVAL.LogfmtExpressionParser = newLogfmtExpressionParser(exprDollar[3].LabelExtractionExpressionList, exprDollar[2].ParserFlags)
 		}
-	case 105:
+	case 124:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:331
+//line expr.y:370
 		{
 			exprVAL.LogfmtExpressionParser = newLogfmtExpressionParser(exprDollar[2].LabelExtractionExpressionList, nil)
 		}
-	case 106:
+	case 125:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line expr.y:374
+		{
+			exprVAL.XMLExpressionParser = newXMLExpressionParser(exprDollar[2].LabelExtractionExpressionList)
+		}
+	case 126:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line expr.y:378
+		{
+			exprVAL.ParserOption = parserOption{name: exprDollar[1].str, value: exprDollar[3].str}
+		}
+	case 127:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:382
+		{
+			exprVAL.ParserOptions = []parserOption{exprDollar[1].ParserOption}
+		}
+	case 128:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line expr.y:383
+		{
+			exprVAL.ParserOptions = append(exprDollar[1].ParserOptions, exprDollar[2].ParserOption)
+		}
+	case 129:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:334
+//line expr.y:387
+		{
+			exprVAL.CSVParser = newCSVParserExpr(exprDollar[2].str, nil, nil)
+		}
+	case 130:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line expr.y:388
+		{
+			exprVAL.CSVParser = newCSVParserExpr(exprDollar[2].str, nil, exprDollar[3].LabelExtractionExpressionList)
+		}
+	case 131:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line expr.y:389
+		{
+			exprVAL.CSVParser = newCSVParserExpr(exprDollar[3].str, exprDollar[2].ParserOptions, nil)
+		}
+	case 132:
+		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line expr.y:390
+		{
+			exprVAL.CSVParser = newCSVParserExpr(exprDollar[3].str, exprDollar[2].ParserOptions, exprDollar[4].LabelExtractionExpressionList)
+		}
+	case 133:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line expr.y:393
 		{
 			exprVAL.LineFormatExpr = newLineFmtExpr(exprDollar[2].str)
 		}
-	case 107:
+	case 134:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:336
+//line expr.y:395
 		{
 			exprVAL.DecolorizeExpr = newDecolorizeExpr()
 		}
-	case 108:
+	case 135:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:339
+//line expr.y:398
 		{
 			exprVAL.LabelFormat = log.NewRenameLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 109:
+	case 136:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:340
+//line expr.y:399
 		{
 			exprVAL.LabelFormat = log.NewTemplateLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 110:
+	case 137:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:344
+//line expr.y:403
 		{
 			exprVAL.LabelsFormat = []log.LabelFmt{exprDollar[1].LabelFormat}
 		}
-	case 111:
+	case 138:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:345
+//line expr.y:404
 		{
 			exprVAL.LabelsFormat = append(exprDollar[1].LabelsFormat, exprDollar[3].LabelFormat)
 		}
-	case 113:
+	case 140:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:350
+//line expr.y:409
 		{
 			exprVAL.LabelFormatExpr = newLabelFmtExpr(exprDollar[2].LabelsFormat)
 		}
-	case 114:
+	case 141:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:353
+//line expr.y:412
 		{
 			exprVAL.LabelFilter = log.NewStringLabelFilter(exprDollar[1].Matcher)
 		}
-	case 115:
+	case 142:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:354
+//line expr.y:413
 		{
 			exprVAL.LabelFilter = exprDollar[1].IPLabelFilter
 		}
-	case 116:
+	case 143:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:355
+//line expr.y:414
+		{
+			exprVAL.LabelFilter = exprDollar[1].LabelFilter
+		}
+	case 144:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:415
 		{
 			exprVAL.LabelFilter = exprDollar[1].UnitFilter
 		}
-	case 117:
+	case 145:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:356
+//line expr.y:416
 		{
 			exprVAL.LabelFilter = exprDollar[1].NumberFilter
 		}
-	case 118:
+	case 146:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:357
+//line expr.y:417
 		{
 			exprVAL.LabelFilter = exprDollar[2].LabelFilter
 		}
-	case 119:
+	case 147:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:358
+//line expr.y:418
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[2].LabelFilter)
 		}
-	case 120:
+	case 148:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:359
+//line expr.y:419
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 121:
+	case 149:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:360
+//line expr.y:420
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 122:
+	case 150:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:361
+//line expr.y:421
 		{
 			exprVAL.LabelFilter = log.NewOrLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 123:
+	case 151:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:365
+//line expr.y:425
 		{
 			exprVAL.LabelExtractionExpression = log.NewLabelExtractionExpr(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 124:
+	case 152:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:366
+//line expr.y:426
 		{
 			exprVAL.LabelExtractionExpression = log.NewLabelExtractionExpr(exprDollar[1].str, exprDollar[1].str)
 		}
-	case 125:
+	case 153:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:369
+//line expr.y:429
 		{
 			exprVAL.LabelExtractionExpressionList = []log.LabelExtractionExpr{exprDollar[1].LabelExtractionExpression}
 		}
-	case 126:
+	case 154:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:370
+//line expr.y:430
 		{
 			exprVAL.LabelExtractionExpressionList = append(exprDollar[1].LabelExtractionExpressionList, exprDollar[3].LabelExtractionExpression)
 		}
-	case 127:
+	case 155:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:374
+//line expr.y:434
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterEqual)
 		}
-	case 128:
+	case 156:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
-//line expr.y:375
+//line expr.y:435
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterNotEqual)
 		}
-	case 129:
+	case 157:
+		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line expr.y:439
+		{
+			exprVAL.LabelFilter = log.NewWordLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterEqual)
+		}
+	case 158:
+		exprDollar = exprS[exprpt-6 : exprpt+1]
+//line expr.y:440
+		{
+			exprVAL.LabelFilter = log.NewWordLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterNotEqual)
+		}
+	case 159:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:379
+//line expr.y:444
 		{
 			exprVAL.UnitFilter = exprDollar[1].DurationFilter
 		}
-	case 130:
+	case 160:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:380
+//line expr.y:445
 		{
 			exprVAL.UnitFilter = exprDollar[1].BytesFilter
 		}
-	case 131:
+	case 161:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:383
+//line expr.y:448
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 132:
+	case 162:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:384
+//line expr.y:449
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 133:
+	case 163:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:385
+//line expr.y:450
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 134:
+	case 164:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:386
+//line expr.y:451
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 135:
+	case 165:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:387
+//line expr.y:452
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 136:
+	case 166:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:388
+//line expr.y:453
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 137:
+	case 167:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:389
+//line expr.y:454
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 138:
+	case 168:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:393
+//line expr.y:458
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 139:
+	case 169:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:394
+//line expr.y:459
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 140:
+	case 170:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:395
+//line expr.y:460
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 141:
+	case 171:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:396
+//line expr.y:461
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 142:
+	case 172:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:397
+//line expr.y:462
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 143:
+	case 173:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:398
+//line expr.y:463
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 144:
+	case 174:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:399
+//line expr.y:464
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 145:
+	case 175:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:403
+//line expr.y:468
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].LiteralExpr.Val)
 		}
-	case 146:
+	case 176:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:404
+//line expr.y:469
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].LiteralExpr.Val)
 		}
-	case 147:
+	case 177:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:405
+//line expr.y:470
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].LiteralExpr.Val)
 		}
-	case 148:
+	case 178:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:406
+//line expr.y:471
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].LiteralExpr.Val)
 		}
-	case 149:
+	case 179:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:407
+//line expr.y:472
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].LiteralExpr.Val)
 		}
-	case 150:
+	case 180:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:408
+//line expr.y:473
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].LiteralExpr.Val)
 		}
-	case 151:
+	case 181:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:409
+//line expr.y:474
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].LiteralExpr.Val)
 		}
-	case 152:
+	case 182:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:413
+//line expr.y:478
 		{
 			exprVAL.DropLabel = log.NewDropLabel(nil, exprDollar[1].str)
 		}
-	case 153:
+	case 183:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:414
+//line expr.y:479
 		{
 			exprVAL.DropLabel = log.NewDropLabel(exprDollar[1].Matcher, "")
 		}
-	case 154:
+	case 184:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:417
+//line expr.y:482
 		{
 			exprVAL.DropLabels = []log.DropLabel{exprDollar[1].DropLabel}
 		}
-	case 155:
+	case 185:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:418
+//line expr.y:483
 		{
 			exprVAL.DropLabels = append(exprDollar[1].DropLabels, exprDollar[3].DropLabel)
 		}
-	case 156:
+	case 186:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:421
+//line expr.y:486
 		{
 			exprVAL.DropLabelsExpr = newDropLabelsExpr(exprDollar[2].DropLabels)
 		}
-	case 157:
+	case 187:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:424
+//line expr.y:489
 		{
 			exprVAL.KeepLabel = log.NewKeepLabel(nil, exprDollar[1].str)
 		}
-	case 158:
+	case 188:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:425
+//line expr.y:490
 		{
 			exprVAL.KeepLabel = log.NewKeepLabel(exprDollar[1].Matcher, "")
 		}
-	case 159:
+	case 189:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:428
+//line expr.y:493
 		{
 			exprVAL.KeepLabels = []log.KeepLabel{exprDollar[1].KeepLabel}
 		}
-	case 160:
+	case 190:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:429
+//line expr.y:494
 		{
 			exprVAL.KeepLabels = append(exprDollar[1].KeepLabels, exprDollar[3].KeepLabel)
 		}
-	case 161:
+	case 191:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:432
+//line expr.y:497
 		{
 			exprVAL.KeepLabelsExpr = newKeepLabelsExpr(exprDollar[2].KeepLabels)
 		}
-	case 162:
+	case 192:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:436
+//line expr.y:501
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("or", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 163:
+	case 193:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:437
+//line expr.y:502
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("and", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 164:
+	case 194:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:438
+//line expr.y:503
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("unless", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 165:
+	case 195:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:439
+//line expr.y:504
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("+", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 166:
+	case 196:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:440
+//line expr.y:505
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("-", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 167:
+	case 197:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:441
+//line expr.y:506
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("*", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 168:
+	case 198:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:442
+//line expr.y:507
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("/", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 169:
+	case 199:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:443
+//line expr.y:508
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("%", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 170:
+	case 200:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:444
+//line expr.y:509
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("^", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 171:
+	case 201:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:445
+//line expr.y:510
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("==", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 172:
+	case 202:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:446
+//line expr.y:511
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("!=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 173:
+	case 203:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:447
+//line expr.y:512
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 174:
+	case 204:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:448
+//line expr.y:513
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 175:
+	case 205:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:449
+//line expr.y:514
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 176:
+	case 206:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:450
+//line expr.y:515
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 177:
+	case 207:
 		exprDollar = exprS[exprpt-0 : exprpt+1]
-//line expr.y:454
+//line expr.y:519
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}}
 		}
-	case 178:
+	case 208:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:458
+//line expr.y:523
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}, ReturnBool: true}
 		}
-	case 179:
+	case 209:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:465
+//line expr.y:530
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 180:
+	case 210:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:471
+//line expr.y:536
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 		}
-	case 181:
+	case 211:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:476
+//line expr.y:541
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 182:
+	case 212:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:481
+//line expr.y:546
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 		}
-	case 183:
+	case 213:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:487
+//line expr.y:552
 		{
 			exprVAL.BinOpModifier = exprDollar[1].BoolModifier
 		}
-	case 184:
+	case 214:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:488
+//line expr.y:553
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 		}
-	case 185:
+	case 215:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:490
+//line expr.y:555
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 186:
+	case 216:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:495
+//line expr.y:560
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 187:
+	case 217:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:500
+//line expr.y:565
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 188:
+	case 218:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:506
+//line expr.y:571
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 189:
+	case 219:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:511
+//line expr.y:576
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 190:
+	case 220:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
-//line expr.y:516
+//line expr.y:581
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 191:
+	case 221:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:524
+//line expr.y:589
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[1].str, false)
 		}
-	case 192:
+	case 222:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:525
+//line expr.y:590
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, false)
 		}
-	case 193:
+	case 223:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:526
+//line expr.y:591
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, true)
 		}
-	case 194:
+	case 224:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:530
+//line expr.y:595
 		{
 			exprVAL.VectorExpr = NewVectorExpr(exprDollar[3].str)
 		}
-	case 195:
+	case 225:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:533
+//line expr.y:598
 		{
 			exprVAL.Vector = OpTypeVector
 		}
-	case 196:
+	case 226:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:537
+//line expr.y:602
 		{
 			exprVAL.VectorOp = OpTypeSum
 		}
-	case 197:
+	case 227:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:538
+//line expr.y:603
 		{
 			exprVAL.VectorOp = OpTypeAvg
 		}
-	case 198:
+	case 228:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:539
+//line expr.y:604
 		{
 			exprVAL.VectorOp = OpTypeCount
 		}
-	case 199:
+	case 229:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:540
+//line expr.y:605
 		{
 			exprVAL.VectorOp = OpTypeMax
 		}
-	case 200:
+	case 230:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:541
+//line expr.y:606
 		{
 			exprVAL.VectorOp = OpTypeMin
 		}
-	case 201:
+	case 231:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:542
+//line expr.y:607
 		{
 			exprVAL.VectorOp = OpTypeStddev
 		}
-	case 202:
+	case 232:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:543
+//line expr.y:608
 		{
 			exprVAL.VectorOp = OpTypeStdvar
 		}
-	case 203:
+	case 233:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:544
+//line expr.y:609
 		{
 			exprVAL.VectorOp = OpTypeBottomK
 		}
-	case 204:
+	case 234:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:545
+//line expr.y:610
 		{
 			exprVAL.VectorOp = OpTypeTopK
 		}
-	case 205:
+	case 235:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:546
+//line expr.y:611
 		{
 			exprVAL.VectorOp = OpTypeSort
 		}
-	case 206:
+	case 236:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:547
+//line expr.y:612
 		{
 			exprVAL.VectorOp = OpTypeSortDesc
 		}
-	case 207:
+	case 237:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:551
+//line expr.y:616
 		{
 			exprVAL.RangeOp = OpRangeTypeCount
 		}
-	case 208:
+	case 238:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:552
+//line expr.y:617
 		{
 			exprVAL.RangeOp = OpRangeTypeRate
 		}
-	case 209:
+	case 239:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:553
+//line expr.y:618
 		{
 			exprVAL.RangeOp = OpRangeTypeRateCounter
 		}
-	case 210:
+	case 240:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:554
+//line expr.y:619
 		{
 			exprVAL.RangeOp = OpRangeTypeBytes
 		}
-	case 211:
+	case 241:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:555
+//line expr.y:620
 		{
 			exprVAL.RangeOp = OpRangeTypeBytesRate
 		}
-	case 212:
+	case 242:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:556
+//line expr.y:621
 		{
 			exprVAL.RangeOp = OpRangeTypeAvg
 		}
-	case 213:
+	case 243:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:557
+//line expr.y:622
 		{
 			exprVAL.RangeOp = OpRangeTypeSum
 		}
-	case 214:
+	case 244:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:558
+//line expr.y:623
 		{
 			exprVAL.RangeOp = OpRangeTypeMin
 		}
-	case 215:
+	case 245:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:559
+//line expr.y:624
 		{
 			exprVAL.RangeOp = OpRangeTypeMax
 		}
-	case 216:
+	case 246:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:560
+//line expr.y:625
 		{
 			exprVAL.RangeOp = OpRangeTypeStdvar
 		}
-	case 217:
+	case 247:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:561
+//line expr.y:626
 		{
 			exprVAL.RangeOp = OpRangeTypeStddev
 		}
-	case 218:
+	case 248:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:562
+//line expr.y:627
 		{
 			exprVAL.RangeOp = OpRangeTypeQuantile
 		}
-	case 219:
+	case 249:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:563
+//line expr.y:628
 		{
 			exprVAL.RangeOp = OpRangeTypeFirst
 		}
-	case 220:
+	case 250:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:564
+//line expr.y:629
 		{
 			exprVAL.RangeOp = OpRangeTypeLast
 		}
-	case 221:
+	case 251:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:565
+//line expr.y:630
 		{
 			exprVAL.RangeOp = OpRangeTypeAbsent
 		}
-	case 222:
+	case 252:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:631
+		{
+			exprVAL.RangeOp = OpRangeTypeHistogram
+		}
+	case 253:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:632
+		{
+			exprVAL.RangeOp = OpRangeTypeChanges
+		}
+	case 254:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:633
+		{
+			exprVAL.RangeOp = OpRangeTypeDistinct
+		}
+	case 255:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
-//line expr.y:569
+//line expr.y:637
 		{
 			exprVAL.OffsetExpr = newOffsetExpr(exprDollar[2].duration)
 		}
-	case 223:
+	case 256:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
-//line expr.y:572
+//line expr.y:638
+		{
+			exprVAL.OffsetExpr = exprDollar[1].OffsetExpr
+		}
+	case 257:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line expr.y:639
+		{
+			exprVAL.OffsetExpr = exprDollar[3].OffsetExpr.withOffset(exprDollar[2].duration)
+		}
+	case 258:
+		exprDollar = exprS[exprpt-3 : exprpt+1]
+//line expr.y:640
+		{
+			exprVAL.OffsetExpr = exprDollar[1].OffsetExpr.withOffset(exprDollar[3].duration)
+		}
+	case 259:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+//line expr.y:644
+		{
+			exprVAL.OffsetExpr = newAtExpr(exprDollar[2].str)
+		}
+	case 260:
+		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line expr.y:645
+		{
+			exprVAL.OffsetExpr = newAtStartOrEndExpr(OpAtStart)
+		}
+	case 261:
+		exprDollar = exprS[exprpt-4 : exprpt+1]
+//line expr.y:646
+		{
+			exprVAL.OffsetExpr = newAtStartOrEndExpr(OpAtEnd)
+		}
+	case 262:
+		exprDollar = exprS[exprpt-1 : exprpt+1]
+//line expr.y:650
 		{
 			exprVAL.Labels = []string{exprDollar[1].str}
 		}
-	case 224:
+	case 263:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:573
+//line expr.y:651
 		{
 			exprVAL.Labels = append(exprDollar[1].Labels, exprDollar[3].str)
 		}
-	case 225:
+	case 264:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:577
+//line expr.y:655
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: exprDollar[3].Labels}
 		}
-	case 226:
+	case 265:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
-//line expr.y:578
+//line expr.y:656
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: exprDollar[3].Labels}
 		}
-	case 227:
+	case 266:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:579
+//line expr.y:657
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: nil}
 		}
-	case 228:
+	case 267:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
-//line expr.y:580
+//line expr.y:658
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: nil}
 		}
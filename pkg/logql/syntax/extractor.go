@@ -62,6 +62,8 @@ func (r RangeAggregationExpr) extractor(override *Grouping) (log.SampleExtractor
 			convOp = log.ConvertBytes
 		case OpConvDuration, OpConvDurationSeconds:
 			convOp = log.ConvertDuration
+		case OpConvLabel:
+			convOp = log.ConvertHash
 		default:
 			convOp = log.ConvertFloat
 		}
@@ -38,6 +38,7 @@ var tokens = map[string]int{
 	"]":            CLOSE_BRACKET,
 	OpLabelReplace: LABEL_REPLACE,
 	OpOffset:       OFFSET,
+	OpAt:           AT,
 	OpOn:           ON,
 	OpIgnoring:     IGNORING,
 	OpGroupLeft:    GROUP_LEFT,
@@ -66,6 +67,8 @@ var tokens = map[string]int{
 	OpParserTypeLogfmt:  LOGFMT,
 	OpParserTypeUnpack:  UNPACK,
 	OpParserTypePattern: PATTERN,
+	OpParserTypeCSV:     CSV,
+	OpParserTypeXML:     XML,
 
 	// fmt
 	OpFmtLabel: LABEL_FMT,
@@ -80,11 +83,20 @@ var tokens = map[string]int{
 
 	// keep labels
 	OpKeep: KEEP,
+
+	// sampling
+	OpSample: SAMPLE,
+
+	// log query join
+	OpJoin:   JOIN,
+	OpWithin: WITHIN,
 }
 
 var parserFlags = map[string]struct{}{
 	OpStrict:    {},
 	OpKeepEmpty: {},
+	OpDelimiter: {},
+	OpQuote:     {},
 }
 
 // functionTokens are tokens that needs to be suffixes with parenthesis
@@ -105,6 +117,9 @@ var functionTokens = map[string]int{
 	OpRangeTypeFirst:       FIRST_OVER_TIME,
 	OpRangeTypeLast:        LAST_OVER_TIME,
 	OpRangeTypeAbsent:      ABSENT_OVER_TIME,
+	OpRangeTypeHistogram:   HISTOGRAM_OVER_TIME,
+	OpRangeTypeChanges:     CHANGES_OVER_TIME,
+	OpRangeTypeDistinct:    DISTINCT_OVER_TIME,
 	OpTypeVector:           VECTOR,
 
 	// vec ops
@@ -121,13 +136,23 @@ var functionTokens = map[string]int{
 	OpTypeSortDesc: SORT_DESC,
 	OpLabelReplace: LABEL_REPLACE,
 
+	OpTypeCountValues: COUNT_VALUES,
+
+	OpHistogramQuantile: HISTOGRAM_QUANTILE,
+
+	// @ modifier
+	OpAtStart: START,
+	OpAtEnd:   END,
+
 	// conversion Op
 	OpConvBytes:           BYTES_CONV,
 	OpConvDuration:        DURATION_CONV,
 	OpConvDurationSeconds: DURATION_SECONDS_CONV,
+	OpConvLabel:           LABEL_CONV,
 
 	// filterOp
-	OpFilterIP: IP,
+	OpFilterIP:   IP,
+	OpFilterWord: WORD,
 }
 
 type lexer struct {
@@ -202,13 +227,27 @@ func (l *lexer) Lex(lval *exprSymType) int {
 		l.builder.Reset()
 		for r := l.Next(); r != scanner.EOF; r = l.Next() {
 			if r == ']' {
-				i, err := model.ParseDuration(l.builder.String())
+				rng, step, isSubquery := strings.Cut(l.builder.String(), ":")
+				i, err := model.ParseDuration(rng)
 				if err != nil {
 					l.Error(err.Error())
 					return 0
 				}
-				lval.duration = time.Duration(i)
-				return RANGE
+				if !isSubquery {
+					lval.duration = time.Duration(i)
+					return RANGE
+				}
+				// subqueries have the form [<range>:<step>] where the step is optional
+				lval.subqueryRange = subqueryRange{Range: time.Duration(i)}
+				if step != "" {
+					s, err := model.ParseDuration(step)
+					if err != nil {
+						l.Error(err.Error())
+						return 0
+					}
+					lval.subqueryRange.Step = time.Duration(s)
+				}
+				return SUBQUERY_RANGE
 			}
 			_, _ = l.builder.WriteRune(r)
 		}
@@ -205,6 +205,16 @@ func validateSampleExpr(expr SampleExpr) error {
 			}
 		}
 		return validateSampleExpr(e.Left)
+	case *SubqueryExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left)
+	case *HistogramQuantileExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left)
 	default:
 		selector, err := e.Selector()
 		if err != nil {
@@ -218,6 +228,14 @@ func validateLogSelectorExpression(expr LogSelectorExpr) error {
 	switch e := expr.(type) {
 	case *VectorExpr:
 		return nil
+	case *JoinExpr:
+		if e.Within <= 0 {
+			return logqlmodel.NewParseError(fmt.Sprintf("invalid %s window %s: must be greater than 0", OpJoin, e.Within), 0, 0)
+		}
+		if err := validateLogSelectorExpression(e.Left); err != nil {
+			return err
+		}
+		return validateLogSelectorExpression(e.Right)
 	default:
 		return validateMatchers(e.Matchers())
 	}
@@ -271,3 +289,77 @@ func ParseLabels(lbs string) (labels.Labels, error) {
 	// for more information
 	return labels.NewBuilder(ls).Labels(), nil
 }
+
+// macroSelector is the stream selector the pipeline of a macro is parsed with.
+const macroSelector = `{__macro__="macro"}`
+
+// ParseMacro parses the pipeline of a macro, e.g. `| json | line_format "{{.msg}}"`.
+// A macro only holds pipeline stages and cannot reference other macros.
+func ParseMacro(pipeline string) (MultiStageExpr, error) {
+	expr, err := ParseLogSelector(macroSelector+" "+pipeline, false)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := expr.(*PipelineExpr)
+	if !ok || len(p.MultiStages) == 0 {
+		return nil, logqlmodel.NewParseError("a macro must only contain pipeline stages", 0, 0)
+	}
+	for _, stage := range p.MultiStages {
+		if m, ok := stage.(*MacroExpr); ok {
+			return nil, logqlmodel.NewParseError(fmt.Sprintf("a macro cannot reference another macro: %s%s", OpAt, m.Name), 0, 0)
+		}
+	}
+	return p.MultiStages, nil
+}
+
+// HasMacros returns true if the expression references any macro.
+func HasMacros(expr Expr) bool {
+	var found bool
+	expr.Walk(func(e Expr) {
+		if _, ok := e.(*MacroExpr); ok {
+			found = true
+		}
+	})
+	return found
+}
+
+// ExpandMacros replaces the macros referenced by the expression with the
+// stages of their pipeline. The pipelines are looked up by macro name in
+// macros. The expression is modified in place.
+func ExpandMacros(expr Expr, macros map[string]string) (Expr, error) {
+	var err error
+	expr.Walk(func(e Expr) {
+		p, ok := e.(*PipelineExpr)
+		if !ok || err != nil {
+			return
+		}
+		p.MultiStages, err = expandStages(p.MultiStages, macros)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+func expandStages(stages MultiStageExpr, macros map[string]string) (MultiStageExpr, error) {
+	expanded := make(MultiStageExpr, 0, len(stages))
+	for _, stage := range stages {
+		m, ok := stage.(*MacroExpr)
+		if !ok {
+			expanded = append(expanded, stage)
+			continue
+		}
+		pipeline, ok := macros[m.Name]
+		if !ok {
+			return nil, logqlmodel.NewParseError(fmt.Sprintf("undefined macro: %s%s", OpAt, m.Name), 0, 0)
+		}
+		// the pipeline is parsed for every reference, so that expanded
+		// stages are never shared.
+		macroStages, err := ParseMacro(pipeline)
+		if err != nil {
+			return nil, logqlmodel.NewParseError(fmt.Sprintf("invalid macro %s%s: %s", OpAt, m.Name, err), 0, 0)
+		}
+		expanded = append(expanded, macroStages...)
+	}
+	return expanded, nil
+}
@@ -50,6 +50,37 @@ var ParseTestCases = []struct {
 			},
 		),
 	},
+	{
+		in: `{ foo = "bar" } |= "error" | sample 0.01`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+			MultiStageExpr{
+				newLineFilterExpr(log.LineMatchEqual, "", "error"),
+				&SamplingExpr{Ratio: 0.01},
+			},
+		),
+	},
+	{
+		in: `{ foo = "bar" } |= "error" | @nginx_parse | status >= 500`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+			MultiStageExpr{
+				newLineFilterExpr(log.LineMatchEqual, "", "error"),
+				newMacroExpr("nginx_parse"),
+				&LabelFilterExpr{
+					LabelFilterer: log.NewNumericLabelFilter(log.LabelFilterGreaterThanOrEqual, "status", 500),
+				},
+			},
+		),
+	},
+	{
+		in:  `{ foo = "bar" } | sample 2`,
+		err: logqlmodel.NewParseError("invalid ratio for sample: 2, must be greater than 0 and at most 1", 0, 0),
+	},
+	{
+		in:  `{ foo = "bar" } | sample 0`,
+		err: logqlmodel.NewParseError("invalid ratio for sample: 0, must be greater than 0 and at most 1", 0, 0),
+	},
 	{
 		// test [12h] before filter expr
 		in: `count_over_time({foo="bar"}[12h] |= "error")`,
@@ -376,6 +407,216 @@ var ParseTestCases = []struct {
 		in:  `label_replace(rate({ foo = "bar" }[5m]),"foo","$1","bar","^^^^x43\\q")`,
 		err: logqlmodel.NewParseError("invalid regex in label_replace: error parsing regexp: invalid escape sequence: `\\q`", 0, 0),
 	},
+	{
+		in: `max_over_time(rate({ foo = "bar" }[5m])[1h:1m])`,
+		exp: newSubqueryExpr(
+			newRangeAggregationExpr(
+				&LogRange{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					Interval: 5 * time.Minute,
+				}, OpRangeTypeRate, nil, nil),
+			OpRangeTypeMax, subqueryRange{Range: time.Hour, Step: time.Minute}, nil, nil),
+	},
+	{
+		in: `quantile_over_time(0.99, sum by (foo) (rate({ foo = "bar" }[5m]))[1h:] offset 5m)`,
+		exp: newSubqueryExpr(
+			mustNewVectorAggregationExpr(
+				newRangeAggregationExpr(
+					&LogRange{
+						Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+						Interval: 5 * time.Minute,
+					}, OpRangeTypeRate, nil, nil),
+				OpTypeSum, &Grouping{Groups: []string{"foo"}}, nil),
+			OpRangeTypeQuantile, subqueryRange{Range: time.Hour}, newOffsetExpr(5*time.Minute), NewStringLabelFilter("0.99")),
+	},
+	{
+		in:  `rate(rate({ foo = "bar" }[5m])[1h:1m])`,
+		err: logqlmodel.NewParseError("invalid aggregation rate with subquery", 0, 0),
+	},
+	{
+		in:  `quantile_over_time(rate({ foo = "bar" }[5m])[1h:1m])`,
+		err: logqlmodel.NewParseError("parameter required for operation quantile_over_time", 0, 0),
+	},
+	{
+		in:  `max_over_time(rate({ foo = "bar" }[5m])[0s:1m])`,
+		err: logqlmodel.NewParseError("invalid subquery range 0s: must be greater than 0", 0, 0),
+	},
+	{
+		in:  `max_over_time(rate({ foo = "bar" }[5m])[1h:1x])`,
+		err: logqlmodel.NewParseError(`unknown unit "x" in duration "1x"`, 0, 40),
+	},
+	{
+		in:  `max_over_time({ foo = "bar" }[1h:1m])`,
+		err: logqlmodel.NewParseError("syntax error: unexpected SUBQUERY_RANGE", 0, 30),
+	},
+	{
+		in: `histogram_over_time({ foo = "bar" } | unwrap latency [5m]) by (foo)`,
+		exp: newRangeAggregationExpr(
+			&LogRange{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				Interval: 5 * time.Minute,
+				Unwrap:   &UnwrapExpr{Identifier: "latency"},
+			}, OpRangeTypeHistogram, &Grouping{Groups: []string{"foo"}}, nil),
+	},
+	{
+		in:  `histogram_over_time({ foo = "bar" }[5m])`,
+		err: logqlmodel.NewParseError("invalid aggregation histogram_over_time without unwrap", 0, 0),
+	},
+	{
+		in: `histogram_quantile(0.99, sum by (le) (histogram_over_time({ foo = "bar" } | unwrap latency [5m])))`,
+		exp: newHistogramQuantileExpr(
+			mustNewVectorAggregationExpr(
+				newRangeAggregationExpr(
+					&LogRange{
+						Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+						Interval: 5 * time.Minute,
+						Unwrap:   &UnwrapExpr{Identifier: "latency"},
+					}, OpRangeTypeHistogram, nil, nil),
+				OpTypeSum, &Grouping{Groups: []string{"le"}}, nil),
+			"0.99"),
+	},
+	{
+		in:  `histogram_quantile(1.5, histogram_over_time({ foo = "bar" } | unwrap latency [5m]))`,
+		err: logqlmodel.NewParseError("invalid quantile 1.5 for operation histogram_quantile: must be between 0 and 1", 0, 0),
+	},
+	{
+		in:  `histogram_quantile(0.5, 1)`,
+		err: logqlmodel.NewParseError("invalid histogram_quantile: expected a metric expression but got a literal", 0, 0),
+	},
+	{
+		in: `changes_over_time({ foo = "bar" } | unwrap latency [5m]) by (foo)`,
+		exp: newRangeAggregationExpr(
+			&LogRange{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				Interval: 5 * time.Minute,
+				Unwrap:   &UnwrapExpr{Identifier: "latency"},
+			}, OpRangeTypeChanges, &Grouping{Groups: []string{"foo"}}, nil),
+	},
+	{
+		in: `distinct_over_time({ foo = "bar" } | unwrap label(user_id) [5m])`,
+		exp: newRangeAggregationExpr(
+			&LogRange{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				Interval: 5 * time.Minute,
+				Unwrap:   &UnwrapExpr{Identifier: "user_id", Operation: OpConvLabel},
+			}, OpRangeTypeDistinct, nil, nil),
+	},
+	{
+		in:  `sum_over_time({ foo = "bar" } | unwrap label(user_id) [5m])`,
+		err: logqlmodel.NewParseError("invalid aggregation sum_over_time with unwrap label(), only changes_over_time and distinct_over_time are supported", 0, 0),
+	},
+	{
+		in:  `distinct_over_time({ foo = "bar" }[5m])`,
+		err: logqlmodel.NewParseError("invalid aggregation distinct_over_time without unwrap", 0, 0),
+	},
+	{
+		in: `count_values by (foo) ("status", distinct_over_time({ foo = "bar" } | unwrap status [5m]))`,
+		exp: newCountValuesExpr(
+			newRangeAggregationExpr(
+				&LogRange{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					Interval: 5 * time.Minute,
+					Unwrap:   &UnwrapExpr{Identifier: "status"},
+				}, OpRangeTypeDistinct, nil, nil),
+			"status", &Grouping{Groups: []string{"foo"}}),
+	},
+	{
+		in: `count_values("status", distinct_over_time({ foo = "bar" } | unwrap status [5m])) without (foo)`,
+		exp: newCountValuesExpr(
+			newRangeAggregationExpr(
+				&LogRange{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					Interval: 5 * time.Minute,
+					Unwrap:   &UnwrapExpr{Identifier: "status"},
+				}, OpRangeTypeDistinct, nil, nil),
+			"status", &Grouping{Groups: []string{"foo"}, Without: true}),
+	},
+	{
+		in:  `count_values("1status", rate({ foo = "bar" }[5m]))`,
+		err: logqlmodel.NewParseError(`invalid label name "1status" for operation count_values`, 0, 0),
+	},
+	{
+		in: `{app="a"} | join on(trace_id) within 30s {app="b"} |= "error"`,
+		exp: newJoinExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "a")}),
+			[]string{"trace_id"},
+			30*time.Second,
+			newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "b")}),
+				MultiStageExpr{newLineFilterExpr(log.LineMatchEqual, "", "error")},
+			),
+		),
+	},
+	{
+		in: `{app="a"} | logfmt | join on(trace_id, span_id) within 1m {app="b"}`,
+		exp: newJoinExpr(
+			newPipelineExpr(
+				newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "a")}),
+				MultiStageExpr{newLogfmtParserExpr(nil)},
+			),
+			[]string{"trace_id", "span_id"},
+			time.Minute,
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "b")}),
+		),
+	},
+	{
+		in:  `{app="a"} | join on(trace_id) within 0s {app="b"}`,
+		err: logqlmodel.NewParseError("invalid join window 0s: must be greater than 0", 0, 0),
+	},
+	{
+		in:  `count_over_time({app="a"} | join on(trace_id) within 30s {app="b"} [5m])`,
+		err: logqlmodel.NewParseError("syntax error: unexpected RANGE", 0, 68),
+	},
+	{
+		in: `count_over_time({ foo = "bar" }[1h] @ 1609746000)`,
+		exp: newRangeAggregationExpr(
+			&LogRange{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				Interval: time.Hour,
+				At:       &AtModifier{Timestamp: 1609746000000},
+			}, OpRangeTypeCount, nil, nil),
+	},
+	{
+		in: `count_over_time({ foo = "bar" }[1h] offset 1d @ 1609746000.5)`,
+		exp: newRangeAggregationExpr(
+			&LogRange{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				Interval: time.Hour,
+				Offset:   24 * time.Hour,
+				At:       &AtModifier{Timestamp: 1609746000500},
+			}, OpRangeTypeCount, nil, nil),
+	},
+	{
+		in: `sum_over_time({ foo = "bar" } | unwrap latency [5m] @ start() offset 1h)`,
+		exp: newRangeAggregationExpr(
+			&LogRange{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+				Interval: 5 * time.Minute,
+				Offset:   time.Hour,
+				At:       &AtModifier{StartOrEnd: OpAtStart},
+				Unwrap:   &UnwrapExpr{Identifier: "latency"},
+			}, OpRangeTypeSum, nil, nil),
+	},
+	{
+		in: `rate({ foo = "bar" } |= "error" [5m] @ end())`,
+		exp: newRangeAggregationExpr(
+			&LogRange{
+				Left: newPipelineExpr(
+					newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+					MultiStageExpr{newLineFilterExpr(log.LineMatchEqual, "", "error")},
+				),
+				Interval: 5 * time.Minute,
+				At:       &AtModifier{StartOrEnd: OpAtEnd},
+			}, OpRangeTypeRate, nil, nil),
+	},
+	{
+		in:  `max_over_time(rate({ foo = "bar" }[5m])[1h:1m] @ 1609746000)`,
+		err: logqlmodel.NewParseError("@ modifier is not supported with subqueries", 0, 0),
+	},
+	{
+		in:  `count_over_time({ foo = "bar" }[1h] @ start)`,
+		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or START or END", 1, 39),
+	},
 	{
 		in:  `rate({ foo = "bar" }[5)`,
 		err: logqlmodel.NewParseError("missing closing ']' in duration", 0, 21),
@@ -495,6 +736,33 @@ var ParseTestCases = []struct {
 			},
 		),
 	},
+	{
+		in: `{ foo = "bar" , word="foo"}|= word("timeout") != word("connection refused")|word="abc"|body=word("timeout")|body!=word("retry")`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar"), mustNewMatcher(labels.MatchEqual, "word", "foo")}),
+			MultiStageExpr{
+				newNestedLineFilterExpr(
+					newLineFilterExpr(log.LineMatchEqual, OpFilterWord, "timeout"),
+					newLineFilterExpr(log.LineMatchNotEqual, OpFilterWord, "connection refused"),
+				),
+				newLabelFilterExpr(log.NewStringLabelFilter(mustNewMatcher(labels.MatchEqual, "word", "abc"))),
+				newLabelFilterExpr(log.NewWordLabelFilter("timeout", "body", log.LabelFilterEqual)),
+				newLabelFilterExpr(log.NewWordLabelFilter("retry", "body", log.LabelFilterNotEqual)),
+			},
+		),
+	},
+	{
+		in: `{foo="bar"} |= word("timeout") or word("refused")`,
+		exp: newPipelineExpr(
+			newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "foo", "bar")}),
+			MultiStageExpr{
+				newOrLineFilter(
+					newLineFilterExpr(log.LineMatchEqual, OpFilterWord, "timeout"),
+					newLineFilterExpr(log.LineMatchEqual, OpFilterWord, "refused"),
+				),
+			},
+		),
+	},
 	// label filter for ip-matcher
 	{
 		in:  `{ foo = "bar" }|logfmt|addr>=ip("1.2.3.4")`,
@@ -1024,7 +1292,7 @@ var ParseTestCases = []struct {
 
 	{
 		in:  `{foo="bar"} |~`,
-		err: logqlmodel.NewParseError("syntax error: unexpected $end, expecting STRING or ip", 1, 15),
+		err: logqlmodel.NewParseError("syntax error: unexpected $end, expecting STRING or ip or WORD", 1, 15),
 	},
 
 	{
@@ -2889,7 +3157,7 @@ var ParseTestCases = []struct {
 	},
 	{
 		in:  `quantile_over_time(foo,{namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms| unwrap latency [5m])`,
-		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or { or (", 1, 20),
+		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER", 1, 20),
 	},
 	{
 		in:  `vector(abc)`,
@@ -3202,6 +3470,72 @@ var ParseTestCases = []struct {
 			},
 		},
 	},
+	{
+		in: `{app="foo"} | xml user="/event/user", id="//user/@id"`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newXMLExpressionParser([]log.LabelExtractionExpr{
+					log.NewLabelExtractionExpr("user", `/event/user`),
+					log.NewLabelExtractionExpr("id", `//user/@id`),
+				}),
+			},
+		},
+	},
+	{
+		in: `{app="foo"} | xml | level="error"`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newLabelParserExpr(OpParserTypeXML, ""),
+				&LabelFilterExpr{
+					LabelFilterer: log.NewStringLabelFilter(mustNewMatcher(labels.MatchEqual, "level", "error")),
+				},
+			},
+		},
+	},
+	{
+		in: `{app="foo"} | csv "ts,method,status"`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				newCSVParserExpr("ts,method,status", nil, nil),
+			},
+		},
+	},
+	{
+		in: `{app="foo"} | csv --delimiter="\t" --quote="'" "ts\tmethod\tstatus" verb="method", status`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{{Type: labels.MatchEqual, Name: "app", Value: "foo"}}),
+			MultiStages: MultiStageExpr{
+				&CSVParserExpr{
+					Header:    "ts\tmethod\tstatus",
+					Delimiter: "\t",
+					Quote:     "'",
+					Expressions: []log.LabelExtractionExpr{
+						log.NewLabelExtractionExpr("verb", "method"),
+						log.NewLabelExtractionExpr("status", "status"),
+					},
+				},
+			},
+		},
+	},
+	{
+		in:  `{app="foo"} | csv --strict "ts,method,status"`,
+		err: logqlmodel.NewParseError("syntax error: unexpected STRING, expecting =", 1, 28),
+	},
+	{
+		in:  `{app="foo"} | csv --keep-empty="x" "ts,method,status"`,
+		err: logqlmodel.NewParseError("invalid csv parser flag: --keep-empty", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | csv "ts,method,status" verb="path"`,
+		err: logqlmodel.NewParseError("invalid csv parser: csv column 'path' not found in header", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | xml user="/event//user"`,
+		err: logqlmodel.NewParseError("invalid xml parser: cannot parse expression [/event//user]: empty step in xml path '/event//user'", 0, 0),
+	},
 	{
 		in: `{app="foo"} |> "foo" or "bar" or "baz"`,
 		exp: &PipelineExpr{
@@ -3583,3 +3917,73 @@ func TestParseSampleExpr_String(t *testing.T) {
 		require.Equal(t, "{cluster=\"beep\", namespace=\"boop\"} | msg=~`\\w.*`", expr.String())
 	})
 }
+
+func TestExpandMacros(t *testing.T) {
+	macros := map[string]string{
+		"nginx_parse": `| json | line_format "{{.msg}}"`,
+		"errors":      `|= "error" | level="error"`,
+		"nested":      `| json | @errors`,
+		"correlate":   `| json | join on(id) within 1m {app="bar"}`,
+		"selector":    `{app="bar"}`,
+		"empty":       ``,
+	}
+
+	for _, tc := range []struct {
+		in  string
+		out string
+		err string
+	}{
+		{
+			in:  `{app="foo"} | @nginx_parse`,
+			out: `{app="foo"} | json | line_format "{{.msg}}"`,
+		},
+		{
+			in:  `sum by (level) (count_over_time({app="foo"} | @nginx_parse | @errors [5m]))`,
+			out: `sum by (level)(count_over_time({app="foo"} | json | line_format "{{.msg}}" |= "error" | level="error"[5m]))`,
+		},
+		{
+			in:  `{app="foo"} | @nginx_parse | join on(id) within 1m {app="bar"} | @errors`,
+			out: `{app="foo"} | json | line_format "{{.msg}}" | join on(id) within 1m {app="bar"} |= "error" | level="error"`,
+		},
+		{
+			in:  `{app="foo"} | json`,
+			out: `{app="foo"} | json`,
+		},
+		{
+			in:  `{app="foo"} | @unknown`,
+			err: "undefined macro: @unknown",
+		},
+		{
+			in:  `{app="foo"} | @nested`,
+			err: "a macro cannot reference another macro: @errors",
+		},
+		{
+			in:  `{app="foo"} | @correlate`,
+			err: "a macro must only contain pipeline stages",
+		},
+		{
+			in:  `{app="foo"} | @selector`,
+			err: "invalid macro @selector",
+		},
+		{
+			in:  `{app="foo"} | @empty`,
+			err: "a macro must only contain pipeline stages",
+		},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			expr, err := ParseExpr(tc.in)
+			require.NoError(t, err)
+			require.Equal(t, tc.in != `{app="foo"} | json`, HasMacros(expr))
+
+			expr, err = ExpandMacros(expr, macros)
+			if tc.err != "" {
+				require.ErrorIs(t, err, logqlmodel.ErrParse)
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.out, expr.String())
+			require.False(t, HasMacros(expr))
+		})
+	}
+}
@@ -70,6 +70,15 @@ func (e *PipelineExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: `{app="a"} | json | join on(trace_id) within 30s {app="b"} |= "error"`
+func (e *JoinExpr) Pretty(level int) string {
+	if !NeedSplit(e) {
+		return Indent(level) + e.String()
+	}
+
+	return fmt.Sprintf("%s\n%s%s\n%s", e.Left.Pretty(level), Indent(level+1), e.joinClause(), e.Right.Pretty(level+1))
+}
+
 // e.g: `|= "error" != "memcache" |= ip("192.168.0.1")`
 // NOTE: here `ip` is Op in this expression.
 func (e *LineFilterExpr) Pretty(level int) string {
@@ -109,6 +118,7 @@ func (e *LogfmtParserExpr) Pretty(level int) string {
 // `| regexp`
 // `| pattern`
 // `| unpack`
+// `| xml`
 func (e *LabelParserExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
@@ -121,6 +131,16 @@ func (e *KeepLabelsExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
 
+// e.g: | @nginx_parse
+func (e *MacroExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
+// e.g: | sample 0.01
+func (e *SamplingExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
 // e.g: | level!="error"
 func (e *LabelFilterExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
@@ -151,6 +171,16 @@ func (e *LogfmtExpressionParser) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
 }
 
+// e.g: | xml label="/path/to/element", another="//element/@attribute"
+func (e *XMLExpressionParser) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
+// e.g: | csv --delimiter=";" "method;path;status" verb="method"
+func (e *CSVParserExpr) Pretty(level int) string {
+	return commonPrefixIndent(level, e)
+}
+
 // e.g: sum_over_time({foo="bar"} | logfmt | unwrap bytes_processed [5m])
 func (e *UnwrapExpr) Pretty(level int) string {
 	s := Indent(level)
@@ -185,6 +215,10 @@ func (e *LogRange) Pretty(level int) string {
 		s += oe.Pretty(level)
 	}
 
+	if e.At != nil {
+		s += e.At.String()
+	}
+
 	return s
 }
 
@@ -226,13 +260,37 @@ func (e *RangeAggregationExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: max_over_time(rate({app="foo"}[5m])[1h:1m])
+func (e *SubqueryExpr) Pretty(level int) string {
+	s := Indent(level)
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Operation
+
+	s += "(\n"
+
+	if e.Params != nil {
+		s = fmt.Sprintf("%s%s%s,", s, Indent(level+1), fmt.Sprint(*e.Params))
+		s += "\n"
+	}
+
+	s += e.Left.Pretty(level + 1)
+	s += "\n" + Indent(level+1) + e.RangeString()
+
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
 // e.g:
 // sum(count_over_time({foo="bar"}[5m])) by (container)
 // topk(10, count_over_time({foo="bar"}[5m])) by (container)
 
 // Syntax: <aggr-op>([parameter,] <vector expression>) [without|by (<label list>)]
 // <aggr-op> - sum, avg, bottomk, topk, etc.
-// [parameters,] - optional params, used only by bottomk and topk for now.
+// [parameters,] - optional params, used only by bottomk, topk and count_values for now.
 // <vector expression> - vector on which aggregation is done.
 // [without|by (<label list)] - optional labels to aggregate either with `by` or `without` clause.
 func (e *VectorAggregationExpr) Pretty(level int) string {
@@ -250,6 +308,8 @@ func (e *VectorAggregationExpr) Pretty(level int) string {
 	// e.Params default value (0) can mean a legit param for topk and bottomk
 	case OpTypeBottomK, OpTypeTopK:
 		params = []string{fmt.Sprintf("%s%d", Indent(level+1), e.Params), left}
+	case OpTypeCountValues:
+		params = []string{Indent(level+1) + strconv.Quote(e.ValueLabel), left}
 
 	default:
 		if e.Params != 0 {
@@ -338,6 +398,26 @@ func (e *LabelReplaceExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: histogram_quantile(0.99, sum by (le) (histogram_over_time({app="foo"} | unwrap latency [5m])))
+func (e *HistogramQuantileExpr) Pretty(level int) string {
+	s := Indent(level)
+
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += OpHistogramQuantile
+
+	s += "(\n"
+
+	s += Indent(level+1) + strconv.FormatFloat(e.Quantile, 'f', -1, 64) + ",\n"
+	s += e.Left.Pretty(level + 1)
+
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
 // e.g: vector(5)
 func (e *VectorExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
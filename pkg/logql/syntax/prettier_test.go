@@ -35,6 +35,15 @@ func TestFormat(t *testing.T) {
   != "memcached"
   |= ip("192.168.0.1")
   | logfmt`,
+		},
+		{
+			name: "join",
+			in:   `{job="api-server"} | logfmt | join on(trace_id) within 30s {job="db"} |= "error"`,
+			exp: `{job="api-server"}
+  | logfmt
+  | join on(trace_id) within 30s
+  {job="db"}
+    |= "error"`,
 		},
 		{
 			name: "pipeline_line_format",
@@ -64,6 +73,14 @@ func TestFormat(t *testing.T) {
 			exp: `count_over_time(
   {job="loki", instance="localhost"}
     |= "error" [5m] offset 20m
+)`,
+		},
+		{
+			name: "aggregation_with_at_modifier",
+			in:   `count_over_time({job="loki", instance="localhost"}|= "error"[5m] @ end() offset 20m)`,
+			exp: `count_over_time(
+  {job="loki", instance="localhost"}
+    |= "error" [5m] offset 20m @ end()
 )`,
 		},
 		{
@@ -200,6 +217,76 @@ func TestFormat_LabelReplace(t *testing.T) {
 	}
 }
 
+func TestFormat_Subquery(t *testing.T) {
+	MaxCharsPerLine = 20
+
+	cases := []struct {
+		name string
+		in   string
+		exp  string
+	}{
+		{
+			name: "subquery",
+			in:   `max_over_time(rate({job="api-server",service="a:c"}|= "err" [5m])[1h:1m] offset 5m)`,
+			exp: `max_over_time(
+  rate(
+    {job="api-server", service="a:c"}
+      |= "err" [5m]
+  )
+  [1h:1m] offset 5m0s
+)`,
+		},
+		{
+			name: "subquery with parameter",
+			in:   `quantile_over_time(0.99, rate({job="api-server",service="a:c"}|= "err" [5m])[1h:])`,
+			exp: `quantile_over_time(
+  0.99,
+  rate(
+    {job="api-server", service="a:c"}
+      |= "err" [5m]
+  )
+  [1h:]
+)`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			expr, err := ParseExpr(c.in)
+			require.NoError(t, err)
+			got := Prettify(expr)
+			assert.Equal(t, c.exp, got)
+
+			// the prettified query must still be parseable
+			_, err = ParseExpr(got)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFormat_HistogramQuantile(t *testing.T) {
+	MaxCharsPerLine = 20
+
+	in := `histogram_quantile(0.99, sum by (le) (histogram_over_time({job="api-server"} | unwrap latency [5m])))`
+	exp := `histogram_quantile(
+  0.99,
+  sum by (le)(
+    histogram_over_time(
+      {job="api-server"}
+        | unwrap latency [5m]
+    )
+  )
+)`
+
+	expr, err := ParseExpr(in)
+	require.NoError(t, err)
+	got := Prettify(expr)
+	assert.Equal(t, exp, got)
+
+	_, err = ParseExpr(got)
+	require.NoError(t, err)
+}
+
 func TestFormat_BinOp(t *testing.T) {
 	MaxCharsPerLine = 20
 
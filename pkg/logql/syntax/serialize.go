@@ -35,11 +35,14 @@ const (
 	Binary              = "binary"
 	Bytes               = "bytes"
 	And                 = "and"
+	AtMillis            = "at_millis"
+	AtStartOrEnd        = "at_start_or_end"
 	Card                = "cardinality"
 	Dst                 = "dst"
 	Duration            = "duration"
 	Groups              = "groups"
 	GroupingField       = "grouping"
+	HistogramQuantile   = "histogram_quantile"
 	Include             = "include"
 	Identifier          = "identifier"
 	Inner               = "inner"
@@ -60,15 +63,19 @@ const (
 	Params              = "params"
 	Pattern             = "pattern"
 	PostFilterers       = "post_filterers"
+	Quantile            = "quantile"
 	Range               = "range"
 	RangeAgg            = "range_agg"
+	RangeNanos          = "range_nanos"
 	Raw                 = "raw"
 	RegexField          = "regex"
 	Replacement         = "replacement"
 	ReturnBool          = "return_bool"
 	RHS                 = "rhs"
 	Src                 = "src"
+	StepNanos           = "step_nanos"
 	StringField         = "string"
+	Subquery            = "subquery"
 	NoopField           = "noop"
 	Type                = "type"
 	Unwrap              = "unwrap"
@@ -77,6 +84,7 @@ const (
 	VectorAgg           = "vector_agg"
 	VectorMatchingField = "vector_matching"
 	Without             = "without"
+	WordField           = "word"
 )
 
 func DecodeJSON(raw string) (Expr, error) {
@@ -96,6 +104,10 @@ func DecodeJSON(raw string) (Expr, error) {
 		return decodeVector(iter)
 	case LabelReplace:
 		return decodeLabelReplace(iter)
+	case Subquery:
+		return decodeSubquery(iter)
+	case HistogramQuantile:
+		return decodeHistogramQuantile(iter)
 	case LogSelector:
 		return decodeLogSelector(iter)
 	default:
@@ -159,6 +171,12 @@ func (v *JSONSerializer) VisitVectorAggregation(e *VectorAggregationExpr) {
 	v.WriteObjectField(Op)
 	v.WriteString(e.Operation)
 
+	if e.ValueLabel != "" {
+		v.WriteMore()
+		v.WriteObjectField(Label)
+		v.WriteString(e.ValueLabel)
+	}
+
 	if e.Grouping != nil {
 		v.WriteMore()
 		v.WriteObjectField(GroupingField)
@@ -213,6 +231,15 @@ func (v *JSONSerializer) VisitLogRange(e *LogRange) {
 	v.WriteObjectField(OffsetNanos)
 	v.WriteInt64(int64(e.Offset))
 
+	if e.At != nil {
+		v.WriteMore()
+		v.WriteObjectField(AtMillis)
+		v.WriteInt64(e.At.Timestamp)
+		v.WriteMore()
+		v.WriteObjectField(AtStartOrEnd)
+		v.WriteString(e.At.StartOrEnd)
+	}
+
 	// Serialize log selector pipeline as string.
 	v.WriteMore()
 	v.WriteObjectField(LogSelector)
@@ -228,6 +255,60 @@ func (v *JSONSerializer) VisitLogRange(e *LogRange) {
 	v.Flush()
 }
 
+func (v *JSONSerializer) VisitSubquery(e *SubqueryExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Subquery)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Op)
+	v.WriteString(e.Operation)
+
+	if e.Params != nil {
+		v.WriteMore()
+		v.WriteObjectField(Params)
+		v.WriteFloat64(*e.Params)
+	}
+
+	v.WriteMore()
+	v.WriteObjectField(RangeNanos)
+	v.WriteInt64(int64(e.Range))
+
+	v.WriteMore()
+	v.WriteObjectField(StepNanos)
+	v.WriteInt64(int64(e.Step))
+
+	v.WriteMore()
+	v.WriteObjectField(OffsetNanos)
+	v.WriteInt64(int64(e.Offset))
+
+	v.WriteMore()
+	v.WriteObjectField(Inner)
+	e.Left.Accept(v)
+
+	v.WriteObjectEnd()
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
+func (v *JSONSerializer) VisitHistogramQuantile(e *HistogramQuantileExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(HistogramQuantile)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Quantile)
+	v.WriteFloat64(e.Quantile)
+
+	v.WriteMore()
+	v.WriteObjectField(Inner)
+	e.Left.Accept(v)
+
+	v.WriteObjectEnd()
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
 func (v *JSONSerializer) VisitLabelReplace(e *LabelReplaceExpr) {
 	v.WriteObjectStart()
 
@@ -304,8 +385,18 @@ func (v *JSONSerializer) VisitPipeline(e *PipelineExpr) {
 	v.Flush()
 }
 
+func (v *JSONSerializer) VisitJoin(e *JoinExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(LogSelector)
+	encodeLogSelector(v.Stream, e)
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
 // Below are StageExpr visitors that we are skipping since a pipeline is
 // serialized as a string.
+func (*JSONSerializer) VisitCSVParser(*CSVParserExpr)                       {}
 func (*JSONSerializer) VisitDecolorize(*DecolorizeExpr)                     {}
 func (*JSONSerializer) VisitDropLabels(*DropLabelsExpr)                     {}
 func (*JSONSerializer) VisitJSONExpressionParser(*JSONExpressionParser)     {}
@@ -317,6 +408,9 @@ func (*JSONSerializer) VisitLineFilter(*LineFilterExpr)                     {}
 func (*JSONSerializer) VisitLineFmt(*LineFmtExpr)                           {}
 func (*JSONSerializer) VisitLogfmtExpressionParser(*LogfmtExpressionParser) {}
 func (*JSONSerializer) VisitLogfmtParser(*LogfmtParserExpr)                 {}
+func (*JSONSerializer) VisitMacro(*MacroExpr)                               {}
+func (*JSONSerializer) VisitSampling(*SamplingExpr)                         {}
+func (*JSONSerializer) VisitXMLExpressionParser(*XMLExpressionParser)       {}
 
 func encodeGrouping(s *jsoniter.Stream, g *Grouping) {
 	s.WriteObjectStart()
@@ -550,6 +644,25 @@ func encodeLabelFilter(s *jsoniter.Stream, filter log.LabelFilterer) {
 
 		s.WriteObjectEnd()
 
+		s.WriteObjectEnd()
+	case *log.WordLabelFilter:
+		s.WriteObjectStart()
+		s.WriteObjectField(WordField)
+
+		s.WriteObjectStart()
+		s.WriteObjectField(Type)
+		s.WriteInt(int(concrete.Ty))
+
+		s.WriteMore()
+		s.WriteObjectField(Label)
+		s.WriteString(concrete.Label)
+
+		s.WriteMore()
+		s.WriteObjectField(Pattern)
+		s.WriteString(concrete.Pattern)
+
+		s.WriteObjectEnd()
+
 		s.WriteObjectEnd()
 	}
 }
@@ -660,6 +773,22 @@ func decodeLabelFilter(iter *jsoniter.Iterator) log.LabelFilterer {
 				}
 			}
 			filter = log.NewIPLabelFilter(pattern, label, t)
+
+		case WordField:
+			var label string
+			var pattern string
+			var t log.LabelFilterType
+			for k := iter.ReadObject(); k != ""; k = iter.ReadObject() {
+				switch k {
+				case Pattern:
+					pattern = iter.ReadString()
+				case Label:
+					label = iter.ReadString()
+				case Type:
+					t = log.LabelFilterType(iter.ReadInt())
+				}
+			}
+			filter = log.NewWordLabelFilter(pattern, label, t)
 		}
 	}
 
@@ -717,6 +846,10 @@ func decodeSample(iter *jsoniter.Iterator) (SampleExpr, error) {
 			expr, err = decodeVector(iter)
 		case LabelReplace:
 			expr, err = decodeLabelReplace(iter)
+		case Subquery:
+			expr, err = decodeSubquery(iter)
+		case HistogramQuantile:
+			expr, err = decodeHistogramQuantile(iter)
 		default:
 			return nil, fmt.Errorf("unknown sample expression type: %s", key)
 		}
@@ -827,6 +960,8 @@ func decodeVectorAgg(iter *jsoniter.Iterator) (*VectorAggregationExpr, error) {
 			expr.Operation = iter.ReadString()
 		case Params:
 			expr.Params = iter.ReadInt()
+		case Label:
+			expr.ValueLabel = iter.ReadString()
 		case GroupingField:
 			expr.Grouping, err = decodeGrouping(iter)
 		case Inner:
@@ -870,6 +1005,16 @@ func decodeLogRange(iter *jsoniter.Iterator) (*LogRange, error) {
 			expr.Interval = time.Duration(iter.ReadInt64())
 		case OffsetNanos:
 			expr.Offset = time.Duration(iter.ReadInt64())
+		case AtMillis:
+			if expr.At == nil {
+				expr.At = &AtModifier{}
+			}
+			expr.At.Timestamp = iter.ReadInt64()
+		case AtStartOrEnd:
+			if expr.At == nil {
+				expr.At = &AtModifier{}
+			}
+			expr.At.StartOrEnd = iter.ReadString()
 		case Unwrap:
 			expr.Unwrap = decodeUnwrap(iter)
 		}
@@ -904,6 +1049,53 @@ func decodeLabelReplace(iter *jsoniter.Iterator) (*LabelReplaceExpr, error) {
 	return mustNewLabelReplaceExpr(left, dst, replacement, src, regex), nil
 }
 
+func decodeSubquery(iter *jsoniter.Iterator) (*SubqueryExpr, error) {
+	expr := &SubqueryExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Op:
+			expr.Operation = iter.ReadString()
+		case Params:
+			tmp := iter.ReadFloat64()
+			expr.Params = &tmp
+		case RangeNanos:
+			expr.Range = time.Duration(iter.ReadInt64())
+		case StepNanos:
+			expr.Step = time.Duration(iter.ReadInt64())
+		case OffsetNanos:
+			expr.Offset = time.Duration(iter.ReadInt64())
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return expr, err
+}
+
+func decodeHistogramQuantile(iter *jsoniter.Iterator) (*HistogramQuantileExpr, error) {
+	expr := &HistogramQuantileExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Quantile:
+			expr.Quantile = iter.ReadFloat64()
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return expr, err
+}
+
 func decodeLiteral(iter *jsoniter.Iterator) (*LiteralExpr, error) {
 	expr := &LiteralExpr{}
 
@@ -42,6 +42,30 @@ func TestJSONSerializationRoundTrip(t *testing.T) {
 		"label replace": {
 			query: `label_replace(vector(0.000000),"foo","bar","","")`,
 		},
+		"subquery": {
+			query: `quantile_over_time(0.99,sum by (app)(rate({app="foo"}[5m]))[1h:1m] offset 5m)`,
+		},
+		"subquery without step": {
+			query: `max_over_time(rate({app="foo"}[5m])[1h:])`,
+		},
+		"histogram quantile": {
+			query: `histogram_quantile(0.9,sum by (le)(histogram_over_time({app="foo"} | unwrap latency [5m])))`,
+		},
+		"count values": {
+			query: `count_values by (app) ("value", distinct_over_time({app="foo"} | unwrap latency [5m]))`,
+		},
+		"join": {
+			query: `{app="foo"} | logfmt | join on(trace_id) within 30s {app="bar"} |= "error"`,
+		},
+		"word filters": {
+			query: `{app="foo"} |= word("timeout") or word("refused") | body = word("upstream timeout") | body != word("retry")`,
+		},
+		"sampling": {
+			query: `sum by (app) (count_over_time({app="foo"} |= "error" | sample 0.01 [5m]))`,
+		},
+		"at modifier": {
+			query: `sum by (app) (count_over_time({app="foo"}[1h] offset 1d @ 1609746000)) / sum by (app) (count_over_time({app="foo"}[1h] @ end()))`,
+		},
 		"filters with bytes": {
 			query: `{app="foo"} |= "bar" | json | ( status_code <500 or ( status_code>200 , size>=2.5KiB ) )`,
 		},
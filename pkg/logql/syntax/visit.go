@@ -14,21 +14,25 @@ type RootVisitor interface {
 
 type SampleExprVisitor interface {
 	VisitBinOp(*BinOpExpr)
+	VisitHistogramQuantile(*HistogramQuantileExpr)
 	VisitVectorAggregation(*VectorAggregationExpr)
 	VisitRangeAggregation(*RangeAggregationExpr)
 	VisitLabelReplace(*LabelReplaceExpr)
 	VisitLiteral(*LiteralExpr)
+	VisitSubquery(*SubqueryExpr)
 	VisitVector(*VectorExpr)
 }
 
 type LogSelectorExprVisitor interface {
 	VisitMatchers(*MatchersExpr)
 	VisitPipeline(*PipelineExpr)
+	VisitJoin(*JoinExpr)
 	VisitLiteral(*LiteralExpr)
 	VisitVector(*VectorExpr)
 }
 
 type StageExprVisitor interface {
+	VisitCSVParser(*CSVParserExpr)
 	VisitDecolorize(*DecolorizeExpr)
 	VisitDropLabels(*DropLabelsExpr)
 	VisitJSONExpressionParser(*JSONExpressionParser)
@@ -40,14 +44,20 @@ type StageExprVisitor interface {
 	VisitLineFmt(*LineFmtExpr)
 	VisitLogfmtExpressionParser(*LogfmtExpressionParser)
 	VisitLogfmtParser(*LogfmtParserExpr)
+	VisitMacro(*MacroExpr)
+	VisitSampling(*SamplingExpr)
+	VisitXMLExpressionParser(*XMLExpressionParser)
 }
 
 var _ RootVisitor = &DepthFirstTraversal{}
 
 type DepthFirstTraversal struct {
 	VisitBinOpFn                  func(v RootVisitor, e *BinOpExpr)
+	VisitCSVParserFn              func(v RootVisitor, e *CSVParserExpr)
 	VisitDecolorizeFn             func(v RootVisitor, e *DecolorizeExpr)
 	VisitDropLabelsFn             func(v RootVisitor, e *DropLabelsExpr)
+	VisitHistogramQuantileFn      func(v RootVisitor, e *HistogramQuantileExpr)
+	VisitJoinFn                   func(v RootVisitor, e *JoinExpr)
 	VisitJSONExpressionParserFn   func(v RootVisitor, e *JSONExpressionParser)
 	VisitKeepLabelFn              func(v RootVisitor, e *KeepLabelsExpr)
 	VisitLabelFilterFn            func(v RootVisitor, e *LabelFilterExpr)
@@ -60,11 +70,15 @@ type DepthFirstTraversal struct {
 	VisitLogRangeFn               func(v RootVisitor, e *LogRange)
 	VisitLogfmtExpressionParserFn func(v RootVisitor, e *LogfmtExpressionParser)
 	VisitLogfmtParserFn           func(v RootVisitor, e *LogfmtParserExpr)
+	VisitMacroFn                  func(v RootVisitor, e *MacroExpr)
 	VisitMatchersFn               func(v RootVisitor, e *MatchersExpr)
 	VisitPipelineFn               func(v RootVisitor, e *PipelineExpr)
 	VisitRangeAggregationFn       func(v RootVisitor, e *RangeAggregationExpr)
+	VisitSamplingFn               func(v RootVisitor, e *SamplingExpr)
+	VisitSubqueryFn               func(v RootVisitor, e *SubqueryExpr)
 	VisitVectorFn                 func(v RootVisitor, e *VectorExpr)
 	VisitVectorAggregationFn      func(v RootVisitor, e *VectorAggregationExpr)
+	VisitXMLExpressionParserFn    func(v RootVisitor, e *XMLExpressionParser)
 }
 
 // VisitBinOp implements RootVisitor.
@@ -80,6 +94,16 @@ func (v *DepthFirstTraversal) VisitBinOp(e *BinOpExpr) {
 	}
 }
 
+// VisitCSVParser implements RootVisitor.
+func (v *DepthFirstTraversal) VisitCSVParser(e *CSVParserExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitCSVParserFn != nil {
+		v.VisitCSVParserFn(v, e)
+	}
+}
+
 // VisitDecolorize implements RootVisitor.
 func (v *DepthFirstTraversal) VisitDecolorize(e *DecolorizeExpr) {
 	if e == nil {
@@ -100,6 +124,31 @@ func (v *DepthFirstTraversal) VisitDropLabels(e *DropLabelsExpr) {
 	}
 }
 
+// VisitHistogramQuantile implements RootVisitor.
+func (v *DepthFirstTraversal) VisitHistogramQuantile(e *HistogramQuantileExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitHistogramQuantileFn != nil {
+		v.VisitHistogramQuantileFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
+// VisitJoin implements RootVisitor.
+func (v *DepthFirstTraversal) VisitJoin(e *JoinExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitJoinFn != nil {
+		v.VisitJoinFn(v, e)
+	} else {
+		e.Left.Accept(v)
+		e.Right.Accept(v)
+	}
+}
+
 // VisitJSONExpressionParser implements RootVisitor.
 func (v *DepthFirstTraversal) VisitJSONExpressionParser(e *JSONExpressionParser) {
 	if e == nil {
@@ -266,6 +315,38 @@ func (v *DepthFirstTraversal) VisitRangeAggregation(e *RangeAggregationExpr) {
 	}
 }
 
+// VisitMacro implements RootVisitor.
+func (v *DepthFirstTraversal) VisitMacro(e *MacroExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitMacroFn != nil {
+		v.VisitMacroFn(v, e)
+	}
+}
+
+// VisitSampling implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSampling(e *SamplingExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSamplingFn != nil {
+		v.VisitSamplingFn(v, e)
+	}
+}
+
+// VisitSubquery implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSubquery(e *SubqueryExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSubqueryFn != nil {
+		v.VisitSubqueryFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
 // VisitVector implements RootVisitor.
 func (v *DepthFirstTraversal) VisitVector(e *VectorExpr) {
 	if e == nil {
@@ -287,3 +368,13 @@ func (v *DepthFirstTraversal) VisitVectorAggregation(e *VectorAggregationExpr) {
 		e.Left.Accept(v)
 	}
 }
+
+// VisitXMLExpressionParser implements RootVisitor.
+func (v *DepthFirstTraversal) VisitXMLExpressionParser(e *XMLExpressionParser) {
+	if e == nil {
+		return
+	}
+	if v.VisitXMLExpressionParserFn != nil {
+		v.VisitXMLExpressionParserFn(v, e)
+	}
+}
@@ -246,6 +246,12 @@ func (m MockDownstreamer) Downstream(ctx context.Context, queries []DownstreamQu
 		}
 		return []logqlmodel.Result{{Data: matrix}}, nil
 	}
+	if matrix, ok := results[0].Data.(DistinctSketchMatrix); ok {
+		for _, m := range results[1:] {
+			matrix, _ = matrix.Merge(m.Data.(DistinctSketchMatrix))
+		}
+		return []logqlmodel.Result{{Data: matrix}}, nil
+	}
 	return results, nil
 }
 
@@ -389,6 +389,10 @@ func applyPathPrefixDefaults(r, defaults *ConfigWrapper) {
 			r.Ingester.WAL.Dir = fmt.Sprintf("%s/wal", prefix)
 		}
 
+		if r.Ingester.QuerySnapshots.Dir == defaults.Ingester.QuerySnapshots.Dir {
+			r.Ingester.QuerySnapshots.Dir = fmt.Sprintf("%s/query-snapshots", prefix)
+		}
+
 		if r.CompactorConfig.WorkingDirectory == defaults.CompactorConfig.WorkingDirectory {
 			r.CompactorConfig.WorkingDirectory = fmt.Sprintf("%s/compactor", prefix)
 		}
@@ -44,6 +44,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/compactor/client/grpc"
 	"github.com/grafana/loki/v3/pkg/compactor/deletion"
 	"github.com/grafana/loki/v3/pkg/compactor/generationnumber"
+	"github.com/grafana/loki/v3/pkg/compression"
 	"github.com/grafana/loki/v3/pkg/distributor"
 	"github.com/grafana/loki/v3/pkg/indexgateway"
 	"github.com/grafana/loki/v3/pkg/ingester"
@@ -70,6 +71,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
 	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/dictionaries"
 	"github.com/grafana/loki/v3/pkg/storage/stores/series/index"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/bloomshipper"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper"
@@ -327,6 +329,13 @@ func (t *Loki) initDistributor() (services.Service, error) {
 	}
 
 	var err error
+	if deadLetterStore := t.Cfg.Distributor.DeadLetter.Store; deadLetterStore != "" {
+		t.Cfg.Distributor.DeadLetter.ObjectClient, err = storage.NewObjectClient(deadLetterStore, "dead-letter-store", t.Cfg.StorageConfig, t.ClientMetrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dead-letter store object client: %w", err)
+		}
+	}
+
 	logger := log.With(util_log.Logger, "component", "distributor")
 	t.distributor, err = distributor.New(
 		t.Cfg.Distributor,
@@ -362,16 +371,31 @@ func (t *Loki) initDistributor() (services.Service, error) {
 
 	lokiPushHandler := httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.PushHandler))
 	otlpPushHandler := httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.OTLPPushHandler))
+	elasticsearchBulkHandler := httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.ElasticsearchBulkHandler))
+	splunkHECHandler := httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.SplunkHECHandler))
+	demotedLabelsHandler := httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.DemotedLabelsHandler))
 
 	t.Server.HTTP.Path("/distributor/ring").Methods("GET", "POST").Handler(t.distributor)
+	t.Server.HTTP.Path("/distributor/demoted_labels").Methods("GET").Handler(demotedLabelsHandler)
 
 	if t.Cfg.InternalServer.Enable {
 		t.InternalServer.HTTP.Path("/distributor/ring").Methods("GET", "POST").Handler(t.distributor)
+		t.InternalServer.HTTP.Path("/distributor/demoted_labels").Methods("GET").Handler(demotedLabelsHandler)
 	}
 
 	t.Server.HTTP.Path("/api/prom/push").Methods("POST").Handler(lokiPushHandler)
 	t.Server.HTTP.Path("/loki/api/v1/push").Methods("POST").Handler(lokiPushHandler)
 	t.Server.HTTP.Path("/otlp/v1/logs").Methods("POST").Handler(otlpPushHandler)
+	t.Server.HTTP.Path("/elasticsearch").Methods("GET", "HEAD").HandlerFunc(t.distributor.ElasticsearchInfoHandler)
+	t.Server.HTTP.Path("/elasticsearch/").Methods("GET", "HEAD").HandlerFunc(t.distributor.ElasticsearchInfoHandler)
+	t.Server.HTTP.Path("/elasticsearch/_bulk").Methods("POST", "PUT").Handler(elasticsearchBulkHandler)
+	t.Server.HTTP.Path("/elasticsearch/{index}/_bulk").Methods("POST", "PUT").Handler(elasticsearchBulkHandler)
+	t.Server.HTTP.Path("/services/collector/health").Methods("GET").HandlerFunc(t.distributor.SplunkHECHealthHandler)
+	t.Server.HTTP.Path("/services/collector").Methods("POST").Handler(splunkHECHandler)
+	t.Server.HTTP.Path("/services/collector/event").Methods("POST").Handler(splunkHECHandler)
+	t.Server.HTTP.Path("/services/collector/event/1.0").Methods("POST").Handler(splunkHECHandler)
+	t.Server.HTTP.Path("/distributor/dead_letter").Methods("GET").Handler(httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.DeadLetterHandler)))
+	t.Server.HTTP.Path("/distributor/dead_letter/replay").Methods("POST").Handler(httpPushHandlerMiddleware.Wrap(http.HandlerFunc(t.distributor.DeadLetterReplayHandler)))
 	return t.distributor, nil
 }
 
@@ -600,6 +624,13 @@ func (t *Loki) initIngester() (_ services.Service, err error) {
 		level.Warn(util_log.Logger).Log("msg", "The config setting shutdown marker path is not set. The /ingester/prepare_shutdown endpoint won't work")
 	}
 
+	if err := t.initZstdDictionaries(); err != nil {
+		return nil, err
+	}
+	if t.zstdDictionaries != nil {
+		t.Cfg.Ingester.ZstdDictionaries = t.zstdDictionaries
+	}
+
 	t.Ingester, err = ingester.New(t.Cfg.Ingester, t.Cfg.IngesterClient, t.Store, t.Overrides, t.tenantConfigs, prometheus.DefaultRegisterer, t.Cfg.Distributor.WriteFailuresLogging, t.Cfg.MetricsNamespace, logger, t.UsageTracker, t.ring, t.partitionRingWatcher)
 	if err != nil {
 		return
@@ -745,6 +776,24 @@ func (t *Loki) initTableManager() (services.Service, error) {
 	return t.tableManager, nil
 }
 
+// initZstdDictionaries creates the store of the zstd dictionaries, shared by the
+// modules, and sets it as the loader of the dictionaries of the chunks.
+func (t *Loki) initZstdDictionaries() error {
+	cfg := t.Cfg.StorageConfig.ZstdDictionaries
+	if !cfg.Enabled() || t.zstdDictionaries != nil {
+		return nil
+	}
+
+	var err error
+	cfg.ObjectClient, err = storage.NewObjectClient(cfg.Store, "zstd-dictionaries", t.Cfg.StorageConfig, t.ClientMetrics)
+	if err != nil {
+		return fmt.Errorf("failed to create zstd dictionaries store object client: %w", err)
+	}
+	t.zstdDictionaries = dictionaries.NewStore(cfg, util_log.Logger)
+	compression.SetZstdDictionaryLoader(t.zstdDictionaries.Loader())
+	return nil
+}
+
 func (t *Loki) initStore() (services.Service, error) {
 	// Set configs pertaining to object storage based indices
 	if config.UsingObjectStorageIndex(t.Cfg.SchemaConfig.Configs) {
@@ -755,6 +804,11 @@ func (t *Loki) initStore() (services.Service, error) {
 		}
 	}
 
+	// The chunks compressed with the zstd-dict encoding load their dictionary from the store.
+	if err := t.initZstdDictionaries(); err != nil {
+		return nil, err
+	}
+
 	store, err := storage.NewStore(t.Cfg.StorageConfig, t.Cfg.ChunkStoreConfig, t.Cfg.SchemaConfig, t.Overrides, t.ClientMetrics, prometheus.DefaultRegisterer, util_log.Logger, t.Cfg.MetricsNamespace)
 	if err != nil {
 		return nil, err
@@ -1191,6 +1245,7 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	t.Server.HTTP.Path("/api/prom/label").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/series").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/loki/api/v1/query_macros").Methods("GET", "POST").Handler(t.HTTPAuthMiddleware.Wrap(queryMacrosHandler(t.Overrides)))
 
 	// Only register tailing requests if this process does not act as a Querier
 	// If this process is also a Querier the Querier will register the tail endpoints.
@@ -1439,6 +1494,16 @@ func (t *Loki) initCompactor() (services.Service, error) {
 		}
 	}
 
+	if t.Cfg.CompactorConfig.ZstdDictionaryTraining.Enabled {
+		if err := t.initZstdDictionaries(); err != nil {
+			return nil, err
+		}
+		if t.zstdDictionaries == nil {
+			return nil, fmt.Errorf("store.zstd-dictionaries.store should be configured when the zstd dictionary training is enabled")
+		}
+		t.Cfg.CompactorConfig.ZstdDictionaryTraining.Store = t.zstdDictionaries
+	}
+
 	t.compactor, err = compactor.NewCompactor(t.Cfg.CompactorConfig, objectClients, deleteRequestStoreClient, t.Cfg.SchemaConfig, t.Overrides, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
 	if err != nil {
 		return nil, err
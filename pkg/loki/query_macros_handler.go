@@ -0,0 +1,86 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/util/server"
+)
+
+type queryMacrosLimits interface {
+	QueryMacros(ctx context.Context, userID string) map[string]string
+}
+
+// queryMacrosHandler lists the query macros of the tenant along with the
+// result of their validation. When the `pipeline` parameter is set, only that
+// pipeline is validated, which allows to check a macro before defining it.
+func queryMacrosHandler(limits queryMacrosLimits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			statusCode = http.StatusOK
+			status     = "success"
+			macros     = []QueryMacro{}
+		)
+
+		if pipeline := r.FormValue("pipeline"); pipeline != "" {
+			macro := validateQueryMacro(r.FormValue("name"), pipeline)
+			if macro.Err != "" {
+				statusCode = http.StatusBadRequest
+				status = "invalid-macro"
+			}
+			macros = append(macros, macro)
+		} else {
+			userID, err := tenant.TenantID(r.Context())
+			if err != nil {
+				server.WriteError(err, w)
+				return
+			}
+			for name, pipeline := range limits.QueryMacros(r.Context(), userID) {
+				macros = append(macros, validateQueryMacro(name, pipeline))
+			}
+			slices.SortFunc(macros, func(a, b QueryMacro) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+		}
+
+		resp := QueryMacrosResponse{
+			Status: status,
+			Data:   macros,
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(statusCode)
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			server.WriteError(err, w)
+		}
+	}
+}
+
+func validateQueryMacro(name, pipeline string) QueryMacro {
+	macro := QueryMacro{Name: name, Pipeline: pipeline}
+	stages, err := syntax.ParseMacro(pipeline)
+	if err != nil {
+		macro.Err = err.Error()
+		return macro
+	}
+	macro.Pipeline = strings.TrimSpace(stages.String())
+	return macro
+}
+
+type QueryMacrosResponse struct {
+	Status string       `json:"status"`
+	Data   []QueryMacro `json:"data"`
+}
+
+type QueryMacro struct {
+	Name     string `json:"name,omitempty"`
+	Pipeline string `json:"pipeline"`
+	Err      string `json:"error,omitempty"`
+}
@@ -0,0 +1,86 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQueryMacrosLimits map[string]map[string]string
+
+func (f fakeQueryMacrosLimits) QueryMacros(_ context.Context, userID string) map[string]string {
+	return f[userID]
+}
+
+func Test_queryMacrosHandlerResponse(t *testing.T) {
+	limits := fakeQueryMacrosLimits{
+		"tenant": {
+			"nginx_parse": `| json|line_format "{{.msg}}"`,
+			"broken":      `| json |`,
+		},
+	}
+
+	cases := []struct {
+		name               string
+		params             url.Values
+		expectedStatusCode int
+		expected           QueryMacrosResponse
+	}{
+		{
+			name:               "list",
+			expectedStatusCode: http.StatusOK,
+			expected: QueryMacrosResponse{
+				Status: "success",
+				Data: []QueryMacro{
+					{Name: "broken", Pipeline: `| json |`, Err: "parse error at line 1, col 29: syntax error: unexpected $end"},
+					{Name: "nginx_parse", Pipeline: `| json | line_format "{{.msg}}"`},
+				},
+			},
+		},
+		{
+			name:               "valid-pipeline",
+			params:             url.Values{"name": {"logfmt"}, "pipeline": {`| logfmt|level="error"`}},
+			expectedStatusCode: http.StatusOK,
+			expected: QueryMacrosResponse{
+				Status: "success",
+				Data:   []QueryMacro{{Name: "logfmt", Pipeline: `| logfmt | level="error"`}},
+			},
+		},
+		{
+			name:               "invalid-pipeline",
+			params:             url.Values{"pipeline": {`| json | @nginx_parse`}},
+			expectedStatusCode: http.StatusBadRequest,
+			expected: QueryMacrosResponse{
+				Status: "invalid-macro",
+				Data:   []QueryMacro{{Pipeline: `| json | @nginx_parse`, Err: "parse error : a macro cannot reference another macro: @nginx_parse"}},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "http://localhost:808?"+tc.params.Encode(), nil)
+			require.NoError(t, err)
+			req = req.WithContext(user.InjectOrgID(req.Context(), "tenant"))
+
+			w := httptest.NewRecorder()
+
+			queryMacrosHandler(limits)(w, req)
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+
+			var got QueryMacrosResponse
+
+			err = json.NewDecoder(w.Body).Decode(&got)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
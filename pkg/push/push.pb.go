@@ -35,6 +35,9 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
 type PushRequest struct {
 	Streams []Stream `protobuf:"bytes,1,rep,name=streams,proto3,customtype=Stream" json:"streams"`
+	// idempotency_key identifies the batch of the request, so that the retries
+	// of the batch are acknowledged without being appended again.
+	IdempotencyKey string `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotencyKey,omitempty"`
 }
 
 func (m *PushRequest) Reset()      { *m = PushRequest{} }
@@ -69,6 +72,13 @@ func (m *PushRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_PushRequest proto.InternalMessageInfo
 
+func (m *PushRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
 type PushResponse struct {
 }
 
@@ -296,40 +306,43 @@ func init() {
 func init() { proto.RegisterFile("pkg/push/push.proto", fileDescriptor_35ec442956852c9e) }
 
 var fileDescriptor_35ec442956852c9e = []byte{
-	// 527 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0xf5, 0x26, 0x6e, 0xda, 0x6e, 0x4a, 0xa9, 0x96, 0xb6, 0x18, 0xab, 0x5a, 0x47, 0x16, 0x87,
-	0x1c, 0xc0, 0x96, 0xc2, 0x81, 0x0b, 0x97, 0x58, 0x42, 0xea, 0xa1, 0x48, 0x95, 0x41, 0x20, 0x71,
-	0xdb, 0x34, 0x5b, 0xdb, 0xaa, 0xed, 0x35, 0xbb, 0x6b, 0xa4, 0xde, 0xf8, 0x84, 0xf2, 0x17, 0x7c,
-	0x01, 0xdf, 0xd0, 0x63, 0x8e, 0x15, 0x07, 0x43, 0x9c, 0x0b, 0xca, 0xa9, 0x9f, 0x80, 0xbc, 0xb6,
-	0x49, 0x28, 0x48, 0x5c, 0x36, 0x6f, 0x66, 0x67, 0xde, 0x7b, 0x99, 0x1d, 0xc3, 0x07, 0xd9, 0x45,
-	0xe0, 0x66, 0xb9, 0x08, 0xd5, 0xe1, 0x64, 0x9c, 0x49, 0x86, 0xb6, 0x62, 0x16, 0x28, 0x64, 0xee,
-	0x07, 0x2c, 0x60, 0x0a, 0xba, 0x15, 0xaa, 0xef, 0x4d, 0x2b, 0x60, 0x2c, 0x88, 0xa9, 0xab, 0xa2,
-	0x49, 0x7e, 0xee, 0xca, 0x28, 0xa1, 0x42, 0x92, 0x24, 0xab, 0x0b, 0xec, 0x77, 0xb0, 0x7f, 0x9a,
-	0x8b, 0xd0, 0xa7, 0x1f, 0x72, 0x2a, 0x24, 0x3a, 0x86, 0x9b, 0x42, 0x72, 0x4a, 0x12, 0x61, 0x80,
-	0x41, 0x77, 0xd8, 0x1f, 0x3d, 0x74, 0x5a, 0x05, 0xe7, 0xb5, 0xba, 0x18, 0x4f, 0x49, 0x26, 0x29,
-	0xf7, 0x0e, 0xbe, 0x15, 0x56, 0xaf, 0x4e, 0x2d, 0x0b, 0xab, 0xed, 0xf2, 0x5b, 0x60, 0xef, 0xc2,
-	0x9d, 0x9a, 0x58, 0x64, 0x2c, 0x15, 0xd4, 0xfe, 0x0c, 0xe0, 0xbd, 0x3f, 0x18, 0x90, 0x0d, 0x7b,
-	0x31, 0x99, 0xd0, 0xb8, 0x92, 0x02, 0xc3, 0x6d, 0x0f, 0x2e, 0x0b, 0xab, 0xc9, 0xf8, 0xcd, 0x2f,
-	0x1a, 0xc3, 0x4d, 0x9a, 0x4a, 0x1e, 0x51, 0x61, 0x74, 0x94, 0x9f, 0xc3, 0x95, 0x9f, 0x97, 0xa9,
-	0xe4, 0x97, 0xad, 0x9d, 0xfb, 0xd7, 0x85, 0xa5, 0x55, 0x46, 0x9a, 0x72, 0xbf, 0x05, 0xe8, 0x11,
-	0xd4, 0x43, 0x22, 0x42, 0xa3, 0x3b, 0x00, 0x43, 0xdd, 0xdb, 0x58, 0x16, 0x16, 0x78, 0xea, 0xab,
-	0x94, 0xfd, 0x02, 0xee, 0x9d, 0x54, 0x3a, 0xa7, 0x24, 0xe2, 0xad, 0x2b, 0x04, 0xf5, 0x94, 0x24,
-	0xb4, 0xf6, 0xe4, 0x2b, 0x8c, 0xf6, 0xe1, 0xc6, 0x47, 0x12, 0xe7, 0xd4, 0xe8, 0xa8, 0x64, 0x1d,
-	0xd8, 0x5f, 0x3b, 0x70, 0x67, 0xdd, 0x03, 0x3a, 0x86, 0xdb, 0xbf, 0xc7, 0xab, 0xfa, 0xfb, 0x23,
-	0xd3, 0xa9, 0x1f, 0xc0, 0x69, 0x1f, 0xc0, 0x79, 0xd3, 0x56, 0x78, 0xbb, 0x8d, 0xe5, 0x8e, 0x14,
-	0x57, 0xdf, 0x2d, 0xe0, 0xaf, 0x9a, 0xd1, 0x11, 0xd4, 0xe3, 0x28, 0x6d, 0xf4, 0xbc, 0xad, 0x65,
-	0x61, 0xa9, 0xd8, 0x57, 0x27, 0xca, 0x20, 0x12, 0x92, 0xe7, 0x67, 0x32, 0xe7, 0x74, 0xfa, 0x8a,
-	0x4a, 0x32, 0x25, 0x92, 0x18, 0x5d, 0x35, 0x1f, 0x73, 0x35, 0x9f, 0xbb, 0x7f, 0xcd, 0x7b, 0xdc,
-	0x08, 0x1e, 0xfd, 0xdd, 0xfd, 0x84, 0x25, 0x91, 0xa4, 0x49, 0x26, 0x2f, 0xfd, 0x7f, 0x70, 0xa3,
-	0x13, 0xd8, 0xcb, 0x08, 0x17, 0x74, 0x6a, 0xe8, 0xff, 0x55, 0x31, 0x1a, 0x95, 0xbd, 0xba, 0x63,
-	0x8d, 0xb9, 0xe1, 0x18, 0x8d, 0x61, 0xaf, 0x5a, 0x0d, 0xca, 0xd1, 0x73, 0xa8, 0x57, 0x08, 0x1d,
-	0xac, 0xf8, 0xd6, 0xb6, 0xd1, 0x3c, 0xbc, 0x9b, 0x6e, 0x76, 0x49, 0xf3, 0xde, 0xce, 0xe6, 0x58,
-	0xbb, 0x99, 0x63, 0xed, 0x76, 0x8e, 0xc1, 0xa7, 0x12, 0x83, 0x2f, 0x25, 0x06, 0xd7, 0x25, 0x06,
-	0xb3, 0x12, 0x83, 0x1f, 0x25, 0x06, 0x3f, 0x4b, 0xac, 0xdd, 0x96, 0x18, 0x5c, 0x2d, 0xb0, 0x36,
-	0x5b, 0x60, 0xed, 0x66, 0x81, 0xb5, 0xf7, 0x83, 0x20, 0x92, 0x61, 0x3e, 0x71, 0xce, 0x58, 0xe2,
-	0x06, 0x9c, 0x9c, 0x93, 0x94, 0xb8, 0x31, 0xbb, 0x88, 0xdc, 0xf6, 0xd3, 0x9a, 0xf4, 0x94, 0xda,
-	0xb3, 0x5f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x7e, 0xaa, 0x57, 0xd3, 0x6d, 0x03, 0x00, 0x00,
+	// 561 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x53, 0xbf, 0x6e, 0xd3, 0x40,
+	0x18, 0xf7, 0x25, 0x6e, 0xda, 0x5e, 0x4a, 0x5a, 0x1d, 0x6d, 0x31, 0x51, 0x74, 0x8e, 0x2c, 0x86,
+	0x0c, 0x60, 0x4b, 0x61, 0x60, 0x61, 0x89, 0xa5, 0x4a, 0x95, 0x28, 0x52, 0x65, 0x10, 0x03, 0x0b,
+	0xba, 0x24, 0x57, 0xc7, 0x8a, 0xed, 0x33, 0xbe, 0x33, 0x52, 0x36, 0x1e, 0xa1, 0xbc, 0x00, 0x33,
+	0x4f, 0xc0, 0x33, 0x74, 0xcc, 0x58, 0x31, 0x18, 0xe2, 0x2c, 0x28, 0x53, 0x1f, 0x01, 0xf9, 0x6c,
+	0x93, 0xb4, 0x20, 0x75, 0x39, 0xff, 0xee, 0xfb, 0xee, 0xfb, 0x7e, 0xbf, 0xef, 0x8f, 0xe1, 0xc3,
+	0x68, 0xea, 0x5a, 0x51, 0xc2, 0x27, 0xf2, 0x30, 0xa3, 0x98, 0x09, 0x86, 0x76, 0x7c, 0xe6, 0x4a,
+	0xd4, 0x3e, 0x74, 0x99, 0xcb, 0x24, 0xb4, 0x72, 0x54, 0xf8, 0xdb, 0xba, 0xcb, 0x98, 0xeb, 0x53,
+	0x4b, 0xde, 0x86, 0xc9, 0x85, 0x25, 0xbc, 0x80, 0x72, 0x41, 0x82, 0xa8, 0x78, 0x60, 0x7c, 0x05,
+	0xb0, 0x79, 0x9e, 0xf0, 0x89, 0x43, 0x3f, 0x26, 0x94, 0x0b, 0x74, 0x0a, 0xb7, 0xb9, 0x88, 0x29,
+	0x09, 0xb8, 0x06, 0xba, 0xf5, 0x5e, 0xb3, 0xff, 0xc8, 0xac, 0x28, 0xcc, 0x37, 0xd2, 0x31, 0x18,
+	0x93, 0x48, 0xd0, 0xd8, 0x3e, 0xfa, 0x91, 0xea, 0x8d, 0xc2, 0xb4, 0x4a, 0xf5, 0x2a, 0xca, 0xa9,
+	0x00, 0x3a, 0x81, 0xfb, 0xde, 0x98, 0x06, 0x11, 0x13, 0x34, 0x1c, 0xcd, 0x3e, 0x4c, 0xe9, 0x4c,
+	0xab, 0x75, 0x41, 0x6f, 0xd7, 0xee, 0xac, 0x52, 0x5d, 0xdb, 0x70, 0xbd, 0xa2, 0xb3, 0xa7, 0x2c,
+	0xf0, 0x04, 0x0d, 0x22, 0x31, 0x73, 0x5a, 0xb7, 0x3d, 0x46, 0x0b, 0xee, 0x15, 0xfa, 0x78, 0xc4,
+	0x42, 0x4e, 0x8d, 0x2f, 0x00, 0x3e, 0xb8, 0x25, 0x04, 0x19, 0xb0, 0xe1, 0x93, 0x21, 0xf5, 0x73,
+	0xc5, 0x79, 0x7e, 0xb8, 0x4a, 0xf5, 0xd2, 0xe2, 0x94, 0x5f, 0x34, 0x80, 0xdb, 0x34, 0x14, 0xb1,
+	0x47, 0xb9, 0x56, 0x93, 0x65, 0x1d, 0xaf, 0xcb, 0x3a, 0x09, 0x45, 0x3c, 0xab, 0xaa, 0xda, 0xbf,
+	0x4a, 0x75, 0x25, 0xaf, 0xa7, 0x7c, 0xee, 0x54, 0x00, 0x3d, 0x86, 0xea, 0x84, 0xf0, 0x89, 0x56,
+	0xef, 0x82, 0x9e, 0x6a, 0x6f, 0xad, 0x52, 0x1d, 0x3c, 0x73, 0xa4, 0xc9, 0x78, 0x09, 0x0f, 0xce,
+	0x72, 0x9e, 0x73, 0xe2, 0xc5, 0x95, 0x2a, 0x04, 0xd5, 0x90, 0x04, 0xb4, 0xd0, 0xe4, 0x48, 0x8c,
+	0x0e, 0xe1, 0xd6, 0x27, 0xe2, 0x27, 0xb4, 0x68, 0x84, 0x53, 0x5c, 0x8c, 0xef, 0x35, 0xb8, 0xb7,
+	0xa9, 0x01, 0x9d, 0xc2, 0xdd, 0xbf, 0x63, 0x92, 0xf1, 0xcd, 0x7e, 0xdb, 0x2c, 0x06, 0x69, 0x56,
+	0x83, 0x34, 0xdf, 0x56, 0x2f, 0xec, 0x56, 0x29, 0xb9, 0x26, 0xf8, 0xe5, 0x4f, 0x1d, 0x38, 0xeb,
+	0x60, 0xd4, 0x81, 0xaa, 0xef, 0x85, 0x25, 0x9f, 0xbd, 0xb3, 0x4a, 0x75, 0x79, 0x77, 0xe4, 0x89,
+	0x22, 0x88, 0xb8, 0x88, 0x93, 0x91, 0x48, 0x62, 0x3a, 0x7e, 0x4d, 0x05, 0x19, 0x13, 0x41, 0xb4,
+	0xba, 0xec, 0x4f, 0x7b, 0xdd, 0x9f, 0xbb, 0xa5, 0xd9, 0x4f, 0x4a, 0xc2, 0xce, 0xbf, 0xd1, 0x1b,
+	0x83, 0xfc, 0x4f, 0x6e, 0x74, 0x06, 0x1b, 0x11, 0x89, 0x39, 0x1d, 0x6b, 0xea, 0xbd, 0x2c, 0x5a,
+	0xc9, 0x72, 0x50, 0x44, 0x6c, 0x64, 0x2e, 0x73, 0xf4, 0x07, 0xb0, 0x91, 0xaf, 0x06, 0x8d, 0xd1,
+	0x0b, 0xa8, 0xe6, 0x08, 0x1d, 0xad, 0xf3, 0x6d, 0x2c, 0x75, 0xfb, 0xf8, 0xae, 0xb9, 0xdc, 0x25,
+	0xc5, 0x7e, 0x37, 0x5f, 0x60, 0xe5, 0x7a, 0x81, 0x95, 0x9b, 0x05, 0x06, 0x9f, 0x33, 0x0c, 0xbe,
+	0x65, 0x18, 0x5c, 0x65, 0x18, 0xcc, 0x33, 0x0c, 0x7e, 0x65, 0x18, 0xfc, 0xce, 0xb0, 0x72, 0x93,
+	0x61, 0x70, 0xb9, 0xc4, 0xca, 0x7c, 0x89, 0x95, 0xeb, 0x25, 0x56, 0xde, 0x77, 0x5d, 0x4f, 0x4c,
+	0x92, 0xa1, 0x39, 0x62, 0x81, 0xe5, 0xc6, 0xe4, 0x82, 0x84, 0xc4, 0xf2, 0xd9, 0xd4, 0xb3, 0xaa,
+	0x5f, 0x74, 0xd8, 0x90, 0x6c, 0xcf, 0xff, 0x0c, 0x00, 0x4f, 0x20, 0xa0, 0xd5, 0xb5, 0x03, 0x00,
+	0x00,
 }
 
 func (this *PushRequest) Equal(that interface{}) bool {
@@ -359,6 +372,9 @@ func (this *PushRequest) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.IdempotencyKey != that1.IdempotencyKey {
+		return false
+	}
 	return true
 }
 func (this *PushResponse) Equal(that interface{}) bool {
@@ -491,9 +507,10 @@ func (this *PushRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 5)
+	s := make([]string, 0, 6)
 	s = append(s, "&push.PushRequest{")
 	s = append(s, "Streams: "+fmt.Sprintf("%#v", this.Streams)+",\n")
+	s = append(s, "IdempotencyKey: "+fmt.Sprintf("%#v", this.IdempotencyKey)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -669,6 +686,13 @@ func (m *PushRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.IdempotencyKey) > 0 {
+		i -= len(m.IdempotencyKey)
+		copy(dAtA[i:], m.IdempotencyKey)
+		i = encodeVarintPush(dAtA, i, uint64(len(m.IdempotencyKey)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.Streams) > 0 {
 		for iNdEx := len(m.Streams) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -884,6 +908,10 @@ func (m *PushRequest) Size() (n int) {
 			n += 1 + l + sovPush(uint64(l))
 		}
 	}
+	l = len(m.IdempotencyKey)
+	if l > 0 {
+		n += 1 + l + sovPush(uint64(l))
+	}
 	return n
 }
 
@@ -974,6 +1002,7 @@ func (this *PushRequest) String() string {
 	}
 	s := strings.Join([]string{`&PushRequest{`,
 		`Streams:` + fmt.Sprintf("%v", this.Streams) + `,`,
+		`IdempotencyKey:` + fmt.Sprintf("%v", this.IdempotencyKey) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1109,6 +1138,38 @@ func (m *PushRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IdempotencyKey", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPush
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPush
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPush
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.IdempotencyKey = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPush(dAtA[iNdEx:])
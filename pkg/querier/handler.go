@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/grafana/dskit/httpgrpc"
+
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange"
@@ -131,6 +133,8 @@ func (h *Handler) Do(ctx context.Context, req queryrangebase.Request) (queryrang
 		}
 
 		return &queryrange.DetectedLabelsResponse{Response: result}, nil
+	case *queryrange.ExplainRequest:
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "explained queries are only supported by the query frontend")
 	default:
 		return nil, fmt.Errorf("unsupported query type %T", req)
 	}
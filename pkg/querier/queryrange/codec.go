@@ -1272,6 +1272,8 @@ func decodeResponseProtobuf(r *http.Response, req queryrangebase.Request) (query
 			return concrete.TopkSketches.WithHeaders(headers), nil
 		case *QueryResponse_QuantileSketches:
 			return concrete.QuantileSketches.WithHeaders(headers), nil
+		case *QueryResponse_DistinctSketches:
+			return concrete.DistinctSketches.WithHeaders(headers), nil
 		default:
 			return nil, httpgrpc.Errorf(http.StatusInternalServerError, "unsupported response type, got (%T)", resp.Response)
 		}
@@ -1315,6 +1317,8 @@ func encodeResponseJSONTo(version loghttp.Version, res queryrangebase.Response,
 	switch response := res.(type) {
 	case *LokiPromResponse:
 		return response.encodeTo(w)
+	case *ExplainResponse:
+		return response.encodeTo(w)
 	case *LokiResponse:
 		streams := make([]logproto.Stream, len(response.Data.Result))
 
@@ -2219,7 +2223,7 @@ func mergeLokiResponse(responses ...queryrangebase.Response) *LokiResponse {
 	}
 }
 
-func parseRangeQuery(r *http.Request) (*LokiRequest, error) {
+func parseRangeQuery(r *http.Request) (queryrangebase.Request, error) {
 	rangeQuery, err := loghttp.ParseRangeQuery(r)
 	if err != nil {
 		return nil, err
@@ -2235,7 +2239,7 @@ func parseRangeQuery(r *http.Request) (*LokiRequest, error) {
 		return nil, err
 	}
 
-	return &LokiRequest{
+	req := &LokiRequest{
 		Query:       rangeQuery.Query,
 		Limit:       rangeQuery.Limit,
 		Direction:   rangeQuery.Direction,
@@ -2249,7 +2253,11 @@ func parseRangeQuery(r *http.Request) (*LokiRequest, error) {
 		Plan: &plan.QueryPlan{
 			AST: parsed,
 		},
-	}, nil
+	}
+	if rangeQuery.Explain {
+		return &ExplainRequest{LokiRequest: req}, nil
+	}
+	return req, nil
 }
 
 func parseInstantQuery(r *http.Request) (*LokiInstantRequest, error) {
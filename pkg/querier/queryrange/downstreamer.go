@@ -118,7 +118,9 @@ func withoutOffset(query logql.DownstreamQuery) (string, time.Time, time.Time) {
 		case *syntax.RangeAggregationExpr:
 			off := rng.Left.Offset
 
-			if off != 0 {
+			// the evaluation time of ranges pinned with an `@` modifier does
+			// not depend on the start and end of the query.
+			if off != 0 && rng.Left.At == nil {
 				rng.Left.Offset = 0 // remove offset
 
 				// adjust start and end time
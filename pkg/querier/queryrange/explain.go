@@ -0,0 +1,309 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	logqlstats "github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	v1 "github.com/grafana/loki/v3/pkg/storage/bloom/v1"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/index/stats"
+	"github.com/grafana/loki/v3/pkg/util"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+// ExplainRequest is a range query request for which the query frontend
+// returns the query plan instead of the query results.
+type ExplainRequest struct {
+	*LokiRequest
+}
+
+// ExplainResponse holds the plan of an explained query.
+type ExplainResponse struct {
+	Plan    QueryPlan
+	headers []queryrangebase.PrometheusResponseHeader
+}
+
+var _ queryrangebase.Response = &ExplainResponse{}
+
+func (r *ExplainResponse) GetHeaders() []*queryrangebase.PrometheusResponseHeader {
+	return convertPrometheusResponseHeadersToPointers(r.headers)
+}
+
+func (r *ExplainResponse) WithHeaders(h []queryrangebase.PrometheusResponseHeader) queryrangebase.Response {
+	r.headers = h
+	return r
+}
+
+func (r *ExplainResponse) SetHeader(name, value string) {
+	r.headers = setHeader(r.headers, name, value)
+}
+
+// Implement proto.Message
+func (r *ExplainResponse) Reset()         {}
+func (r *ExplainResponse) String() string { return "" }
+func (r *ExplainResponse) ProtoMessage()  {}
+
+func (r *ExplainResponse) encodeTo(w io.Writer) error {
+	return json.NewEncoder(w).Encode(struct {
+		Status string    `json:"status"`
+		Data   QueryPlan `json:"data"`
+	}{
+		Status: loghttp.QueryStatusSuccess,
+		Data:   r.Plan,
+	})
+}
+
+// QueryPlan is the plan of a range query after it has been split by time and
+// sharded. The bytes and chunks are estimated from the index stats and the
+// shards computed by the index gateways, which are filtered by the bloom
+// gateways when chunk refs are precomputed.
+type QueryPlan struct {
+	Query      string           `json:"query"`
+	Subqueries int              `json:"subqueries"`
+	Bytes      uint64           `json:"bytes"`
+	Chunks     uint64           `json:"chunks"`
+	Splits     []QueryPlanSplit `json:"splits"`
+}
+
+// QueryPlanSplit is the plan of a time split of a query.
+type QueryPlanSplit struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Query is the query of the split after sharding. The expressions which
+	// are not wrapped into a downstream<> expression are evaluated by the
+	// query frontend.
+	Query   string `json:"query"`
+	Sharded bool   `json:"sharded"`
+	Bytes   uint64 `json:"bytes"`
+	Chunks  uint64 `json:"chunks"`
+	// TotalChunks and PostFilterChunks are the chunks matched by the index and
+	// the chunks left after bloom filtering when the shards are computed.
+	TotalChunks      int64               `json:"totalChunks,omitempty"`
+	PostFilterChunks int64               `json:"postFilterChunks,omitempty"`
+	Subqueries       []QueryPlanSubquery `json:"subqueries"`
+}
+
+// QueryPlanSubquery is a subquery executed by the queriers.
+type QueryPlanSubquery struct {
+	Query   string `json:"query"`
+	Shard   string `json:"shard,omitempty"`
+	Streams uint64 `json:"streams"`
+	Chunks  uint64 `json:"chunks"`
+	Bytes   uint64 `json:"bytes"`
+	Entries uint64 `json:"entries"`
+}
+
+// queryExplainer builds the plan of explained range queries. It splits and
+// shards the queries the same way the range query middlewares do, without
+// executing them.
+type queryExplainer struct {
+	logger               log.Logger
+	limits               Limits
+	configs              []config.PeriodConfig
+	logSplitter          splitter
+	metricSplitter       splitter
+	alignQueriesWithStep bool
+	shardedQueries       bool
+	shardAggregation     []string
+	defaultLookback      time.Duration
+	mapperware           *astMapperware
+	mapperMetrics        *logql.MapperMetrics
+	statsHandler         queryrangebase.Handler
+	now                  func() time.Time
+}
+
+func newQueryExplainer(
+	cfg Config,
+	engineOpts logql.EngineOpts,
+	iqo util.IngesterQueryOptions,
+	logger log.Logger,
+	limits Limits,
+	schema config.SchemaConfig,
+	statsHandler, next queryrangebase.Handler,
+) *queryExplainer {
+	return &queryExplainer{
+		logger:               logger,
+		limits:               limits,
+		configs:              schema.Configs,
+		logSplitter:          newDefaultSplitter(limits, iqo),
+		metricSplitter:       newMetricQuerySplitter(limits, iqo),
+		alignQueriesWithStep: cfg.AlignQueriesWithStep,
+		shardedQueries:       cfg.ShardedQueries && hasShards(schema.Configs),
+		shardAggregation:     cfg.ShardAggregations,
+		defaultLookback:      engineOpts.MaxLookBackPeriod,
+		mapperware:           newASTMapperware(schema.Configs, engineOpts, next, next, statsHandler, logger, nil, limits, 0, cfg.ShardAggregations),
+		// The explained queries are not accounted in the sharding metrics.
+		mapperMetrics: logql.NewShardMapperMetrics(nil),
+		statsHandler:  statsHandler,
+		now:           time.Now,
+	}
+}
+
+func (e *queryExplainer) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	explain, ok := r.(*ExplainRequest)
+	if !ok {
+		return nil, fmt.Errorf("expected *ExplainRequest, got (%T)", r)
+	}
+	req := explain.LokiRequest
+
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := e.logSplitter
+	if _, ok := req.Plan.AST.(syntax.SampleExpr); ok {
+		s = e.metricSplitter
+		if e.alignQueriesWithStep && req.Step > 0 {
+			start := (req.StartTs.UnixMilli() / req.Step) * req.Step
+			end := (req.EndTs.UnixMilli() / req.Step) * req.Step
+			req = req.WithStartEnd(time.UnixMilli(start), time.UnixMilli(end)).(*LokiRequest)
+		}
+	}
+
+	splits := []queryrangebase.Request{req}
+	if interval := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, e.limits.QuerySplitDuration); interval > 0 {
+		intervals, err := s.split(e.now().UTC(), tenantIDs, req, interval)
+		if err != nil {
+			return nil, err
+		}
+		if len(intervals) > 0 {
+			splits = intervals
+		}
+	}
+
+	plan := QueryPlan{
+		Query:  req.Query,
+		Splits: make([]QueryPlanSplit, 0, len(splits)),
+	}
+	for _, split := range splits {
+		explained, err := e.explainSplit(ctx, tenantIDs, split)
+		if err != nil {
+			return nil, err
+		}
+		plan.Subqueries += len(explained.Subqueries)
+		plan.Bytes += explained.Bytes
+		plan.Chunks += explained.Chunks
+		plan.Splits = append(plan.Splits, explained)
+	}
+
+	return &ExplainResponse{Plan: plan}, nil
+}
+
+func (e *queryExplainer) explainSplit(ctx context.Context, tenantIDs []string, r queryrangebase.Request) (QueryPlanSplit, error) {
+	split := QueryPlanSplit{
+		Start: r.GetStart(),
+		End:   r.GetEnd(),
+		Query: r.GetQuery(),
+	}
+
+	params, err := ParamsFromRequest(r)
+	if err != nil {
+		return split, err
+	}
+
+	resolverStats, ctx := logqlstats.NewContext(ctx)
+	from, through := model.Time(r.GetStart().UnixMilli()), model.Time(r.GetEnd().UnixMilli())
+	statsResolver := &dynamicShardResolver{
+		ctx:             ctx,
+		logger:          e.logger,
+		statsHandler:    e.statsHandler,
+		limits:          e.limits,
+		from:            from,
+		through:         through,
+		maxParallelism:  MinWeightedParallelism(ctx, tenantIDs, e.configs, e.limits, from, through),
+		defaultLookback: e.defaultLookback,
+	}
+
+	leaves := []logql.DownstreamLeaf{{Expr: params.GetExpression()}}
+	if e.shardedQueries && shouldShard(e.limits, tenantIDs, e.now(), r) {
+		strategy, ok, err := e.mapperware.shardingStrategy(ctx, r, params)
+		if err != nil {
+			return split, err
+		}
+		if ok {
+			mapper := logql.NewShardMapper(strategy, e.mapperMetrics, e.shardAggregation)
+			noop, _, mapped, err := mapper.Parse(params.GetExpression())
+			if err != nil {
+				return split, err
+			}
+			if !noop {
+				split.Sharded = true
+				split.Query = mapped.String()
+				leaves = logql.DownstreamLeaves(mapped)
+			}
+		}
+	}
+
+	// The stats of the power of two shards are estimated from the stats of
+	// the whole subquery, which are shared by all its shards.
+	exprStats := make(map[string]stats.Stats)
+	getStats := func(expr syntax.Expr) (stats.Stats, error) {
+		key := expr.String()
+		if s, ok := exprStats[key]; ok {
+			return s, nil
+		}
+		s, err := statsResolver.GetStats(expr)
+		if err != nil {
+			return s, err
+		}
+		exprStats[key] = s
+		return s, nil
+	}
+
+	split.Subqueries = make([]QueryPlanSubquery, 0, len(leaves))
+	for _, leaf := range leaves {
+		subquery := QueryPlanSubquery{Query: leaf.Expr.String()}
+
+		switch {
+		case leaf.Shard != nil && leaf.Shard.Bounded != nil:
+			subquery.Shard = v1.BoundsFromProto(leaf.Shard.Bounded.Bounds).String()
+			if s := leaf.Shard.Bounded.Stats; s != nil {
+				subquery.Streams, subquery.Chunks, subquery.Bytes, subquery.Entries = s.Streams, s.Chunks, s.Bytes, s.Entries
+			}
+			// Precomputed chunk refs are what is left after bloom filtering,
+			// the bytes are scaled down accordingly.
+			if leaf.Chunks != nil && len(leaf.Chunks.Refs) > 0 && subquery.Chunks > 0 {
+				chunks := uint64(len(leaf.Chunks.Refs))
+				subquery.Bytes = subquery.Bytes * chunks / subquery.Chunks
+				subquery.Entries = subquery.Entries * chunks / subquery.Chunks
+				subquery.Chunks = chunks
+			}
+		case leaf.Shard != nil:
+			subquery.Shard = leaf.Shard.PowerOfTwo.String()
+			s, err := getStats(leaf.Expr)
+			if err != nil {
+				return split, err
+			}
+			of := uint64(max(leaf.Shard.PowerOfTwo.Of, 1))
+			subquery.Streams, subquery.Chunks, subquery.Bytes, subquery.Entries = s.Streams/of, s.Chunks/of, s.Bytes/of, s.Entries/of
+		default:
+			s, err := getStats(leaf.Expr)
+			if err != nil {
+				return split, err
+			}
+			subquery.Streams, subquery.Chunks, subquery.Bytes, subquery.Entries = s.Streams, s.Chunks, s.Bytes, s.Entries
+		}
+
+		split.Bytes += subquery.Bytes
+		split.Chunks += subquery.Chunks
+		split.Subqueries = append(split.Subqueries, subquery)
+	}
+
+	index := resolverStats.Result(0, 0, 0).Index
+	split.TotalChunks, split.PostFilterChunks = index.TotalChunks, index.PostFilterChunks
+
+	return split, nil
+}
@@ -0,0 +1,118 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+)
+
+func Test_queryExplainer(t *testing.T) {
+	handler := queryrangebase.HandlerFunc(func(_ context.Context, req queryrangebase.Request) (queryrangebase.Response, error) {
+		if _, ok := req.(*logproto.IndexStatsRequest); ok {
+			return &IndexStatsResponse{
+				Response: &logproto.IndexStatsResponse{
+					Streams: 4,
+					Chunks:  8,
+					Bytes:   400,
+					Entries: 40,
+				},
+			}, nil
+		}
+		t.Errorf("unexpected request %T", req)
+		return nil, nil
+	})
+
+	cfg := testConfig
+	cfg.ShardedQueries = true
+	schema := config.SchemaConfig{
+		Configs: []config.PeriodConfig{{RowShards: 4}},
+	}
+	limits := fakeLimits{
+		maxQueryParallelism: 1,
+		maxSeries:           1000,
+		splitDuration:       map[string]time.Duration{"1": time.Hour},
+	}
+	explainer := newQueryExplainer(cfg, testEngineOpts, nil, log.NewNopLogger(), limits, schema, handler, handler)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := user.InjectOrgID(context.Background(), "1")
+
+	for _, tc := range []struct {
+		query         string
+		subqueries    int
+		bytes, chunks uint64
+		shard         string
+		sharded       bool
+	}{
+		{
+			query:      `sum(count_over_time({app="foo"} |= "foo" [1m]))`,
+			subqueries: 8,
+			bytes:      800,
+			chunks:     16,
+			shard:      "0_of_4",
+			sharded:    true,
+		},
+		{
+			// quantiles cannot be sharded without sketches.
+			query:      `max(quantile_over_time(0.99, {app="foo"} | unwrap latency [1m]))`,
+			subqueries: 2,
+			bytes:      800,
+			chunks:     16,
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			req := &ExplainRequest{LokiRequest: &LokiRequest{
+				Query:   tc.query,
+				StartTs: start,
+				EndTs:   start.Add(2 * time.Hour),
+				Step:    time.Minute.Milliseconds(),
+				Limit:   100,
+				Plan:    &plan.QueryPlan{AST: syntax.MustParseExpr(tc.query)},
+			}}
+
+			resp, err := explainer.Do(ctx, req)
+			require.NoError(t, err)
+
+			queryPlan := resp.(*ExplainResponse).Plan
+			require.Equal(t, tc.query, queryPlan.Query)
+			require.Len(t, queryPlan.Splits, 2)
+			require.Equal(t, tc.subqueries, queryPlan.Subqueries)
+			require.Equal(t, tc.bytes, queryPlan.Bytes)
+			require.Equal(t, tc.chunks, queryPlan.Chunks)
+
+			split := queryPlan.Splits[0]
+			require.Equal(t, start, split.Start)
+			require.Equal(t, tc.sharded, split.Sharded)
+			require.Equal(t, tc.shard, split.Subqueries[0].Shard)
+		})
+	}
+}
+
+func Test_codec_DecodeExplainRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, `/loki/api/v1/query_range?query={app="foo"}&start=0&end=3600&explain=true`, nil)
+	require.NoError(t, err)
+
+	decoded, err := DefaultCodec.DecodeRequest(context.Background(), req, nil)
+	require.NoError(t, err)
+	explain, ok := decoded.(*ExplainRequest)
+	require.True(t, ok)
+	require.Equal(t, `{app="foo"}`, explain.Query)
+
+	req, err = http.NewRequest(http.MethodGet, `/loki/api/v1/query_range?query={app="foo"}&start=0&end=3600`, nil)
+	require.NoError(t, err)
+
+	decoded, err = DefaultCodec.DecodeRequest(context.Background(), req, nil)
+	require.NoError(t, err)
+	require.IsType(t, &LokiRequest{}, decoded)
+}
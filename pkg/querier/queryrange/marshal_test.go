@@ -47,6 +47,18 @@ func TestResultToResponse(t *testing.T) {
 				Headers: []queryrangebase.PrometheusResponseHeader(nil),
 			},
 		},
+		{
+			name: "empty distinct sketch matrix",
+			result: logqlmodel.Result{
+				Data: logql.DistinctSketchMatrix{},
+			},
+			response: &DistinctSketchResponse{
+				Response: &logproto.DistinctSketchMatrix{
+					Values: []*logproto.DistinctSketchVector{},
+				},
+				Headers: []queryrangebase.PrometheusResponseHeader(nil),
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -73,6 +85,7 @@ func TestResponseWrap(t *testing.T) {
 		{"streams", &LokiResponse{}, &QueryResponse_Streams{}},
 		{"topk", &TopKSketchesResponse{}, &QueryResponse_TopkSketches{}},
 		{"quantile", &QuantileSketchResponse{}, &QueryResponse_QuantileSketches{}},
+		{"distinct", &DistinctSketchResponse{}, &QueryResponse_DistinctSketches{}},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			actual, err := QueryResponseWrap(tt.response)
@@ -908,6 +908,59 @@ func (m *QuantileSketchResponse) GetStatistics() stats.Result {
 	return stats.Result{}
 }
 
+type DistinctSketchResponse struct {
+	Response   *github_com_grafana_loki_v3_pkg_logproto.DistinctSketchMatrix                                           `protobuf:"bytes,1,opt,name=response,proto3,customtype=github.com/grafana/loki/v3/pkg/logproto.DistinctSketchMatrix" json:"response,omitempty"`
+	Headers    []github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,2,rep,name=Headers,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
+	Warnings   []string                                                                                                `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	Statistics stats.Result                                                                                            `protobuf:"bytes,4,opt,name=statistics,proto3" json:"statistics"`
+}
+
+func (m *DistinctSketchResponse) Reset()      { *m = DistinctSketchResponse{} }
+func (*DistinctSketchResponse) ProtoMessage() {}
+func (*DistinctSketchResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_51b9d53b40d11902, []int{13}
+}
+func (m *DistinctSketchResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DistinctSketchResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DistinctSketchResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DistinctSketchResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DistinctSketchResponse.Merge(m, src)
+}
+func (m *DistinctSketchResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *DistinctSketchResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DistinctSketchResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DistinctSketchResponse proto.InternalMessageInfo
+
+func (m *DistinctSketchResponse) GetWarnings() []string {
+	if m != nil {
+		return m.Warnings
+	}
+	return nil
+}
+
+func (m *DistinctSketchResponse) GetStatistics() stats.Result {
+	if m != nil {
+		return m.Statistics
+	}
+	return stats.Result{}
+}
+
 type ShardsResponse struct {
 	Response *github_com_grafana_loki_v3_pkg_logproto.ShardsResponse                                                 `protobuf:"bytes,1,opt,name=response,proto3,customtype=github.com/grafana/loki/v3/pkg/logproto.ShardsResponse" json:"response,omitempty"`
 	Headers  []github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,2,rep,name=Headers,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
@@ -916,7 +969,7 @@ type ShardsResponse struct {
 func (m *ShardsResponse) Reset()      { *m = ShardsResponse{} }
 func (*ShardsResponse) ProtoMessage() {}
 func (*ShardsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{13}
+	return fileDescriptor_51b9d53b40d11902, []int{14}
 }
 func (m *ShardsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -953,7 +1006,7 @@ type DetectedFieldsResponse struct {
 func (m *DetectedFieldsResponse) Reset()      { *m = DetectedFieldsResponse{} }
 func (*DetectedFieldsResponse) ProtoMessage() {}
 func (*DetectedFieldsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{14}
+	return fileDescriptor_51b9d53b40d11902, []int{15}
 }
 func (m *DetectedFieldsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -990,7 +1043,7 @@ type QueryPatternsResponse struct {
 func (m *QueryPatternsResponse) Reset()      { *m = QueryPatternsResponse{} }
 func (*QueryPatternsResponse) ProtoMessage() {}
 func (*QueryPatternsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{15}
+	return fileDescriptor_51b9d53b40d11902, []int{16}
 }
 func (m *QueryPatternsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1027,7 +1080,7 @@ type DetectedLabelsResponse struct {
 func (m *DetectedLabelsResponse) Reset()      { *m = DetectedLabelsResponse{} }
 func (*DetectedLabelsResponse) ProtoMessage() {}
 func (*DetectedLabelsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{16}
+	return fileDescriptor_51b9d53b40d11902, []int{17}
 }
 func (m *DetectedLabelsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1071,13 +1124,14 @@ type QueryResponse struct {
 	//	*QueryResponse_DetectedFields
 	//	*QueryResponse_PatternsResponse
 	//	*QueryResponse_DetectedLabels
+	//	*QueryResponse_DistinctSketches
 	Response isQueryResponse_Response `protobuf_oneof:"response"`
 }
 
 func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
 func (*QueryResponse) ProtoMessage() {}
 func (*QueryResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{17}
+	return fileDescriptor_51b9d53b40d11902, []int{18}
 }
 func (m *QueryResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1149,6 +1203,9 @@ type QueryResponse_PatternsResponse struct {
 type QueryResponse_DetectedLabels struct {
 	DetectedLabels *DetectedLabelsResponse `protobuf:"bytes,13,opt,name=detectedLabels,proto3,oneof"`
 }
+type QueryResponse_DistinctSketches struct {
+	DistinctSketches *DistinctSketchResponse `protobuf:"bytes,14,opt,name=distinctSketches,proto3,oneof"`
+}
 
 func (*QueryResponse_Series) isQueryResponse_Response()           {}
 func (*QueryResponse_Labels) isQueryResponse_Response()           {}
@@ -1162,6 +1219,7 @@ func (*QueryResponse_ShardsResponse) isQueryResponse_Response()   {}
 func (*QueryResponse_DetectedFields) isQueryResponse_Response()   {}
 func (*QueryResponse_PatternsResponse) isQueryResponse_Response() {}
 func (*QueryResponse_DetectedLabels) isQueryResponse_Response()   {}
+func (*QueryResponse_DistinctSketches) isQueryResponse_Response() {}
 
 func (m *QueryResponse) GetResponse() isQueryResponse_Response {
 	if m != nil {
@@ -1261,6 +1319,13 @@ func (m *QueryResponse) GetDetectedLabels() *DetectedLabelsResponse {
 	return nil
 }
 
+func (m *QueryResponse) GetDistinctSketches() *DistinctSketchResponse {
+	if x, ok := m.GetResponse().(*QueryResponse_DistinctSketches); ok {
+		return x.DistinctSketches
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*QueryResponse) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -1276,6 +1341,7 @@ func (*QueryResponse) XXX_OneofWrappers() []interface{} {
 		(*QueryResponse_DetectedFields)(nil),
 		(*QueryResponse_PatternsResponse)(nil),
 		(*QueryResponse_DetectedLabels)(nil),
+		(*QueryResponse_DistinctSketches)(nil),
 	}
 }
 
@@ -1298,7 +1364,7 @@ type QueryRequest struct {
 func (m *QueryRequest) Reset()      { *m = QueryRequest{} }
 func (*QueryRequest) ProtoMessage() {}
 func (*QueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_51b9d53b40d11902, []int{18}
+	return fileDescriptor_51b9d53b40d11902, []int{19}
 }
 func (m *QueryRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1490,6 +1556,7 @@ func init() {
 	proto.RegisterType((*VolumeResponse)(nil), "queryrange.VolumeResponse")
 	proto.RegisterType((*TopKSketchesResponse)(nil), "queryrange.TopKSketchesResponse")
 	proto.RegisterType((*QuantileSketchResponse)(nil), "queryrange.QuantileSketchResponse")
+	proto.RegisterType((*DistinctSketchResponse)(nil), "queryrange.DistinctSketchResponse")
 	proto.RegisterType((*ShardsResponse)(nil), "queryrange.ShardsResponse")
 	proto.RegisterType((*DetectedFieldsResponse)(nil), "queryrange.DetectedFieldsResponse")
 	proto.RegisterType((*QueryPatternsResponse)(nil), "queryrange.QueryPatternsResponse")
@@ -1504,129 +1571,131 @@ func init() {
 }
 
 var fileDescriptor_51b9d53b40d11902 = []byte{
-	// 1944 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcb, 0x6f, 0x1b, 0xc7,
-	0x19, 0xe7, 0xf2, 0x29, 0x7e, 0x7a, 0x58, 0x1d, 0xab, 0xca, 0x56, 0x71, 0xb8, 0x2c, 0x81, 0x26,
-	0x6a, 0xd1, 0x92, 0x31, 0x95, 0xb8, 0x89, 0xea, 0x1a, 0xf1, 0x5a, 0x76, 0x65, 0xd7, 0x69, 0x9c,
-	0x95, 0xd0, 0x43, 0x2f, 0xc5, 0x88, 0x1c, 0x91, 0x5b, 0x91, 0xbb, 0xeb, 0xdd, 0xa1, 0x6c, 0x01,
-	0x45, 0x91, 0x7f, 0x20, 0x68, 0xee, 0xbd, 0x17, 0xbd, 0x15, 0x05, 0x7a, 0xea, 0xa9, 0xc7, 0xe4,
-	0x50, 0xc0, 0xc7, 0x80, 0x40, 0xb7, 0x35, 0xdd, 0x43, 0xa1, 0x53, 0x80, 0xfe, 0x03, 0xc5, 0x3c,
-	0x76, 0x39, 0x43, 0x52, 0x35, 0xa5, 0x16, 0x05, 0x14, 0xe4, 0x22, 0xcd, 0xe3, 0xfb, 0xcd, 0xce,
-	0xfc, 0xbe, 0xdf, 0xf7, 0xcd, 0x83, 0xf0, 0x46, 0x70, 0xd4, 0x69, 0x3c, 0x1e, 0x90, 0xd0, 0x25,
-	0x21, 0xff, 0x7f, 0x12, 0x62, 0xaf, 0x43, 0x94, 0x62, 0x3d, 0x08, 0x7d, 0xea, 0x23, 0x18, 0xb7,
-	0x6c, 0x34, 0x3b, 0x2e, 0xed, 0x0e, 0x0e, 0xea, 0x2d, 0xbf, 0xdf, 0xe8, 0xf8, 0x1d, 0xbf, 0xd1,
-	0xf1, 0xfd, 0x4e, 0x8f, 0xe0, 0xc0, 0x8d, 0x64, 0xb1, 0x11, 0x06, 0xad, 0x46, 0x44, 0x31, 0x1d,
-	0x44, 0x02, 0xbf, 0xb1, 0xc6, 0x0c, 0x79, 0x91, 0x43, 0x64, 0xab, 0x25, 0xcd, 0x79, 0xed, 0x60,
-	0x70, 0xd8, 0xa0, 0x6e, 0x9f, 0x44, 0x14, 0xf7, 0x83, 0xc4, 0x80, 0xcd, 0xaf, 0xe7, 0x77, 0x04,
-	0xd2, 0xf5, 0xda, 0xe4, 0x69, 0x07, 0x53, 0xf2, 0x04, 0x9f, 0x48, 0x83, 0x57, 0x35, 0x83, 0xa4,
-	0x20, 0x3b, 0x37, 0xb4, 0xce, 0x00, 0x53, 0x4a, 0x42, 0x4f, 0xf6, 0x7d, 0x43, 0xeb, 0x8b, 0x8e,
-	0x08, 0x6d, 0x75, 0x65, 0x57, 0x55, 0x76, 0x3d, 0xee, 0xf5, 0xfd, 0x36, 0xe9, 0xf1, 0x85, 0x44,
-	0xe2, 0xaf, 0xb4, 0xb8, 0xca, 0x2c, 0x82, 0x41, 0xd4, 0xe5, 0x7f, 0x64, 0xe3, 0x9d, 0x97, 0x72,
-	0x79, 0x80, 0x23, 0xd2, 0x68, 0x93, 0x43, 0xd7, 0x73, 0xa9, 0xeb, 0x7b, 0x91, 0x5a, 0x96, 0x83,
-	0xdc, 0x98, 0x6f, 0x90, 0x49, 0xff, 0x6c, 0xbc, 0xc9, 0x70, 0x11, 0xf5, 0x43, 0xdc, 0x21, 0x8d,
-	0x56, 0x77, 0xe0, 0x1d, 0x35, 0x5a, 0xb8, 0xd5, 0x25, 0x8d, 0x90, 0x44, 0x83, 0x1e, 0x8d, 0x44,
-	0x85, 0x9e, 0x04, 0x44, 0x7e, 0xa9, 0xf6, 0x59, 0x1e, 0x16, 0x1f, 0xfa, 0x47, 0xae, 0x43, 0x1e,
-	0x0f, 0x48, 0x44, 0xd1, 0x1a, 0x14, 0xf8, 0xa8, 0xa6, 0x51, 0x35, 0x36, 0xcb, 0x8e, 0xa8, 0xb0,
-	0xd6, 0x9e, 0xdb, 0x77, 0xa9, 0x99, 0xad, 0x1a, 0x9b, 0xcb, 0x8e, 0xa8, 0x20, 0x04, 0xf9, 0x88,
-	0x92, 0xc0, 0xcc, 0x55, 0x8d, 0xcd, 0x9c, 0xc3, 0xcb, 0x68, 0x03, 0x16, 0x5c, 0x8f, 0x92, 0xf0,
-	0x18, 0xf7, 0xcc, 0x32, 0x6f, 0x4f, 0xeb, 0xe8, 0x16, 0x94, 0x22, 0x8a, 0x43, 0xba, 0x1f, 0x99,
-	0xf9, 0xaa, 0xb1, 0xb9, 0xd8, 0xdc, 0xa8, 0x0b, 0xcf, 0xd7, 0x13, 0xcf, 0xd7, 0xf7, 0x13, 0xcf,
-	0xdb, 0x0b, 0x9f, 0xc6, 0x56, 0xe6, 0x93, 0xbf, 0x59, 0x86, 0x93, 0x80, 0xd0, 0x36, 0x14, 0x88,
-	0xd7, 0xde, 0x8f, 0xcc, 0xc2, 0x39, 0xd0, 0x02, 0x82, 0xae, 0x43, 0xb9, 0xed, 0x86, 0xa4, 0xc5,
-	0x58, 0x36, 0x8b, 0x55, 0x63, 0x73, 0xa5, 0x79, 0xb5, 0x9e, 0x0a, 0x65, 0x27, 0xe9, 0x72, 0xc6,
-	0x56, 0x6c, 0x79, 0x01, 0xa6, 0x5d, 0xb3, 0xc4, 0x99, 0xe0, 0x65, 0x54, 0x83, 0x62, 0xd4, 0xc5,
-	0x61, 0x3b, 0x32, 0x17, 0xaa, 0xb9, 0xcd, 0xb2, 0x0d, 0xa7, 0xb1, 0x25, 0x5b, 0x1c, 0xf9, 0x1f,
-	0xfd, 0x1c, 0xf2, 0x41, 0x0f, 0x7b, 0x26, 0xf0, 0x59, 0xae, 0xd6, 0x15, 0x2f, 0x3d, 0xea, 0x61,
-	0xcf, 0x7e, 0x77, 0x18, 0x5b, 0x6f, 0xab, 0xc1, 0x13, 0xe2, 0x43, 0xec, 0xe1, 0x46, 0xcf, 0x3f,
-	0x72, 0x1b, 0xc7, 0x5b, 0x0d, 0xd5, 0xf7, 0x6c, 0xa0, 0xfa, 0x87, 0x6c, 0x00, 0x06, 0x75, 0xf8,
-	0xc0, 0xe8, 0x01, 0x2c, 0x32, 0x1f, 0x93, 0x3b, 0xcc, 0xc1, 0x91, 0xb9, 0xc8, 0xbf, 0xf3, 0xca,
-	0x78, 0x35, 0xbc, 0xdd, 0x21, 0x87, 0x3f, 0x0a, 0xfd, 0x41, 0x60, 0x5f, 0x39, 0x8d, 0x2d, 0xd5,
-	0xde, 0x51, 0x2b, 0xe8, 0x01, 0xac, 0x30, 0x51, 0xb8, 0x5e, 0xe7, 0x83, 0x80, 0x2b, 0xd0, 0x5c,
-	0xe2, 0xc3, 0x5d, 0xab, 0xab, 0x92, 0xa9, 0xdf, 0xd1, 0x6c, 0xec, 0x3c, 0xa3, 0xd7, 0x99, 0x40,
-	0xd6, 0x46, 0x39, 0x40, 0x4c, 0x4b, 0xf7, 0xbd, 0x88, 0x62, 0x8f, 0x5e, 0x44, 0x52, 0x37, 0xa1,
-	0xc8, 0x82, 0x7f, 0x3f, 0xe2, 0xa2, 0x9a, 0xd7, 0xc7, 0x12, 0xa3, 0x3b, 0x39, 0x7f, 0x2e, 0x27,
-	0x17, 0x66, 0x3a, 0xb9, 0xf8, 0x52, 0x27, 0x97, 0xfe, 0x4f, 0x4e, 0x5e, 0xf8, 0xdf, 0x3a, 0xb9,
-	0x7c, 0x61, 0x27, 0x9b, 0x90, 0x67, 0xb3, 0x44, 0xab, 0x90, 0x0b, 0xf1, 0x13, 0xee, 0xd3, 0x25,
-	0x87, 0x15, 0x6b, 0xa3, 0x3c, 0x2c, 0x89, 0x54, 0x12, 0x05, 0xbe, 0x17, 0x11, 0xc6, 0xe3, 0x1e,
-	0xcf, 0xfe, 0xc2, 0xf3, 0x92, 0x47, 0xde, 0xe2, 0xc8, 0x1e, 0xf4, 0x1e, 0xe4, 0x77, 0x30, 0xc5,
-	0x5c, 0x05, 0x8b, 0xcd, 0x35, 0x95, 0x47, 0x36, 0x16, 0xeb, 0xb3, 0xd7, 0xd9, 0x44, 0x4e, 0x63,
-	0x6b, 0xa5, 0x8d, 0x29, 0xfe, 0xae, 0xdf, 0x77, 0x29, 0xe9, 0x07, 0xf4, 0xc4, 0xe1, 0x48, 0xf4,
-	0x36, 0x94, 0xef, 0x86, 0xa1, 0x1f, 0xee, 0x9f, 0x04, 0x84, 0xab, 0xa6, 0x6c, 0xbf, 0x72, 0x1a,
-	0x5b, 0x57, 0x49, 0xd2, 0xa8, 0x20, 0xc6, 0x96, 0xe8, 0xdb, 0x50, 0xe0, 0x15, 0xae, 0x93, 0xb2,
-	0x7d, 0xf5, 0x34, 0xb6, 0xae, 0x70, 0x88, 0x62, 0x2e, 0x2c, 0x74, 0x59, 0x15, 0xe6, 0x92, 0x55,
-	0xaa, 0xee, 0xa2, 0xaa, 0x6e, 0x13, 0x4a, 0xc7, 0x24, 0x8c, 0xd8, 0x30, 0x25, 0xde, 0x9e, 0x54,
-	0xd1, 0x6d, 0x00, 0x46, 0x8c, 0x1b, 0x51, 0xb7, 0x95, 0x38, 0x7b, 0xb9, 0x2e, 0x36, 0x1b, 0x87,
-	0xfb, 0xc8, 0x46, 0x92, 0x05, 0xc5, 0xd0, 0x51, 0xca, 0xe8, 0xf7, 0x06, 0x94, 0x76, 0x09, 0x6e,
-	0x93, 0x90, 0xb9, 0x37, 0xb7, 0xb9, 0xd8, 0xfc, 0x56, 0x5d, 0xdd, 0x59, 0x1e, 0x85, 0x7e, 0x9f,
-	0xd0, 0x2e, 0x19, 0x44, 0x89, 0x83, 0x84, 0xb5, 0xed, 0x0d, 0x63, 0x8b, 0xcc, 0x29, 0xd5, 0xb9,
-	0x36, 0xb4, 0x33, 0x3f, 0x75, 0x1a, 0x5b, 0xc6, 0xf7, 0x9c, 0x64, 0x96, 0xa8, 0x09, 0x0b, 0x4f,
-	0x70, 0xe8, 0xb9, 0x5e, 0x27, 0x32, 0x81, 0x47, 0xda, 0xfa, 0x69, 0x6c, 0xa1, 0xa4, 0x4d, 0x71,
-	0x44, 0x6a, 0x57, 0xfb, 0xab, 0x01, 0x5f, 0x63, 0xc2, 0xd8, 0x63, 0xf3, 0x89, 0x94, 0x14, 0xd3,
-	0xc7, 0xb4, 0xd5, 0x35, 0x0d, 0x36, 0x8c, 0x23, 0x2a, 0xea, 0x7e, 0x93, 0xfd, 0xaf, 0xf6, 0x9b,
-	0xdc, 0xf9, 0xf7, 0x9b, 0x24, 0xaf, 0xe4, 0x67, 0xe6, 0x95, 0xc2, 0x59, 0x79, 0xa5, 0xf6, 0x6b,
-	0x99, 0x43, 0x93, 0xf5, 0x9d, 0x23, 0x94, 0xee, 0xa5, 0xa1, 0x94, 0xe3, 0xb3, 0x4d, 0x15, 0x2a,
-	0xc6, 0xba, 0xdf, 0x26, 0x1e, 0x75, 0x0f, 0x5d, 0x12, 0xbe, 0x24, 0xa0, 0x14, 0x95, 0xe6, 0x74,
-	0x95, 0xaa, 0x12, 0xcb, 0x5f, 0x0a, 0x89, 0xe9, 0x71, 0x55, 0xb8, 0x40, 0x5c, 0xd5, 0xfe, 0x95,
-	0x85, 0x75, 0xe6, 0x91, 0x87, 0xf8, 0x80, 0xf4, 0x7e, 0x82, 0xfb, 0xe7, 0xf4, 0xca, 0xeb, 0x8a,
-	0x57, 0xca, 0x36, 0xfa, 0x8a, 0xf5, 0xf9, 0x58, 0xff, 0xad, 0x01, 0x0b, 0xc9, 0x06, 0x80, 0xea,
-	0x00, 0x02, 0xc6, 0x73, 0xbc, 0xe0, 0x7a, 0x85, 0x81, 0xc3, 0xb4, 0xd5, 0x51, 0x2c, 0xd0, 0x2f,
-	0xa0, 0x28, 0x6a, 0x32, 0x16, 0x94, 0x6d, 0x73, 0x8f, 0x86, 0x04, 0xf7, 0x6f, 0xb7, 0x71, 0x40,
-	0x49, 0x68, 0xbf, 0xcb, 0x66, 0x31, 0x8c, 0xad, 0x37, 0xce, 0x62, 0x29, 0x39, 0xe1, 0x4b, 0x1c,
-	0xf3, 0xaf, 0xf8, 0xa6, 0x23, 0xbf, 0x50, 0xfb, 0xd8, 0x80, 0x55, 0x36, 0x51, 0x46, 0x4d, 0x2a,
-	0x8c, 0x1d, 0x58, 0x08, 0x65, 0x99, 0x4f, 0x77, 0xb1, 0x59, 0xab, 0xeb, 0xb4, 0xce, 0xa0, 0x92,
-	0x6f, 0xb8, 0x86, 0x93, 0x22, 0xd1, 0x96, 0x46, 0x63, 0x76, 0x16, 0x8d, 0x62, 0x8f, 0x56, 0x89,
-	0xfb, 0x73, 0x16, 0xd0, 0x7d, 0x76, 0x43, 0x62, 0xfa, 0x1b, 0x4b, 0xf5, 0xe9, 0xd4, 0x8c, 0xae,
-	0x8d, 0x49, 0x99, 0xb6, 0xb7, 0x6f, 0x0d, 0x63, 0x6b, 0xfb, 0x25, 0xda, 0xf9, 0x0f, 0x78, 0x65,
-	0x15, 0xaa, 0x7c, 0xb3, 0x97, 0x41, 0xbe, 0xb5, 0x3f, 0x66, 0x61, 0xe5, 0xa7, 0x7e, 0x6f, 0xd0,
-	0x27, 0x29, 0x7d, 0xc1, 0x14, 0x7d, 0xe6, 0x98, 0x3e, 0xdd, 0xd6, 0xde, 0x1e, 0xc6, 0xd6, 0x8d,
-	0x79, 0xa9, 0xd3, 0xb1, 0x97, 0x9a, 0xb6, 0xdf, 0xe4, 0x60, 0x6d, 0xdf, 0x0f, 0x7e, 0xbc, 0xc7,
-	0x6f, 0xd1, 0x4a, 0x9a, 0xec, 0x4e, 0x91, 0xb7, 0x36, 0x26, 0x8f, 0x21, 0xde, 0xc7, 0x34, 0x74,
-	0x9f, 0xda, 0x37, 0x86, 0xb1, 0xd5, 0x9c, 0x97, 0xb8, 0x31, 0xee, 0x32, 0x93, 0xa6, 0x9d, 0x81,
-	0x72, 0xf3, 0x9d, 0x81, 0x26, 0xf2, 0x42, 0x7e, 0xbe, 0xbc, 0xf0, 0x87, 0x1c, 0xac, 0x7f, 0x38,
-	0xc0, 0x1e, 0x75, 0x7b, 0x44, 0x78, 0x28, 0xf5, 0xcf, 0x2f, 0xa7, 0xfc, 0x53, 0x19, 0xfb, 0x47,
-	0xc7, 0x48, 0x4f, 0xbd, 0x37, 0x8c, 0xad, 0x9b, 0xf3, 0x7a, 0x6a, 0xd6, 0x08, 0x5f, 0xf9, 0x6c,
-	0x1e, 0x9f, 0xfd, 0x29, 0x0b, 0x2b, 0x7b, 0xe2, 0x7c, 0x98, 0xb0, 0x75, 0x3c, 0xc3, 0x57, 0xea,
-	0x83, 0x58, 0x70, 0x50, 0xd7, 0x11, 0xe7, 0x4b, 0x47, 0x3a, 0xf6, 0x52, 0xa7, 0xa3, 0xbf, 0x64,
-	0x61, 0x7d, 0x87, 0x50, 0xd2, 0xa2, 0xa4, 0x7d, 0xcf, 0x25, 0x3d, 0x85, 0xc4, 0x8f, 0x8c, 0x29,
-	0x16, 0xab, 0xca, 0x85, 0x6e, 0x26, 0xc8, 0xb6, 0x87, 0xb1, 0x75, 0x6b, 0x5e, 0x1e, 0x67, 0x8f,
-	0x71, 0xa9, 0xf9, 0xfc, 0x2c, 0x0b, 0x5f, 0x17, 0x8f, 0x14, 0xe2, 0x05, 0x75, 0x4c, 0xe7, 0xaf,
-	0xa6, 0xd8, 0xb4, 0xd4, 0xfc, 0x31, 0x03, 0x62, 0xdf, 0x1e, 0xc6, 0xd6, 0x0f, 0xe7, 0x4f, 0x20,
-	0x33, 0x86, 0xf8, 0xd2, 0x68, 0x93, 0xdf, 0x2b, 0xce, 0xab, 0x4d, 0x1d, 0x74, 0x31, 0x6d, 0xea,
-	0x63, 0x5c, 0x6a, 0x3e, 0xff, 0x51, 0x84, 0x65, 0xae, 0x92, 0x94, 0xc6, 0xef, 0x80, 0xbc, 0x88,
-	0x49, 0x0e, 0x51, 0x72, 0x79, 0x0f, 0x83, 0x56, 0x7d, 0x4f, 0x5e, 0xd1, 0x84, 0x05, 0x7a, 0x07,
-	0x8a, 0x11, 0xbf, 0x22, 0xcb, 0x33, 0x76, 0x65, 0xf2, 0x15, 0x4a, 0xbf, 0x8c, 0xef, 0x66, 0x1c,
-	0x69, 0x8f, 0x6e, 0x42, 0xb1, 0xc7, 0x59, 0x94, 0x4f, 0x04, 0xb5, 0x49, 0xe4, 0xf4, 0xa5, 0x91,
-	0xa1, 0x05, 0x06, 0xdd, 0x80, 0x02, 0xdf, 0x00, 0xe4, 0x76, 0xa0, 0x7d, 0x76, 0xfa, 0x48, 0xbd,
-	0x9b, 0x71, 0x84, 0x39, 0x6a, 0x42, 0x3e, 0x08, 0xfd, 0xbe, 0xbc, 0x58, 0x5d, 0x9b, 0xfc, 0xa6,
-	0x7a, 0x13, 0xd9, 0xcd, 0x38, 0xdc, 0x16, 0xbd, 0x05, 0xa5, 0x88, 0x5f, 0x61, 0x22, 0xfe, 0x24,
-	0xc5, 0xce, 0xaf, 0x13, 0x30, 0x05, 0x92, 0x98, 0xa2, 0xb7, 0xa0, 0x78, 0xcc, 0x0f, 0xa8, 0xf2,
-	0x9d, 0x73, 0x43, 0x05, 0xe9, 0x47, 0x57, 0xb6, 0x2e, 0x61, 0x8b, 0xee, 0xc1, 0x12, 0xf5, 0x83,
-	0xa3, 0xe4, 0x1c, 0x28, 0x9f, 0xb3, 0xaa, 0x2a, 0x76, 0xd6, 0x39, 0x71, 0x37, 0xe3, 0x68, 0x38,
-	0xf4, 0x08, 0x56, 0x1f, 0x6b, 0x67, 0x07, 0x92, 0x3c, 0x5c, 0x6a, 0x3c, 0xcf, 0x3e, 0xd5, 0xec,
-	0x66, 0x9c, 0x29, 0x34, 0xda, 0x81, 0x95, 0x48, 0xdb, 0xe1, 0xe4, 0x23, 0xbd, 0xb6, 0x2e, 0x7d,
-	0x0f, 0xdc, 0xcd, 0x38, 0x13, 0x18, 0xf4, 0x10, 0x56, 0xda, 0x5a, 0x7e, 0x97, 0x4f, 0xf0, 0xda,
-	0xac, 0x66, 0xef, 0x00, 0x6c, 0x34, 0x1d, 0x8b, 0x3e, 0x80, 0xd5, 0x60, 0x22, 0xb7, 0xc9, 0x37,
-	0xf8, 0x6f, 0xea, 0xab, 0x9c, 0x91, 0x04, 0xd9, 0x22, 0x27, 0xc1, 0xea, 0xf4, 0x44, 0x88, 0x9b,
-	0xcb, 0x67, 0x4f, 0x4f, 0x4f, 0x02, 0xea, 0xf4, 0x44, 0x8f, 0x0d, 0xe3, 0x74, 0x54, 0xfb, 0xb8,
-	0x08, 0x4b, 0x32, 0xcc, 0xc4, 0xbb, 0xdb, 0xf7, 0xd3, 0xc8, 0x11, 0x51, 0xf6, 0xda, 0x59, 0x91,
-	0xc3, 0xcd, 0x95, 0xc0, 0x79, 0x33, 0x0d, 0x1c, 0x11, 0x72, 0xeb, 0xe3, 0x14, 0xc7, 0xbf, 0xab,
-	0x20, 0x64, 0xb0, 0x6c, 0x25, 0xc1, 0x22, 0x22, 0xed, 0xd5, 0xd9, 0xb7, 0xd7, 0x04, 0x25, 0x23,
-	0x65, 0x1b, 0x4a, 0xae, 0xf8, 0x31, 0x62, 0x56, 0x8c, 0x4d, 0xff, 0x56, 0xc1, 0xb4, 0x2f, 0x01,
-	0x68, 0x6b, 0x1c, 0x31, 0x05, 0xf9, 0xf8, 0x3e, 0x15, 0x31, 0x29, 0x28, 0x09, 0x98, 0xeb, 0x69,
-	0xc0, 0x14, 0x27, 0x1f, 0xec, 0x93, 0x70, 0x49, 0x17, 0x26, 0xa3, 0xe5, 0x2e, 0x2c, 0x27, 0xfa,
-	0xe2, 0x5d, 0x32, 0x5c, 0x5e, 0x3b, 0xeb, 0x58, 0x97, 0xe0, 0x75, 0x14, 0xba, 0x3f, 0x25, 0xca,
-	0xf2, 0xe4, 0x56, 0x3c, 0x29, 0xc9, 0x64, 0xa4, 0x49, 0x45, 0x3e, 0x80, 0x2b, 0x63, 0x51, 0x89,
-	0x39, 0xc1, 0xf4, 0xb5, 0x40, 0x93, 0x63, 0x32, 0xd4, 0x24, 0x50, 0x9d, 0x96, 0x14, 0xe3, 0xe2,
-	0x59, 0xd3, 0x4a, 0xa4, 0x38, 0x35, 0x2d, 0xd1, 0x81, 0x76, 0x61, 0xa1, 0x4f, 0x28, 0x6e, 0x63,
-	0x8a, 0xcd, 0x12, 0xdf, 0x96, 0x5e, 0x9f, 0x0a, 0x10, 0x89, 0xae, 0xbf, 0x2f, 0x0d, 0xef, 0x7a,
-	0x34, 0x3c, 0x91, 0x27, 0xeb, 0x14, 0xbd, 0xf1, 0x03, 0x58, 0xd6, 0x0c, 0xd0, 0x2a, 0xe4, 0x8e,
-	0x48, 0xf2, 0x03, 0x15, 0x2b, 0xa2, 0x35, 0x28, 0x1c, 0xe3, 0xde, 0x80, 0x70, 0x7d, 0x96, 0x1d,
-	0x51, 0xd9, 0xce, 0xbe, 0x63, 0xd8, 0x65, 0x28, 0x85, 0xe2, 0x2b, 0x76, 0xe7, 0xd9, 0xf3, 0x4a,
-	0xe6, 0xf3, 0xe7, 0x95, 0xcc, 0x17, 0xcf, 0x2b, 0xc6, 0x47, 0xa3, 0x8a, 0xf1, 0xbb, 0x51, 0xc5,
-	0xf8, 0x74, 0x54, 0x31, 0x9e, 0x8d, 0x2a, 0xc6, 0xdf, 0x47, 0x15, 0xe3, 0x9f, 0xa3, 0x4a, 0xe6,
-	0x8b, 0x51, 0xc5, 0xf8, 0xe4, 0x45, 0x25, 0xf3, 0xec, 0x45, 0x25, 0xf3, 0xf9, 0x8b, 0x4a, 0xe6,
-	0x67, 0xd7, 0xcf, 0xbd, 0x43, 0x1e, 0x14, 0x39, 0x53, 0x5b, 0xff, 0x0e, 0x00, 0x00, 0xff, 0xff,
-	0x6b, 0xd4, 0xf8, 0x77, 0xa9, 0x1f, 0x00, 0x00,
+	// 1975 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0x4d, 0x6f, 0x1b, 0xc7,
+	0x19, 0xe6, 0xf2, 0x4b, 0xe2, 0x50, 0xa2, 0xd5, 0xb1, 0xaa, 0x6c, 0x15, 0x87, 0xcb, 0x12, 0x68,
+	0xa2, 0x16, 0xed, 0x32, 0xa6, 0x12, 0x37, 0x51, 0x53, 0x23, 0x5e, 0xcb, 0xae, 0xec, 0x3a, 0x8d,
+	0xb3, 0x12, 0x7a, 0xe8, 0xa5, 0x18, 0x91, 0x23, 0x72, 0x2b, 0x72, 0x77, 0xbd, 0x3b, 0x94, 0x2d,
+	0xa0, 0x28, 0xf2, 0x07, 0x82, 0xe6, 0xde, 0x7b, 0xd1, 0x5b, 0x51, 0xa0, 0xe8, 0xa1, 0xa7, 0x1e,
+	0x93, 0x43, 0x01, 0x1f, 0x03, 0x02, 0xdd, 0xd6, 0xf4, 0xa5, 0xd0, 0x29, 0x40, 0xff, 0x40, 0x31,
+	0x1f, 0xbb, 0x9c, 0xe1, 0xae, 0x6a, 0x52, 0x2d, 0x0a, 0x28, 0xf0, 0x85, 0x9c, 0x9d, 0x79, 0x9f,
+	0xd9, 0x77, 0xde, 0xe7, 0x79, 0xe7, 0x6b, 0xc1, 0x1b, 0xfe, 0x71, 0xaf, 0xf5, 0x68, 0x84, 0x03,
+	0x07, 0x07, 0xec, 0xff, 0x34, 0x40, 0x6e, 0x0f, 0x4b, 0x45, 0xd3, 0x0f, 0x3c, 0xe2, 0x41, 0x30,
+	0xad, 0xd9, 0x6c, 0xf7, 0x1c, 0xd2, 0x1f, 0x1d, 0x9a, 0x1d, 0x6f, 0xd8, 0xea, 0x79, 0x3d, 0xaf,
+	0xd5, 0xf3, 0xbc, 0xde, 0x00, 0x23, 0xdf, 0x09, 0x45, 0xb1, 0x15, 0xf8, 0x9d, 0x56, 0x48, 0x10,
+	0x19, 0x85, 0x1c, 0xbf, 0xb9, 0x4e, 0x0d, 0x59, 0x91, 0x41, 0x44, 0xad, 0x21, 0xcc, 0xd9, 0xd3,
+	0xe1, 0xe8, 0xa8, 0x45, 0x9c, 0x21, 0x0e, 0x09, 0x1a, 0xfa, 0xb1, 0x01, 0xf5, 0x6f, 0xe0, 0xf5,
+	0x38, 0xd2, 0x71, 0xbb, 0xf8, 0x49, 0x0f, 0x11, 0xfc, 0x18, 0x9d, 0x0a, 0x83, 0x57, 0x15, 0x83,
+	0xb8, 0x20, 0x1a, 0x37, 0x95, 0x46, 0x1f, 0x11, 0x82, 0x03, 0x57, 0xb4, 0x7d, 0x43, 0x69, 0x0b,
+	0x8f, 0x31, 0xe9, 0xf4, 0x45, 0x53, 0x43, 0x34, 0x3d, 0x1a, 0x0c, 0xbd, 0x2e, 0x1e, 0xb0, 0x81,
+	0x84, 0xfc, 0x57, 0x58, 0x5c, 0xa5, 0x16, 0xfe, 0x28, 0xec, 0xb3, 0x1f, 0x51, 0x79, 0xfb, 0x85,
+	0xb1, 0x3c, 0x44, 0x21, 0x6e, 0x75, 0xf1, 0x91, 0xe3, 0x3a, 0xc4, 0xf1, 0xdc, 0x50, 0x2e, 0x8b,
+	0x4e, 0x6e, 0xcc, 0xd7, 0xc9, 0x2c, 0x3f, 0x9b, 0x6f, 0x52, 0x5c, 0x48, 0xbc, 0x00, 0xf5, 0x70,
+	0xab, 0xd3, 0x1f, 0xb9, 0xc7, 0xad, 0x0e, 0xea, 0xf4, 0x71, 0x2b, 0xc0, 0xe1, 0x68, 0x40, 0x42,
+	0xfe, 0x40, 0x4e, 0x7d, 0x2c, 0xde, 0xd4, 0xfc, 0xbc, 0x08, 0xaa, 0x0f, 0xbc, 0x63, 0xc7, 0xc6,
+	0x8f, 0x46, 0x38, 0x24, 0x70, 0x1d, 0x94, 0x58, 0xaf, 0xba, 0xd6, 0xd0, 0xb6, 0x2a, 0x36, 0x7f,
+	0xa0, 0xb5, 0x03, 0x67, 0xe8, 0x10, 0x3d, 0xdf, 0xd0, 0xb6, 0x56, 0x6d, 0xfe, 0x00, 0x21, 0x28,
+	0x86, 0x04, 0xfb, 0x7a, 0xa1, 0xa1, 0x6d, 0x15, 0x6c, 0x56, 0x86, 0x9b, 0x60, 0xd9, 0x71, 0x09,
+	0x0e, 0x4e, 0xd0, 0x40, 0xaf, 0xb0, 0xfa, 0xe4, 0x19, 0xde, 0x04, 0x4b, 0x21, 0x41, 0x01, 0x39,
+	0x08, 0xf5, 0x62, 0x43, 0xdb, 0xaa, 0xb6, 0x37, 0x4d, 0xce, 0xbc, 0x19, 0x33, 0x6f, 0x1e, 0xc4,
+	0xcc, 0x5b, 0xcb, 0x9f, 0x45, 0x46, 0xee, 0xd3, 0xbf, 0x1b, 0x9a, 0x1d, 0x83, 0xe0, 0x0e, 0x28,
+	0x61, 0xb7, 0x7b, 0x10, 0xea, 0xa5, 0x05, 0xd0, 0x1c, 0x02, 0xaf, 0x83, 0x4a, 0xd7, 0x09, 0x70,
+	0x87, 0x46, 0x59, 0x2f, 0x37, 0xb4, 0xad, 0x5a, 0xfb, 0xaa, 0x99, 0x08, 0x65, 0x37, 0x6e, 0xb2,
+	0xa7, 0x56, 0x74, 0x78, 0x3e, 0x22, 0x7d, 0x7d, 0x89, 0x45, 0x82, 0x95, 0x61, 0x13, 0x94, 0xc3,
+	0x3e, 0x0a, 0xba, 0xa1, 0xbe, 0xdc, 0x28, 0x6c, 0x55, 0x2c, 0x70, 0x16, 0x19, 0xa2, 0xc6, 0x16,
+	0xff, 0xf0, 0xe7, 0xa0, 0xe8, 0x0f, 0x90, 0xab, 0x03, 0xe6, 0xe5, 0x9a, 0x29, 0xb1, 0xf4, 0x70,
+	0x80, 0x5c, 0xeb, 0xdd, 0x71, 0x64, 0xbc, 0x2d, 0x27, 0x4f, 0x80, 0x8e, 0x90, 0x8b, 0x5a, 0x03,
+	0xef, 0xd8, 0x69, 0x9d, 0x6c, 0xb7, 0x64, 0xee, 0x69, 0x47, 0xe6, 0x47, 0xb4, 0x03, 0x0a, 0xb5,
+	0x59, 0xc7, 0xf0, 0x3e, 0xa8, 0x52, 0x8e, 0xf1, 0x6d, 0x4a, 0x70, 0xa8, 0x57, 0xd9, 0x7b, 0x5e,
+	0x99, 0x8e, 0x86, 0xd5, 0xdb, 0xf8, 0xe8, 0x47, 0x81, 0x37, 0xf2, 0xad, 0x2b, 0x67, 0x91, 0x21,
+	0xdb, 0xdb, 0xf2, 0x03, 0xbc, 0x0f, 0x6a, 0x54, 0x14, 0x8e, 0xdb, 0xfb, 0xd0, 0x67, 0x0a, 0xd4,
+	0x57, 0x58, 0x77, 0xd7, 0x4c, 0x59, 0x32, 0xe6, 0x6d, 0xc5, 0xc6, 0x2a, 0xd2, 0xf0, 0xda, 0x33,
+	0xc8, 0xe6, 0xa4, 0x00, 0x20, 0xd5, 0xd2, 0x3d, 0x37, 0x24, 0xc8, 0x25, 0x17, 0x91, 0xd4, 0x7b,
+	0xa0, 0x4c, 0x93, 0xff, 0x20, 0xd4, 0x0b, 0x0b, 0x70, 0x2c, 0x30, 0x2a, 0xc9, 0xc5, 0x85, 0x48,
+	0x2e, 0x65, 0x92, 0x5c, 0x7e, 0x21, 0xc9, 0x4b, 0xff, 0x27, 0x92, 0x97, 0xff, 0xb7, 0x24, 0x57,
+	0x2e, 0x4c, 0xb2, 0x0e, 0x8a, 0xd4, 0x4b, 0xb8, 0x06, 0x0a, 0x01, 0x7a, 0xcc, 0x38, 0x5d, 0xb1,
+	0x69, 0xb1, 0x39, 0x29, 0x82, 0x15, 0x3e, 0x95, 0x84, 0xbe, 0xe7, 0x86, 0x98, 0xc6, 0x71, 0x9f,
+	0xcd, 0xfe, 0x9c, 0x79, 0x11, 0x47, 0x56, 0x63, 0x8b, 0x16, 0xf8, 0x3e, 0x28, 0xee, 0x22, 0x82,
+	0x98, 0x0a, 0xaa, 0xed, 0x75, 0x39, 0x8e, 0xb4, 0x2f, 0xda, 0x66, 0x6d, 0x50, 0x47, 0xce, 0x22,
+	0xa3, 0xd6, 0x45, 0x04, 0x7d, 0xd7, 0x1b, 0x3a, 0x04, 0x0f, 0x7d, 0x72, 0x6a, 0x33, 0x24, 0x7c,
+	0x1b, 0x54, 0xee, 0x04, 0x81, 0x17, 0x1c, 0x9c, 0xfa, 0x98, 0xa9, 0xa6, 0x62, 0xbd, 0x72, 0x16,
+	0x19, 0x57, 0x71, 0x5c, 0x29, 0x21, 0xa6, 0x96, 0xf0, 0xdb, 0xa0, 0xc4, 0x1e, 0x98, 0x4e, 0x2a,
+	0xd6, 0xd5, 0xb3, 0xc8, 0xb8, 0xc2, 0x20, 0x92, 0x39, 0xb7, 0x50, 0x65, 0x55, 0x9a, 0x4b, 0x56,
+	0x89, 0xba, 0xcb, 0xb2, 0xba, 0x75, 0xb0, 0x74, 0x82, 0x83, 0xd0, 0xf1, 0xb8, 0x6e, 0x56, 0xed,
+	0xf8, 0x11, 0xde, 0x02, 0x80, 0x06, 0xc6, 0x09, 0x89, 0xd3, 0x89, 0xc9, 0x5e, 0x35, 0xf9, 0x62,
+	0x63, 0x33, 0x8e, 0x2c, 0x28, 0xa2, 0x20, 0x19, 0xda, 0x52, 0x19, 0xfe, 0x5e, 0x03, 0x4b, 0x7b,
+	0x18, 0x75, 0x71, 0x40, 0xe9, 0x2d, 0x6c, 0x55, 0xdb, 0xdf, 0x32, 0xe5, 0x95, 0xe5, 0x61, 0xe0,
+	0x0d, 0x31, 0xe9, 0xe3, 0x51, 0x18, 0x13, 0xc4, 0xad, 0x2d, 0x77, 0x1c, 0x19, 0x78, 0x4e, 0xa9,
+	0xce, 0xb5, 0xa0, 0x9d, 0xfb, 0xaa, 0xb3, 0xc8, 0xd0, 0xbe, 0x67, 0xc7, 0x5e, 0xc2, 0x36, 0x58,
+	0x7e, 0x8c, 0x02, 0xd7, 0x71, 0x7b, 0xa1, 0x0e, 0x58, 0xa6, 0x6d, 0x9c, 0x45, 0x06, 0x8c, 0xeb,
+	0x24, 0x22, 0x12, 0xbb, 0xe6, 0xdf, 0x34, 0xf0, 0x35, 0x2a, 0x8c, 0x7d, 0xea, 0x4f, 0x28, 0x4d,
+	0x31, 0x43, 0x44, 0x3a, 0x7d, 0x5d, 0xa3, 0xdd, 0xd8, 0xfc, 0x41, 0x5e, 0x6f, 0xf2, 0xff, 0xd5,
+	0x7a, 0x53, 0x58, 0x7c, 0xbd, 0x89, 0xe7, 0x95, 0x62, 0xe6, 0xbc, 0x52, 0x3a, 0x6f, 0x5e, 0x69,
+	0xfe, 0x5a, 0xcc, 0xa1, 0xf1, 0xf8, 0x16, 0x48, 0xa5, 0xbb, 0x49, 0x2a, 0x15, 0x98, 0xb7, 0x89,
+	0x42, 0x79, 0x5f, 0xf7, 0xba, 0xd8, 0x25, 0xce, 0x91, 0x83, 0x83, 0x17, 0x24, 0x94, 0xa4, 0xd2,
+	0x82, 0xaa, 0x52, 0x59, 0x62, 0xc5, 0x4b, 0x21, 0x31, 0x35, 0xaf, 0x4a, 0x17, 0xc8, 0xab, 0xe6,
+	0xbf, 0xf2, 0x60, 0x83, 0x32, 0xf2, 0x00, 0x1d, 0xe2, 0xc1, 0x4f, 0xd0, 0x70, 0x41, 0x56, 0x5e,
+	0x97, 0x58, 0xa9, 0x58, 0xf0, 0x65, 0xd4, 0xe7, 0x8b, 0xfa, 0x6f, 0x35, 0xb0, 0x1c, 0x2f, 0x00,
+	0xd0, 0x04, 0x80, 0xc3, 0xd8, 0x1c, 0xcf, 0x63, 0x5d, 0xa3, 0xe0, 0x20, 0xa9, 0xb5, 0x25, 0x0b,
+	0xf8, 0x0b, 0x50, 0xe6, 0x4f, 0x22, 0x17, 0xa4, 0x65, 0x73, 0x9f, 0x04, 0x18, 0x0d, 0x6f, 0x75,
+	0x91, 0x4f, 0x70, 0x60, 0xbd, 0x4b, 0xbd, 0x18, 0x47, 0xc6, 0x1b, 0xe7, 0x45, 0x29, 0xde, 0xe1,
+	0x0b, 0x1c, 0xe5, 0x97, 0xbf, 0xd3, 0x16, 0x6f, 0x68, 0x7e, 0xa2, 0x81, 0x35, 0xea, 0x28, 0x0d,
+	0x4d, 0x22, 0x8c, 0x5d, 0xb0, 0x1c, 0x88, 0x32, 0x73, 0xb7, 0xda, 0x6e, 0x9a, 0x6a, 0x58, 0x33,
+	0x42, 0xc9, 0x16, 0x5c, 0xcd, 0x4e, 0x90, 0x70, 0x5b, 0x09, 0x63, 0x3e, 0x2b, 0x8c, 0x7c, 0x8d,
+	0x96, 0x03, 0xf7, 0x97, 0x3c, 0x80, 0xf7, 0xe8, 0x09, 0x89, 0xea, 0x6f, 0x2a, 0xd5, 0x27, 0x29,
+	0x8f, 0xae, 0x4d, 0x83, 0x92, 0xb6, 0xb7, 0x6e, 0x8e, 0x23, 0x63, 0xe7, 0x05, 0xda, 0xf9, 0x0f,
+	0x78, 0x69, 0x14, 0xb2, 0x7c, 0xf3, 0x97, 0x41, 0xbe, 0xcd, 0x3f, 0xe6, 0x41, 0xed, 0xa7, 0xde,
+	0x60, 0x34, 0xc4, 0x49, 0xf8, 0xfc, 0x54, 0xf8, 0xf4, 0x69, 0xf8, 0x54, 0x5b, 0x6b, 0x67, 0x1c,
+	0x19, 0x37, 0xe6, 0x0d, 0x9d, 0x8a, 0xbd, 0xd4, 0x61, 0xfb, 0x4d, 0x01, 0xac, 0x1f, 0x78, 0xfe,
+	0x8f, 0xf7, 0xd9, 0x29, 0x5a, 0x9a, 0x26, 0xfb, 0xa9, 0xe0, 0xad, 0x4f, 0x83, 0x47, 0x11, 0x1f,
+	0x20, 0x12, 0x38, 0x4f, 0xac, 0x1b, 0xe3, 0xc8, 0x68, 0xcf, 0x1b, 0xb8, 0x29, 0xee, 0x32, 0x07,
+	0x4d, 0xd9, 0x03, 0x15, 0xe6, 0xdb, 0x03, 0xcd, 0xcc, 0x0b, 0xc5, 0xf9, 0xe6, 0x85, 0x3f, 0x14,
+	0xc0, 0xc6, 0x47, 0x23, 0xe4, 0x12, 0x67, 0x80, 0x39, 0x43, 0x09, 0x3f, 0xbf, 0x4c, 0xf1, 0x53,
+	0x9f, 0xf2, 0xa3, 0x62, 0x04, 0x53, 0xef, 0x8f, 0x23, 0xe3, 0xbd, 0x79, 0x99, 0xca, 0xea, 0xe1,
+	0x25, 0x67, 0xf3, 0x72, 0xb6, 0x4b, 0xcb, 0x6e, 0x87, 0x2c, 0xc2, 0x99, 0x8a, 0xb9, 0x08, 0x67,
+	0x59, 0x3d, 0xbc, 0xe4, 0x6c, 0x1e, 0xce, 0xfe, 0x9c, 0x07, 0xb5, 0x7d, 0xbe, 0xa7, 0x8f, 0xa3,
+	0x75, 0x92, 0xc1, 0x95, 0x7c, 0x89, 0xe9, 0x1f, 0x9a, 0x2a, 0x62, 0xb1, 0x25, 0x44, 0xc5, 0x5e,
+	0xea, 0x25, 0xe4, 0xaf, 0x79, 0xb0, 0xb1, 0x8b, 0x09, 0xee, 0x10, 0xdc, 0xbd, 0xeb, 0xe0, 0x81,
+	0x14, 0xc4, 0x8f, 0xb5, 0x54, 0x14, 0x1b, 0x92, 0xe2, 0x33, 0x41, 0x96, 0x35, 0x8e, 0x8c, 0x9b,
+	0x73, 0x6b, 0x3e, 0xb3, 0x8f, 0x4b, 0x1d, 0xcf, 0xcf, 0xf3, 0xe0, 0xeb, 0xfc, 0x62, 0x89, 0xdf,
+	0x7a, 0x4f, 0xc3, 0xf9, 0xab, 0x54, 0x34, 0x0d, 0x79, 0xce, 0xcf, 0x80, 0x58, 0xb7, 0xc6, 0x91,
+	0xf1, 0xc3, 0xf9, 0x27, 0xfd, 0x8c, 0x2e, 0xbe, 0x32, 0xda, 0x64, 0x67, 0xc1, 0x45, 0xb5, 0xa9,
+	0x82, 0x2e, 0xa6, 0x4d, 0xb5, 0x8f, 0x4b, 0x1d, 0xcf, 0x3f, 0x2d, 0x81, 0x55, 0xa6, 0x92, 0x24,
+	0x8c, 0xdf, 0x01, 0xe2, 0xf0, 0x2c, 0x62, 0x08, 0xe3, 0x0b, 0x97, 0xc0, 0xef, 0x98, 0xfb, 0xe2,
+	0x58, 0xcd, 0x2d, 0xe0, 0x3b, 0xa0, 0x1c, 0x52, 0xa7, 0xe2, 0x73, 0x51, 0x7d, 0xf6, 0xe6, 0x50,
+	0xbd, 0x40, 0xd9, 0xcb, 0xd9, 0xc2, 0x9e, 0x5e, 0x31, 0x0f, 0x58, 0x14, 0xf5, 0x42, 0xea, 0x64,
+	0x66, 0x66, 0x1f, 0xf4, 0x29, 0x9a, 0x63, 0xe0, 0x0d, 0x50, 0x62, 0x0b, 0x80, 0x5e, 0x4c, 0xbf,
+	0x36, 0x7d, 0x0c, 0xda, 0xcb, 0xd9, 0xdc, 0x1c, 0xb6, 0x41, 0xd1, 0x0f, 0xbc, 0xa1, 0x38, 0x0c,
+	0x5f, 0x9b, 0x7d, 0xa7, 0x7c, 0x7a, 0xdc, 0xcb, 0xd9, 0xcc, 0x16, 0xbe, 0x45, 0xef, 0xaf, 0xe8,
+	0xb1, 0x33, 0xd4, 0xcb, 0xe2, 0xcc, 0x31, 0x03, 0x93, 0x20, 0xb1, 0x29, 0x7c, 0x0b, 0x94, 0x4f,
+	0xd8, 0xa1, 0x42, 0xdc, 0x4d, 0x6f, 0xca, 0x20, 0xf5, 0xb8, 0x41, 0xc7, 0xc5, 0x6d, 0xe1, 0x5d,
+	0xb0, 0x42, 0x3c, 0xff, 0x38, 0xde, 0xbb, 0x8b, 0x2b, 0xc8, 0x86, 0x8c, 0xcd, 0xda, 0xdb, 0xef,
+	0xe5, 0x6c, 0x05, 0x07, 0x1f, 0x82, 0xb5, 0x47, 0xca, 0x7e, 0x0f, 0xc7, 0x97, 0xcd, 0x4a, 0x9c,
+	0xb3, 0x77, 0xa2, 0x7b, 0x39, 0x3b, 0x85, 0x86, 0xbb, 0xa0, 0x16, 0x2a, 0x2b, 0x9c, 0x0e, 0xd2,
+	0xe3, 0x52, 0xd7, 0xc0, 0xbd, 0x9c, 0x3d, 0x83, 0x81, 0x0f, 0x40, 0xad, 0xab, 0xcc, 0xef, 0x7a,
+	0x35, 0xed, 0x55, 0xf6, 0x0a, 0x40, 0x7b, 0x53, 0xb1, 0xf0, 0x43, 0xb0, 0xe6, 0xcf, 0xcc, 0x6d,
+	0xe2, 0xbb, 0xc9, 0x37, 0xd5, 0x51, 0x66, 0x4c, 0x82, 0x74, 0x90, 0xb3, 0x60, 0xd9, 0x3d, 0x9e,
+	0xe2, 0xfa, 0xea, 0xf9, 0xee, 0xa9, 0x93, 0x80, 0xec, 0x1e, 0x6f, 0xa1, 0x24, 0x74, 0x95, 0x0d,
+	0x1c, 0x0e, 0xf5, 0x5a, 0x46, 0x7f, 0x99, 0x5b, 0x4b, 0xea, 0xdf, 0x2c, 0xda, 0x02, 0xd3, 0x09,
+	0xae, 0xf9, 0x49, 0x19, 0xac, 0x88, 0xc4, 0xe5, 0xb7, 0xaf, 0xdf, 0x4f, 0x72, 0x91, 0xe7, 0xed,
+	0x6b, 0xe7, 0xe5, 0x22, 0x33, 0x97, 0x52, 0xf1, 0xcd, 0x24, 0x15, 0x79, 0x12, 0x6f, 0x4c, 0x27,
+	0x4d, 0x36, 0x12, 0x09, 0x21, 0xd2, 0x6f, 0x3b, 0x4e, 0x3f, 0x9e, 0xbb, 0xaf, 0x66, 0xdf, 0x61,
+	0xc4, 0x28, 0x91, 0x7b, 0x3b, 0x60, 0xc9, 0xe1, 0x9f, 0xa4, 0xb2, 0xb2, 0x36, 0xfd, 0xc5, 0x8a,
+	0x66, 0x93, 0x00, 0xc0, 0xed, 0x69, 0x0e, 0x96, 0xc4, 0x27, 0x98, 0x54, 0x0e, 0x26, 0xa0, 0x38,
+	0x05, 0xaf, 0x27, 0x29, 0x58, 0x9e, 0xfd, 0x6c, 0x13, 0x27, 0x60, 0x32, 0x30, 0x91, 0x7f, 0x77,
+	0xc0, 0x6a, 0xac, 0x58, 0xd6, 0x24, 0x12, 0xf0, 0xb5, 0xf3, 0x36, 0x8a, 0x31, 0x5e, 0x45, 0xc1,
+	0x7b, 0x29, 0x99, 0x57, 0x66, 0x17, 0xf7, 0x59, 0x91, 0xc7, 0x3d, 0xcd, 0x6a, 0xfc, 0x3e, 0xb8,
+	0x32, 0x95, 0x29, 0xf7, 0x09, 0xa4, 0x0f, 0x87, 0x8a, 0xc0, 0xe3, 0xae, 0x66, 0x81, 0xb2, 0x5b,
+	0x42, 0xde, 0xd5, 0xf3, 0xdc, 0x8a, 0xc5, 0x9d, 0x72, 0x4b, 0x68, 0x7b, 0x0f, 0x2c, 0x0f, 0x31,
+	0x41, 0xf4, 0x0e, 0x55, 0x5f, 0x62, 0x0b, 0xdd, 0xeb, 0xa9, 0x94, 0x13, 0x68, 0xf3, 0x03, 0x61,
+	0x78, 0xc7, 0x25, 0xc1, 0xa9, 0xd8, 0xab, 0x27, 0xe8, 0xcd, 0x1f, 0x80, 0x55, 0xc5, 0x80, 0x7e,
+	0xd2, 0x3a, 0xc6, 0xf1, 0x67, 0x4a, 0x5a, 0xa4, 0xdf, 0x15, 0x4e, 0xd0, 0x60, 0x84, 0x99, 0x3e,
+	0x2b, 0x36, 0x7f, 0xd8, 0xc9, 0xbf, 0xa3, 0x59, 0x15, 0xb0, 0x14, 0xf0, 0xb7, 0x58, 0xbd, 0xa7,
+	0xcf, 0xea, 0xb9, 0x2f, 0x9e, 0xd5, 0x73, 0x5f, 0x3e, 0xab, 0x6b, 0x1f, 0x4f, 0xea, 0xda, 0xef,
+	0x26, 0x75, 0xed, 0xb3, 0x49, 0x5d, 0x7b, 0x3a, 0xa9, 0x6b, 0xff, 0x98, 0xd4, 0xb5, 0x7f, 0x4e,
+	0xea, 0xb9, 0x2f, 0x27, 0x75, 0xed, 0xd3, 0xe7, 0xf5, 0xdc, 0xd3, 0xe7, 0xf5, 0xdc, 0x17, 0xcf,
+	0xeb, 0xb9, 0x9f, 0x5d, 0x5f, 0x78, 0xcd, 0x3d, 0x2c, 0xb3, 0x48, 0x6d, 0xff, 0x7b, 0x00, 0x8e,
+	0xb5, 0x5c, 0x36, 0xaf, 0x21, 0x00, 0x00,
 }
 
 func (this *LokiRequest) Equal(that interface{}) bool {
@@ -2200,6 +2269,53 @@ func (this *QuantileSketchResponse) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *DistinctSketchResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*DistinctSketchResponse)
+	if !ok {
+		that2, ok := that.(DistinctSketchResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if that1.Response == nil {
+		if this.Response != nil {
+			return false
+		}
+	} else if !this.Response.Equal(*that1.Response) {
+		return false
+	}
+	if len(this.Headers) != len(that1.Headers) {
+		return false
+	}
+	for i := range this.Headers {
+		if !this.Headers[i].Equal(that1.Headers[i]) {
+			return false
+		}
+	}
+	if len(this.Warnings) != len(that1.Warnings) {
+		return false
+	}
+	for i := range this.Warnings {
+		if this.Warnings[i] != that1.Warnings[i] {
+			return false
+		}
+	}
+	if !this.Statistics.Equal(&that1.Statistics) {
+		return false
+	}
+	return true
+}
 func (this *ShardsResponse) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -2665,6 +2781,30 @@ func (this *QueryResponse_DetectedLabels) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *QueryResponse_DistinctSketches) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*QueryResponse_DistinctSketches)
+	if !ok {
+		that2, ok := that.(QueryResponse_DistinctSketches)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.DistinctSketches.Equal(that1.DistinctSketches) {
+		return false
+	}
+	return true
+}
 func (this *QueryRequest) Equal(that interface{}) bool {
 	if that == nil {
 		return this == nil
@@ -3135,6 +3275,19 @@ func (this *QuantileSketchResponse) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *DistinctSketchResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&queryrange.DistinctSketchResponse{")
+	s = append(s, "Response: "+fmt.Sprintf("%#v", this.Response)+",\n")
+	s = append(s, "Headers: "+fmt.Sprintf("%#v", this.Headers)+",\n")
+	s = append(s, "Warnings: "+fmt.Sprintf("%#v", this.Warnings)+",\n")
+	s = append(s, "Statistics: "+strings.Replace(this.Statistics.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func (this *ShardsResponse) GoString() string {
 	if this == nil {
 		return "nil"
@@ -3183,7 +3336,7 @@ func (this *QueryResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 17)
+	s := make([]string, 0, 18)
 	s = append(s, "&queryrange.QueryResponse{")
 	if this.Status != nil {
 		s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
@@ -3290,6 +3443,14 @@ func (this *QueryResponse_DetectedLabels) GoString() string {
 		`DetectedLabels:` + fmt.Sprintf("%#v", this.DetectedLabels) + `}`}, ", ")
 	return s
 }
+func (this *QueryResponse_DistinctSketches) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&queryrange.QueryResponse_DistinctSketches{` +
+		`DistinctSketches:` + fmt.Sprintf("%#v", this.DistinctSketches) + `}`}, ", ")
+	return s
+}
 func (this *QueryRequest) GoString() string {
 	if this == nil {
 		return "nil"
@@ -4277,6 +4438,74 @@ func (m *QuantileSketchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	return len(dAtA) - i, nil
 }
 
+func (m *DistinctSketchResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DistinctSketchResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DistinctSketchResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	{
+		size, err := m.Statistics.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintQueryrange(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x22
+	if len(m.Warnings) > 0 {
+		for iNdEx := len(m.Warnings) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Warnings[iNdEx])
+			copy(dAtA[i:], m.Warnings[iNdEx])
+			i = encodeVarintQueryrange(dAtA, i, uint64(len(m.Warnings[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Headers) > 0 {
+		for iNdEx := len(m.Headers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.Headers[iNdEx].Size()
+				i -= size
+				if _, err := m.Headers[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintQueryrange(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Response != nil {
+		{
+			size := m.Response.Size()
+			i -= size
+			if _, err := m.Response.MarshalTo(dAtA[i:]); err != nil {
+				return 0, err
+			}
+			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *ShardsResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -4757,6 +4986,26 @@ func (m *QueryResponse_DetectedLabels) MarshalToSizedBuffer(dAtA []byte) (int, e
 	}
 	return len(dAtA) - i, nil
 }
+func (m *QueryResponse_DistinctSketches) MarshalTo(dAtA []byte) (int, error) {
+	return m.MarshalToSizedBuffer(dAtA[:m.Size()])
+}
+
+func (m *QueryResponse_DistinctSketches) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.DistinctSketches != nil {
+		{
+			size, err := m.DistinctSketches.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintQueryrange(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x72
+	}
+	return len(dAtA) - i, nil
+}
 func (m *QueryRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -5383,6 +5632,33 @@ func (m *QuantileSketchResponse) Size() (n int) {
 	return n
 }
 
+func (m *DistinctSketchResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Response != nil {
+		l = m.Response.Size()
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
+	if len(m.Headers) > 0 {
+		for _, e := range m.Headers {
+			l = e.Size()
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	if len(m.Warnings) > 0 {
+		for _, s := range m.Warnings {
+			l = len(s)
+			n += 1 + l + sovQueryrange(uint64(l))
+		}
+	}
+	l = m.Statistics.Size()
+	n += 1 + l + sovQueryrange(uint64(l))
+	return n
+}
+
 func (m *ShardsResponse) Size() (n int) {
 	if m == nil {
 		return 0
@@ -5619,6 +5895,18 @@ func (m *QueryResponse_DetectedLabels) Size() (n int) {
 	}
 	return n
 }
+func (m *QueryResponse_DistinctSketches) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.DistinctSketches != nil {
+		l = m.DistinctSketches.Size()
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
+	return n
+}
 func (m *QueryRequest) Size() (n int) {
 	if m == nil {
 		return 0
@@ -5951,6 +6239,19 @@ func (this *QuantileSketchResponse) String() string {
 	}, "")
 	return s
 }
+func (this *DistinctSketchResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&DistinctSketchResponse{`,
+		`Response:` + fmt.Sprintf("%v", this.Response) + `,`,
+		`Headers:` + fmt.Sprintf("%v", this.Headers) + `,`,
+		`Warnings:` + fmt.Sprintf("%v", this.Warnings) + `,`,
+		`Statistics:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Statistics), "Result", "stats.Result", 1), `&`, ``, 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *ShardsResponse) String() string {
 	if this == nil {
 		return "nil"
@@ -6126,6 +6427,16 @@ func (this *QueryResponse_DetectedLabels) String() string {
 	}, "")
 	return s
 }
+func (this *QueryResponse_DistinctSketches) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&QueryResponse_DistinctSketches{`,
+		`DistinctSketches:` + strings.Replace(fmt.Sprintf("%v", this.DistinctSketches), "DistinctSketchResponse", "DistinctSketchResponse", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *QueryRequest) String() string {
 	if this == nil {
 		return "nil"
@@ -8887,6 +9198,194 @@ func (m *QuantileSketchResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *DistinctSketchResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowQueryrange
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DistinctSketchResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DistinctSketchResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Response", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Response == nil {
+				m.Response = &github_com_grafana_loki_v3_pkg_logproto.DistinctSketchMatrix{}
+			}
+			if err := m.Response.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Headers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Headers = append(m.Headers, github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader{})
+			if err := m.Headers[len(m.Headers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Warnings", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Statistics", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Statistics.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipQueryrange(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *ShardsResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -9864,6 +10363,41 @@ func (m *QueryResponse) Unmarshal(dAtA []byte) error {
 			}
 			m.Response = &QueryResponse_DetectedLabels{v}
 			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DistinctSketches", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &DistinctSketchResponse{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Response = &QueryResponse_DistinctSketches{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
@@ -149,43 +149,32 @@ func (ast *astMapperware) checkQuerySizeLimit(ctx context.Context, bytesPerShard
 	return nil
 }
 
-func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
-	logger := util_log.WithContext(ctx, ast.logger)
+// shardingStrategy returns the strategy used to shard the query of the
+// request, or false if the query cannot be sharded.
+func (ast *astMapperware) shardingStrategy(ctx context.Context, r queryrangebase.Request, params logql.Params) (logql.ShardingStrategy, bool, error) {
 	spLogger := spanlogger.FromContextWithFallback(
 		ctx,
-		logger,
+		util_log.WithContext(ctx, ast.logger),
 	)
 
-	params, err := ParamsFromRequest(r)
-	if err != nil {
-		return nil, err
-	}
-
 	maxRVDuration, maxOffset, err := maxRangeVectorAndOffsetDuration(params.GetExpression())
 	if err != nil {
 		level.Warn(spLogger).Log("err", err.Error(), "msg", "failed to get range-vector and offset duration so skipped AST mapper for request")
-		return ast.next.Do(ctx, r)
+		return nil, false, nil
 	}
 
 	conf, err := ast.confs.GetConf(int64(model.Time(r.GetStart().UnixMilli()).Add(-maxRVDuration).Add(-maxOffset)), int64(model.Time(r.GetEnd().UnixMilli()).Add(-maxOffset)))
 	// cannot shard with this timerange
 	if err != nil {
 		level.Warn(spLogger).Log("err", err.Error(), "msg", "skipped AST mapper for request")
-		return ast.next.Do(ctx, r)
+		return nil, false, nil
 	}
 
 	tenants, err := tenant.TenantIDs(ctx)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	// The shard resolver uses index stats to determine the number of shards.
-	// We want to store the cache stats for the requests to get the index stats.
-	// Later on, the query engine overwrites the stats context with other stats,
-	// so we create a separate stats context here for the resolver that we
-	// will merge with the stats returned from the engine.
-	resolverStats, ctx := stats.NewContext(ctx)
-
 	resolver, ok := shardResolverForConf(
 		ctx,
 		conf,
@@ -200,7 +189,7 @@ func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (que
 		ast.limits,
 	)
 	if !ok {
-		return ast.next.Do(ctx, r)
+		return nil, false, nil
 	}
 
 	v := ast.limits.TSDBShardingStrategy(tenants[0])
@@ -214,7 +203,36 @@ func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (que
 			"query", r.GetQuery(),
 		)
 	}
-	strategy := version.Strategy(resolver, uint64(ast.limits.TSDBMaxBytesPerShard(tenants[0])))
+	return version.Strategy(resolver, uint64(ast.limits.TSDBMaxBytesPerShard(tenants[0]))), true, nil
+}
+
+func (ast *astMapperware) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	logger := util_log.WithContext(ctx, ast.logger)
+	spLogger := spanlogger.FromContextWithFallback(
+		ctx,
+		logger,
+	)
+
+	params, err := ParamsFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The shard resolver uses index stats to determine the number of shards.
+	// We want to store the cache stats for the requests to get the index stats.
+	// Later on, the query engine overwrites the stats context with other stats,
+	// so we create a separate stats context here for the resolver that we
+	// will merge with the stats returned from the engine.
+	resolverStats, resolverCtx := stats.NewContext(ctx)
+
+	strategy, ok, err := ast.shardingStrategy(resolverCtx, r, params)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return ast.next.Do(ctx, r)
+	}
+	ctx = resolverCtx
 
 	mapper := logql.NewShardMapper(strategy, ast.metrics, ast.shardAggregation)
 
@@ -325,17 +343,23 @@ func (splitter *shardSplitter) Do(ctx context.Context, r queryrangebase.Request)
 	if err != nil {
 		return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
 	}
-	minShardingLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, splitter.limits.MinShardingLookback)
-	if minShardingLookback == 0 {
+	if shouldShard(splitter.limits, tenantIDs, splitter.now(), r) {
 		return splitter.shardingware.Do(ctx, r)
 	}
-	cutoff := splitter.now().Add(-minShardingLookback)
+	return splitter.next.Do(ctx, r)
+}
+
+// shouldShard returns true if the request is older than the sharding lookback
+// of the tenants.
+func shouldShard(limits Limits, tenantIDs []string, now time.Time, r queryrangebase.Request) bool {
+	minShardingLookback := validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, limits.MinShardingLookback)
+	if minShardingLookback == 0 {
+		return true
+	}
+	cutoff := now.Add(-minShardingLookback)
 	// Only attempt to shard queries which are older than the sharding lookback
 	// (the period for which ingesters are also queried) or when the lookback is disabled.
-	if minShardingLookback == 0 || util.TimeFromMillis(r.GetEnd().UnixMilli()).Before(cutoff) {
-		return splitter.shardingware.Do(ctx, r)
-	}
-	return splitter.next.Do(ctx, r)
+	return util.TimeFromMillis(r.GetEnd().UnixMilli()).Before(cutoff)
 }
 
 func hasShards(confs ShardingConfigs) bool {
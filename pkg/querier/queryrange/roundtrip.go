@@ -270,9 +270,10 @@ func NewMiddleware(
 			seriesVolumeRT   = seriesVolumeTripperware.Wrap(next)
 			detectedFieldsRT = detectedFieldsTripperware.Wrap(next)
 			detectedLabelsRT = detectedLabelsTripperware.Wrap(next)
+			explainRT        = newQueryExplainer(cfg, engineOpts, iqo, log, limits, schema, indexStatsTripperware.Wrap(next), next)
 		)
 
-		return newRoundTripper(log, next, limitedRT, logFilterRT, metricRT, seriesRT, labelsRT, instantRT, statsRT, seriesVolumeRT, detectedFieldsRT, detectedLabelsRT, limits)
+		return newRoundTripper(log, next, limitedRT, logFilterRT, metricRT, seriesRT, labelsRT, instantRT, statsRT, seriesVolumeRT, detectedFieldsRT, detectedLabelsRT, explainRT, limits)
 	}), StopperWrapper{resultsCache, statsCache, volumeCache}, nil
 }
 
@@ -326,13 +327,13 @@ func NewDetectedLabelsCardinalityFilter(rt queryrangebase.Handler) queryrangebas
 type roundTripper struct {
 	logger log.Logger
 
-	next, limited, log, metric, series, labels, instantMetric, indexStats, seriesVolume, detectedFields, detectedLabels base.Handler
+	next, limited, log, metric, series, labels, instantMetric, indexStats, seriesVolume, detectedFields, detectedLabels, explain base.Handler
 
 	limits Limits
 }
 
 // newRoundTripper creates a new queryrange roundtripper
-func newRoundTripper(logger log.Logger, next, limited, log, metric, series, labels, instantMetric, indexStats, seriesVolume, detectedFields, detectedLabels base.Handler, limits Limits) roundTripper {
+func newRoundTripper(logger log.Logger, next, limited, log, metric, series, labels, instantMetric, indexStats, seriesVolume, detectedFields, detectedLabels, explain base.Handler, limits Limits) roundTripper {
 	return roundTripper{
 		logger:         logger,
 		limited:        limited,
@@ -346,10 +347,33 @@ func newRoundTripper(logger log.Logger, next, limited, log, metric, series, labe
 		seriesVolume:   seriesVolume,
 		detectedFields: detectedFields,
 		detectedLabels: detectedLabels,
+		explain:        explain,
 		next:           next,
 	}
 }
 
+// resolveRangeQuery expands the query macros and resolves the `@` modifiers of
+// a range query before it is split and sharded.
+func (r roundTripper) resolveRangeQuery(ctx context.Context, op *LokiRequest) error {
+	// macros are expanded before the query is split and sharded, so that
+	// their stages are part of every subquery.
+	expr, expanded, err := logql.ExpandQueryMacros(ctx, op.Plan.AST, r.limits)
+	if err != nil {
+		return httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+	}
+	if expanded {
+		op.Plan.AST = expr
+		op.Query = expr.String()
+	}
+
+	// `@ start()` and `@ end()` refer to the original query, so they are
+	// resolved before the query is split.
+	if syntax.ResolveAtModifiers(op.Plan.AST, op.StartTs, op.EndTs) {
+		op.Query = op.Plan.AST.String()
+	}
+	return nil
+}
+
 func (r roundTripper) Do(ctx context.Context, req base.Request) (base.Response, error) {
 	logger := logutil.WithContext(ctx, r.logger)
 
@@ -373,6 +397,10 @@ func (r roundTripper) Do(ctx context.Context, req base.Request) (base.Response,
 			return nil, errors.New("query plan is empty")
 		}
 
+		if err := r.resolveRangeQuery(ctx, op); err != nil {
+			return nil, err
+		}
+
 		switch e := op.Plan.AST.(type) {
 		case syntax.SampleExpr:
 			// The error will be handled later.
@@ -406,6 +434,16 @@ func (r roundTripper) Do(ctx context.Context, req base.Request) (base.Response,
 		default:
 			return r.next.Do(ctx, req)
 		}
+	case *ExplainRequest:
+		level.Info(logger).Log("msg", "explaining query", "type", "range", "query", op.Query, "length", op.EndTs.Sub(op.StartTs), "step", op.Step)
+
+		if op.Plan == nil {
+			return nil, errors.New("query plan is empty")
+		}
+		if err := r.resolveRangeQuery(ctx, op.LokiRequest); err != nil {
+			return nil, err
+		}
+		return r.explain.Do(ctx, req)
 	case *LokiSeriesRequest:
 		level.Info(logger).Log("msg", "executing query", "type", "series", "match", logql.PrintMatches(op.Match), "length", op.EndTs.Sub(op.StartTs))
 
@@ -418,6 +456,25 @@ func (r roundTripper) Do(ctx context.Context, req base.Request) (base.Response,
 		queryHash := util.HashedQuery(op.Query)
 		level.Info(logger).Log("msg", "executing query", "type", "instant", "query", op.Query, "query_hash", queryHash)
 
+		if op.Plan == nil {
+			return nil, errors.New("query plan is empty")
+		}
+
+		// macros are expanded before the query is split and sharded, so that
+		// their stages are part of every subquery.
+		expr, expanded, err := logql.ExpandQueryMacros(ctx, op.Plan.AST, r.limits)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+		}
+		if expanded {
+			op.Plan.AST = expr
+			op.Query = expr.String()
+		}
+
+		if syntax.ResolveAtModifiers(op.Plan.AST, op.TimeTs, op.TimeTs) {
+			op.Query = op.Plan.AST.String()
+		}
+
 		switch op.Plan.AST.(type) {
 		case syntax.SampleExpr:
 			return r.instantMetric.Do(ctx, req)
@@ -1005,6 +1005,7 @@ func TestPostQueries(t *testing.T) {
 		handler,
 		handler,
 		handler,
+		handler,
 		fakeLimits{},
 	).Do(ctx, lreq)
 	require.NoError(t, err)
@@ -1409,6 +1410,7 @@ type fakeLimits struct {
 	maxStatsCacheFreshness      time.Duration
 	maxMetadataCacheFreshness   time.Duration
 	volumeEnabled               bool
+	queryMacros                 map[string]string
 }
 
 func (f fakeLimits) QuerySplitDuration(key string) time.Duration {
@@ -1480,6 +1482,14 @@ func (f fakeLimits) MaxQuerySeries(context.Context, string) int {
 	return f.maxSeries
 }
 
+func (f fakeLimits) MaxQueryJoinEntries(context.Context, string) int {
+	return 0
+}
+
+func (f fakeLimits) QueryMacros(context.Context, string) map[string]string {
+	return f.queryMacros
+}
+
 func (f fakeLimits) MaxCacheFreshness(context.Context, string) time.Duration {
 	return 1 * time.Minute
 }
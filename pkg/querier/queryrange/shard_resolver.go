@@ -92,6 +92,10 @@ func getStatsForMatchers(
 	results := make([]*stats.Stats, len(matcherGroups))
 	if err := concurrency.ForEachJob(ctx, len(matcherGroups), parallelism, func(ctx context.Context, i int) error {
 		matchers := syntax.MatchersString(matcherGroups[i].Matchers)
+		start, end := start, end
+		if at := matcherGroups[i].At; at != nil {
+			start, end = model.Time(at.Timestamp), model.Time(at.Timestamp)
+		}
 		diff := matcherGroups[i].Interval + matcherGroups[i].Offset
 		adjustedFrom := start.Add(-diff)
 		if matcherGroups[i].Interval == 0 {
@@ -249,14 +253,19 @@ func (r *dynamicShardResolver) ShardingRanges(expr syntax.Expr, targetBytesPerSh
 
 		diff += grp.Offset
 
+		from, through := r.from, r.through
+		if grp.At != nil {
+			from, through = model.Time(grp.At.Timestamp), model.Time(grp.At.Timestamp)
+		}
+
 		// use the oldest adjustedFrom
-		if r.from.Add(-diff).Before(adjustedFrom) {
-			adjustedFrom = r.from.Add(-diff)
+		if from.Add(-diff).Before(adjustedFrom) {
+			adjustedFrom = from.Add(-diff)
 		}
 
 		// use the latest adjustedThrough
-		if r.through.Add(-grp.Offset).After(adjustedThrough) {
-			adjustedThrough = r.through.Add(-grp.Offset)
+		if through.Add(-grp.Offset).After(adjustedThrough) {
+			adjustedThrough = through.Add(-grp.Offset)
 		}
 	}
 
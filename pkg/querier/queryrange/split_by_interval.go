@@ -271,13 +271,24 @@ func maxRangeVectorAndOffsetDuration(expr syntax.Expr) (time.Duration, time.Dura
 
 	var maxRVDuration, maxOffset time.Duration
 	expr.Walk(func(e syntax.Expr) {
-		if r, ok := e.(*syntax.LogRange); ok {
+		switch r := e.(type) {
+		case *syntax.LogRange:
 			if r.Interval > maxRVDuration {
 				maxRVDuration = r.Interval
 			}
 			if r.Offset > maxOffset {
 				maxOffset = r.Offset
 			}
+		case *syntax.SubqueryExpr:
+			// A subquery looks back its own range and offset on top of the
+			// range vectors and offsets of its inner expression.
+			innerRV, innerOffset, _ := maxRangeVectorAndOffsetDuration(r.Left)
+			if r.Range+innerRV > maxRVDuration {
+				maxRVDuration = r.Range + innerRV
+			}
+			if r.Offset+innerOffset > maxOffset {
+				maxOffset = r.Offset + innerOffset
+			}
 		}
 	})
 	return maxRVDuration, maxOffset, nil
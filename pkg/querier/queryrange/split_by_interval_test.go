@@ -794,6 +794,55 @@ func Test_splitMetricQuery(t *testing.T) {
 			},
 			splitInterval: 3 * time.Hour,
 		},
+		// ranges pinned with an @ modifier are evaluated at the same time by
+		// every split.
+		{
+			input: &LokiRequest{
+				StartTs: time.Unix(0, 0),
+				EndTs:   time.Unix(2*3*3600, 0),
+				Step:    15 * seconds,
+				Query:   `rate({app="foo"}[1m] @ 3600) / rate({app="foo"}[1m])`,
+			},
+			expected: []queryrangebase.Request{
+				&LokiRequest{
+					StartTs: time.Unix(0, 0),
+					EndTs:   time.Unix((3*3600)-15, 0),
+					Step:    15 * seconds,
+					Query:   `rate({app="foo"}[1m] @ 3600) / rate({app="foo"}[1m])`,
+				},
+				&LokiRequest{
+					StartTs: time.Unix((3 * 3600), 0),
+					EndTs:   time.Unix((2 * 3 * 3600), 0),
+					Step:    15 * seconds,
+					Query:   `rate({app="foo"}[1m] @ 3600) / rate({app="foo"}[1m])`,
+				},
+			},
+			splitInterval: 3 * time.Hour,
+		},
+		// `@ end()` is pinned to the end of the query being split.
+		{
+			input: &LokiRequest{
+				StartTs: time.Unix(0, 0),
+				EndTs:   time.Unix(2*3*3600, 0),
+				Step:    15 * seconds,
+				Query:   `rate({app="foo"}[1m] @ end())`,
+			},
+			expected: []queryrangebase.Request{
+				&LokiRequest{
+					StartTs: time.Unix(0, 0),
+					EndTs:   time.Unix((3*3600)-15, 0),
+					Step:    15 * seconds,
+					Query:   `rate({app="foo"}[1m] @ 21600.000)`,
+				},
+				&LokiRequest{
+					StartTs: time.Unix((3 * 3600), 0),
+					EndTs:   time.Unix((2 * 3 * 3600), 0),
+					Step:    15 * seconds,
+					Query:   `rate({app="foo"}[1m] @ 21600.000)`,
+				},
+			},
+			splitInterval: 3 * time.Hour,
+		},
 		{
 			input: &LokiRequest{
 				StartTs: time.Unix(0, 0),
@@ -1948,3 +1997,24 @@ func assertSplits(t *testing.T, want, splits []queryrangebase.Request) {
 		}
 	}
 }
+
+func Test_maxRangeVectorAndOffsetDuration(t *testing.T) {
+	for _, tc := range []struct {
+		query          string
+		expectedRange  time.Duration
+		expectedOffset time.Duration
+	}{
+		{`{app="foo"}`, 0, 0},
+		{`rate({app="foo"}[5m])`, 5 * time.Minute, 0},
+		{`rate({app="foo"}[5m] offset 1h) / rate({app="foo"}[10m])`, 10 * time.Minute, time.Hour},
+		{`max_over_time(rate({app="foo"}[5m])[1h:1m])`, time.Hour + 5*time.Minute, 0},
+		{`max_over_time(rate({app="foo"}[5m] offset 10m)[1h:] offset 1h)`, time.Hour + 5*time.Minute, time.Hour + 10*time.Minute},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			maxRange, maxOffset, err := maxRangeVectorAndOffsetDurationFromQueryString(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedRange, maxRange)
+			require.Equal(t, tc.expectedOffset, maxOffset)
+		})
+	}
+}
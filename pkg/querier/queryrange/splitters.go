@@ -10,6 +10,8 @@ import (
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/v3/pkg/util"
 	"github.com/grafana/loki/v3/pkg/util/validation"
@@ -234,6 +236,23 @@ func (s *metricQuerySplitter) split(execTime time.Time, tenantIDs []string, r qu
 
 	lokiReq := r.(*LokiRequest)
 
+	// ranges pinned with an `@` modifier are evaluated at the same time by
+	// every split, while the other ranges are split as usual. `@ start()` and
+	// `@ end()` refer to the query being split, so they are pinned to its start
+	// and end beforehand.
+	if lokiReq.Plan != nil && syntax.HasAtModifier(lokiReq.Plan.AST) {
+		expr, err := syntax.Clone(lokiReq.Plan.AST)
+		if err != nil {
+			return nil, err
+		}
+		if syntax.ResolveAtModifiers(expr, lokiReq.StartTs, lokiReq.EndTs) {
+			resolved := *lokiReq
+			resolved.Query = expr.String()
+			resolved.Plan = &plan.QueryPlan{AST: expr}
+			lokiReq = &resolved
+		}
+	}
+
 	interval, err := s.reduceSplitIntervalForRangeVector(lokiReq, interval)
 	if err != nil {
 		return nil, err
@@ -19,6 +19,11 @@ type UnsupportedLabelMatcher struct{}
 // must only pass if the key-value pair exists in the bloom.
 type PlainLabelMatcher struct{ Key, Value string }
 
+// TermLabelMatcher represents a test for a term in the value of a key, mapped
+// from word label filters. Bloom tests must only pass if the term of the key
+// exists in the bloom.
+type TermLabelMatcher struct{ Key, Term string }
+
 // OrLabelMatcher represents a logical OR test. Bloom tests must only pass if
 // one of the Left or Right label matcher bloom tests pass.
 type OrLabelMatcher struct{ Left, Right LabelMatcher }
@@ -73,6 +78,23 @@ func buildLabelMatcher(filter log.LabelFilterer) LabelMatcher {
 			Value: filter.Value,
 		}
 
+	case *log.WordLabelFilter:
+		if filter.Ty != log.LabelFilterEqual || filter.PatternError() != nil {
+			return UnsupportedLabelMatcher{}
+		}
+
+		// All terms of the pattern must be present.
+		var matcher LabelMatcher
+		for _, term := range log.Terms(filter.Pattern) {
+			termMatcher := TermLabelMatcher{Key: filter.Label, Term: term}
+			if matcher == nil {
+				matcher = termMatcher
+				continue
+			}
+			matcher = AndLabelMatcher{Left: matcher, Right: termMatcher}
+		}
+		return matcher
+
 	case *log.BinaryLabelFilter:
 		var (
 			left  = buildLabelMatcher(filter.Left)
@@ -95,5 +117,6 @@ func buildLabelMatcher(filter log.LabelFilterer) LabelMatcher {
 
 func (UnsupportedLabelMatcher) isLabelMatcher() {}
 func (PlainLabelMatcher) isLabelMatcher()       {}
+func (TermLabelMatcher) isLabelMatcher()        {}
 func (OrLabelMatcher) isLabelMatcher()          {}
 func (AndLabelMatcher) isLabelMatcher()         {}
@@ -55,6 +55,25 @@ func TestExtractLabelMatchers(t *testing.T) {
 			},
 		},
 
+		{
+			name:  "word label matcher",
+			input: `{app="foo"} | key=word("Connection timeout")`,
+			expect: []v1.LabelMatcher{
+				v1.AndLabelMatcher{
+					Left:  v1.TermLabelMatcher{Key: "key", Term: "connection"},
+					Right: v1.TermLabelMatcher{Key: "key", Term: "timeout"},
+				},
+			},
+		},
+
+		{
+			name:  "negated word label matcher",
+			input: `{app="foo"} | key!=word("timeout")`,
+			expect: []v1.LabelMatcher{
+				v1.UnsupportedLabelMatcher{},
+			},
+		},
+
 		{
 			name:  "unsupported label matchers",
 			input: `{app="foo"} | key1=~"value1"`,
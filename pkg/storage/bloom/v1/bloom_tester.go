@@ -118,6 +118,9 @@ func matcherToBloomTest(matcher LabelMatcher) BloomTest {
 	case PlainLabelMatcher:
 		return newStringMatcherTest(matcher)
 
+	case TermLabelMatcher:
+		return newTermMatcherTest(matcher)
+
 	case OrLabelMatcher:
 		return newOrTest(
 			matcherToBloomTest(matcher.Left),
@@ -195,6 +198,42 @@ func (sm stringMatcherTest) MatchesWithPrefixBuf(bloom filter.Checker, buf []byt
 	return bloom.Test(prefixedCombined)
 }
 
+type termMatcherTest struct {
+	matcher TermLabelMatcher
+}
+
+func newTermMatcherTest(matcher TermLabelMatcher) termMatcherTest {
+	return termMatcherTest{matcher: matcher}
+}
+
+func (tm termMatcherTest) Matches(bloom filter.Checker) bool {
+	var (
+		indexed = termsIndexedToken(tm.matcher.Key)
+		term    = termToken(tm.matcher.Key, tm.matcher.Term)
+
+		rawIndexed = unsafe.Slice(unsafe.StringData(indexed), len(indexed))
+		rawTerm    = unsafe.Slice(unsafe.StringData(term), len(term))
+	)
+
+	if !bloom.Test(rawIndexed) {
+		// The terms of the structured metadata key weren't indexed, either because
+		// the key doesn't exist or because the bloom was built before terms were
+		// indexed, so we can't safely filter out this chunk.
+		return true
+	}
+
+	return bloom.Test(rawTerm)
+}
+
+func (tm termMatcherTest) MatchesWithPrefixBuf(bloom filter.Checker, buf []byte, prefixLen int) bool {
+	if !bloom.Test(appendToBuf(buf, prefixLen, termsIndexedToken(tm.matcher.Key))) {
+		// See Matches.
+		return true
+	}
+
+	return bloom.Test(appendToBuf(buf, prefixLen, termToken(tm.matcher.Key, tm.matcher.Term)))
+}
+
 // appendToBuf is the equivalent of append(buf[:prefixLen], str). len(buf) must
 // be greater than or equal to prefixLen+len(str) to avoid allocations.
 func appendToBuf(buf []byte, prefixLen int, str string) []byte {
@@ -12,14 +12,15 @@ import (
 
 func TestLabelMatchersToBloomTest(t *testing.T) {
 	// All test cases below have access to a fake bloom filter with
-	// trace_id=exists_1 and trace_id=exists_2
+	// trace_id=exists_1, trace_id=exists_2 and a request_body
 	var (
 		prefix    = "fakeprefix"
-		tokenizer = NewStructuredMetadataTokenizer(prefix)
+		tokenizer = NewStructuredMetadataTokenizer(prefix, map[string]struct{}{"request_body": {}})
 		bloom     = newFakeMetadataBloom(
 			tokenizer,
 			push.LabelAdapter{Name: "trace_id", Value: "exists_1"},
 			push.LabelAdapter{Name: "trace_id", Value: "exists_2"},
+			push.LabelAdapter{Name: "request_body", Value: "upstream Timeout after 30s"},
 		)
 	)
 
@@ -88,6 +89,46 @@ func TestLabelMatchersToBloomTest(t *testing.T) {
 			query: `{app="fake"} | trace_id="exists_1" and trace_id="noexist"`,
 			match: false,
 		},
+		{
+			name:  "word test pass",
+			query: `{app="fake"} | request_body=word("timeout")`,
+			match: true,
+		},
+		{
+			name:  "word test with multiple terms pass",
+			query: `{app="fake"} | request_body=word("TIMEOUT after")`,
+			match: true,
+		},
+		{
+			name:  "word test fail",
+			query: `{app="fake"} | request_body=word("refused")`,
+			match: false,
+		},
+		{
+			name:  "word test with multiple terms fail",
+			query: `{app="fake"} | request_body=word("timeout refused")`,
+			match: false,
+		},
+		{
+			name:  "word test on partial term fail",
+			query: `{app="fake"} | request_body=word("time")`,
+			match: false,
+		},
+		{
+			name:  "ignore word test on key without indexed terms",
+			query: `{app="fake"} | trace_id=word("noexist")`,
+			match: true,
+		},
+		{
+			name:  "ignore negated word test",
+			query: `{app="fake"} | request_body!=word("timeout")`,
+			match: true,
+		},
+		{
+			name:  "ignore word test on non-indexed key",
+			query: `{app="fake"} | noexist=word("timeout")`,
+			match: true,
+		},
 	}
 
 	for _, tc := range tt {
@@ -24,6 +24,7 @@ type BloomTokenizer struct {
 	logger  log.Logger
 
 	maxBloomSize int // size in bytes
+	termKeys     map[string]struct{}
 	cache        map[string]interface{}
 }
 
@@ -36,12 +37,18 @@ const eightBits = 8
 // 1) The token slices generated must not be mutated externally
 // 2) The token slice must not be used after the next call to `Tokens()` as it will repopulate the slice.
 // 2) This is not thread safe.
-func NewBloomTokenizer(maxBloomSize int, metrics *Metrics, logger log.Logger) *BloomTokenizer {
+// The terms of the values of the structured metadata termKeys are indexed too.
+func NewBloomTokenizer(maxBloomSize int, termKeys []string, metrics *Metrics, logger log.Logger) *BloomTokenizer {
+	keys := make(map[string]struct{}, len(termKeys))
+	for _, k := range termKeys {
+		keys[k] = struct{}{}
+	}
 	return &BloomTokenizer{
 		metrics:      metrics,
 		logger:       logger,
 		cache:        make(map[string]interface{}, cacheSize),
 		maxBloomSize: maxBloomSize,
+		termKeys:     keys,
 	}
 }
 
@@ -164,7 +171,7 @@ func (bt *BloomTokenizer) addChunkToBloom(bloom *Bloom, ref ChunkRef, entryIter
 	// return values
 	full, info := false, newIndexingInfo()
 
-	tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(ref)))
+	tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(ref)), bt.termKeys)
 
 	// We use a peeking iterator to avoid advancing the iterator until we're sure the bloom has accepted the line.
 	for entry, ok := entryIter.Peek(); ok; entry, ok = entryIter.Peek() {
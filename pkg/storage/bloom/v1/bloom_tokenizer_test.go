@@ -32,7 +32,7 @@ var metrics = NewMetrics(prometheus.DefaultRegisterer)
 func TestTokenizerPopulate(t *testing.T) {
 	t.Parallel()
 	var testLine = "this is a log line"
-	bt := NewBloomTokenizer(0, metrics, logger.NewNopLogger())
+	bt := NewBloomTokenizer(0, nil, metrics, logger.NewNopLogger())
 
 	metadata := push.LabelsAdapter{
 		{Name: "pod", Value: "loki-1"},
@@ -64,7 +64,7 @@ func TestTokenizerPopulate(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, len(blooms))
 
-	tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(ref)))
+	tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(ref)), nil)
 
 	for _, kv := range metadata {
 		tokens := tokenizer.Tokens(kv)
@@ -77,7 +77,7 @@ func TestTokenizerPopulate(t *testing.T) {
 
 func TestBloomTokenizerPopulateWithoutPreexistingBloom(t *testing.T) {
 	var testLine = "this is a log line"
-	bt := NewBloomTokenizer(0, metrics, logger.NewNopLogger())
+	bt := NewBloomTokenizer(0, nil, metrics, logger.NewNopLogger())
 
 	metadata := push.LabelsAdapter{
 		{Name: "pod", Value: "loki-1"},
@@ -108,7 +108,7 @@ func TestBloomTokenizerPopulateWithoutPreexistingBloom(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, len(blooms))
 
-	tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(ref)))
+	tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(ref)), nil)
 
 	for _, kv := range metadata {
 		tokens := tokenizer.Tokens(kv)
@@ -154,7 +154,7 @@ func randomStr(ln int) string {
 
 func TestTokenizerPopulateWontExceedMaxSize(t *testing.T) {
 	maxSize := 4 << 10
-	bt := NewBloomTokenizer(maxSize, NewMetrics(nil), logger.NewNopLogger())
+	bt := NewBloomTokenizer(maxSize, nil, NewMetrics(nil), logger.NewNopLogger())
 	ch := make(chan *BloomCreation)
 
 	metadata := make([]push.LabelsAdapter, 0, 4<<10)
@@ -201,7 +201,7 @@ func populateAndConsumeBloom(
 
 func BenchmarkPopulateSeriesWithBloom(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		bt := NewBloomTokenizer(0, metrics, logger.NewNopLogger())
+		bt := NewBloomTokenizer(0, nil, metrics, logger.NewNopLogger())
 
 		sbf := filter.NewScalableBloomFilter(1024, 0.01, 0.8)
 
@@ -238,7 +238,7 @@ func BenchmarkPopulateSeriesWithBloom(b *testing.B) {
 }
 
 func TestTokenizerClearsCacheBetweenPopulateCalls(t *testing.T) {
-	bt := NewBloomTokenizer(0, NewMetrics(nil), logger.NewNopLogger())
+	bt := NewBloomTokenizer(0, nil, NewMetrics(nil), logger.NewNopLogger())
 	md := push.LabelsAdapter{
 		{Name: "trace_id", Value: "3bef3c91643bde73"},
 	}
@@ -264,7 +264,7 @@ func TestTokenizerClearsCacheBetweenPopulateCalls(t *testing.T) {
 
 	}
 
-	tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(ref)))
+	tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(ref)), nil)
 	for _, bloom := range blooms {
 		toks := tokenizer.Tokens(md[0])
 		for toks.Next() {
@@ -276,7 +276,7 @@ func TestTokenizerClearsCacheBetweenPopulateCalls(t *testing.T) {
 }
 
 func BenchmarkMapClear(b *testing.B) {
-	bt := NewBloomTokenizer(0, metrics, logger.NewNopLogger())
+	bt := NewBloomTokenizer(0, nil, metrics, logger.NewNopLogger())
 	for i := 0; i < b.N; i++ {
 		for k := 0; k < cacheSize; k++ {
 			bt.cache[fmt.Sprint(k)] = k
@@ -287,7 +287,7 @@ func BenchmarkMapClear(b *testing.B) {
 }
 
 func BenchmarkNewMap(b *testing.B) {
-	bt := NewBloomTokenizer(0, metrics, logger.NewNopLogger())
+	bt := NewBloomTokenizer(0, nil, metrics, logger.NewNopLogger())
 	for i := 0; i < b.N; i++ {
 		for k := 0; k < cacheSize; k++ {
 			bt.cache[fmt.Sprint(k)] = k
@@ -92,7 +92,7 @@ func MkBasicSeriesWithBlooms(nSeries int, fromFp, throughFp model.Fingerprint, f
 
 			batchStart, batchEnd := j*chunkBatchSize, min(series.Chunks.Len(), (j+1)*chunkBatchSize)
 			for x, chk := range series.Chunks[batchStart:batchEnd] {
-				tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(chk)))
+				tokenizer := NewStructuredMetadataTokenizer(string(prefixForChunkRef(chk)), nil)
 				kv := push.LabelAdapter{Name: "trace_id", Value: fmt.Sprintf("%s:%04x", series.Fingerprint, j*chunkBatchSize+x)}
 				it := tokenizer.Tokens(kv)
 				for it.Next() {
@@ -4,20 +4,42 @@ import (
 	"fmt"
 
 	iter "github.com/grafana/loki/v3/pkg/iter/v2"
+	"github.com/grafana/loki/v3/pkg/logql/log"
 
 	"github.com/grafana/loki/pkg/push"
 )
 
+// termSeparator separates the structured metadata key from a term of its value
+// in term tokens.
+const termSeparator = "~"
+
+// termsIndexedToken returns the token marking that the terms of the values of
+// key have been indexed. Blooms built before terms were indexed don't contain
+// it, so term tests must pass for them.
+func termsIndexedToken(key string) string {
+	return key + termSeparator
+}
+
+// termToken returns the token of a term of the value of key.
+func termToken(key, term string) string {
+	return key + termSeparator + term
+}
+
 type StructuredMetadataTokenizer struct {
 	// prefix to add to tokens, typically the encoded chunkref
 	prefix string
-	tokens []string
+	// termKeys are the keys whose values are split into term tokens
+	termKeys map[string]struct{}
+	tokens   []string
 }
 
-func NewStructuredMetadataTokenizer(prefix string) *StructuredMetadataTokenizer {
+// NewStructuredMetadataTokenizer returns a tokenizer which also indexes the
+// terms of the values of termKeys.
+func NewStructuredMetadataTokenizer(prefix string, termKeys map[string]struct{}) *StructuredMetadataTokenizer {
 	return &StructuredMetadataTokenizer{
-		prefix: prefix,
-		tokens: make([]string, 6),
+		prefix:   prefix,
+		termKeys: termKeys,
+		tokens:   make([]string, 8),
 	}
 }
 
@@ -28,5 +50,17 @@ func (t *StructuredMetadataTokenizer) Tokens(kv push.LabelAdapter) iter.Iterator
 		kv.Value, t.prefix+kv.Value,
 		combined, t.prefix+combined,
 	)
+
+	// Index the terms of the value, so that word filters on the key can be
+	// tested, see log.Terms. Term tests pass for the keys that aren't indexed.
+	if _, ok := t.termKeys[kv.Name]; !ok {
+		return iter.NewSliceIter(t.tokens)
+	}
+	indexed := termsIndexedToken(kv.Name)
+	t.tokens = append(t.tokens, indexed, t.prefix+indexed)
+	for _, term := range log.Terms(kv.Value) {
+		tok := termToken(kv.Name, term)
+		t.tokens = append(t.tokens, tok, t.prefix+tok)
+	}
 	return iter.NewSliceIter(t.tokens)
 }
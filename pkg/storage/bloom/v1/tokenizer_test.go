@@ -11,10 +11,33 @@ import (
 )
 
 func TestStructuredMetadataTokenizer(t *testing.T) {
-	tokenizer := NewStructuredMetadataTokenizer("chunk")
+	tokenizer := NewStructuredMetadataTokenizer("chunk", map[string]struct{}{"pod": {}})
 
 	metadata := push.LabelAdapter{Name: "pod", Value: "loki-1"}
-	expected := []string{"pod", "chunkpod", "loki-1", "chunkloki-1", "pod=loki-1", "chunkpod=loki-1"}
+	expected := []string{
+		"pod", "chunkpod",
+		"loki-1", "chunkloki-1",
+		"pod=loki-1", "chunkpod=loki-1",
+		"pod~", "chunkpod~",
+		"pod~loki", "chunkpod~loki",
+		"pod~1", "chunkpod~1",
+	}
+
+	tokenIter := tokenizer.Tokens(metadata)
+	got, err := v2.Collect(tokenIter)
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+}
+
+func TestStructuredMetadataTokenizer_NoTermKeys(t *testing.T) {
+	tokenizer := NewStructuredMetadataTokenizer("chunk", map[string]struct{}{"msg": {}})
+
+	metadata := push.LabelAdapter{Name: "pod", Value: "loki-1"}
+	expected := []string{
+		"pod", "chunkpod",
+		"loki-1", "chunkloki-1",
+		"pod=loki-1", "chunkpod=loki-1",
+	}
 
 	tokenIter := tokenizer.Tokens(metadata)
 	got, err := v2.Collect(tokenIter)
@@ -46,6 +46,7 @@ var (
 	errCurrentBoltdbShipperNon24Hours  = errors.New("boltdb-shipper works best with 24h periodic index config. Either add a new config with future date set to 24h to retain the existing index or change the existing config to use 24h period")
 	errUpcomingBoltdbShipperNon24Hours = errors.New("boltdb-shipper with future date must always have periodic config for index set to 24h")
 	errTSDBNon24HoursIndexPeriod       = errors.New("tsdb must always have periodic config for index set to 24h")
+	errColumnarChunksSchema            = errors.New("columnar chunks require schema v13 or greater")
 	errZeroLengthConfig                = errors.New("must specify at least one schema configuration")
 
 	// regexp for finding the trailing index table number at the end of the table name
@@ -145,6 +146,8 @@ type PeriodConfig struct {
 	IndexTables IndexPeriodicTableConfig `yaml:"index" doc:"description=Configures how the index is updated and stored."`
 	ChunkTables PeriodicTableConfig      `yaml:"chunks" doc:"description=Configured how the chunks are updated and stored."`
 	RowShards   uint32                   `yaml:"row_shards" doc:"default=16|description=How many shards will be created. Only used if schema is v10 or greater."`
+	// ColumnarChunks selects chunkenc.ChunkFormatV5.
+	ColumnarChunks bool `yaml:"columnar_chunks" doc:"default=false|description=Experimental: Write the chunks in the columnar format, whose blocks store the timestamps, structured metadata and log lines apart, along with summaries that let the queries skip the blocks on line filters. Requires schema v13 or greater. As the older Loki versions can't read these chunks, enable it in a new period_config starting once all the components are upgraded."`
 
 	// Integer representation of schema used for hot path calculation. Populated on unmarshaling.
 	schemaInt *int `yaml:"-"`
@@ -423,6 +426,8 @@ func (cfg *PeriodConfig) ChunkFormat() (byte, chunkenc.HeadBlockFmt, error) {
 	switch {
 	case sver <= 12:
 		return chunkenc.ChunkFormatV3, chunkenc.ChunkHeadFormatFor(chunkenc.ChunkFormatV3), nil
+	case cfg.ColumnarChunks:
+		return chunkenc.ChunkFormatV5, chunkenc.ChunkHeadFormatFor(chunkenc.ChunkFormatV5), nil
 	default: // for v13 and above
 		return chunkenc.ChunkFormatV4, chunkenc.ChunkHeadFormatFor(chunkenc.ChunkFormatV4), nil
 	}
@@ -468,6 +473,10 @@ func (cfg PeriodConfig) validate() error {
 		return err
 	}
 
+	if cfg.ColumnarChunks && v < 13 {
+		return errColumnarChunksSchema
+	}
+
 	switch v {
 	case 10, 11, 12, 13:
 		if cfg.RowShards == 0 {
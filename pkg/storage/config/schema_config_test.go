@@ -483,6 +483,33 @@ func TestPeriodConfig_Validate(t *testing.T) {
 				ChunkTables: PeriodicTableConfig{Period: 0},
 			},
 		},
+		{
+			desc: "v13 with columnar chunks",
+			in: PeriodConfig{
+				Schema:         "v13",
+				RowShards:      16,
+				ColumnarChunks: true,
+				IndexTables: IndexPeriodicTableConfig{
+					PathPrefix:          "index/",
+					PeriodicTableConfig: PeriodicTableConfig{Period: 0},
+				},
+				ChunkTables: PeriodicTableConfig{Period: 0},
+			},
+		},
+		{
+			desc: "error v12 with columnar chunks",
+			in: PeriodConfig{
+				Schema:         "v12",
+				RowShards:      16,
+				ColumnarChunks: true,
+				IndexTables: IndexPeriodicTableConfig{
+					PathPrefix:          "index/",
+					PeriodicTableConfig: PeriodicTableConfig{Period: 0},
+				},
+				ChunkTables: PeriodicTableConfig{Period: 0},
+			},
+			err: "columnar chunks require schema v13 or greater",
+		},
 	} {
 		t.Run(tc.desc, func(t *testing.T) {
 			if tc.err == "" {
@@ -0,0 +1,42 @@
+package dictionaries
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+)
+
+// Config configures the object store of the zstd dictionaries of the zstd-dict
+// chunk encoding.
+type Config struct {
+	Store           string        `yaml:"store"`
+	KeyPrefix       string        `yaml:"key_prefix"`
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+
+	// ObjectClient is the client of the store, set by the module.
+	ObjectClient client.ObjectClient `yaml:"-"`
+}
+
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, fs *flag.FlagSet) {
+	fs.StringVar(&cfg.Store, prefix+".store", "", "Object store the zstd dictionaries trained by the compactor are stored in. The chunks written with the zstd-dict encoding are compressed with the current dictionary of their tenant, and need the dictionary to be read. Empty disables the dictionaries, the zstd-dict encoding then compresses without dictionary.")
+	fs.StringVar(&cfg.KeyPrefix, prefix+".key-prefix", "zstd-dictionaries/", "Path prefix of the zstd dictionaries in the object store. The dictionaries of a tenant, which contain some of its log lines, are stored under <prefix>tenants/<tenant>/ and are not deleted by the retention nor by the delete requests.")
+	fs.DurationVar(&cfg.RefreshInterval, prefix+".refresh-interval", 10*time.Minute, "Interval at which the ingesters refresh the current zstd dictionary of the tenants.")
+}
+
+// Enabled returns whether the store of the zstd dictionaries is configured.
+func (cfg *Config) Enabled() bool {
+	return cfg.Store != ""
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if cfg.RefreshInterval <= 0 {
+		return errors.New("the refresh interval of the zstd dictionaries must be greater than 0")
+	}
+	return config.ValidatePathPrefix(cfg.KeyPrefix)
+}
@@ -0,0 +1,217 @@
+package dictionaries
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/loki/v3/pkg/compression"
+)
+
+// minID is the lowest ID of the trained dictionaries, the lower ones are
+// reserved by zstd.
+const minID = 1 << 15
+
+// Version is a version of the zstd dictionary of a tenant.
+type Version struct {
+	ID        uint32    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type tenantIndex struct {
+	Versions []Version `json:"versions"`
+}
+
+// Store stores the zstd dictionaries of the tenants in the object store. The
+// dictionaries are immutable objects named after their ID, so that the chunks
+// compressed with them stay readable once the tenant gets a new version. The
+// versions of the dictionary of each tenant are listed in an index object.
+//
+// The dictionaries contain log lines of their tenant, so all the objects of a
+// tenant are stored under its own prefix, see TenantPrefix. They are not
+// deleted by the retention nor by the delete requests: removing the prefix of
+// a tenant removes its dictionaries, which makes its zstd-dict chunks
+// unreadable. Only the owners of the dictionary IDs, which contain the tenant
+// name only, are stored out of the tenant prefix.
+type Store struct {
+	cfg    Config
+	logger log.Logger
+
+	mtx     sync.Mutex
+	current map[string]*currentVersion // by tenant
+}
+
+type currentVersion struct {
+	id          uint32
+	refreshedAt time.Time
+	refreshing  bool
+}
+
+func NewStore(cfg Config, logger log.Logger) *Store {
+	return &Store{
+		cfg:     cfg,
+		logger:  log.With(logger, "component", "zstd-dictionaries"),
+		current: make(map[string]*currentVersion),
+	}
+}
+
+// TenantPrefix returns the prefix of the objects of the tenant in the object
+// store.
+func (s *Store) TenantPrefix(tenant string) string {
+	return s.cfg.KeyPrefix + "tenants/" + tenant + "/"
+}
+
+func (s *Store) dictionaryKey(tenant string, id uint32) string {
+	return fmt.Sprintf("%s%08x.zdict", s.TenantPrefix(tenant), id)
+}
+
+func (s *Store) indexKey(tenant string) string {
+	return s.TenantPrefix(tenant) + "index.json"
+}
+
+// ownerKey is the key of the object containing the tenant of the dictionary,
+// as the chunks only reference the dictionaries by ID.
+func (s *Store) ownerKey(id uint32) string {
+	return fmt.Sprintf("%sids/%08x", s.cfg.KeyPrefix, id)
+}
+
+func (s *Store) get(ctx context.Context, key string) ([]byte, error) {
+	rc, _, err := s.cfg.ObjectClient.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// Load returns the dictionary. It is the loader of the dictionaries of the
+// compression package.
+func (s *Store) Load(ctx context.Context, id uint32) ([]byte, error) {
+	tenant, err := s.get(ctx, s.ownerKey(id))
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, s.dictionaryKey(string(tenant), id))
+}
+
+// Loader returns the loader of the dictionaries of the compression package,
+// which reads the chunks compressed with the dictionaries of the store.
+func (s *Store) Loader() compression.ZstdDictionaryLoader {
+	return func(id uint32) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		return s.Load(ctx, id)
+	}
+}
+
+// Versions returns the versions of the dictionary of the tenant, the newest
+// last.
+func (s *Store) Versions(ctx context.Context, tenant string) ([]Version, error) {
+	b, err := s.get(ctx, s.indexKey(tenant))
+	if err != nil {
+		if s.cfg.ObjectClient.IsObjectNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var index tenantIndex
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("invalid zstd dictionaries index of tenant %s: %w", tenant, err)
+	}
+	return index.Versions, nil
+}
+
+// NewID returns a random dictionary ID which is not used by a stored
+// dictionary.
+func (s *Store) NewID(ctx context.Context) (uint32, error) {
+	for {
+		id := minID + uint32(rand.Int31n(1<<31-minID))
+		exists, err := s.cfg.ObjectClient.ObjectExists(ctx, s.ownerKey(id))
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			return id, nil
+		}
+	}
+}
+
+// Add stores the dictionary as the newest version of the dictionary of the
+// tenant. The dictionaries are only added by the compactor, so the index of
+// the tenant is not updated concurrently.
+func (s *Store) Add(ctx context.Context, tenant string, dict []byte, now time.Time) (Version, error) {
+	id, err := compression.RegisterZstdDictionary(dict)
+	if err != nil {
+		return Version{}, err
+	}
+	if err := s.cfg.ObjectClient.PutObject(ctx, s.dictionaryKey(tenant, id), bytes.NewReader(dict)); err != nil {
+		return Version{}, err
+	}
+	if err := s.cfg.ObjectClient.PutObject(ctx, s.ownerKey(id), bytes.NewReader([]byte(tenant))); err != nil {
+		return Version{}, err
+	}
+
+	versions, err := s.Versions(ctx, tenant)
+	if err != nil {
+		return Version{}, err
+	}
+	version := Version{ID: id, CreatedAt: now}
+	b, err := json.Marshal(tenantIndex{Versions: append(versions, version)})
+	if err != nil {
+		return Version{}, err
+	}
+	return version, s.cfg.ObjectClient.PutObject(ctx, s.indexKey(tenant), bytes.NewReader(b))
+}
+
+// Current returns the ID of the newest dictionary of the tenant, 0 meaning no
+// dictionary, without blocking: the IDs are cached and refreshed in the
+// background every refresh interval. The dictionary is registered in the
+// compression package before its ID is returned.
+func (s *Store) Current(tenant string) uint32 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	c, ok := s.current[tenant]
+	if !ok {
+		c = &currentVersion{}
+		s.current[tenant] = c
+	}
+	if !c.refreshing && time.Since(c.refreshedAt) >= s.cfg.RefreshInterval {
+		c.refreshing = true
+		go s.refresh(tenant, c)
+	}
+	return c.id
+}
+
+func (s *Store) refresh(tenant string, c *currentVersion) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	id, err := s.newest(ctx, tenant)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	c.refreshing = false
+	if err != nil {
+		level.Warn(s.logger).Log("msg", "failed to refresh the zstd dictionary", "tenant", tenant, "err", err)
+		return
+	}
+	c.id, c.refreshedAt = id, time.Now()
+}
+
+func (s *Store) newest(ctx context.Context, tenant string) (uint32, error) {
+	versions, err := s.Versions(ctx, tenant)
+	if err != nil || len(versions) == 0 {
+		return 0, err
+	}
+	id := versions[len(versions)-1].ID
+	return id, compression.LoadZstdDictionary(id)
+}
@@ -0,0 +1,74 @@
+package dictionaries
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	objectClient := testutils.NewInMemoryObjectClient()
+	s := NewStore(Config{
+		KeyPrefix:       "zstd-dictionaries/",
+		RefreshInterval: time.Hour,
+		ObjectClient:    objectClient,
+	}, log.NewNopLogger())
+	compression.SetZstdDictionaryLoader(s.Loader())
+	defer compression.SetZstdDictionaryLoader(nil)
+
+	versions, err := s.Versions(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Empty(t, versions)
+	require.Equal(t, uint32(0), s.Current("tenant-a"))
+
+	var added []Version
+	for i := 0; i < 2; i++ {
+		id, err := s.NewID(ctx)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, id, uint32(minID))
+
+		var samples [][]byte
+		for j := 0; j < 100; j++ {
+			samples = append(samples, []byte(fmt.Sprintf("level=info version=%d msg=\"request %d\"", i, j)))
+		}
+		dict, err := compression.BuildZstdDictionary(id, samples, 1<<10)
+		require.NoError(t, err)
+		version, err := s.Add(ctx, "tenant-a", dict, time.Unix(int64(i), 0).UTC())
+		require.NoError(t, err)
+		require.Equal(t, id, version.ID)
+		added = append(added, version)
+
+		stored, err := s.Load(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, dict, stored)
+	}
+
+	// the dictionaries of the tenant are stored under its prefix.
+	objects, _, err := objectClient.List(ctx, s.TenantPrefix("tenant-a"), "")
+	require.NoError(t, err)
+	require.Len(t, objects, len(added)+1)
+
+	versions, err = s.Versions(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Equal(t, added, versions)
+	versions, err = s.Versions(ctx, "tenant-b")
+	require.NoError(t, err)
+	require.Empty(t, versions)
+
+	// the current dictionary is refreshed once the refresh interval elapsed.
+	s.mtx.Lock()
+	s.current["tenant-a"].refreshedAt = time.Time{}
+	s.mtx.Unlock()
+	require.Eventually(t, func() bool {
+		return s.Current("tenant-a") == added[1].ID
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, uint32(0), s.Current("tenant-b"))
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/openstack"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/testutils"
 	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/dictionaries"
 	"github.com/grafana/loki/v3/pkg/storage/stores"
 	"github.com/grafana/loki/v3/pkg/storage/stores/series/index"
 	bloomshipperconfig "github.com/grafana/loki/v3/pkg/storage/stores/shipper/bloomshipper/config"
@@ -302,6 +303,7 @@ type Config struct {
 	BoltDBShipperConfig boltdb.IndexCfg           `yaml:"boltdb_shipper" doc:"description=Configures storing index in an Object Store (GCS/S3/Azure/Swift/COS/Filesystem) in the form of boltdb files. Required fields only required when boltdb-shipper is defined in config."`
 	TSDBShipperConfig   indexshipper.Config       `yaml:"tsdb_shipper" doc:"description=Configures storing index in an Object Store (GCS/S3/Azure/Swift/COS/Filesystem) in a prometheus TSDB-like format. Required fields only required when TSDB is defined in config."`
 	BloomShipperConfig  bloomshipperconfig.Config `yaml:"bloom_shipper" category:"experimental" doc:"description=Experimental: Configures the bloom shipper component, which contains the store abstraction to fetch bloom filters from and put them to object storage."`
+	ZstdDictionaries    dictionaries.Config       `yaml:"zstd_dictionaries" category:"experimental" doc:"description=Experimental: Configures the object store of the zstd dictionaries trained by the compactor for the zstd-dict chunk encoding."`
 
 	// Config for using AsyncStore when using async index stores like `boltdb-shipper`.
 	// It is required for getting chunk ids of recently flushed chunks from the ingesters.
@@ -337,6 +339,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxChunkBatchSize, "store.max-chunk-batch-size", 50, "The maximum number of chunks to fetch per batch.")
 	cfg.TSDBShipperConfig.RegisterFlagsWithPrefix("tsdb.", f)
 	cfg.BloomShipperConfig.RegisterFlagsWithPrefix("bloom.", f)
+	cfg.ZstdDictionaries.RegisterFlagsWithPrefix("store.zstd-dictionaries", f)
 }
 
 // Validate config and returns error on failure
@@ -368,6 +371,9 @@ func (cfg *Config) Validate() error {
 	if err := cfg.ObjectStore.Validate(); err != nil {
 		return errors.Wrap(err, "invalid object store config")
 	}
+	if err := cfg.ZstdDictionaries.Validate(); err != nil {
+		return errors.Wrap(err, "invalid zstd dictionaries config")
+	}
 
 	return cfg.NamedStores.Validate()
 }
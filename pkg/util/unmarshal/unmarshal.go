@@ -19,7 +19,8 @@ func DecodePushRequest(b io.Reader, r *logproto.PushRequest) error {
 	}
 
 	*r = logproto.PushRequest{
-		Streams: *(*[]logproto.Stream)(unsafe.Pointer(&request.Streams)),
+		Streams:        *(*[]logproto.Stream)(unsafe.Pointer(&request.Streams)),
+		IdempotencyKey: request.IdempotencyKey,
 	}
 
 	return nil
@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-kit/log/level"
@@ -16,11 +17,17 @@ import (
 	"github.com/prometheus/common/sigv4"
 	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage/remote/otlptranslator/prometheus"
 	"golang.org/x/time/rate"
 	"gopkg.in/yaml.v2"
 
 	"github.com/grafana/loki/v3/pkg/compactor/deletionmode"
 	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/distributor/adaptivesampling"
+	"github.com/grafana/loki/v3/pkg/distributor/cardinalityguard"
+	"github.com/grafana/loki/v3/pkg/distributor/deadletter"
+	"github.com/grafana/loki/v3/pkg/distributor/idempotency"
+	"github.com/grafana/loki/v3/pkg/distributor/redaction"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logql"
@@ -87,17 +94,23 @@ type Limits struct {
 	DiscoverServiceName         []string         `yaml:"discover_service_name" json:"discover_service_name"`
 	DiscoverLogLevels           bool             `yaml:"discover_log_levels" json:"discover_log_levels"`
 
+	ExtractFields []ExtractedField `yaml:"extract_fields,omitempty" json:"extract_fields,omitempty" category:"experimental" doc:"description=Fields of the JSON or logfmt log lines extracted by the distributors into structured metadata at ingestion, so that the queries can filter on them without parsing the log lines.\nExample:\n extract_fields:\n - field: trace_id\n - field: user.id\n name: user_id\n - field: status\nThe keys of nested JSON objects are separated by dots. The fields already present in the structured metadata of the log lines are not overridden, and the fields which would exceed the 'max_structured_metadata_size' or 'max_structured_metadata_entries_count' limits are skipped."`
+
+	IngestionRatePolicies []IngestionRatePolicy `yaml:"ingestion_rate_policies,omitempty" json:"ingestion_rate_policies,omitempty" category:"experimental" doc:"description=Ingestion rate limits of the streams matching a selector, enforced by the distributors on top of the tenant ingestion rate limit.\nExample:\n ingestion_rate_policies:\n - name: batch-jobs\n selector: '{namespace=\"batch-jobs\"}'\n rate_mb: 5\n burst_size_mb: 10\nThe log lines of the streams exceeding the rate limit of a policy are discarded with the 'ingestion_rate_policy_limited' reason. A stream matching several policies is limited by all of them. The rate limits of the policies are enforced with the ingestion rate strategy of the tenant."`
+
 	// Ingester enforced limits.
-	UseOwnedStreamCount     bool             `yaml:"use_owned_stream_count" json:"use_owned_stream_count"`
-	MaxLocalStreamsPerUser  int              `yaml:"max_streams_per_user" json:"max_streams_per_user"`
-	MaxGlobalStreamsPerUser int              `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
-	UnorderedWrites         bool             `yaml:"unordered_writes" json:"unordered_writes"`
-	PerStreamRateLimit      flagext.ByteSize `yaml:"per_stream_rate_limit" json:"per_stream_rate_limit"`
-	PerStreamRateLimitBurst flagext.ByteSize `yaml:"per_stream_rate_limit_burst" json:"per_stream_rate_limit_burst"`
+	UseOwnedStreamCount     bool               `yaml:"use_owned_stream_count" json:"use_owned_stream_count"`
+	MaxLocalStreamsPerUser  int                `yaml:"max_streams_per_user" json:"max_streams_per_user"`
+	MaxGlobalStreamsPerUser int                `yaml:"max_global_streams_per_user" json:"max_global_streams_per_user"`
+	UnorderedWrites         bool               `yaml:"unordered_writes" json:"unordered_writes"`
+	OutOfOrderWindows       []OutOfOrderWindow `yaml:"out_of_order_windows,omitempty" json:"out_of_order_windows,omitempty" category:"experimental" doc:"description=Out-of-order windows of the streams matching a selector, enforced by the ingesters instead of half of the 'max_chunk_age'. The streams matching a window accept out-of-order writes even when 'unordered_writes' is disabled.\nExample:\n out_of_order_windows:\n - selector: '{source=\"iot\"}'\n window: 6h\n backfill: true\n - selector: '{namespace=\"apps\"}'\n window: 5m\nThe log lines older than the highest timestamp of their stream minus the window are discarded with the 'too_far_behind' reason. With 'backfill' enabled, the log lines within the window but older than half of the 'max_chunk_age' are appended by the ingesters to separate chunks of their stream, flushed to the store once full or older than the ingester 'backfill_max_age'. In case multiple windows are matching, the highest priority one is picked."`
+	PerStreamRateLimit      flagext.ByteSize   `yaml:"per_stream_rate_limit" json:"per_stream_rate_limit"`
+	PerStreamRateLimitBurst flagext.ByteSize   `yaml:"per_stream_rate_limit_burst" json:"per_stream_rate_limit_burst"`
 
 	// Querier enforced limits.
 	MaxChunksPerQuery          int              `yaml:"max_chunks_per_query" json:"max_chunks_per_query"`
 	MaxQuerySeries             int              `yaml:"max_query_series" json:"max_query_series"`
+	MaxQueryJoinEntries        int              `yaml:"max_query_join_entries" json:"max_query_join_entries"`
 	MaxQueryLookback           model.Duration   `yaml:"max_query_lookback" json:"max_query_lookback"`
 	MaxQueryLength             model.Duration   `yaml:"max_query_length" json:"max_query_length"`
 	MaxQueryRange              model.Duration   `yaml:"max_query_range" json:"max_query_range"`
@@ -198,6 +211,8 @@ type Limits struct {
 	RequiredLabels       []string `yaml:"required_labels,omitempty" json:"required_labels,omitempty" doc:"description=Define a list of required selector labels."`
 	RequiredNumberLabels int      `yaml:"minimum_labels_number,omitempty" json:"minimum_labels_number,omitempty" doc:"description=Minimum number of label matchers a query should contain."`
 
+	QueryMacros OverwriteMarshalingStringMap `yaml:"query_macros,omitempty" json:"query_macros,omitempty" doc:"description=Named pipeline fragments which can be referenced in the log pipelines of queries by their name prefixed with '@'. A map with the macro name as key and the pipeline stages as value."`
+
 	IndexGatewayShardSize int `yaml:"index_gateway_shard_size" json:"index_gateway_shard_size"`
 
 	BloomGatewayShardSize        int           `yaml:"bloom_gateway_shard_size" json:"bloom_gateway_shard_size" category:"experimental"`
@@ -217,15 +232,32 @@ type Limits struct {
 	BloomMaxBlockSize flagext.ByteSize `yaml:"bloom_max_block_size" json:"bloom_max_block_size" category:"experimental"`
 	BloomMaxBloomSize flagext.ByteSize `yaml:"bloom_max_bloom_size" json:"bloom_max_bloom_size" category:"experimental"`
 
+	BloomTermIndexedKeys dskit_flagext.StringSliceCSV `yaml:"bloom_term_indexed_keys" json:"bloom_term_indexed_keys" category:"experimental"`
+
 	AllowStructuredMetadata           bool                  `yaml:"allow_structured_metadata,omitempty" json:"allow_structured_metadata,omitempty" doc:"description=Allow user to send structured metadata in push payload."`
 	MaxStructuredMetadataSize         flagext.ByteSize      `yaml:"max_structured_metadata_size" json:"max_structured_metadata_size" doc:"description=Maximum size accepted for structured metadata per log line."`
 	MaxStructuredMetadataEntriesCount int                   `yaml:"max_structured_metadata_entries_count" json:"max_structured_metadata_entries_count" doc:"description=Maximum number of structured metadata entries per log line."`
 	OTLPConfig                        push.OTLPConfig       `yaml:"otlp_config" json:"otlp_config" doc:"description=OTLP log ingestion configurations"`
 	GlobalOTLPConfig                  push.GlobalOTLPConfig `yaml:"-" json:"-"`
 
+	ElasticsearchConfig push.ElasticsearchConfig `yaml:"elasticsearch_config" json:"elasticsearch_config" category:"experimental" doc:"description=Elasticsearch bulk API log ingestion configurations"`
+	SplunkHECConfig     push.SplunkHECConfig     `yaml:"splunk_hec_config" json:"splunk_hec_config" category:"experimental" doc:"description=Splunk HTTP Event Collector API log ingestion configurations"`
+
 	BlockIngestionUntil      dskit_flagext.Time `yaml:"block_ingestion_until" json:"block_ingestion_until"`
 	BlockIngestionStatusCode int                `yaml:"block_ingestion_status_code" json:"block_ingestion_status_code"`
 
+	IngestionPipelines []IngestionPipeline `yaml:"ingestion_pipelines,omitempty" json:"ingestion_pipelines,omitempty" category:"experimental" doc:"description=Pipelines run by the distributors on the pushed log lines of the streams matching their selector, in the order they are configured.\nExample:\n ingestion_pipelines:\n - selector: '{namespace=\"dev\"}'\n pipeline: '!= \"healthcheck\" | regexp \"user=(?P<user>\\\\w+)\" | label_format team=\"{{.namespace}}\"'\n stream_labels: [team]\nThe pipeline supports the stages of LogQL log pipelines. The log lines filtered out by the pipeline are dropped, and the log lines are replaced by the output of the 'line_format' stages. The labels extracted by the pipeline are added to the structured metadata of the log lines, unless they are listed in 'stream_labels' or override a stream label, in which case they are added to the stream labels."`
+
+	Redaction redaction.Config `yaml:"redaction" json:"redaction" category:"experimental" doc:"description=Redaction of the sensitive data of the pushed log lines by the distributors."`
+
+	AdaptiveSampling adaptivesampling.Config `yaml:"adaptive_sampling" json:"adaptive_sampling" category:"experimental" doc:"description=Sampling of the log lines of the streams with a runaway rate by the distributors."`
+
+	PushIdempotency idempotency.Config `yaml:"push_idempotency" json:"push_idempotency" category:"experimental" doc:"description=Deduplication of the retried push requests with the same idempotency key by the distributors and the ingesters."`
+
+	CardinalityGuard cardinalityguard.Config `yaml:"cardinality_guard" json:"cardinality_guard" category:"experimental" doc:"description=Demotion of the labels with too many distinct values to structured metadata by the distributors. The demoted labels are listed by the /distributor/demoted_labels endpoint."`
+
+	DeadLetter deadletter.Config `yaml:"dead_letter" json:"dead_letter" category:"experimental" doc:"description=Storage of the log lines rejected by the distributors in the dead-letter store, from where they can be listed and replayed."`
+
 	IngestionPartitionsTenantShardSize int `yaml:"ingestion_partitions_tenant_shard_size" json:"ingestion_partitions_tenant_shard_size" category:"experimental"`
 
 	PatternIngesterTokenizableJSONFieldsDefault dskit_flagext.StringSliceCSV `yaml:"pattern_ingester_tokenizable_json_fields_default" json:"pattern_ingester_tokenizable_json_fields_default" doc:"hidden"`
@@ -240,6 +272,63 @@ type StreamRetention struct {
 	Matchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
 }
 
+// IngestionRatePolicy is an ingestion rate limit of the streams matching its
+// selector.
+type IngestionRatePolicy struct {
+	Name        string            `yaml:"name" json:"name" doc:"description:Name of the policy, used in the metrics and the errors."`
+	Selector    string            `yaml:"selector" json:"selector" doc:"description:Stream selector expression."`
+	RateMB      float64           `yaml:"rate_mb" json:"rate_mb" doc:"description:Ingestion rate limit of the streams matching the selector in sample size per second. Units in MB."`
+	BurstSizeMB float64           `yaml:"burst_size_mb" json:"burst_size_mb" doc:"description:Ingestion burst size of the streams matching the selector in sample size. Units in MB. Defaults to the tenant ingestion burst size."`
+	Matchers    []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+}
+
+// RateBytes returns the rate limit of the policy in bytes per second.
+func (p IngestionRatePolicy) RateBytes() float64 {
+	return p.RateMB * bytesInMB
+}
+
+// OutOfOrderWindow is the accepted out-of-order window of the streams matching
+// its selector.
+type OutOfOrderWindow struct {
+	Selector string            `yaml:"selector" json:"selector" doc:"description:Stream selector expression."`
+	Window   model.Duration    `yaml:"window" json:"window" doc:"description:How far behind the highest timestamp of a stream its log lines are accepted."`
+	Priority int               `yaml:"priority" json:"priority" doc:"description:The larger the value, the higher the priority."`
+	Backfill bool              `yaml:"backfill" json:"backfill" doc:"description:Append the log lines older than half of the 'max_chunk_age' to separate chunks flushed to the store once full or older than the ingester 'backfill_max_age'."`
+	Matchers []*labels.Matcher `yaml:"-" json:"-"` // populated during validation.
+}
+
+// BurstSizeBytes returns the burst size of the policy in bytes, or 0 if it
+// isn't set.
+func (p IngestionRatePolicy) BurstSizeBytes() int {
+	return int(p.BurstSizeMB * bytesInMB)
+}
+
+// ExtractedField is a field of the JSON or logfmt log lines extracted by the
+// distributors into structured metadata.
+type ExtractedField struct {
+	Field string   `yaml:"field" json:"field" doc:"description:Key of the field in the log lines. The keys of nested JSON objects are separated by dots."`
+	Name  string   `yaml:"name,omitempty" json:"name,omitempty" doc:"description:Name of the structured metadata. Defaults to the key of the field with the characters not allowed in label names replaced by underscores."`
+	Path  []string `yaml:"-" json:"-"` // populated during validation.
+}
+
+// MetadataName returns the name of the structured metadata the field is
+// extracted into.
+func (f ExtractedField) MetadataName() string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return prometheus.NormalizeLabel(f.Field)
+}
+
+// IngestionPipeline is a LogQL pipeline run by the distributors on the log
+// lines of the streams matching its selector.
+type IngestionPipeline struct {
+	Selector     string                 `yaml:"selector" json:"selector" doc:"description:Stream selector expression."`
+	Pipeline     string                 `yaml:"pipeline" json:"pipeline" doc:"description:LogQL pipeline stages run on the log lines of the streams matching the selector."`
+	StreamLabels []string               `yaml:"stream_labels" json:"stream_labels" doc:"description:Labels extracted by the pipeline which are added to the stream labels instead of the structured metadata."`
+	Expr         syntax.LogSelectorExpr `yaml:"-" json:"-"` // populated during validation.
+}
+
 // LimitError are errors that do not comply with the limits specified.
 type LimitError string
 
@@ -301,6 +390,7 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	_ = l.MaxQueryLength.Set("721h")
 	f.Var(&l.MaxQueryLength, "store.max-query-length", "The limit to length of chunk store queries. 0 to disable.")
 	f.IntVar(&l.MaxQuerySeries, "querier.max-query-series", 500, "Limit the maximum of unique series that is returned by a metric query. When the limit is reached an error is returned.")
+	f.IntVar(&l.MaxQueryJoinEntries, "querier.max-query-join-entries", 100000, "Limit the maximum number of log entries read from the right-hand side of a log query join, which are held in memory while the join is evaluated. The left-hand side is streamed and isn't limited. When the limit is reached an error is returned. 0 to disable.")
 	_ = l.MaxQueryRange.Set("0s")
 	f.Var(&l.MaxQueryRange, "querier.max-query-range", "Limit the length of the [range] inside a range query. Default is 0 or unlimited")
 	_ = l.QueryTimeout.Set(DefaultPerTenantQueryTimeout)
@@ -411,8 +501,16 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 			defaultBloomBuildMaxBloomSize,
 		),
 	)
+	f.Var(&l.BloomTermIndexedKeys, "bloom-build.term-indexed-keys", "Experimental. Comma separated list of structured metadata keys whose values are split into terms indexed in the blooms, so that word filters on these keys can use the blooms. Indexing terms adds a token per term, so only keys with free text values should be listed.")
 
 	l.ShardStreams.RegisterFlagsWithPrefix("shard-streams", f)
+	l.ElasticsearchConfig = push.DefaultElasticsearchConfig()
+	l.SplunkHECConfig = push.DefaultSplunkHECConfig()
+	l.Redaction.RegisterFlagsWithPrefix("distributor.redaction", f)
+	l.AdaptiveSampling.RegisterFlagsWithPrefix("distributor.adaptive-sampling", f)
+	l.PushIdempotency.RegisterFlagsWithPrefix("distributor.push-idempotency", f)
+	l.CardinalityGuard.RegisterFlagsWithPrefix("distributor.cardinality-guard", f)
+	l.DeadLetter.RegisterFlagsWithPrefix("distributor.dead-letter", f)
 
 	f.IntVar(&l.VolumeMaxSeries, "limits.volume-max-series", 1000, "The default number of aggregated series or labels that can be returned from a log-volume endpoint")
 
@@ -505,6 +603,10 @@ func (l *Limits) Validate() error {
 		return err
 	}
 
+	if err := l.ElasticsearchConfig.Validate(); err != nil {
+		return err
+	}
+
 	if _, err := logql.ParseShardVersion(l.TSDBShardingStrategy); err != nil {
 		return errors.Wrap(err, "invalid tsdb sharding strategy")
 	}
@@ -517,6 +619,94 @@ func (l *Limits) Validate() error {
 		return errors.New("querier.tsdb-max-bytes-per-shard must be greater than 0")
 	}
 
+	if err := l.Redaction.Validate(); err != nil {
+		return err
+	}
+
+	if l.AdaptiveSampling.Enabled && l.AdaptiveSampling.RateThreshold <= 0 {
+		return errors.New("distributor.adaptive-sampling.rate-threshold must be greater than 0")
+	}
+
+	if l.PushIdempotency.Window < 0 || l.PushIdempotency.MaxKeys < 0 {
+		return errors.New("distributor.push-idempotency.window and distributor.push-idempotency.max-keys must not be negative")
+	}
+
+	if err := l.CardinalityGuard.Validate(); err != nil {
+		return err
+	}
+
+	policyNames := make(map[string]struct{}, len(l.IngestionRatePolicies))
+	for i, p := range l.IngestionRatePolicies {
+		if p.Name == "" {
+			return errors.New("ingestion rate policy name must not be empty")
+		}
+		if _, ok := policyNames[p.Name]; ok {
+			return fmt.Errorf("duplicate ingestion rate policy %s", p.Name)
+		}
+		policyNames[p.Name] = struct{}{}
+
+		if p.RateMB <= 0 {
+			return fmt.Errorf("invalid ingestion rate policy %s: rate_mb must be greater than 0", p.Name)
+		}
+		if p.BurstSizeMB < 0 {
+			return fmt.Errorf("invalid ingestion rate policy %s: burst_size_mb must not be negative", p.Name)
+		}
+
+		matchers, err := syntax.ParseMatchers(p.Selector, true)
+		if err != nil {
+			return fmt.Errorf("invalid ingestion rate policy %s selector %s: %w", p.Name, p.Selector, err)
+		}
+		// populate the matchers during validation
+		l.IngestionRatePolicies[i].Matchers = matchers
+	}
+
+	for i, w := range l.OutOfOrderWindows {
+		if w.Window <= 0 {
+			return fmt.Errorf("invalid out-of-order window %s: window must be greater than 0", w.Selector)
+		}
+		matchers, err := syntax.ParseMatchers(w.Selector, true)
+		if err != nil {
+			return fmt.Errorf("invalid out-of-order window selector %s: %w", w.Selector, err)
+		}
+		// populate the matchers during validation
+		l.OutOfOrderWindows[i].Matchers = matchers
+	}
+
+	fieldNames := make(map[string]struct{}, len(l.ExtractFields))
+	for i, f := range l.ExtractFields {
+		if f.Field == "" {
+			return errors.New("extracted field must not be empty")
+		}
+		name := f.MetadataName()
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid extracted field %s: invalid name %s", f.Field, name)
+		}
+		if _, ok := fieldNames[name]; ok {
+			return fmt.Errorf("duplicate extracted field name %s", name)
+		}
+		fieldNames[name] = struct{}{}
+		// populate the path during validation
+		l.ExtractFields[i].Path = strings.Split(f.Field, ".")
+	}
+
+	for i, p := range l.IngestionPipelines {
+		expr, err := syntax.ParseLogSelector(p.Selector+" "+p.Pipeline, true)
+		if err != nil {
+			return fmt.Errorf("invalid ingestion pipeline %s %s: %w", p.Selector, p.Pipeline, err)
+		}
+		if syntax.HasMacros(expr) {
+			return fmt.Errorf("invalid ingestion pipeline %s %s: query macros are not supported", p.Selector, p.Pipeline)
+		}
+		// populate the expression during validation
+		l.IngestionPipelines[i].Expr = expr
+	}
+
+	for name, pipeline := range l.QueryMacros.Map() {
+		if _, err := syntax.ParseMacro(pipeline); err != nil {
+			return fmt.Errorf("invalid query macro %s: %w", name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -649,6 +839,11 @@ func (o *Overrides) MaxQuerySeries(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).MaxQuerySeries
 }
 
+// MaxQueryJoinEntries returns the limit of entries read from the right-hand side of a log query join.
+func (o *Overrides) MaxQueryJoinEntries(_ context.Context, userID string) int {
+	return o.getOverridesForUser(userID).MaxQueryJoinEntries
+}
+
 // MaxQueryRange returns the limit for the max [range] value that can be in a range query
 func (o *Overrides) MaxQueryRange(_ context.Context, userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).MaxQueryRange)
@@ -957,6 +1152,11 @@ func (o *Overrides) RequiredNumberLabels(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).RequiredNumberLabels
 }
 
+// QueryMacros returns the pipelines of the query macros of a tenant by name.
+func (o *Overrides) QueryMacros(_ context.Context, userID string) map[string]string {
+	return o.getOverridesForUser(userID).QueryMacros.Map()
+}
+
 func (o *Overrides) DefaultLimits() *Limits {
 	return o.defaultLimits
 }
@@ -1043,6 +1243,10 @@ func (o *Overrides) BloomMaxBloomSize(userID string) int {
 	return o.getOverridesForUser(userID).BloomMaxBloomSize.Val()
 }
 
+func (o *Overrides) BloomTermIndexedKeys(userID string) []string {
+	return o.getOverridesForUser(userID).BloomTermIndexedKeys
+}
+
 func (o *Overrides) BloomBlockEncoding(userID string) string {
 	return o.getOverridesForUser(userID).BloomBlockEncoding
 }
@@ -1063,6 +1267,14 @@ func (o *Overrides) OTLPConfig(userID string) push.OTLPConfig {
 	return o.getOverridesForUser(userID).OTLPConfig
 }
 
+func (o *Overrides) ElasticsearchConfig(userID string) push.ElasticsearchConfig {
+	return o.getOverridesForUser(userID).ElasticsearchConfig
+}
+
+func (o *Overrides) SplunkHECConfig(userID string) push.SplunkHECConfig {
+	return o.getOverridesForUser(userID).SplunkHECConfig
+}
+
 func (o *Overrides) BlockIngestionUntil(userID string) time.Time {
 	return time.Time(o.getOverridesForUser(userID).BlockIngestionUntil)
 }
@@ -1071,6 +1283,42 @@ func (o *Overrides) BlockIngestionStatusCode(userID string) int {
 	return o.getOverridesForUser(userID).BlockIngestionStatusCode
 }
 
+func (o *Overrides) ExtractFields(userID string) []ExtractedField {
+	return o.getOverridesForUser(userID).ExtractFields
+}
+
+func (o *Overrides) IngestionRatePolicies(userID string) []IngestionRatePolicy {
+	return o.getOverridesForUser(userID).IngestionRatePolicies
+}
+
+func (o *Overrides) OutOfOrderWindows(userID string) []OutOfOrderWindow {
+	return o.getOverridesForUser(userID).OutOfOrderWindows
+}
+
+func (o *Overrides) IngestionPipelines(userID string) []IngestionPipeline {
+	return o.getOverridesForUser(userID).IngestionPipelines
+}
+
+func (o *Overrides) Redaction(userID string) redaction.Config {
+	return o.getOverridesForUser(userID).Redaction
+}
+
+func (o *Overrides) AdaptiveSampling(userID string) adaptivesampling.Config {
+	return o.getOverridesForUser(userID).AdaptiveSampling
+}
+
+func (o *Overrides) PushIdempotency(userID string) idempotency.Config {
+	return o.getOverridesForUser(userID).PushIdempotency
+}
+
+func (o *Overrides) CardinalityGuard(userID string) cardinalityguard.Config {
+	return o.getOverridesForUser(userID).CardinalityGuard
+}
+
+func (o *Overrides) DeadLetter(userID string) deadletter.Config {
+	return o.getOverridesForUser(userID).DeadLetter
+}
+
 func (o *Overrides) PatternIngesterTokenizableJSONFields(userID string) []string {
 	defaultFields := o.getOverridesForUser(userID).PatternIngesterTokenizableJSONFieldsDefault
 	appendFields := o.getOverridesForUser(userID).PatternIngesterTokenizableJSONFieldsAppend
@@ -14,6 +14,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/compactor/deletionmode"
 	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/distributor/adaptivesampling"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logql"
 )
@@ -341,6 +342,62 @@ func TestLimitsValidation(t *testing.T) {
 			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "unknown"},
 			expected: fmt.Errorf("invalid encoding: unknown, supported: %s", compression.SupportedCodecs()),
 		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", QueryMacros: NewOverwriteMarshalingStringMap(map[string]string{"parse": "| json | level=\"error\""})},
+			expected: nil,
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", QueryMacros: NewOverwriteMarshalingStringMap(map[string]string{"parse": "| json | @parse"})},
+			expected: fmt.Errorf("invalid query macro parse: parse error : a macro cannot reference another macro: @parse"),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IngestionPipelines: []IngestionPipeline{{Selector: `{app="foo"}`, Pipeline: `!= "debug" | logfmt`}}},
+			expected: nil,
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IngestionPipelines: []IngestionPipeline{{Selector: `{app="foo"}`, Pipeline: `| logfmt |`}}},
+			expected: fmt.Errorf(`invalid ingestion pipeline {app="foo"} | logfmt |`),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IngestionPipelines: []IngestionPipeline{{Selector: `{app="foo"}`, Pipeline: `| @parse`}}},
+			expected: fmt.Errorf(`invalid ingestion pipeline {app="foo"} | @parse: query macros are not supported`),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", AdaptiveSampling: adaptivesampling.Config{Enabled: true}},
+			expected: fmt.Errorf("distributor.adaptive-sampling.rate-threshold must be greater than 0"),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IngestionRatePolicies: []IngestionRatePolicy{{Name: "batch", Selector: `{namespace="batch"}`, RateMB: 5}}},
+			expected: nil,
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IngestionRatePolicies: []IngestionRatePolicy{{Selector: `{namespace="batch"}`, RateMB: 5}}},
+			expected: fmt.Errorf("ingestion rate policy name must not be empty"),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IngestionRatePolicies: []IngestionRatePolicy{{Name: "batch", Selector: `{namespace="batch"}`, RateMB: 5}, {Name: "batch", Selector: `{namespace="cron"}`, RateMB: 5}}},
+			expected: fmt.Errorf("duplicate ingestion rate policy batch"),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IngestionRatePolicies: []IngestionRatePolicy{{Name: "batch", Selector: `{namespace="batch"}`}}},
+			expected: fmt.Errorf("invalid ingestion rate policy batch: rate_mb must be greater than 0"),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", IngestionRatePolicies: []IngestionRatePolicy{{Name: "batch", Selector: `{namespace=}`, RateMB: 5}}},
+			expected: fmt.Errorf("invalid ingestion rate policy batch selector {namespace=}"),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", OutOfOrderWindows: []OutOfOrderWindow{{Selector: `{source="iot"}`, Window: model.Duration(6 * time.Hour), Backfill: true}}},
+			expected: nil,
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", OutOfOrderWindows: []OutOfOrderWindow{{Selector: `{source="iot"}`}}},
+			expected: fmt.Errorf(`invalid out-of-order window {source="iot"}: window must be greater than 0`),
+		},
+		{
+			limits:   Limits{DeletionMode: "disabled", BloomBlockEncoding: "none", OutOfOrderWindows: []OutOfOrderWindow{{Selector: `{source=}`, Window: model.Duration(time.Hour)}}},
+			expected: fmt.Errorf("invalid out-of-order window selector {source=}"),
+		},
 	} {
 		desc := fmt.Sprintf("%s/%s", tc.limits.DeletionMode, tc.limits.BloomBlockEncoding)
 		t.Run(desc, func(t *testing.T) {
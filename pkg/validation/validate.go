@@ -25,6 +25,10 @@ const (
 	// LineTooLong is a reason for discarding too long log lines.
 	LineTooLong         = "line_too_long"
 	LineTooLongErrorMsg = "Max entry size '%d' bytes exceeded for stream '%s' while adding an entry with length '%d' bytes"
+	// IngestionRatePolicyLimited is a reason for discarding lines when the rate
+	// limit of an ingestion rate policy matching their stream is hit.
+	IngestionRatePolicyLimited         = "ingestion_rate_policy_limited"
+	IngestionRatePolicyLimitedErrorMsg = "Ingestion rate limit of policy %s exceeded for user %s (limit: %d bytes/sec) while attempting to ingest '%d' lines totaling '%d' bytes for stream %s, reduce log volume or contact your Loki administrator to see if the limit can be increased"
 	// StreamLimit is a reason for discarding lines when we can't create a new stream
 	// because the limit of active streams has been reached.
 	StreamLimit         = "stream_limit"
@@ -69,6 +73,17 @@ const (
 	StructuredMetadataTooManyErrorMsg    = "stream '%s' has too many structured metadata labels: '%d', limit: '%d'. Please see `limits_config.max_structured_metadata_entries_count` or contact your Loki administrator to increase it."
 	BlockedIngestion                     = "blocked_ingestion"
 	BlockedIngestionErrorMsg             = "ingestion blocked for user %s until '%s' with status code '%d'"
+	// DroppedByIngestionPipeline is the reason for the log lines filtered out by
+	// the ingestion pipelines of a tenant.
+	DroppedByIngestionPipeline = "dropped_by_ingestion_pipeline"
+	// Redacted is the reason for the log lines whose sensitive data is redacted.
+	Redacted = "redacted"
+	// Sampled is the reason for the log lines of runaway streams discarded by
+	// the adaptive sampling.
+	Sampled = "sampled"
+	// Deduplicated is the reason for the identical log lines of runaway streams
+	// suppressed by the adaptive sampling.
+	Deduplicated = "deduplicated"
 )
 
 type ErrStreamRateLimit struct {